@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/flynn/flynn/controller/client"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/go-docopt"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	register("apply", runApply, `
+usage: flynn apply [-y] [-n] -f <file>
+
+Apply a declarative app manifest.
+
+The manifest is a YAML (or JSON) file describing the app's configuration,
+which is compared with the app's current configuration by the controller.
+The changes are shown and, once confirmed, applied as a single release,
+formation and route change.
+
+Top-level keys which are omitted are left unchanged, whereas keys which are
+present replace the existing configuration (for example "routes: []" deletes
+all of the app's routes). The keys are:
+
+	env        release env vars, which are merged with the env of the app's
+	           resources and env groups
+	processes  process types, with the fields of each being merged with
+	           those of the existing process type (unlisted types are
+	           removed)
+	formation  number of each process type to run (unlisted types are
+	           scaled to zero)
+	routes     HTTP routes (with domain and path) and TCP routes (with port)
+	resources  providers to provision resources from (existing resources
+	           are never removed)
+	deploy     deploy strategy and timeout
+
+Options:
+	-f, --file=<file>  path to the manifest, or "-" to read it from stdin
+	-y, --yes          apply the changes without confirmation
+	-n, --dry-run      only show the changes which would be made
+
+Examples:
+
+	$ cat app.yaml
+	env:
+	  LOG_LEVEL: info
+	processes:
+	  web:
+	    resources:
+	      memory:
+	        limit: 1073741824
+	formation:
+	  web: 3
+	routes:
+	  - domain: www.example.com
+	resources:
+	  - postgres
+	deploy:
+	  strategy: one-by-one
+
+	$ flynn apply -f app.yaml
+	+ env LOG_LEVEL: info
+	~ process web: {"args":["/runner/init","start","web"]} => {"args":["/runner/init","start","web"],"resources":{"memory":{"limit":1073741824}}}
+	~ formation web: 1 => 3
+	+ route http:www.example.com/: myapp-web
+	+ resource postgres
+	~ deploy strategy: all-at-once => one-by-one
+	Apply these changes? (yes/no): yes
+	Created release 5058ae7964f74c399a240bdd6e7d1bcb (deployment 3a8b1c6e2f5d4e7a9b0c1d2e3f4a5b6c).
+`)
+}
+
+func runApply(args *docopt.Args, client controller.Client) error {
+	path := args.String["--file"]
+	var data []byte
+	var err error
+	if path == "-" {
+		if !args.Bool["--yes"] && !args.Bool["--dry-run"] {
+			return errors.New("--yes is required when reading the manifest from stdin")
+		}
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+	manifest, err := parseManifest(data)
+	if err != nil {
+		return fmt.Errorf("error parsing manifest: %s", err)
+	}
+
+	appID := mustApp()
+	diff, err := client.ManifestDryRun(appID, manifest)
+	if err != nil {
+		return err
+	}
+	if len(diff.Changes) == 0 {
+		log.Println("No changes.")
+		return nil
+	}
+	printManifestDiff(diff)
+	if args.Bool["--dry-run"] {
+		return nil
+	}
+	if !args.Bool["--yes"] && !promptYesNo("Apply these changes?") {
+		return nil
+	}
+
+	diff, err = client.ApplyManifest(appID, manifest)
+	if err != nil {
+		return err
+	}
+	switch {
+	case diff.DeploymentID != "":
+		log.Printf("Created release %s (deployment %s).", diff.ReleaseID, diff.DeploymentID)
+	case diff.ReleaseID != "":
+		log.Printf("Created release %s.", diff.ReleaseID)
+	default:
+		log.Println("Applied changes.")
+	}
+	return nil
+}
+
+// parseManifest parses a YAML manifest by converting it to JSON, so that
+// the manifest has the same structure as the controller's JSON types
+func parseManifest(data []byte) (*ct.AppManifest, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(yamlToJSON(v))
+	if err != nil {
+		return nil, err
+	}
+	manifest := &ct.AppManifest{}
+	return manifest, json.Unmarshal(data, manifest)
+}
+
+// yamlToJSON converts the maps in a decoded YAML value to maps with string
+// keys, which can be encoded as JSON
+func yamlToJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[fmt.Sprint(key)] = yamlToJSON(val)
+		}
+		return m
+	case []interface{}:
+		for i, val := range v {
+			v[i] = yamlToJSON(val)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func printManifestDiff(diff *ct.ManifestDiff) {
+	for _, c := range diff.Changes {
+		switch c.Op {
+		case ct.ManifestChangeAdd:
+			if c.New == "" {
+				fmt.Printf("+ %s %s\n", c.Type, c.Name)
+			} else {
+				fmt.Printf("+ %s %s: %s\n", c.Type, c.Name, c.New)
+			}
+		case ct.ManifestChangeRemove:
+			fmt.Printf("- %s %s\n", c.Type, c.Name)
+		default:
+			fmt.Printf("~ %s %s: %s => %s\n", c.Type, c.Name, c.Old, c.New)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/flynn/flynn/controller/client"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/go-docopt"
+)
+
+func init() {
+	register("audit", runAudit, `
+usage: flynn audit [--app=<app>] [--actor=<key-id>] [--since=<time>] [--until=<time>] [-n <count>] [-f] [--json]
+
+Show the audit log.
+
+When the controller's AUDIT_LOG env var is set to "true", a record of every
+request which modifies the cluster is persisted, including the ID of the auth
+key or name of the API token which made the request and its body (with
+secrets redacted).
+
+Times may either be an RFC 3339 timestamp or a duration before now (e.g. "2h").
+
+Options:
+	--app=<app>       only show requests for the given app
+	--actor=<key-id>  only show requests made with the given auth key ID or API token name
+	--since=<time>    only show requests made at or after the given time
+	--until=<time>    only show requests made before the given time
+	-n <count>        show at most <count> records [default: 100]
+	-f, --follow      stream new records
+	--json            print records as JSON, including request bodies
+
+Examples:
+
+	$ flynn audit --app myapp --since 1h
+	TIME                 ACTOR  METHOD  PATH                   APP    STATUS
+	2017-05-04 12:01:39  ci     POST    /releases              myapp  200
+	2017-05-04 12:01:40  ci     PUT     /apps/myapp/release    myapp  200
+	2017-05-04 12:01:40  ci     POST    /apps/myapp/deploy     myapp  200
+`)
+}
+
+func runAudit(args *docopt.Args, client controller.Client) error {
+	opts := ct.ListAuditOptions{KeyID: args.String["--actor"]}
+	if name := args.String["--app"]; name != "" {
+		app, err := client.GetApp(name)
+		if err != nil {
+			return err
+		}
+		opts.AppID = app.ID
+	}
+	var err error
+	if opts.Since, err = parseAuditTime(args.String["--since"]); err != nil {
+		return err
+	}
+	if opts.Before, err = parseAuditTime(args.String["--until"]); err != nil {
+		return err
+	}
+	if opts.Count, err = strconv.Atoi(args.String["-n"]); err != nil {
+		return fmt.Errorf("invalid count %q", args.String["-n"])
+	}
+
+	records, err := client.ListAuditLog(opts)
+	if err != nil {
+		return err
+	}
+
+	apps, err := client.AppList()
+	if err != nil {
+		return err
+	}
+	appNames := make(map[string]string, len(apps))
+	for _, app := range apps {
+		appNames[app.ID] = app.Name
+	}
+
+	jsonOutput := args.Bool["--json"]
+	var w *tabwriter.Writer
+	if !jsonOutput {
+		w = tabWriter()
+		listRec(w, "TIME", "ACTOR", "METHOD", "PATH", "APP", "STATUS")
+	}
+	printRecord := func(r *ct.AuditRecord) error {
+		if jsonOutput {
+			return json.NewEncoder(os.Stdout).Encode(r)
+		}
+		var ts string
+		if r.CreatedAt != nil {
+			ts = r.CreatedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		app := r.AppID
+		if name, ok := appNames[app]; ok {
+			app = name
+		}
+		listRec(w, ts, r.KeyID, r.Method, r.Path, app, r.Status)
+		return w.Flush()
+	}
+
+	// records are listed most recent first, so print them in reverse
+	for i := len(records) - 1; i >= 0; i-- {
+		if err := printRecord(records[i]); err != nil {
+			return err
+		}
+	}
+	if !args.Bool["--follow"] {
+		return nil
+	}
+
+	events := make(chan *ct.Event)
+	stream, err := client.StreamEvents(ct.StreamEventsOptions{
+		AppID:       opts.AppID,
+		ObjectTypes: []ct.EventType{ct.EventTypeAudit},
+	}, events)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	for event := range events {
+		var r ct.AuditRecord
+		if err := json.Unmarshal(event.Data, &r); err != nil {
+			return err
+		}
+		if opts.KeyID != "" && r.KeyID != opts.KeyID {
+			continue
+		}
+		if err := printRecord(&r); err != nil {
+			return err
+		}
+	}
+	return stream.Err()
+}
+
+// parseAuditTime parses either an RFC 3339 timestamp or a duration before now
+func parseAuditTime(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		t := time.Now().Add(-d)
+		return &t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time %q, expected an RFC 3339 timestamp or a duration", s)
+	}
+	return &t, nil
+}
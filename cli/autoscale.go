@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/flynn/flynn/controller/client"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/go-docopt"
+)
+
+func init() {
+	register("autoscale", runAutoscale, `
+usage: flynn autoscale
+       flynn autoscale set <proc> [--min=<min>] --max=<max> [--rps=<rps>] [--cpu=<percent>] [--memory=<percent>] [--scale-up-cooldown=<seconds>] [--scale-down-cooldown=<seconds>]
+       flynn autoscale remove <proc>
+
+Manage autoscale policies.
+
+An autoscale policy periodically scales a process type between a minimum and
+maximum number of jobs so that the load on each job stays close to the given
+targets, using the highest number of jobs required by any target.
+
+Options:
+	--min=<min>                        minimum number of jobs [default: 1]
+	--max=<max>                        maximum number of jobs
+	--rps=<rps>                        target requests per second per job
+	--cpu=<percent>                    target CPU utilisation of each job as a percentage of its CPU limit
+	--memory=<percent>                 target memory usage of each job as a percentage of its memory limit
+	--scale-up-cooldown=<seconds>      minimum seconds between scaling and scaling up again (defaults to 60)
+	--scale-down-cooldown=<seconds>    minimum seconds between scaling and scaling down again (defaults to 300)
+
+Commands:
+	With no arguments, shows a list of autoscale policies.
+
+	set     sets the autoscale policy for a process type
+	remove  removes the autoscale policy for a process type
+
+Examples:
+
+	$ flynn autoscale set web --min 2 --max 10 --rps 50 --cpu 70
+
+	$ flynn autoscale
+	TYPE  MIN  MAX  TARGET RPS  TARGET CPU  TARGET MEMORY  LAST SCALED
+	web   2    10   50          70%                        5 minutes ago
+
+	$ flynn autoscale remove web
+`)
+}
+
+func runAutoscale(args *docopt.Args, client controller.Client) error {
+	if args.Bool["set"] {
+		return runAutoscaleSet(args, client)
+	} else if args.Bool["remove"] {
+		_, err := client.DeleteAutoscalePolicy(mustApp(), args.String["<proc>"])
+		return err
+	}
+	return runAutoscaleList(client)
+}
+
+func runAutoscaleList(client controller.Client) error {
+	policies, err := client.AutoscalePolicyList(mustApp())
+	if err != nil {
+		return err
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	percent := func(n int) string {
+		if n == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%d%%", n)
+	}
+	listRec(w, "TYPE", "MIN", "MAX", "TARGET RPS", "TARGET CPU", "TARGET MEMORY", "LAST SCALED")
+	for _, p := range policies {
+		var rps string
+		if p.TargetRequestsPerSecond > 0 {
+			rps = strconv.FormatFloat(p.TargetRequestsPerSecond, 'f', -1, 64)
+		}
+		listRec(w, p.ProcessType, p.Min, p.Max, rps, percent(p.TargetCPU), percent(p.TargetMemory), humanTime(p.LastScaledAt))
+	}
+	return nil
+}
+
+func runAutoscaleSet(args *docopt.Args, client controller.Client) error {
+	policy := &ct.AutoscalePolicy{
+		AppID:       mustApp(),
+		ProcessType: args.String["<proc>"],
+	}
+	for _, opt := range []struct {
+		flag string
+		val  *int
+	}{
+		{"--min", &policy.Min},
+		{"--max", &policy.Max},
+		{"--cpu", &policy.TargetCPU},
+		{"--memory", &policy.TargetMemory},
+		{"--scale-up-cooldown", &policy.ScaleUpCooldown},
+		{"--scale-down-cooldown", &policy.ScaleDownCooldown},
+	} {
+		if s := args.String[opt.flag]; s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s: %q", opt.flag, s)
+			}
+			*opt.val = n
+		}
+	}
+	if s := args.String["--rps"]; s != "" {
+		rps, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value for --rps: %q", s)
+		}
+		policy.TargetRequestsPerSecond = rps
+	}
+	return client.PutAutoscalePolicy(policy)
+}
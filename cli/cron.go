@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/flynn/flynn/controller/client"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/host/resource"
+	"github.com/flynn/go-docopt"
+)
+
+func init() {
+	register("cron", runCron, `
+usage: flynn cron
+       flynn cron add [-r <release>] [-c <policy>] [-e <env>]... [--limits <limits>] <schedule> [--] <command> [<argument>...]
+       flynn cron remove <id>
+       flynn cron runs <id>
+
+Manage cron schedules which periodically run one-off jobs.
+
+Options:
+	-r, --release=<release>      id of release to run (defaults to the app release at the time of each run)
+	-c, --concurrency=<policy>   what to do if the previous run's job is still active, one of
+	                             "allow" (run alongside it), "forbid" (skip the run) or
+	                             "replace" (stop it and run a new job) [default: allow]
+	-e, --env=<env>              set an environment variable for the job (e.g. -e KEY=val)
+	--limits <limits>            comma separated limits for the job (see "flynn limit -h" for format)
+
+Commands:
+	With no arguments, shows a list of cron schedules.
+
+	add     adds a schedule which runs <command> according to the cron expression
+	        <schedule>, which is either five space separated minute, hour, day of
+	        month, month and day of week fields (times are in UTC) or one of
+	        @yearly, @monthly, @weekly, @daily or @hourly
+
+	remove  removes a schedule
+
+	runs    shows the most recent runs of a schedule
+
+Examples:
+
+	$ flynn cron add "*/15 * * * *" -- bin/sync --all
+	Created schedule 8f2a3bd4-5e64-4d9b-8d1e-4f1a38c8b0f3
+
+	$ flynn cron add -c forbid @daily bin/report
+	Created schedule 2b1e0f7e-9c63-4f8e-b4a5-0d1c0f3b6a3e
+
+	$ flynn cron
+	ID                                    SCHEDULE      COMMAND          CONCURRENCY  NEXT RUN     LAST RUN
+	8f2a3bd4-5e64-4d9b-8d1e-4f1a38c8b0f3  */15 * * * *  bin/sync --all   allow        in 9 minutes  6 minutes ago
+	2b1e0f7e-9c63-4f8e-b4a5-0d1c0f3b6a3e  @daily        bin/report       forbid       in 13 hours
+
+	$ flynn cron remove 2b1e0f7e-9c63-4f8e-b4a5-0d1c0f3b6a3e
+	Removed schedule 2b1e0f7e-9c63-4f8e-b4a5-0d1c0f3b6a3e
+`)
+}
+
+func runCron(args *docopt.Args, client controller.Client) error {
+	if args.Bool["add"] {
+		return runCronAdd(args, client)
+	} else if args.Bool["remove"] {
+		return runCronRemove(args, client)
+	} else if args.Bool["runs"] {
+		return runCronRuns(args, client)
+	}
+	return runCronList(args, client)
+}
+
+func runCronList(args *docopt.Args, client controller.Client) error {
+	schedules, err := client.ScheduleList(mustApp())
+	if err != nil {
+		return err
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	listRec(w, "ID", "SCHEDULE", "COMMAND", "CONCURRENCY", "NEXT RUN", "LAST RUN")
+	for _, s := range schedules {
+		var next string
+		if s.NextRunAt != nil {
+			next = "in " + units.HumanDuration(s.NextRunAt.Sub(time.Now().UTC()))
+		}
+		listRec(w, s.ID, s.Cron, strings.Join(s.Args, " "), s.Concurrency, next, humanTime(s.LastRunAt))
+	}
+	return nil
+}
+
+func runCronAdd(args *docopt.Args, client controller.Client) error {
+	schedule := &ct.Schedule{
+		AppID:       mustApp(),
+		Cron:        args.String["<schedule>"],
+		ReleaseID:   args.String["--release"],
+		Args:        append([]string{args.String["<command>"]}, args.All["<argument>"].([]string)...),
+		Concurrency: ct.ScheduleConcurrencyPolicy(args.String["--concurrency"]),
+	}
+	if env := args.All["--env"].([]string); len(env) > 0 {
+		schedule.Env = make(map[string]string, len(env))
+		for _, e := range env {
+			kv := strings.SplitN(e, "=", 2)
+			if len(kv) != 2 {
+				return fmt.Errorf("invalid env var %q, expected <var>=<val>", e)
+			}
+			schedule.Env[kv[0]] = kv[1]
+		}
+	}
+	if limits := args.String["--limits"]; limits != "" {
+		schedule.Resources = resource.Defaults()
+		resources, err := resource.ParseCSV(limits)
+		if err != nil {
+			return err
+		}
+		for typ, limit := range resources {
+			schedule.Resources[typ] = limit
+		}
+	}
+	if schedule.ReleaseID == "" {
+		// check the app has a release to run so that the error is
+		// reported now rather than when the schedule runs
+		if _, err := client.GetAppRelease(schedule.AppID); err == controller.ErrNotFound {
+			return errors.New("No app release, specify a release with --release")
+		} else if err != nil {
+			return err
+		}
+	}
+	if err := client.CreateSchedule(schedule); err != nil {
+		return err
+	}
+	fmt.Printf("Created schedule %s\n", schedule.ID)
+	return nil
+}
+
+func runCronRemove(args *docopt.Args, client controller.Client) error {
+	schedule, err := client.DeleteSchedule(mustApp(), args.String["<id>"])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed schedule %s\n", schedule.ID)
+	return nil
+}
+
+func runCronRuns(args *docopt.Args, client controller.Client) error {
+	runs, err := client.ScheduleRunList(mustApp(), args.String["<id>"])
+	if err != nil {
+		return err
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	listRec(w, "ID", "STATE", "JOB", "RELEASE", "SCHEDULED", "ERROR")
+	for _, r := range runs {
+		listRec(w, r.ID, r.State, r.JobID, r.ReleaseID, humanTime(r.ScheduledAt), r.Error)
+	}
+	return nil
+}
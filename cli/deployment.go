@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -14,6 +15,15 @@ func init() {
 usage: flynn deployment
        flynn deployment timeout [<timeout>]
        flynn deployment batch-size [<size>]
+       flynn deployment canary [--count=<count>] [--weight=<percent>] [--window=<seconds>]
+       flynn deployment warm-period [<seconds>]
+       flynn deployment pause [<deployment>]
+       flynn deployment resume [<deployment>]
+       flynn deployment abort [<deployment>]
+       flynn deployment rollback [--bake-period=<seconds>] [--max-error-rate=<percent>] [--max-crashes=<count>] [--max-health-failures=<count>]
+       flynn deployment approval [--webhook=<url>] [--timeout=<seconds>] [--disable]
+       flynn deployment approve [<deployment>] [--comment=<comment>]
+       flynn deployment reject [<deployment>] [--comment=<comment>]
 
 Manage app deployments.
 
@@ -24,6 +34,45 @@ Commands:
 
 	batch-size  gets or sets the batch size for deployments using the in-batches strategy
 
+	canary      gets or sets the configuration for deployments using the canary strategy
+
+	warm-period gets or sets the number of seconds to keep the old release running after
+	            switching routes to the new release when deploying using the blue-green strategy
+
+	pause       pauses a running deployment before its next batch or step (sirenia deployments cannot be paused)
+	            (defaults to the app's running deployment)
+
+	resume      resumes a paused deployment
+
+	abort       stops a running or paused deployment and rolls back to the previous release
+
+	rollback    gets or sets the configuration for automatically rolling back deployments
+	            which exceed any of the thresholds while the new release is monitored for
+	            the bake period (a bake period of 0 disables automatic rollback)
+
+	approval    gets or sets the deploy gate, which holds new deployments in the pending_approval
+	            status until they are approved, optionally sending the approval request and
+	            release diff to a webhook (signed in the Flynn-Signature header with the
+	            listed secret in the same way as webhook deliveries)
+
+	approve     approves a deployment which is pending approval
+	            (defaults to the app's running deployment)
+
+	reject      rejects a deployment which is pending approval
+
+Options:
+	--count=<count>                number of jobs of each process type to start from the new release
+	--weight=<percent>             percentage of HTTP requests to route to the new jobs
+	--window=<seconds>             number of seconds to observe the new jobs before promoting the release
+	--bake-period=<seconds>        number of seconds to monitor the new release once deployed
+	--max-error-rate=<percent>     maximum percentage of HTTP requests to the new release which may fail
+	--max-crashes=<count>          maximum number of new release jobs which may crash
+	--max-health-failures=<count>  maximum number of times new release jobs may fail health checks
+	--webhook=<url>                URL to POST approval requests to
+	--timeout=<seconds>            number of seconds to wait for approval before rejecting the deployment
+	--disable                      disable the deploy gate
+	--comment=<comment>            comment to record with the approval or rejection
+
 Examples:
 
 	$ flynn deployment
@@ -42,6 +91,41 @@ Examples:
 
 	$ flynn deployment batch-size
 	3
+
+	$ flynn deployment canary --count 2 --weight 25
+
+	$ flynn deployment canary
+	COUNT  WEIGHT  WINDOW
+	2      25%     300s
+
+	$ flynn deployment warm-period 600
+
+	$ flynn deployment warm-period
+	600
+
+	$ flynn deployment pause
+	deployment a6d470d6-9638-4d74-ae71-91c3d9887714 will pause before its next batch or step
+
+	$ flynn deployment resume
+	deployment a6d470d6-9638-4d74-ae71-91c3d9887714 resumed
+
+	$ flynn deployment abort
+	deployment a6d470d6-9638-4d74-ae71-91c3d9887714 aborted, rolling back
+
+	$ flynn deployment rollback --bake-period 300 --max-error-rate 2.5
+
+	$ flynn deployment rollback
+	BAKE PERIOD  MAX ERROR RATE  MAX CRASHES  MAX HEALTH FAILURES
+	300s         2.5%            0            0
+
+	$ flynn deployment approval --webhook https://ci.example.com/flynn --timeout 1800
+
+	$ flynn deployment approval
+	WEBHOOK                        TIMEOUT  SECRET
+	https://ci.example.com/flynn   1800s    3c5b8a0f9e2d417b6a8c4e1f0d9b7a2c5e8f1a3d6b9c0e4f7a2d5b8c1e3f6a9d
+
+	$ flynn deployment approve --comment "checks passed"
+	deployment a6d470d6-9638-4d74-ae71-91c3d9887714 approved
 `)
 }
 
@@ -56,6 +140,31 @@ func runDeployments(args *docopt.Args, client controller.Client) error {
 			return runSetDeployBatchSize(args, client)
 		}
 		return runGetDeployBatchSize(args, client)
+	} else if args.Bool["canary"] {
+		return runDeployCanary(args, client)
+	} else if args.Bool["warm-period"] {
+		if args.String["<seconds>"] != "" {
+			return runSetDeployWarmPeriod(args, client)
+		}
+		return runGetDeployWarmPeriod(args, client)
+	} else if args.Bool["pause"] {
+		return runControlDeployment(args, client, client.PauseDeployment, "will pause before its next batch or step")
+	} else if args.Bool["resume"] {
+		return runControlDeployment(args, client, client.ResumeDeployment, "resumed")
+	} else if args.Bool["abort"] {
+		return runControlDeployment(args, client, client.AbortDeployment, "aborted, rolling back")
+	} else if args.Bool["rollback"] {
+		return runDeployRollback(args, client)
+	} else if args.Bool["approval"] {
+		return runDeployApproval(args, client)
+	} else if args.Bool["approve"] {
+		return runControlDeployment(args, client, func(id string) (*ct.Deployment, error) {
+			return client.ApproveDeployment(id, args.String["--comment"])
+		}, "approved")
+	} else if args.Bool["reject"] {
+		return runControlDeployment(args, client, func(id string) (*ct.Deployment, error) {
+			return client.RejectDeployment(id, args.String["--comment"])
+		}, "rejected")
 	}
 
 	deployments, err := client.DeploymentList(mustApp())
@@ -66,9 +175,9 @@ func runDeployments(args *docopt.Args, client controller.Client) error {
 	w := tabWriter()
 	defer w.Flush()
 
-	listRec(w, "ID", "STATUS", "CREATED", "FINISHED")
+	listRec(w, "ID", "STATUS", "CREATED", "FINISHED", "ROLLBACK REASON")
 	for _, d := range deployments {
-		listRec(w, d.ID, d.Status, humanTime(d.CreatedAt), humanTime(d.FinishedAt))
+		listRec(w, d.ID, d.Status, humanTime(d.CreatedAt), humanTime(d.FinishedAt), d.RollbackReason)
 	}
 	return nil
 }
@@ -116,3 +225,204 @@ func runSetDeployBatchSize(args *docopt.Args, client controller.Client) error {
 	app.SetDeployBatchSize(batchSize)
 	return client.UpdateApp(app)
 }
+
+func runGetDeployWarmPeriod(args *docopt.Args, client controller.Client) error {
+	app, err := client.GetApp(mustApp())
+	if err != nil {
+		return err
+	}
+	fmt.Println(app.DeployWarmPeriod())
+	return nil
+}
+
+func runSetDeployWarmPeriod(args *docopt.Args, client controller.Client) error {
+	seconds, err := strconv.Atoi(args.String["<seconds>"])
+	if err != nil {
+		return fmt.Errorf("error parsing warm-period %q: %s", args.String["<seconds>"], err)
+	}
+	if seconds < 0 {
+		return fmt.Errorf("warm-period must not be negative")
+	}
+	app, err := client.GetApp(mustApp())
+	if err != nil {
+		return err
+	}
+	// update the existing meta so that other app settings are preserved
+	app.SetDeployWarmPeriod(seconds)
+	return client.UpdateApp(&ct.App{ID: app.ID, Meta: app.Meta})
+}
+
+func runDeployCanary(args *docopt.Args, client controller.Client) error {
+	app, err := client.GetApp(mustApp())
+	if err != nil {
+		return err
+	}
+	config := app.DeployCanary()
+
+	update := false
+	for _, opt := range []struct {
+		name string
+		val  *int
+	}{
+		{"--count", &config.Count},
+		{"--weight", &config.Weight},
+		{"--window", &config.Window},
+	} {
+		s := args.String[opt.name]
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("error parsing %s %q: %s", opt.name, s, err)
+		}
+		*opt.val = n
+		update = true
+	}
+	if !update {
+		w := tabWriter()
+		defer w.Flush()
+		listRec(w, "COUNT", "WEIGHT", "WINDOW")
+		listRec(w, config.Count, fmt.Sprintf("%d%%", config.Weight), fmt.Sprintf("%ds", config.Window))
+		return nil
+	}
+
+	if config.Count < 1 {
+		return fmt.Errorf("count must be at least 1")
+	}
+	if config.Weight < 0 || config.Weight > 100 {
+		return fmt.Errorf("weight must be between 0 and 100")
+	}
+	if config.Window < 0 {
+		return fmt.Errorf("window must not be negative")
+	}
+	// update the existing meta so that other app settings are preserved
+	app.SetDeployCanary(config)
+	return client.UpdateApp(&ct.App{ID: app.ID, Meta: app.Meta})
+}
+
+// runControlDeployment pauses, resumes, aborts, approves or rejects the given
+// deployment, or the app's running deployment if none is given
+func runControlDeployment(args *docopt.Args, client controller.Client, control func(string) (*ct.Deployment, error), msg string) error {
+	id := args.String["<deployment>"]
+	if id == "" {
+		deployments, err := client.DeploymentList(mustApp())
+		if err != nil {
+			return err
+		}
+		for _, d := range deployments {
+			if d.FinishedAt == nil {
+				id = d.ID
+				break
+			}
+		}
+		if id == "" {
+			return errors.New("no running deployment")
+		}
+	}
+	if _, err := control(id); err != nil {
+		return err
+	}
+	fmt.Printf("deployment %s %s\n", id, msg)
+	return nil
+}
+
+func runDeployRollback(args *docopt.Args, client controller.Client) error {
+	app, err := client.GetApp(mustApp())
+	if err != nil {
+		return err
+	}
+	config := app.DeployRollback()
+
+	update := false
+	for _, opt := range []struct {
+		name string
+		val  *int
+	}{
+		{"--bake-period", &config.BakePeriod},
+		{"--max-crashes", &config.MaxCrashes},
+		{"--max-health-failures", &config.MaxHealthFailures},
+	} {
+		s := args.String[opt.name]
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("error parsing %s %q: %s", opt.name, s, err)
+		}
+		if n < 0 {
+			return fmt.Errorf("%s must not be negative", opt.name)
+		}
+		*opt.val = n
+		update = true
+	}
+	if s := args.String["--max-error-rate"]; s != "" {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("error parsing --max-error-rate %q: %s", s, err)
+		}
+		if f < 0 || f > 100 {
+			return fmt.Errorf("--max-error-rate must be between 0 and 100")
+		}
+		config.MaxErrorRate = f
+		update = true
+	}
+	if !update {
+		w := tabWriter()
+		defer w.Flush()
+		listRec(w, "BAKE PERIOD", "MAX ERROR RATE", "MAX CRASHES", "MAX HEALTH FAILURES")
+		listRec(w, fmt.Sprintf("%ds", config.BakePeriod), fmt.Sprintf("%g%%", config.MaxErrorRate), config.MaxCrashes, config.MaxHealthFailures)
+		return nil
+	}
+
+	// update the existing meta so that other app settings are preserved
+	app.SetDeployRollback(config)
+	return client.UpdateApp(&ct.App{ID: app.ID, Meta: app.Meta})
+}
+
+func runDeployApproval(args *docopt.Args, client controller.Client) error {
+	app, err := client.GetApp(mustApp())
+	if err != nil {
+		return err
+	}
+
+	if args.Bool["--disable"] {
+		// update the existing meta so that other app settings are preserved
+		app.SetDeployApproval(nil)
+		return client.UpdateApp(&ct.App{ID: app.ID, Meta: app.Meta})
+	}
+
+	webhook, timeout := args.String["--webhook"], args.String["--timeout"]
+	config := app.DeployApproval()
+	if webhook == "" && timeout == "" {
+		if config == nil {
+			fmt.Println("deploy approval is not enabled")
+			return nil
+		}
+		w := tabWriter()
+		defer w.Flush()
+		listRec(w, "WEBHOOK", "TIMEOUT", "SECRET")
+		listRec(w, config.WebhookURL, fmt.Sprintf("%ds", config.Timeout), config.Secret)
+		return nil
+	}
+
+	if config == nil {
+		config = &ct.ApprovalConfig{Timeout: ct.DefaultDeployApprovalTimeout}
+	}
+	if webhook != "" {
+		config.WebhookURL = webhook
+	}
+	if timeout != "" {
+		n, err := strconv.Atoi(timeout)
+		if err != nil {
+			return fmt.Errorf("error parsing --timeout %q: %s", timeout, err)
+		}
+		if n < 1 {
+			return errors.New("--timeout must be at least 1")
+		}
+		config.Timeout = n
+	}
+	app.SetDeployApproval(config)
+	return client.UpdateApp(&ct.App{ID: app.ID, Meta: app.Meta})
+}
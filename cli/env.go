@@ -15,21 +15,28 @@ import (
 func init() {
 	register("env", runEnv, `
 usage: flynn env [-t <proc>]
-       flynn env set [-t <proc>] <var>=<val>...
+       flynn env set [-t <proc>] [--secret] <var>=<val>...
        flynn env unset [-t <proc>] <var>...
        flynn env get [-t <proc>] <var>
+       flynn env secret <var>...
 
 Manage app environment variables.
 
+Secret variables are encrypted by the controller before being stored and are
+only decrypted when starting jobs. Their values are not shown when listing
+variables, and reading them with "flynn env get" requires the deploy role.
+
 Options:
 	-t, --process-type=<proc>  set or read env for specified process type
+	--secret                   store the variables as encrypted secrets
 
 Commands:
 	With no arguments, shows a list of environment variables.
 
-	set    sets value of one or more env variables
-	unset  deletes one or more variables
-	get    returns the value of variable
+	set     sets value of one or more env variables
+	unset   deletes one or more variables
+	get     returns the value of variable
+	secret  encrypts the values of one or more existing variables
 
 Examples:
 
@@ -43,6 +50,14 @@ Examples:
 	$ flynn env get -t web FOO
 	bar
 
+	$ flynn env set --secret DATABASE_PASSWORD=hunter2
+	Created release 9a4c1b0e2d7f4f5c8e6b3a2d1c0f9e8d.
+
+	$ flynn env
+	BAZ=foobar
+	DATABASE_PASSWORD=<secret>
+	FOO=bar
+
 	$ flynn env unset FOO
 	Created release b1bbd9bc76d6436ea2fd245300bce72e.
 `)
@@ -59,6 +74,8 @@ func runEnv(args *docopt.Args, client controller.Client) error {
 		return runEnvUnset(args, client)
 	} else if args.Bool["get"] {
 		return runEnvGet(args, client)
+	} else if args.Bool["secret"] {
+		return runEnvSecret(args, client)
 	}
 
 	release, err := client.GetAppRelease(mustApp())
@@ -80,6 +97,9 @@ func runEnv(args *docopt.Args, client controller.Client) error {
 
 	vars := make([]string, 0, len(release.Env))
 	for k, v := range release.Env {
+		if isSecret(release, k) {
+			v = "<secret>"
+		}
 		vars = append(vars, k+"="+v)
 	}
 	sort.Strings(vars)
@@ -100,7 +120,16 @@ func runEnvSet(args *docopt.Args, client controller.Client) error {
 		}
 		env[v[0]] = &v[1]
 	}
-	id, err := setEnv(client, envProc, env)
+	var secret []string
+	if args.Bool["--secret"] {
+		if envProc != "" {
+			return errors.New("secret variables cannot be set for a process type")
+		}
+		for k := range env {
+			secret = append(secret, k)
+		}
+	}
+	id, err := setEnv(client, envProc, env, secret...)
 	if err != nil {
 		return err
 	}
@@ -122,6 +151,16 @@ func runEnvUnset(args *docopt.Args, client controller.Client) error {
 	return nil
 }
 
+func runEnvSecret(args *docopt.Args, client controller.Client) error {
+	vars := args.All["<var>"].([]string)
+	id, err := setEnv(client, "", nil, vars...)
+	if err != nil {
+		return err
+	}
+	log.Printf("Created release %s.", id)
+	return nil
+}
+
 func runEnvGet(args *docopt.Args, client controller.Client) error {
 	arg := args.All["<var>"].([]string)[0]
 	release, err := client.GetAppRelease(mustApp())
@@ -142,6 +181,13 @@ func runEnvGet(args *docopt.Args, client controller.Client) error {
 	}
 
 	if v, ok := release.Env[arg]; ok {
+		if isSecret(release, arg) {
+			release, err = client.GetAppReleaseWithSecrets(release.AppID)
+			if err != nil {
+				return err
+			}
+			v = release.Env[arg]
+		}
 		fmt.Println(v)
 		return nil
 	}
@@ -149,7 +195,19 @@ func runEnvGet(args *docopt.Args, client controller.Client) error {
 	return fmt.Errorf("var %q not found in release %q", arg, release.ID)
 }
 
-func setEnv(client controller.Client, proc string, env map[string]*string) (string, error) {
+func isSecret(release *ct.Release, name string) bool {
+	for _, s := range release.Secrets {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// setEnv creates and deploys a new release with the given env changes,
+// marking the given variables as secret. Existing secrets are left encrypted
+// and are stored unchanged in the new release.
+func setEnv(client controller.Client, proc string, env map[string]*string, secret ...string) (string, error) {
 	app, err := client.GetApp(mustApp())
 	if err != nil {
 		return "", err
@@ -189,6 +247,14 @@ func setEnv(client controller.Client, proc string, env map[string]*string) (stri
 			dest[k] = *v
 		}
 	}
+	for _, k := range secret {
+		if _, ok := release.Env[k]; !ok {
+			return "", fmt.Errorf("var %q not found in release %s", k, release.ID)
+		}
+		if !isSecret(release, k) {
+			release.Secrets = append(release.Secrets, k)
+		}
+	}
 
 	release.ID = ""
 	if err := client.CreateRelease(app.ID, release); err != nil {
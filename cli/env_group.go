@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/flynn/flynn/controller/client"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/go-docopt"
+)
+
+func init() {
+	register("env-group", runEnvGroup, `
+usage: flynn env-group [list]
+       flynn env-group create [--auto-deploy=<bool>] <name> [<var>=<val>...]
+       flynn env-group show <name>
+       flynn env-group set [--auto-deploy=<bool>] <name> [<var>=<val>...]
+       flynn env-group unset <name> <var>...
+       flynn env-group delete <name>
+       flynn env-group attach <name>
+       flynn env-group detach <name>
+
+Manage env groups shared by multiple apps.
+
+When an env group changes, or is attached to or detached from an app, a new
+release is created for each affected app with the group's env, which is
+deployed if the group has auto deploy enabled (otherwise the releases must be
+deployed with "flynn release rollback" or by the next deploy).
+
+Env vars set directly on an app take precedence over env groups, and groups
+attached later take precedence over those attached earlier. The groups used
+for a release are recorded in its "flynn-env-groups" metadata, and the
+precedence in its "flynn-env-precedence" metadata.
+
+Options:
+	--auto-deploy=<bool>  whether to deploy the releases created when the group changes
+
+Commands:
+	With no arguments, shows a list of env groups.
+
+	create  creates an env group
+	show    shows the env of an env group
+	set     sets the value of one or more variables
+	unset   deletes one or more variables
+	delete  deletes an env group, detaching it from all apps
+	attach  attaches an env group to the app
+	detach  detaches an env group from the app
+
+Examples:
+
+	$ flynn env-group create --auto-deploy=true shared FEATURE_X=true API_URL=https://api.example.com
+	Created env group shared.
+
+	$ flynn -a myapp env-group attach shared
+	Created release 5058ae7964f74c399a240bdd6e7d1bcb for app myapp (deployment 3a8b1c6e2f5d4e7a9b0c1d2e3f4a5b6c).
+
+	$ flynn env-group set shared FEATURE_X=false
+	Created release b1bbd9bc76d6436ea2fd245300bce72e for app myapp (deployment 7c2d4e6f8a0b4c1d9e3f5a7b9c1d3e5f).
+
+	$ flynn env-group
+	NAME    APPS   AUTO DEPLOY  UPDATED
+	shared  myapp  true         1 minute ago
+`)
+}
+
+func runEnvGroup(args *docopt.Args, client controller.Client) error {
+	switch {
+	case args.Bool["create"]:
+		return runEnvGroupCreate(args, client)
+	case args.Bool["show"]:
+		return runEnvGroupShow(args, client)
+	case args.Bool["set"]:
+		return runEnvGroupSet(args, client)
+	case args.Bool["unset"]:
+		return runEnvGroupUnset(args, client)
+	case args.Bool["delete"]:
+		update, err := client.DeleteEnvGroup(args.String["<name>"])
+		if err != nil {
+			return err
+		}
+		return printEnvGroupUpdate(update, client)
+	case args.Bool["attach"]:
+		update, err := client.AttachEnvGroup(mustApp(), args.String["<name>"])
+		if err != nil {
+			return err
+		}
+		return printEnvGroupUpdate(update, client)
+	case args.Bool["detach"]:
+		update, err := client.DetachEnvGroup(mustApp(), args.String["<name>"])
+		if err != nil {
+			return err
+		}
+		return printEnvGroupUpdate(update, client)
+	}
+	return runEnvGroupList(client)
+}
+
+func appNames(client controller.Client) (map[string]string, error) {
+	apps, err := client.AppList()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(apps))
+	for _, app := range apps {
+		names[app.ID] = app.Name
+	}
+	return names, nil
+}
+
+func runEnvGroupList(client controller.Client) error {
+	groups, err := client.ListEnvGroups()
+	if err != nil {
+		return err
+	}
+	names, err := appNames(client)
+	if err != nil {
+		return err
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	listRec(w, "NAME", "APPS", "AUTO DEPLOY", "UPDATED")
+	for _, g := range groups {
+		apps := make([]string, len(g.Apps))
+		for i, id := range g.Apps {
+			if name, ok := names[id]; ok {
+				apps[i] = name
+			} else {
+				apps[i] = id
+			}
+		}
+		listRec(w, g.Name, strings.Join(apps, ","), g.AutoDeploy, humanTime(g.UpdatedAt))
+	}
+	return nil
+}
+
+func parseEnvPairs(pairs []string) (map[string]string, error) {
+	env := make(map[string]string, len(pairs))
+	for _, s := range pairs {
+		v := strings.SplitN(s, "=", 2)
+		if len(v) != 2 {
+			return nil, fmt.Errorf("invalid var format: %q", s)
+		}
+		env[v[0]] = v[1]
+	}
+	return env, nil
+}
+
+func runEnvGroupCreate(args *docopt.Args, client controller.Client) error {
+	env, err := parseEnvPairs(args.All["<var>=<val>"].([]string))
+	if err != nil {
+		return err
+	}
+	group := &ct.EnvGroup{Name: args.String["<name>"], Env: env}
+	if group.AutoDeploy, err = parseAutoDeploy(args, false); err != nil {
+		return err
+	}
+	if err := client.CreateEnvGroup(group); err != nil {
+		return err
+	}
+	log.Printf("Created env group %s.", group.Name)
+	return nil
+}
+
+func runEnvGroupShow(args *docopt.Args, client controller.Client) error {
+	group, err := client.GetEnvGroup(args.String["<name>"])
+	if err != nil {
+		return err
+	}
+	vars := make([]string, 0, len(group.Env))
+	for k, v := range group.Env {
+		vars = append(vars, k+"="+v)
+	}
+	sort.Strings(vars)
+	for _, v := range vars {
+		fmt.Println(v)
+	}
+	return nil
+}
+
+func runEnvGroupSet(args *docopt.Args, client controller.Client) error {
+	env, err := parseEnvPairs(args.All["<var>=<val>"].([]string))
+	if err != nil {
+		return err
+	}
+	return updateEnvGroup(args, client, func(group *ct.EnvGroup) error {
+		var err error
+		if group.AutoDeploy, err = parseAutoDeploy(args, group.AutoDeploy); err != nil {
+			return err
+		}
+		for k, v := range env {
+			group.Env[k] = v
+		}
+		return nil
+	})
+}
+
+// parseAutoDeploy returns the value of the --auto-deploy flag, or def if it is
+// not set
+func parseAutoDeploy(args *docopt.Args, def bool) (bool, error) {
+	s := args.String["--auto-deploy"]
+	if s == "" {
+		return def, nil
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for --auto-deploy: %q", s)
+	}
+	return v, nil
+}
+
+func runEnvGroupUnset(args *docopt.Args, client controller.Client) error {
+	return updateEnvGroup(args, client, func(group *ct.EnvGroup) error {
+		for _, k := range args.All["<var>"].([]string) {
+			delete(group.Env, k)
+		}
+		return nil
+	})
+}
+
+func updateEnvGroup(args *docopt.Args, client controller.Client, change func(*ct.EnvGroup) error) error {
+	group, err := client.GetEnvGroup(args.String["<name>"])
+	if err != nil {
+		return err
+	}
+	if group.Env == nil {
+		group.Env = make(map[string]string)
+	}
+	if err := change(group); err != nil {
+		return err
+	}
+	update, err := client.UpdateEnvGroup(group)
+	if err != nil {
+		return err
+	}
+	return printEnvGroupUpdate(update, client)
+}
+
+func printEnvGroupUpdate(update *ct.EnvGroupUpdate, client controller.Client) error {
+	if len(update.Releases) == 0 {
+		log.Printf("No apps affected.")
+		return nil
+	}
+	names, err := appNames(client)
+	if err != nil {
+		return err
+	}
+	var failed bool
+	for _, r := range update.Releases {
+		app := names[r.AppID]
+		switch {
+		case r.ReleaseID == "":
+			log.Printf("Error creating release for app %s: %s", app, r.Error)
+			failed = true
+		case r.Error != "":
+			log.Printf("Created release %s for app %s, but deploying it failed: %s", r.ReleaseID, app, r.Error)
+			failed = true
+		case r.DeploymentID != "":
+			log.Printf("Created release %s for app %s (deployment %s).", r.ReleaseID, app, r.DeploymentID)
+		default:
+			log.Printf("Created release %s for app %s.", r.ReleaseID, app)
+		}
+	}
+	if failed {
+		return fmt.Errorf("not all apps were updated")
+	}
+	return nil
+}
@@ -102,7 +102,9 @@ func runExport(args *docopt.Args, client controller.Client) error {
 		return fmt.Errorf("error exporting routes: %s", err)
 	}
 
-	release, err := client.GetAppRelease(mustApp())
+	// secrets are exported decrypted since they are bound to the app they
+	// belong to, and are encrypted again for the new app when imported
+	release, err := client.GetAppReleaseWithSecrets(mustApp())
 	if err == controller.ErrNotFound {
 		// if the app has no release then there is nothing more to export
 		return nil
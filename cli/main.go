@@ -45,13 +45,16 @@ Commands:
 	delete      delete an app
 	apps        list apps
 	info        show app information
+	apply       apply an app manifest
 	ps          list jobs
 	kill        kill jobs
 	log         get app log
 	scale       change formation
 	run         run a job
 	env         manage env variables
+	env-group   manage env groups shared by apps
 	limit       manage resource limits
+	quota       manage app quotas
 	meta        manage app metadata
 	route       manage routes
 	pg          manage postgres database
@@ -65,6 +68,9 @@ Commands:
 	release     manage app releases
 	deployment  list deployments
 	volume      manage volumes
+	token       manage API tokens
+	audit       show the audit log
+	webhook     manage webhooks
 	export      export app data
 	import      create app from exported data
 	version     show flynn version
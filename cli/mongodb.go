@@ -56,7 +56,7 @@ func runMongodb(args *docopt.Args, client controller.Client) error {
 }
 
 func getAppMongodbRunConfig(client controller.Client) (*runConfig, error) {
-	appRelease, err := client.GetAppRelease(mustApp())
+	appRelease, err := client.GetAppReleaseWithSecrets(mustApp())
 	if err != nil {
 		return nil, fmt.Errorf("error getting app release: %s", err)
 	}
@@ -57,7 +57,7 @@ func runMysql(args *docopt.Args, client controller.Client) error {
 }
 
 func getAppMysqlRunConfig(client controller.Client) (*runConfig, error) {
-	appRelease, err := client.GetAppRelease(mustApp())
+	appRelease, err := client.GetAppReleaseWithSecrets(mustApp())
 	if err != nil {
 		return nil, fmt.Errorf("error getting app release: %s", err)
 	}
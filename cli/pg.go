@@ -58,7 +58,7 @@ func runPg(args *docopt.Args, client controller.Client) error {
 }
 
 func getAppPgRunConfig(client controller.Client) (*runConfig, error) {
-	appRelease, err := client.GetAppRelease(mustApp())
+	appRelease, err := client.GetAppReleaseWithSecrets(mustApp())
 	if err != nil {
 		return nil, fmt.Errorf("error getting app release: %s", err)
 	}
@@ -18,8 +18,12 @@ usage: flynn ps [-a] [-c] [-q] [-t <type>]
 
 List flynn jobs.
 
+Jobs of process types which have stopped being restarted because they exceeded
+the restart budget of their restart policy are shown as crash looping, and are
+started again when the process type is next scaled.
+
 Options:
-  -a, --all           Show all jobs (default is running and pending)
+  -a, --all           Show all jobs (default is running, pending and blocked)
   -c, --command       Show command
   -q, --quiet         Only display IDs
   -t, --type=<type>   Show jobs of type <type>
@@ -45,6 +49,11 @@ Example:
        host0-0f34548b-72fa-41fe-a425-abc4ac6a3857
        host0-129b821f-3195-4b3b-b04b-669196cfbb03
 
+       $ flynn ps
+       ID                                          TYPE    STATE                                            CREATED        RELEASE
+       host0-52aedfbf-e613-40f2-941a-d832d10fc400  web     up                                               2 minutes ago  cf39a906-38d1-4393-a6b1-8ad2befe8142
+       host0-9a1d4c3b-0a35-4c8e-bd5e-0c1f2c3a4b5d  worker  down (crash looping: restarted 5 times in 5m0s)  5 seconds ago  cf39a906-38d1-4393-a6b1-8ad2befe8142
+
        $ flynn ps --all --type=run
        ID                                          TYPE  STATE  CREATED             RELEASE
        host0-129b821f-3195-4b3b-b04b-669196cfbb03  run   down   5 seconds ago       cf39a906-38d1-4393-a6b1-8ad2befe842
@@ -66,8 +75,17 @@ func runPs(args *docopt.Args, client controller.Client) error {
 		}
 		listRec(w, headers...)
 	}
+
+	// crash looping jobs are shown by default if they are the most recent
+	// job of their release and process type
+	latest := make(map[string]*ct.Job, len(jobs))
+	for _, j := range jobs {
+		latest[j.ReleaseID+"|"+j.Type] = j
+	}
+
 	for _, j := range jobs {
-		if !args.Bool["--all"] && j.State != ct.JobStateUp && j.State != ct.JobStatePending {
+		crashLooping := j.CrashLoopReason != nil && latest[j.ReleaseID+"|"+j.Type] == j
+		if !args.Bool["--all"] && j.State != ct.JobStateUp && j.State != ct.JobStatePending && j.State != ct.JobStateBlocked && !crashLooping {
 			continue
 		}
 		if j.Type == "" {
@@ -88,7 +106,13 @@ func runPs(args *docopt.Args, client controller.Client) error {
 		if j.CreatedAt != nil {
 			created = units.HumanDuration(time.Now().UTC().Sub(*j.CreatedAt)) + " ago"
 		}
-		fields := []interface{}{id, j.Type, j.State, created, j.ReleaseID}
+		state := string(j.State)
+		if j.State == ct.JobStateBlocked && j.BlockedReason != nil {
+			state = fmt.Sprintf("%s (%s)", state, *j.BlockedReason)
+		} else if j.CrashLoopReason != nil {
+			state = fmt.Sprintf("%s (crash looping: %s)", state, *j.CrashLoopReason)
+		}
+		fields := []interface{}{id, j.Type, state, created, j.ReleaseID}
 		if args.Bool["--command"] {
 			fields = append(fields, strings.Join(j.Args, " "))
 		}
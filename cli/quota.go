@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/flynn/flynn/controller/client"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/host/resource"
+	"github.com/flynn/go-docopt"
+)
+
+func init() {
+	register("quota", runQuota, `
+usage: flynn quota
+       flynn quota set <var>=<val>...
+
+Manage app quotas.
+
+Quotas limit the total resources used by an app's jobs and routes, and are
+checked when scaling the app, running jobs and adding routes. A limit of 0
+means no limit.
+
+The limits are:
+
+	jobs     number of formation and one-off jobs
+	memory   total memory of jobs (e.g. 4GB)
+	cpu      total milliCPU of jobs (e.g. 2000 for two CPU cores)
+	volumes  number of volumes used by formation jobs
+	routes   number of HTTP and TCP routes
+
+Only unrestricted admin tokens and controller keys can change quotas.
+
+Commands:
+	With no arguments, shows the app's quota and usage.
+
+	set    sets the value of one or more limits
+
+Examples:
+
+	$ flynn quota set jobs=10 memory=4GB routes=5
+	$ flynn quota
+	RESOURCE  USAGE  LIMIT
+	jobs      3      10
+	memory    3GB    4GB
+	cpu       3000   unlimited
+	volumes   0      unlimited
+	routes    1      5
+`)
+}
+
+func runQuota(args *docopt.Args, client controller.Client) error {
+	if args.Bool["set"] {
+		return runQuotaSet(args, client)
+	}
+
+	quota, err := client.GetAppQuota(mustApp())
+	if err != nil {
+		return err
+	}
+	var usage ct.QuotaResources
+	if quota.Usage != nil {
+		usage = *quota.Usage
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	formatMemory := func(v int64) string { return resource.FormatLimit(resource.TypeMemory, v) }
+	listRec(w, "RESOURCE", "USAGE", "LIMIT")
+	for _, r := range []struct {
+		name         string
+		usage, limit int64
+		format       func(int64) string
+	}{
+		{"jobs", usage.Jobs, quota.Limits.Jobs, nil},
+		{"memory", usage.Memory, quota.Limits.Memory, formatMemory},
+		{"cpu", usage.CPU, quota.Limits.CPU, nil},
+		{"volumes", usage.Volumes, quota.Limits.Volumes, nil},
+		{"routes", usage.Routes, quota.Limits.Routes, nil},
+	} {
+		format := r.format
+		if format == nil {
+			format = func(v int64) string { return strconv.FormatInt(v, 10) }
+		}
+		limit := "unlimited"
+		if r.limit > 0 {
+			limit = format(r.limit)
+		}
+		listRec(w, r.name, format(r.usage), limit)
+	}
+	return nil
+}
+
+func runQuotaSet(args *docopt.Args, client controller.Client) error {
+	quota, err := client.GetAppQuota(mustApp())
+	if err != nil {
+		return err
+	}
+	quota.Usage = nil
+
+	for _, s := range args.All["<var>=<val>"].([]string) {
+		v := strings.SplitN(s, "=", 2)
+		if len(v) != 2 {
+			return fmt.Errorf("invalid var format: %q", s)
+		}
+		var n int64
+		if v[0] == "memory" {
+			n, err = resource.ParseLimit(resource.TypeMemory, v[1])
+		} else {
+			n, err = strconv.ParseInt(v[1], 10, 64)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %q", v[0], v[1])
+		}
+		switch v[0] {
+		case "jobs":
+			quota.Limits.Jobs = n
+		case "memory":
+			quota.Limits.Memory = n
+		case "cpu":
+			quota.Limits.CPU = n
+		case "volumes":
+			quota.Limits.Volumes = n
+		case "routes":
+			quota.Limits.Routes = n
+		default:
+			return fmt.Errorf("unknown quota limit %q", v[0])
+		}
+	}
+	return client.UpdateAppQuota(quota)
+}
@@ -54,7 +54,7 @@ func runRedis(args *docopt.Args, client controller.Client) error {
 }
 
 func getAppRedisRunConfig(client controller.Client) (*runConfig, error) {
-	appRelease, err := client.GetAppRelease(mustApp())
+	appRelease, err := client.GetAppReleaseWithSecrets(mustApp())
 	if err != nil {
 		return nil, fmt.Errorf("error getting app release: %s", err)
 	}
@@ -1,26 +1,34 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/flynn/flynn/controller/client"
+	"github.com/flynn/flynn/controller/secrets"
 	ct "github.com/flynn/flynn/controller/types"
+	hh "github.com/flynn/flynn/pkg/httphelper"
 	"github.com/flynn/go-docopt"
 )
 
 func init() {
 	register("resource", runResource, `
 usage: flynn resource
-       flynn resource add <provider>
+       flynn resource add [--unencrypted] <provider>
        flynn resource remove <provider> [<resource>]
 
 Manage resources for the app.
 
+Options:
+       --unencrypted  store the resource env as plain env vars if the controller has no secrets keys
+
 Commands:
        With no arguments, shows a list of resources.
 
-       add     provisions a new resource for the app using <provider>.
+       add     provisions a new resource for the app using <provider>, setting its env as secret env vars.
        remove  removes the existing <resource> provided by <provider>, resolves <resource> automatically if unambigious.
 `)
 }
@@ -64,12 +72,26 @@ func runResourceAdd(args *docopt.Args, client controller.Client) error {
 	}
 
 	env := make(map[string]*string)
+	keys := make([]string, 0, len(res.Env))
 	for k, v := range res.Env {
 		s := v
 		env[k] = &s
+		keys = append(keys, k)
 	}
 
-	releaseID, err := setEnv(client, "", env)
+	// resource env contains credentials, so is stored as secret env vars,
+	// only falling back to plain env vars if explicitly requested when
+	// the cluster has no keys to encrypt them with
+	releaseID, err := setEnv(client, "", env, keys...)
+	if isNoKeyringError(err) {
+		if !args.Bool["--unencrypted"] {
+			if _, delErr := client.DeleteResource(provider, res.ID); delErr != nil {
+				log.Printf("WARNING: error removing resource %s: %s", res.ID, delErr)
+			}
+			return errNoKeyring
+		}
+		releaseID, err = setEnv(client, "", env)
+	}
 	if err != nil {
 		return err
 	}
@@ -79,6 +101,23 @@ func runResourceAdd(args *docopt.Args, client controller.Client) error {
 	return nil
 }
 
+var errNoKeyring = errors.New("the controller has no secrets keys to encrypt the resource env with, so the resource was removed (run again with --unencrypted to store its env as plain env vars)")
+
+// isNoKeyringError returns whether the error is the validation error returned
+// by the controller when secret env vars cannot be encrypted because it has
+// no secrets keys
+func isNoKeyringError(err error) bool {
+	e, ok := err.(hh.JSONError)
+	if !ok || e.Code != hh.ValidationErrorCode {
+		return false
+	}
+	var detail ct.ValidationError
+	if err := json.Unmarshal(e.Detail, &detail); err != nil || !strings.HasPrefix(detail.Field, "env.") {
+		return false
+	}
+	return e.Message == fmt.Sprintf("%s %s", detail.Field, secrets.ErrNoKeyring)
+}
+
 func runResourceRemove(args *docopt.Args, client controller.Client) error {
 	provider := args.String["<provider>"]
 	resource := args.String["<resource>"]
@@ -96,7 +135,9 @@ func runResourceRemove(args *docopt.Args, client controller.Client) error {
 		return err
 	}
 
-	release, err := client.GetAppRelease(mustApp())
+	// compare against the decrypted env as the resource's vars are
+	// normally secret
+	release, err := client.GetAppReleaseWithSecrets(mustApp())
 	if err != nil {
 		return err
 	}
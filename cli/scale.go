@@ -32,6 +32,7 @@ Omitting the arguments will show the current scale.
 
 Options:
 	-n, --no-wait            don't wait for the scaling events to happen
+	-d, --dry-run            show the jobs the scheduler would start and stop without scaling
 	-r, --release=<release>  id of release to scale (defaults to current app release)
 	-a, --all                show non-zero formations from all releases (only works when listing formations, can't be combined with --release)
 
@@ -57,6 +58,13 @@ Example:
 	02:28:37.601 ==> worker flynn-e24760c511af4733b01ed5b98aa54647 up
 
 	scale completed in 3.944629056s
+
+	$ flynn scale --dry-run web=3,disk=ssd
+	ACTION  TYPE  JOB                                         HOST   REASON
+	start   web                                               host1
+	start   web                                               host3
+	stop    web   host2-8d39cd91-2d44-4c1a-8d3d-1ad1e4e33bd7  host2  job tags do not match host
+	block   web                                                      no hosts found with capacity for job resource requests (requested memory=1GB)
 `)
 }
 
@@ -139,6 +147,10 @@ func runScale(args *docopt.Args, client controller.Client) error {
 		NoWait:    args.Bool["--no-wait"],
 	}
 
+	if args.Bool["--dry-run"] {
+		return runScaleDryRun(client, app, release, opts)
+	}
+
 	status, err := client.Status()
 	if err != nil {
 		return err
@@ -182,6 +194,32 @@ func runScaleWithScaleRequest(client controller.Client, app string, release *ct.
 	return nil
 }
 
+func runScaleDryRun(client controller.Client, app string, release *ct.Release, opts ct.ScaleOptions) error {
+	res, err := client.ScaleAppReleaseDryRun(app, release.ID, opts)
+	if err != nil {
+		return err
+	}
+	if len(res.Placements) == 0 && len(res.Blocked) == 0 && len(res.Stopped) == 0 {
+		fmt.Println("requested scale equals current scale, nothing to do!")
+		return nil
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	listRec(w, "ACTION", "TYPE", "JOB", "HOST", "REASON")
+	for _, job := range res.Placements {
+		listRec(w, "start", job.Type, job.ID, job.HostID, job.Reason)
+	}
+	for _, job := range res.Stopped {
+		listRec(w, "stop", job.Type, job.ID, job.HostID, job.Reason)
+	}
+	for _, job := range res.Blocked {
+		listRec(w, "block", job.Type, job.ID, job.HostID, job.Reason)
+	}
+	return nil
+}
+
 func runScaleWithJobEvents(client controller.Client, app string, release *ct.Release, opts ct.ScaleOptions) error {
 	processes := opts.Processes
 	tags := opts.Tags
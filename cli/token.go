@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flynn/flynn/controller/client"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/go-docopt"
+)
+
+func init() {
+	register("token", runToken, `
+usage: flynn token
+       flynn token create <name> [--role=<role>] [--app=<app>]... [--resource=<type>]...
+       flynn token revoke <id>
+
+Manage API tokens.
+
+API tokens authenticate with the controller in the same way as the cluster's
+auth key, but are limited to the operations permitted by their role, apps and
+resource types, so they can be given to CI systems and other users without
+granting full access to the cluster.
+
+Options:
+	-r, --role=<role>      role of the token, one of "read" (read only access), "deploy"
+	                       (may also create releases and deployments and scale formations)
+	                       or "admin" (full access) [default: read]
+	--app=<app>            restrict the token to the given app (may be repeated)
+	--resource=<type>      restrict the token to the given resource type (may be repeated),
+	                       one of apps, artifacts, releases, formations, deployments, jobs,
+	                       routes, resources, volumes, schedules, autoscale, events, sinks,
+	                       webhooks or env_groups
+
+Commands:
+	With no arguments, shows a list of API tokens.
+
+	create  creates an API token, printing its key (which cannot be retrieved later)
+	revoke  revokes an API token so that it can no longer be used
+
+Examples:
+
+	$ flynn token create ci --role deploy --app myapp
+	Created token ci (3e1f2a8c-0d5b-4c8e-9f7a-6b2d1c0e9a4f) with key:
+	8f14e45fceea167a5a36dedd4bea2543bd6fc13cbd5f9ba3d1b6c44a9f0c7e21
+
+	$ flynn token
+	ID                                    NAME  ROLE    APPS   RESOURCE TYPES  CREATED
+	3e1f2a8c-0d5b-4c8e-9f7a-6b2d1c0e9a4f  ci    deploy  myapp                  2 minutes ago
+
+	$ flynn token revoke 3e1f2a8c-0d5b-4c8e-9f7a-6b2d1c0e9a4f
+`)
+}
+
+func runToken(args *docopt.Args, client controller.Client) error {
+	if args.Bool["create"] {
+		return runTokenCreate(args, client)
+	} else if args.Bool["revoke"] {
+		_, err := client.DeleteToken(args.String["<id>"])
+		return err
+	}
+	return runTokenList(client)
+}
+
+func runTokenList(client controller.Client) error {
+	tokens, err := client.ListTokens()
+	if err != nil {
+		return err
+	}
+
+	// tokens store app IDs, so look up the app names to display
+	apps, err := client.AppList()
+	if err != nil {
+		return err
+	}
+	appNames := make(map[string]string, len(apps))
+	for _, app := range apps {
+		appNames[app.ID] = app.Name
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	listRec(w, "ID", "NAME", "ROLE", "APPS", "RESOURCE TYPES", "CREATED")
+	for _, t := range tokens {
+		names := make([]string, len(t.Apps))
+		for i, id := range t.Apps {
+			if name, ok := appNames[id]; ok {
+				names[i] = name
+			} else {
+				names[i] = id
+			}
+		}
+		types := make([]string, len(t.ResourceTypes))
+		for i, typ := range t.ResourceTypes {
+			types[i] = string(typ)
+		}
+		listRec(w, t.ID, t.Name, t.Role, strings.Join(names, ","), strings.Join(types, ","), humanTime(t.CreatedAt))
+	}
+	return nil
+}
+
+func runTokenCreate(args *docopt.Args, client controller.Client) error {
+	token := &ct.Token{
+		Name: args.String["<name>"],
+		Role: ct.TokenRole(args.String["--role"]),
+		Apps: args.All["--app"].([]string),
+	}
+	for _, typ := range args.All["--resource"].([]string) {
+		token.ResourceTypes = append(token.ResourceTypes, ct.TokenResourceType(typ))
+	}
+	if err := client.CreateToken(token); err != nil {
+		return err
+	}
+	fmt.Printf("Created token %s (%s) with key:\n%s\n", token.Name, token.ID, token.Key)
+	return nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/flynn/flynn/controller/client"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/go-docopt"
+)
+
+func init() {
+	register("webhook", runWebhook, `
+usage: flynn webhook [list]
+       flynn webhook add <url> [--app=<app>]... [--event=<type>]... [--secret=<secret>]
+       flynn webhook remove <id>
+       flynn webhook deliveries <id> [-n <count>]
+
+Manage webhooks.
+
+Webhooks are POSTed controller events (e.g. deployments, job state changes and
+scale requests) as they happen, with the same JSON representation as the
+events API. Each request has a Flynn-Signature header containing "sha256="
+followed by the hex encoded HMAC-SHA256 of the body keyed with the webhook's
+secret. Deliveries which fail are retried with backoff up to 10 times.
+
+Options:
+	--app=<app>        only send events for the given app (may be repeated)
+	--event=<type>     only send events of the given type (may be repeated), e.g. deployment,
+	                   job, scale_request, release or app_release
+	--secret=<secret>  secret used to sign payloads (a random secret is generated if not set)
+	-n <count>         show at most <count> deliveries [default: 20]
+
+Commands:
+	With no arguments, shows a list of webhooks.
+
+	add         adds a webhook, printing its secret (which cannot be retrieved later)
+	remove      removes a webhook so that no more events are sent to it
+	deliveries  shows the most recent deliveries of a webhook
+
+Examples:
+
+	$ flynn webhook add https://chat.example.com/flynn --app myapp --event deployment
+	Created webhook 9d1c7f4e-5b2a-4e8f-a3c6-0f7b2d8e1a59 with secret:
+	5d41402abc4b2a76b9719d911017c592ae2c3b9d6f1e4a8c7b0d5e2f9a1c3b6d
+
+	$ flynn webhook
+	ID                                    URL                             APPS   EVENTS      CREATED
+	9d1c7f4e-5b2a-4e8f-a3c6-0f7b2d8e1a59  https://chat.example.com/flynn  myapp  deployment  2 minutes ago
+
+	$ flynn webhook deliveries 9d1c7f4e-5b2a-4e8f-a3c6-0f7b2d8e1a59
+	ID                                    EVENT  TYPE        STATE      ATTEMPTS  STATUS  ERROR  CREATED
+	1f0e3d2c-7a6b-4c5d-9e8f-b1a2c3d4e5f6  1432   deployment  delivered  1         200            1 minute ago
+
+	$ flynn webhook remove 9d1c7f4e-5b2a-4e8f-a3c6-0f7b2d8e1a59
+`)
+}
+
+func runWebhook(args *docopt.Args, client controller.Client) error {
+	if args.Bool["add"] {
+		return runWebhookAdd(args, client)
+	} else if args.Bool["remove"] {
+		_, err := client.DeleteWebhook(args.String["<id>"])
+		return err
+	} else if args.Bool["deliveries"] {
+		return runWebhookDeliveries(args, client)
+	}
+	return runWebhookList(client)
+}
+
+func runWebhookList(client controller.Client) error {
+	webhooks, err := client.ListWebhooks()
+	if err != nil {
+		return err
+	}
+
+	// webhooks store app IDs, so look up the app names to display
+	apps, err := client.AppList()
+	if err != nil {
+		return err
+	}
+	appNames := make(map[string]string, len(apps))
+	for _, app := range apps {
+		appNames[app.ID] = app.Name
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	listRec(w, "ID", "URL", "APPS", "EVENTS", "CREATED")
+	for _, h := range webhooks {
+		names := make([]string, len(h.Apps))
+		for i, id := range h.Apps {
+			if name, ok := appNames[id]; ok {
+				names[i] = name
+			} else {
+				names[i] = id
+			}
+		}
+		types := make([]string, len(h.EventTypes))
+		for i, typ := range h.EventTypes {
+			types[i] = string(typ)
+		}
+		listRec(w, h.ID, h.URL, strings.Join(names, ","), strings.Join(types, ","), humanTime(h.CreatedAt))
+	}
+	return nil
+}
+
+func runWebhookAdd(args *docopt.Args, client controller.Client) error {
+	webhook := &ct.Webhook{
+		URL:    args.String["<url>"],
+		Apps:   args.All["--app"].([]string),
+		Secret: args.String["--secret"],
+	}
+	for _, typ := range args.All["--event"].([]string) {
+		webhook.EventTypes = append(webhook.EventTypes, ct.EventType(typ))
+	}
+	if err := client.CreateWebhook(webhook); err != nil {
+		return err
+	}
+	fmt.Printf("Created webhook %s with secret:\n%s\n", webhook.ID, webhook.Secret)
+	return nil
+}
+
+func runWebhookDeliveries(args *docopt.Args, client controller.Client) error {
+	count, err := strconv.Atoi(args.String["-n"])
+	if err != nil {
+		return fmt.Errorf("invalid count %q", args.String["-n"])
+	}
+	deliveries, err := client.ListWebhookDeliveries(args.String["<id>"], count)
+	if err != nil {
+		return err
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	listRec(w, "ID", "EVENT", "TYPE", "STATE", "ATTEMPTS", "STATUS", "ERROR", "CREATED")
+	for _, d := range deliveries {
+		var status string
+		if d.ResponseStatus != 0 {
+			status = strconv.Itoa(d.ResponseStatus)
+		}
+		listRec(w, d.ID, d.EventID, d.EventType, d.State, d.Attempts, status, d.Error, humanTime(d.CreatedAt))
+	}
+	return nil
+}
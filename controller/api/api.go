@@ -2,9 +2,11 @@ package api
 
 import (
 	context "context"
+	"encoding/json"
 	fmt "fmt"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,7 +14,10 @@ import (
 	ct "github.com/flynn/flynn/controller/types"
 	"github.com/flynn/flynn/host/resource"
 	host "github.com/flynn/flynn/host/types"
+	"github.com/flynn/flynn/host/volume"
+	"github.com/flynn/flynn/pkg/httphelper"
 	"github.com/flynn/flynn/pkg/version"
+	router "github.com/flynn/flynn/router/types"
 	"github.com/golang/protobuf/ptypes"
 	durpb "github.com/golang/protobuf/ptypes/duration"
 	tspb "github.com/golang/protobuf/ptypes/timestamp"
@@ -182,9 +187,20 @@ func NewError(err error, message string, args ...interface{}) error {
 	if err == controller.ErrNotFound {
 		errCode = codes.NotFound
 	}
-	switch err.(type) {
+	switch e := err.(type) {
 	case ct.ValidationError, *ct.ValidationError:
 		errCode = codes.InvalidArgument
+	case httphelper.JSONError:
+		switch e.Code {
+		case httphelper.NotFoundErrorCode, httphelper.ObjectNotFoundErrorCode:
+			errCode = codes.NotFound
+		case httphelper.ObjectExistsErrorCode, httphelper.ConflictErrorCode:
+			errCode = codes.AlreadyExists
+		case httphelper.ValidationErrorCode:
+			errCode = codes.InvalidArgument
+		case httphelper.PreconditionFailedErrorCode:
+			errCode = codes.FailedPrecondition
+		}
 	}
 	return grpc.Errorf(errCode, fmt.Sprintf(message, args...))
 }
@@ -371,7 +387,7 @@ func NewControllerResources(from map[string]*HostResourceSpec) resource.Resource
 		}
 		to[resource.Type(k)] = spec
 	}
-	return resource.Resources{}
+	return to
 }
 
 func NewMounts(from []host.Mount) []*HostMount {
@@ -691,6 +707,39 @@ func NewExpandedDeployment(from *ct.ExpandedDeployment) *ExpandedDeployment {
 	}
 }
 
+func (d *ExpandedDeployment) ControllerType() *ct.ExpandedDeployment {
+	var releaseType ct.ReleaseType
+	switch d.Type {
+	case ReleaseType_CODE:
+		releaseType = ct.ReleaseTypeCode
+	case ReleaseType_CONFIG:
+		releaseType = ct.ReleaseTypeConfig
+	}
+
+	var oldRelease *ct.Release
+	if d.OldRelease != nil {
+		oldRelease = d.OldRelease.ControllerType()
+	}
+	var newRelease *ct.Release
+	if d.NewRelease != nil {
+		newRelease = d.NewRelease.ControllerType()
+	}
+	return &ct.ExpandedDeployment{
+		ID:            ParseIDFromName(d.Name, "deployments"),
+		AppID:         ParseIDFromName(d.Name, "apps"),
+		OldRelease:    oldRelease,
+		NewRelease:    newRelease,
+		Type:          releaseType,
+		Strategy:      d.Strategy,
+		Status:        d.Status.ControllerType(),
+		Processes:     NewControllerDeploymentProcesses(d.Processes),
+		Tags:          NewControllerDeploymentTags(d.Tags),
+		DeployTimeout: d.DeployTimeout,
+		CreatedAt:     NewGoTimestamp(d.CreateTime),
+		FinishedAt:    NewGoTimestamp(d.EndTime),
+	}
+}
+
 func NewJobState(from ct.JobState) DeploymentEvent_JobState {
 	switch from {
 	case "pending":
@@ -712,3 +761,398 @@ func NewJobState(from ct.JobState) DeploymentEvent_JobState {
 	}
 	return DeploymentEvent_PENDING
 }
+
+func (s DeploymentEvent_JobState) ControllerType() ct.JobState {
+	switch s {
+	case DeploymentEvent_BLOCKED:
+		return ct.JobStateBlocked
+	case DeploymentEvent_STARTING:
+		return ct.JobStateStarting
+	case DeploymentEvent_UP:
+		return ct.JobStateUp
+	case DeploymentEvent_STOPPING:
+		return ct.JobStateStopping
+	case DeploymentEvent_DOWN:
+		return ct.JobStateDown
+	case DeploymentEvent_CRASHED:
+		return ct.JobStateCrashed
+	case DeploymentEvent_FAILED:
+		return ct.JobStateFailed
+	default:
+		return ct.JobStatePending
+	}
+}
+
+func NewJob(from *ct.Job) *Job {
+	var releaseName string
+	if from.ReleaseID != "" {
+		releaseName = path.Join("apps", from.AppID, "releases", from.ReleaseID)
+	}
+	to := &Job{
+		Name:       path.Join("apps", from.AppID, "jobs", from.UUID),
+		Release:    releaseName,
+		JobId:      from.ID,
+		HostId:     from.HostID,
+		Type:       from.Type,
+		State:      NewJobState(from.State),
+		Args:       from.Args,
+		Volumes:    from.VolumeIDs,
+		Labels:     from.Meta,
+		RunTime:    NewTimestamp(from.RunAt),
+		CreateTime: NewTimestamp(from.CreatedAt),
+		UpdateTime: NewTimestamp(from.UpdatedAt),
+	}
+	if from.ExitStatus != nil {
+		to.ExitStatus = *from.ExitStatus
+	}
+	if from.HostError != nil {
+		to.HostError = *from.HostError
+	}
+	if from.Restarts != nil {
+		to.Restarts = *from.Restarts
+	}
+	if from.BlockedReason != nil {
+		to.BlockedReason = *from.BlockedReason
+	}
+	if from.PreemptedBy != nil {
+		to.PreemptedBy = *from.PreemptedBy
+	}
+	if from.CrashLoopReason != nil {
+		to.CrashLoopReason = *from.CrashLoopReason
+	}
+	return to
+}
+
+func (j *Job) ControllerType() *ct.Job {
+	to := &ct.Job{
+		ID:        j.JobId,
+		UUID:      ParseIDFromName(j.Name, "jobs"),
+		HostID:    j.HostId,
+		AppID:     ParseIDFromName(j.Name, "apps"),
+		ReleaseID: ParseIDFromName(j.Release, "releases"),
+		Type:      j.Type,
+		State:     j.State.ControllerType(),
+		Args:      j.Args,
+		VolumeIDs: j.Volumes,
+		Meta:      j.Labels,
+		RunAt:     NewGoTimestamp(j.RunTime),
+		CreatedAt: NewGoTimestamp(j.CreateTime),
+		UpdatedAt: NewGoTimestamp(j.UpdateTime),
+	}
+	if j.HostError != "" {
+		to.HostError = &j.HostError
+	} else if to.State == ct.JobStateDown {
+		to.ExitStatus = &j.ExitStatus
+	}
+	if j.Restarts > 0 {
+		to.Restarts = &j.Restarts
+	}
+	if j.BlockedReason != "" {
+		to.BlockedReason = &j.BlockedReason
+	}
+	if j.PreemptedBy != "" {
+		to.PreemptedBy = &j.PreemptedBy
+	}
+	if j.CrashLoopReason != "" {
+		to.CrashLoopReason = &j.CrashLoopReason
+	}
+	return to
+}
+
+func NewCreateJobRequest(appID string, from *ct.NewJob) *CreateJobRequest {
+	profiles := make([]string, len(from.Profiles))
+	for i, p := range from.Profiles {
+		profiles[i] = string(p)
+	}
+	return &CreateJobRequest{
+		Parent:     path.Join("apps", appID, "releases", from.ReleaseID),
+		Artifacts:  from.ArtifactIDs,
+		ReleaseEnv: from.ReleaseEnv,
+		Args:       from.Args,
+		Env:        from.Env,
+		Labels:     from.Meta,
+		DisableLog: from.DisableLog,
+		Resources:  NewResources(from.Resources),
+		Data:       from.Data,
+		Partition:  string(from.Partition),
+		Profiles:   profiles,
+		MountsFrom: from.MountsFrom,
+	}
+}
+
+func (r *CreateJobRequest) ControllerType() *ct.NewJob {
+	var profiles []host.JobProfile
+	if len(r.Profiles) > 0 {
+		profiles = make([]host.JobProfile, len(r.Profiles))
+		for i, p := range r.Profiles {
+			profiles[i] = host.JobProfile(p)
+		}
+	}
+	var resources resource.Resources
+	if len(r.Resources) > 0 {
+		resources = NewControllerResources(r.Resources)
+	}
+	return &ct.NewJob{
+		ReleaseID:   ParseIDFromName(r.Parent, "releases"),
+		ArtifactIDs: r.Artifacts,
+		ReleaseEnv:  r.ReleaseEnv,
+		Args:        r.Args,
+		Env:         r.Env,
+		Meta:        r.Labels,
+		DisableLog:  r.DisableLog,
+		Resources:   resources,
+		Data:        r.Data,
+		Partition:   ct.PartitionType(r.Partition),
+		Profiles:    profiles,
+		MountsFrom:  r.MountsFrom,
+	}
+}
+
+func NewRoute(from *router.Route) *Route {
+	var appName string
+	if strings.HasPrefix(from.ParentRef, ct.RouteParentRefPrefix) {
+		appName = path.Join("apps", strings.TrimPrefix(from.ParentRef, ct.RouteParentRefPrefix))
+	}
+	name := path.Join(appName, "routes", from.ID)
+	to := &Route{
+		Name:              name,
+		Type:              from.Type,
+		Service:           from.Service,
+		Port:              from.Port,
+		Leader:            from.Leader,
+		Domain:            from.Domain,
+		Sticky:            from.Sticky,
+		Path:              from.Path,
+		DrainBackends:     from.DrainBackends,
+		DisableKeepAlives: from.DisableKeepAlives,
+		CanaryWeight:      int32(from.CanaryWeight),
+	}
+	if from.CanaryRelease != "" {
+		to.CanaryRelease = path.Join(appName, "releases", from.CanaryRelease)
+	}
+	if c := from.Certificate; c != nil {
+		to.Certificate = &RouteCertificate{Cert: c.Cert, Key: c.Key}
+	} else if from.LegacyTLSCert != "" {
+		to.Certificate = &RouteCertificate{Cert: from.LegacyTLSCert, Key: from.LegacyTLSKey}
+	}
+	if !from.CreatedAt.IsZero() {
+		to.CreateTime = NewTimestamp(&from.CreatedAt)
+	}
+	if !from.UpdatedAt.IsZero() {
+		to.UpdateTime = NewTimestamp(&from.UpdatedAt)
+	}
+	return to
+}
+
+func (r *Route) ControllerType() *router.Route {
+	to := &router.Route{
+		Type:              r.Type,
+		ID:                ParseIDFromName(r.Name, "routes"),
+		Service:           r.Service,
+		Port:              r.Port,
+		Leader:            r.Leader,
+		Domain:            r.Domain,
+		Sticky:            r.Sticky,
+		Path:              r.Path,
+		DrainBackends:     r.DrainBackends,
+		DisableKeepAlives: r.DisableKeepAlives,
+		CanaryRelease:     ParseIDFromName(r.CanaryRelease, "releases"),
+		CanaryWeight:      int(r.CanaryWeight),
+	}
+	if appID := ParseIDFromName(r.Name, "apps"); appID != "" {
+		to.ParentRef = ct.RouteParentRefPrefix + appID
+	}
+	if c := r.Certificate; c != nil {
+		to.Certificate = &router.Certificate{Cert: c.Cert, Key: c.Key}
+	}
+	if t := NewGoTimestamp(r.CreateTime); t != nil {
+		to.CreatedAt = *t
+	}
+	if t := NewGoTimestamp(r.UpdateTime); t != nil {
+		to.UpdatedAt = *t
+	}
+	return to
+}
+
+func NewProvider(from *ct.Provider) *Provider {
+	return &Provider{
+		Name:        path.Join("providers", from.ID),
+		DisplayName: from.Name,
+		Url:         from.URL,
+		CreateTime:  NewTimestamp(from.CreatedAt),
+		UpdateTime:  NewTimestamp(from.UpdatedAt),
+	}
+}
+
+func (p *Provider) ControllerType() *ct.Provider {
+	return &ct.Provider{
+		ID:        ParseIDFromName(p.Name, "providers"),
+		Name:      p.DisplayName,
+		URL:       p.Url,
+		CreatedAt: NewGoTimestamp(p.CreateTime),
+		UpdatedAt: NewGoTimestamp(p.UpdateTime),
+	}
+}
+
+func NewResource(from *ct.Resource) *Resource {
+	apps := make([]string, len(from.Apps))
+	for i, appID := range from.Apps {
+		apps[i] = path.Join("apps", appID)
+	}
+	return &Resource{
+		Name:       path.Join("providers", from.ProviderID, "resources", from.ID),
+		ExternalId: from.ExternalID,
+		Env:        from.Env,
+		Apps:       apps,
+		CreateTime: NewTimestamp(from.CreatedAt),
+	}
+}
+
+func (r *Resource) ControllerType() *ct.Resource {
+	var apps []string
+	if len(r.Apps) > 0 {
+		apps = make([]string, len(r.Apps))
+		for i, name := range r.Apps {
+			apps[i] = ParseIDFromName(name, "apps")
+		}
+	}
+	return &ct.Resource{
+		ID:         ParseIDFromName(r.Name, "resources"),
+		ProviderID: ParseIDFromName(r.Name, "providers"),
+		ExternalID: r.ExternalId,
+		Env:        r.Env,
+		Apps:       apps,
+		CreatedAt:  NewGoTimestamp(r.CreateTime),
+	}
+}
+
+func NewVolumeState(from ct.VolumeState) Volume_State {
+	switch from {
+	case ct.VolumeStateCreated:
+		return Volume_CREATED
+	case ct.VolumeStateDestroyed:
+		return Volume_DESTROYED
+	default:
+		return Volume_PENDING
+	}
+}
+
+func (s Volume_State) ControllerType() ct.VolumeState {
+	switch s {
+	case Volume_CREATED:
+		return ct.VolumeStateCreated
+	case Volume_DESTROYED:
+		return ct.VolumeStateDestroyed
+	default:
+		return ct.VolumeStatePending
+	}
+}
+
+func NewVolume(from *ct.Volume) *Volume {
+	name := path.Join("volumes", from.ID)
+	var releaseName string
+	if from.AppID != "" {
+		name = path.Join("apps", from.AppID, name)
+		if from.ReleaseID != "" {
+			releaseName = path.Join("apps", from.AppID, "releases", from.ReleaseID)
+		}
+	}
+	to := &Volume{
+		Name:             name,
+		Path:             from.Path,
+		DeleteOnStop:     from.DeleteOnStop,
+		HostId:           from.HostID,
+		Type:             string(from.Type),
+		State:            NewVolumeState(from.State),
+		Release:          releaseName,
+		JobType:          from.JobType,
+		Labels:           from.Meta,
+		CreateTime:       NewTimestamp(from.CreatedAt),
+		UpdateTime:       NewTimestamp(from.UpdatedAt),
+		DecommissionTime: NewTimestamp(from.DecommissionedAt),
+	}
+	if from.JobID != nil {
+		to.JobId = *from.JobID
+	}
+	return to
+}
+
+func (v *Volume) ControllerType() *ct.Volume {
+	to := &ct.Volume{
+		VolumeReq: ct.VolumeReq{
+			Path:         v.Path,
+			DeleteOnStop: v.DeleteOnStop,
+		},
+		ID:               ParseIDFromName(v.Name, "volumes"),
+		HostID:           v.HostId,
+		Type:             volume.VolumeType(v.Type),
+		State:            v.State.ControllerType(),
+		AppID:            ParseIDFromName(v.Name, "apps"),
+		ReleaseID:        ParseIDFromName(v.Release, "releases"),
+		JobType:          v.JobType,
+		Meta:             v.Labels,
+		CreatedAt:        NewGoTimestamp(v.CreateTime),
+		UpdatedAt:        NewGoTimestamp(v.UpdateTime),
+		DecommissionedAt: NewGoTimestamp(v.DecommissionTime),
+	}
+	if v.JobId != "" {
+		to.JobID = &v.JobId
+	}
+	return to
+}
+
+func NewSink(from *ct.Sink) *Sink {
+	to := &Sink{
+		Name:        path.Join("sinks", from.ID),
+		Kind:        string(from.Kind),
+		HostManaged: from.HostManaged,
+		CreateTime:  NewTimestamp(from.CreatedAt),
+		UpdateTime:  NewTimestamp(from.UpdatedAt),
+	}
+	if from.Config != nil {
+		to.Config = *from.Config
+	}
+	return to
+}
+
+func (s *Sink) ControllerType() *ct.Sink {
+	to := &ct.Sink{
+		ID:          ParseIDFromName(s.Name, "sinks"),
+		Kind:        ct.SinkKind(s.Kind),
+		HostManaged: s.HostManaged,
+		CreatedAt:   NewGoTimestamp(s.CreateTime),
+		UpdatedAt:   NewGoTimestamp(s.UpdateTime),
+	}
+	if len(s.Config) > 0 {
+		config := json.RawMessage(s.Config)
+		to.Config = &config
+	}
+	return to
+}
+
+func NewEvent(from *ct.Event) *Event {
+	var parent string
+	if from.AppID != "" {
+		parent = path.Join("apps", from.AppID)
+	}
+	return &Event{
+		Name:       path.Join("events", strconv.FormatInt(from.ID, 10)),
+		Parent:     parent,
+		ObjectType: string(from.ObjectType),
+		ObjectId:   from.ObjectID,
+		Data:       from.Data,
+		CreateTime: NewTimestamp(from.CreatedAt),
+	}
+}
+
+func (e *Event) ControllerType() *ct.Event {
+	id, _ := strconv.ParseInt(ParseIDFromName(e.Name, "events"), 10, 64)
+	return &ct.Event{
+		ID:         id,
+		AppID:      ParseIDFromName(e.Parent, "apps"),
+		ObjectType: ct.EventType(e.ObjectType),
+		ObjectID:   e.ObjectId,
+		Data:       e.Data,
+		CreatedAt:  NewGoTimestamp(e.CreateTime),
+	}
+}
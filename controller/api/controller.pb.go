@@ -225,6 +225,34 @@ func (DeploymentEvent_JobState) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_ed7f10298fa1d90f, []int{27, 0}
 }
 
+type Volume_State int32
+
+const (
+	Volume_PENDING   Volume_State = 0
+	Volume_CREATED   Volume_State = 1
+	Volume_DESTROYED Volume_State = 2
+)
+
+var Volume_State_name = map[int32]string{
+	0: "PENDING",
+	1: "CREATED",
+	2: "DESTROYED",
+}
+
+var Volume_State_value = map[string]int32{
+	"PENDING":   0,
+	"CREATED":   1,
+	"DESTROYED": 2,
+}
+
+func (x Volume_State) String() string {
+	return proto.EnumName(Volume_State_name, int32(x))
+}
+
+func (Volume_State) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{52, 0}
+}
+
 type StatusResponse struct {
 	Status               StatusResponse_Code `protobuf:"varint,1,opt,name=status,proto3,enum=flynn.api.v1.StatusResponse_Code" json:"status,omitempty"`
 	Detail               []byte              `protobuf:"bytes,2,opt,name=detail,proto3" json:"detail,omitempty"`
@@ -2399,394 +2427,2801 @@ func (m *DeploymentEvent) GetCreateTime() *timestamp.Timestamp {
 	return nil
 }
 
-func init() {
-	proto.RegisterEnum("flynn.api.v1.ReleaseType", ReleaseType_name, ReleaseType_value)
-	proto.RegisterEnum("flynn.api.v1.ScaleRequestState", ScaleRequestState_name, ScaleRequestState_value)
-	proto.RegisterEnum("flynn.api.v1.DeploymentStatus", DeploymentStatus_name, DeploymentStatus_value)
-	proto.RegisterEnum("flynn.api.v1.StatusResponse_Code", StatusResponse_Code_name, StatusResponse_Code_value)
-	proto.RegisterEnum("flynn.api.v1.LabelFilter_Expression_Operator", LabelFilter_Expression_Operator_name, LabelFilter_Expression_Operator_value)
-	proto.RegisterEnum("flynn.api.v1.DeploymentEvent_JobState", DeploymentEvent_JobState_name, DeploymentEvent_JobState_value)
-	proto.RegisterType((*StatusResponse)(nil), "flynn.api.v1.StatusResponse")
-	proto.RegisterType((*LabelFilter)(nil), "flynn.api.v1.LabelFilter")
-	proto.RegisterType((*LabelFilter_Expression)(nil), "flynn.api.v1.LabelFilter.Expression")
-	proto.RegisterType((*StreamAppsRequest)(nil), "flynn.api.v1.StreamAppsRequest")
-	proto.RegisterType((*StreamAppsResponse)(nil), "flynn.api.v1.StreamAppsResponse")
-	proto.RegisterType((*StreamReleasesRequest)(nil), "flynn.api.v1.StreamReleasesRequest")
-	proto.RegisterType((*StreamReleasesResponse)(nil), "flynn.api.v1.StreamReleasesResponse")
-	proto.RegisterType((*StreamScalesRequest)(nil), "flynn.api.v1.StreamScalesRequest")
-	proto.RegisterType((*StreamScalesResponse)(nil), "flynn.api.v1.StreamScalesResponse")
-	proto.RegisterType((*StreamDeploymentsRequest)(nil), "flynn.api.v1.StreamDeploymentsRequest")
-	proto.RegisterType((*StreamDeploymentsResponse)(nil), "flynn.api.v1.StreamDeploymentsResponse")
-	proto.RegisterType((*UpdateAppRequest)(nil), "flynn.api.v1.UpdateAppRequest")
-	proto.RegisterType((*CreateScaleRequest)(nil), "flynn.api.v1.CreateScaleRequest")
-	proto.RegisterMapType((map[string]int32)(nil), "flynn.api.v1.CreateScaleRequest.ProcessesEntry")
-	proto.RegisterMapType((map[string]*DeploymentProcessTags)(nil), "flynn.api.v1.CreateScaleRequest.TagsEntry")
-	proto.RegisterType((*CreateReleaseRequest)(nil), "flynn.api.v1.CreateReleaseRequest")
-	proto.RegisterType((*CreateDeploymentRequest)(nil), "flynn.api.v1.CreateDeploymentRequest")
-	proto.RegisterType((*App)(nil), "flynn.api.v1.App")
-	proto.RegisterMapType((map[string]string)(nil), "flynn.api.v1.App.LabelsEntry")
-	proto.RegisterType((*HostHealthCheck)(nil), "flynn.api.v1.HostHealthCheck")
-	proto.RegisterType((*HostService)(nil), "flynn.api.v1.HostService")
-	proto.RegisterType((*Port)(nil), "flynn.api.v1.Port")
-	proto.RegisterType((*VolumeReq)(nil), "flynn.api.v1.VolumeReq")
-	proto.RegisterType((*HostResourceSpec)(nil), "flynn.api.v1.HostResourceSpec")
-	proto.RegisterType((*HostMount)(nil), "flynn.api.v1.HostMount")
-	proto.RegisterType((*LibContainerDevice)(nil), "flynn.api.v1.LibContainerDevice")
-	proto.RegisterType((*ProcessType)(nil), "flynn.api.v1.ProcessType")
-	proto.RegisterMapType((map[string]string)(nil), "flynn.api.v1.ProcessType.EnvEntry")
-	proto.RegisterMapType((map[string]*HostResourceSpec)(nil), "flynn.api.v1.ProcessType.ResourcesEntry")
-	proto.RegisterType((*Release)(nil), "flynn.api.v1.Release")
-	proto.RegisterMapType((map[string]string)(nil), "flynn.api.v1.Release.EnvEntry")
-	proto.RegisterMapType((map[string]string)(nil), "flynn.api.v1.Release.LabelsEntry")
-	proto.RegisterMapType((map[string]*ProcessType)(nil), "flynn.api.v1.Release.ProcessesEntry")
-	proto.RegisterType((*ScaleRequest)(nil), "flynn.api.v1.ScaleRequest")
-	proto.RegisterMapType((map[string]int32)(nil), "flynn.api.v1.ScaleRequest.NewProcessesEntry")
-	proto.RegisterMapType((map[string]*DeploymentProcessTags)(nil), "flynn.api.v1.ScaleRequest.NewTagsEntry")
-	proto.RegisterMapType((map[string]int32)(nil), "flynn.api.v1.ScaleRequest.OldProcessesEntry")
-	proto.RegisterMapType((map[string]*DeploymentProcessTags)(nil), "flynn.api.v1.ScaleRequest.OldTagsEntry")
-	proto.RegisterType((*DeploymentProcessTags)(nil), "flynn.api.v1.DeploymentProcessTags")
-	proto.RegisterMapType((map[string]string)(nil), "flynn.api.v1.DeploymentProcessTags.TagsEntry")
-	proto.RegisterType((*ExpandedDeployment)(nil), "flynn.api.v1.ExpandedDeployment")
-	proto.RegisterMapType((map[string]int32)(nil), "flynn.api.v1.ExpandedDeployment.ProcessesEntry")
-	proto.RegisterMapType((map[string]*DeploymentProcessTags)(nil), "flynn.api.v1.ExpandedDeployment.TagsEntry")
-	proto.RegisterType((*DeploymentEvent)(nil), "flynn.api.v1.DeploymentEvent")
+type StreamJobsRequest struct {
+	// Specifies an optional list of resource names that should be looked up.
+	// This can be used to request a known set of one or more resources and
+	// optionally receive updates about them, and can also be used to retrieve
+	// a single resource. Parent resource names may also be used to filter
+	// resources. Only active jobs are returned if no app is given.
+	NameFilters []string `protobuf:"bytes,1,rep,name=name_filters,json=nameFilters,proto3" json:"name_filters,omitempty"`
+	// When set, only includes resources having one of the specified states
+	StateFilters []DeploymentEvent_JobState `protobuf:"varint,2,rep,packed,name=state_filters,json=stateFilters,proto3,enum=flynn.api.v1.DeploymentEvent_JobState" json:"state_filters,omitempty"`
+	// When true, leaves the stream open and sends any updates to each resource
+	// returned in the initial page until the stream is closed.
+	StreamUpdates bool `protobuf:"varint,3,opt,name=stream_updates,json=streamUpdates,proto3" json:"stream_updates,omitempty"`
+	// When true, leaves the stream open and sends newly created resources
+	// matching the filters until the stream is closed.
+	StreamCreates        bool     `protobuf:"varint,4,opt,name=stream_creates,json=streamCreates,proto3" json:"stream_creates,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func init() { proto.RegisterFile("controller.proto", fileDescriptor_ed7f10298fa1d90f) }
-
-var fileDescriptor_ed7f10298fa1d90f = []byte{
-	// 2721 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe4, 0x3a, 0xcd, 0x6f, 0x1b, 0xc7,
-	0xf5, 0x22, 0x29, 0x92, 0xbb, 0x8f, 0xa4, 0x4c, 0x4d, 0x12, 0x87, 0x66, 0x12, 0x47, 0x5e, 0xe7,
-	0x43, 0xf0, 0x2f, 0xa6, 0x13, 0x25, 0xf6, 0xaf, 0x6e, 0xd2, 0xa4, 0x34, 0x45, 0xc5, 0x6a, 0x65,
-	0x52, 0x1d, 0xca, 0x4d, 0x53, 0x04, 0x20, 0x56, 0xdc, 0x91, 0xb4, 0xd1, 0x72, 0x77, 0xbb, 0x3b,
-	0xd4, 0x47, 0x80, 0x16, 0x3d, 0x04, 0x68, 0xee, 0x45, 0x81, 0x9e, 0x5a, 0xf4, 0xd2, 0x53, 0xfb,
-	0x27, 0xf4, 0xd2, 0x5b, 0x81, 0xde, 0x7b, 0xea, 0x21, 0xff, 0x49, 0xf1, 0x66, 0x66, 0xc9, 0xe5,
-	0x72, 0x29, 0x4a, 0xa9, 0x1b, 0x14, 0xe8, 0x6d, 0xdf, 0x9b, 0xf7, 0x1e, 0xdf, 0xbc, 0xef, 0x99,
-	0x21, 0x54, 0x07, 0x9e, 0xcb, 0x03, 0xcf, 0x71, 0x58, 0xd0, 0xf0, 0x03, 0x8f, 0x7b, 0xa4, 0x7c,
-	0xe0, 0x9c, 0xbb, 0x6e, 0xc3, 0xf4, 0xed, 0xc6, 0xc9, 0x3b, 0xf5, 0x57, 0x0f, 0x3d, 0xef, 0xd0,
-	0x61, 0xf7, 0xc4, 0xda, 0xfe, 0xe8, 0xe0, 0x1e, 0xb7, 0x87, 0x2c, 0xe4, 0xe6, 0xd0, 0x97, 0xe4,
-	0xf5, 0x9b, 0x49, 0x02, 0x6b, 0x14, 0x98, 0xdc, 0xf6, 0x5c, 0xb5, 0xbe, 0x96, 0x5c, 0x3f, 0xb0,
-	0x99, 0x63, 0xf5, 0x87, 0x66, 0x78, 0xac, 0x28, 0x5e, 0x4a, 0x52, 0xb0, 0xa1, 0xcf, 0xcf, 0xe5,
-	0xa2, 0xf1, 0x87, 0x0c, 0xac, 0xf4, 0xb8, 0xc9, 0x47, 0x21, 0x65, 0xa1, 0xef, 0xb9, 0x21, 0x23,
-	0x0f, 0xa1, 0x10, 0x0a, 0x4c, 0x2d, 0xb3, 0x96, 0x59, 0x5f, 0xd9, 0xb8, 0xd5, 0x88, 0x6b, 0xdc,
-	0x98, 0xa6, 0x6e, 0xb4, 0x3c, 0x8b, 0x51, 0xc5, 0x40, 0xae, 0x43, 0xc1, 0x62, 0xdc, 0xb4, 0x9d,
-	0x5a, 0x76, 0x2d, 0xb3, 0x5e, 0xa6, 0x0a, 0x22, 0x35, 0x28, 0x9e, 0xb0, 0x20, 0xb4, 0x3d, 0xb7,
-	0x96, 0x5b, 0xcb, 0xac, 0xeb, 0x34, 0x02, 0x0d, 0x03, 0x96, 0x51, 0x02, 0x29, 0x41, 0xf1, 0x71,
-	0xbb, 0xb9, 0xb3, 0xf7, 0xf8, 0xd3, 0xea, 0x12, 0xa9, 0x80, 0xfe, 0xb4, 0x13, 0x81, 0x19, 0xe3,
-	0x37, 0x59, 0x28, 0xed, 0x98, 0xfb, 0xcc, 0xd9, 0xb2, 0x1d, 0xce, 0x02, 0xb2, 0x05, 0x25, 0x76,
-	0xe6, 0x07, 0x2c, 0x44, 0x09, 0xa8, 0x65, 0x6e, 0xbd, 0xb4, 0xf1, 0xda, 0xb4, 0x96, 0x31, 0xfa,
-	0x46, 0x7b, 0x4c, 0x4c, 0xe3, 0x8c, 0xf5, 0xbf, 0x64, 0x00, 0x26, 0x6b, 0xa4, 0x0a, 0xb9, 0x63,
-	0x76, 0x2e, 0x36, 0xad, 0x53, 0xfc, 0x24, 0xdf, 0x83, 0xac, 0xe7, 0x8b, 0xad, 0xac, 0x6c, 0xdc,
-	0xbd, 0x8c, 0xfc, 0x46, 0xd7, 0x67, 0x81, 0xc9, 0xbd, 0x80, 0x66, 0x3d, 0x1f, 0xad, 0x71, 0x62,
-	0x3a, 0x23, 0x16, 0xd6, 0x72, 0x6b, 0xb9, 0x75, 0x9d, 0x2a, 0xc8, 0xd8, 0x02, 0x2d, 0xa2, 0x23,
-	0x3a, 0xe4, 0xbb, 0xbb, 0xfd, 0xed, 0x8e, 0xdc, 0x75, 0x77, 0xb7, 0xdf, 0xe9, 0xee, 0x21, 0x98,
-	0x51, 0x60, 0xfb, 0x27, 0xdb, 0xbd, 0xbd, 0x5e, 0x35, 0x4b, 0x56, 0xa1, 0xa2, 0x56, 0x15, 0x2a,
-	0x67, 0xfc, 0x32, 0x0b, 0xab, 0x3d, 0x1e, 0x30, 0x73, 0xd8, 0xf4, 0xfd, 0x90, 0xb2, 0x9f, 0x8d,
-	0x58, 0xc8, 0xc9, 0x4b, 0xa0, 0xfb, 0xe6, 0x21, 0xeb, 0x87, 0xf6, 0x17, 0x4c, 0x6c, 0x26, 0x4f,
-	0x35, 0x44, 0xf4, 0xec, 0x2f, 0x18, 0x79, 0x05, 0x40, 0x2c, 0x72, 0xef, 0x98, 0xb9, 0x62, 0x67,
-	0x3a, 0x15, 0xe4, 0x7b, 0x88, 0x20, 0xb7, 0xa0, 0xec, 0x9a, 0x43, 0xd6, 0x3f, 0x10, 0x1b, 0x8b,
-	0xf4, 0x2e, 0x21, 0x4e, 0xee, 0x35, 0x24, 0x1f, 0x42, 0xc5, 0xc1, 0xbd, 0x8f, 0x69, 0x96, 0x85,
-	0xf9, 0x6f, 0xcc, 0x35, 0x0f, 0x2d, 0x3b, 0x13, 0x20, 0x24, 0xaf, 0xc3, 0x4a, 0x28, 0x74, 0xee,
-	0x8f, 0x7c, 0xcb, 0xe4, 0x2c, 0xac, 0xe5, 0xd7, 0x32, 0xeb, 0x1a, 0xad, 0x48, 0xec, 0x53, 0x89,
-	0x8c, 0x91, 0x0d, 0x02, 0x26, 0xc8, 0x0a, 0x71, 0xb2, 0x96, 0x44, 0x1a, 0x5f, 0x65, 0x80, 0xc4,
-	0x4d, 0xa0, 0x42, 0xf8, 0x75, 0x58, 0x36, 0x7d, 0x3f, 0x0a, 0x8d, 0xd5, 0x69, 0xdd, 0x9a, 0xbe,
-	0x4f, 0xc5, 0x32, 0xb9, 0x0d, 0x15, 0x61, 0x8d, 0x81, 0x37, 0xf4, 0x1d, 0xc6, 0x99, 0x30, 0x88,
-	0x46, 0xcb, 0x88, 0x6c, 0x29, 0x1c, 0x79, 0x03, 0xae, 0xb9, 0xec, 0x8c, 0xf7, 0x63, 0x76, 0x93,
-	0x31, 0x5c, 0x41, 0xf4, 0x6e, 0x64, 0x3b, 0xe3, 0xcb, 0x2c, 0xbc, 0x20, 0x55, 0xa1, 0xcc, 0x61,
-	0x66, 0xc8, 0xfe, 0x37, 0x3d, 0xf2, 0xdb, 0x0c, 0x5c, 0x4f, 0x9a, 0x41, 0x79, 0xe5, 0x1d, 0xd0,
-	0x02, 0x85, 0x53, 0x9e, 0x79, 0x61, 0x5a, 0x47, 0xc5, 0x41, 0xc7, 0x64, 0xcf, 0xd6, 0x43, 0x5f,
-	0x65, 0xe1, 0x39, 0xa9, 0x5a, 0x6f, 0x60, 0x3a, 0xdf, 0x9a, 0x7f, 0x36, 0xa1, 0x82, 0xe5, 0x91,
-	0x4d, 0xf9, 0x67, 0x65, 0xe3, 0xd5, 0x44, 0x59, 0x45, 0x95, 0x94, 0x46, 0x58, 0x62, 0x19, 0x2d,
-	0x0b, 0xae, 0xff, 0x8c, 0x97, 0xfe, 0x98, 0x81, 0xe7, 0xa7, 0x4d, 0xa1, 0x7c, 0xd4, 0x84, 0x95,
-	0x10, 0x31, 0xfd, 0x40, 0xaa, 0x12, 0x79, 0xaa, 0x3e, 0x5f, 0x5b, 0x5a, 0x09, 0x63, 0xd0, 0x33,
-	0xf6, 0xd9, 0xdf, 0xb3, 0x50, 0x93, 0x8a, 0x6e, 0x32, 0xdf, 0xf1, 0xce, 0x87, 0xcc, 0xe5, 0xdf,
-	0x96, 0xe3, 0x3e, 0x80, 0x32, 0x3f, 0xf7, 0x93, 0x7e, 0xbb, 0x91, 0x1a, 0xb3, 0x7b, 0xe7, 0x3e,
-	0xa3, 0x25, 0x24, 0x8f, 0xb8, 0xdb, 0xe8, 0x09, 0xec, 0x8a, 0x63, 0xfe, 0x82, 0xe0, 0xbf, 0x39,
-	0xcd, 0x3f, 0xd9, 0x96, 0x6a, 0xac, 0x15, 0xc9, 0x35, 0xdf, 0xef, 0xc5, 0xcb, 0xf9, 0x5d, 0x4b,
-	0xf3, 0xfb, 0x9f, 0x32, 0x70, 0x23, 0xc5, 0x9c, 0xca, 0xf9, 0x8f, 0xa0, 0x64, 0x4d, 0xd0, 0xca,
-	0xf3, 0x6b, 0xd3, 0xfa, 0xb6, 0xcf, 0x7c, 0xd3, 0xb5, 0x98, 0x35, 0xe1, 0xa7, 0x71, 0xa6, 0x67,
-	0xeb, 0x7d, 0x0e, 0x55, 0xb9, 0x41, 0xac, 0xd9, 0xca, 0xe9, 0xb7, 0x21, 0x67, 0xfa, 0xbe, 0x70,
-	0x77, 0x6a, 0x69, 0xc7, 0x55, 0xf2, 0x3e, 0x94, 0xa4, 0xb9, 0xc4, 0x20, 0x24, 0x74, 0xc0, 0x18,
-	0x96, 0x93, 0x50, 0x23, 0x9a, 0x84, 0x1a, 0x5b, 0x38, 0x2b, 0x3d, 0x31, 0xc3, 0x63, 0x0a, 0x92,
-	0x1c, 0xbf, 0x8d, 0x7f, 0x64, 0x81, 0x48, 0x83, 0xc5, 0xc3, 0x1c, 0xdb, 0xb9, 0x6f, 0x06, 0xcc,
-	0xe5, 0x6a, 0x44, 0x50, 0x10, 0x79, 0x02, 0xba, 0x1f, 0x78, 0x03, 0x16, 0x62, 0x5d, 0xcb, 0x0a,
-	0x9b, 0xdd, 0x9b, 0x56, 0x6b, 0x56, 0x58, 0x63, 0x37, 0xe2, 0x68, 0xbb, 0x3c, 0x38, 0xa7, 0x13,
-	0x09, 0xe4, 0x43, 0x58, 0xe6, 0xe6, 0xa1, 0x0c, 0xc8, 0xd2, 0xc6, 0x9d, 0x85, 0x92, 0xf6, 0xcc,
-	0x43, 0x25, 0x44, 0xf0, 0xd5, 0x3f, 0x80, 0x95, 0x69, 0xe1, 0x29, 0x83, 0xcd, 0xf3, 0x90, 0x17,
-	0xb3, 0x88, 0x30, 0x4c, 0x9e, 0x4a, 0xe0, 0xbb, 0xd9, 0xef, 0x64, 0xea, 0x9f, 0x81, 0x3e, 0x16,
-	0x98, 0xc2, 0xf8, 0x30, 0xce, 0x58, 0xda, 0xb8, 0x3d, 0x2f, 0x96, 0x95, 0x06, 0x28, 0x2a, 0x26,
-	0xdd, 0xf8, 0x05, 0x3c, 0x2f, 0x77, 0x10, 0x55, 0xfa, 0x05, 0xa6, 0xbd, 0x07, 0x45, 0xd5, 0x0a,
-	0xd4, 0x0f, 0xce, 0x69, 0x18, 0x11, 0x15, 0x26, 0xbd, 0x2a, 0x5c, 0x7d, 0xdb, 0x52, 0x31, 0xa5,
-	0x2b, 0xcc, 0xb6, 0x65, 0x9c, 0xc1, 0x8b, 0xf2, 0xf7, 0x63, 0xd1, 0xbb, 0x40, 0x85, 0x36, 0x54,
-	0xa6, 0x0a, 0xa2, 0x52, 0x64, 0x6d, 0x91, 0x5f, 0x68, 0x39, 0x5e, 0x15, 0x8d, 0xbf, 0xe6, 0x20,
-	0xd7, 0xf4, 0x7d, 0x42, 0x60, 0x19, 0x4b, 0x8c, 0xfa, 0x11, 0xf1, 0x8d, 0xa5, 0xc8, 0xb2, 0x43,
-	0xdf, 0x31, 0xcf, 0xfb, 0x62, 0x4d, 0xd6, 0xaa, 0x92, 0xc2, 0x75, 0x90, 0xe4, 0x3e, 0x14, 0x44,
-	0xcf, 0x8e, 0xc2, 0xe2, 0x95, 0x99, 0xb8, 0x97, 0x0d, 0x5e, 0x45, 0x82, 0x22, 0xc6, 0xaa, 0x20,
-	0x73, 0xb3, 0x8f, 0xc7, 0x0a, 0x6f, 0xc4, 0x6b, 0xcb, 0xc2, 0xe1, 0x15, 0x89, 0xdd, 0x93, 0x48,
-	0x52, 0x07, 0x2d, 0xe4, 0x81, 0xc9, 0xd9, 0xe1, 0xb9, 0xe8, 0x2a, 0x3a, 0x1d, 0xc3, 0x38, 0xba,
-	0x47, 0x2e, 0x28, 0xc8, 0xd1, 0x3d, 0xb2, 0xf5, 0xfb, 0x50, 0x92, 0xb5, 0x46, 0x08, 0x17, 0x65,
-	0x29, 0x2d, 0xc7, 0xf6, 0xa2, 0x03, 0x0d, 0x05, 0x49, 0x8e, 0x88, 0x58, 0x82, 0x0a, 0x66, 0x6d,
-	0x31, 0xb3, 0x24, 0x8f, 0x98, 0x2d, 0x86, 0x85, 0x44, 0x32, 0xeb, 0x8b, 0x99, 0x25, 0x39, 0x22,
-	0xea, 0x0f, 0xd5, 0x61, 0xe2, 0x72, 0xc9, 0xa1, 0xc7, 0xc3, 0xf7, 0xcf, 0x59, 0xb8, 0xf6, 0xd8,
-	0x0b, 0xf9, 0x63, 0x66, 0x3a, 0xfc, 0xa8, 0x75, 0xc4, 0x06, 0xc7, 0xe8, 0x50, 0xac, 0xfa, 0x91,
-	0x43, 0xf1, 0x9b, 0xdc, 0x07, 0xcd, 0x76, 0x39, 0x0b, 0x4e, 0x4c, 0x47, 0xc4, 0x20, 0x0e, 0x63,
-	0x49, 0xe5, 0x36, 0xd5, 0x31, 0x8e, 0x8e, 0x49, 0xc9, 0xcb, 0xa0, 0xf3, 0xa3, 0x80, 0x85, 0x47,
-	0x9e, 0x63, 0x29, 0x47, 0x4d, 0x10, 0xd8, 0xec, 0x8e, 0x6d, 0xc7, 0xe9, 0x5b, 0xde, 0xa9, 0xab,
-	0x7a, 0xbf, 0x86, 0x88, 0x4d, 0xef, 0xd4, 0xc5, 0x19, 0x30, 0xe4, 0x66, 0xc0, 0xc7, 0x7e, 0x2e,
-	0x2c, 0xfa, 0xd9, 0xb2, 0xa0, 0x8f, 0x22, 0x80, 0xc0, 0xb2, 0x6f, 0xf2, 0x23, 0xe1, 0x44, 0x9d,
-	0x8a, 0x6f, 0xc4, 0x1d, 0x79, 0x21, 0x17, 0xbe, 0xd1, 0xa9, 0xf8, 0x46, 0xdb, 0x0c, 0x4d, 0x3e,
-	0x38, 0x12, 0x36, 0xd7, 0xa9, 0x04, 0x30, 0x77, 0xd4, 0x89, 0x11, 0x84, 0xd6, 0x0a, 0x32, 0x7e,
-	0x0e, 0x25, 0x34, 0x57, 0x8f, 0x05, 0x27, 0xf6, 0x60, 0x36, 0xce, 0x33, 0xb3, 0x71, 0x7e, 0x1d,
-	0x0a, 0x32, 0x48, 0x54, 0xdb, 0x50, 0x10, 0x79, 0x17, 0xf2, 0x03, 0x34, 0xb7, 0x32, 0x67, 0x22,
-	0xfc, 0x13, 0x3e, 0xa1, 0x92, 0xd6, 0x60, 0xb0, 0xbc, 0xeb, 0x05, 0x72, 0x73, 0x5e, 0xc0, 0xd5,
-	0x84, 0x20, 0xbe, 0x71, 0x23, 0xc2, 0x26, 0x91, 0x93, 0xe5, 0xd9, 0xfc, 0x5d, 0x28, 0x86, 0x52,
-	0xd9, 0xb1, 0xdf, 0x66, 0x7e, 0x48, 0xed, 0x86, 0x46, 0x94, 0x46, 0x1b, 0xf4, 0x1f, 0x7b, 0xce,
-	0x68, 0x88, 0xb9, 0x3e, 0x36, 0x64, 0x26, 0x66, 0xc8, 0xd7, 0x30, 0x0b, 0x45, 0xb8, 0x7a, 0x6e,
-	0x3f, 0xe4, 0xea, 0x48, 0xa9, 0xd1, 0xb2, 0xc4, 0x76, 0xdd, 0x1e, 0xf7, 0x7c, 0xe3, 0x11, 0x54,
-	0x51, 0x3c, 0x65, 0xa1, 0x37, 0x0a, 0x06, 0xac, 0xe7, 0xb3, 0x81, 0x4c, 0x3e, 0x59, 0x76, 0x50,
-	0x60, 0x8e, 0x46, 0x20, 0xea, 0xef, 0xd8, 0x43, 0x5b, 0x96, 0xa3, 0x1c, 0x95, 0x80, 0xf1, 0xfb,
-	0x0c, 0xe8, 0x28, 0xe4, 0x89, 0x37, 0x72, 0x45, 0x5a, 0x3b, 0xde, 0x40, 0xb8, 0x5b, 0xe9, 0x33,
-	0x86, 0xd1, 0xd0, 0xdc, 0x0c, 0x0e, 0x19, 0x57, 0x06, 0x50, 0x10, 0xc6, 0xe0, 0x69, 0x60, 0x73,
-	0x66, 0xee, 0x3b, 0xd2, 0x06, 0x1a, 0x9d, 0x20, 0xe4, 0xf9, 0x5e, 0x98, 0x67, 0x59, 0x72, 0x49,
-	0x08, 0x77, 0x6d, 0x99, 0xdc, 0x54, 0xc5, 0x43, 0x7c, 0xa3, 0x86, 0x07, 0x0e, 0x36, 0xb2, 0x82,
-	0xec, 0x31, 0x02, 0x30, 0xbe, 0xce, 0x00, 0xd9, 0xb1, 0xf7, 0x5b, 0x9e, 0xcb, 0x4d, 0xdb, 0x65,
-	0xc1, 0xe6, 0x58, 0xc0, 0x38, 0x8b, 0xf2, 0x2a, 0x8b, 0x22, 0x53, 0x66, 0x63, 0xa6, 0x14, 0xf1,
-	0xf7, 0xb9, 0x17, 0x08, 0xd5, 0x72, 0x54, 0x02, 0x02, 0x6b, 0xbb, 0x5e, 0x20, 0xb4, 0x42, 0x2c,
-	0x02, 0x64, 0x0d, 0x4a, 0x3e, 0x0b, 0x86, 0xb6, 0xba, 0x26, 0x90, 0xba, 0xc5, 0x51, 0x98, 0x52,
-	0x07, 0xb6, 0xc3, 0xfa, 0x43, 0xcf, 0x92, 0xd5, 0xad, 0x42, 0x35, 0x44, 0x3c, 0xf1, 0x2c, 0x86,
-	0x85, 0x61, 0x64, 0x5b, 0x22, 0x23, 0x2a, 0x14, 0x3f, 0x11, 0x73, 0x68, 0x5b, 0x22, 0x1f, 0x2a,
-	0x14, 0x3f, 0xf1, 0x87, 0x4d, 0xc7, 0xf1, 0x4e, 0x45, 0x3a, 0x68, 0x54, 0x02, 0xc6, 0xaf, 0x0a,
-	0x50, 0x8a, 0x1a, 0xa0, 0xda, 0x88, 0x19, 0x1c, 0xca, 0x79, 0x4a, 0xa7, 0xe2, 0x9b, 0xbc, 0x07,
-	0x39, 0xe6, 0x9e, 0xa8, 0x71, 0xc1, 0x98, 0x8e, 0xb2, 0x18, 0x6f, 0xa3, 0xed, 0x9e, 0xc8, 0x92,
-	0x8e, 0xe4, 0x64, 0x1d, 0xf2, 0x18, 0xbd, 0x51, 0x17, 0x20, 0x09, 0x3e, 0x2f, 0xe0, 0x54, 0x12,
-	0x90, 0x77, 0xa0, 0x78, 0x22, 0x82, 0x32, 0x3a, 0x0e, 0xbe, 0x38, 0x4d, 0x3b, 0x8e, 0x58, 0x1a,
-	0xd1, 0xa1, 0x9a, 0xde, 0xd0, 0xb5, 0x55, 0x6d, 0x11, 0xdf, 0x98, 0xb2, 0x98, 0xf7, 0x7d, 0x97,
-	0xf1, 0x53, 0x2f, 0x38, 0x56, 0x87, 0x89, 0x12, 0xe2, 0x3a, 0x12, 0x45, 0xde, 0x02, 0x22, 0x48,
-	0x7c, 0xdb, 0x12, 0x69, 0x1d, 0xfa, 0xe6, 0x80, 0xa9, 0x21, 0xb5, 0x8a, 0x2b, 0xbb, 0xb6, 0xd5,
-	0x89, 0xf0, 0x18, 0xd1, 0x51, 0x86, 0xc9, 0xba, 0x12, 0x81, 0x18, 0x79, 0x01, 0x0b, 0x47, 0x41,
-	0xc0, 0x06, 0x5c, 0xd9, 0x73, 0x82, 0x20, 0x5b, 0x62, 0x55, 0x64, 0x06, 0x56, 0x19, 0xdc, 0xd1,
-	0xfa, 0x7c, 0xab, 0x45, 0x49, 0x14, 0x4d, 0x57, 0x63, 0x56, 0x72, 0x0f, 0x0a, 0x43, 0x4c, 0x8e,
-	0xb0, 0x56, 0x4a, 0x33, 0xcb, 0x38, 0x79, 0xa8, 0x22, 0x23, 0x77, 0x81, 0x38, 0xb6, 0x3b, 0x3a,
-	0xeb, 0x0f, 0x4c, 0xdf, 0xdc, 0xb7, 0x1d, 0x9b, 0xdb, 0x2c, 0xac, 0x95, 0x85, 0x2b, 0x57, 0xc5,
-	0x4a, 0x2b, 0xb6, 0x40, 0xb6, 0xe1, 0x9a, 0x08, 0x02, 0x66, 0xf5, 0x65, 0x6e, 0x84, 0xb5, 0x4a,
-	0xda, 0x18, 0x3d, 0x9b, 0x03, 0x74, 0x45, 0x31, 0x4a, 0x30, 0x24, 0xff, 0x07, 0xab, 0xe3, 0xcc,
-	0xeb, 0x0f, 0x0e, 0x03, 0x6f, 0xe4, 0x87, 0xb5, 0x15, 0x69, 0xd7, 0xf1, 0x42, 0x4b, 0xe2, 0xeb,
-	0x0f, 0x40, 0x8b, 0x42, 0xe5, 0x2a, 0x2d, 0xad, 0xfe, 0x19, 0xac, 0x4c, 0x1b, 0x2b, 0x85, 0xfb,
-	0xbd, 0xe9, 0xa1, 0xef, 0xe6, 0xac, 0xc9, 0xe2, 0x45, 0x2b, 0xde, 0x30, 0xbf, 0x5e, 0x86, 0xa2,
-	0x9a, 0xd1, 0x52, 0x27, 0x9f, 0x97, 0x41, 0x37, 0x03, 0x6e, 0x1f, 0x98, 0x03, 0x1e, 0x9d, 0xc0,
-	0x26, 0x08, 0xf2, 0xb6, 0xcc, 0x11, 0x19, 0xbf, 0x37, 0x53, 0x27, 0xbf, 0x44, 0x7e, 0x3c, 0x1c,
-	0x8f, 0x49, 0x79, 0xc1, 0x74, 0x2b, 0x9d, 0x29, 0x6d, 0x54, 0x7a, 0x14, 0x9f, 0xe2, 0x0b, 0x69,
-	0x57, 0x8a, 0x11, 0xf7, 0xfc, 0xd1, 0xfd, 0xae, 0xaa, 0x62, 0x45, 0x71, 0x63, 0x78, 0xc1, 0x41,
-	0x51, 0x16, 0xb8, 0xc4, 0x00, 0xa5, 0x5d, 0x75, 0x80, 0xfa, 0xe6, 0x33, 0xd0, 0x37, 0x8d, 0x96,
-	0x6f, 0x3e, 0x3b, 0xd5, 0x3f, 0xb9, 0xc4, 0xb1, 0xe4, 0xde, 0x74, 0xa0, 0xdd, 0x98, 0x9b, 0xe0,
-	0xf1, 0x18, 0xfb, 0x67, 0x01, 0xca, 0x97, 0x3a, 0xa7, 0x45, 0x01, 0x98, 0x8d, 0x05, 0xe0, 0x7d,
-	0xc8, 0x8b, 0x5b, 0x16, 0xd1, 0x4f, 0x2e, 0x71, 0x27, 0x23, 0xa9, 0xc9, 0x8f, 0xa0, 0xe2, 0x39,
-	0x56, 0x7f, 0x12, 0x30, 0x32, 0x46, 0xdf, 0x9a, 0xcf, 0xde, 0xe8, 0x3a, 0x56, 0x22, 0x70, 0xca,
-	0x5e, 0x0c, 0x85, 0x22, 0x5d, 0x76, 0x1a, 0x13, 0x99, 0x5f, 0x28, 0xb2, 0xc3, 0x4e, 0x93, 0x22,
-	0xdd, 0x18, 0x8a, 0x3c, 0x02, 0x0d, 0xb5, 0xe4, 0xb2, 0x09, 0xa3, 0xb4, 0x37, 0x2f, 0x56, 0x70,
-	0x72, 0x94, 0x2c, 0x7a, 0x12, 0x42, 0x19, 0xa8, 0x96, 0x90, 0x51, 0x5c, 0x28, 0xa3, 0xc3, 0x4e,
-	0x63, 0x32, 0x5c, 0x09, 0xfd, 0xdb, 0x71, 0x1e, 0x3f, 0x28, 0xe8, 0x57, 0x39, 0x28, 0xd4, 0x3f,
-	0x82, 0xd5, 0x19, 0xbb, 0x5f, 0xe9, 0x38, 0xfc, 0x11, 0xac, 0xce, 0x58, 0xf9, 0x4a, 0x02, 0xfa,
-	0x50, 0x8e, 0x1b, 0xf6, 0x99, 0x1f, 0xa9, 0xf1, 0x07, 0xe2, 0x56, 0x7f, 0xf6, 0x67, 0xf6, 0x5f,
-	0x67, 0xe0, 0x85, 0x54, 0x22, 0xd2, 0x54, 0x37, 0x15, 0xf2, 0x9e, 0xe8, 0xee, 0x25, 0xe4, 0xce,
-	0x5c, 0x56, 0xfc, 0xff, 0xc5, 0xd7, 0x0d, 0xf3, 0x8f, 0x62, 0xbf, 0x2b, 0x00, 0x99, 0xbd, 0x8a,
-	0x4a, 0x6d, 0x32, 0x0f, 0xa0, 0x84, 0x69, 0x10, 0x9d, 0x62, 0x73, 0x17, 0x5d, 0x24, 0x80, 0xe7,
-	0x58, 0x51, 0xc3, 0x7a, 0x00, 0x25, 0x0c, 0xfd, 0x88, 0x6f, 0xf9, 0x42, 0x3e, 0x97, 0x9d, 0x46,
-	0x7c, 0x51, 0x17, 0xc8, 0x5f, 0xae, 0x0b, 0xc4, 0x0f, 0xdf, 0x85, 0xc4, 0xe1, 0xfb, 0xc1, 0xf8,
-	0x60, 0x25, 0x5b, 0xca, 0xa2, 0xbb, 0xc3, 0xe8, 0x1d, 0x6e, 0xea, 0x4a, 0x4a, 0x4b, 0xbb, 0x92,
-	0x9a, 0xb5, 0xdd, 0x25, 0xae, 0xa4, 0xf4, 0xb4, 0x2b, 0xa9, 0x14, 0x49, 0x09, 0x2f, 0xa7, 0x5c,
-	0x43, 0x40, 0xda, 0x35, 0x44, 0xa2, 0x4e, 0x94, 0xae, 0x5a, 0x27, 0xd8, 0x99, 0x6f, 0x07, 0x8a,
-	0xb9, 0xbc, 0x98, 0x59, 0x92, 0x0b, 0xe6, 0xfb, 0xa0, 0x31, 0xd7, 0x92, 0x9c, 0x95, 0x85, 0x9c,
-	0x45, 0xe6, 0x5a, 0xa2, 0xbc, 0xfc, 0x37, 0x5f, 0xb5, 0xfd, 0x2d, 0x0b, 0xd7, 0x26, 0x44, 0xed,
-	0x13, 0xcc, 0x8e, 0x79, 0x9d, 0xf1, 0x06, 0x68, 0x9f, 0x7b, 0xfb, 0x7d, 0x11, 0xb5, 0x32, 0xd3,
-	0x8a, 0x9f, 0x7b, 0xfb, 0xe2, 0xec, 0xd2, 0x02, 0x1d, 0x97, 0xe2, 0x4d, 0xf2, 0x8d, 0x79, 0x9a,
-	0x88, 0x1f, 0x69, 0xfc, 0xc0, 0xdb, 0x97, 0xbd, 0x12, 0x65, 0x8a, 0x2f, 0xb4, 0x41, 0x3b, 0x08,
-	0xd4, 0xf9, 0x4c, 0xa7, 0x12, 0x48, 0xba, 0x3b, 0x7f, 0x15, 0x77, 0x1b, 0xc7, 0xa0, 0x45, 0x3f,
-	0x44, 0x4a, 0x50, 0xdc, 0x6d, 0x77, 0x36, 0xb7, 0x3b, 0x1f, 0x57, 0x97, 0x10, 0x78, 0xb4, 0xd3,
-	0x6d, 0xfd, 0xb0, 0xbd, 0x59, 0xcd, 0x90, 0x32, 0x68, 0xbd, 0xbd, 0x26, 0xdd, 0xc3, 0xa5, 0x2c,
-	0x29, 0x40, 0xf6, 0xe9, 0x6e, 0x35, 0x27, 0xb1, 0xdd, 0xdd, 0x5d, 0xc4, 0xe6, 0x89, 0x06, 0xcb,
-	0x9b, 0xdd, 0x4f, 0x3a, 0xd5, 0x02, 0xb2, 0xb6, 0x68, 0xb3, 0xf7, 0xb8, 0xbd, 0x59, 0x2d, 0x12,
-	0x80, 0xc2, 0x56, 0x73, 0x7b, 0xa7, 0xbd, 0x59, 0xd5, 0xee, 0xbc, 0x05, 0xa5, 0x58, 0xde, 0x92,
-	0x22, 0xe4, 0x9a, 0x9d, 0x4f, 0xab, 0x4b, 0xc8, 0xda, 0xea, 0x6e, 0xb6, 0xab, 0x19, 0xa4, 0x6e,
-	0x75, 0x3b, 0x5b, 0xdb, 0x1f, 0x57, 0xb3, 0x77, 0xba, 0xb0, 0x3a, 0x33, 0x38, 0x90, 0x55, 0xa8,
-	0xf4, 0x5a, 0xcd, 0x9d, 0x76, 0x7f, 0xa2, 0xe9, 0x73, 0x70, 0x4d, 0xa2, 0x5a, 0xcd, 0x4e, 0xab,
-	0xbd, 0xb3, 0x23, 0x34, 0x26, 0xb0, 0xa2, 0x90, 0xdd, 0x27, 0xbb, 0x3b, 0xed, 0xbd, 0x76, 0x35,
-	0x7b, 0x67, 0x0b, 0xaa, 0xc9, 0x4c, 0x9f, 0xde, 0xf3, 0x44, 0xd7, 0x0c, 0x2e, 0xd0, 0xa7, 0x9d,
-	0x8e, 0xdc, 0x71, 0x19, 0xb4, 0xb1, 0x9c, 0xdc, 0xc6, 0x97, 0x05, 0x80, 0xd6, 0xf8, 0xef, 0x08,
-	0xe4, 0x43, 0x28, 0x28, 0x61, 0xd7, 0x67, 0x8c, 0xde, 0x1e, 0xfa, 0xfc, 0xbc, 0xfe, 0xf2, 0x45,
-	0x2f, 0xff, 0xa4, 0x07, 0x30, 0x79, 0x7a, 0x25, 0xc9, 0xd1, 0x29, 0xf9, 0x2e, 0x5d, 0x5f, 0x9b,
-	0x4f, 0x20, 0x05, 0x1a, 0x4b, 0x6f, 0x67, 0x48, 0x1f, 0x56, 0xa6, 0x5f, 0x0f, 0xc9, 0xed, 0x34,
-	0xbe, 0xc4, 0x13, 0x6b, 0xfd, 0xb5, 0x8b, 0x89, 0x62, 0x3f, 0xf0, 0x29, 0x94, 0xe3, 0x0f, 0x5f,
-	0xe4, 0x56, 0x1a, 0xe7, 0xd4, 0xfb, 0x60, 0xdd, 0xb8, 0x88, 0x24, 0x26, 0xfa, 0x28, 0x7a, 0x8e,
-	0x8f, 0xbd, 0xad, 0x90, 0x37, 0xd2, 0x98, 0x67, 0xdf, 0xb2, 0xea, 0x6f, 0x2e, 0xa4, 0x8b, 0xfd,
-	0xd2, 0xf7, 0x41, 0x1f, 0xbf, 0x8b, 0x90, 0x44, 0x53, 0x48, 0x3e, 0x98, 0xd4, 0x67, 0xdf, 0x48,
-	0x8c, 0x25, 0xf2, 0x04, 0x4a, 0xb1, 0x3b, 0x6b, 0xb2, 0xf0, 0x3a, 0xbb, 0x7e, 0xc1, 0x03, 0xa0,
-	0xb1, 0x44, 0x76, 0xa0, 0x32, 0x75, 0xb1, 0x4f, 0x8c, 0x34, 0x81, 0xd3, 0xb7, 0xfe, 0xf5, 0xf4,
-	0x5e, 0x6a, 0x2c, 0x91, 0xcf, 0xa0, 0x9a, 0xbc, 0xa6, 0x27, 0xaf, 0xa7, 0x09, 0x9c, 0xb9, 0xc6,
-	0xaf, 0xbf, 0x72, 0x61, 0x71, 0x42, 0xe3, 0x3d, 0xca, 0xff, 0x34, 0x67, 0xfa, 0xf6, 0x7e, 0x41,
-	0x04, 0xfb, 0xbb, 0xff, 0x0a, 0x00, 0x00, 0xff, 0xff, 0x74, 0xfa, 0x56, 0xa2, 0xa2, 0x23, 0x00,
-	0x00,
+func (m *StreamJobsRequest) Reset()         { *m = StreamJobsRequest{} }
+func (m *StreamJobsRequest) String() string { return proto.CompactTextString(m) }
+func (*StreamJobsRequest) ProtoMessage()    {}
+func (*StreamJobsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{28}
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *StreamJobsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamJobsRequest.Unmarshal(m, b)
+}
+func (m *StreamJobsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamJobsRequest.Marshal(b, m, deterministic)
+}
+func (m *StreamJobsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamJobsRequest.Merge(m, src)
+}
+func (m *StreamJobsRequest) XXX_Size() int {
+	return xxx_messageInfo_StreamJobsRequest.Size(m)
+}
+func (m *StreamJobsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamJobsRequest.DiscardUnknown(m)
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+var xxx_messageInfo_StreamJobsRequest proto.InternalMessageInfo
 
-// ControllerClient is the client API for Controller service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type ControllerClient interface {
-	Status(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*StatusResponse, error)
-	StreamApps(ctx context.Context, in *StreamAppsRequest, opts ...grpc.CallOption) (Controller_StreamAppsClient, error)
-	StreamReleases(ctx context.Context, in *StreamReleasesRequest, opts ...grpc.CallOption) (Controller_StreamReleasesClient, error)
-	StreamScales(ctx context.Context, in *StreamScalesRequest, opts ...grpc.CallOption) (Controller_StreamScalesClient, error)
-	StreamDeployments(ctx context.Context, in *StreamDeploymentsRequest, opts ...grpc.CallOption) (Controller_StreamDeploymentsClient, error)
-	UpdateApp(ctx context.Context, in *UpdateAppRequest, opts ...grpc.CallOption) (*App, error)
-	CreateScale(ctx context.Context, in *CreateScaleRequest, opts ...grpc.CallOption) (*ScaleRequest, error)
-	CreateRelease(ctx context.Context, in *CreateReleaseRequest, opts ...grpc.CallOption) (*Release, error)
-	CreateDeployment(ctx context.Context, in *CreateDeploymentRequest, opts ...grpc.CallOption) (Controller_CreateDeploymentClient, error)
+func (m *StreamJobsRequest) GetNameFilters() []string {
+	if m != nil {
+		return m.NameFilters
+	}
+	return nil
 }
 
-type controllerClient struct {
-	cc *grpc.ClientConn
+func (m *StreamJobsRequest) GetStateFilters() []DeploymentEvent_JobState {
+	if m != nil {
+		return m.StateFilters
+	}
+	return nil
 }
 
-func NewControllerClient(cc *grpc.ClientConn) ControllerClient {
-	return &controllerClient{cc}
+func (m *StreamJobsRequest) GetStreamUpdates() bool {
+	if m != nil {
+		return m.StreamUpdates
+	}
+	return false
 }
 
-func (c *controllerClient) Status(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*StatusResponse, error) {
-	out := new(StatusResponse)
-	err := c.cc.Invoke(ctx, "/flynn.api.v1.Controller/Status", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *StreamJobsRequest) GetStreamCreates() bool {
+	if m != nil {
+		return m.StreamCreates
 	}
-	return out, nil
+	return false
 }
 
-func (c *controllerClient) StreamApps(ctx context.Context, in *StreamAppsRequest, opts ...grpc.CallOption) (Controller_StreamAppsClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_Controller_serviceDesc.Streams[0], "/flynn.api.v1.Controller/StreamApps", opts...)
-	if err != nil {
-		return nil, err
-	}
-	x := &controllerStreamAppsClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
-	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
-	}
-	return x, nil
+type StreamJobsResponse struct {
+	Jobs []*Job `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	// Set to true on the last response for the initial page.
+	PageComplete         bool     `protobuf:"varint,2,opt,name=page_complete,json=pageComplete,proto3" json:"page_complete,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-type Controller_StreamAppsClient interface {
-	Recv() (*StreamAppsResponse, error)
-	grpc.ClientStream
+func (m *StreamJobsResponse) Reset()         { *m = StreamJobsResponse{} }
+func (m *StreamJobsResponse) String() string { return proto.CompactTextString(m) }
+func (*StreamJobsResponse) ProtoMessage()    {}
+func (*StreamJobsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{29}
 }
 
-type controllerStreamAppsClient struct {
-	grpc.ClientStream
+func (m *StreamJobsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamJobsResponse.Unmarshal(m, b)
+}
+func (m *StreamJobsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamJobsResponse.Marshal(b, m, deterministic)
+}
+func (m *StreamJobsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamJobsResponse.Merge(m, src)
+}
+func (m *StreamJobsResponse) XXX_Size() int {
+	return xxx_messageInfo_StreamJobsResponse.Size(m)
+}
+func (m *StreamJobsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamJobsResponse.DiscardUnknown(m)
 }
 
-func (x *controllerStreamAppsClient) Recv() (*StreamAppsResponse, error) {
-	m := new(StreamAppsResponse)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
+var xxx_messageInfo_StreamJobsResponse proto.InternalMessageInfo
+
+func (m *StreamJobsResponse) GetJobs() []*Job {
+	if m != nil {
+		return m.Jobs
 	}
-	return m, nil
+	return nil
 }
 
-func (c *controllerClient) StreamReleases(ctx context.Context, in *StreamReleasesRequest, opts ...grpc.CallOption) (Controller_StreamReleasesClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_Controller_serviceDesc.Streams[1], "/flynn.api.v1.Controller/StreamReleases", opts...)
-	if err != nil {
-		return nil, err
-	}
-	x := &controllerStreamReleasesClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
-	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
+func (m *StreamJobsResponse) GetPageComplete() bool {
+	if m != nil {
+		return m.PageComplete
 	}
-	return x, nil
+	return false
 }
 
-type Controller_StreamReleasesClient interface {
-	Recv() (*StreamReleasesResponse, error)
-	grpc.ClientStream
+type CreateJobRequest struct {
+	// parent = "apps/APP_ID/releases/RELEASE_ID"
+	Parent string `protobuf:"bytes,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	// artifacts override the release's artifacts when set
+	Artifacts []string `protobuf:"bytes,2,rep,name=artifacts,proto3" json:"artifacts,omitempty"`
+	// When true, the job's env includes the release's env
+	ReleaseEnv bool                         `protobuf:"varint,3,opt,name=release_env,json=releaseEnv,proto3" json:"release_env,omitempty"`
+	Args       []string                     `protobuf:"bytes,4,rep,name=args,proto3" json:"args,omitempty"`
+	Env        map[string]string            `protobuf:"bytes,5,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Labels     map[string]string            `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	DisableLog bool                         `protobuf:"varint,7,opt,name=disable_log,json=disableLog,proto3" json:"disable_log,omitempty"`
+	Resources  map[string]*HostResourceSpec `protobuf:"bytes,8,rep,name=resources,proto3" json:"resources,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// When true, a /data volume is provisioned for the job
+	Data      bool     `protobuf:"varint,9,opt,name=data,proto3" json:"data,omitempty"`
+	Partition string   `protobuf:"bytes,10,opt,name=partition,proto3" json:"partition,omitempty"`
+	Profiles  []string `protobuf:"bytes,11,rep,name=profiles,proto3" json:"profiles,omitempty"`
+	// mounts_from is a process type to copy mounts from
+	MountsFrom           string   `protobuf:"bytes,12,opt,name=mounts_from,json=mountsFrom,proto3" json:"mounts_from,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-type controllerStreamReleasesClient struct {
-	grpc.ClientStream
+func (m *CreateJobRequest) Reset()         { *m = CreateJobRequest{} }
+func (m *CreateJobRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateJobRequest) ProtoMessage()    {}
+func (*CreateJobRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{30}
 }
 
-func (x *controllerStreamReleasesClient) Recv() (*StreamReleasesResponse, error) {
-	m := new(StreamReleasesResponse)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
-	}
-	return m, nil
+func (m *CreateJobRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateJobRequest.Unmarshal(m, b)
+}
+func (m *CreateJobRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateJobRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateJobRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateJobRequest.Merge(m, src)
+}
+func (m *CreateJobRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateJobRequest.Size(m)
+}
+func (m *CreateJobRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateJobRequest.DiscardUnknown(m)
 }
 
-func (c *controllerClient) StreamScales(ctx context.Context, in *StreamScalesRequest, opts ...grpc.CallOption) (Controller_StreamScalesClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_Controller_serviceDesc.Streams[2], "/flynn.api.v1.Controller/StreamScales", opts...)
-	if err != nil {
-		return nil, err
-	}
-	x := &controllerStreamScalesClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
-	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
+var xxx_messageInfo_CreateJobRequest proto.InternalMessageInfo
+
+func (m *CreateJobRequest) GetParent() string {
+	if m != nil {
+		return m.Parent
 	}
-	return x, nil
+	return ""
 }
 
-type Controller_StreamScalesClient interface {
-	Recv() (*StreamScalesResponse, error)
-	grpc.ClientStream
+func (m *CreateJobRequest) GetArtifacts() []string {
+	if m != nil {
+		return m.Artifacts
+	}
+	return nil
 }
 
-type controllerStreamScalesClient struct {
-	grpc.ClientStream
+func (m *CreateJobRequest) GetReleaseEnv() bool {
+	if m != nil {
+		return m.ReleaseEnv
+	}
+	return false
 }
 
-func (x *controllerStreamScalesClient) Recv() (*StreamScalesResponse, error) {
-	m := new(StreamScalesResponse)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
+func (m *CreateJobRequest) GetArgs() []string {
+	if m != nil {
+		return m.Args
 	}
-	return m, nil
+	return nil
 }
 
-func (c *controllerClient) StreamDeployments(ctx context.Context, in *StreamDeploymentsRequest, opts ...grpc.CallOption) (Controller_StreamDeploymentsClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_Controller_serviceDesc.Streams[3], "/flynn.api.v1.Controller/StreamDeployments", opts...)
-	if err != nil {
-		return nil, err
-	}
-	x := &controllerStreamDeploymentsClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
-	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
+func (m *CreateJobRequest) GetEnv() map[string]string {
+	if m != nil {
+		return m.Env
 	}
-	return x, nil
+	return nil
 }
 
-type Controller_StreamDeploymentsClient interface {
-	Recv() (*StreamDeploymentsResponse, error)
-	grpc.ClientStream
+func (m *CreateJobRequest) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
 }
 
-type controllerStreamDeploymentsClient struct {
-	grpc.ClientStream
+func (m *CreateJobRequest) GetDisableLog() bool {
+	if m != nil {
+		return m.DisableLog
+	}
+	return false
+}
+
+func (m *CreateJobRequest) GetResources() map[string]*HostResourceSpec {
+	if m != nil {
+		return m.Resources
+	}
+	return nil
+}
+
+func (m *CreateJobRequest) GetData() bool {
+	if m != nil {
+		return m.Data
+	}
+	return false
+}
+
+func (m *CreateJobRequest) GetPartition() string {
+	if m != nil {
+		return m.Partition
+	}
+	return ""
+}
+
+func (m *CreateJobRequest) GetProfiles() []string {
+	if m != nil {
+		return m.Profiles
+	}
+	return nil
+}
+
+func (m *CreateJobRequest) GetMountsFrom() string {
+	if m != nil {
+		return m.MountsFrom
+	}
+	return ""
+}
+
+type DeleteJobRequest struct {
+	// name = "apps/APP_ID/jobs/JOB_ID"
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteJobRequest) Reset()         { *m = DeleteJobRequest{} }
+func (m *DeleteJobRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteJobRequest) ProtoMessage()    {}
+func (*DeleteJobRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{31}
+}
+
+func (m *DeleteJobRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteJobRequest.Unmarshal(m, b)
+}
+func (m *DeleteJobRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteJobRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteJobRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteJobRequest.Merge(m, src)
+}
+func (m *DeleteJobRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteJobRequest.Size(m)
+}
+func (m *DeleteJobRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteJobRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteJobRequest proto.InternalMessageInfo
+
+func (m *DeleteJobRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type Job struct {
+	// name = "apps/APP_ID/jobs/JOB_UUID"
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// release = Release.name
+	Release string `protobuf:"bytes,2,opt,name=release,proto3" json:"release,omitempty"`
+	// job_id is the job's full cluster ID (i.e. HOST_ID-JOB_UUID), which is
+	// empty if the job has not been placed on a host
+	JobId  string                   `protobuf:"bytes,3,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	HostId string                   `protobuf:"bytes,4,opt,name=host_id,json=hostId,proto3" json:"host_id,omitempty"`
+	Type   string                   `protobuf:"bytes,5,opt,name=type,proto3" json:"type,omitempty"`
+	State  DeploymentEvent_JobState `protobuf:"varint,6,opt,name=state,proto3,enum=flynn.api.v1.DeploymentEvent_JobState" json:"state,omitempty"`
+	Args   []string                 `protobuf:"bytes,7,rep,name=args,proto3" json:"args,omitempty"`
+	// volumes are the IDs of the job's volumes
+	Volumes []string          `protobuf:"bytes,8,rep,name=volumes,proto3" json:"volumes,omitempty"`
+	Labels  map[string]string `protobuf:"bytes,9,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// exit_status is only set if the job exited without a host error
+	ExitStatus           int32                `protobuf:"varint,10,opt,name=exit_status,json=exitStatus,proto3" json:"exit_status,omitempty"`
+	HostError            string               `protobuf:"bytes,11,opt,name=host_error,json=hostError,proto3" json:"host_error,omitempty"`
+	Restarts             int32                `protobuf:"varint,12,opt,name=restarts,proto3" json:"restarts,omitempty"`
+	BlockedReason        string               `protobuf:"bytes,13,opt,name=blocked_reason,json=blockedReason,proto3" json:"blocked_reason,omitempty"`
+	PreemptedBy          string               `protobuf:"bytes,14,opt,name=preempted_by,json=preemptedBy,proto3" json:"preempted_by,omitempty"`
+	CrashLoopReason      string               `protobuf:"bytes,15,opt,name=crash_loop_reason,json=crashLoopReason,proto3" json:"crash_loop_reason,omitempty"`
+	RunTime              *timestamp.Timestamp `protobuf:"bytes,16,opt,name=run_time,json=runTime,proto3" json:"run_time,omitempty"`
+	CreateTime           *timestamp.Timestamp `protobuf:"bytes,17,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	UpdateTime           *timestamp.Timestamp `protobuf:"bytes,18,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Job) Reset()         { *m = Job{} }
+func (m *Job) String() string { return proto.CompactTextString(m) }
+func (*Job) ProtoMessage()    {}
+func (*Job) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{32}
+}
+
+func (m *Job) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Job.Unmarshal(m, b)
+}
+func (m *Job) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Job.Marshal(b, m, deterministic)
+}
+func (m *Job) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Job.Merge(m, src)
+}
+func (m *Job) XXX_Size() int {
+	return xxx_messageInfo_Job.Size(m)
+}
+func (m *Job) XXX_DiscardUnknown() {
+	xxx_messageInfo_Job.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Job proto.InternalMessageInfo
+
+func (m *Job) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Job) GetRelease() string {
+	if m != nil {
+		return m.Release
+	}
+	return ""
+}
+
+func (m *Job) GetJobId() string {
+	if m != nil {
+		return m.JobId
+	}
+	return ""
+}
+
+func (m *Job) GetHostId() string {
+	if m != nil {
+		return m.HostId
+	}
+	return ""
+}
+
+func (m *Job) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Job) GetState() DeploymentEvent_JobState {
+	if m != nil {
+		return m.State
+	}
+	return DeploymentEvent_PENDING
+}
+
+func (m *Job) GetArgs() []string {
+	if m != nil {
+		return m.Args
+	}
+	return nil
+}
+
+func (m *Job) GetVolumes() []string {
+	if m != nil {
+		return m.Volumes
+	}
+	return nil
+}
+
+func (m *Job) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *Job) GetExitStatus() int32 {
+	if m != nil {
+		return m.ExitStatus
+	}
+	return 0
+}
+
+func (m *Job) GetHostError() string {
+	if m != nil {
+		return m.HostError
+	}
+	return ""
+}
+
+func (m *Job) GetRestarts() int32 {
+	if m != nil {
+		return m.Restarts
+	}
+	return 0
+}
+
+func (m *Job) GetBlockedReason() string {
+	if m != nil {
+		return m.BlockedReason
+	}
+	return ""
+}
+
+func (m *Job) GetPreemptedBy() string {
+	if m != nil {
+		return m.PreemptedBy
+	}
+	return ""
+}
+
+func (m *Job) GetCrashLoopReason() string {
+	if m != nil {
+		return m.CrashLoopReason
+	}
+	return ""
+}
+
+func (m *Job) GetRunTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.RunTime
+	}
+	return nil
+}
+
+func (m *Job) GetCreateTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreateTime
+	}
+	return nil
+}
+
+func (m *Job) GetUpdateTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.UpdateTime
+	}
+	return nil
+}
+
+type StreamRoutesRequest struct {
+	// Specifies an optional list of resource names that should be looked up.
+	// This can be used to request a known set of one or more resources and
+	// optionally receive updates about them, and can also be used to retrieve
+	// a single resource. Parent resource names may also be used to filter
+	// resources.
+	NameFilters []string `protobuf:"bytes,1,rep,name=name_filters,json=nameFilters,proto3" json:"name_filters,omitempty"`
+	// When true, leaves the stream open and sends any updates (including
+	// deletions) to each resource returned in the initial page until the
+	// stream is closed.
+	StreamUpdates bool `protobuf:"varint,2,opt,name=stream_updates,json=streamUpdates,proto3" json:"stream_updates,omitempty"`
+	// When true, leaves the stream open and sends newly created resources
+	// matching the filters until the stream is closed.
+	StreamCreates        bool     `protobuf:"varint,3,opt,name=stream_creates,json=streamCreates,proto3" json:"stream_creates,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamRoutesRequest) Reset()         { *m = StreamRoutesRequest{} }
+func (m *StreamRoutesRequest) String() string { return proto.CompactTextString(m) }
+func (*StreamRoutesRequest) ProtoMessage()    {}
+func (*StreamRoutesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{33}
+}
+
+func (m *StreamRoutesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamRoutesRequest.Unmarshal(m, b)
+}
+func (m *StreamRoutesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamRoutesRequest.Marshal(b, m, deterministic)
+}
+func (m *StreamRoutesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamRoutesRequest.Merge(m, src)
+}
+func (m *StreamRoutesRequest) XXX_Size() int {
+	return xxx_messageInfo_StreamRoutesRequest.Size(m)
+}
+func (m *StreamRoutesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamRoutesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamRoutesRequest proto.InternalMessageInfo
+
+func (m *StreamRoutesRequest) GetNameFilters() []string {
+	if m != nil {
+		return m.NameFilters
+	}
+	return nil
+}
+
+func (m *StreamRoutesRequest) GetStreamUpdates() bool {
+	if m != nil {
+		return m.StreamUpdates
+	}
+	return false
+}
+
+func (m *StreamRoutesRequest) GetStreamCreates() bool {
+	if m != nil {
+		return m.StreamCreates
+	}
+	return false
+}
+
+type StreamRoutesResponse struct {
+	Routes []*Route `protobuf:"bytes,1,rep,name=routes,proto3" json:"routes,omitempty"`
+	// Set to true on the last response for the initial page.
+	PageComplete         bool     `protobuf:"varint,2,opt,name=page_complete,json=pageComplete,proto3" json:"page_complete,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamRoutesResponse) Reset()         { *m = StreamRoutesResponse{} }
+func (m *StreamRoutesResponse) String() string { return proto.CompactTextString(m) }
+func (*StreamRoutesResponse) ProtoMessage()    {}
+func (*StreamRoutesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{34}
+}
+
+func (m *StreamRoutesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamRoutesResponse.Unmarshal(m, b)
+}
+func (m *StreamRoutesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamRoutesResponse.Marshal(b, m, deterministic)
+}
+func (m *StreamRoutesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamRoutesResponse.Merge(m, src)
+}
+func (m *StreamRoutesResponse) XXX_Size() int {
+	return xxx_messageInfo_StreamRoutesResponse.Size(m)
+}
+func (m *StreamRoutesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamRoutesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamRoutesResponse proto.InternalMessageInfo
+
+func (m *StreamRoutesResponse) GetRoutes() []*Route {
+	if m != nil {
+		return m.Routes
+	}
+	return nil
+}
+
+func (m *StreamRoutesResponse) GetPageComplete() bool {
+	if m != nil {
+		return m.PageComplete
+	}
+	return false
+}
+
+type CreateRouteRequest struct {
+	// parent = "apps/APP_ID"
+	Parent               string   `protobuf:"bytes,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	Route                *Route   `protobuf:"bytes,2,opt,name=route,proto3" json:"route,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateRouteRequest) Reset()         { *m = CreateRouteRequest{} }
+func (m *CreateRouteRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateRouteRequest) ProtoMessage()    {}
+func (*CreateRouteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{35}
+}
+
+func (m *CreateRouteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateRouteRequest.Unmarshal(m, b)
+}
+func (m *CreateRouteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateRouteRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateRouteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateRouteRequest.Merge(m, src)
+}
+func (m *CreateRouteRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateRouteRequest.Size(m)
+}
+func (m *CreateRouteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateRouteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateRouteRequest proto.InternalMessageInfo
+
+func (m *CreateRouteRequest) GetParent() string {
+	if m != nil {
+		return m.Parent
+	}
+	return ""
+}
+
+func (m *CreateRouteRequest) GetRoute() *Route {
+	if m != nil {
+		return m.Route
+	}
+	return nil
+}
+
+type UpdateRouteRequest struct {
+	Route                *Route   `protobuf:"bytes,1,opt,name=route,proto3" json:"route,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpdateRouteRequest) Reset()         { *m = UpdateRouteRequest{} }
+func (m *UpdateRouteRequest) String() string { return proto.CompactTextString(m) }
+func (*UpdateRouteRequest) ProtoMessage()    {}
+func (*UpdateRouteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{36}
+}
+
+func (m *UpdateRouteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateRouteRequest.Unmarshal(m, b)
+}
+func (m *UpdateRouteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateRouteRequest.Marshal(b, m, deterministic)
+}
+func (m *UpdateRouteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateRouteRequest.Merge(m, src)
+}
+func (m *UpdateRouteRequest) XXX_Size() int {
+	return xxx_messageInfo_UpdateRouteRequest.Size(m)
+}
+func (m *UpdateRouteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateRouteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateRouteRequest proto.InternalMessageInfo
+
+func (m *UpdateRouteRequest) GetRoute() *Route {
+	if m != nil {
+		return m.Route
+	}
+	return nil
+}
+
+type DeleteRouteRequest struct {
+	// name = "apps/APP_ID/routes/ROUTE_ID"
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteRouteRequest) Reset()         { *m = DeleteRouteRequest{} }
+func (m *DeleteRouteRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteRouteRequest) ProtoMessage()    {}
+func (*DeleteRouteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{37}
+}
+
+func (m *DeleteRouteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteRouteRequest.Unmarshal(m, b)
+}
+func (m *DeleteRouteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteRouteRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteRouteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteRouteRequest.Merge(m, src)
+}
+func (m *DeleteRouteRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteRouteRequest.Size(m)
+}
+func (m *DeleteRouteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteRouteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteRouteRequest proto.InternalMessageInfo
+
+func (m *DeleteRouteRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type RouteCertificate struct {
+	Cert                 string   `protobuf:"bytes,1,opt,name=cert,proto3" json:"cert,omitempty"`
+	Key                  string   `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RouteCertificate) Reset()         { *m = RouteCertificate{} }
+func (m *RouteCertificate) String() string { return proto.CompactTextString(m) }
+func (*RouteCertificate) ProtoMessage()    {}
+func (*RouteCertificate) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{38}
+}
+
+func (m *RouteCertificate) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RouteCertificate.Unmarshal(m, b)
+}
+func (m *RouteCertificate) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RouteCertificate.Marshal(b, m, deterministic)
+}
+func (m *RouteCertificate) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RouteCertificate.Merge(m, src)
+}
+func (m *RouteCertificate) XXX_Size() int {
+	return xxx_messageInfo_RouteCertificate.Size(m)
+}
+func (m *RouteCertificate) XXX_DiscardUnknown() {
+	xxx_messageInfo_RouteCertificate.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RouteCertificate proto.InternalMessageInfo
+
+func (m *RouteCertificate) GetCert() string {
+	if m != nil {
+		return m.Cert
+	}
+	return ""
+}
+
+func (m *RouteCertificate) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+type Route struct {
+	// name = "apps/APP_ID/routes/ROUTE_ID", or "routes/ROUTE_ID" for routes
+	// which do not belong to an app
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// type is either http or tcp
+	Type    string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Service string `protobuf:"bytes,3,opt,name=service,proto3" json:"service,omitempty"`
+	// port is the port to listen on (only used by TCP routes)
+	Port int32 `protobuf:"varint,4,opt,name=port,proto3" json:"port,omitempty"`
+	// When true, traffic is only routed to the service leader
+	Leader bool `protobuf:"varint,5,opt,name=leader,proto3" json:"leader,omitempty"`
+	// domain, certificate, sticky, path and canary_* are only used by HTTP
+	// routes
+	Domain            string            `protobuf:"bytes,6,opt,name=domain,proto3" json:"domain,omitempty"`
+	Certificate       *RouteCertificate `protobuf:"bytes,7,opt,name=certificate,proto3" json:"certificate,omitempty"`
+	Sticky            bool              `protobuf:"varint,8,opt,name=sticky,proto3" json:"sticky,omitempty"`
+	Path              string            `protobuf:"bytes,9,opt,name=path,proto3" json:"path,omitempty"`
+	DrainBackends     bool              `protobuf:"varint,10,opt,name=drain_backends,json=drainBackends,proto3" json:"drain_backends,omitempty"`
+	DisableKeepAlives bool              `protobuf:"varint,11,opt,name=disable_keep_alives,json=disableKeepAlives,proto3" json:"disable_keep_alives,omitempty"`
+	// canary_release = Release.name
+	CanaryRelease        string               `protobuf:"bytes,12,opt,name=canary_release,json=canaryRelease,proto3" json:"canary_release,omitempty"`
+	CanaryWeight         int32                `protobuf:"varint,13,opt,name=canary_weight,json=canaryWeight,proto3" json:"canary_weight,omitempty"`
+	CreateTime           *timestamp.Timestamp `protobuf:"bytes,14,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	UpdateTime           *timestamp.Timestamp `protobuf:"bytes,15,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	DeleteTime           *timestamp.Timestamp `protobuf:"bytes,16,opt,name=delete_time,json=deleteTime,proto3" json:"delete_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Route) Reset()         { *m = Route{} }
+func (m *Route) String() string { return proto.CompactTextString(m) }
+func (*Route) ProtoMessage()    {}
+func (*Route) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{39}
+}
+
+func (m *Route) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Route.Unmarshal(m, b)
+}
+func (m *Route) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Route.Marshal(b, m, deterministic)
+}
+func (m *Route) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Route.Merge(m, src)
+}
+func (m *Route) XXX_Size() int {
+	return xxx_messageInfo_Route.Size(m)
+}
+func (m *Route) XXX_DiscardUnknown() {
+	xxx_messageInfo_Route.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Route proto.InternalMessageInfo
+
+func (m *Route) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Route) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Route) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *Route) GetPort() int32 {
+	if m != nil {
+		return m.Port
+	}
+	return 0
+}
+
+func (m *Route) GetLeader() bool {
+	if m != nil {
+		return m.Leader
+	}
+	return false
+}
+
+func (m *Route) GetDomain() string {
+	if m != nil {
+		return m.Domain
+	}
+	return ""
+}
+
+func (m *Route) GetCertificate() *RouteCertificate {
+	if m != nil {
+		return m.Certificate
+	}
+	return nil
+}
+
+func (m *Route) GetSticky() bool {
+	if m != nil {
+		return m.Sticky
+	}
+	return false
+}
+
+func (m *Route) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Route) GetDrainBackends() bool {
+	if m != nil {
+		return m.DrainBackends
+	}
+	return false
+}
+
+func (m *Route) GetDisableKeepAlives() bool {
+	if m != nil {
+		return m.DisableKeepAlives
+	}
+	return false
+}
+
+func (m *Route) GetCanaryRelease() string {
+	if m != nil {
+		return m.CanaryRelease
+	}
+	return ""
+}
+
+func (m *Route) GetCanaryWeight() int32 {
+	if m != nil {
+		return m.CanaryWeight
+	}
+	return 0
+}
+
+func (m *Route) GetCreateTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreateTime
+	}
+	return nil
+}
+
+func (m *Route) GetUpdateTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.UpdateTime
+	}
+	return nil
+}
+
+func (m *Route) GetDeleteTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.DeleteTime
+	}
+	return nil
+}
+
+type StreamProvidersRequest struct {
+	// Specifies an optional list of resource names that should be looked up.
+	NameFilters []string `protobuf:"bytes,1,rep,name=name_filters,json=nameFilters,proto3" json:"name_filters,omitempty"`
+	// When true, leaves the stream open and sends any updates to each resource
+	// returned in the initial page until the stream is closed.
+	StreamUpdates bool `protobuf:"varint,2,opt,name=stream_updates,json=streamUpdates,proto3" json:"stream_updates,omitempty"`
+	// When true, leaves the stream open and sends newly created resources
+	// matching the filters until the stream is closed.
+	StreamCreates        bool     `protobuf:"varint,3,opt,name=stream_creates,json=streamCreates,proto3" json:"stream_creates,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamProvidersRequest) Reset()         { *m = StreamProvidersRequest{} }
+func (m *StreamProvidersRequest) String() string { return proto.CompactTextString(m) }
+func (*StreamProvidersRequest) ProtoMessage()    {}
+func (*StreamProvidersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{40}
+}
+
+func (m *StreamProvidersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamProvidersRequest.Unmarshal(m, b)
+}
+func (m *StreamProvidersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamProvidersRequest.Marshal(b, m, deterministic)
+}
+func (m *StreamProvidersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamProvidersRequest.Merge(m, src)
+}
+func (m *StreamProvidersRequest) XXX_Size() int {
+	return xxx_messageInfo_StreamProvidersRequest.Size(m)
+}
+func (m *StreamProvidersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamProvidersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamProvidersRequest proto.InternalMessageInfo
+
+func (m *StreamProvidersRequest) GetNameFilters() []string {
+	if m != nil {
+		return m.NameFilters
+	}
+	return nil
+}
+
+func (m *StreamProvidersRequest) GetStreamUpdates() bool {
+	if m != nil {
+		return m.StreamUpdates
+	}
+	return false
+}
+
+func (m *StreamProvidersRequest) GetStreamCreates() bool {
+	if m != nil {
+		return m.StreamCreates
+	}
+	return false
+}
+
+type StreamProvidersResponse struct {
+	Providers []*Provider `protobuf:"bytes,1,rep,name=providers,proto3" json:"providers,omitempty"`
+	// Set to true on the last response for the initial page.
+	PageComplete         bool     `protobuf:"varint,2,opt,name=page_complete,json=pageComplete,proto3" json:"page_complete,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamProvidersResponse) Reset()         { *m = StreamProvidersResponse{} }
+func (m *StreamProvidersResponse) String() string { return proto.CompactTextString(m) }
+func (*StreamProvidersResponse) ProtoMessage()    {}
+func (*StreamProvidersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{41}
+}
+
+func (m *StreamProvidersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamProvidersResponse.Unmarshal(m, b)
+}
+func (m *StreamProvidersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamProvidersResponse.Marshal(b, m, deterministic)
+}
+func (m *StreamProvidersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamProvidersResponse.Merge(m, src)
+}
+func (m *StreamProvidersResponse) XXX_Size() int {
+	return xxx_messageInfo_StreamProvidersResponse.Size(m)
+}
+func (m *StreamProvidersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamProvidersResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamProvidersResponse proto.InternalMessageInfo
+
+func (m *StreamProvidersResponse) GetProviders() []*Provider {
+	if m != nil {
+		return m.Providers
+	}
+	return nil
+}
+
+func (m *StreamProvidersResponse) GetPageComplete() bool {
+	if m != nil {
+		return m.PageComplete
+	}
+	return false
+}
+
+type CreateProviderRequest struct {
+	Provider             *Provider `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *CreateProviderRequest) Reset()         { *m = CreateProviderRequest{} }
+func (m *CreateProviderRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateProviderRequest) ProtoMessage()    {}
+func (*CreateProviderRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{42}
+}
+
+func (m *CreateProviderRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateProviderRequest.Unmarshal(m, b)
+}
+func (m *CreateProviderRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateProviderRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateProviderRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateProviderRequest.Merge(m, src)
+}
+func (m *CreateProviderRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateProviderRequest.Size(m)
+}
+func (m *CreateProviderRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateProviderRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateProviderRequest proto.InternalMessageInfo
+
+func (m *CreateProviderRequest) GetProvider() *Provider {
+	if m != nil {
+		return m.Provider
+	}
+	return nil
+}
+
+type Provider struct {
+	// name = "providers/PROVIDER_ID"
+	Name                 string               `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	DisplayName          string               `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Url                  string               `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	CreateTime           *timestamp.Timestamp `protobuf:"bytes,4,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	UpdateTime           *timestamp.Timestamp `protobuf:"bytes,5,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Provider) Reset()         { *m = Provider{} }
+func (m *Provider) String() string { return proto.CompactTextString(m) }
+func (*Provider) ProtoMessage()    {}
+func (*Provider) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{43}
+}
+
+func (m *Provider) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Provider.Unmarshal(m, b)
+}
+func (m *Provider) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Provider.Marshal(b, m, deterministic)
+}
+func (m *Provider) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Provider.Merge(m, src)
+}
+func (m *Provider) XXX_Size() int {
+	return xxx_messageInfo_Provider.Size(m)
+}
+func (m *Provider) XXX_DiscardUnknown() {
+	xxx_messageInfo_Provider.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Provider proto.InternalMessageInfo
+
+func (m *Provider) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Provider) GetDisplayName() string {
+	if m != nil {
+		return m.DisplayName
+	}
+	return ""
+}
+
+func (m *Provider) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Provider) GetCreateTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreateTime
+	}
+	return nil
+}
+
+func (m *Provider) GetUpdateTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.UpdateTime
+	}
+	return nil
+}
+
+type StreamResourcesRequest struct {
+	// Specifies an optional list of resource names that should be looked up.
+	// This can be used to request a known set of one or more resources and
+	// optionally receive updates about them, and can also be used to retrieve
+	// a single resource. Parent resource names (i.e. providers) and app names
+	// may also be used to filter resources.
+	NameFilters []string `protobuf:"bytes,1,rep,name=name_filters,json=nameFilters,proto3" json:"name_filters,omitempty"`
+	// When true, leaves the stream open and sends any updates (including
+	// deletions) to each resource returned in the initial page until the
+	// stream is closed.
+	StreamUpdates bool `protobuf:"varint,2,opt,name=stream_updates,json=streamUpdates,proto3" json:"stream_updates,omitempty"`
+	// When true, leaves the stream open and sends newly created resources
+	// matching the filters until the stream is closed.
+	StreamCreates        bool     `protobuf:"varint,3,opt,name=stream_creates,json=streamCreates,proto3" json:"stream_creates,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamResourcesRequest) Reset()         { *m = StreamResourcesRequest{} }
+func (m *StreamResourcesRequest) String() string { return proto.CompactTextString(m) }
+func (*StreamResourcesRequest) ProtoMessage()    {}
+func (*StreamResourcesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{44}
+}
+
+func (m *StreamResourcesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamResourcesRequest.Unmarshal(m, b)
+}
+func (m *StreamResourcesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamResourcesRequest.Marshal(b, m, deterministic)
+}
+func (m *StreamResourcesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamResourcesRequest.Merge(m, src)
+}
+func (m *StreamResourcesRequest) XXX_Size() int {
+	return xxx_messageInfo_StreamResourcesRequest.Size(m)
+}
+func (m *StreamResourcesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamResourcesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamResourcesRequest proto.InternalMessageInfo
+
+func (m *StreamResourcesRequest) GetNameFilters() []string {
+	if m != nil {
+		return m.NameFilters
+	}
+	return nil
+}
+
+func (m *StreamResourcesRequest) GetStreamUpdates() bool {
+	if m != nil {
+		return m.StreamUpdates
+	}
+	return false
+}
+
+func (m *StreamResourcesRequest) GetStreamCreates() bool {
+	if m != nil {
+		return m.StreamCreates
+	}
+	return false
+}
+
+type StreamResourcesResponse struct {
+	Resources []*Resource `protobuf:"bytes,1,rep,name=resources,proto3" json:"resources,omitempty"`
+	// Set to true on the last response for the initial page.
+	PageComplete         bool     `protobuf:"varint,2,opt,name=page_complete,json=pageComplete,proto3" json:"page_complete,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamResourcesResponse) Reset()         { *m = StreamResourcesResponse{} }
+func (m *StreamResourcesResponse) String() string { return proto.CompactTextString(m) }
+func (*StreamResourcesResponse) ProtoMessage()    {}
+func (*StreamResourcesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{45}
+}
+
+func (m *StreamResourcesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamResourcesResponse.Unmarshal(m, b)
+}
+func (m *StreamResourcesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamResourcesResponse.Marshal(b, m, deterministic)
+}
+func (m *StreamResourcesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamResourcesResponse.Merge(m, src)
+}
+func (m *StreamResourcesResponse) XXX_Size() int {
+	return xxx_messageInfo_StreamResourcesResponse.Size(m)
+}
+func (m *StreamResourcesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamResourcesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamResourcesResponse proto.InternalMessageInfo
+
+func (m *StreamResourcesResponse) GetResources() []*Resource {
+	if m != nil {
+		return m.Resources
+	}
+	return nil
+}
+
+func (m *StreamResourcesResponse) GetPageComplete() bool {
+	if m != nil {
+		return m.PageComplete
+	}
+	return false
+}
+
+type CreateResourceRequest struct {
+	// parent = "providers/PROVIDER_ID"
+	Parent string `protobuf:"bytes,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	// config is the JSON encoded provider specific config
+	Config []byte `protobuf:"bytes,2,opt,name=config,proto3" json:"config,omitempty"`
+	// apps = App.name
+	Apps                 []string `protobuf:"bytes,3,rep,name=apps,proto3" json:"apps,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateResourceRequest) Reset()         { *m = CreateResourceRequest{} }
+func (m *CreateResourceRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateResourceRequest) ProtoMessage()    {}
+func (*CreateResourceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{46}
+}
+
+func (m *CreateResourceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateResourceRequest.Unmarshal(m, b)
+}
+func (m *CreateResourceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateResourceRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateResourceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateResourceRequest.Merge(m, src)
+}
+func (m *CreateResourceRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateResourceRequest.Size(m)
+}
+func (m *CreateResourceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateResourceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateResourceRequest proto.InternalMessageInfo
+
+func (m *CreateResourceRequest) GetParent() string {
+	if m != nil {
+		return m.Parent
+	}
+	return ""
+}
+
+func (m *CreateResourceRequest) GetConfig() []byte {
+	if m != nil {
+		return m.Config
+	}
+	return nil
+}
+
+func (m *CreateResourceRequest) GetApps() []string {
+	if m != nil {
+		return m.Apps
+	}
+	return nil
+}
+
+type DeleteResourceRequest struct {
+	// name = "providers/PROVIDER_ID/resources/RESOURCE_ID"
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteResourceRequest) Reset()         { *m = DeleteResourceRequest{} }
+func (m *DeleteResourceRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteResourceRequest) ProtoMessage()    {}
+func (*DeleteResourceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{47}
+}
+
+func (m *DeleteResourceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteResourceRequest.Unmarshal(m, b)
+}
+func (m *DeleteResourceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteResourceRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteResourceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteResourceRequest.Merge(m, src)
+}
+func (m *DeleteResourceRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteResourceRequest.Size(m)
+}
+func (m *DeleteResourceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteResourceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteResourceRequest proto.InternalMessageInfo
+
+func (m *DeleteResourceRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type Resource struct {
+	// name = "providers/PROVIDER_ID/resources/RESOURCE_ID"
+	Name       string            `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	ExternalId string            `protobuf:"bytes,2,opt,name=external_id,json=externalId,proto3" json:"external_id,omitempty"`
+	Env        map[string]string `protobuf:"bytes,3,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// apps = App.name
+	Apps                 []string             `protobuf:"bytes,4,rep,name=apps,proto3" json:"apps,omitempty"`
+	CreateTime           *timestamp.Timestamp `protobuf:"bytes,5,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	DeleteTime           *timestamp.Timestamp `protobuf:"bytes,6,opt,name=delete_time,json=deleteTime,proto3" json:"delete_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Resource) Reset()         { *m = Resource{} }
+func (m *Resource) String() string { return proto.CompactTextString(m) }
+func (*Resource) ProtoMessage()    {}
+func (*Resource) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{48}
+}
+
+func (m *Resource) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Resource.Unmarshal(m, b)
+}
+func (m *Resource) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Resource.Marshal(b, m, deterministic)
+}
+func (m *Resource) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Resource.Merge(m, src)
+}
+func (m *Resource) XXX_Size() int {
+	return xxx_messageInfo_Resource.Size(m)
+}
+func (m *Resource) XXX_DiscardUnknown() {
+	xxx_messageInfo_Resource.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Resource proto.InternalMessageInfo
+
+func (m *Resource) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Resource) GetExternalId() string {
+	if m != nil {
+		return m.ExternalId
+	}
+	return ""
+}
+
+func (m *Resource) GetEnv() map[string]string {
+	if m != nil {
+		return m.Env
+	}
+	return nil
+}
+
+func (m *Resource) GetApps() []string {
+	if m != nil {
+		return m.Apps
+	}
+	return nil
+}
+
+func (m *Resource) GetCreateTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreateTime
+	}
+	return nil
+}
+
+func (m *Resource) GetDeleteTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.DeleteTime
+	}
+	return nil
+}
+
+type StreamVolumesRequest struct {
+	// Specifies an optional list of resource names that should be looked up.
+	// This can be used to request a known set of one or more resources and
+	// optionally receive updates about them, and can also be used to retrieve
+	// a single resource. Parent resource names may also be used to filter
+	// resources.
+	NameFilters []string `protobuf:"bytes,1,rep,name=name_filters,json=nameFilters,proto3" json:"name_filters,omitempty"`
+	// When true, leaves the stream open and sends any updates to each resource
+	// returned in the initial page until the stream is closed.
+	StreamUpdates bool `protobuf:"varint,2,opt,name=stream_updates,json=streamUpdates,proto3" json:"stream_updates,omitempty"`
+	// When true, leaves the stream open and sends newly created resources
+	// matching the filters until the stream is closed.
+	StreamCreates        bool     `protobuf:"varint,3,opt,name=stream_creates,json=streamCreates,proto3" json:"stream_creates,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamVolumesRequest) Reset()         { *m = StreamVolumesRequest{} }
+func (m *StreamVolumesRequest) String() string { return proto.CompactTextString(m) }
+func (*StreamVolumesRequest) ProtoMessage()    {}
+func (*StreamVolumesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{49}
+}
+
+func (m *StreamVolumesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamVolumesRequest.Unmarshal(m, b)
+}
+func (m *StreamVolumesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamVolumesRequest.Marshal(b, m, deterministic)
+}
+func (m *StreamVolumesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamVolumesRequest.Merge(m, src)
+}
+func (m *StreamVolumesRequest) XXX_Size() int {
+	return xxx_messageInfo_StreamVolumesRequest.Size(m)
+}
+func (m *StreamVolumesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamVolumesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamVolumesRequest proto.InternalMessageInfo
+
+func (m *StreamVolumesRequest) GetNameFilters() []string {
+	if m != nil {
+		return m.NameFilters
+	}
+	return nil
+}
+
+func (m *StreamVolumesRequest) GetStreamUpdates() bool {
+	if m != nil {
+		return m.StreamUpdates
+	}
+	return false
+}
+
+func (m *StreamVolumesRequest) GetStreamCreates() bool {
+	if m != nil {
+		return m.StreamCreates
+	}
+	return false
+}
+
+type StreamVolumesResponse struct {
+	Volumes []*Volume `protobuf:"bytes,1,rep,name=volumes,proto3" json:"volumes,omitempty"`
+	// Set to true on the last response for the initial page.
+	PageComplete         bool     `protobuf:"varint,2,opt,name=page_complete,json=pageComplete,proto3" json:"page_complete,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamVolumesResponse) Reset()         { *m = StreamVolumesResponse{} }
+func (m *StreamVolumesResponse) String() string { return proto.CompactTextString(m) }
+func (*StreamVolumesResponse) ProtoMessage()    {}
+func (*StreamVolumesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{50}
+}
+
+func (m *StreamVolumesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamVolumesResponse.Unmarshal(m, b)
+}
+func (m *StreamVolumesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamVolumesResponse.Marshal(b, m, deterministic)
+}
+func (m *StreamVolumesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamVolumesResponse.Merge(m, src)
+}
+func (m *StreamVolumesResponse) XXX_Size() int {
+	return xxx_messageInfo_StreamVolumesResponse.Size(m)
+}
+func (m *StreamVolumesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamVolumesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamVolumesResponse proto.InternalMessageInfo
+
+func (m *StreamVolumesResponse) GetVolumes() []*Volume {
+	if m != nil {
+		return m.Volumes
+	}
+	return nil
+}
+
+func (m *StreamVolumesResponse) GetPageComplete() bool {
+	if m != nil {
+		return m.PageComplete
+	}
+	return false
+}
+
+type DecommissionVolumeRequest struct {
+	// name = "apps/APP_ID/volumes/VOLUME_ID"
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DecommissionVolumeRequest) Reset()         { *m = DecommissionVolumeRequest{} }
+func (m *DecommissionVolumeRequest) String() string { return proto.CompactTextString(m) }
+func (*DecommissionVolumeRequest) ProtoMessage()    {}
+func (*DecommissionVolumeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{51}
+}
+
+func (m *DecommissionVolumeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DecommissionVolumeRequest.Unmarshal(m, b)
+}
+func (m *DecommissionVolumeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DecommissionVolumeRequest.Marshal(b, m, deterministic)
+}
+func (m *DecommissionVolumeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DecommissionVolumeRequest.Merge(m, src)
+}
+func (m *DecommissionVolumeRequest) XXX_Size() int {
+	return xxx_messageInfo_DecommissionVolumeRequest.Size(m)
+}
+func (m *DecommissionVolumeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DecommissionVolumeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DecommissionVolumeRequest proto.InternalMessageInfo
+
+func (m *DecommissionVolumeRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type Volume struct {
+	// name = "apps/APP_ID/volumes/VOLUME_ID", or "volumes/VOLUME_ID" for
+	// volumes which do not belong to an app
+	Name         string       `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Path         string       `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	DeleteOnStop bool         `protobuf:"varint,3,opt,name=delete_on_stop,json=deleteOnStop,proto3" json:"delete_on_stop,omitempty"`
+	HostId       string       `protobuf:"bytes,4,opt,name=host_id,json=hostId,proto3" json:"host_id,omitempty"`
+	Type         string       `protobuf:"bytes,5,opt,name=type,proto3" json:"type,omitempty"`
+	State        Volume_State `protobuf:"varint,6,opt,name=state,proto3,enum=flynn.api.v1.Volume_State" json:"state,omitempty"`
+	// release = Release.name
+	Release string `protobuf:"bytes,7,opt,name=release,proto3" json:"release,omitempty"`
+	// job_id is the full cluster ID of the job using the volume
+	JobId                string               `protobuf:"bytes,8,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	JobType              string               `protobuf:"bytes,9,opt,name=job_type,json=jobType,proto3" json:"job_type,omitempty"`
+	Labels               map[string]string    `protobuf:"bytes,10,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	CreateTime           *timestamp.Timestamp `protobuf:"bytes,11,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	UpdateTime           *timestamp.Timestamp `protobuf:"bytes,12,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	DecommissionTime     *timestamp.Timestamp `protobuf:"bytes,13,opt,name=decommission_time,json=decommissionTime,proto3" json:"decommission_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Volume) Reset()         { *m = Volume{} }
+func (m *Volume) String() string { return proto.CompactTextString(m) }
+func (*Volume) ProtoMessage()    {}
+func (*Volume) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{52}
+}
+
+func (m *Volume) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Volume.Unmarshal(m, b)
+}
+func (m *Volume) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Volume.Marshal(b, m, deterministic)
+}
+func (m *Volume) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Volume.Merge(m, src)
+}
+func (m *Volume) XXX_Size() int {
+	return xxx_messageInfo_Volume.Size(m)
+}
+func (m *Volume) XXX_DiscardUnknown() {
+	xxx_messageInfo_Volume.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Volume proto.InternalMessageInfo
+
+func (m *Volume) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Volume) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Volume) GetDeleteOnStop() bool {
+	if m != nil {
+		return m.DeleteOnStop
+	}
+	return false
+}
+
+func (m *Volume) GetHostId() string {
+	if m != nil {
+		return m.HostId
+	}
+	return ""
+}
+
+func (m *Volume) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Volume) GetState() Volume_State {
+	if m != nil {
+		return m.State
+	}
+	return Volume_PENDING
+}
+
+func (m *Volume) GetRelease() string {
+	if m != nil {
+		return m.Release
+	}
+	return ""
+}
+
+func (m *Volume) GetJobId() string {
+	if m != nil {
+		return m.JobId
+	}
+	return ""
+}
+
+func (m *Volume) GetJobType() string {
+	if m != nil {
+		return m.JobType
+	}
+	return ""
+}
+
+func (m *Volume) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *Volume) GetCreateTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreateTime
+	}
+	return nil
+}
+
+func (m *Volume) GetUpdateTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.UpdateTime
+	}
+	return nil
+}
+
+func (m *Volume) GetDecommissionTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.DecommissionTime
+	}
+	return nil
+}
+
+type StreamSinksRequest struct {
+	// Specifies an optional list of resource names that should be looked up.
+	NameFilters []string `protobuf:"bytes,1,rep,name=name_filters,json=nameFilters,proto3" json:"name_filters,omitempty"`
+	// When true, leaves the stream open and sends any updates (including
+	// deletions) to each resource returned in the initial page until the
+	// stream is closed.
+	StreamUpdates bool `protobuf:"varint,2,opt,name=stream_updates,json=streamUpdates,proto3" json:"stream_updates,omitempty"`
+	// When true, leaves the stream open and sends newly created resources
+	// matching the filters until the stream is closed.
+	StreamCreates        bool     `protobuf:"varint,3,opt,name=stream_creates,json=streamCreates,proto3" json:"stream_creates,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamSinksRequest) Reset()         { *m = StreamSinksRequest{} }
+func (m *StreamSinksRequest) String() string { return proto.CompactTextString(m) }
+func (*StreamSinksRequest) ProtoMessage()    {}
+func (*StreamSinksRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{53}
+}
+
+func (m *StreamSinksRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamSinksRequest.Unmarshal(m, b)
+}
+func (m *StreamSinksRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamSinksRequest.Marshal(b, m, deterministic)
+}
+func (m *StreamSinksRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamSinksRequest.Merge(m, src)
+}
+func (m *StreamSinksRequest) XXX_Size() int {
+	return xxx_messageInfo_StreamSinksRequest.Size(m)
+}
+func (m *StreamSinksRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamSinksRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamSinksRequest proto.InternalMessageInfo
+
+func (m *StreamSinksRequest) GetNameFilters() []string {
+	if m != nil {
+		return m.NameFilters
+	}
+	return nil
+}
+
+func (m *StreamSinksRequest) GetStreamUpdates() bool {
+	if m != nil {
+		return m.StreamUpdates
+	}
+	return false
+}
+
+func (m *StreamSinksRequest) GetStreamCreates() bool {
+	if m != nil {
+		return m.StreamCreates
+	}
+	return false
+}
+
+type StreamSinksResponse struct {
+	Sinks []*Sink `protobuf:"bytes,1,rep,name=sinks,proto3" json:"sinks,omitempty"`
+	// Set to true on the last response for the initial page.
+	PageComplete         bool     `protobuf:"varint,2,opt,name=page_complete,json=pageComplete,proto3" json:"page_complete,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamSinksResponse) Reset()         { *m = StreamSinksResponse{} }
+func (m *StreamSinksResponse) String() string { return proto.CompactTextString(m) }
+func (*StreamSinksResponse) ProtoMessage()    {}
+func (*StreamSinksResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{54}
+}
+
+func (m *StreamSinksResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamSinksResponse.Unmarshal(m, b)
+}
+func (m *StreamSinksResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamSinksResponse.Marshal(b, m, deterministic)
+}
+func (m *StreamSinksResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamSinksResponse.Merge(m, src)
+}
+func (m *StreamSinksResponse) XXX_Size() int {
+	return xxx_messageInfo_StreamSinksResponse.Size(m)
+}
+func (m *StreamSinksResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamSinksResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamSinksResponse proto.InternalMessageInfo
+
+func (m *StreamSinksResponse) GetSinks() []*Sink {
+	if m != nil {
+		return m.Sinks
+	}
+	return nil
+}
+
+func (m *StreamSinksResponse) GetPageComplete() bool {
+	if m != nil {
+		return m.PageComplete
+	}
+	return false
+}
+
+type CreateSinkRequest struct {
+	Sink                 *Sink    `protobuf:"bytes,1,opt,name=sink,proto3" json:"sink,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateSinkRequest) Reset()         { *m = CreateSinkRequest{} }
+func (m *CreateSinkRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateSinkRequest) ProtoMessage()    {}
+func (*CreateSinkRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{55}
+}
+
+func (m *CreateSinkRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateSinkRequest.Unmarshal(m, b)
+}
+func (m *CreateSinkRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateSinkRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateSinkRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateSinkRequest.Merge(m, src)
+}
+func (m *CreateSinkRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateSinkRequest.Size(m)
+}
+func (m *CreateSinkRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateSinkRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateSinkRequest proto.InternalMessageInfo
+
+func (m *CreateSinkRequest) GetSink() *Sink {
+	if m != nil {
+		return m.Sink
+	}
+	return nil
+}
+
+type DeleteSinkRequest struct {
+	// name = "sinks/SINK_ID"
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteSinkRequest) Reset()         { *m = DeleteSinkRequest{} }
+func (m *DeleteSinkRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteSinkRequest) ProtoMessage()    {}
+func (*DeleteSinkRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{56}
+}
+
+func (m *DeleteSinkRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteSinkRequest.Unmarshal(m, b)
+}
+func (m *DeleteSinkRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteSinkRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteSinkRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteSinkRequest.Merge(m, src)
+}
+func (m *DeleteSinkRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteSinkRequest.Size(m)
+}
+func (m *DeleteSinkRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteSinkRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteSinkRequest proto.InternalMessageInfo
+
+func (m *DeleteSinkRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type Sink struct {
+	// name = "sinks/SINK_ID"
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// kind is either syslog or logaggregator
+	Kind        string `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	HostManaged bool   `protobuf:"varint,3,opt,name=host_managed,json=hostManaged,proto3" json:"host_managed,omitempty"`
+	// config is the JSON encoded kind specific config
+	Config               []byte               `protobuf:"bytes,4,opt,name=config,proto3" json:"config,omitempty"`
+	CreateTime           *timestamp.Timestamp `protobuf:"bytes,5,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	UpdateTime           *timestamp.Timestamp `protobuf:"bytes,6,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	DeleteTime           *timestamp.Timestamp `protobuf:"bytes,7,opt,name=delete_time,json=deleteTime,proto3" json:"delete_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Sink) Reset()         { *m = Sink{} }
+func (m *Sink) String() string { return proto.CompactTextString(m) }
+func (*Sink) ProtoMessage()    {}
+func (*Sink) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{57}
+}
+
+func (m *Sink) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Sink.Unmarshal(m, b)
+}
+func (m *Sink) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Sink.Marshal(b, m, deterministic)
+}
+func (m *Sink) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Sink.Merge(m, src)
+}
+func (m *Sink) XXX_Size() int {
+	return xxx_messageInfo_Sink.Size(m)
+}
+func (m *Sink) XXX_DiscardUnknown() {
+	xxx_messageInfo_Sink.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Sink proto.InternalMessageInfo
+
+func (m *Sink) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Sink) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *Sink) GetHostManaged() bool {
+	if m != nil {
+		return m.HostManaged
+	}
+	return false
+}
+
+func (m *Sink) GetConfig() []byte {
+	if m != nil {
+		return m.Config
+	}
+	return nil
+}
+
+func (m *Sink) GetCreateTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreateTime
+	}
+	return nil
+}
+
+func (m *Sink) GetUpdateTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.UpdateTime
+	}
+	return nil
+}
+
+func (m *Sink) GetDeleteTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.DeleteTime
+	}
+	return nil
+}
+
+type StreamEventsRequest struct {
+	// The maximum number of past events to return in the initial page, with
+	// all matching events being returned if not set.
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Specifies an optional list of app names (i.e. "apps/APP_ID") to filter
+	// events by, or event names to retrieve single events.
+	NameFilters []string `protobuf:"bytes,2,rep,name=name_filters,json=nameFilters,proto3" json:"name_filters,omitempty"`
+	// When set, only includes events having one of the specified object types
+	ObjectTypeFilters []string `protobuf:"bytes,3,rep,name=object_type_filters,json=objectTypeFilters,proto3" json:"object_type_filters,omitempty"`
+	// When set, only includes events having one of the specified object IDs
+	ObjectIdFilters []string `protobuf:"bytes,4,rep,name=object_id_filters,json=objectIdFilters,proto3" json:"object_id_filters,omitempty"`
+	// When set, only includes past events created before the given event
+	// (before = Event.name)
+	Before string `protobuf:"bytes,5,opt,name=before,proto3" json:"before,omitempty"`
+	// When set, only includes events created after the given event
+	// (since = Event.name)
+	Since string `protobuf:"bytes,6,opt,name=since,proto3" json:"since,omitempty"`
+	// When true, past events are not returned and the initial page is empty.
+	SkipPast bool `protobuf:"varint,7,opt,name=skip_past,json=skipPast,proto3" json:"skip_past,omitempty"`
+	// When true, leaves the stream open and sends newly created events
+	// matching the filters until the stream is closed.
+	StreamCreates        bool     `protobuf:"varint,8,opt,name=stream_creates,json=streamCreates,proto3" json:"stream_creates,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamEventsRequest) Reset()         { *m = StreamEventsRequest{} }
+func (m *StreamEventsRequest) String() string { return proto.CompactTextString(m) }
+func (*StreamEventsRequest) ProtoMessage()    {}
+func (*StreamEventsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{58}
+}
+
+func (m *StreamEventsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamEventsRequest.Unmarshal(m, b)
+}
+func (m *StreamEventsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamEventsRequest.Marshal(b, m, deterministic)
+}
+func (m *StreamEventsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamEventsRequest.Merge(m, src)
+}
+func (m *StreamEventsRequest) XXX_Size() int {
+	return xxx_messageInfo_StreamEventsRequest.Size(m)
+}
+func (m *StreamEventsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamEventsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamEventsRequest proto.InternalMessageInfo
+
+func (m *StreamEventsRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *StreamEventsRequest) GetNameFilters() []string {
+	if m != nil {
+		return m.NameFilters
+	}
+	return nil
+}
+
+func (m *StreamEventsRequest) GetObjectTypeFilters() []string {
+	if m != nil {
+		return m.ObjectTypeFilters
+	}
+	return nil
+}
+
+func (m *StreamEventsRequest) GetObjectIdFilters() []string {
+	if m != nil {
+		return m.ObjectIdFilters
+	}
+	return nil
+}
+
+func (m *StreamEventsRequest) GetBefore() string {
+	if m != nil {
+		return m.Before
+	}
+	return ""
+}
+
+func (m *StreamEventsRequest) GetSince() string {
+	if m != nil {
+		return m.Since
+	}
+	return ""
+}
+
+func (m *StreamEventsRequest) GetSkipPast() bool {
+	if m != nil {
+		return m.SkipPast
+	}
+	return false
+}
+
+func (m *StreamEventsRequest) GetStreamCreates() bool {
+	if m != nil {
+		return m.StreamCreates
+	}
+	return false
+}
+
+type StreamEventsResponse struct {
+	// events are in ascending order of creation
+	Events []*Event `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	// Set to true on the last response for the initial page.
+	PageComplete         bool     `protobuf:"varint,2,opt,name=page_complete,json=pageComplete,proto3" json:"page_complete,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamEventsResponse) Reset()         { *m = StreamEventsResponse{} }
+func (m *StreamEventsResponse) String() string { return proto.CompactTextString(m) }
+func (*StreamEventsResponse) ProtoMessage()    {}
+func (*StreamEventsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{59}
+}
+
+func (m *StreamEventsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamEventsResponse.Unmarshal(m, b)
+}
+func (m *StreamEventsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamEventsResponse.Marshal(b, m, deterministic)
+}
+func (m *StreamEventsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamEventsResponse.Merge(m, src)
+}
+func (m *StreamEventsResponse) XXX_Size() int {
+	return xxx_messageInfo_StreamEventsResponse.Size(m)
+}
+func (m *StreamEventsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamEventsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamEventsResponse proto.InternalMessageInfo
+
+func (m *StreamEventsResponse) GetEvents() []*Event {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+func (m *StreamEventsResponse) GetPageComplete() bool {
+	if m != nil {
+		return m.PageComplete
+	}
+	return false
+}
+
+type Event struct {
+	// name = "events/EVENT_ID"
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// parent = App.name, or empty for events which do not belong to an app
+	Parent     string `protobuf:"bytes,2,opt,name=parent,proto3" json:"parent,omitempty"`
+	ObjectType string `protobuf:"bytes,3,opt,name=object_type,json=objectType,proto3" json:"object_type,omitempty"`
+	ObjectId   string `protobuf:"bytes,4,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
+	// data is the JSON encoded object
+	Data                 []byte               `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
+	CreateTime           *timestamp.Timestamp `protobuf:"bytes,6,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Event) Reset()         { *m = Event{} }
+func (m *Event) String() string { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()    {}
+func (*Event) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ed7f10298fa1d90f, []int{60}
+}
+
+func (m *Event) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Event.Unmarshal(m, b)
+}
+func (m *Event) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Event.Marshal(b, m, deterministic)
+}
+func (m *Event) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Event.Merge(m, src)
+}
+func (m *Event) XXX_Size() int {
+	return xxx_messageInfo_Event.Size(m)
+}
+func (m *Event) XXX_DiscardUnknown() {
+	xxx_messageInfo_Event.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Event proto.InternalMessageInfo
+
+func (m *Event) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Event) GetParent() string {
+	if m != nil {
+		return m.Parent
+	}
+	return ""
+}
+
+func (m *Event) GetObjectType() string {
+	if m != nil {
+		return m.ObjectType
+	}
+	return ""
+}
+
+func (m *Event) GetObjectId() string {
+	if m != nil {
+		return m.ObjectId
+	}
+	return ""
+}
+
+func (m *Event) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *Event) GetCreateTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreateTime
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("flynn.api.v1.ReleaseType", ReleaseType_name, ReleaseType_value)
+	proto.RegisterEnum("flynn.api.v1.ScaleRequestState", ScaleRequestState_name, ScaleRequestState_value)
+	proto.RegisterEnum("flynn.api.v1.DeploymentStatus", DeploymentStatus_name, DeploymentStatus_value)
+	proto.RegisterEnum("flynn.api.v1.StatusResponse_Code", StatusResponse_Code_name, StatusResponse_Code_value)
+	proto.RegisterEnum("flynn.api.v1.LabelFilter_Expression_Operator", LabelFilter_Expression_Operator_name, LabelFilter_Expression_Operator_value)
+	proto.RegisterEnum("flynn.api.v1.DeploymentEvent_JobState", DeploymentEvent_JobState_name, DeploymentEvent_JobState_value)
+	proto.RegisterEnum("flynn.api.v1.Volume_State", Volume_State_name, Volume_State_value)
+	proto.RegisterType((*StatusResponse)(nil), "flynn.api.v1.StatusResponse")
+	proto.RegisterType((*LabelFilter)(nil), "flynn.api.v1.LabelFilter")
+	proto.RegisterType((*LabelFilter_Expression)(nil), "flynn.api.v1.LabelFilter.Expression")
+	proto.RegisterType((*StreamAppsRequest)(nil), "flynn.api.v1.StreamAppsRequest")
+	proto.RegisterType((*StreamAppsResponse)(nil), "flynn.api.v1.StreamAppsResponse")
+	proto.RegisterType((*StreamReleasesRequest)(nil), "flynn.api.v1.StreamReleasesRequest")
+	proto.RegisterType((*StreamReleasesResponse)(nil), "flynn.api.v1.StreamReleasesResponse")
+	proto.RegisterType((*StreamScalesRequest)(nil), "flynn.api.v1.StreamScalesRequest")
+	proto.RegisterType((*StreamScalesResponse)(nil), "flynn.api.v1.StreamScalesResponse")
+	proto.RegisterType((*StreamDeploymentsRequest)(nil), "flynn.api.v1.StreamDeploymentsRequest")
+	proto.RegisterType((*StreamDeploymentsResponse)(nil), "flynn.api.v1.StreamDeploymentsResponse")
+	proto.RegisterType((*UpdateAppRequest)(nil), "flynn.api.v1.UpdateAppRequest")
+	proto.RegisterType((*CreateScaleRequest)(nil), "flynn.api.v1.CreateScaleRequest")
+	proto.RegisterMapType((map[string]int32)(nil), "flynn.api.v1.CreateScaleRequest.ProcessesEntry")
+	proto.RegisterMapType((map[string]*DeploymentProcessTags)(nil), "flynn.api.v1.CreateScaleRequest.TagsEntry")
+	proto.RegisterType((*CreateReleaseRequest)(nil), "flynn.api.v1.CreateReleaseRequest")
+	proto.RegisterType((*CreateDeploymentRequest)(nil), "flynn.api.v1.CreateDeploymentRequest")
+	proto.RegisterType((*App)(nil), "flynn.api.v1.App")
+	proto.RegisterMapType((map[string]string)(nil), "flynn.api.v1.App.LabelsEntry")
+	proto.RegisterType((*HostHealthCheck)(nil), "flynn.api.v1.HostHealthCheck")
+	proto.RegisterType((*HostService)(nil), "flynn.api.v1.HostService")
+	proto.RegisterType((*Port)(nil), "flynn.api.v1.Port")
+	proto.RegisterType((*VolumeReq)(nil), "flynn.api.v1.VolumeReq")
+	proto.RegisterType((*HostResourceSpec)(nil), "flynn.api.v1.HostResourceSpec")
+	proto.RegisterType((*HostMount)(nil), "flynn.api.v1.HostMount")
+	proto.RegisterType((*LibContainerDevice)(nil), "flynn.api.v1.LibContainerDevice")
+	proto.RegisterType((*ProcessType)(nil), "flynn.api.v1.ProcessType")
+	proto.RegisterMapType((map[string]string)(nil), "flynn.api.v1.ProcessType.EnvEntry")
+	proto.RegisterMapType((map[string]*HostResourceSpec)(nil), "flynn.api.v1.ProcessType.ResourcesEntry")
+	proto.RegisterType((*Release)(nil), "flynn.api.v1.Release")
+	proto.RegisterMapType((map[string]string)(nil), "flynn.api.v1.Release.EnvEntry")
+	proto.RegisterMapType((map[string]string)(nil), "flynn.api.v1.Release.LabelsEntry")
+	proto.RegisterMapType((map[string]*ProcessType)(nil), "flynn.api.v1.Release.ProcessesEntry")
+	proto.RegisterType((*ScaleRequest)(nil), "flynn.api.v1.ScaleRequest")
+	proto.RegisterMapType((map[string]int32)(nil), "flynn.api.v1.ScaleRequest.NewProcessesEntry")
+	proto.RegisterMapType((map[string]*DeploymentProcessTags)(nil), "flynn.api.v1.ScaleRequest.NewTagsEntry")
+	proto.RegisterMapType((map[string]int32)(nil), "flynn.api.v1.ScaleRequest.OldProcessesEntry")
+	proto.RegisterMapType((map[string]*DeploymentProcessTags)(nil), "flynn.api.v1.ScaleRequest.OldTagsEntry")
+	proto.RegisterType((*DeploymentProcessTags)(nil), "flynn.api.v1.DeploymentProcessTags")
+	proto.RegisterMapType((map[string]string)(nil), "flynn.api.v1.DeploymentProcessTags.TagsEntry")
+	proto.RegisterType((*ExpandedDeployment)(nil), "flynn.api.v1.ExpandedDeployment")
+	proto.RegisterMapType((map[string]int32)(nil), "flynn.api.v1.ExpandedDeployment.ProcessesEntry")
+	proto.RegisterMapType((map[string]*DeploymentProcessTags)(nil), "flynn.api.v1.ExpandedDeployment.TagsEntry")
+	proto.RegisterType((*DeploymentEvent)(nil), "flynn.api.v1.DeploymentEvent")
+	proto.RegisterType((*StreamJobsRequest)(nil), "flynn.api.v1.StreamJobsRequest")
+	proto.RegisterType((*StreamJobsResponse)(nil), "flynn.api.v1.StreamJobsResponse")
+	proto.RegisterType((*CreateJobRequest)(nil), "flynn.api.v1.CreateJobRequest")
+	proto.RegisterMapType((map[string]string)(nil), "flynn.api.v1.CreateJobRequest.EnvEntry")
+	proto.RegisterMapType((map[string]string)(nil), "flynn.api.v1.CreateJobRequest.LabelsEntry")
+	proto.RegisterMapType((map[string]*HostResourceSpec)(nil), "flynn.api.v1.CreateJobRequest.ResourcesEntry")
+	proto.RegisterType((*DeleteJobRequest)(nil), "flynn.api.v1.DeleteJobRequest")
+	proto.RegisterType((*Job)(nil), "flynn.api.v1.Job")
+	proto.RegisterMapType((map[string]string)(nil), "flynn.api.v1.Job.LabelsEntry")
+	proto.RegisterType((*StreamRoutesRequest)(nil), "flynn.api.v1.StreamRoutesRequest")
+	proto.RegisterType((*StreamRoutesResponse)(nil), "flynn.api.v1.StreamRoutesResponse")
+	proto.RegisterType((*CreateRouteRequest)(nil), "flynn.api.v1.CreateRouteRequest")
+	proto.RegisterType((*UpdateRouteRequest)(nil), "flynn.api.v1.UpdateRouteRequest")
+	proto.RegisterType((*DeleteRouteRequest)(nil), "flynn.api.v1.DeleteRouteRequest")
+	proto.RegisterType((*RouteCertificate)(nil), "flynn.api.v1.RouteCertificate")
+	proto.RegisterType((*Route)(nil), "flynn.api.v1.Route")
+	proto.RegisterType((*StreamProvidersRequest)(nil), "flynn.api.v1.StreamProvidersRequest")
+	proto.RegisterType((*StreamProvidersResponse)(nil), "flynn.api.v1.StreamProvidersResponse")
+	proto.RegisterType((*CreateProviderRequest)(nil), "flynn.api.v1.CreateProviderRequest")
+	proto.RegisterType((*Provider)(nil), "flynn.api.v1.Provider")
+	proto.RegisterType((*StreamResourcesRequest)(nil), "flynn.api.v1.StreamResourcesRequest")
+	proto.RegisterType((*StreamResourcesResponse)(nil), "flynn.api.v1.StreamResourcesResponse")
+	proto.RegisterType((*CreateResourceRequest)(nil), "flynn.api.v1.CreateResourceRequest")
+	proto.RegisterType((*DeleteResourceRequest)(nil), "flynn.api.v1.DeleteResourceRequest")
+	proto.RegisterType((*Resource)(nil), "flynn.api.v1.Resource")
+	proto.RegisterMapType((map[string]string)(nil), "flynn.api.v1.Resource.EnvEntry")
+	proto.RegisterType((*StreamVolumesRequest)(nil), "flynn.api.v1.StreamVolumesRequest")
+	proto.RegisterType((*StreamVolumesResponse)(nil), "flynn.api.v1.StreamVolumesResponse")
+	proto.RegisterType((*DecommissionVolumeRequest)(nil), "flynn.api.v1.DecommissionVolumeRequest")
+	proto.RegisterType((*Volume)(nil), "flynn.api.v1.Volume")
+	proto.RegisterMapType((map[string]string)(nil), "flynn.api.v1.Volume.LabelsEntry")
+	proto.RegisterType((*StreamSinksRequest)(nil), "flynn.api.v1.StreamSinksRequest")
+	proto.RegisterType((*StreamSinksResponse)(nil), "flynn.api.v1.StreamSinksResponse")
+	proto.RegisterType((*CreateSinkRequest)(nil), "flynn.api.v1.CreateSinkRequest")
+	proto.RegisterType((*DeleteSinkRequest)(nil), "flynn.api.v1.DeleteSinkRequest")
+	proto.RegisterType((*Sink)(nil), "flynn.api.v1.Sink")
+	proto.RegisterType((*StreamEventsRequest)(nil), "flynn.api.v1.StreamEventsRequest")
+	proto.RegisterType((*StreamEventsResponse)(nil), "flynn.api.v1.StreamEventsResponse")
+	proto.RegisterType((*Event)(nil), "flynn.api.v1.Event")
+}
+
+func init() { proto.RegisterFile("controller.proto", fileDescriptor_ed7f10298fa1d90f) }
+
+var fileDescriptor_ed7f10298fa1d90f = []byte{
+	// 4367 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe4, 0x3b, 0x4d, 0x73, 0xdc, 0x46,
+	0x76, 0x9c, 0x6f, 0xcc, 0x9b, 0x19, 0x72, 0xd8, 0xfa, 0xf0, 0x08, 0x6b, 0x5b, 0x14, 0x64, 0xc9,
+	0x8c, 0x6c, 0x8f, 0x6c, 0xd9, 0x72, 0xac, 0xac, 0x63, 0x2f, 0x39, 0x1c, 0x5a, 0x94, 0x28, 0x92,
+	0x01, 0xa9, 0x75, 0x9c, 0xb8, 0x6a, 0x82, 0x19, 0x34, 0x49, 0x88, 0x18, 0x34, 0x02, 0x80, 0xa4,
+	0xb8, 0x55, 0x49, 0xe5, 0x90, 0x64, 0x37, 0xe7, 0x54, 0xaa, 0x72, 0xca, 0x47, 0xa5, 0x2a, 0x87,
+	0x54, 0x72, 0xcf, 0x25, 0x97, 0xad, 0xca, 0x21, 0x55, 0xc9, 0x21, 0x87, 0x54, 0x72, 0xc9, 0x61,
+	0xcf, 0xf9, 0x13, 0xa9, 0xfe, 0xc2, 0x34, 0x30, 0x18, 0xce, 0xd0, 0xd4, 0x5a, 0xa9, 0xca, 0x0d,
+	0xfd, 0xfa, 0xbd, 0x87, 0xee, 0xd7, 0xaf, 0xdf, 0x27, 0x00, 0xcd, 0x01, 0xf1, 0xa2, 0x80, 0xb8,
+	0x2e, 0x0e, 0xda, 0x7e, 0x40, 0x22, 0x82, 0xea, 0xfb, 0xee, 0x99, 0xe7, 0xb5, 0x2d, 0xdf, 0x69,
+	0x9f, 0x7c, 0xa4, 0xdf, 0x3c, 0x20, 0xe4, 0xc0, 0xc5, 0xf7, 0xd9, 0x5c, 0xff, 0x78, 0xff, 0x7e,
+	0xe4, 0x0c, 0x71, 0x18, 0x59, 0x43, 0x9f, 0xa3, 0xeb, 0x6f, 0xa7, 0x11, 0xec, 0xe3, 0xc0, 0x8a,
+	0x1c, 0xe2, 0x89, 0xf9, 0xa5, 0xf4, 0xfc, 0xbe, 0x83, 0x5d, 0xbb, 0x37, 0xb4, 0xc2, 0x23, 0x81,
+	0xf1, 0x83, 0x34, 0x06, 0x1e, 0xfa, 0xd1, 0x19, 0x9f, 0x34, 0xfe, 0x3a, 0x07, 0xf3, 0xbb, 0x91,
+	0x15, 0x1d, 0x87, 0x26, 0x0e, 0x7d, 0xe2, 0x85, 0x18, 0x3d, 0x82, 0x72, 0xc8, 0x20, 0xad, 0xdc,
+	0x52, 0x6e, 0x79, 0xfe, 0xc1, 0xad, 0xb6, 0xba, 0xe2, 0x76, 0x12, 0xbb, 0xdd, 0x21, 0x36, 0x36,
+	0x05, 0x01, 0xba, 0x0e, 0x65, 0x1b, 0x47, 0x96, 0xe3, 0xb6, 0xf2, 0x4b, 0xb9, 0xe5, 0xba, 0x29,
+	0x46, 0xa8, 0x05, 0x95, 0x13, 0x1c, 0x84, 0x0e, 0xf1, 0x5a, 0x85, 0xa5, 0xdc, 0x72, 0xd5, 0x94,
+	0x43, 0xc3, 0x80, 0x22, 0xe5, 0x80, 0x6a, 0x50, 0x79, 0xdc, 0x5d, 0xd9, 0xdc, 0x7b, 0xfc, 0x4d,
+	0x73, 0x0e, 0x35, 0xa0, 0xfa, 0x7c, 0x4b, 0x0e, 0x73, 0xc6, 0x9f, 0xe5, 0xa1, 0xb6, 0x69, 0xf5,
+	0xb1, 0xbb, 0xee, 0xb8, 0x11, 0x0e, 0xd0, 0x3a, 0xd4, 0xf0, 0x4b, 0x3f, 0xc0, 0x21, 0xe5, 0x40,
+	0x57, 0x59, 0x58, 0xae, 0x3d, 0x78, 0x27, 0xb9, 0x4a, 0x05, 0xbf, 0xdd, 0x8d, 0x91, 0x4d, 0x95,
+	0x50, 0xff, 0xa7, 0x1c, 0xc0, 0x68, 0x0e, 0x35, 0xa1, 0x70, 0x84, 0xcf, 0xd8, 0xa6, 0xab, 0x26,
+	0x7d, 0x44, 0xbf, 0x0e, 0x79, 0xe2, 0xb3, 0xad, 0xcc, 0x3f, 0xf8, 0x60, 0x16, 0xfe, 0xed, 0x6d,
+	0x1f, 0x07, 0x56, 0x44, 0x02, 0x33, 0x4f, 0x7c, 0x2a, 0x8d, 0x13, 0xcb, 0x3d, 0xc6, 0x61, 0xab,
+	0xb0, 0x54, 0x58, 0xae, 0x9a, 0x62, 0x64, 0xac, 0x83, 0x26, 0xf1, 0x50, 0x15, 0x4a, 0xdb, 0x3b,
+	0xbd, 0x8d, 0x2d, 0xbe, 0xeb, 0xed, 0x9d, 0xde, 0xd6, 0xf6, 0x1e, 0x1d, 0xe6, 0xc4, 0xb0, 0xfb,
+	0x9b, 0x1b, 0xbb, 0x7b, 0xbb, 0xcd, 0x3c, 0x5a, 0x84, 0x86, 0x98, 0x15, 0xa0, 0x82, 0xf1, 0x07,
+	0x79, 0x58, 0xdc, 0x8d, 0x02, 0x6c, 0x0d, 0x57, 0x7c, 0x3f, 0x34, 0xf1, 0xef, 0x1e, 0xe3, 0x30,
+	0x42, 0x3f, 0x80, 0xaa, 0x6f, 0x1d, 0xe0, 0x5e, 0xe8, 0xfc, 0x04, 0xb3, 0xcd, 0x94, 0x4c, 0x8d,
+	0x02, 0x76, 0x9d, 0x9f, 0x60, 0xf4, 0x16, 0x00, 0x9b, 0x8c, 0xc8, 0x11, 0xf6, 0xd8, 0xce, 0xaa,
+	0x26, 0x43, 0xdf, 0xa3, 0x00, 0x74, 0x0b, 0xea, 0x9e, 0x35, 0xc4, 0xbd, 0x7d, 0xb6, 0x31, 0xb9,
+	0xee, 0x1a, 0x85, 0xf1, 0xbd, 0x86, 0xe8, 0x0b, 0x68, 0xb8, 0x74, 0xef, 0x31, 0x4e, 0x91, 0x89,
+	0xff, 0xc6, 0x44, 0xf1, 0x98, 0x75, 0x77, 0x34, 0x08, 0xd1, 0x1d, 0x98, 0x0f, 0xd9, 0x9a, 0x7b,
+	0xc7, 0xbe, 0x6d, 0x45, 0x38, 0x6c, 0x95, 0x96, 0x72, 0xcb, 0x9a, 0xd9, 0xe0, 0xd0, 0xe7, 0x1c,
+	0xa8, 0xa0, 0x0d, 0x02, 0xcc, 0xd0, 0xca, 0x2a, 0x5a, 0x87, 0x03, 0x8d, 0x9f, 0xe5, 0x00, 0xa9,
+	0x22, 0x10, 0x2a, 0x7c, 0x07, 0x8a, 0x96, 0xef, 0x4b, 0xd5, 0x58, 0x4c, 0xae, 0x6d, 0xc5, 0xf7,
+	0x4d, 0x36, 0x8d, 0x6e, 0x43, 0x83, 0x49, 0x63, 0x40, 0x86, 0xbe, 0x8b, 0x23, 0xcc, 0x04, 0xa2,
+	0x99, 0x75, 0x0a, 0xec, 0x08, 0x18, 0xba, 0x0b, 0x0b, 0x1e, 0x7e, 0x19, 0xf5, 0x14, 0xb9, 0x71,
+	0x1d, 0x6e, 0x50, 0xf0, 0x8e, 0x94, 0x9d, 0xf1, 0x87, 0x79, 0xb8, 0xc6, 0x97, 0x62, 0x62, 0x17,
+	0x5b, 0x21, 0xfe, 0xff, 0x79, 0x22, 0x7f, 0x9e, 0x83, 0xeb, 0x69, 0x31, 0x88, 0x53, 0xf9, 0x08,
+	0xb4, 0x40, 0xc0, 0xc4, 0xc9, 0x5c, 0x4b, 0xae, 0x51, 0x50, 0x98, 0x31, 0xda, 0xab, 0x3d, 0xa1,
+	0x9f, 0xe5, 0xe1, 0x0a, 0x5f, 0xda, 0xee, 0xc0, 0x72, 0xbf, 0xb7, 0xf3, 0x59, 0x83, 0x06, 0x35,
+	0x8f, 0x38, 0x71, 0x3e, 0xf3, 0x0f, 0x6e, 0xa6, 0xcc, 0x2a, 0x5d, 0x92, 0x58, 0x11, 0x35, 0xb1,
+	0xd8, 0xac, 0x33, 0xaa, 0x5f, 0xce, 0x29, 0xfd, 0x6d, 0x0e, 0xae, 0x26, 0x45, 0x21, 0xce, 0x68,
+	0x05, 0xe6, 0x43, 0x0a, 0xe9, 0x05, 0x7c, 0x29, 0xf2, 0xa4, 0xf4, 0xc9, 0xab, 0x35, 0x1b, 0xa1,
+	0x32, 0x7a, 0xc5, 0x67, 0xf6, 0xaf, 0x79, 0x68, 0xf1, 0x85, 0xae, 0x61, 0xdf, 0x25, 0x67, 0x43,
+	0xec, 0x45, 0xdf, 0xd7, 0xc1, 0x7d, 0x0e, 0xf5, 0xe8, 0xcc, 0x4f, 0x9f, 0xdb, 0x8d, 0x4c, 0x9d,
+	0xdd, 0x3b, 0xf3, 0xb1, 0x59, 0xa3, 0xe8, 0x92, 0xba, 0x0b, 0xf3, 0xdc, 0x2b, 0xc6, 0xf4, 0x65,
+	0x46, 0xff, 0x76, 0x92, 0x7e, 0xb4, 0x2d, 0xe1, 0x58, 0x1b, 0x9c, 0x6a, 0xf2, 0xb9, 0x57, 0x66,
+	0x3b, 0x77, 0x2d, 0xeb, 0xdc, 0xff, 0x3e, 0x07, 0x37, 0x32, 0xc4, 0x29, 0x0e, 0x7f, 0x15, 0x6a,
+	0xf6, 0x08, 0x2c, 0x4e, 0x7e, 0x29, 0xb9, 0xde, 0xee, 0x4b, 0xdf, 0xf2, 0x6c, 0x6c, 0x8f, 0xe8,
+	0x4d, 0x95, 0xe8, 0xd5, 0x9e, 0x7e, 0x04, 0x4d, 0xbe, 0x41, 0x6a, 0xb3, 0xc5, 0xa1, 0xdf, 0x86,
+	0x82, 0xe5, 0xfb, 0xec, 0xb8, 0x33, 0x4d, 0x3b, 0x9d, 0x45, 0x3f, 0x84, 0x1a, 0x17, 0x17, 0x0b,
+	0x84, 0xd8, 0x1a, 0xa8, 0x0e, 0xf3, 0x48, 0xa8, 0x2d, 0x23, 0xa1, 0xf6, 0x3a, 0x8d, 0x95, 0x9e,
+	0x59, 0xe1, 0x91, 0x09, 0x1c, 0x9d, 0x3e, 0x1b, 0xff, 0x99, 0x07, 0xc4, 0x05, 0xa6, 0xaa, 0x39,
+	0x75, 0xe7, 0xbe, 0x15, 0x60, 0x2f, 0x12, 0x21, 0x82, 0x18, 0xa1, 0x67, 0x50, 0xf5, 0x03, 0x32,
+	0xc0, 0x21, 0xb5, 0x6b, 0x79, 0x26, 0xb3, 0xfb, 0xc9, 0x65, 0x8d, 0x33, 0x6b, 0xef, 0x48, 0x8a,
+	0xae, 0x17, 0x05, 0x67, 0xe6, 0x88, 0x03, 0xfa, 0x02, 0x8a, 0x91, 0x75, 0xc0, 0x15, 0xb2, 0xf6,
+	0xe0, 0xde, 0x54, 0x4e, 0x7b, 0xd6, 0x81, 0x60, 0xc2, 0xe8, 0xf4, 0xcf, 0x61, 0x3e, 0xc9, 0x3c,
+	0x23, 0xb0, 0xb9, 0x0a, 0x25, 0x16, 0x8b, 0x30, 0xc1, 0x94, 0x4c, 0x3e, 0xf8, 0xb5, 0xfc, 0x67,
+	0x39, 0xfd, 0x5b, 0xa8, 0xc6, 0x0c, 0x33, 0x08, 0x1f, 0xa9, 0x84, 0xb5, 0x07, 0xb7, 0x27, 0xe9,
+	0xb2, 0x58, 0x01, 0x65, 0xa5, 0x70, 0x37, 0x7e, 0x1f, 0xae, 0xf2, 0x1d, 0x48, 0x4b, 0x3f, 0x45,
+	0xb4, 0xf7, 0xa1, 0x22, 0x5c, 0x81, 0x78, 0xe1, 0x04, 0x87, 0x21, 0xb1, 0xe8, 0xa5, 0x17, 0x86,
+	0xab, 0xe7, 0xd8, 0x42, 0xa7, 0xaa, 0x02, 0xb2, 0x61, 0x1b, 0x2f, 0xe1, 0x0d, 0xfe, 0x7e, 0x45,
+	0x7b, 0xa7, 0x2c, 0xa1, 0x0b, 0x8d, 0x84, 0x41, 0x14, 0x0b, 0x59, 0x9a, 0x76, 0x2e, 0x66, 0x5d,
+	0xb5, 0x8a, 0xc6, 0xcf, 0x0b, 0x50, 0x58, 0xf1, 0x7d, 0x84, 0xa0, 0x48, 0x4d, 0x8c, 0x78, 0x09,
+	0x7b, 0xa6, 0xa6, 0xc8, 0x76, 0x42, 0xdf, 0xb5, 0xce, 0x7a, 0x6c, 0x8e, 0xdb, 0xaa, 0x9a, 0x80,
+	0x6d, 0x51, 0x94, 0x87, 0x50, 0x66, 0x3e, 0x5b, 0xaa, 0xc5, 0x5b, 0x63, 0x7a, 0xcf, 0x1d, 0xbc,
+	0xd0, 0x04, 0x81, 0x4c, 0xad, 0x02, 0xbf, 0x9b, 0x3d, 0x9a, 0x56, 0x90, 0xe3, 0xa8, 0x55, 0x64,
+	0x07, 0xde, 0xe0, 0xd0, 0x3d, 0x0e, 0x44, 0x3a, 0x68, 0x61, 0x14, 0x58, 0x11, 0x3e, 0x38, 0x63,
+	0x5e, 0xa5, 0x6a, 0xc6, 0x63, 0x1a, 0xba, 0xcb, 0x23, 0x28, 0xf3, 0xd0, 0x5d, 0x0c, 0xe9, 0x1d,
+	0xe3, 0xb6, 0x86, 0x31, 0x6f, 0x55, 0x26, 0xdc, 0xb1, 0x3d, 0x99, 0xd0, 0x98, 0xc0, 0xd1, 0x29,
+	0x40, 0xb9, 0xa0, 0x8c, 0x58, 0x9b, 0x4e, 0xcc, 0xd1, 0x25, 0xb1, 0x8d, 0x5d, 0x2c, 0x89, 0xab,
+	0xd3, 0x89, 0x39, 0x3a, 0x05, 0xe8, 0x8f, 0xa0, 0xa6, 0x88, 0x6a, 0xda, 0xe5, 0xa8, 0xaa, 0xea,
+	0xfb, 0x0f, 0x79, 0x58, 0x78, 0x4c, 0xc2, 0xe8, 0x31, 0xb6, 0xdc, 0xe8, 0xb0, 0x73, 0x88, 0x07,
+	0x47, 0xf4, 0x40, 0xa9, 0xd5, 0x97, 0x07, 0x4a, 0x9f, 0xd1, 0x43, 0xd0, 0x1c, 0x2f, 0xc2, 0xc1,
+	0x89, 0xe5, 0x32, 0x1d, 0xa4, 0xc1, 0x58, 0x7a, 0x71, 0x6b, 0x22, 0x8d, 0x33, 0x63, 0x54, 0xf4,
+	0x26, 0x54, 0xa3, 0xc3, 0x00, 0x87, 0x87, 0xc4, 0xb5, 0xc5, 0x41, 0x8d, 0x00, 0xd4, 0xd9, 0x1d,
+	0x39, 0xae, 0xdb, 0xb3, 0xc9, 0xa9, 0x27, 0x7c, 0xbf, 0x46, 0x01, 0x6b, 0xe4, 0xd4, 0xa3, 0x31,
+	0x60, 0x18, 0x59, 0x41, 0x14, 0x9f, 0x73, 0x79, 0xda, 0x6b, 0xeb, 0x0c, 0x5f, 0x6a, 0x00, 0x82,
+	0xa2, 0x6f, 0x45, 0x87, 0xec, 0x10, 0xab, 0x26, 0x7b, 0xa6, 0xb0, 0x43, 0x12, 0x46, 0xec, 0x6c,
+	0xaa, 0x26, 0x7b, 0xa6, 0xb2, 0x19, 0x5a, 0xd1, 0xe0, 0x90, 0xc9, 0xbc, 0x6a, 0xf2, 0x01, 0xbd,
+	0x3b, 0x22, 0x63, 0x04, 0xb6, 0x6a, 0x31, 0x32, 0x7e, 0x0f, 0x6a, 0x54, 0x5c, 0xbb, 0x38, 0x38,
+	0x71, 0x06, 0xe3, 0x7a, 0x9e, 0x1b, 0xd7, 0xf3, 0xeb, 0x50, 0xe6, 0x4a, 0x22, 0xdc, 0x86, 0x18,
+	0xa1, 0x8f, 0xa1, 0x34, 0xa0, 0xe2, 0x16, 0xe2, 0x4c, 0xa9, 0x7f, 0xea, 0x4c, 0x4c, 0x8e, 0x6b,
+	0x60, 0x28, 0xee, 0x90, 0x80, 0x6f, 0x8e, 0x04, 0x91, 0x88, 0x10, 0xd8, 0x33, 0xdd, 0x08, 0x93,
+	0x89, 0x3c, 0x64, 0x36, 0x40, 0x1f, 0x43, 0x25, 0xe4, 0x8b, 0x8d, 0xcf, 0x6d, 0xec, 0x45, 0x62,
+	0x37, 0xa6, 0xc4, 0x34, 0xba, 0x50, 0xfd, 0x31, 0x71, 0x8f, 0x87, 0xf4, 0xae, 0xc7, 0x82, 0xcc,
+	0x29, 0x82, 0x7c, 0x07, 0xe6, 0xb9, 0xfe, 0xf5, 0x88, 0xd7, 0x0b, 0x23, 0x91, 0x52, 0x6a, 0x66,
+	0x9d, 0x43, 0xb7, 0xbd, 0xdd, 0x88, 0xf8, 0xc6, 0x2a, 0x34, 0x29, 0x7b, 0x13, 0x87, 0xe4, 0x38,
+	0x18, 0xe0, 0x5d, 0x1f, 0x0f, 0xf8, 0xe5, 0xe3, 0x66, 0x87, 0x32, 0x2c, 0x98, 0x72, 0x48, 0xd7,
+	0xef, 0x3a, 0x43, 0x87, 0x9b, 0xa3, 0x82, 0xc9, 0x07, 0xc6, 0x5f, 0xe6, 0xa0, 0x4a, 0x99, 0x3c,
+	0x23, 0xc7, 0x1e, 0xbb, 0xd6, 0x2e, 0x19, 0xb0, 0xe3, 0x16, 0xeb, 0x89, 0xc7, 0x54, 0xd0, 0x91,
+	0x15, 0x1c, 0xe0, 0x48, 0x08, 0x40, 0x8c, 0xa8, 0x0e, 0x9e, 0x06, 0x4e, 0x84, 0xad, 0xbe, 0xcb,
+	0x65, 0xa0, 0x99, 0x23, 0x00, 0xcf, 0xef, 0x99, 0x78, 0x8a, 0x9c, 0x8a, 0x8f, 0xe8, 0xae, 0x6d,
+	0x2b, 0xb2, 0x84, 0xf1, 0x60, 0xcf, 0x74, 0x85, 0xfb, 0x2e, 0x75, 0x64, 0x65, 0xee, 0x63, 0xd8,
+	0xc0, 0xf8, 0x45, 0x0e, 0xd0, 0xa6, 0xd3, 0xef, 0x10, 0x2f, 0xb2, 0x1c, 0x0f, 0x07, 0x6b, 0x31,
+	0x83, 0xf8, 0x16, 0x95, 0xc4, 0x2d, 0x92, 0xa2, 0xcc, 0x2b, 0xa2, 0x64, 0xfa, 0xf7, 0x82, 0x04,
+	0x6c, 0x69, 0x05, 0x93, 0x0f, 0x18, 0xd4, 0xf1, 0x48, 0xd0, 0x2a, 0x0a, 0x28, 0x1d, 0xa0, 0x25,
+	0xa8, 0xf9, 0x38, 0x18, 0x3a, 0xa2, 0x4c, 0xc0, 0xd7, 0xa6, 0x82, 0xe8, 0x95, 0xda, 0x77, 0x5c,
+	0xdc, 0x1b, 0x12, 0x9b, 0x5b, 0xb7, 0x86, 0xa9, 0x51, 0xc0, 0x33, 0x5a, 0x91, 0x68, 0x42, 0xe1,
+	0xd8, 0xb1, 0xd9, 0x8d, 0x68, 0x98, 0xf4, 0x91, 0x42, 0x0e, 0x1c, 0x9b, 0xdd, 0x87, 0x86, 0x49,
+	0x1f, 0xe9, 0x8b, 0x2d, 0xd7, 0x25, 0xa7, 0xec, 0x3a, 0x68, 0x26, 0x1f, 0x18, 0x3f, 0x2d, 0x43,
+	0x4d, 0x3a, 0x40, 0xb1, 0x11, 0x2b, 0x38, 0xe0, 0xf1, 0x54, 0xd5, 0x64, 0xcf, 0xe8, 0x13, 0x28,
+	0x60, 0xef, 0x44, 0x84, 0x0b, 0x46, 0x52, 0xcb, 0x14, 0xda, 0x76, 0xd7, 0x3b, 0xe1, 0x26, 0x9d,
+	0xa2, 0xa3, 0x65, 0x28, 0x51, 0xed, 0x95, 0x5e, 0x00, 0xa5, 0xe8, 0x48, 0x10, 0x99, 0x1c, 0x01,
+	0x7d, 0x04, 0x95, 0x13, 0xa6, 0x94, 0x32, 0x1d, 0x7c, 0x23, 0x89, 0x1b, 0x6b, 0xac, 0x29, 0xf1,
+	0xe8, 0x32, 0xc9, 0xd0, 0x73, 0x84, 0x6d, 0x61, 0xcf, 0xf4, 0xca, 0xd2, 0x7b, 0xdf, 0xf3, 0x70,
+	0x74, 0x4a, 0x82, 0x23, 0x91, 0x4c, 0xd4, 0x28, 0x6c, 0x8b, 0x83, 0xd0, 0xfb, 0x80, 0x18, 0x8a,
+	0xef, 0xd8, 0xec, 0x5a, 0x87, 0xbe, 0x35, 0xc0, 0x22, 0x48, 0x6d, 0xd2, 0x99, 0x1d, 0xc7, 0xde,
+	0x92, 0x70, 0xaa, 0xd1, 0xf2, 0x86, 0x71, 0xbb, 0x22, 0x87, 0x54, 0xf3, 0x02, 0x1c, 0x1e, 0x07,
+	0x01, 0x1e, 0x44, 0x42, 0x9e, 0x23, 0x00, 0x5a, 0x67, 0xb3, 0xec, 0x66, 0x50, 0x2b, 0x43, 0x77,
+	0xb4, 0x3c, 0x59, 0x6a, 0xf2, 0x12, 0xc9, 0xe8, 0x2a, 0x26, 0x45, 0xf7, 0xa1, 0x3c, 0xa4, 0x97,
+	0x23, 0x6c, 0xd5, 0xb2, 0xc4, 0x12, 0x5f, 0x1e, 0x53, 0xa0, 0xa1, 0x0f, 0x00, 0xb9, 0x8e, 0x77,
+	0xfc, 0xb2, 0x37, 0xb0, 0x7c, 0xab, 0xef, 0xb8, 0x4e, 0xe4, 0xe0, 0xb0, 0x55, 0x67, 0x47, 0xb9,
+	0xc8, 0x66, 0x3a, 0xca, 0x04, 0xda, 0x80, 0x05, 0xa6, 0x04, 0xd8, 0xee, 0xf1, 0xbb, 0x11, 0xb6,
+	0x1a, 0x59, 0x61, 0xf4, 0xf8, 0x1d, 0x30, 0xe7, 0x05, 0x21, 0x1f, 0x86, 0xe8, 0x3d, 0x58, 0x8c,
+	0x6f, 0x5e, 0x6f, 0x70, 0x10, 0x90, 0x63, 0x3f, 0x6c, 0xcd, 0x73, 0xb9, 0xc6, 0x13, 0x1d, 0x0e,
+	0xd7, 0x3f, 0x05, 0x4d, 0xaa, 0xca, 0x45, 0x5c, 0x9a, 0xfe, 0x2d, 0xcc, 0x27, 0x85, 0x95, 0x41,
+	0xfd, 0x49, 0x32, 0xe8, 0x7b, 0x7b, 0x5c, 0x64, 0xaa, 0xd1, 0x52, 0x1d, 0xe6, 0x2f, 0x8a, 0x50,
+	0x11, 0x31, 0x5a, 0x66, 0xe4, 0xf3, 0x26, 0x54, 0xad, 0x20, 0x72, 0xf6, 0xad, 0x41, 0x24, 0x33,
+	0xb0, 0x11, 0x00, 0x7d, 0xc8, 0xef, 0x08, 0xd7, 0xdf, 0xb7, 0x33, 0x23, 0xbf, 0xd4, 0xfd, 0x78,
+	0x14, 0x87, 0x49, 0x25, 0x46, 0x74, 0x2b, 0x9b, 0x28, 0x2b, 0x54, 0x5a, 0x55, 0xa3, 0xf8, 0x72,
+	0x56, 0x49, 0x51, 0x52, 0x4f, 0x0e, 0xdd, 0x3f, 0x10, 0x56, 0xac, 0xc2, 0x2a, 0x86, 0xe7, 0x24,
+	0x8a, 0x0c, 0x2d, 0x1d, 0x40, 0x69, 0x17, 0x0d, 0xa0, 0xbe, 0x7b, 0x0c, 0xf4, 0x5d, 0xb5, 0xe5,
+	0xbb, 0xc7, 0x4e, 0xfa, 0xd7, 0x33, 0xa4, 0x25, 0xf7, 0x93, 0x8a, 0x76, 0x63, 0xe2, 0x05, 0x57,
+	0x75, 0xec, 0xbf, 0xcb, 0x50, 0x9f, 0x29, 0x4f, 0x93, 0x0a, 0x98, 0x57, 0x14, 0xf0, 0x21, 0x94,
+	0x58, 0x95, 0x85, 0xf9, 0x93, 0x19, 0x6a, 0x32, 0x1c, 0x1b, 0xfd, 0x06, 0x34, 0x88, 0x6b, 0xf7,
+	0x46, 0x0a, 0xc3, 0x75, 0xf4, 0xfd, 0xc9, 0xe4, 0xed, 0x6d, 0xd7, 0x4e, 0x29, 0x4e, 0x9d, 0x28,
+	0x20, 0xca, 0xd2, 0xc3, 0xa7, 0x0a, 0xcb, 0xd2, 0x54, 0x96, 0x5b, 0xf8, 0x34, 0xcd, 0xd2, 0x53,
+	0x40, 0x68, 0x15, 0x34, 0xba, 0xca, 0x88, 0x3b, 0x61, 0xca, 0xed, 0xdd, 0xf3, 0x17, 0x38, 0x4a,
+	0x25, 0x2b, 0x84, 0x8f, 0x28, 0x0f, 0xba, 0x2c, 0xc6, 0xa3, 0x32, 0x95, 0xc7, 0x16, 0x3e, 0x55,
+	0x78, 0x78, 0x7c, 0x74, 0x69, 0x3d, 0x57, 0x13, 0x85, 0xea, 0x45, 0x12, 0x05, 0xfd, 0x4b, 0x58,
+	0x1c, 0x93, 0xfb, 0x85, 0xd2, 0xe1, 0x2f, 0x61, 0x71, 0x4c, 0xca, 0x17, 0x62, 0xd0, 0x83, 0xba,
+	0x2a, 0xd8, 0x57, 0x9e, 0x52, 0xd3, 0x17, 0xa8, 0x52, 0x7f, 0xf5, 0x39, 0xfb, 0x9f, 0xe6, 0xe0,
+	0x5a, 0x26, 0x12, 0x5a, 0x11, 0x95, 0x0a, 0x5e, 0x27, 0xfa, 0x60, 0x06, 0xbe, 0x63, 0xc5, 0x8a,
+	0x5f, 0x3d, 0xbf, 0xdc, 0x30, 0x39, 0x15, 0xfb, 0x8b, 0x32, 0xa0, 0xf1, 0x52, 0x54, 0xa6, 0x93,
+	0xf9, 0x14, 0x6a, 0xf4, 0x1a, 0xc8, 0x2c, 0xb6, 0x70, 0x5e, 0x21, 0x01, 0x88, 0x6b, 0x8b, 0x67,
+	0x4a, 0x47, 0x55, 0x5f, 0xd2, 0x15, 0xcf, 0xa5, 0xf3, 0xf0, 0xa9, 0xa4, 0x93, 0x5e, 0xa0, 0x34,
+	0x9b, 0x17, 0x50, 0x93, 0xef, 0x72, 0x2a, 0xf9, 0xfe, 0x34, 0x4e, 0xac, 0xb8, 0x4b, 0x99, 0x56,
+	0x3b, 0x14, 0xd8, 0xc9, 0x92, 0x94, 0x96, 0x55, 0x92, 0x1a, 0x97, 0xdd, 0x0c, 0x25, 0xa9, 0x6a,
+	0x56, 0x49, 0x2a, 0x83, 0x53, 0xea, 0x94, 0x33, 0xca, 0x10, 0x90, 0x55, 0x86, 0x48, 0xd9, 0x89,
+	0xda, 0x45, 0xed, 0x04, 0x7e, 0xe9, 0x3b, 0x81, 0x20, 0xae, 0x4f, 0x27, 0xe6, 0xe8, 0x8c, 0xf8,
+	0x21, 0x68, 0xd8, 0xb3, 0x39, 0x65, 0x63, 0x2a, 0x65, 0x05, 0x7b, 0x36, 0x33, 0x2f, 0xff, 0x97,
+	0x4b, 0x6d, 0xff, 0x92, 0x87, 0x85, 0x11, 0x52, 0xf7, 0x04, 0x7b, 0x93, 0x3d, 0xe3, 0x0d, 0xd0,
+	0x5e, 0x90, 0x7e, 0x8f, 0x69, 0x2d, 0xbf, 0x69, 0x95, 0x17, 0xa4, 0xcf, 0x72, 0x97, 0x0e, 0x54,
+	0xe9, 0x94, 0xea, 0x24, 0xef, 0x4e, 0x5a, 0x09, 0x7b, 0x49, 0xfb, 0x09, 0xe9, 0x73, 0x5f, 0xa9,
+	0xbd, 0x10, 0x4f, 0x54, 0x06, 0xdd, 0x20, 0x10, 0xf9, 0x59, 0xd5, 0xe4, 0x83, 0xf4, 0x71, 0x97,
+	0x2e, 0x72, 0xdc, 0xc6, 0x11, 0x68, 0xf2, 0x45, 0xb4, 0x77, 0xbc, 0xd3, 0xdd, 0x5a, 0xdb, 0xd8,
+	0xfa, 0xaa, 0x39, 0x47, 0x07, 0xab, 0x9b, 0xdb, 0x9d, 0xa7, 0xdd, 0xb5, 0x66, 0x0e, 0xd5, 0x41,
+	0xdb, 0xdd, 0x5b, 0x31, 0xf7, 0xe8, 0x54, 0x1e, 0x95, 0x21, 0xff, 0x7c, 0xa7, 0x59, 0xe0, 0xd0,
+	0xed, 0x9d, 0x1d, 0x0a, 0x2d, 0x21, 0x0d, 0x8a, 0x6b, 0xdb, 0x5f, 0x6f, 0x35, 0xcb, 0x94, 0xb4,
+	0x63, 0xae, 0xec, 0x3e, 0xee, 0xae, 0x35, 0x2b, 0x08, 0xa0, 0xbc, 0xbe, 0xb2, 0xb1, 0xd9, 0x5d,
+	0x6b, 0x6a, 0xc6, 0xbf, 0xe7, 0x64, 0xa3, 0xf5, 0x09, 0xe9, 0xc7, 0xdd, 0x87, 0x74, 0x07, 0x21,
+	0x37, 0xde, 0x41, 0x78, 0x9a, 0x6e, 0xfd, 0xe4, 0x97, 0x0a, 0x17, 0x90, 0xe0, 0xb4, 0x0e, 0x50,
+	0x61, 0xb6, 0x4e, 0x40, 0x31, 0xab, 0x13, 0xf0, 0x3b, 0x80, 0xd4, 0x2d, 0x8d, 0x1a, 0xa7, 0x2f,
+	0x48, 0x7f, 0x42, 0xe3, 0xf4, 0x09, 0xe9, 0x9b, 0x6c, 0x7a, 0xa6, 0x22, 0xbf, 0xf1, 0x77, 0x25,
+	0x68, 0xf2, 0xb7, 0x51, 0xc2, 0x29, 0xc1, 0x59, 0x22, 0x13, 0xc8, 0xa7, 0x33, 0x81, 0x9b, 0x50,
+	0x13, 0x66, 0xb8, 0x47, 0x33, 0x02, 0xbe, 0x6f, 0x10, 0xa0, 0xae, 0x77, 0x12, 0xa7, 0xd8, 0x45,
+	0x25, 0xc5, 0x7e, 0xc4, 0xd3, 0x87, 0x52, 0x56, 0xd4, 0x92, 0x5e, 0x57, 0x2a, 0x8f, 0x58, 0x8d,
+	0xf3, 0x88, 0xf2, 0xe4, 0x2a, 0xbc, 0x42, 0x9d, 0x95, 0x50, 0xdc, 0x04, 0x5a, 0xd9, 0x62, 0xc9,
+	0x9b, 0x4b, 0x0e, 0x44, 0x42, 0x0c, 0x02, 0xb4, 0x49, 0x0e, 0xd0, 0x53, 0x35, 0xa5, 0xd5, 0xb2,
+	0x7c, 0xe8, 0xd8, 0x7b, 0x26, 0xe7, 0xb5, 0xb2, 0x02, 0xc3, 0x13, 0x67, 0xf6, 0x4c, 0x65, 0xea,
+	0x53, 0x19, 0xb2, 0x02, 0x10, 0xc8, 0x06, 0x98, 0x00, 0x50, 0xbf, 0xe3, 0x07, 0x84, 0x96, 0x3b,
+	0x78, 0x2e, 0x5c, 0x35, 0xe3, 0x31, 0x5d, 0x3b, 0x4f, 0x7f, 0x7b, 0xfb, 0x01, 0x19, 0x32, 0x63,
+	0x5a, 0x35, 0x81, 0x83, 0xd6, 0x03, 0x32, 0x7c, 0x1d, 0x09, 0xc4, 0x2f, 0x37, 0x53, 0xbd, 0x0b,
+	0xcd, 0x35, 0x96, 0x1f, 0x29, 0xba, 0x9a, 0x11, 0x4c, 0x18, 0xff, 0x55, 0x82, 0xc2, 0x13, 0xd2,
+	0xcf, 0x9a, 0x53, 0x4b, 0xe5, 0xf9, 0x64, 0xa9, 0xfc, 0x1a, 0x94, 0xa9, 0x15, 0x8d, 0x5b, 0x12,
+	0xa5, 0x17, 0xa4, 0xbf, 0x61, 0xa3, 0x37, 0xa0, 0xc2, 0x4a, 0x27, 0x8e, 0x2d, 0xeb, 0x69, 0x74,
+	0xb8, 0x61, 0xc7, 0xe5, 0xb0, 0x92, 0x52, 0x54, 0xfe, 0x5c, 0xa6, 0x2a, 0xe5, 0x0b, 0x59, 0x61,
+	0x4e, 0x14, 0x5f, 0x90, 0x8a, 0x72, 0x41, 0x5a, 0xa3, 0x1a, 0x91, 0xc6, 0xc0, 0x72, 0xa8, 0xb4,
+	0x1b, 0xaa, 0x59, 0xed, 0x86, 0x27, 0xa4, 0x3f, 0x49, 0xe5, 0xf1, 0x4b, 0x27, 0xea, 0x25, 0x8a,
+	0xc1, 0x40, 0x41, 0x3c, 0x3e, 0xa1, 0xed, 0x19, 0xb6, 0x61, 0xcc, 0xbc, 0x41, 0x8d, 0xab, 0x24,
+	0x85, 0x70, 0x8f, 0xa0, 0xd3, 0x0f, 0x04, 0x58, 0x5d, 0x3a, 0x64, 0x3a, 0x57, 0x32, 0xe3, 0x31,
+	0x35, 0x6b, 0x7d, 0x97, 0x0c, 0x8e, 0x30, 0x8d, 0xe4, 0xac, 0x90, 0x78, 0xcc, 0x51, 0x57, 0xcd,
+	0x86, 0x80, 0x9a, 0x0c, 0x48, 0x8d, 0xb2, 0x1f, 0x60, 0x3c, 0xf4, 0x23, 0x6c, 0xf7, 0xfa, 0x67,
+	0xad, 0x79, 0x51, 0xf5, 0x93, 0xb0, 0xd5, 0x33, 0x74, 0x0f, 0x16, 0x07, 0x81, 0x15, 0x1e, 0xf6,
+	0x5c, 0x42, 0x7c, 0xc9, 0x6c, 0x81, 0xe1, 0x2d, 0xb0, 0x89, 0x4d, 0x42, 0x7c, 0xc1, 0xee, 0x21,
+	0x68, 0xc1, 0xb1, 0xc7, 0x1d, 0x54, 0x73, 0x7a, 0x60, 0x10, 0x1c, 0x7b, 0x32, 0x18, 0x51, 0x5d,
+	0xdb, 0xe2, 0x65, 0x32, 0x1e, 0x74, 0xa1, 0x8c, 0xe7, 0x12, 0xdd, 0x8d, 0x3f, 0xce, 0xc9, 0xcf,
+	0x23, 0x4c, 0x72, 0x1c, 0xe1, 0x8b, 0xf8, 0xb9, 0x71, 0xd7, 0x94, 0x9f, 0xcd, 0x35, 0x15, 0xb2,
+	0x5c, 0xd3, 0x21, 0x5c, 0x4d, 0xae, 0x43, 0x38, 0xa7, 0xf7, 0xa0, 0x1c, 0x30, 0x88, 0x70, 0x4f,
+	0x57, 0x52, 0xa1, 0x35, 0x9d, 0x33, 0x05, 0xca, 0x6c, 0x2e, 0xea, 0x6b, 0xd9, 0xe8, 0xe5, 0xb4,
+	0x53, 0x7c, 0xd4, 0xaf, 0x40, 0x89, 0x31, 0x17, 0xd6, 0x25, 0xf3, 0xf5, 0x1c, 0xc3, 0xf8, 0x12,
+	0x10, 0xdf, 0x74, 0x82, 0x71, 0xcc, 0x20, 0x37, 0x95, 0xc1, 0x32, 0x20, 0x6e, 0x8f, 0x12, 0x0c,
+	0xb2, 0x2c, 0xd2, 0x67, 0xd0, 0x64, 0x38, 0x1d, 0x4c, 0xdd, 0xa5, 0x33, 0x10, 0xb7, 0x7d, 0x80,
+	0x03, 0xb9, 0x7e, 0xf6, 0x2c, 0x55, 0x21, 0x1f, 0xab, 0x82, 0xf1, 0x1f, 0x45, 0x28, 0x31, 0xd2,
+	0x4c, 0x6b, 0x26, 0x6d, 0x50, 0x5e, 0xb1, 0x41, 0xad, 0x64, 0x7f, 0x44, 0xa9, 0xde, 0xca, 0x1e,
+	0x4b, 0x51, 0xe9, 0xb1, 0x5c, 0x87, 0xb2, 0x8b, 0x2d, 0x1b, 0x07, 0xa2, 0xa4, 0x2c, 0x46, 0x14,
+	0x6e, 0x93, 0xa1, 0xe5, 0x78, 0x22, 0xdf, 0x11, 0x23, 0xf4, 0x23, 0xa8, 0x0d, 0x46, 0x9b, 0x68,
+	0x55, 0xb2, 0x6c, 0x78, 0x7a, 0xab, 0xa6, 0x4a, 0xc2, 0x1b, 0x51, 0xce, 0xe0, 0xe8, 0x4c, 0x7c,
+	0xfd, 0x20, 0x46, 0x71, 0x2b, 0xa1, 0xaa, 0xb4, 0x12, 0x68, 0x52, 0x12, 0x58, 0x8e, 0xd7, 0xeb,
+	0x5b, 0x83, 0x23, 0xec, 0xd9, 0xdc, 0x5e, 0x69, 0x66, 0x83, 0x41, 0x57, 0x05, 0x10, 0xb5, 0xe1,
+	0x8a, 0x74, 0xe3, 0x47, 0x18, 0xfb, 0x3d, 0xcb, 0x75, 0x4e, 0x98, 0xc7, 0xa4, 0xb8, 0x8b, 0x62,
+	0xea, 0x29, 0xc6, 0xfe, 0x0a, 0x9b, 0xa0, 0x6c, 0x07, 0x96, 0x67, 0x05, 0x67, 0x71, 0xe2, 0xc8,
+	0xbd, 0x67, 0x83, 0x43, 0x65, 0x92, 0x78, 0x1b, 0x04, 0xa0, 0x77, 0x8a, 0x9d, 0x83, 0xc3, 0x88,
+	0x59, 0xb3, 0x92, 0x59, 0xe7, 0xc0, 0xaf, 0x19, 0x2c, 0x6d, 0x46, 0xe6, 0x2f, 0x63, 0x46, 0x16,
+	0x2e, 0xd3, 0x61, 0x6d, 0x5e, 0xa4, 0xba, 0x68, 0xfc, 0x49, 0xfc, 0x09, 0xd8, 0x4e, 0x40, 0x4e,
+	0x1c, 0x1b, 0x07, 0xaf, 0xcf, 0x96, 0x44, 0xf0, 0xc6, 0xd8, 0x52, 0x84, 0x39, 0xf9, 0x84, 0x25,
+	0xc9, 0x1c, 0x28, 0x2c, 0xca, 0xf5, 0xb1, 0x8a, 0x23, 0x9b, 0x36, 0x47, 0x88, 0xb3, 0xd9, 0x95,
+	0xa7, 0x70, 0x8d, 0x2f, 0x20, 0xe6, 0x20, 0xf6, 0xff, 0x80, 0x05, 0x5d, 0x0c, 0x24, 0x8c, 0xc0,
+	0xa4, 0x57, 0xc6, 0x78, 0xc6, 0xbf, 0xe5, 0x40, 0x93, 0xe0, 0xef, 0xfa, 0xfd, 0x00, 0x6d, 0x66,
+	0x05, 0xae, 0xb8, 0xb4, 0xf4, 0x31, 0xad, 0x5b, 0xc5, 0xcb, 0xe8, 0x56, 0xe9, 0x22, 0xba, 0xa5,
+	0xa8, 0x47, 0x1c, 0xcf, 0xbd, 0x7e, 0xf5, 0x50, 0x96, 0x32, 0x52, 0x8f, 0x51, 0x78, 0x9e, 0xa9,
+	0x1e, 0x92, 0x46, 0x8d, 0xc3, 0x67, 0x52, 0x8f, 0xdf, 0x96, 0xea, 0x11, 0x73, 0x98, 0xe2, 0x79,
+	0x68, 0x5b, 0x9c, 0x78, 0xfb, 0xce, 0x81, 0xfc, 0xae, 0x9a, 0x8f, 0x10, 0x12, 0xdf, 0xb9, 0x16,
+	0x44, 0x54, 0xe7, 0xfb, 0xa1, 0xf1, 0x1e, 0x2d, 0xd7, 0xb9, 0x78, 0x9c, 0x79, 0x96, 0xf3, 0xf8,
+	0xc7, 0x3c, 0x68, 0x12, 0x2f, 0x53, 0xb7, 0x58, 0x48, 0x17, 0xe1, 0xc0, 0xb3, 0x5c, 0x1a, 0xa6,
+	0x72, 0xd5, 0x02, 0x09, 0xda, 0xb0, 0xd1, 0x47, 0x3c, 0xcb, 0xe2, 0x0d, 0xc9, 0x9b, 0xd9, 0x02,
+	0x4a, 0x65, 0x57, 0x72, 0xd5, 0xc5, 0xd1, 0xaa, 0x2f, 0x55, 0x0c, 0x48, 0x5b, 0xab, 0xf2, 0xf7,
+	0xd1, 0x0b, 0x31, 0x7e, 0x1a, 0x7f, 0x42, 0xc9, 0x7b, 0xa9, 0xaf, 0x4f, 0x89, 0x5d, 0xb8, 0x96,
+	0x5a, 0x88, 0x50, 0xe1, 0xf6, 0x28, 0xc0, 0xe7, 0x0a, 0x7c, 0x35, 0xb3, 0x09, 0x2c, 0x91, 0x66,
+	0x53, 0xde, 0xfb, 0x70, 0x63, 0x0d, 0x0f, 0xc8, 0x50, 0x74, 0xd1, 0xe3, 0x46, 0xf2, 0x44, 0x1d,
+	0xfb, 0x9f, 0x22, 0x94, 0x39, 0xd6, 0xa4, 0x38, 0x63, 0xac, 0xcd, 0x3f, 0xfe, 0xc5, 0x44, 0x61,
+	0xfc, 0x8b, 0x89, 0x8b, 0xa5, 0x4f, 0x1f, 0x26, 0xd3, 0x27, 0x3d, 0x4b, 0x12, 0xed, 0x44, 0xca,
+	0xa4, 0xa4, 0x73, 0x95, 0x49, 0xe9, 0x9c, 0xa6, 0xa6, 0x73, 0x6a, 0x19, 0xad, 0x9a, 0x2c, 0xa3,
+	0x7d, 0x16, 0x27, 0x54, 0x90, 0xd5, 0x0d, 0x16, 0xaf, 0xcf, 0xca, 0xa9, 0x2e, 0x5b, 0x14, 0x55,
+	0xed, 0x74, 0xfd, 0x42, 0x31, 0xc0, 0x57, 0xb0, 0x68, 0x2b, 0x07, 0x3d, 0x6b, 0x75, 0xb4, 0xa9,
+	0x12, 0x5d, 0x36, 0x27, 0xf9, 0x10, 0x4a, 0xd9, 0x35, 0xbe, 0x8e, 0xd9, 0x5d, 0xd9, 0x63, 0x35,
+	0xbe, 0x06, 0x54, 0xd7, 0xba, 0xbb, 0x7b, 0xe6, 0xf6, 0x37, 0xdd, 0xb5, 0x66, 0xde, 0xf8, 0xa3,
+	0xf8, 0x8f, 0x80, 0x5d, 0xc7, 0x3b, 0x7a, 0x7d, 0x97, 0xd2, 0x86, 0x2b, 0x89, 0x65, 0x88, 0x2b,
+	0xb9, 0x0c, 0xa5, 0x90, 0x02, 0x5a, 0xb9, 0xac, 0x2f, 0x38, 0x28, 0xae, 0xc9, 0x11, 0x66, 0xbb,
+	0x8c, 0x3f, 0x84, 0x45, 0xfe, 0x42, 0x46, 0x29, 0xf6, 0x7a, 0x17, 0x8a, 0x94, 0x85, 0x08, 0x30,
+	0xb2, 0x5e, 0xc1, 0xe6, 0x8d, 0x77, 0x61, 0x91, 0x7b, 0x0a, 0x95, 0x38, 0xeb, 0x06, 0xff, 0x55,
+	0x1e, 0x8a, 0x14, 0x67, 0xd2, 0xfd, 0x3d, 0x72, 0x3c, 0xe9, 0x1a, 0xd8, 0x73, 0xfc, 0xd9, 0xc8,
+	0xd0, 0xf2, 0xac, 0x03, 0x6c, 0xb7, 0x0a, 0xa3, 0xcf, 0x46, 0x9e, 0x71, 0x90, 0xe2, 0xd2, 0x8a,
+	0x09, 0x97, 0x76, 0x59, 0x47, 0xa0, 0xea, 0x7b, 0xf9, 0x32, 0x31, 0x6f, 0xe5, 0x42, 0x31, 0xef,
+	0xdf, 0xc4, 0xff, 0x16, 0xb0, 0xfa, 0xcb, 0x6c, 0x9f, 0xa8, 0xa7, 0x95, 0x32, 0x3f, 0xae, 0x94,
+	0x6d, 0xb8, 0x42, 0xfa, 0x2f, 0xf0, 0x20, 0xea, 0x25, 0x3e, 0x45, 0xe7, 0x0e, 0x7f, 0x91, 0x4f,
+	0xed, 0x29, 0x5f, 0x9d, 0xdf, 0x03, 0x01, 0xec, 0x39, 0x76, 0xe2, 0xc3, 0xf5, 0xaa, 0xb9, 0xc0,
+	0x27, 0x36, 0x6c, 0x89, 0x7b, 0x1d, 0xca, 0x7d, 0xbc, 0x4f, 0x02, 0x69, 0x28, 0xc5, 0x88, 0x5e,
+	0xc7, 0xd0, 0xf1, 0x06, 0xf2, 0x83, 0x4f, 0x3e, 0xa0, 0x3b, 0x09, 0x8f, 0x1c, 0xbf, 0xe7, 0x5b,
+	0x61, 0x24, 0xaa, 0x99, 0x1a, 0x05, 0xec, 0x58, 0x61, 0x34, 0xeb, 0xe7, 0xe7, 0x71, 0x66, 0x2f,
+	0x85, 0x34, 0xca, 0xec, 0xf1, 0x89, 0xf2, 0xcd, 0x79, 0x2a, 0x33, 0x66, 0xd8, 0xa6, 0x40, 0x99,
+	0xed, 0x62, 0xfc, 0x3c, 0x07, 0x25, 0x46, 0x96, 0xa9, 0xb3, 0xa3, 0x38, 0x2b, 0x9f, 0x88, 0xb3,
+	0x6e, 0x42, 0x4d, 0x91, 0xb6, 0x08, 0x97, 0x61, 0x24, 0x65, 0x2a, 0x84, 0x58, 0xbc, 0xc2, 0xe9,
+	0x68, 0x52, 0xac, 0x89, 0xaf, 0xe0, 0xea, 0xa2, 0x06, 0x9b, 0x52, 0xe7, 0xf2, 0x45, 0xd4, 0xf9,
+	0xde, 0xfb, 0x50, 0x53, 0xfa, 0x85, 0xa8, 0x02, 0x85, 0x95, 0x2d, 0xfa, 0x7f, 0x9c, 0x06, 0xc5,
+	0xce, 0xf6, 0x5a, 0xb7, 0x99, 0xa3, 0x5d, 0x8a, 0xce, 0xf6, 0xd6, 0xfa, 0xc6, 0x57, 0xcd, 0xfc,
+	0xbd, 0x6d, 0x58, 0x1c, 0xfb, 0x60, 0x81, 0xfe, 0x36, 0xb6, 0xdb, 0x59, 0xd9, 0xec, 0xf6, 0x46,
+	0xd6, 0xf3, 0x0a, 0x2c, 0x70, 0x50, 0x67, 0x65, 0xab, 0xd3, 0xdd, 0xdc, 0x64, 0x56, 0x14, 0xc1,
+	0xbc, 0x00, 0x6e, 0x3f, 0xdb, 0xd9, 0xec, 0xee, 0x75, 0x9b, 0xf9, 0x7b, 0xeb, 0xd0, 0x1c, 0x95,
+	0x15, 0x45, 0x05, 0x2f, 0x61, 0x87, 0x47, 0x3d, 0x92, 0x1c, 0x9d, 0x30, 0x9f, 0x6f, 0x6d, 0xf1,
+	0x4e, 0x4b, 0x1d, 0xb4, 0x98, 0x4f, 0xe1, 0xc1, 0x3f, 0x2f, 0x02, 0x74, 0xe2, 0xdf, 0x20, 0xd1,
+	0x17, 0x50, 0x16, 0xcc, 0xae, 0x8f, 0xc9, 0xa1, 0x4b, 0x7f, 0x4d, 0xd4, 0xdf, 0x3c, 0xef, 0x8f,
+	0x43, 0xb4, 0x0b, 0x30, 0xfa, 0xe5, 0x0b, 0xa5, 0x3f, 0xd9, 0x48, 0xff, 0x0f, 0xa7, 0x2f, 0x4d,
+	0x46, 0xe0, 0x0c, 0x8d, 0xb9, 0x0f, 0x73, 0xa8, 0x07, 0xf3, 0x7c, 0xc6, 0x8c, 0x7f, 0x3d, 0xca,
+	0xa2, 0x4b, 0xfd, 0xda, 0xa5, 0xbf, 0x73, 0x3e, 0x92, 0xf2, 0x82, 0x6f, 0xa0, 0xae, 0xfe, 0x70,
+	0x83, 0x6e, 0x65, 0x51, 0x26, 0xfe, 0x4b, 0xd2, 0x8d, 0xf3, 0x50, 0x14, 0xd6, 0x87, 0xb2, 0x3b,
+	0xb5, 0xa6, 0xfc, 0x87, 0x71, 0x37, 0x8b, 0x78, 0xfc, 0x1f, 0x1a, 0xfd, 0xdd, 0xa9, 0x78, 0xca,
+	0x9b, 0x7e, 0x04, 0xd5, 0xf8, 0x7f, 0x0c, 0x94, 0xaa, 0xcc, 0xa4, 0x7f, 0xd4, 0xd0, 0xc7, 0xff,
+	0xcd, 0x30, 0xe6, 0xd0, 0x33, 0xa8, 0x29, 0xdf, 0xca, 0xa3, 0xa9, 0x9f, 0xd1, 0xeb, 0xe7, 0xfc,
+	0x78, 0x64, 0xcc, 0xa1, 0x4d, 0x68, 0x24, 0x7e, 0x28, 0x40, 0x46, 0x16, 0xc3, 0xe4, 0xdf, 0x06,
+	0x7a, 0x76, 0x0f, 0xdf, 0x98, 0x43, 0xdf, 0xca, 0x86, 0xd5, 0x68, 0xff, 0xe8, 0x4e, 0x16, 0xc3,
+	0xb1, 0xdf, 0x07, 0xf4, 0xb7, 0xce, 0x2d, 0xc7, 0x33, 0xe1, 0xc5, 0x7a, 0x4b, 0x3b, 0x6e, 0xd9,
+	0x7a, 0xab, 0xb4, 0x17, 0xf5, 0xa5, 0xc9, 0x08, 0xc9, 0x13, 0x89, 0xbb, 0x44, 0xe9, 0x13, 0x49,
+	0xb7, 0x8f, 0xf4, 0xf1, 0x7e, 0x9e, 0x31, 0x87, 0xba, 0x50, 0x8d, 0x3b, 0x1f, 0x68, 0xec, 0x03,
+	0x83, 0x64, 0x4b, 0x44, 0x9f, 0x70, 0x63, 0x8d, 0xb9, 0x91, 0x7e, 0xf3, 0xa2, 0x6d, 0xb6, 0x7e,
+	0x27, 0x0a, 0xcb, 0xba, 0x71, 0x1e, 0x8a, 0xb2, 0xc7, 0x75, 0xa9, 0x33, 0x6c, 0x2e, 0x5b, 0x67,
+	0xd4, 0x32, 0xa9, 0x9e, 0x55, 0x58, 0x35, 0xe6, 0x28, 0x1f, 0xa5, 0x28, 0x9b, 0xe6, 0x33, 0x5e,
+	0xaf, 0x9d, 0xc4, 0x67, 0x03, 0x6a, 0x4a, 0x6d, 0x36, 0xcd, 0x67, 0xbc, 0x6c, 0x7b, 0x8e, 0xd4,
+	0xfa, 0xb0, 0x90, 0x2a, 0x4f, 0xa1, 0x4c, 0x93, 0x92, 0x2e, 0xa4, 0xe9, 0x77, 0xa6, 0x60, 0x29,
+	0xe2, 0xdb, 0x86, 0xf9, 0x64, 0x31, 0x2a, 0x6d, 0xda, 0x32, 0x4b, 0x55, 0xfa, 0x84, 0xc2, 0x94,
+	0xba, 0xe8, 0xb8, 0x68, 0x82, 0x26, 0xd8, 0xc1, 0x64, 0x79, 0x47, 0xbf, 0x33, 0x05, 0x2b, 0x6b,
+	0xd1, 0x72, 0x3a, 0x7b, 0xd1, 0xa9, 0x1a, 0x87, 0x3e, 0xa1, 0x42, 0x63, 0xcc, 0x51, 0x86, 0xc9,
+	0xb2, 0x08, 0xba, 0x9d, 0x79, 0x6e, 0x33, 0x33, 0xfc, 0x16, 0x1a, 0x89, 0xac, 0x1b, 0x65, 0xaa,
+	0x73, 0xb2, 0x36, 0xa0, 0xdf, 0x3e, 0x17, 0x47, 0xd9, 0xff, 0x73, 0x5a, 0xff, 0x4f, 0x67, 0xd9,
+	0xe8, 0xdd, 0xf4, 0x92, 0x27, 0xe4, 0xe1, 0x7a, 0x66, 0xa2, 0x6f, 0xcc, 0xa1, 0x1f, 0x43, 0x4d,
+	0xc9, 0x4a, 0x50, 0xa6, 0x8d, 0x51, 0xf3, 0x26, 0xfd, 0xd6, 0x39, 0x18, 0xca, 0x72, 0x3b, 0x00,
+	0xa3, 0x3c, 0x24, 0x6d, 0xdb, 0xc6, 0x32, 0x14, 0x3d, 0x23, 0x27, 0x31, 0xe6, 0x28, 0x93, 0x51,
+	0x3e, 0x92, 0x66, 0x32, 0x96, 0xa9, 0x4c, 0x60, 0x12, 0xdb, 0x21, 0x1e, 0x62, 0x66, 0xdb, 0xa1,
+	0x44, 0x8c, 0xae, 0x1b, 0xe7, 0xa1, 0x8c, 0x36, 0xb9, 0x5a, 0xfa, 0xad, 0x82, 0xe5, 0x3b, 0xfd,
+	0x32, 0xbb, 0xc5, 0x1f, 0xff, 0xef, 0x00, 0xa3, 0x8f, 0xc7, 0xe5, 0xdb, 0x41, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// ControllerClient is the client API for Controller service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ControllerClient interface {
+	Status(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*StatusResponse, error)
+	StreamApps(ctx context.Context, in *StreamAppsRequest, opts ...grpc.CallOption) (Controller_StreamAppsClient, error)
+	StreamReleases(ctx context.Context, in *StreamReleasesRequest, opts ...grpc.CallOption) (Controller_StreamReleasesClient, error)
+	StreamScales(ctx context.Context, in *StreamScalesRequest, opts ...grpc.CallOption) (Controller_StreamScalesClient, error)
+	StreamDeployments(ctx context.Context, in *StreamDeploymentsRequest, opts ...grpc.CallOption) (Controller_StreamDeploymentsClient, error)
+	UpdateApp(ctx context.Context, in *UpdateAppRequest, opts ...grpc.CallOption) (*App, error)
+	CreateScale(ctx context.Context, in *CreateScaleRequest, opts ...grpc.CallOption) (*ScaleRequest, error)
+	CreateRelease(ctx context.Context, in *CreateReleaseRequest, opts ...grpc.CallOption) (*Release, error)
+	CreateDeployment(ctx context.Context, in *CreateDeploymentRequest, opts ...grpc.CallOption) (Controller_CreateDeploymentClient, error)
+	StreamJobs(ctx context.Context, in *StreamJobsRequest, opts ...grpc.CallOption) (Controller_StreamJobsClient, error)
+	CreateJob(ctx context.Context, in *CreateJobRequest, opts ...grpc.CallOption) (*Job, error)
+	DeleteJob(ctx context.Context, in *DeleteJobRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	StreamRoutes(ctx context.Context, in *StreamRoutesRequest, opts ...grpc.CallOption) (Controller_StreamRoutesClient, error)
+	CreateRoute(ctx context.Context, in *CreateRouteRequest, opts ...grpc.CallOption) (*Route, error)
+	UpdateRoute(ctx context.Context, in *UpdateRouteRequest, opts ...grpc.CallOption) (*Route, error)
+	DeleteRoute(ctx context.Context, in *DeleteRouteRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	StreamProviders(ctx context.Context, in *StreamProvidersRequest, opts ...grpc.CallOption) (Controller_StreamProvidersClient, error)
+	CreateProvider(ctx context.Context, in *CreateProviderRequest, opts ...grpc.CallOption) (*Provider, error)
+	StreamResources(ctx context.Context, in *StreamResourcesRequest, opts ...grpc.CallOption) (Controller_StreamResourcesClient, error)
+	CreateResource(ctx context.Context, in *CreateResourceRequest, opts ...grpc.CallOption) (*Resource, error)
+	DeleteResource(ctx context.Context, in *DeleteResourceRequest, opts ...grpc.CallOption) (*Resource, error)
+	StreamVolumes(ctx context.Context, in *StreamVolumesRequest, opts ...grpc.CallOption) (Controller_StreamVolumesClient, error)
+	DecommissionVolume(ctx context.Context, in *DecommissionVolumeRequest, opts ...grpc.CallOption) (*Volume, error)
+	StreamSinks(ctx context.Context, in *StreamSinksRequest, opts ...grpc.CallOption) (Controller_StreamSinksClient, error)
+	CreateSink(ctx context.Context, in *CreateSinkRequest, opts ...grpc.CallOption) (*Sink, error)
+	DeleteSink(ctx context.Context, in *DeleteSinkRequest, opts ...grpc.CallOption) (*Sink, error)
+	StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (Controller_StreamEventsClient, error)
+}
+
+type controllerClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewControllerClient(cc *grpc.ClientConn) ControllerClient {
+	return &controllerClient{cc}
+}
+
+func (c *controllerClient) Status(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, "/flynn.api.v1.Controller/Status", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controllerClient) StreamApps(ctx context.Context, in *StreamAppsRequest, opts ...grpc.CallOption) (Controller_StreamAppsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Controller_serviceDesc.Streams[0], "/flynn.api.v1.Controller/StreamApps", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &controllerStreamAppsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Controller_StreamAppsClient interface {
+	Recv() (*StreamAppsResponse, error)
+	grpc.ClientStream
+}
+
+type controllerStreamAppsClient struct {
+	grpc.ClientStream
+}
+
+func (x *controllerStreamAppsClient) Recv() (*StreamAppsResponse, error) {
+	m := new(StreamAppsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *controllerClient) StreamReleases(ctx context.Context, in *StreamReleasesRequest, opts ...grpc.CallOption) (Controller_StreamReleasesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Controller_serviceDesc.Streams[1], "/flynn.api.v1.Controller/StreamReleases", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &controllerStreamReleasesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Controller_StreamReleasesClient interface {
+	Recv() (*StreamReleasesResponse, error)
+	grpc.ClientStream
+}
+
+type controllerStreamReleasesClient struct {
+	grpc.ClientStream
+}
+
+func (x *controllerStreamReleasesClient) Recv() (*StreamReleasesResponse, error) {
+	m := new(StreamReleasesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *controllerClient) StreamScales(ctx context.Context, in *StreamScalesRequest, opts ...grpc.CallOption) (Controller_StreamScalesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Controller_serviceDesc.Streams[2], "/flynn.api.v1.Controller/StreamScales", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &controllerStreamScalesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Controller_StreamScalesClient interface {
+	Recv() (*StreamScalesResponse, error)
+	grpc.ClientStream
+}
+
+type controllerStreamScalesClient struct {
+	grpc.ClientStream
+}
+
+func (x *controllerStreamScalesClient) Recv() (*StreamScalesResponse, error) {
+	m := new(StreamScalesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *controllerClient) StreamDeployments(ctx context.Context, in *StreamDeploymentsRequest, opts ...grpc.CallOption) (Controller_StreamDeploymentsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Controller_serviceDesc.Streams[3], "/flynn.api.v1.Controller/StreamDeployments", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &controllerStreamDeploymentsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Controller_StreamDeploymentsClient interface {
+	Recv() (*StreamDeploymentsResponse, error)
+	grpc.ClientStream
+}
+
+type controllerStreamDeploymentsClient struct {
+	grpc.ClientStream
 }
 
 func (x *controllerStreamDeploymentsClient) Recv() (*StreamDeploymentsResponse, error) {
@@ -2797,288 +5232,1028 @@ func (x *controllerStreamDeploymentsClient) Recv() (*StreamDeploymentsResponse,
 	return m, nil
 }
 
-func (c *controllerClient) UpdateApp(ctx context.Context, in *UpdateAppRequest, opts ...grpc.CallOption) (*App, error) {
-	out := new(App)
-	err := c.cc.Invoke(ctx, "/flynn.api.v1.Controller/UpdateApp", in, out, opts...)
-	if err != nil {
+func (c *controllerClient) UpdateApp(ctx context.Context, in *UpdateAppRequest, opts ...grpc.CallOption) (*App, error) {
+	out := new(App)
+	err := c.cc.Invoke(ctx, "/flynn.api.v1.Controller/UpdateApp", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controllerClient) CreateScale(ctx context.Context, in *CreateScaleRequest, opts ...grpc.CallOption) (*ScaleRequest, error) {
+	out := new(ScaleRequest)
+	err := c.cc.Invoke(ctx, "/flynn.api.v1.Controller/CreateScale", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controllerClient) CreateRelease(ctx context.Context, in *CreateReleaseRequest, opts ...grpc.CallOption) (*Release, error) {
+	out := new(Release)
+	err := c.cc.Invoke(ctx, "/flynn.api.v1.Controller/CreateRelease", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controllerClient) CreateDeployment(ctx context.Context, in *CreateDeploymentRequest, opts ...grpc.CallOption) (Controller_CreateDeploymentClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Controller_serviceDesc.Streams[4], "/flynn.api.v1.Controller/CreateDeployment", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &controllerCreateDeploymentClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Controller_CreateDeploymentClient interface {
+	Recv() (*DeploymentEvent, error)
+	grpc.ClientStream
+}
+
+type controllerCreateDeploymentClient struct {
+	grpc.ClientStream
+}
+
+func (x *controllerCreateDeploymentClient) Recv() (*DeploymentEvent, error) {
+	m := new(DeploymentEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *controllerClient) StreamJobs(ctx context.Context, in *StreamJobsRequest, opts ...grpc.CallOption) (Controller_StreamJobsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Controller_serviceDesc.Streams[5], "/flynn.api.v1.Controller/StreamJobs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &controllerStreamJobsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Controller_StreamJobsClient interface {
+	Recv() (*StreamJobsResponse, error)
+	grpc.ClientStream
+}
+
+type controllerStreamJobsClient struct {
+	grpc.ClientStream
+}
+
+func (x *controllerStreamJobsClient) Recv() (*StreamJobsResponse, error) {
+	m := new(StreamJobsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *controllerClient) CreateJob(ctx context.Context, in *CreateJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, "/flynn.api.v1.Controller/CreateJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controllerClient) DeleteJob(ctx context.Context, in *DeleteJobRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/flynn.api.v1.Controller/DeleteJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controllerClient) StreamRoutes(ctx context.Context, in *StreamRoutesRequest, opts ...grpc.CallOption) (Controller_StreamRoutesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Controller_serviceDesc.Streams[6], "/flynn.api.v1.Controller/StreamRoutes", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &controllerStreamRoutesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Controller_StreamRoutesClient interface {
+	Recv() (*StreamRoutesResponse, error)
+	grpc.ClientStream
+}
+
+type controllerStreamRoutesClient struct {
+	grpc.ClientStream
+}
+
+func (x *controllerStreamRoutesClient) Recv() (*StreamRoutesResponse, error) {
+	m := new(StreamRoutesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *controllerClient) CreateRoute(ctx context.Context, in *CreateRouteRequest, opts ...grpc.CallOption) (*Route, error) {
+	out := new(Route)
+	err := c.cc.Invoke(ctx, "/flynn.api.v1.Controller/CreateRoute", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controllerClient) UpdateRoute(ctx context.Context, in *UpdateRouteRequest, opts ...grpc.CallOption) (*Route, error) {
+	out := new(Route)
+	err := c.cc.Invoke(ctx, "/flynn.api.v1.Controller/UpdateRoute", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controllerClient) DeleteRoute(ctx context.Context, in *DeleteRouteRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/flynn.api.v1.Controller/DeleteRoute", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controllerClient) StreamProviders(ctx context.Context, in *StreamProvidersRequest, opts ...grpc.CallOption) (Controller_StreamProvidersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Controller_serviceDesc.Streams[7], "/flynn.api.v1.Controller/StreamProviders", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &controllerStreamProvidersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Controller_StreamProvidersClient interface {
+	Recv() (*StreamProvidersResponse, error)
+	grpc.ClientStream
+}
+
+type controllerStreamProvidersClient struct {
+	grpc.ClientStream
+}
+
+func (x *controllerStreamProvidersClient) Recv() (*StreamProvidersResponse, error) {
+	m := new(StreamProvidersResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *controllerClient) CreateProvider(ctx context.Context, in *CreateProviderRequest, opts ...grpc.CallOption) (*Provider, error) {
+	out := new(Provider)
+	err := c.cc.Invoke(ctx, "/flynn.api.v1.Controller/CreateProvider", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controllerClient) StreamResources(ctx context.Context, in *StreamResourcesRequest, opts ...grpc.CallOption) (Controller_StreamResourcesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Controller_serviceDesc.Streams[8], "/flynn.api.v1.Controller/StreamResources", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &controllerStreamResourcesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Controller_StreamResourcesClient interface {
+	Recv() (*StreamResourcesResponse, error)
+	grpc.ClientStream
+}
+
+type controllerStreamResourcesClient struct {
+	grpc.ClientStream
+}
+
+func (x *controllerStreamResourcesClient) Recv() (*StreamResourcesResponse, error) {
+	m := new(StreamResourcesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *controllerClient) CreateResource(ctx context.Context, in *CreateResourceRequest, opts ...grpc.CallOption) (*Resource, error) {
+	out := new(Resource)
+	err := c.cc.Invoke(ctx, "/flynn.api.v1.Controller/CreateResource", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controllerClient) DeleteResource(ctx context.Context, in *DeleteResourceRequest, opts ...grpc.CallOption) (*Resource, error) {
+	out := new(Resource)
+	err := c.cc.Invoke(ctx, "/flynn.api.v1.Controller/DeleteResource", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controllerClient) StreamVolumes(ctx context.Context, in *StreamVolumesRequest, opts ...grpc.CallOption) (Controller_StreamVolumesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Controller_serviceDesc.Streams[9], "/flynn.api.v1.Controller/StreamVolumes", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &controllerStreamVolumesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Controller_StreamVolumesClient interface {
+	Recv() (*StreamVolumesResponse, error)
+	grpc.ClientStream
+}
+
+type controllerStreamVolumesClient struct {
+	grpc.ClientStream
+}
+
+func (x *controllerStreamVolumesClient) Recv() (*StreamVolumesResponse, error) {
+	m := new(StreamVolumesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *controllerClient) DecommissionVolume(ctx context.Context, in *DecommissionVolumeRequest, opts ...grpc.CallOption) (*Volume, error) {
+	out := new(Volume)
+	err := c.cc.Invoke(ctx, "/flynn.api.v1.Controller/DecommissionVolume", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controllerClient) StreamSinks(ctx context.Context, in *StreamSinksRequest, opts ...grpc.CallOption) (Controller_StreamSinksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Controller_serviceDesc.Streams[10], "/flynn.api.v1.Controller/StreamSinks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &controllerStreamSinksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Controller_StreamSinksClient interface {
+	Recv() (*StreamSinksResponse, error)
+	grpc.ClientStream
+}
+
+type controllerStreamSinksClient struct {
+	grpc.ClientStream
+}
+
+func (x *controllerStreamSinksClient) Recv() (*StreamSinksResponse, error) {
+	m := new(StreamSinksResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *controllerClient) CreateSink(ctx context.Context, in *CreateSinkRequest, opts ...grpc.CallOption) (*Sink, error) {
+	out := new(Sink)
+	err := c.cc.Invoke(ctx, "/flynn.api.v1.Controller/CreateSink", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controllerClient) DeleteSink(ctx context.Context, in *DeleteSinkRequest, opts ...grpc.CallOption) (*Sink, error) {
+	out := new(Sink)
+	err := c.cc.Invoke(ctx, "/flynn.api.v1.Controller/DeleteSink", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controllerClient) StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (Controller_StreamEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Controller_serviceDesc.Streams[11], "/flynn.api.v1.Controller/StreamEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &controllerStreamEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Controller_StreamEventsClient interface {
+	Recv() (*StreamEventsResponse, error)
+	grpc.ClientStream
+}
+
+type controllerStreamEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *controllerStreamEventsClient) Recv() (*StreamEventsResponse, error) {
+	m := new(StreamEventsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ControllerServer is the server API for Controller service.
+type ControllerServer interface {
+	Status(context.Context, *empty.Empty) (*StatusResponse, error)
+	StreamApps(*StreamAppsRequest, Controller_StreamAppsServer) error
+	StreamReleases(*StreamReleasesRequest, Controller_StreamReleasesServer) error
+	StreamScales(*StreamScalesRequest, Controller_StreamScalesServer) error
+	StreamDeployments(*StreamDeploymentsRequest, Controller_StreamDeploymentsServer) error
+	UpdateApp(context.Context, *UpdateAppRequest) (*App, error)
+	CreateScale(context.Context, *CreateScaleRequest) (*ScaleRequest, error)
+	CreateRelease(context.Context, *CreateReleaseRequest) (*Release, error)
+	CreateDeployment(*CreateDeploymentRequest, Controller_CreateDeploymentServer) error
+	StreamJobs(*StreamJobsRequest, Controller_StreamJobsServer) error
+	CreateJob(context.Context, *CreateJobRequest) (*Job, error)
+	DeleteJob(context.Context, *DeleteJobRequest) (*empty.Empty, error)
+	StreamRoutes(*StreamRoutesRequest, Controller_StreamRoutesServer) error
+	CreateRoute(context.Context, *CreateRouteRequest) (*Route, error)
+	UpdateRoute(context.Context, *UpdateRouteRequest) (*Route, error)
+	DeleteRoute(context.Context, *DeleteRouteRequest) (*empty.Empty, error)
+	StreamProviders(*StreamProvidersRequest, Controller_StreamProvidersServer) error
+	CreateProvider(context.Context, *CreateProviderRequest) (*Provider, error)
+	StreamResources(*StreamResourcesRequest, Controller_StreamResourcesServer) error
+	CreateResource(context.Context, *CreateResourceRequest) (*Resource, error)
+	DeleteResource(context.Context, *DeleteResourceRequest) (*Resource, error)
+	StreamVolumes(*StreamVolumesRequest, Controller_StreamVolumesServer) error
+	DecommissionVolume(context.Context, *DecommissionVolumeRequest) (*Volume, error)
+	StreamSinks(*StreamSinksRequest, Controller_StreamSinksServer) error
+	CreateSink(context.Context, *CreateSinkRequest) (*Sink, error)
+	DeleteSink(context.Context, *DeleteSinkRequest) (*Sink, error)
+	StreamEvents(*StreamEventsRequest, Controller_StreamEventsServer) error
+}
+
+// UnimplementedControllerServer can be embedded to have forward compatible implementations.
+type UnimplementedControllerServer struct {
+}
+
+func (*UnimplementedControllerServer) Status(ctx context.Context, req *empty.Empty) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (*UnimplementedControllerServer) StreamApps(req *StreamAppsRequest, srv Controller_StreamAppsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamApps not implemented")
+}
+func (*UnimplementedControllerServer) StreamReleases(req *StreamReleasesRequest, srv Controller_StreamReleasesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamReleases not implemented")
+}
+func (*UnimplementedControllerServer) StreamScales(req *StreamScalesRequest, srv Controller_StreamScalesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamScales not implemented")
+}
+func (*UnimplementedControllerServer) StreamDeployments(req *StreamDeploymentsRequest, srv Controller_StreamDeploymentsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamDeployments not implemented")
+}
+func (*UnimplementedControllerServer) UpdateApp(ctx context.Context, req *UpdateAppRequest) (*App, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateApp not implemented")
+}
+func (*UnimplementedControllerServer) CreateScale(ctx context.Context, req *CreateScaleRequest) (*ScaleRequest, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateScale not implemented")
+}
+func (*UnimplementedControllerServer) CreateRelease(ctx context.Context, req *CreateReleaseRequest) (*Release, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateRelease not implemented")
+}
+func (*UnimplementedControllerServer) CreateDeployment(req *CreateDeploymentRequest, srv Controller_CreateDeploymentServer) error {
+	return status.Errorf(codes.Unimplemented, "method CreateDeployment not implemented")
+}
+func (*UnimplementedControllerServer) StreamJobs(req *StreamJobsRequest, srv Controller_StreamJobsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamJobs not implemented")
+}
+func (*UnimplementedControllerServer) CreateJob(ctx context.Context, req *CreateJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateJob not implemented")
+}
+func (*UnimplementedControllerServer) DeleteJob(ctx context.Context, req *DeleteJobRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteJob not implemented")
+}
+func (*UnimplementedControllerServer) StreamRoutes(req *StreamRoutesRequest, srv Controller_StreamRoutesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamRoutes not implemented")
+}
+func (*UnimplementedControllerServer) CreateRoute(ctx context.Context, req *CreateRouteRequest) (*Route, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateRoute not implemented")
+}
+func (*UnimplementedControllerServer) UpdateRoute(ctx context.Context, req *UpdateRouteRequest) (*Route, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateRoute not implemented")
+}
+func (*UnimplementedControllerServer) DeleteRoute(ctx context.Context, req *DeleteRouteRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRoute not implemented")
+}
+func (*UnimplementedControllerServer) StreamProviders(req *StreamProvidersRequest, srv Controller_StreamProvidersServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamProviders not implemented")
+}
+func (*UnimplementedControllerServer) CreateProvider(ctx context.Context, req *CreateProviderRequest) (*Provider, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateProvider not implemented")
+}
+func (*UnimplementedControllerServer) StreamResources(req *StreamResourcesRequest, srv Controller_StreamResourcesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamResources not implemented")
+}
+func (*UnimplementedControllerServer) CreateResource(ctx context.Context, req *CreateResourceRequest) (*Resource, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateResource not implemented")
+}
+func (*UnimplementedControllerServer) DeleteResource(ctx context.Context, req *DeleteResourceRequest) (*Resource, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteResource not implemented")
+}
+func (*UnimplementedControllerServer) StreamVolumes(req *StreamVolumesRequest, srv Controller_StreamVolumesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamVolumes not implemented")
+}
+func (*UnimplementedControllerServer) DecommissionVolume(ctx context.Context, req *DecommissionVolumeRequest) (*Volume, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DecommissionVolume not implemented")
+}
+func (*UnimplementedControllerServer) StreamSinks(req *StreamSinksRequest, srv Controller_StreamSinksServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamSinks not implemented")
+}
+func (*UnimplementedControllerServer) CreateSink(ctx context.Context, req *CreateSinkRequest) (*Sink, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSink not implemented")
+}
+func (*UnimplementedControllerServer) DeleteSink(ctx context.Context, req *DeleteSinkRequest) (*Sink, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSink not implemented")
+}
+func (*UnimplementedControllerServer) StreamEvents(req *StreamEventsRequest, srv Controller_StreamEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEvents not implemented")
+}
+
+func RegisterControllerServer(s *grpc.Server, srv ControllerServer) {
+	s.RegisterService(&_Controller_serviceDesc, srv)
+}
+
+func _Controller_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(ControllerServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/flynn.api.v1.Controller/Status",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControllerServer).Status(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *controllerClient) CreateScale(ctx context.Context, in *CreateScaleRequest, opts ...grpc.CallOption) (*ScaleRequest, error) {
-	out := new(ScaleRequest)
-	err := c.cc.Invoke(ctx, "/flynn.api.v1.Controller/CreateScale", in, out, opts...)
-	if err != nil {
-		return nil, err
+func _Controller_StreamApps_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamAppsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
 	}
-	return out, nil
+	return srv.(ControllerServer).StreamApps(m, &controllerStreamAppsServer{stream})
 }
 
-func (c *controllerClient) CreateRelease(ctx context.Context, in *CreateReleaseRequest, opts ...grpc.CallOption) (*Release, error) {
-	out := new(Release)
-	err := c.cc.Invoke(ctx, "/flynn.api.v1.Controller/CreateRelease", in, out, opts...)
-	if err != nil {
-		return nil, err
+type Controller_StreamAppsServer interface {
+	Send(*StreamAppsResponse) error
+	grpc.ServerStream
+}
+
+type controllerStreamAppsServer struct {
+	grpc.ServerStream
+}
+
+func (x *controllerStreamAppsServer) Send(m *StreamAppsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Controller_StreamReleases_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamReleasesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
 	}
-	return out, nil
+	return srv.(ControllerServer).StreamReleases(m, &controllerStreamReleasesServer{stream})
 }
 
-func (c *controllerClient) CreateDeployment(ctx context.Context, in *CreateDeploymentRequest, opts ...grpc.CallOption) (Controller_CreateDeploymentClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_Controller_serviceDesc.Streams[4], "/flynn.api.v1.Controller/CreateDeployment", opts...)
-	if err != nil {
+type Controller_StreamReleasesServer interface {
+	Send(*StreamReleasesResponse) error
+	grpc.ServerStream
+}
+
+type controllerStreamReleasesServer struct {
+	grpc.ServerStream
+}
+
+func (x *controllerStreamReleasesServer) Send(m *StreamReleasesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Controller_StreamScales_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamScalesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ControllerServer).StreamScales(m, &controllerStreamScalesServer{stream})
+}
+
+type Controller_StreamScalesServer interface {
+	Send(*StreamScalesResponse) error
+	grpc.ServerStream
+}
+
+type controllerStreamScalesServer struct {
+	grpc.ServerStream
+}
+
+func (x *controllerStreamScalesServer) Send(m *StreamScalesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Controller_StreamDeployments_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamDeploymentsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ControllerServer).StreamDeployments(m, &controllerStreamDeploymentsServer{stream})
+}
+
+type Controller_StreamDeploymentsServer interface {
+	Send(*StreamDeploymentsResponse) error
+	grpc.ServerStream
+}
+
+type controllerStreamDeploymentsServer struct {
+	grpc.ServerStream
+}
+
+func (x *controllerStreamDeploymentsServer) Send(m *StreamDeploymentsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Controller_UpdateApp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAppRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	x := &controllerCreateDeploymentClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
+	if interceptor == nil {
+		return srv.(ControllerServer).UpdateApp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/flynn.api.v1.Controller/UpdateApp",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControllerServer).UpdateApp(ctx, req.(*UpdateAppRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Controller_CreateScale_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateScaleRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	if err := x.ClientStream.CloseSend(); err != nil {
+	if interceptor == nil {
+		return srv.(ControllerServer).CreateScale(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/flynn.api.v1.Controller/CreateScale",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControllerServer).CreateScale(ctx, req.(*CreateScaleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Controller_CreateRelease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateReleaseRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return x, nil
+	if interceptor == nil {
+		return srv.(ControllerServer).CreateRelease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/flynn.api.v1.Controller/CreateRelease",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControllerServer).CreateRelease(ctx, req.(*CreateReleaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-type Controller_CreateDeploymentClient interface {
-	Recv() (*DeploymentEvent, error)
-	grpc.ClientStream
+func _Controller_CreateDeployment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CreateDeploymentRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ControllerServer).CreateDeployment(m, &controllerCreateDeploymentServer{stream})
+}
+
+type Controller_CreateDeploymentServer interface {
+	Send(*DeploymentEvent) error
+	grpc.ServerStream
+}
+
+type controllerCreateDeploymentServer struct {
+	grpc.ServerStream
 }
 
-type controllerCreateDeploymentClient struct {
-	grpc.ClientStream
+func (x *controllerCreateDeploymentServer) Send(m *DeploymentEvent) error {
+	return x.ServerStream.SendMsg(m)
 }
 
-func (x *controllerCreateDeploymentClient) Recv() (*DeploymentEvent, error) {
-	m := new(DeploymentEvent)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
+func _Controller_StreamJobs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamJobsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
 	}
-	return m, nil
+	return srv.(ControllerServer).StreamJobs(m, &controllerStreamJobsServer{stream})
 }
 
-// ControllerServer is the server API for Controller service.
-type ControllerServer interface {
-	Status(context.Context, *empty.Empty) (*StatusResponse, error)
-	StreamApps(*StreamAppsRequest, Controller_StreamAppsServer) error
-	StreamReleases(*StreamReleasesRequest, Controller_StreamReleasesServer) error
-	StreamScales(*StreamScalesRequest, Controller_StreamScalesServer) error
-	StreamDeployments(*StreamDeploymentsRequest, Controller_StreamDeploymentsServer) error
-	UpdateApp(context.Context, *UpdateAppRequest) (*App, error)
-	CreateScale(context.Context, *CreateScaleRequest) (*ScaleRequest, error)
-	CreateRelease(context.Context, *CreateReleaseRequest) (*Release, error)
-	CreateDeployment(*CreateDeploymentRequest, Controller_CreateDeploymentServer) error
+type Controller_StreamJobsServer interface {
+	Send(*StreamJobsResponse) error
+	grpc.ServerStream
 }
 
-// UnimplementedControllerServer can be embedded to have forward compatible implementations.
-type UnimplementedControllerServer struct {
+type controllerStreamJobsServer struct {
+	grpc.ServerStream
 }
 
-func (*UnimplementedControllerServer) Status(ctx context.Context, req *empty.Empty) (*StatusResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
-}
-func (*UnimplementedControllerServer) StreamApps(req *StreamAppsRequest, srv Controller_StreamAppsServer) error {
-	return status.Errorf(codes.Unimplemented, "method StreamApps not implemented")
-}
-func (*UnimplementedControllerServer) StreamReleases(req *StreamReleasesRequest, srv Controller_StreamReleasesServer) error {
-	return status.Errorf(codes.Unimplemented, "method StreamReleases not implemented")
-}
-func (*UnimplementedControllerServer) StreamScales(req *StreamScalesRequest, srv Controller_StreamScalesServer) error {
-	return status.Errorf(codes.Unimplemented, "method StreamScales not implemented")
-}
-func (*UnimplementedControllerServer) StreamDeployments(req *StreamDeploymentsRequest, srv Controller_StreamDeploymentsServer) error {
-	return status.Errorf(codes.Unimplemented, "method StreamDeployments not implemented")
-}
-func (*UnimplementedControllerServer) UpdateApp(ctx context.Context, req *UpdateAppRequest) (*App, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method UpdateApp not implemented")
-}
-func (*UnimplementedControllerServer) CreateScale(ctx context.Context, req *CreateScaleRequest) (*ScaleRequest, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method CreateScale not implemented")
-}
-func (*UnimplementedControllerServer) CreateRelease(ctx context.Context, req *CreateReleaseRequest) (*Release, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method CreateRelease not implemented")
-}
-func (*UnimplementedControllerServer) CreateDeployment(req *CreateDeploymentRequest, srv Controller_CreateDeploymentServer) error {
-	return status.Errorf(codes.Unimplemented, "method CreateDeployment not implemented")
+func (x *controllerStreamJobsServer) Send(m *StreamJobsResponse) error {
+	return x.ServerStream.SendMsg(m)
 }
 
-func RegisterControllerServer(s *grpc.Server, srv ControllerServer) {
-	s.RegisterService(&_Controller_serviceDesc, srv)
+func _Controller_CreateJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControllerServer).CreateJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/flynn.api.v1.Controller/CreateJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControllerServer).CreateJob(ctx, req.(*CreateJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Controller_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(empty.Empty)
+func _Controller_DeleteJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteJobRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ControllerServer).Status(ctx, in)
+		return srv.(ControllerServer).DeleteJob(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/flynn.api.v1.Controller/Status",
+		FullMethod: "/flynn.api.v1.Controller/DeleteJob",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ControllerServer).Status(ctx, req.(*empty.Empty))
+		return srv.(ControllerServer).DeleteJob(ctx, req.(*DeleteJobRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Controller_StreamApps_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(StreamAppsRequest)
+func _Controller_StreamRoutes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamRoutesRequest)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
-	return srv.(ControllerServer).StreamApps(m, &controllerStreamAppsServer{stream})
+	return srv.(ControllerServer).StreamRoutes(m, &controllerStreamRoutesServer{stream})
 }
 
-type Controller_StreamAppsServer interface {
-	Send(*StreamAppsResponse) error
+type Controller_StreamRoutesServer interface {
+	Send(*StreamRoutesResponse) error
 	grpc.ServerStream
 }
 
-type controllerStreamAppsServer struct {
+type controllerStreamRoutesServer struct {
 	grpc.ServerStream
 }
 
-func (x *controllerStreamAppsServer) Send(m *StreamAppsResponse) error {
+func (x *controllerStreamRoutesServer) Send(m *StreamRoutesResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
-func _Controller_StreamReleases_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(StreamReleasesRequest)
+func _Controller_CreateRoute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRouteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControllerServer).CreateRoute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/flynn.api.v1.Controller/CreateRoute",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControllerServer).CreateRoute(ctx, req.(*CreateRouteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Controller_UpdateRoute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRouteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControllerServer).UpdateRoute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/flynn.api.v1.Controller/UpdateRoute",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControllerServer).UpdateRoute(ctx, req.(*UpdateRouteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Controller_DeleteRoute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRouteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControllerServer).DeleteRoute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/flynn.api.v1.Controller/DeleteRoute",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControllerServer).DeleteRoute(ctx, req.(*DeleteRouteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Controller_StreamProviders_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamProvidersRequest)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
-	return srv.(ControllerServer).StreamReleases(m, &controllerStreamReleasesServer{stream})
+	return srv.(ControllerServer).StreamProviders(m, &controllerStreamProvidersServer{stream})
 }
 
-type Controller_StreamReleasesServer interface {
-	Send(*StreamReleasesResponse) error
+type Controller_StreamProvidersServer interface {
+	Send(*StreamProvidersResponse) error
 	grpc.ServerStream
 }
 
-type controllerStreamReleasesServer struct {
+type controllerStreamProvidersServer struct {
 	grpc.ServerStream
 }
 
-func (x *controllerStreamReleasesServer) Send(m *StreamReleasesResponse) error {
+func (x *controllerStreamProvidersServer) Send(m *StreamProvidersResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
-func _Controller_StreamScales_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(StreamScalesRequest)
+func _Controller_CreateProvider_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateProviderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControllerServer).CreateProvider(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/flynn.api.v1.Controller/CreateProvider",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControllerServer).CreateProvider(ctx, req.(*CreateProviderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Controller_StreamResources_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamResourcesRequest)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
-	return srv.(ControllerServer).StreamScales(m, &controllerStreamScalesServer{stream})
+	return srv.(ControllerServer).StreamResources(m, &controllerStreamResourcesServer{stream})
 }
 
-type Controller_StreamScalesServer interface {
-	Send(*StreamScalesResponse) error
+type Controller_StreamResourcesServer interface {
+	Send(*StreamResourcesResponse) error
 	grpc.ServerStream
 }
 
-type controllerStreamScalesServer struct {
+type controllerStreamResourcesServer struct {
 	grpc.ServerStream
 }
 
-func (x *controllerStreamScalesServer) Send(m *StreamScalesResponse) error {
+func (x *controllerStreamResourcesServer) Send(m *StreamResourcesResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
-func _Controller_StreamDeployments_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(StreamDeploymentsRequest)
+func _Controller_CreateResource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateResourceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControllerServer).CreateResource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/flynn.api.v1.Controller/CreateResource",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControllerServer).CreateResource(ctx, req.(*CreateResourceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Controller_DeleteResource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteResourceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControllerServer).DeleteResource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/flynn.api.v1.Controller/DeleteResource",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControllerServer).DeleteResource(ctx, req.(*DeleteResourceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Controller_StreamVolumes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamVolumesRequest)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
-	return srv.(ControllerServer).StreamDeployments(m, &controllerStreamDeploymentsServer{stream})
+	return srv.(ControllerServer).StreamVolumes(m, &controllerStreamVolumesServer{stream})
 }
 
-type Controller_StreamDeploymentsServer interface {
-	Send(*StreamDeploymentsResponse) error
+type Controller_StreamVolumesServer interface {
+	Send(*StreamVolumesResponse) error
 	grpc.ServerStream
 }
 
-type controllerStreamDeploymentsServer struct {
+type controllerStreamVolumesServer struct {
 	grpc.ServerStream
 }
 
-func (x *controllerStreamDeploymentsServer) Send(m *StreamDeploymentsResponse) error {
+func (x *controllerStreamVolumesServer) Send(m *StreamVolumesResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
-func _Controller_UpdateApp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UpdateAppRequest)
+func _Controller_DecommissionVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DecommissionVolumeRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ControllerServer).UpdateApp(ctx, in)
+		return srv.(ControllerServer).DecommissionVolume(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/flynn.api.v1.Controller/UpdateApp",
+		FullMethod: "/flynn.api.v1.Controller/DecommissionVolume",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ControllerServer).UpdateApp(ctx, req.(*UpdateAppRequest))
+		return srv.(ControllerServer).DecommissionVolume(ctx, req.(*DecommissionVolumeRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Controller_CreateScale_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateScaleRequest)
+func _Controller_StreamSinks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamSinksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ControllerServer).StreamSinks(m, &controllerStreamSinksServer{stream})
+}
+
+type Controller_StreamSinksServer interface {
+	Send(*StreamSinksResponse) error
+	grpc.ServerStream
+}
+
+type controllerStreamSinksServer struct {
+	grpc.ServerStream
+}
+
+func (x *controllerStreamSinksServer) Send(m *StreamSinksResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Controller_CreateSink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSinkRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ControllerServer).CreateScale(ctx, in)
+		return srv.(ControllerServer).CreateSink(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/flynn.api.v1.Controller/CreateScale",
+		FullMethod: "/flynn.api.v1.Controller/CreateSink",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ControllerServer).CreateScale(ctx, req.(*CreateScaleRequest))
+		return srv.(ControllerServer).CreateSink(ctx, req.(*CreateSinkRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Controller_CreateRelease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateReleaseRequest)
+func _Controller_DeleteSink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSinkRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ControllerServer).CreateRelease(ctx, in)
+		return srv.(ControllerServer).DeleteSink(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/flynn.api.v1.Controller/CreateRelease",
+		FullMethod: "/flynn.api.v1.Controller/DeleteSink",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ControllerServer).CreateRelease(ctx, req.(*CreateReleaseRequest))
+		return srv.(ControllerServer).DeleteSink(ctx, req.(*DeleteSinkRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Controller_CreateDeployment_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(CreateDeploymentRequest)
+func _Controller_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamEventsRequest)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
-	return srv.(ControllerServer).CreateDeployment(m, &controllerCreateDeploymentServer{stream})
+	return srv.(ControllerServer).StreamEvents(m, &controllerStreamEventsServer{stream})
 }
 
-type Controller_CreateDeploymentServer interface {
-	Send(*DeploymentEvent) error
+type Controller_StreamEventsServer interface {
+	Send(*StreamEventsResponse) error
 	grpc.ServerStream
 }
 
-type controllerCreateDeploymentServer struct {
+type controllerStreamEventsServer struct {
 	grpc.ServerStream
 }
 
-func (x *controllerCreateDeploymentServer) Send(m *DeploymentEvent) error {
+func (x *controllerStreamEventsServer) Send(m *StreamEventsResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
@@ -3102,6 +6277,50 @@ var _Controller_serviceDesc = grpc.ServiceDesc{
 			MethodName: "CreateRelease",
 			Handler:    _Controller_CreateRelease_Handler,
 		},
+		{
+			MethodName: "CreateJob",
+			Handler:    _Controller_CreateJob_Handler,
+		},
+		{
+			MethodName: "DeleteJob",
+			Handler:    _Controller_DeleteJob_Handler,
+		},
+		{
+			MethodName: "CreateRoute",
+			Handler:    _Controller_CreateRoute_Handler,
+		},
+		{
+			MethodName: "UpdateRoute",
+			Handler:    _Controller_UpdateRoute_Handler,
+		},
+		{
+			MethodName: "DeleteRoute",
+			Handler:    _Controller_DeleteRoute_Handler,
+		},
+		{
+			MethodName: "CreateProvider",
+			Handler:    _Controller_CreateProvider_Handler,
+		},
+		{
+			MethodName: "CreateResource",
+			Handler:    _Controller_CreateResource_Handler,
+		},
+		{
+			MethodName: "DeleteResource",
+			Handler:    _Controller_DeleteResource_Handler,
+		},
+		{
+			MethodName: "DecommissionVolume",
+			Handler:    _Controller_DecommissionVolume_Handler,
+		},
+		{
+			MethodName: "CreateSink",
+			Handler:    _Controller_CreateSink_Handler,
+		},
+		{
+			MethodName: "DeleteSink",
+			Handler:    _Controller_DeleteSink_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -3129,6 +6348,41 @@ var _Controller_serviceDesc = grpc.ServiceDesc{
 			Handler:       _Controller_CreateDeployment_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "StreamJobs",
+			Handler:       _Controller_StreamJobs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamRoutes",
+			Handler:       _Controller_StreamRoutes_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamProviders",
+			Handler:       _Controller_StreamProviders_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamResources",
+			Handler:       _Controller_StreamResources_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamVolumes",
+			Handler:       _Controller_StreamVolumes_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamSinks",
+			Handler:       _Controller_StreamSinks_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamEvents",
+			Handler:       _Controller_StreamEvents_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "controller.proto",
 }
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/pkg/httphelper"
+	"golang.org/x/net/context"
+)
+
+// List audit records, optionally filtered by app, key ID and time
+func (c *controllerAPI) GetAuditLog(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	var opts ct.ListAuditOptions
+	if appID := req.FormValue("app_id"); appID != "" {
+		data, err := c.appRepo.Get(appID)
+		if err != nil {
+			respondWithError(w, err)
+			return
+		}
+		opts.AppID = data.(*ct.App).ID
+	}
+	opts.KeyID = req.FormValue("key_id")
+	for _, f := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"since", &opts.Since},
+		{"before", &opts.Before},
+	} {
+		if v := req.FormValue(f.name); v != "" {
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				respondWithError(w, ct.ValidationError{Field: f.name, Message: "must be an RFC 3339 timestamp"})
+				return
+			}
+			*f.dst = &t
+		}
+	}
+	if v := req.FormValue("count"); v != "" {
+		count, err := strconv.Atoi(v)
+		if err != nil || count < 0 {
+			respondWithError(w, ct.ValidationError{Field: "count", Message: "is invalid"})
+			return
+		}
+		opts.Count = count
+	}
+
+	list, err := c.auditRepo.List(opts)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, list)
+}
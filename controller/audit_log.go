@@ -12,6 +12,7 @@ import (
 	"github.com/flynn/flynn/pkg/httphelper"
 	"github.com/flynn/flynn/router/types"
 	log "github.com/inconshreveable/log15"
+	"golang.org/x/net/context"
 )
 
 const maxAuditRequestBodySize = 1000000
@@ -62,6 +63,11 @@ func maybeRedactBody(req *http.Request, buf *bytes.Buffer) {
 			return
 		}
 		redactEnv(release.Env)
+		for _, name := range release.Secrets {
+			if _, ok := release.Env[name]; ok {
+				release.Env[name] = redactedPlaceholder
+			}
+		}
 		for _, proc := range release.Processes {
 			redactEnv(proc.Env)
 		}
@@ -88,6 +94,33 @@ func maybeRedactBody(req *http.Request, buf *bytes.Buffer) {
 			route.LegacyTLSKey = redactedPlaceholder
 		}
 		data = route
+	} else if req.Method == "POST" && req.URL.Path == "/webhooks" {
+		webhook := &ct.Webhook{}
+		if err := json.NewDecoder(buf).Decode(webhook); err != nil {
+			return
+		}
+		if webhook.Secret != "" {
+			webhook.Secret = redactedPlaceholder
+		}
+		data = webhook
+	} else if (req.Method == "POST" && req.URL.Path == "/env-groups") || (req.Method == "PUT" && strings.HasPrefix(req.URL.Path, "/env-groups/")) {
+		// env groups are typically used to share credentials, so
+		// redact all of their values
+		group := &ct.EnvGroup{}
+		if err := json.NewDecoder(buf).Decode(group); err != nil {
+			return
+		}
+		for k := range group.Env {
+			group.Env[k] = redactedPlaceholder
+		}
+		data = group
+	} else if strings.HasSuffix(req.URL.Path, "/manifest") || strings.HasSuffix(req.URL.Path, "/manifest/dry-run") {
+		manifest := &ct.AppManifest{}
+		if err := json.NewDecoder(buf).Decode(manifest); err != nil {
+			return
+		}
+		redactEnv(manifest.Env)
+		data = manifest
 	}
 	if data != nil {
 		buf.Reset()
@@ -107,9 +140,76 @@ func (l *limitedBodyReader) Read(p []byte) (n int, err error) {
 	return
 }
 
-func auditLoggerFn(handler http.Handler, logger log.Logger, clientIP string, rw *httphelper.ResponseWriter, req *http.Request) {
+// ctxKeyAudit is the request context key of the auditRequest of requests
+// handled by auditLogger
+const ctxKeyAudit = "audit"
+
+// auditRequest is the authenticated identity and app of a request, which
+// muxHandler sets for auditLogger
+type auditRequest struct {
+	KeyID string
+	AppID string
+}
+
+// auditAppID returns the ID of the app an audited request accesses
+func (c *controllerAPI) auditAppID(req *http.Request) string {
+	// the permission contains the requested app reference if the app
+	// doesn't exist, so check it does
+	if perm := c.requestPermission(req, true); len(perm.AppIDs) > 0 {
+		if data, err := c.appRepo.Get(perm.AppIDs[0]); err == nil {
+			return data.(*ct.App).ID
+		}
+	}
+	return ""
+}
+
+// auditLogger returns a request logger which logs requests along with their
+// redacted bodies, and persists audit records of requests which modify the
+// cluster
+func (c *controllerAPI) auditLogger(handler http.Handler, logger log.Logger, clientIP string, rw *httphelper.ResponseWriter, req *http.Request) {
 	start := time.Now()
 	logger.Info("request started", "method", req.Method, "path", req.URL.Path, "client_ip", clientIP)
+
+	// the key ID and app are set by muxHandler once the request has been
+	// authenticated
+	audit := &auditRequest{}
+	req = req.WithContext(context.WithValue(req.Context(), ctxKeyAudit, audit))
+
 	bodyBuf := handleRequestWithAuditBodyBuffer(handler, rw, req)
-	logger.Info("request completed", "status", rw.Status(), "duration", time.Since(start), "method", req.Method, "path", req.URL.Path, "client_ip", clientIP, "key_id", req.Header.Get("Flynn-Auth-Key-ID"), "user_agent", req.Header.Get("User-Agent"), "body", bodyBuf.String())
+	keyID := audit.KeyID
+	logger.Info("request completed", "status", rw.Status(), "duration", time.Since(start), "method", req.Method, "path", req.URL.Path, "client_ip", clientIP, "key_id", keyID, "user_agent", req.Header.Get("User-Agent"), "body", bodyBuf.String())
+
+	status := rw.Status()
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if !isAuditedRequest(req) || status == http.StatusUnauthorized {
+		return
+	}
+	record := &ct.AuditRecord{
+		KeyID:     keyID,
+		Method:    req.Method,
+		Path:      req.URL.Path,
+		AppID:     audit.AppID,
+		Status:    status,
+		ClientIP:  clientIP,
+		UserAgent: req.Header.Get("User-Agent"),
+	}
+	if bodyBuf != nil {
+		record.Body = strings.TrimSpace(bodyBuf.String())
+	}
+	if err := c.auditRepo.Add(record); err != nil {
+		logger.Error("error persisting audit record", "err", err)
+	}
+}
+
+// isAuditedRequest returns whether an audit record should be persisted for
+// the request, which is the case for requests which may modify the cluster
+// (gRPC requests are excluded as they are not buffered)
+func isAuditedRequest(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS":
+		return false
+	}
+	return !strings.HasPrefix(req.Header.Get("Content-Type"), "application/grpc")
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	controller "github.com/flynn/flynn/controller/client"
+	ct "github.com/flynn/flynn/controller/types"
+	. "github.com/flynn/go-check"
+)
+
+func (s *S) TestAuditLog(c *C) {
+	hc := s.hc
+	hc.auditLog = true
+	handler, _, _ := appHandler(hc)
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+	client, err := controller.NewClient(srv.URL, authKey)
+	c.Assert(err, IsNil)
+
+	// requests made with a token are recorded against the token name
+	token := &ct.Token{Name: "audit-deploy", Role: ct.TokenRoleDeploy}
+	c.Assert(client.CreateToken(token), IsNil)
+	tokenClient, err := controller.NewClient(srv.URL, token.Key)
+	c.Assert(err, IsNil)
+
+	start := time.Now()
+	app := &ct.App{Name: "audit-app"}
+	c.Assert(client.CreateApp(app), IsNil)
+	c.Assert(tokenClient.UpdateApp(&ct.App{ID: app.ID, Meta: map[string]string{"foo": "bar"}}), IsNil)
+
+	// reads are not recorded
+	_, err = tokenClient.GetApp(app.ID)
+	c.Assert(err, IsNil)
+
+	records, err := client.ListAuditLog(ct.ListAuditOptions{AppID: app.ID})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].KeyID, Equals, token.Name)
+	c.Assert(records[0].Method, Equals, "POST")
+	c.Assert(records[0].Path, Equals, "/apps/"+app.ID)
+	c.Assert(records[0].Status, Equals, 200)
+	c.Assert(records[0].Body, Matches, `.*"foo":"bar".*`)
+	c.Assert(records[0].CreatedAt, NotNil)
+
+	// secrets in request bodies are redacted
+	c.Assert(tokenClient.CreateRelease(app.ID, &ct.Release{Env: map[string]string{"SECRET_KEY": "s3cr3t"}}), IsNil)
+	records, err = client.ListAuditLog(ct.ListAuditOptions{KeyID: token.Name, Since: &start})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 2)
+	c.Assert(records[0].Path, Equals, "/releases")
+	c.Assert(records[0].Body, Not(Matches), `.*s3cr3t.*`)
+	c.Assert(records[0].Body, Matches, `.*\[redacted\].*`)
+	c.Assert(records[1].AppID, Equals, app.ID)
+
+	// webhook secrets are redacted
+	c.Assert(client.CreateWebhook(&ct.Webhook{URL: "http://example.com/hook", Secret: "hooks3cr3t"}), IsNil)
+	records, err = client.ListAuditLog(ct.ListAuditOptions{Since: &start})
+	c.Assert(err, IsNil)
+	c.Assert(records[0].Path, Equals, "/webhooks")
+	c.Assert(records[0].Body, Not(Matches), `.*hooks3cr3t.*`)
+	c.Assert(records[0].Body, Matches, `.*"secret":"\[redacted\]".*`)
+
+	// env group values are redacted
+	c.Assert(client.CreateEnvGroup(&ct.EnvGroup{Name: "audit-env-group", Env: map[string]string{"DATABASE_HOST": "db.internal"}}), IsNil)
+	records, err = client.ListAuditLog(ct.ListAuditOptions{Since: &start})
+	c.Assert(err, IsNil)
+	c.Assert(records[0].Path, Equals, "/env-groups")
+	c.Assert(records[0].Body, Not(Matches), `.*db\.internal.*`)
+	c.Assert(records[0].Body, Matches, `.*"DATABASE_HOST":"\[redacted\]".*`)
+
+	records, err = client.ListAuditLog(ct.ListAuditOptions{Before: &start, KeyID: token.Name})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 0)
+
+	// a key ID sent by the client is replaced with the authenticated one
+	req, err := http.NewRequest("POST", srv.URL+"/apps/"+app.ID, strings.NewReader(`{"meta":{"foo":"baz"}}`))
+	c.Assert(err, IsNil)
+	req.SetBasicAuth("", token.Key)
+	req.Header.Set("Flynn-Auth-Key-ID", "forged")
+	res, err := http.DefaultClient.Do(req)
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(res.StatusCode, Equals, 200)
+	records, err = client.ListAuditLog(ct.ListAuditOptions{KeyID: "forged"})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 0)
+	records, err = client.ListAuditLog(ct.ListAuditOptions{KeyID: token.Name, Since: &start})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 3)
+
+	// the audit log is not readable by restricted tokens
+	_, err = tokenClient.ListAuditLog(ct.ListAuditOptions{})
+	c.Assert(err, NotNil)
+
+	// nor are audit events
+	events, err := client.ListEvents(ct.ListEventsOptions{AppID: app.ID, ObjectTypes: []ct.EventType{ct.EventTypeAudit}})
+	c.Assert(err, IsNil)
+	c.Assert(len(events) > 0, Equals, true)
+	_, err = tokenClient.GetEvent(events[0].ID)
+	c.Assert(err, Equals, controller.ErrNotFound)
+	events, err = tokenClient.ListEvents(ct.ListEventsOptions{AppID: app.ID, ObjectTypes: []ct.EventType{ct.EventTypeAudit}})
+	c.Assert(err, IsNil)
+	c.Assert(events, HasLen, 0)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/flynn/flynn/controller/schema"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/pkg/ctxhelper"
+	"github.com/flynn/flynn/pkg/httphelper"
+	"golang.org/x/net/context"
+)
+
+func (c *controllerAPI) PutAutoscalePolicy(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	var policy ct.AutoscalePolicy
+	if err := httphelper.DecodeJSON(req, &policy); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	if err := schema.Validate(&policy); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	params, _ := ctxhelper.ParamsFromContext(ctx)
+	app := c.getApp(ctx)
+	policy.AppID = app.ID
+	policy.ProcessType = params.ByName("process_type")
+
+	// check the process type exists in the current release (if the app
+	// has one) to catch typos, whilst still allowing policies to be
+	// created for process types which will be added by future releases
+	release, err := c.appRepo.GetRelease(app.ID)
+	if err != nil && err != ErrNotFound {
+		respondWithError(w, err)
+		return
+	} else if err == nil {
+		if _, ok := release.Processes[policy.ProcessType]; !ok {
+			respondWithError(w, ct.ValidationError{
+				Field:   "process_type",
+				Message: fmt.Sprintf("%q does not exist in the current release", policy.ProcessType),
+			})
+			return
+		}
+	}
+
+	if err := c.autoscaleRepo.Put(&policy); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, &policy)
+}
+
+func (c *controllerAPI) ListAutoscalePolicies(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	list, err := c.autoscaleRepo.List(c.getApp(ctx).ID)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, list)
+}
+
+func (c *controllerAPI) GetAutoscalePolicy(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	params, _ := ctxhelper.ParamsFromContext(ctx)
+	policy, err := c.autoscaleRepo.Get(c.getApp(ctx).ID, params.ByName("process_type"))
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, policy)
+}
+
+func (c *controllerAPI) DeleteAutoscalePolicy(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	params, _ := ctxhelper.ParamsFromContext(ctx)
+	policy, err := c.autoscaleRepo.Remove(c.getApp(ctx).ID, params.ByName("process_type"))
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, policy)
+}
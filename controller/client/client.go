@@ -10,6 +10,7 @@ import (
 
 	v1controller "github.com/flynn/flynn/controller/client/v1"
 	ct "github.com/flynn/flynn/controller/types"
+	host "github.com/flynn/flynn/host/types"
 	logagg "github.com/flynn/flynn/logaggregator/types"
 	"github.com/flynn/flynn/pkg/httpclient"
 	"github.com/flynn/flynn/pkg/httphelper"
@@ -47,6 +48,7 @@ type Client interface {
 	DeleteJob(appID, jobID string) error
 	SetAppRelease(appID, releaseID string) error
 	GetAppRelease(appID string) (*ct.Release, error)
+	GetAppReleaseWithSecrets(appID string) (*ct.Release, error)
 	RouteList() ([]*router.Route, error)
 	AppRouteList(appID string) ([]*router.Route, error)
 	GetRoute(appID string, routeID string) (*router.Route, error)
@@ -66,9 +68,15 @@ type Client interface {
 	GetDeployment(deploymentID string) (*ct.Deployment, error)
 	CreateDeployment(appID, releaseID string) (*ct.Deployment, error)
 	DeploymentList(appID string) ([]*ct.Deployment, error)
+	PauseDeployment(deploymentID string) (*ct.Deployment, error)
+	ResumeDeployment(deploymentID string) (*ct.Deployment, error)
+	AbortDeployment(deploymentID string) (*ct.Deployment, error)
+	ApproveDeployment(deploymentID, comment string) (*ct.Deployment, error)
+	RejectDeployment(deploymentID, comment string) (*ct.Deployment, error)
 	StreamDeployment(d *ct.Deployment, output chan *ct.DeploymentEvent) (stream.Stream, error)
 	DeployAppRelease(appID, releaseID string, stopWait <-chan struct{}) error
 	ScaleAppRelease(appID, releaseID string, opts ct.ScaleOptions) error
+	ScaleAppReleaseDryRun(appID, releaseID string, opts ct.ScaleOptions) (*ct.SchedulerDryRunResult, error)
 	StreamJobEvents(appID string, output chan *ct.Job) (stream.Stream, error)
 	WatchJobEvents(appID, releaseID string) (ct.JobWatcher, error)
 	StreamEvents(opts ct.StreamEventsOptions, output chan *ct.Event) (stream.Stream, error)
@@ -80,6 +88,7 @@ type Client interface {
 	GetJob(appID, jobID string) (*ct.Job, error)
 	JobList(appID string) ([]*ct.Job, error)
 	JobListActive() ([]*ct.Job, error)
+	JobListSince(since time.Time) ([]*ct.Job, error)
 	AppList() ([]*ct.App, error)
 	ArtifactList() ([]*ct.Artifact, error)
 	ReleaseList() ([]*ct.Release, error)
@@ -101,6 +110,38 @@ type Client interface {
 	DeleteSink(sinkID string) (*ct.Sink, error)
 	ListSinks() ([]*ct.Sink, error)
 	StreamSinks(since *time.Time, output chan *ct.Sink) (stream.Stream, error)
+	CreateSchedule(schedule *ct.Schedule) error
+	GetSchedule(appID, scheduleID string) (*ct.Schedule, error)
+	DeleteSchedule(appID, scheduleID string) (*ct.Schedule, error)
+	ScheduleList(appID string) ([]*ct.Schedule, error)
+	ScheduleRunList(appID, scheduleID string) ([]*ct.ScheduleRun, error)
+	PutAutoscalePolicy(policy *ct.AutoscalePolicy) error
+	GetAutoscalePolicy(appID, processType string) (*ct.AutoscalePolicy, error)
+	DeleteAutoscalePolicy(appID, processType string) (*ct.AutoscalePolicy, error)
+	AutoscalePolicyList(appID string) ([]*ct.AutoscalePolicy, error)
+	CreateToken(token *ct.Token) error
+	DeleteToken(tokenID string) (*ct.Token, error)
+	ListTokens() ([]*ct.Token, error)
+	ListAuditLog(opts ct.ListAuditOptions) ([]*ct.AuditRecord, error)
+	CreateWebhook(webhook *ct.Webhook) error
+	GetWebhook(webhookID string) (*ct.Webhook, error)
+	DeleteWebhook(webhookID string) (*ct.Webhook, error)
+	ListWebhooks() ([]*ct.Webhook, error)
+	ListWebhookDeliveries(webhookID string, count int) ([]*ct.WebhookDelivery, error)
+	CreateEnvGroup(group *ct.EnvGroup) error
+	GetEnvGroup(groupID string) (*ct.EnvGroup, error)
+	UpdateEnvGroup(group *ct.EnvGroup) (*ct.EnvGroupUpdate, error)
+	DeleteEnvGroup(groupID string) (*ct.EnvGroupUpdate, error)
+	ListEnvGroups() ([]*ct.EnvGroup, error)
+	AppEnvGroupList(appID string) ([]*ct.EnvGroup, error)
+	AttachEnvGroup(appID, groupID string) (*ct.EnvGroupUpdate, error)
+	DetachEnvGroup(appID, groupID string) (*ct.EnvGroupUpdate, error)
+	GetAppQuota(appID string) (*ct.AppQuota, error)
+	UpdateAppQuota(quota *ct.AppQuota) error
+	ManifestDryRun(appID string, manifest *ct.AppManifest) (*ct.ManifestDiff, error)
+	ApplyManifest(appID string, manifest *ct.AppManifest) (*ct.ManifestDiff, error)
+	PutHostDrain(status *host.DrainStatus) error
+	HostDrainList() ([]*host.DrainStatus, error)
 }
 
 type Config struct {
@@ -0,0 +1,1062 @@
+// Package grpccontroller provides a client for the gRPC controller API.
+package grpccontroller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/flynn/flynn/controller/api"
+	controller "github.com/flynn/flynn/controller/client"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/pkg/httphelper"
+	flynnstatus "github.com/flynn/flynn/pkg/status"
+	"github.com/flynn/flynn/pkg/stream"
+	router "github.com/flynn/flynn/router/types"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/genproto/protobuf/field_mask"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Client is a client for the gRPC controller API, which implements
+// controller.Client by using the gRPC API for apps, releases, scales,
+// deployments, jobs, routes, providers, resources, volumes, sinks, events
+// and the controller's status, and the given fallback client for everything
+// else.
+type Client struct {
+	controller.Client
+
+	grpc api.ControllerClient
+	key  string
+}
+
+var _ controller.Client = (*Client)(nil)
+
+// ErrNoFallback is returned by methods which are not part of the gRPC API
+// when the client was created without a fallback client
+var ErrNoFallback = errors.New("grpccontroller: not supported by the gRPC API and no fallback client given")
+
+// NewClient returns a client which uses the given connection to make gRPC
+// requests authenticated with key, and fallback to make all other requests.
+//
+// fallback may be nil, in which case requests which are not part of the
+// gRPC API return ErrNoFallback.
+func NewClient(conn *grpc.ClientConn, key string, fallback controller.Client) *Client {
+	if fallback == nil {
+		fallback = noFallback{}
+	}
+	return &Client{
+		Client: fallback,
+		grpc:   api.NewControllerClient(conn),
+		key:    key,
+	}
+}
+
+func (c *Client) SetKey(newKey string) {
+	c.key = newKey
+	if c.Client != nil {
+		c.Client.SetKey(newKey)
+	}
+}
+
+func (c *Client) context() context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "auth-key", c.key)
+}
+
+// convertError converts gRPC status errors into the errors returned by the
+// JSON API client
+func convertError(err error) error {
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch s.Code() {
+	case codes.NotFound:
+		return controller.ErrNotFound
+	case codes.InvalidArgument:
+		return httphelper.JSONError{Code: httphelper.ValidationErrorCode, Message: s.Message()}
+	case codes.AlreadyExists:
+		return httphelper.JSONError{Code: httphelper.ConflictErrorCode, Message: s.Message()}
+	case codes.PermissionDenied:
+		return httphelper.JSONError{Code: httphelper.ForbiddenErrorCode, Message: s.Message()}
+	case codes.Unauthenticated:
+		return httphelper.JSONError{Code: httphelper.UnauthorizedErrorCode, Message: s.Message()}
+	default:
+		return err
+	}
+}
+
+// newStream returns a stream which calls recv until it returns an error,
+// with the stream's context being cancelled and done being called once
+// that happens (or once the stream is closed).
+//
+// recv should return early if stopCh is closed when sending to its output.
+func newStream(ctx context.Context, cancel context.CancelFunc, recv func(stopCh <-chan struct{}) error, done func()) stream.Stream {
+	s := stream.New()
+	go func() {
+		select {
+		case <-s.StopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	go func() {
+		defer done()
+		defer cancel()
+		for {
+			if err := recv(s.StopCh); err != nil {
+				if err != io.EOF && status.Code(err) != codes.Canceled {
+					s.Error = convertError(err)
+				}
+				return
+			}
+		}
+	}()
+	return s
+}
+
+// Status returns the controller's status
+func (c *Client) Status() (*flynnstatus.Status, error) {
+	res, err := c.grpc.Status(c.context(), &empty.Empty{})
+	if err != nil {
+		return nil, convertError(err)
+	}
+	s := &flynnstatus.Status{Status: flynnstatus.CodeHealthy, Version: res.Version}
+	if res.Status == api.StatusResponse_UNHEALTHY {
+		s.Status = flynnstatus.CodeUnhealthy
+	}
+	if len(res.Detail) > 0 {
+		detail := json.RawMessage(res.Detail)
+		s.Detail = &detail
+	}
+	return s, nil
+}
+
+func (c *Client) listApps(nameFilters ...string) ([]*ct.App, error) {
+	var apps []*ct.App
+	req := &api.StreamAppsRequest{NameFilters: nameFilters}
+	for {
+		stream, err := c.grpc.StreamApps(c.context(), req)
+		if err != nil {
+			return nil, convertError(err)
+		}
+		res, err := stream.Recv()
+		if err != nil {
+			return nil, convertError(err)
+		}
+		for _, app := range res.Apps {
+			apps = append(apps, app.ControllerType())
+		}
+		if res.NextPageToken == "" {
+			return apps, nil
+		}
+		req.PageToken = res.NextPageToken
+	}
+}
+
+// AppList returns a list of all apps.
+func (c *Client) AppList() ([]*ct.App, error) {
+	return c.listApps()
+}
+
+// GetApp returns details for the specified app, which may be given by name
+// or ID.
+func (c *Client) GetApp(appID string) (*ct.App, error) {
+	apps, err := c.listApps(path.Join("apps", appID))
+	if err != nil {
+		return nil, err
+	}
+	if len(apps) > 0 {
+		return apps[0], nil
+	}
+	// the gRPC API only looks up apps by ID, so look for the name in
+	// the list of all apps
+	apps, err = c.listApps()
+	if err != nil {
+		return nil, err
+	}
+	for _, app := range apps {
+		if app.Name == appID {
+			return app, nil
+		}
+	}
+	return nil, controller.ErrNotFound
+}
+
+func (c *Client) updateApp(app *ct.App, paths ...string) error {
+	if app.ID == "" {
+		return errors.New("controller: missing id")
+	}
+	res, err := c.grpc.UpdateApp(c.context(), &api.UpdateAppRequest{
+		App:        api.NewApp(app),
+		UpdateMask: &field_mask.FieldMask{Paths: paths},
+	})
+	if err != nil {
+		return convertError(err)
+	}
+	*app = *res.ControllerType()
+	return nil
+}
+
+// UpdateApp updates the meta, strategy and deploy timeout of the app,
+// leaving those which are not set unchanged.
+func (c *Client) UpdateApp(app *ct.App) error {
+	var paths []string
+	if len(app.Meta) > 0 {
+		paths = append(paths, "labels")
+	}
+	if app.Strategy != "" {
+		paths = append(paths, "strategy")
+	}
+	if app.DeployTimeout > 0 {
+		paths = append(paths, "deploy_timeout")
+	}
+	if len(paths) == 0 {
+		if app.ID == "" {
+			return errors.New("controller: missing id")
+		}
+		current, err := c.GetApp(app.ID)
+		if err != nil {
+			return err
+		}
+		*app = *current
+		return nil
+	}
+	return c.updateApp(app, paths...)
+}
+
+// UpdateAppMeta updates the meta using app.ID, allowing empty meta to be set explicitly.
+func (c *Client) UpdateAppMeta(app *ct.App) error {
+	return c.updateApp(app, "labels")
+}
+
+func (c *Client) listReleases(nameFilters ...string) ([]*ct.Release, error) {
+	var releases []*ct.Release
+	req := &api.StreamReleasesRequest{NameFilters: nameFilters}
+	for {
+		stream, err := c.grpc.StreamReleases(c.context(), req)
+		if err != nil {
+			return nil, convertError(err)
+		}
+		res, err := stream.Recv()
+		if err != nil {
+			return nil, convertError(err)
+		}
+		for _, release := range res.Releases {
+			releases = append(releases, release.ControllerType())
+		}
+		if res.NextPageToken == "" {
+			return releases, nil
+		}
+		req.PageToken = res.NextPageToken
+	}
+}
+
+// ReleaseList returns a list of all releases.
+func (c *Client) ReleaseList() ([]*ct.Release, error) {
+	return c.listReleases()
+}
+
+// AppReleaseList returns a list of all releases under appID.
+func (c *Client) AppReleaseList(appID string) ([]*ct.Release, error) {
+	return c.listReleases(path.Join("apps", appID))
+}
+
+// GetRelease returns details for the specified release.
+func (c *Client) GetRelease(releaseID string) (*ct.Release, error) {
+	releases, err := c.listReleases(path.Join("releases", releaseID))
+	if err != nil {
+		return nil, err
+	}
+	if len(releases) == 0 {
+		return nil, controller.ErrNotFound
+	}
+	return releases[0], nil
+}
+
+// GetAppRelease returns the current release of an app.
+func (c *Client) GetAppRelease(appID string) (*ct.Release, error) {
+	app, err := c.GetApp(appID)
+	if err != nil {
+		return nil, err
+	}
+	if app.ReleaseID == "" {
+		return nil, controller.ErrNotFound
+	}
+	return c.GetRelease(app.ReleaseID)
+}
+
+// CreateRelease creates a new release.
+func (c *Client) CreateRelease(appID string, release *ct.Release) error {
+	res, err := c.grpc.CreateRelease(c.context(), &api.CreateReleaseRequest{
+		Parent:  path.Join("apps", appID),
+		Release: api.NewRelease(release),
+	})
+	if err != nil {
+		return convertError(err)
+	}
+	*release = *res.ControllerType()
+	return nil
+}
+
+// PutScaleRequest scales the release, waiting for the scale to complete as
+// the gRPC API only returns scale requests once they have completed.
+func (c *Client) PutScaleRequest(req *ct.ScaleRequest) error {
+	if req.AppID == "" || req.ReleaseID == "" {
+		return errors.New("controller: missing app id and/or release id")
+	}
+	// service suffixes are not part of the gRPC API
+	if req.NewServiceSuffix != nil {
+		return c.Client.PutScaleRequest(req)
+	}
+	return c.createScale(context.Background(), req)
+}
+
+func (c *Client) createScale(ctx context.Context, req *ct.ScaleRequest) error {
+	r := &api.CreateScaleRequest{Parent: path.Join("apps", req.AppID, "releases", req.ReleaseID)}
+	if req.NewProcesses != nil {
+		r.Processes = api.NewDeploymentProcesses(*req.NewProcesses)
+	}
+	if req.NewTags != nil {
+		r.Tags = api.NewDeploymentTags(*req.NewTags)
+	}
+	ctx = metadata.AppendToOutgoingContext(ctx, "auth-key", c.key)
+	res, err := c.grpc.CreateScale(ctx, r)
+	if err != nil {
+		return convertError(err)
+	}
+	*req = *res.ControllerType()
+	return nil
+}
+
+// ScaleAppRelease scales the release, waiting for the scale to complete.
+//
+// The gRPC API only returns scale requests once they have completed and does
+// not stream job events, so options which need either of those use the
+// fallback client, and opts.ScaleRequestCallback is called once the scale
+// has completed.
+func (c *Client) ScaleAppRelease(appID, releaseID string, opts ct.ScaleOptions) error {
+	if opts.NoWait || opts.JobEventCallback != nil || opts.ServiceSuffix != nil {
+		return c.Client.ScaleAppRelease(appID, releaseID, opts)
+	}
+	if opts.Processes == nil && opts.Tags == nil {
+		return errors.New("controller: missing processes or tags")
+	}
+	if opts.Timeout == nil {
+		opts.Timeout = &ct.DefaultScaleTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *opts.Timeout)
+	defer cancel()
+	stopped := make(chan struct{})
+	go func() {
+		select {
+		case <-opts.Stop:
+			close(stopped)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	req := &ct.ScaleRequest{
+		AppID:     appID,
+		ReleaseID: releaseID,
+		State:     ct.ScaleRequestStatePending,
+	}
+	if opts.Processes != nil {
+		req.NewProcesses = &opts.Processes
+	}
+	if opts.Tags != nil {
+		req.NewTags = &opts.Tags
+	}
+	if err := c.createScale(ctx, req); err != nil {
+		select {
+		case <-stopped:
+			return ct.ErrScalingStopped
+		default:
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("timed out waiting for scale to complete (waited %.f seconds)", opts.Timeout.Seconds())
+		}
+		if status.Code(err) == codes.Canceled {
+			return errors.New("scale request cancelled")
+		}
+		return err
+	}
+	if opts.ScaleRequestCallback != nil {
+		opts.ScaleRequestCallback(req)
+	}
+	return nil
+}
+
+func deploymentType(d *ct.ExpandedDeployment) *ct.Deployment {
+	deployment := &ct.Deployment{
+		ID:            d.ID,
+		AppID:         d.AppID,
+		Strategy:      d.Strategy,
+		Status:        d.Status,
+		Processes:     d.Processes,
+		Tags:          d.Tags,
+		DeployTimeout: d.DeployTimeout,
+		CreatedAt:     d.CreatedAt,
+		FinishedAt:    d.FinishedAt,
+	}
+	if d.OldRelease != nil {
+		deployment.OldReleaseID = d.OldRelease.ID
+	}
+	if d.NewRelease != nil {
+		deployment.NewReleaseID = d.NewRelease.ID
+	}
+	return deployment
+}
+
+func (c *Client) listDeployments(nameFilters ...string) ([]*ct.Deployment, error) {
+	var deployments []*ct.Deployment
+	req := &api.StreamDeploymentsRequest{NameFilters: nameFilters}
+	for {
+		stream, err := c.grpc.StreamDeployments(c.context(), req)
+		if err != nil {
+			return nil, convertError(err)
+		}
+		res, err := stream.Recv()
+		if err != nil {
+			return nil, convertError(err)
+		}
+		for _, d := range res.Deployments {
+			deployments = append(deployments, deploymentType(d.ControllerType()))
+		}
+		if res.NextPageToken == "" {
+			return deployments, nil
+		}
+		req.PageToken = res.NextPageToken
+	}
+}
+
+// DeploymentList returns a list of all deployments.
+func (c *Client) DeploymentList(appID string) ([]*ct.Deployment, error) {
+	return c.listDeployments(path.Join("apps", appID))
+}
+
+// GetDeployment returns the deployment with the given ID.
+func (c *Client) GetDeployment(deploymentID string) (*ct.Deployment, error) {
+	deployments, err := c.listDeployments(path.Join("deployments", deploymentID))
+	if err != nil {
+		return nil, err
+	}
+	if len(deployments) == 0 {
+		return nil, controller.ErrNotFound
+	}
+	return deployments[0], nil
+}
+
+func deploymentParent(appID, releaseID string) string {
+	return path.Join("apps", appID, "releases", releaseID)
+}
+
+// CreateDeployment creates a deployment of the release, returning once it
+// has been created rather than once it has finished.
+func (c *Client) CreateDeployment(appID, releaseID string) (*ct.Deployment, error) {
+	ctx, cancel := context.WithCancel(c.context())
+	defer cancel()
+	stream, err := c.grpc.CreateDeployment(ctx, &api.CreateDeploymentRequest{Parent: deploymentParent(appID, releaseID)})
+	if err != nil {
+		return nil, convertError(err)
+	}
+	// the first event is sent once the deployment has been created
+	event, err := stream.Recv()
+	if err != nil {
+		return nil, convertError(err)
+	}
+	cancel()
+	return c.GetDeployment(api.ParseIDFromName(event.Parent, "deployments"))
+}
+
+// DeployAppRelease deploys the release, waiting for the deployment to
+// finish unless stopWait is closed.
+func (c *Client) DeployAppRelease(appID, releaseID string, stopWait <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(c.context())
+	defer cancel()
+	stream, err := c.grpc.CreateDeployment(ctx, &api.CreateDeploymentRequest{Parent: deploymentParent(appID, releaseID)})
+	if err != nil {
+		return convertError(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		for {
+			if _, err := stream.Recv(); err != nil {
+				done <- err
+				return
+			}
+		}
+	}()
+	select {
+	case err := <-done:
+		switch {
+		case err == io.EOF:
+			return nil
+		case status.Code(err) == codes.FailedPrecondition:
+			// failed deployments return the deployment's error
+			return errors.New(status.Convert(err).Message())
+		default:
+			return convertError(err)
+		}
+	case <-stopWait:
+		return errors.New("deploy wait cancelled")
+	}
+}
+
+func jobName(appID, jobID string) string {
+	return path.Join("apps", appID, "jobs", jobID)
+}
+
+func (c *Client) listJobs(req *api.StreamJobsRequest) ([]*ct.Job, error) {
+	stream, err := c.grpc.StreamJobs(c.context(), req)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	res, err := stream.Recv()
+	if err != nil {
+		return nil, convertError(err)
+	}
+	jobs := make([]*ct.Job, len(res.Jobs))
+	for i, job := range res.Jobs {
+		jobs[i] = job.ControllerType()
+	}
+	return jobs, nil
+}
+
+// GetJob returns a Job for the given app and job ID
+func (c *Client) GetJob(appID, jobID string) (*ct.Job, error) {
+	jobs, err := c.listJobs(&api.StreamJobsRequest{NameFilters: []string{jobName(appID, jobID)}})
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, controller.ErrNotFound
+	}
+	return jobs[0], nil
+}
+
+// JobList returns a list of all jobs.
+func (c *Client) JobList(appID string) ([]*ct.Job, error) {
+	return c.listJobs(&api.StreamJobsRequest{NameFilters: []string{path.Join("apps", appID)}})
+}
+
+// JobListActive returns a list of all active jobs.
+func (c *Client) JobListActive() ([]*ct.Job, error) {
+	return c.listJobs(&api.StreamJobsRequest{})
+}
+
+// RunJobDetached runs a new job under the specified app, returning the job's
+// details.
+func (c *Client) RunJobDetached(appID string, req *ct.NewJob) (*ct.Job, error) {
+	job, err := c.grpc.CreateJob(c.context(), api.NewCreateJobRequest(appID, req))
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return job.ControllerType(), nil
+}
+
+// DeleteJob kills a specific job id under the specified app.
+func (c *Client) DeleteJob(appID, jobID string) error {
+	_, err := c.grpc.DeleteJob(c.context(), &api.DeleteJobRequest{Name: jobName(appID, jobID)})
+	return convertError(err)
+}
+
+// StreamJobEvents streams job events to the output channel.
+func (c *Client) StreamJobEvents(appID string, output chan *ct.Job) (stream.Stream, error) {
+	req := &api.StreamJobsRequest{StreamCreates: true, StreamUpdates: true}
+	if appID != "" {
+		req.NameFilters = []string{path.Join("apps", appID)}
+	}
+	ctx, cancel := context.WithCancel(c.context())
+	jobStream, err := c.grpc.StreamJobs(ctx, req)
+	if err != nil {
+		cancel()
+		return nil, convertError(err)
+	}
+	// skip the existing jobs, which are not job events
+	if _, err := jobStream.Recv(); err != nil {
+		cancel()
+		return nil, convertError(err)
+	}
+	return newStream(ctx, cancel, func(stopCh <-chan struct{}) error {
+		res, err := jobStream.Recv()
+		if err != nil {
+			return err
+		}
+		for _, job := range res.Jobs {
+			select {
+			case output <- job.ControllerType():
+			case <-stopCh:
+				return io.EOF
+			}
+		}
+		return nil
+	}, func() { close(output) }), nil
+}
+
+func routeName(appID, routeID string) string {
+	// route IDs may be formatted with the route type (e.g. http/ID)
+	return path.Join("apps", appID, "routes", path.Base(routeID))
+}
+
+func (c *Client) listRoutes(nameFilters ...string) ([]*router.Route, error) {
+	stream, err := c.grpc.StreamRoutes(c.context(), &api.StreamRoutesRequest{NameFilters: nameFilters})
+	if err != nil {
+		return nil, convertError(err)
+	}
+	res, err := stream.Recv()
+	if err != nil {
+		return nil, convertError(err)
+	}
+	routes := make([]*router.Route, len(res.Routes))
+	for i, route := range res.Routes {
+		routes[i] = route.ControllerType()
+	}
+	return routes, nil
+}
+
+// RouteList returns all routes.
+func (c *Client) RouteList() ([]*router.Route, error) {
+	return c.listRoutes()
+}
+
+// AppRouteList returns all routes for an app.
+func (c *Client) AppRouteList(appID string) ([]*router.Route, error) {
+	return c.listRoutes(path.Join("apps", appID))
+}
+
+// GetRoute returns details for the routeID under the specified app.
+func (c *Client) GetRoute(appID string, routeID string) (*router.Route, error) {
+	routes, err := c.listRoutes(routeName(appID, routeID))
+	if err != nil {
+		return nil, err
+	}
+	if len(routes) == 0 {
+		return nil, controller.ErrNotFound
+	}
+	return routes[0], nil
+}
+
+// CreateRoute creates a new route for the specified app.
+func (c *Client) CreateRoute(appID string, route *router.Route) error {
+	res, err := c.grpc.CreateRoute(c.context(), &api.CreateRouteRequest{
+		Parent: path.Join("apps", appID),
+		Route:  api.NewRoute(route),
+	})
+	if err != nil {
+		return convertError(err)
+	}
+	*route = *res.ControllerType()
+	return nil
+}
+
+// UpdateRoute updates details for the routeID under the specified app.
+func (c *Client) UpdateRoute(appID string, routeID string, route *router.Route) error {
+	r := api.NewRoute(route)
+	r.Name = routeName(appID, routeID)
+	res, err := c.grpc.UpdateRoute(c.context(), &api.UpdateRouteRequest{Route: r})
+	if err != nil {
+		return convertError(err)
+	}
+	*route = *res.ControllerType()
+	return nil
+}
+
+// DeleteRoute deletes a route under the specified app.
+func (c *Client) DeleteRoute(appID string, routeID string) error {
+	_, err := c.grpc.DeleteRoute(c.context(), &api.DeleteRouteRequest{Name: routeName(appID, routeID)})
+	return convertError(err)
+}
+
+func (c *Client) listProviders(nameFilters ...string) ([]*ct.Provider, error) {
+	stream, err := c.grpc.StreamProviders(c.context(), &api.StreamProvidersRequest{NameFilters: nameFilters})
+	if err != nil {
+		return nil, convertError(err)
+	}
+	res, err := stream.Recv()
+	if err != nil {
+		return nil, convertError(err)
+	}
+	providers := make([]*ct.Provider, len(res.Providers))
+	for i, p := range res.Providers {
+		providers[i] = p.ControllerType()
+	}
+	return providers, nil
+}
+
+// ProviderList returns a list of all providers.
+func (c *Client) ProviderList() ([]*ct.Provider, error) {
+	return c.listProviders()
+}
+
+// GetProvider returns the provider identified by providerID.
+func (c *Client) GetProvider(providerID string) (*ct.Provider, error) {
+	providers, err := c.listProviders(path.Join("providers", providerID))
+	if err != nil {
+		return nil, err
+	}
+	if len(providers) == 0 {
+		return nil, controller.ErrNotFound
+	}
+	return providers[0], nil
+}
+
+// CreateProvider creates a new provider.
+func (c *Client) CreateProvider(provider *ct.Provider) error {
+	res, err := c.grpc.CreateProvider(c.context(), &api.CreateProviderRequest{Provider: api.NewProvider(provider)})
+	if err != nil {
+		return convertError(err)
+	}
+	*provider = *res.ControllerType()
+	return nil
+}
+
+func resourceName(providerID, resourceID string) string {
+	return path.Join("providers", providerID, "resources", resourceID)
+}
+
+func (c *Client) listResources(nameFilters ...string) ([]*ct.Resource, error) {
+	stream, err := c.grpc.StreamResources(c.context(), &api.StreamResourcesRequest{NameFilters: nameFilters})
+	if err != nil {
+		return nil, convertError(err)
+	}
+	res, err := stream.Recv()
+	if err != nil {
+		return nil, convertError(err)
+	}
+	resources := make([]*ct.Resource, len(res.Resources))
+	for i, r := range res.Resources {
+		resources[i] = r.ControllerType()
+	}
+	return resources, nil
+}
+
+// ResourceListAll returns all resources.
+func (c *Client) ResourceListAll() ([]*ct.Resource, error) {
+	return c.listResources()
+}
+
+// ResourceList returns all resources under providerID.
+func (c *Client) ResourceList(providerID string) ([]*ct.Resource, error) {
+	return c.listResources(path.Join("providers", providerID))
+}
+
+// AppResourceList returns a list of all resources under appID.
+func (c *Client) AppResourceList(appID string) ([]*ct.Resource, error) {
+	return c.listResources(path.Join("apps", appID))
+}
+
+// GetResource returns the resource identified by resourceID under providerID.
+func (c *Client) GetResource(providerID, resourceID string) (*ct.Resource, error) {
+	resources, err := c.listResources(resourceName(providerID, resourceID))
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) == 0 {
+		return nil, controller.ErrNotFound
+	}
+	return resources[0], nil
+}
+
+// ProvisionResource uses a provider to provision a new resource for the
+// application. Returns details about the resource.
+func (c *Client) ProvisionResource(req *ct.ResourceReq) (*ct.Resource, error) {
+	r := &api.CreateResourceRequest{Parent: path.Join("providers", req.ProviderID)}
+	if req.Config != nil {
+		r.Config = *req.Config
+	}
+	for _, appID := range req.Apps {
+		r.Apps = append(r.Apps, path.Join("apps", appID))
+	}
+	res, err := c.grpc.CreateResource(c.context(), r)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return res.ControllerType(), nil
+}
+
+// DeleteResource deprovisions and deletes the resource identified by resourceID under providerID.
+func (c *Client) DeleteResource(providerID, resourceID string) (*ct.Resource, error) {
+	res, err := c.grpc.DeleteResource(c.context(), &api.DeleteResourceRequest{Name: resourceName(providerID, resourceID)})
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return res.ControllerType(), nil
+}
+
+func (c *Client) listVolumes(nameFilters ...string) ([]*ct.Volume, error) {
+	stream, err := c.grpc.StreamVolumes(c.context(), &api.StreamVolumesRequest{NameFilters: nameFilters})
+	if err != nil {
+		return nil, convertError(err)
+	}
+	res, err := stream.Recv()
+	if err != nil {
+		return nil, convertError(err)
+	}
+	volumes := make([]*ct.Volume, len(res.Volumes))
+	for i, vol := range res.Volumes {
+		volumes[i] = vol.ControllerType()
+	}
+	return volumes, nil
+}
+
+// VolumeList returns a list of all volumes.
+func (c *Client) VolumeList() ([]*ct.Volume, error) {
+	return c.listVolumes()
+}
+
+// AppVolumeList returns a list of all volumes for an app.
+func (c *Client) AppVolumeList(appID string) ([]*ct.Volume, error) {
+	return c.listVolumes(path.Join("apps", appID))
+}
+
+// GetVolume returns a Volume for the given volume ID
+func (c *Client) GetVolume(appID, id string) (*ct.Volume, error) {
+	volumes, err := c.listVolumes(path.Join("apps", appID, "volumes", id))
+	if err != nil {
+		return nil, err
+	}
+	if len(volumes) == 0 {
+		return nil, controller.ErrNotFound
+	}
+	return volumes[0], nil
+}
+
+// DecommissionVolume decommissions a volume
+func (c *Client) DecommissionVolume(appID string, vol *ct.Volume) error {
+	res, err := c.grpc.DecommissionVolume(c.context(), &api.DecommissionVolumeRequest{
+		Name: path.Join("apps", appID, "volumes", vol.ID),
+	})
+	if err != nil {
+		return convertError(err)
+	}
+	*vol = *res.ControllerType()
+	return nil
+}
+
+// StreamVolumes sends a series of Volume into the provided channel.
+// If since is not nil, only retrieves volume updates since the specified time.
+//
+// As with the JSON API, an empty Volume is sent once the existing volumes
+// have been sent.
+func (c *Client) StreamVolumes(since *time.Time, output chan *ct.Volume) (stream.Stream, error) {
+	ctx, cancel := context.WithCancel(c.context())
+	volStream, err := c.grpc.StreamVolumes(ctx, &api.StreamVolumesRequest{StreamCreates: true, StreamUpdates: true})
+	if err != nil {
+		cancel()
+		return nil, convertError(err)
+	}
+	return newStream(ctx, cancel, func(stopCh <-chan struct{}) error {
+		res, err := volStream.Recv()
+		if err != nil {
+			return err
+		}
+		for _, v := range res.Volumes {
+			vol := v.ControllerType()
+			if since != nil && vol.UpdatedAt != nil && !vol.UpdatedAt.After(*since) {
+				continue
+			}
+			select {
+			case output <- vol:
+			case <-stopCh:
+				return io.EOF
+			}
+		}
+		if res.PageComplete {
+			select {
+			case output <- &ct.Volume{}:
+			case <-stopCh:
+				return io.EOF
+			}
+		}
+		return nil
+	}, func() { close(output) }), nil
+}
+
+func (c *Client) listSinks(nameFilters ...string) ([]*ct.Sink, error) {
+	stream, err := c.grpc.StreamSinks(c.context(), &api.StreamSinksRequest{NameFilters: nameFilters})
+	if err != nil {
+		return nil, convertError(err)
+	}
+	res, err := stream.Recv()
+	if err != nil {
+		return nil, convertError(err)
+	}
+	sinks := make([]*ct.Sink, len(res.Sinks))
+	for i, sink := range res.Sinks {
+		sinks[i] = sink.ControllerType()
+	}
+	return sinks, nil
+}
+
+// ListSinks returns all log sinks
+func (c *Client) ListSinks() ([]*ct.Sink, error) {
+	return c.listSinks()
+}
+
+// GetSink gets a log sink
+func (c *Client) GetSink(sinkID string) (*ct.Sink, error) {
+	sinks, err := c.listSinks(path.Join("sinks", sinkID))
+	if err != nil {
+		return nil, err
+	}
+	if len(sinks) == 0 {
+		return nil, controller.ErrNotFound
+	}
+	return sinks[0], nil
+}
+
+// CreateSink creates a new log sink
+func (c *Client) CreateSink(sink *ct.Sink) error {
+	res, err := c.grpc.CreateSink(c.context(), &api.CreateSinkRequest{Sink: api.NewSink(sink)})
+	if err != nil {
+		return convertError(err)
+	}
+	*sink = *res.ControllerType()
+	return nil
+}
+
+// DeleteSink removes a log sink
+func (c *Client) DeleteSink(sinkID string) (*ct.Sink, error) {
+	res, err := c.grpc.DeleteSink(c.context(), &api.DeleteSinkRequest{Name: path.Join("sinks", sinkID)})
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return res.ControllerType(), nil
+}
+
+// StreamSinks yields a series of Sink into the provided channel.
+// If since is not nil, only retrieves sink updates since the specified time.
+//
+// As with the JSON API, an empty Sink is sent once the existing sinks have
+// been sent, and deleted sinks are sent without a config.
+func (c *Client) StreamSinks(since *time.Time, output chan *ct.Sink) (stream.Stream, error) {
+	ctx, cancel := context.WithCancel(c.context())
+	sinkStream, err := c.grpc.StreamSinks(ctx, &api.StreamSinksRequest{StreamCreates: true, StreamUpdates: true})
+	if err != nil {
+		cancel()
+		return nil, convertError(err)
+	}
+	return newStream(ctx, cancel, func(stopCh <-chan struct{}) error {
+		res, err := sinkStream.Recv()
+		if err != nil {
+			return err
+		}
+		for _, s := range res.Sinks {
+			sink := s.ControllerType()
+			if since != nil && sink.UpdatedAt != nil && !sink.UpdatedAt.After(*since) {
+				continue
+			}
+			if s.DeleteTime != nil {
+				sink.Config = nil
+			}
+			select {
+			case output <- sink:
+			case <-stopCh:
+				return io.EOF
+			}
+		}
+		if res.PageComplete {
+			select {
+			case output <- &ct.Sink{}:
+			case <-stopCh:
+				return io.EOF
+			}
+		}
+		return nil
+	}, func() { close(output) }), nil
+}
+
+func newStreamEventsRequest(appID string, objectTypes []ct.EventType, objectID string, count int) *api.StreamEventsRequest {
+	req := &api.StreamEventsRequest{PageSize: int32(count)}
+	if appID != "" {
+		req.NameFilters = []string{path.Join("apps", appID)}
+	}
+	for _, typ := range objectTypes {
+		req.ObjectTypeFilters = append(req.ObjectTypeFilters, string(typ))
+	}
+	if objectID != "" {
+		req.ObjectIdFilters = []string{objectID}
+	}
+	return req
+}
+
+func eventName(id int64) string {
+	return path.Join("events", strconv.FormatInt(id, 10))
+}
+
+func (c *Client) StreamEvents(opts ct.StreamEventsOptions, output chan *ct.Event) (stream.Stream, error) {
+	req := newStreamEventsRequest(opts.AppID, opts.ObjectTypes, opts.ObjectID, opts.Count)
+	req.SkipPast = !opts.Past
+	req.StreamCreates = true
+	ctx, cancel := context.WithCancel(c.context())
+	eventStream, err := c.grpc.StreamEvents(ctx, req)
+	if err != nil {
+		cancel()
+		return nil, convertError(err)
+	}
+	return newStream(ctx, cancel, func(stopCh <-chan struct{}) error {
+		res, err := eventStream.Recv()
+		if err != nil {
+			return err
+		}
+		for _, event := range res.Events {
+			select {
+			case output <- event.ControllerType():
+			case <-stopCh:
+				return io.EOF
+			}
+		}
+		return nil
+	}, func() { close(output) }), nil
+}
+
+func (c *Client) ListEvents(opts ct.ListEventsOptions) ([]*ct.Event, error) {
+	req := newStreamEventsRequest(opts.AppID, opts.ObjectTypes, opts.ObjectID, opts.Count)
+	if opts.BeforeID != nil {
+		req.Before = eventName(*opts.BeforeID)
+	}
+	if opts.SinceID != nil {
+		req.Since = eventName(*opts.SinceID)
+	}
+	stream, err := c.grpc.StreamEvents(c.context(), req)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	res, err := stream.Recv()
+	if err != nil {
+		return nil, convertError(err)
+	}
+	// the gRPC API returns events in ascending order, whereas the JSON API
+	// returns the most recent events first
+	events := make([]*ct.Event, len(res.Events))
+	for i, event := range res.Events {
+		events[len(events)-1-i] = event.ControllerType()
+	}
+	return events, nil
+}
+
+func (c *Client) GetEvent(id int64) (*ct.Event, error) {
+	stream, err := c.grpc.StreamEvents(c.context(), &api.StreamEventsRequest{NameFilters: []string{eventName(id)}})
+	if err != nil {
+		return nil, convertError(err)
+	}
+	res, err := stream.Recv()
+	if err != nil {
+		return nil, convertError(err)
+	}
+	if len(res.Events) == 0 {
+		return nil, controller.ErrNotFound
+	}
+	return res.Events[0].ControllerType(), nil
+}
@@ -0,0 +1,306 @@
+package grpccontroller
+
+import (
+	"io"
+	"time"
+
+	controller "github.com/flynn/flynn/controller/client"
+	v1controller "github.com/flynn/flynn/controller/client/v1"
+	ct "github.com/flynn/flynn/controller/types"
+	host "github.com/flynn/flynn/host/types"
+	logagg "github.com/flynn/flynn/logaggregator/types"
+	"github.com/flynn/flynn/pkg/httpclient"
+	"github.com/flynn/flynn/pkg/stream"
+)
+
+// noFallback is the fallback client of clients created without one, which
+// returns ErrNoFallback from the methods which are not part of the gRPC API.
+//
+// The embedded controller.Client is nil, but is only used to satisfy the
+// interface for the methods which Client implements itself.
+type noFallback struct {
+	controller.Client
+}
+
+func (noFallback) SetKey(newKey string) {}
+
+func (noFallback) GetCACert() ([]byte, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) StreamFormations(since *time.Time, output chan<- *ct.ExpandedFormation) (stream.Stream, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) PutDomain(dm *ct.DomainMigration) error {
+	return ErrNoFallback
+}
+
+func (noFallback) CreateArtifact(artifact *ct.Artifact) error {
+	return ErrNoFallback
+}
+
+func (noFallback) CreateApp(app *ct.App) error {
+	return ErrNoFallback
+}
+
+func (noFallback) DeleteApp(appID string) (*ct.AppDeletion, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) AddResourceApp(providerID, resourceID, appID string) (*ct.Resource, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) DeleteResourceApp(providerID, resourceID, appID string) (*ct.Resource, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) PutResource(resource *ct.Resource) error {
+	return ErrNoFallback
+}
+
+func (noFallback) PutFormation(formation *ct.Formation) error {
+	return ErrNoFallback
+}
+
+func (noFallback) PutJob(job *ct.Job) error {
+	return ErrNoFallback
+}
+
+func (noFallback) SetAppRelease(appID, releaseID string) error {
+	return ErrNoFallback
+}
+
+func (noFallback) GetAppReleaseWithSecrets(appID string) (*ct.Release, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) GetFormation(appID, releaseID string) (*ct.Formation, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) GetExpandedFormation(appID, releaseID string) (*ct.ExpandedFormation, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) FormationList(appID string) ([]*ct.Formation, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) FormationListActive() ([]*ct.ExpandedFormation, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) DeleteFormation(appID, releaseID string) error {
+	return ErrNoFallback
+}
+
+func (noFallback) GetArtifact(artifactID string) (*ct.Artifact, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) GetAppLog(appID string, options *logagg.LogOpts) (io.ReadCloser, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) StreamAppLog(appID string, options *logagg.LogOpts, output chan<- *ct.SSELogChunk) (stream.Stream, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) PauseDeployment(deploymentID string) (*ct.Deployment, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) ResumeDeployment(deploymentID string) (*ct.Deployment, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) AbortDeployment(deploymentID string) (*ct.Deployment, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) ApproveDeployment(deploymentID, comment string) (*ct.Deployment, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) RejectDeployment(deploymentID, comment string) (*ct.Deployment, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) StreamDeployment(d *ct.Deployment, output chan *ct.DeploymentEvent) (stream.Stream, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) ScaleAppReleaseDryRun(appID, releaseID string, opts ct.ScaleOptions) (*ct.SchedulerDryRunResult, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) WatchJobEvents(appID, releaseID string) (ct.JobWatcher, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) ExpectedScalingEvents(actual, expected map[string]int, releaseProcesses map[string]ct.ProcessType, clusterSize int) ct.JobEvents {
+	// this doesn't make any requests
+	return (&v1controller.Client{}).ExpectedScalingEvents(actual, expected, releaseProcesses, clusterSize)
+}
+
+func (noFallback) RunJobAttached(appID string, job *ct.NewJob) (httpclient.ReadWriteCloser, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) JobListSince(since time.Time) ([]*ct.Job, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) ArtifactList() ([]*ct.Artifact, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) PutVolume(vol *ct.Volume) error {
+	return ErrNoFallback
+}
+
+func (noFallback) Backup() (io.ReadCloser, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) GetBackupMeta() (*ct.ClusterBackup, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) DeleteRelease(appID, releaseID string) (*ct.ReleaseDeletion, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) ScheduleAppGarbageCollection(appID string) error {
+	return ErrNoFallback
+}
+
+func (noFallback) CreateSchedule(schedule *ct.Schedule) error {
+	return ErrNoFallback
+}
+
+func (noFallback) GetSchedule(appID, scheduleID string) (*ct.Schedule, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) DeleteSchedule(appID, scheduleID string) (*ct.Schedule, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) ScheduleList(appID string) ([]*ct.Schedule, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) ScheduleRunList(appID, scheduleID string) ([]*ct.ScheduleRun, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) PutAutoscalePolicy(policy *ct.AutoscalePolicy) error {
+	return ErrNoFallback
+}
+
+func (noFallback) GetAutoscalePolicy(appID, processType string) (*ct.AutoscalePolicy, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) DeleteAutoscalePolicy(appID, processType string) (*ct.AutoscalePolicy, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) AutoscalePolicyList(appID string) ([]*ct.AutoscalePolicy, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) CreateToken(token *ct.Token) error {
+	return ErrNoFallback
+}
+
+func (noFallback) DeleteToken(tokenID string) (*ct.Token, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) ListTokens() ([]*ct.Token, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) ListAuditLog(opts ct.ListAuditOptions) ([]*ct.AuditRecord, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) CreateWebhook(webhook *ct.Webhook) error {
+	return ErrNoFallback
+}
+
+func (noFallback) GetWebhook(webhookID string) (*ct.Webhook, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) DeleteWebhook(webhookID string) (*ct.Webhook, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) ListWebhooks() ([]*ct.Webhook, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) ListWebhookDeliveries(webhookID string, count int) ([]*ct.WebhookDelivery, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) CreateEnvGroup(group *ct.EnvGroup) error {
+	return ErrNoFallback
+}
+
+func (noFallback) GetEnvGroup(groupID string) (*ct.EnvGroup, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) UpdateEnvGroup(group *ct.EnvGroup) (*ct.EnvGroupUpdate, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) DeleteEnvGroup(groupID string) (*ct.EnvGroupUpdate, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) ListEnvGroups() ([]*ct.EnvGroup, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) AppEnvGroupList(appID string) ([]*ct.EnvGroup, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) AttachEnvGroup(appID, groupID string) (*ct.EnvGroupUpdate, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) DetachEnvGroup(appID, groupID string) (*ct.EnvGroupUpdate, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) GetAppQuota(appID string) (*ct.AppQuota, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) UpdateAppQuota(quota *ct.AppQuota) error {
+	return ErrNoFallback
+}
+
+func (noFallback) ManifestDryRun(appID string, manifest *ct.AppManifest) (*ct.ManifestDiff, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) ApplyManifest(appID string, manifest *ct.AppManifest) (*ct.ManifestDiff, error) {
+	return nil, ErrNoFallback
+}
+
+func (noFallback) PutHostDrain(status *host.DrainStatus) error {
+	return ErrNoFallback
+}
+
+func (noFallback) HostDrainList() ([]*host.DrainStatus, error) {
+	return nil, ErrNoFallback
+}
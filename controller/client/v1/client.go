@@ -15,6 +15,7 @@ import (
 	"time"
 
 	ct "github.com/flynn/flynn/controller/types"
+	host "github.com/flynn/flynn/host/types"
 	logagg "github.com/flynn/flynn/logaggregator/types"
 	"github.com/flynn/flynn/pkg/httpclient"
 	"github.com/flynn/flynn/pkg/httphelper"
@@ -328,6 +329,7 @@ func (c *Client) ScaleAppRelease(appID, releaseID string, opts ct.ScaleOptions)
 	if opts.Tags != nil {
 		scaleReq.NewTags = &opts.Tags
 	}
+	scaleReq.NewServiceSuffix = opts.ServiceSuffix
 	if err := c.PutScaleRequest(scaleReq); err != nil {
 		return err
 	}
@@ -385,6 +387,26 @@ func (c *Client) ScaleAppRelease(appID, releaseID string, opts ct.ScaleOptions)
 	}
 }
 
+// ScaleAppReleaseDryRun returns the decisions the scheduler would make if the
+// given release was scaled with the given options, without scaling it.
+func (c *Client) ScaleAppReleaseDryRun(appID, releaseID string, opts ct.ScaleOptions) (*ct.SchedulerDryRunResult, error) {
+	if opts.Processes == nil && opts.Tags == nil {
+		return nil, errors.New("controller: missing processes or tags")
+	}
+	req := &ct.ScaleRequest{
+		AppID:     appID,
+		ReleaseID: releaseID,
+	}
+	if opts.Processes != nil {
+		req.NewProcesses = &opts.Processes
+	}
+	if opts.Tags != nil {
+		req.NewTags = &opts.Tags
+	}
+	res := &ct.SchedulerDryRunResult{}
+	return res, c.Post(fmt.Sprintf("/apps/%s/scale/%s/dry-run", appID, releaseID), req, res)
+}
+
 // PutFormation updates an existing formation.
 func (c *Client) PutFormation(formation *ct.Formation) error {
 	if formation.AppID == "" || formation.ReleaseID == "" {
@@ -417,6 +439,13 @@ func (c *Client) GetAppRelease(appID string) (*ct.Release, error) {
 	return release, c.Get(fmt.Sprintf("/apps/%s/release", appID), release)
 }
 
+// GetAppReleaseWithSecrets returns the current release of an app with its
+// secret env vars decrypted.
+func (c *Client) GetAppReleaseWithSecrets(appID string) (*ct.Release, error) {
+	release := &ct.Release{}
+	return release, c.Get(fmt.Sprintf("/apps/%s/release?secrets=true", appID), release)
+}
+
 // RouteList returns all routes.
 func (c *Client) RouteList() ([]*router.Route, error) {
 	var routes []*router.Route
@@ -540,6 +569,37 @@ func (c *Client) CreateDeployment(appID, releaseID string) (*ct.Deployment, erro
 	return deployment, c.Post(fmt.Sprintf("/apps/%s/deploy", appID), &ct.Release{ID: releaseID}, deployment)
 }
 
+// PauseDeployment pauses a running deployment once its current step has
+// finished.
+func (c *Client) PauseDeployment(deploymentID string) (*ct.Deployment, error) {
+	deployment := &ct.Deployment{}
+	return deployment, c.Post(fmt.Sprintf("/deployments/%s/pause", deploymentID), nil, deployment)
+}
+
+// ResumeDeployment resumes a paused deployment.
+func (c *Client) ResumeDeployment(deploymentID string) (*ct.Deployment, error) {
+	deployment := &ct.Deployment{}
+	return deployment, c.Post(fmt.Sprintf("/deployments/%s/resume", deploymentID), nil, deployment)
+}
+
+// AbortDeployment stops a running or paused deployment and rolls it back.
+func (c *Client) AbortDeployment(deploymentID string) (*ct.Deployment, error) {
+	deployment := &ct.Deployment{}
+	return deployment, c.Post(fmt.Sprintf("/deployments/%s/abort", deploymentID), nil, deployment)
+}
+
+// ApproveDeployment approves a deployment which is waiting for approval.
+func (c *Client) ApproveDeployment(deploymentID, comment string) (*ct.Deployment, error) {
+	deployment := &ct.Deployment{}
+	return deployment, c.Post(fmt.Sprintf("/deployments/%s/approve", deploymentID), &ct.DeploymentApproval{Comment: comment}, deployment)
+}
+
+// RejectDeployment rejects a deployment which is waiting for approval.
+func (c *Client) RejectDeployment(deploymentID, comment string) (*ct.Deployment, error) {
+	deployment := &ct.Deployment{}
+	return deployment, c.Post(fmt.Sprintf("/deployments/%s/reject", deploymentID), &ct.DeploymentApproval{Comment: comment}, deployment)
+}
+
 // DeploymentList returns a list of all deployments.
 func (c *Client) DeploymentList(appID string) ([]*ct.Deployment, error) {
 	var deployments []*ct.Deployment
@@ -756,6 +816,13 @@ func (c *Client) JobListActive() ([]*ct.Job, error) {
 	return jobs, c.Get("/active-jobs", &jobs)
 }
 
+// JobListSince returns a list of the formation jobs which have been updated
+// since the given time.
+func (c *Client) JobListSince(since time.Time) ([]*ct.Job, error) {
+	var jobs []*ct.Job
+	return jobs, c.Get("/jobs?since="+url.QueryEscape(since.UTC().Format(time.RFC3339Nano)), &jobs)
+}
+
 // AppList returns a list of all apps.
 func (c *Client) AppList() ([]*ct.App, error) {
 	var apps []*ct.App
@@ -944,6 +1011,238 @@ func (c *Client) StreamSinks(since *time.Time, output chan *ct.Sink) (stream.Str
 	return c.Stream("GET", "/sinks?since="+t, nil, output)
 }
 
+// CreateSchedule creates a schedule which periodically runs a one-off job in
+// schedule.AppID
+func (c *Client) CreateSchedule(schedule *ct.Schedule) error {
+	if schedule.AppID == "" {
+		return errors.New("controller: missing app ID")
+	}
+	return c.Post(fmt.Sprintf("/apps/%s/schedules", schedule.AppID), schedule, schedule)
+}
+
+// GetSchedule gets a schedule
+func (c *Client) GetSchedule(appID, scheduleID string) (*ct.Schedule, error) {
+	schedule := &ct.Schedule{}
+	return schedule, c.Get(fmt.Sprintf("/apps/%s/schedules/%s", appID, scheduleID), schedule)
+}
+
+// DeleteSchedule removes a schedule, returning the removed schedule
+func (c *Client) DeleteSchedule(appID, scheduleID string) (*ct.Schedule, error) {
+	schedule := &ct.Schedule{}
+	return schedule, c.Delete(fmt.Sprintf("/apps/%s/schedules/%s", appID, scheduleID), schedule)
+}
+
+// ScheduleList returns all schedules for an app
+func (c *Client) ScheduleList(appID string) ([]*ct.Schedule, error) {
+	var schedules []*ct.Schedule
+	return schedules, c.Get(fmt.Sprintf("/apps/%s/schedules", appID), &schedules)
+}
+
+// ScheduleRunList returns the most recent runs of a schedule
+func (c *Client) ScheduleRunList(appID, scheduleID string) ([]*ct.ScheduleRun, error) {
+	var runs []*ct.ScheduleRun
+	return runs, c.Get(fmt.Sprintf("/apps/%s/schedules/%s/runs", appID, scheduleID), &runs)
+}
+
+// PutAutoscalePolicy creates or updates the autoscale policy for
+// policy.ProcessType in policy.AppID
+func (c *Client) PutAutoscalePolicy(policy *ct.AutoscalePolicy) error {
+	if policy.AppID == "" {
+		return errors.New("controller: missing app ID")
+	}
+	if policy.ProcessType == "" {
+		return errors.New("controller: missing process type")
+	}
+	return c.Put(fmt.Sprintf("/apps/%s/autoscale/%s", policy.AppID, policy.ProcessType), policy, policy)
+}
+
+// GetAutoscalePolicy gets the autoscale policy for an app's process type
+func (c *Client) GetAutoscalePolicy(appID, processType string) (*ct.AutoscalePolicy, error) {
+	policy := &ct.AutoscalePolicy{}
+	return policy, c.Get(fmt.Sprintf("/apps/%s/autoscale/%s", appID, processType), policy)
+}
+
+// DeleteAutoscalePolicy removes the autoscale policy for an app's process
+// type, returning the removed policy
+func (c *Client) DeleteAutoscalePolicy(appID, processType string) (*ct.AutoscalePolicy, error) {
+	policy := &ct.AutoscalePolicy{}
+	return policy, c.Delete(fmt.Sprintf("/apps/%s/autoscale/%s", appID, processType), policy)
+}
+
+// AutoscalePolicyList returns all autoscale policies for an app
+func (c *Client) AutoscalePolicyList(appID string) ([]*ct.AutoscalePolicy, error) {
+	var policies []*ct.AutoscalePolicy
+	return policies, c.Get(fmt.Sprintf("/apps/%s/autoscale", appID), &policies)
+}
+
+// CreateToken creates a new API token, setting token.Key to the key used to
+// authenticate with it
+func (c *Client) CreateToken(token *ct.Token) error {
+	return c.Post("/tokens", token, token)
+}
+
+// DeleteToken revokes an API token, returning the revoked token
+func (c *Client) DeleteToken(tokenID string) (*ct.Token, error) {
+	token := &ct.Token{}
+	return token, c.Delete(fmt.Sprintf("/tokens/%s", tokenID), token)
+}
+
+// ListTokens returns all API tokens which have not been revoked
+func (c *Client) ListTokens() ([]*ct.Token, error) {
+	var tokens []*ct.Token
+	return tokens, c.Get("/tokens", &tokens)
+}
+
+// ListAuditLog returns audit records matching the given options, most recent
+// first
+func (c *Client) ListAuditLog(opts ct.ListAuditOptions) ([]*ct.AuditRecord, error) {
+	q := make(url.Values)
+	if opts.AppID != "" {
+		q.Set("app_id", opts.AppID)
+	}
+	if opts.KeyID != "" {
+		q.Set("key_id", opts.KeyID)
+	}
+	if opts.Since != nil {
+		q.Set("since", opts.Since.Format(time.RFC3339Nano))
+	}
+	if opts.Before != nil {
+		q.Set("before", opts.Before.Format(time.RFC3339Nano))
+	}
+	if opts.Count > 0 {
+		q.Set("count", strconv.Itoa(opts.Count))
+	}
+	path := "/audit"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	var records []*ct.AuditRecord
+	return records, c.Get(path, &records)
+}
+
+// CreateWebhook creates a new webhook, setting webhook.Secret to the secret
+// used to sign its payloads
+func (c *Client) CreateWebhook(webhook *ct.Webhook) error {
+	return c.Post("/webhooks", webhook, webhook)
+}
+
+// GetWebhook returns the webhook with the given ID
+func (c *Client) GetWebhook(webhookID string) (*ct.Webhook, error) {
+	webhook := &ct.Webhook{}
+	return webhook, c.Get(fmt.Sprintf("/webhooks/%s", webhookID), webhook)
+}
+
+// DeleteWebhook removes a webhook, returning the removed webhook
+func (c *Client) DeleteWebhook(webhookID string) (*ct.Webhook, error) {
+	webhook := &ct.Webhook{}
+	return webhook, c.Delete(fmt.Sprintf("/webhooks/%s", webhookID), webhook)
+}
+
+// ListWebhooks returns all webhooks which have not been removed
+func (c *Client) ListWebhooks() ([]*ct.Webhook, error) {
+	var webhooks []*ct.Webhook
+	return webhooks, c.Get("/webhooks", &webhooks)
+}
+
+// ListWebhookDeliveries returns the most recent count deliveries of the
+// given webhook (or all deliveries if count is zero), most recent first
+func (c *Client) ListWebhookDeliveries(webhookID string, count int) ([]*ct.WebhookDelivery, error) {
+	path := fmt.Sprintf("/webhooks/%s/deliveries", webhookID)
+	if count > 0 {
+		path += fmt.Sprintf("?count=%d", count)
+	}
+	var deliveries []*ct.WebhookDelivery
+	return deliveries, c.Get(path, &deliveries)
+}
+
+// CreateEnvGroup creates an env group which is not attached to any apps
+func (c *Client) CreateEnvGroup(group *ct.EnvGroup) error {
+	return c.Post("/env-groups", group, group)
+}
+
+// GetEnvGroup returns the env group with the given ID or name
+func (c *Client) GetEnvGroup(groupID string) (*ct.EnvGroup, error) {
+	group := &ct.EnvGroup{}
+	return group, c.Get(fmt.Sprintf("/env-groups/%s", groupID), group)
+}
+
+// UpdateEnvGroup sets the env and auto deploy setting of an env group,
+// returning the releases created for the apps it is attached to
+func (c *Client) UpdateEnvGroup(group *ct.EnvGroup) (*ct.EnvGroupUpdate, error) {
+	update := &ct.EnvGroupUpdate{}
+	return update, c.Put(fmt.Sprintf("/env-groups/%s", group.ID), group, update)
+}
+
+// DeleteEnvGroup deletes an env group, returning the releases created for the
+// apps it was attached to
+func (c *Client) DeleteEnvGroup(groupID string) (*ct.EnvGroupUpdate, error) {
+	update := &ct.EnvGroupUpdate{}
+	return update, c.Delete(fmt.Sprintf("/env-groups/%s", groupID), update)
+}
+
+// ListEnvGroups returns all env groups
+func (c *Client) ListEnvGroups() ([]*ct.EnvGroup, error) {
+	var groups []*ct.EnvGroup
+	return groups, c.Get("/env-groups", &groups)
+}
+
+// AppEnvGroupList returns the env groups attached to an app in order of
+// increasing precedence
+func (c *Client) AppEnvGroupList(appID string) ([]*ct.EnvGroup, error) {
+	var groups []*ct.EnvGroup
+	return groups, c.Get(fmt.Sprintf("/apps/%s/env-groups", appID), &groups)
+}
+
+// AttachEnvGroup attaches an env group to an app, returning the release
+// created for the app
+func (c *Client) AttachEnvGroup(appID, groupID string) (*ct.EnvGroupUpdate, error) {
+	update := &ct.EnvGroupUpdate{}
+	return update, c.Put(fmt.Sprintf("/apps/%s/env-groups/%s", appID, groupID), nil, update)
+}
+
+// DetachEnvGroup detaches an env group from an app, returning the release
+// created for the app
+func (c *Client) DetachEnvGroup(appID, groupID string) (*ct.EnvGroupUpdate, error) {
+	update := &ct.EnvGroupUpdate{}
+	return update, c.Delete(fmt.Sprintf("/apps/%s/env-groups/%s", appID, groupID), update)
+}
+
+// GetAppQuota returns the quota of an app along with its current usage
+func (c *Client) GetAppQuota(appID string) (*ct.AppQuota, error) {
+	quota := &ct.AppQuota{}
+	return quota, c.Get(fmt.Sprintf("/apps/%s/quota", appID), quota)
+}
+
+// UpdateAppQuota sets the quota of an app, with zero limits meaning no limit
+func (c *Client) UpdateAppQuota(quota *ct.AppQuota) error {
+	return c.Put(fmt.Sprintf("/apps/%s/quota", quota.AppID), quota, quota)
+}
+
+// ManifestDryRun returns the changes which applying the given manifest to
+// an app would make, without making them
+func (c *Client) ManifestDryRun(appID string, manifest *ct.AppManifest) (*ct.ManifestDiff, error) {
+	diff := &ct.ManifestDiff{}
+	return diff, c.Post(fmt.Sprintf("/apps/%s/manifest/dry-run", appID), manifest, diff)
+}
+
+// ApplyManifest applies the given manifest to an app, returning the changes
+// made along with the IDs of the release and deployment created
+func (c *Client) ApplyManifest(appID string, manifest *ct.AppManifest) (*ct.ManifestDiff, error) {
+	diff := &ct.ManifestDiff{}
+	return diff, c.Put(fmt.Sprintf("/apps/%s/manifest", appID), manifest, diff)
+}
+
+// PutHostDrain stores the status of a scheduler drain of a host
+func (c *Client) PutHostDrain(status *host.DrainStatus) error {
+	return c.Put(fmt.Sprintf("/host-drains/%s", status.HostID), status, status)
+}
+
+// HostDrainList returns the most recent drain of each host
+func (c *Client) HostDrainList() ([]*host.DrainStatus, error) {
+	var list []*host.DrainStatus
+	return list, c.Get("/host-drains", &list)
+}
+
 func (c *Client) Put(path string, in, out interface{}) error {
 	return c.send("PUT", path, in, out)
 }
@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net"
@@ -14,6 +15,7 @@ import (
 	"github.com/flynn/flynn/controller/data"
 	"github.com/flynn/flynn/controller/name"
 	"github.com/flynn/flynn/controller/schema"
+	"github.com/flynn/flynn/controller/secrets"
 	ct "github.com/flynn/flynn/controller/types"
 	"github.com/flynn/flynn/controller/utils"
 	discoverd "github.com/flynn/flynn/discoverd/client"
@@ -108,6 +110,16 @@ func main() {
 		grpcService.Close()
 	})
 
+	oidcTokens, err := oidcTokenStoreFromEnv()
+	if err != nil {
+		shutdown.Fatal(err)
+	}
+
+	keyring, err := secrets.ParseKeyring(os.Getenv("SECRETS_KEYS"))
+	if err != nil {
+		shutdown.Fatal(err)
+	}
+
 	handler, grpcServer, _ := appHandler(handlerConfig{
 		db:     db,
 		cc:     utils.ClusterClientWrapper(cluster.NewClient()),
@@ -115,6 +127,10 @@ func main() {
 		keys:   strings.Split(os.Getenv("AUTH_KEY"), ","),
 		keyIDs: strings.Split(os.Getenv("AUTH_KEY_IDS"), ","),
 		caCert: []byte(os.Getenv("CA_CERT")),
+		oidc:   oidcTokens,
+
+		auditLog: os.Getenv("AUDIT_LOG") == "true",
+		secrets:  keyring,
 	})
 	go grpcServer.Serve(grpcListener)
 	shutdown.Fatal(http.ListenAndServe(httpAddr, handler))
@@ -131,6 +147,15 @@ type handlerConfig struct {
 	keys   []string
 	keyIDs []string
 	caCert []byte
+	oidc   *oidcTokenStore
+
+	// auditLog enables logging request bodies and persisting audit
+	// records of requests which modify the cluster
+	auditLog bool
+
+	// secrets are the cluster keys used to encrypt secret release env
+	// vars (secret env vars are rejected if not set)
+	secrets *secrets.Keyring
 }
 
 // NOTE: this is temporary until httphelper supports custom errors
@@ -160,7 +185,7 @@ func appHandler(c handlerConfig) (http.Handler, *grpc.Server, *controllerAPI) {
 	routeRepo := data.NewRouteRepo(c.db)
 	appRepo := data.NewAppRepo(c.db, os.Getenv("DEFAULT_ROUTE_DOMAIN"), routeRepo)
 	artifactRepo := data.NewArtifactRepo(c.db)
-	releaseRepo := data.NewReleaseRepo(c.db, artifactRepo, q)
+	releaseRepo := data.NewReleaseRepo(c.db, artifactRepo, q, c.secrets)
 	jobRepo := data.NewJobRepo(c.db)
 	formationRepo := data.NewFormationRepo(c.db, appRepo, releaseRepo, artifactRepo)
 	deploymentRepo := data.NewDeploymentRepo(c.db, appRepo, releaseRepo, formationRepo)
@@ -168,6 +193,14 @@ func appHandler(c handlerConfig) (http.Handler, *grpc.Server, *controllerAPI) {
 	backupRepo := data.NewBackupRepo(c.db)
 	sinkRepo := data.NewSinkRepo(c.db)
 	volumeRepo := data.NewVolumeRepo(c.db)
+	scheduleRepo := data.NewScheduleRepo(c.db)
+	autoscaleRepo := data.NewAutoscaleRepo(c.db)
+	tokenRepo := data.NewTokenRepo(c.db, appRepo)
+	auditRepo := data.NewAuditRepo(c.db)
+	webhookRepo := data.NewWebhookRepo(c.db, appRepo)
+	envGroupRepo := data.NewEnvGroupRepo(c.db)
+	quotaRepo := data.NewQuotaRepo(c.db, formationRepo, releaseRepo, routeRepo)
+	hostDrainRepo := data.NewHostDrainRepo(c.db)
 
 	api := controllerAPI{
 		domainMigrationRepo: domainMigrationRepo,
@@ -184,12 +217,20 @@ func appHandler(c handlerConfig) (http.Handler, *grpc.Server, *controllerAPI) {
 		backupRepo:          backupRepo,
 		sinkRepo:            sinkRepo,
 		volumeRepo:          volumeRepo,
+		scheduleRepo:        scheduleRepo,
+		autoscaleRepo:       autoscaleRepo,
+		tokenRepo:           tokenRepo,
+		auditRepo:           auditRepo,
+		webhookRepo:         webhookRepo,
+		envGroupRepo:        envGroupRepo,
+		quotaRepo:           quotaRepo,
+		hostDrainRepo:       hostDrainRepo,
 		clusterClient:       c.cc,
 		logaggc:             c.lc,
 		que:                 q,
 		caCert:              c.caCert,
 		config:              c,
-		authorizer:          utils.NewAuthorizer(c.keys, c.keyIDs),
+		authorizer:          newAuthorizer(c, tokenRepo),
 	}
 
 	shutdown.BeforeExit(api.Shutdown)
@@ -227,6 +268,7 @@ func appHandler(c handlerConfig) (http.Handler, *grpc.Server, *controllerAPI) {
 	httpRouter.GET("/formations", httphelper.WrapHandler(api.GetFormations))
 
 	httpRouter.PUT("/apps/:apps_id/scale/:releases_id", httphelper.WrapHandler(api.appLookup(api.PutScaleRequest)))
+	httpRouter.POST("/apps/:apps_id/scale/:releases_id/dry-run", httphelper.WrapHandler(api.appLookup(api.ScaleDryRun)))
 
 	httpRouter.POST("/apps/:apps_id/jobs", httphelper.WrapHandler(api.appLookup(api.RunJob)))
 	httpRouter.GET("/apps/:apps_id/jobs/:jobs_id", httphelper.WrapHandler(api.GetJob))
@@ -234,10 +276,16 @@ func appHandler(c handlerConfig) (http.Handler, *grpc.Server, *controllerAPI) {
 	httpRouter.GET("/apps/:apps_id/jobs", httphelper.WrapHandler(api.appLookup(api.ListJobs)))
 	httpRouter.DELETE("/apps/:apps_id/jobs/:jobs_id", httphelper.WrapHandler(api.KillJob))
 	httpRouter.GET("/active-jobs", httphelper.WrapHandler(api.ListActiveJobs))
+	httpRouter.GET("/jobs", httphelper.WrapHandler(api.ListJobsSince))
 
 	httpRouter.POST("/apps/:apps_id/deploy", httphelper.WrapHandler(api.appLookup(api.CreateDeployment)))
 	httpRouter.GET("/apps/:apps_id/deployments", httphelper.WrapHandler(api.appLookup(api.ListDeployments)))
 	httpRouter.GET("/deployments/:deployment_id", httphelper.WrapHandler(api.GetDeployment))
+	httpRouter.POST("/deployments/:deployment_id/pause", httphelper.WrapHandler(api.PauseDeployment))
+	httpRouter.POST("/deployments/:deployment_id/resume", httphelper.WrapHandler(api.ResumeDeployment))
+	httpRouter.POST("/deployments/:deployment_id/abort", httphelper.WrapHandler(api.AbortDeployment))
+	httpRouter.POST("/deployments/:deployment_id/approve", httphelper.WrapHandler(api.ApproveDeployment))
+	httpRouter.POST("/deployments/:deployment_id/reject", httphelper.WrapHandler(api.RejectDeployment))
 
 	httpRouter.PUT("/apps/:apps_id/release", httphelper.WrapHandler(api.appLookup(api.SetAppRelease)))
 	httpRouter.GET("/apps/:apps_id/release", httphelper.WrapHandler(api.appLookup(api.GetAppRelease)))
@@ -271,26 +319,81 @@ func appHandler(c handlerConfig) (http.Handler, *grpc.Server, *controllerAPI) {
 	httpRouter.GET("/apps/:apps_id/volumes/:volume_id", httphelper.WrapHandler(api.appLookup(api.GetVolume)))
 	httpRouter.PUT("/apps/:apps_id/volumes/:volume_id/decommission", httphelper.WrapHandler(api.appLookup(api.DecommissionVolume)))
 
+	httpRouter.POST("/apps/:apps_id/schedules", httphelper.WrapHandler(api.appLookup(api.CreateSchedule)))
+	httpRouter.GET("/apps/:apps_id/schedules", httphelper.WrapHandler(api.appLookup(api.ListSchedules)))
+	httpRouter.GET("/apps/:apps_id/schedules/:schedule_id", httphelper.WrapHandler(api.appLookup(api.GetSchedule)))
+	httpRouter.DELETE("/apps/:apps_id/schedules/:schedule_id", httphelper.WrapHandler(api.appLookup(api.DeleteSchedule)))
+	httpRouter.GET("/apps/:apps_id/schedules/:schedule_id/runs", httphelper.WrapHandler(api.appLookup(api.ListScheduleRuns)))
+
+	httpRouter.GET("/apps/:apps_id/autoscale", httphelper.WrapHandler(api.appLookup(api.ListAutoscalePolicies)))
+	httpRouter.PUT("/apps/:apps_id/autoscale/:process_type", httphelper.WrapHandler(api.appLookup(api.PutAutoscalePolicy)))
+	httpRouter.GET("/apps/:apps_id/autoscale/:process_type", httphelper.WrapHandler(api.appLookup(api.GetAutoscalePolicy)))
+	httpRouter.DELETE("/apps/:apps_id/autoscale/:process_type", httphelper.WrapHandler(api.appLookup(api.DeleteAutoscalePolicy)))
+
 	httpRouter.POST("/sinks", httphelper.WrapHandler(api.CreateSink))
 	httpRouter.GET("/sinks", httphelper.WrapHandler(api.GetSinks))
 	httpRouter.GET("/sinks/:sink_id", httphelper.WrapHandler(api.GetSink))
 	httpRouter.DELETE("/sinks/:sink_id", httphelper.WrapHandler(api.DeleteSink))
 
+	httpRouter.POST("/tokens", httphelper.WrapHandler(api.CreateToken))
+	httpRouter.GET("/tokens", httphelper.WrapHandler(api.GetTokens))
+	httpRouter.DELETE("/tokens/:token_id", httphelper.WrapHandler(api.DeleteToken))
+
+	httpRouter.GET("/audit", httphelper.WrapHandler(api.GetAuditLog))
+
+	httpRouter.POST("/webhooks", httphelper.WrapHandler(api.CreateWebhook))
+	httpRouter.GET("/webhooks", httphelper.WrapHandler(api.GetWebhooks))
+	httpRouter.GET("/webhooks/:webhook_id", httphelper.WrapHandler(api.GetWebhook))
+	httpRouter.DELETE("/webhooks/:webhook_id", httphelper.WrapHandler(api.DeleteWebhook))
+	httpRouter.GET("/webhooks/:webhook_id/deliveries", httphelper.WrapHandler(api.GetWebhookDeliveries))
+
+	httpRouter.POST("/env-groups", httphelper.WrapHandler(api.CreateEnvGroup))
+	httpRouter.GET("/env-groups", httphelper.WrapHandler(api.GetEnvGroups))
+	httpRouter.GET("/env-groups/:env_group_id", httphelper.WrapHandler(api.GetEnvGroup))
+	httpRouter.PUT("/env-groups/:env_group_id", httphelper.WrapHandler(api.UpdateEnvGroup))
+	httpRouter.DELETE("/env-groups/:env_group_id", httphelper.WrapHandler(api.DeleteEnvGroup))
+	httpRouter.GET("/apps/:apps_id/env-groups", httphelper.WrapHandler(api.appLookup(api.GetAppEnvGroups)))
+	httpRouter.PUT("/apps/:apps_id/env-groups/:env_group_id", httphelper.WrapHandler(api.appLookup(api.AttachEnvGroup)))
+	httpRouter.DELETE("/apps/:apps_id/env-groups/:env_group_id", httphelper.WrapHandler(api.appLookup(api.DetachEnvGroup)))
+
+	httpRouter.GET("/apps/:apps_id/quota", httphelper.WrapHandler(api.appLookup(api.GetAppQuota)))
+	httpRouter.PUT("/apps/:apps_id/quota", httphelper.WrapHandler(api.appLookup(api.PutAppQuota)))
+
+	httpRouter.PUT("/apps/:apps_id/manifest", httphelper.WrapHandler(api.appLookup(api.ApplyManifest)))
+	httpRouter.POST("/apps/:apps_id/manifest/dry-run", httphelper.WrapHandler(api.appLookup(api.ManifestDryRun)))
+
+	httpRouter.GET("/host-drains", httphelper.WrapHandler(api.GetHostDrains))
+	httpRouter.PUT("/host-drains/:host_id", httphelper.WrapHandler(api.PutHostDrain))
+
 	grpcAPI := &grpcAPI{&api, c.db}
 	grpcSrv := grpcAPI.grpcServer()
 
-	handler := muxHandler(httpRouter, grpcSrv, api.authorizer)
-	if os.Getenv("AUDIT_LOG") == "true" {
-		handler = httphelper.NewRequestLoggerCustom(handler, auditLoggerFn)
+	handler := muxHandler(httpRouter, grpcSrv, &api)
+	if c.auditLog {
+		handler = httphelper.NewRequestLoggerCustom(handler, api.auditLogger)
 	} else {
 		handler = httphelper.NewRequestLogger(handler)
 	}
 	return httphelper.ContextInjector("controller", handler), grpcSrv, &api
 }
 
-func muxHandler(main http.Handler, grpcSrv *grpc.Server, authorizer *utils.Authorizer) http.Handler {
-	grpcWeb := grpcweb.WrapServer(grpcSrv)
+// ctxKeyAuthorization is the request context key of the authorization of
+// requests which muxHandler has authenticated
+const ctxKeyAuthorization = "authorization"
+
+func muxHandler(main http.Handler, grpcSrv *grpc.Server, api *controllerAPI) http.Handler {
+	// grpcweb streams are also served over websockets so that browsers can
+	// use client streaming, with the origin being allowed in the same way as
+	// CORS requests
+	grpcWeb := grpcweb.WrapServer(grpcSrv,
+		grpcweb.WithWebsockets(true),
+		grpcweb.WithWebsocketOriginFunc(func(*http.Request) bool { return true }),
+	)
 	return httphelper.CORSAllowAll.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the key ID header is only ever set from the request's
+		// authorization below, so don't trust one sent by the client
+		r.Header.Del("Flynn-Auth-Key-ID")
+
 		if shutdown.IsActive() {
 			httphelper.ServiceUnavailableError(w, ErrShutdown.Error())
 			return
@@ -302,12 +405,12 @@ func muxHandler(main http.Handler, grpcSrv *grpc.Server, authorizer *utils.Autho
 		}
 
 		// handle grpcweb requests before auth (grpc does its own auth)
-		if grpcWeb.IsGrpcWebRequest(r) {
+		if grpcWeb.IsGrpcWebRequest(r) || grpcWeb.IsGrpcWebSocketRequest(r) {
 			grpcWeb.ServeHTTP(w, r)
 			return
 		}
 
-		_, password, _ := r.BasicAuth()
+		password := requestKey(r)
 		if password == "" && r.URL.Path == "/ca-cert" {
 			main.ServeHTTP(w, r)
 			return
@@ -315,14 +418,34 @@ func muxHandler(main http.Handler, grpcSrv *grpc.Server, authorizer *utils.Autho
 		if password == "" && (strings.Contains(r.Header.Get("Accept"), "text/event-stream") || r.URL.Path == "/backup") {
 			password = r.URL.Query().Get("key")
 		}
-		auth, err := authorizer.Authorize(password)
+		auth, err := api.authorizer.Authorize(password)
 		if err != nil {
 			w.WriteHeader(401)
 			return
 		}
+		if auth.Token != nil {
+			perm := api.requestPermission(r, len(auth.Token.Apps) > 0)
+			if !auth.Allows(perm) {
+				httphelper.Error(w, httphelper.JSONError{
+					Code:    httphelper.ForbiddenErrorCode,
+					Message: fmt.Sprintf("token %q is not permitted to %s %s", auth.Token.Name, r.Method, r.URL.Path),
+				})
+				return
+			}
+		}
 		if auth.ID != "" {
 			r.Header.Set("Flynn-Auth-Key-ID", auth.ID)
 		}
+		// the audit record is populated from the authenticated request
+		// before it is handled, as the handler may consume the body or
+		// delete the app
+		if audit, ok := r.Context().Value(ctxKeyAudit).(*auditRequest); ok {
+			audit.KeyID = auth.ID
+			if isAuditedRequest(r) {
+				audit.AppID = api.auditAppID(r)
+			}
+		}
+		r = r.WithContext(context.WithValue(r.Context(), ctxKeyAuthorization, auth))
 		main.ServeHTTP(w, r)
 	}))
 }
@@ -342,6 +465,14 @@ type controllerAPI struct {
 	backupRepo          *data.BackupRepo
 	sinkRepo            *data.SinkRepo
 	volumeRepo          *data.VolumeRepo
+	scheduleRepo        *data.ScheduleRepo
+	autoscaleRepo       *data.AutoscaleRepo
+	tokenRepo           *data.TokenRepo
+	auditRepo           *data.AuditRepo
+	webhookRepo         *data.WebhookRepo
+	envGroupRepo        *data.EnvGroupRepo
+	quotaRepo           *data.QuotaRepo
+	hostDrainRepo       *data.HostDrainRepo
 	clusterClient       utils.ClusterClient
 	logaggc             logClient
 	que                 *que.Client
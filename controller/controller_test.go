@@ -9,9 +9,11 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	controller "github.com/flynn/flynn/controller/client"
 	"github.com/flynn/flynn/controller/data"
+	"github.com/flynn/flynn/controller/secrets"
 	tu "github.com/flynn/flynn/controller/testutils"
 	ct "github.com/flynn/flynn/controller/types"
 	"github.com/flynn/flynn/controller/utils"
@@ -91,12 +93,15 @@ func (s *S) SetUpSuite(c *C) {
 
 	s.flac = newFakeLogAggregatorClient()
 	s.cc = tu.NewFakeCluster()
+	keyring, err := secrets.ParseKeyring("test:" + strings.Repeat("ab", 32))
+	c.Assert(err, IsNil)
 	s.hc = handlerConfig{
-		db:     db,
-		cc:     s.cc,
-		lc:     s.flac,
-		keys:   []string{authKey},
-		caCert: s.caCert,
+		db:      db,
+		cc:      s.cc,
+		lc:      s.flac,
+		keys:    []string{authKey},
+		caCert:  s.caCert,
+		secrets: keyring,
 	}
 	handler, _, _ := appHandler(s.hc)
 	s.srv = httptest.NewServer(handler)
@@ -337,6 +342,38 @@ func (s *S) TestCreateRelease(c *C) {
 	}
 }
 
+func (s *S) TestCreateReleaseWithSecrets(c *C) {
+	app := s.createTestApp(c, &ct.App{})
+	release := s.createTestRelease(c, app.ID, &ct.Release{
+		Env:     map[string]string{"PASSWORD": "s3cr3t", "PORT": "8080"},
+		Secrets: []string{"PASSWORD"},
+	})
+	c.Assert(release.Secrets, DeepEquals, []string{"PASSWORD"})
+	c.Assert(secrets.IsEncrypted(release.Env["PASSWORD"]), Equals, true)
+	c.Assert(release.Env["PORT"], Equals, "8080")
+	c.Assert(s.c.SetAppRelease(app.ID, release.ID), IsNil)
+
+	// secrets are only decrypted when explicitly requested
+	gotRelease, err := s.c.GetAppRelease(app.ID)
+	c.Assert(err, IsNil)
+	c.Assert(gotRelease.Env["PASSWORD"], Equals, release.Env["PASSWORD"])
+	gotRelease, err = s.c.GetAppReleaseWithSecrets(app.ID)
+	c.Assert(err, IsNil)
+	c.Assert(gotRelease.Env["PASSWORD"], Equals, "s3cr3t")
+
+	// encrypted values can be copied into new releases of the same app
+	copied := s.createTestRelease(c, app.ID, &ct.Release{Env: map[string]string{"PASSWORD": release.Env["PASSWORD"]}})
+	c.Assert(copied.Secrets, DeepEquals, []string{"PASSWORD"})
+
+	// but not into releases of other apps
+	other := s.createTestApp(c, &ct.App{})
+	err = s.c.CreateRelease(other.ID, &ct.Release{
+		ArtifactIDs: release.ArtifactIDs,
+		Env:         map[string]string{"PASSWORD": release.Env["PASSWORD"]},
+	})
+	c.Assert(hh.IsValidationError(err), Equals, true)
+}
+
 func (s *S) TestCreateFormation(c *C) {
 	for i, useName := range []bool{false, true} {
 		release := s.createTestRelease(c, "", &ct.Release{
@@ -695,6 +732,171 @@ func (s *S) TestCreateSink(c *C) {
 	c.Assert(outConfig, DeepEquals, config)
 }
 
+func (s *S) TestSchedules(c *C) {
+	app := s.createTestApp(c, &ct.App{Name: "schedules"})
+	release := s.createTestRelease(c, app.ID, &ct.Release{})
+
+	// check invalid schedules are rejected
+	for _, in := range []*ct.Schedule{
+		{AppID: app.ID, Cron: "* * *", Args: []string{"true"}},
+		{AppID: app.ID, Cron: "0 0 30 2 *", Args: []string{"true"}},
+		{AppID: app.ID, Cron: "@daily", Args: []string{"true"}, Concurrency: "sometimes"},
+		{AppID: app.ID, Cron: "@daily", Args: []string{"true"}, ReleaseID: random.UUID()},
+	} {
+		err := s.c.CreateSchedule(in)
+		c.Assert(err, NotNil)
+		c.Assert(hh.IsValidationError(err), Equals, true)
+	}
+
+	in := &ct.Schedule{
+		AppID:     app.ID,
+		Cron:      "*/15 * * * *",
+		ReleaseID: release.ID,
+		Args:      []string{"bin/sync", "--all"},
+		Env:       map[string]string{"FOO": "bar"},
+	}
+	c.Assert(s.c.CreateSchedule(in), IsNil)
+	c.Assert(in.ID, Not(Equals), "")
+	c.Assert(in.Concurrency, Equals, ct.ScheduleConcurrencyAllow)
+	c.Assert(in.NextRunAt, NotNil)
+	c.Assert(in.NextRunAt.Minute()%15, Equals, 0)
+
+	out, err := s.c.GetSchedule(app.ID, in.ID)
+	c.Assert(err, IsNil)
+	c.Assert(out.Cron, Equals, in.Cron)
+	c.Assert(out.ReleaseID, Equals, release.ID)
+	c.Assert(out.Args, DeepEquals, in.Args)
+	c.Assert(out.Env, DeepEquals, in.Env)
+
+	list, err := s.c.ScheduleList(app.ID)
+	c.Assert(err, IsNil)
+	c.Assert(list, HasLen, 1)
+	c.Assert(list[0].ID, Equals, in.ID)
+
+	runs, err := s.c.ScheduleRunList(app.ID, in.ID)
+	c.Assert(err, IsNil)
+	c.Assert(runs, HasLen, 0)
+
+	_, err = s.c.DeleteSchedule(app.ID, in.ID)
+	c.Assert(err, IsNil)
+	_, err = s.c.GetSchedule(app.ID, in.ID)
+	c.Assert(err, Equals, controller.ErrNotFound)
+	list, err = s.c.ScheduleList(app.ID)
+	c.Assert(err, IsNil)
+	c.Assert(list, HasLen, 0)
+}
+
+func (s *S) TestScheduleLaunchLastJob(c *C) {
+	app := s.createTestApp(c, &ct.App{Name: "schedule-last-job"})
+	release := s.createTestRelease(c, app.ID, &ct.Release{})
+	schedule := &ct.Schedule{
+		AppID:       app.ID,
+		Cron:        "* * * * *",
+		ReleaseID:   release.ID,
+		Args:        []string{"bin/sync"},
+		Concurrency: ct.ScheduleConcurrencyForbid,
+	}
+	c.Assert(s.c.CreateSchedule(schedule), IsNil)
+	defer s.c.DeleteSchedule(app.ID, schedule.ID)
+
+	repo := data.NewScheduleRepo(s.hc.db)
+	launchDue := func(launch data.ScheduleLauncher) *ct.ScheduleRun {
+		c.Assert(s.hc.db.Exec("UPDATE schedules SET next_run_at = now() - interval '1 minute' WHERE schedule_id = $1", schedule.ID), IsNil)
+		run, err := repo.LaunchNextDue(launch)
+		c.Assert(err, IsNil)
+		c.Assert(run.ScheduleID, Equals, schedule.ID)
+		return run
+	}
+
+	// the first run starts a job which is still running when the next
+	// two runs are due
+	jobID := random.UUID()
+	launchDue(func(_ *ct.Schedule, lastRun *ct.ScheduleRun) *ct.ScheduleRun {
+		c.Assert(lastRun, IsNil)
+		return &ct.ScheduleRun{ReleaseID: release.ID, JobID: jobID, State: ct.ScheduleRunStateStarted}
+	})
+	launchDue(func(_ *ct.Schedule, lastRun *ct.ScheduleRun) *ct.ScheduleRun {
+		c.Assert(lastRun, NotNil)
+		c.Assert(lastRun.JobID, Equals, jobID)
+		return &ct.ScheduleRun{ReleaseID: release.ID, State: ct.ScheduleRunStateSkipped}
+	})
+
+	// a pending run left behind by an interrupted launch is failed
+	c.Assert(s.hc.db.Exec("schedule_run_insert", random.UUID(), schedule.ID, app.ID, nil, nil, string(ct.ScheduleRunStatePending), nil, time.Now()), IsNil)
+
+	// the third run is given the first run's job rather than the skipped
+	// or pending runs, so it is skipped too
+	launchDue(func(_ *ct.Schedule, lastRun *ct.ScheduleRun) *ct.ScheduleRun {
+		c.Assert(lastRun, NotNil)
+		c.Assert(lastRun.JobID, Equals, jobID)
+		return &ct.ScheduleRun{ReleaseID: release.ID, State: ct.ScheduleRunStateSkipped}
+	})
+
+	runs, err := s.c.ScheduleRunList(app.ID, schedule.ID)
+	c.Assert(err, IsNil)
+	c.Assert(runs, HasLen, 4)
+	states := make([]ct.ScheduleRunState, len(runs))
+	for i, run := range runs {
+		states[i] = run.State
+	}
+	c.Assert(states, DeepEquals, []ct.ScheduleRunState{
+		ct.ScheduleRunStateSkipped,
+		ct.ScheduleRunStateFailed,
+		ct.ScheduleRunStateSkipped,
+		ct.ScheduleRunStateStarted,
+	})
+	c.Assert(runs[1].Error, Equals, data.ErrScheduleRunInterrupted.Error())
+}
+
+func (s *S) TestAutoscalePolicies(c *C) {
+	app := s.createTestApp(c, &ct.App{Name: "autoscale"})
+	release := s.createTestRelease(c, app.ID, &ct.Release{
+		Processes: map[string]ct.ProcessType{"web": {}},
+	})
+	s.setAppRelease(c, app.ID, release.ID)
+
+	// check invalid policies are rejected
+	for _, in := range []*ct.AutoscalePolicy{
+		{AppID: app.ID, ProcessType: "worker", Max: 2, TargetCPU: 50},
+		{AppID: app.ID, ProcessType: "web", Min: 3, Max: 2, TargetCPU: 50},
+		{AppID: app.ID, ProcessType: "web", Max: 2},
+		{AppID: app.ID, ProcessType: "web", Max: 2, TargetMemory: 150},
+	} {
+		err := s.c.PutAutoscalePolicy(in)
+		c.Assert(err, NotNil)
+		c.Assert(hh.IsValidationError(err), Equals, true)
+	}
+
+	in := &ct.AutoscalePolicy{
+		AppID:                   app.ID,
+		ProcessType:             "web",
+		Min:                     1,
+		Max:                     5,
+		TargetRequestsPerSecond: 20,
+	}
+	c.Assert(s.c.PutAutoscalePolicy(in), IsNil)
+	c.Assert(in.ScaleUpCooldown, Equals, ct.DefaultAutoscaleScaleUpCooldown)
+	c.Assert(in.ScaleDownCooldown, Equals, ct.DefaultAutoscaleScaleDownCooldown)
+
+	// check putting the policy again updates it
+	in.TargetCPU = 70
+	c.Assert(s.c.PutAutoscalePolicy(in), IsNil)
+	out, err := s.c.GetAutoscalePolicy(app.ID, "web")
+	c.Assert(err, IsNil)
+	c.Assert(out.Max, Equals, 5)
+	c.Assert(out.TargetRequestsPerSecond, Equals, float64(20))
+	c.Assert(out.TargetCPU, Equals, 70)
+
+	list, err := s.c.AutoscalePolicyList(app.ID)
+	c.Assert(err, IsNil)
+	c.Assert(list, HasLen, 1)
+
+	_, err = s.c.DeleteAutoscalePolicy(app.ID, "web")
+	c.Assert(err, IsNil)
+	_, err = s.c.GetAutoscalePolicy(app.ID, "web")
+	c.Assert(err, Equals, controller.ErrNotFound)
+}
+
 func (s *S) TestGetCACertWithAuth(c *C) {
 	cert, err := s.c.GetCACert()
 	c.Assert(err, IsNil)
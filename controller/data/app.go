@@ -60,6 +60,7 @@ func (r *AppRepo) Add(data interface{}) error {
 	if _, ok := app.Meta["gc.max_inactive_slug_releases"]; !ok {
 		app.Meta["gc.max_inactive_slug_releases"] = "10"
 	}
+	setDeployApprovalSecret(app.Meta)
 
 	if err := tx.QueryRow("app_insert", app.ID, app.Name, app.Meta, app.Strategy, app.DeployTimeout).Scan(&app.CreatedAt, &app.UpdatedAt); err != nil {
 		tx.Rollback()
@@ -131,6 +132,14 @@ func selectApp(db rowQueryer, id string, update bool) (*ct.App, error) {
 	return scanApp(row)
 }
 
+// setDeployApprovalSecret generates the secret used to sign deploy gate
+// approval requests if the meta sets a webhook without one
+func setDeployApprovalSecret(meta map[string]string) {
+	if meta["flynn-deploy-approval-webhook"] != "" && meta["flynn-deploy-approval-secret"] == "" {
+		meta["flynn-deploy-approval-secret"] = random.Hex(32)
+	}
+}
+
 func (r *AppRepo) Get(id string) (interface{}, error) {
 	return r.TxGet(r.db, id)
 }
@@ -181,6 +190,7 @@ func (r *AppRepo) Update(id string, data map[string]interface{}) (interface{}, e
 					data[k] = s
 				}
 			}
+			setDeployApprovalSecret(data)
 			app.Meta = data
 			if err := tx.Exec("app_update_meta", app.ID, app.Meta); err != nil {
 				tx.Rollback()
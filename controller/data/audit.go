@@ -0,0 +1,128 @@
+package data
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/pkg/postgres"
+)
+
+type AuditRepo struct {
+	db *postgres.DB
+}
+
+func NewAuditRepo(db *postgres.DB) *AuditRepo {
+	return &AuditRepo{db: db}
+}
+
+// Add persists the audit record and emits an audit event so that records can
+// be streamed via the events API (which only includes audit events for
+// callers which can read the audit log)
+func (r *AuditRepo) Add(a *ct.AuditRecord) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := tx.QueryRow(
+		"audit_insert",
+		nullString(a.KeyID),
+		a.Method,
+		a.Path,
+		nullString(a.AppID),
+		nullString(a.Body),
+		a.Status,
+		nullString(a.ClientIP),
+		nullString(a.UserAgent),
+	).Scan(&a.ID, &a.CreatedAt); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := CreateEvent(tx.Exec, &ct.Event{
+		AppID:      a.AppID,
+		ObjectID:   strconv.FormatInt(a.ID, 10),
+		ObjectType: ct.EventTypeAudit,
+	}, a); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// List returns the audit records matching the given options, most recent
+// first
+func (r *AuditRepo) List(opts ct.ListAuditOptions) ([]*ct.AuditRecord, error) {
+	query := "SELECT audit_id, key_id, method, path, app_id, body, status, client_ip, user_agent, created_at FROM audit_log"
+	var conditions []string
+	var args []interface{}
+	addCondition := func(format string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+	if opts.AppID != "" {
+		addCondition("app_id = $%d", opts.AppID)
+	}
+	if opts.KeyID != "" {
+		addCondition("key_id = $%d", opts.KeyID)
+	}
+	if opts.Since != nil {
+		addCondition("created_at >= $%d", *opts.Since)
+	}
+	if opts.Before != nil {
+		addCondition("created_at < $%d", *opts.Before)
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY audit_id DESC"
+	if opts.Count > 0 {
+		args = append(args, opts.Count)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var records []*ct.AuditRecord
+	for rows.Next() {
+		record, err := scanAuditRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+func scanAuditRecord(s postgres.Scanner) (*ct.AuditRecord, error) {
+	a := &ct.AuditRecord{}
+	var keyID, appID, body, clientIP, userAgent *string
+	if err := s.Scan(&a.ID, &keyID, &a.Method, &a.Path, &appID, &body, &a.Status, &clientIP, &userAgent, &a.CreatedAt); err != nil {
+		return nil, err
+	}
+	for _, f := range []struct {
+		dst *string
+		src *string
+	}{
+		{&a.KeyID, keyID},
+		{&a.AppID, appID},
+		{&a.Body, body},
+		{&a.ClientIP, clientIP},
+		{&a.UserAgent, userAgent},
+	} {
+		if f.src != nil {
+			*f.dst = *f.src
+		}
+	}
+	return a, nil
+}
+
+// nullString returns nil for an empty string so that it is stored as NULL
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
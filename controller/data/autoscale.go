@@ -0,0 +1,216 @@
+package data
+
+import (
+	"time"
+
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/pkg/postgres"
+	"github.com/jackc/pgx"
+)
+
+type AutoscaleRepo struct {
+	db *postgres.DB
+}
+
+func NewAutoscaleRepo(db *postgres.DB) *AutoscaleRepo {
+	return &AutoscaleRepo{db}
+}
+
+func validateAutoscalePolicy(p *ct.AutoscalePolicy) error {
+	if p.ProcessType == "" {
+		return ct.ValidationError{Field: "process_type", Message: "must not be empty"}
+	}
+	if p.Min < 0 {
+		return ct.ValidationError{Field: "min", Message: "must not be negative"}
+	}
+	if p.Max < 1 || p.Max < p.Min {
+		return ct.ValidationError{Field: "max", Message: "must be at least 1 and not less than min"}
+	}
+	if p.TargetRequestsPerSecond == 0 && p.TargetCPU == 0 && p.TargetMemory == 0 {
+		return ct.ValidationError{Message: "at least one of target_requests_per_second, target_cpu or target_memory must be set"}
+	}
+	if p.TargetRequestsPerSecond < 0 {
+		return ct.ValidationError{Field: "target_requests_per_second", Message: "must not be negative"}
+	}
+	if p.TargetCPU < 0 || p.TargetCPU > 100 {
+		return ct.ValidationError{Field: "target_cpu", Message: "must be a percentage between 1 and 100"}
+	}
+	if p.TargetMemory < 0 || p.TargetMemory > 100 {
+		return ct.ValidationError{Field: "target_memory", Message: "must be a percentage between 1 and 100"}
+	}
+	if p.ScaleUpCooldown < 0 {
+		return ct.ValidationError{Field: "scale_up_cooldown", Message: "must not be negative"}
+	} else if p.ScaleUpCooldown == 0 {
+		p.ScaleUpCooldown = ct.DefaultAutoscaleScaleUpCooldown
+	}
+	if p.ScaleDownCooldown < 0 {
+		return ct.ValidationError{Field: "scale_down_cooldown", Message: "must not be negative"}
+	} else if p.ScaleDownCooldown == 0 {
+		p.ScaleDownCooldown = ct.DefaultAutoscaleScaleDownCooldown
+	}
+	return nil
+}
+
+// Put creates or updates the autoscale policy for p.ProcessType in p.AppID
+func (r *AutoscaleRepo) Put(p *ct.AutoscalePolicy) error {
+	if err := validateAutoscalePolicy(p); err != nil {
+		return err
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := tx.QueryRow(
+		"autoscale_policy_upsert",
+		p.AppID,
+		p.ProcessType,
+		p.Min,
+		p.Max,
+		p.TargetRequestsPerSecond,
+		p.TargetCPU,
+		p.TargetMemory,
+		p.ScaleUpCooldown,
+		p.ScaleDownCooldown,
+	).Scan(&p.LastScaledAt, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := CreateEvent(tx.Exec, &ct.Event{
+		AppID:      p.AppID,
+		ObjectID:   autoscaleObjectID(p),
+		ObjectType: ct.EventTypeAutoscalePolicy,
+	}, p); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func autoscaleObjectID(p *ct.AutoscalePolicy) string {
+	return p.AppID + ":" + p.ProcessType
+}
+
+func scanAutoscalePolicy(s postgres.Scanner) (*ct.AutoscalePolicy, error) {
+	p := &ct.AutoscalePolicy{}
+	err := s.Scan(
+		&p.AppID,
+		&p.ProcessType,
+		&p.Min,
+		&p.Max,
+		&p.TargetRequestsPerSecond,
+		&p.TargetCPU,
+		&p.TargetMemory,
+		&p.ScaleUpCooldown,
+		&p.ScaleDownCooldown,
+		&p.LastScaledAt,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		err = ErrNotFound
+	}
+	return p, err
+}
+
+func (r *AutoscaleRepo) Get(appID, processType string) (*ct.AutoscalePolicy, error) {
+	return scanAutoscalePolicy(r.db.QueryRow("autoscale_policy_select", appID, processType))
+}
+
+func (r *AutoscaleRepo) List(appID string) ([]*ct.AutoscalePolicy, error) {
+	rows, err := r.db.Query("autoscale_policy_list", appID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var policies []*ct.AutoscalePolicy
+	for rows.Next() {
+		p, err := scanAutoscalePolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+func (r *AutoscaleRepo) Remove(appID, processType string) (*ct.AutoscalePolicy, error) {
+	p, err := r.Get(appID, processType)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Exec("autoscale_policy_delete", appID, processType); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := CreateEvent(tx.Exec, &ct.Event{
+		AppID:      p.AppID,
+		ObjectID:   autoscaleObjectID(p),
+		ObjectType: ct.EventTypeAutoscalePolicyDeletion,
+	}, p); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return p, tx.Commit()
+}
+
+// AutoscaleEvaluator evaluates an autoscale policy, scaling the process type
+// if necessary and returning an event explaining the decision, or nil if the
+// process type was left alone
+type AutoscaleEvaluator func(policy *ct.AutoscalePolicy) *ct.AutoscaleEvent
+
+// EvaluateNextDue claims the autoscale policy which has gone the longest
+// without being evaluated (provided it has not been evaluated within the
+// given interval), evaluates it using the given function and records any
+// resulting event, returning ErrNotFound if no policies are due.
+//
+// The policy is claimed by marking it as evaluated in a short transaction
+// which skips locked policies, so that multiple evaluators can run
+// concurrently without scaling the same process type twice, and the
+// decision is recorded in a second transaction once evaluated so that the
+// policy row is not locked whilst the evaluator talks to the cluster.
+func (r *AutoscaleRepo) EvaluateNextDue(interval time.Duration, evaluate AutoscaleEvaluator) (*ct.AutoscaleEvent, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	policy, err := scanAutoscalePolicy(tx.QueryRow("autoscale_policy_select_due", interval.Seconds()))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Exec("autoscale_policy_update_evaluated", policy.AppID, policy.ProcessType, false); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	event := evaluate(policy)
+	if event == nil {
+		return nil, nil
+	}
+
+	tx, err = r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	scaled := event.Error == "" && event.From != event.To
+	if err := tx.Exec("autoscale_policy_update_evaluated", policy.AppID, policy.ProcessType, scaled); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := CreateEvent(tx.Exec, &ct.Event{
+		AppID:      policy.AppID,
+		ObjectID:   autoscaleObjectID(policy),
+		ObjectType: ct.EventTypeAutoscale,
+	}, event); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return event, tx.Commit()
+}
@@ -27,7 +27,14 @@ func NewDeploymentRepo(db *postgres.DB, appRepo *AppRepo, releaseRepo *ReleaseRe
 }
 
 func (r *DeploymentRepo) Add(appID, releaseID string) (*ct.Deployment, error) {
-	ed, err := r.AddExpanded(appID, releaseID)
+	return r.AddWithProcesses(appID, releaseID, nil)
+}
+
+// AddWithProcesses is like Add but deploys the given number of each process
+// type rather than the number in the app's current formation (if processes
+// is not nil)
+func (r *DeploymentRepo) AddWithProcesses(appID, releaseID string, processes map[string]int) (*ct.Deployment, error) {
+	ed, err := r.addExpanded(appID, releaseID, processes)
 	if err != nil {
 		return nil, err
 	}
@@ -51,6 +58,10 @@ func (r *DeploymentRepo) Add(appID, releaseID string) (*ct.Deployment, error) {
 }
 
 func (r *DeploymentRepo) AddExpanded(appID, releaseID string) (*ct.ExpandedDeployment, error) {
+	return r.addExpanded(appID, releaseID, nil)
+}
+
+func (r *DeploymentRepo) addExpanded(appID, releaseID string, processes map[string]int) (*ct.ExpandedDeployment, error) {
 	tx, err := r.db.Begin()
 	if err != nil {
 		return nil, err
@@ -100,6 +111,10 @@ func (r *DeploymentRepo) AddExpanded(appID, releaseID string) (*ct.ExpandedDeplo
 	for _, i := range oldFormation.Processes {
 		procCount += i
 	}
+	// deployments with no processes to scale finish immediately, unless
+	// the new release has a release phase for the deployment worker to run
+	_, hasReleasePhase := release.Processes[ct.ReleaseProcessType]
+	immediate := procCount == 0 && !hasReleasePhase
 
 	releaseType := ct.ReleaseTypeCode
 	if oldRelease != nil {
@@ -130,6 +145,36 @@ func (r *DeploymentRepo) AddExpanded(appID, releaseID string) (*ct.ExpandedDeplo
 		DeployTimeout:   app.DeployTimeout,
 		DeployBatchSize: app.DeployBatchSize(),
 	}
+	if processes != nil {
+		d.Processes = processes
+		ed.Processes = processes
+	}
+	if app.Strategy == "canary" {
+		d.DeployCanary = app.DeployCanary()
+		ed.DeployCanary = d.DeployCanary
+	}
+	if app.Strategy == "blue-green" {
+		warmPeriod := app.DeployWarmPeriod()
+		d.DeployWarmPeriod = &warmPeriod
+		ed.DeployWarmPeriod = d.DeployWarmPeriod
+	}
+	if rollback := app.DeployRollback(); rollback.BakePeriod > 0 {
+		d.DeployRollback = rollback
+		ed.DeployRollback = rollback
+	}
+	// deployments which finish immediately are not held by the app's
+	// deploy gate
+	var approvalState *string
+	if approval := app.DeployApproval(); approval != nil && !immediate {
+		// the deployment worker reads the secret from the app when
+		// signing the approval request, so don't store it with the
+		// deployment
+		approval.Secret = ""
+		d.DeployApproval = approval
+		ed.DeployApproval = approval
+		state := string(ct.DeploymentApprovalStatePending)
+		approvalState = &state
+	}
 	if oldRelease != nil {
 		d.OldReleaseID = oldRelease.ID
 	}
@@ -138,7 +183,7 @@ func (r *DeploymentRepo) AddExpanded(appID, releaseID string) (*ct.ExpandedDeplo
 		tx.Rollback()
 		return nil, err
 	}
-	if procCount == 0 {
+	if immediate {
 		// immediately set app release
 		if err := r.appRepo.TxSetRelease(tx, app, release.ID); err != nil {
 			tx.Rollback()
@@ -157,7 +202,7 @@ func (r *DeploymentRepo) AddExpanded(appID, releaseID string) (*ct.ExpandedDeplo
 		d.ID = random.UUID()
 	}
 	ed.ID = d.ID
-	if err := tx.QueryRow("deployment_insert", d.ID, d.AppID, oldReleaseID, d.NewReleaseID, string(releaseType), d.Strategy, d.Processes, d.Tags, d.DeployTimeout, d.DeployBatchSize).Scan(&d.CreatedAt); err != nil {
+	if err := tx.QueryRow("deployment_insert", d.ID, d.AppID, oldReleaseID, d.NewReleaseID, string(releaseType), d.Strategy, d.Processes, d.Tags, d.DeployTimeout, d.DeployBatchSize, d.DeployCanary, d.DeployWarmPeriod, d.DeployRollback, d.DeployApproval, approvalState).Scan(&d.CreatedAt); err != nil {
 		tx.Rollback()
 		if postgres.IsUniquenessError(err, "isolate_deploys") {
 			return nil, ct.ValidationError{Message: "Cannot create deploy, there is already one in progress for this app."}
@@ -186,11 +231,17 @@ func (r *DeploymentRepo) AddExpanded(appID, releaseID string) (*ct.ExpandedDeplo
 		return nil, err
 	}
 
-	if err = createDeploymentEvent(tx.Exec, d, "pending"); err != nil {
+	// deployments held by a deploy gate wait for approval before the
+	// deployment worker starts deploying them
+	status := "pending"
+	if d.DeployApproval != nil {
+		status = "pending_approval"
+	}
+	if err = createDeploymentEvent(tx.Exec, d, status); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
-	ed.Status = "pending"
+	ed.Status = status
 
 	job := &que.Job{Type: "deployment", Args: args}
 	if err := r.q.EnqueueInTx(job, tx.Tx); err != nil {
@@ -227,6 +278,130 @@ func (r *DeploymentRepo) List(appID string) ([]*ct.Deployment, error) {
 	return deployments, rows.Err()
 }
 
+// Pause requests that the given deployment is paused once the current step
+// (e.g. the current batch of jobs) has finished
+func (r *DeploymentRepo) Pause(id string) (*ct.Deployment, error) {
+	return r.updateControl(id, func(d *ct.Deployment) error {
+		if d.Paused {
+			return ct.ValidationError{Message: "deployment is already paused"}
+		}
+		// sirenia deployments replace database peers in a fixed
+		// sequence which cannot be resumed part way through
+		if d.Strategy == "sirenia" {
+			return ct.ValidationError{Message: "sirenia deployments cannot be paused"}
+		}
+		d.Paused = true
+		return nil
+	})
+}
+
+// Resume requests that the given paused deployment continues
+func (r *DeploymentRepo) Resume(id string) (*ct.Deployment, error) {
+	return r.updateControl(id, func(d *ct.Deployment) error {
+		if !d.Paused {
+			return ct.ValidationError{Message: "deployment is not paused"}
+		}
+		d.Paused = false
+		return nil
+	})
+}
+
+// Abort requests that the given deployment is stopped and rolled back
+func (r *DeploymentRepo) Abort(id string) (*ct.Deployment, error) {
+	return r.updateControl(id, func(d *ct.Deployment) error {
+		d.Aborted = true
+		return nil
+	})
+}
+
+func (r *DeploymentRepo) updateControl(id string, update func(*ct.Deployment) error) (*ct.Deployment, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	d, err := scanDeployment(tx.QueryRow("deployment_select", id))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if d.FinishedAt != nil {
+		tx.Rollback()
+		return nil, ct.ValidationError{Message: "deployment has already finished"}
+	}
+	if d.Aborted {
+		tx.Rollback()
+		return nil, ct.ValidationError{Message: "deployment has been aborted"}
+	}
+	if err := update(d); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Exec("deployment_update_paused", d.ID, d.Paused); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if d.Aborted {
+		if err := tx.Exec("deployment_update_aborted", d.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	return d, tx.Commit()
+}
+
+// Approve approves a deployment which is held by a deploy gate, recording
+// the approval in the deployment's audit trail
+func (r *DeploymentRepo) Approve(id, actor, comment string) (*ct.Deployment, error) {
+	return r.decideApproval(id, ct.DeploymentApprovalStateApproved, &ct.DeploymentApprovalEntry{
+		Action:  ct.DeploymentApprovalActionApproved,
+		Actor:   actor,
+		Comment: comment,
+	})
+}
+
+// Reject rejects a deployment which is held by a deploy gate, recording the
+// rejection in the deployment's audit trail
+func (r *DeploymentRepo) Reject(id, actor, comment string) (*ct.Deployment, error) {
+	return r.decideApproval(id, ct.DeploymentApprovalStateRejected, &ct.DeploymentApprovalEntry{
+		Action:  ct.DeploymentApprovalActionRejected,
+		Actor:   actor,
+		Comment: comment,
+	})
+}
+
+func (r *DeploymentRepo) decideApproval(id string, state ct.DeploymentApprovalState, entry *ct.DeploymentApprovalEntry) (*ct.Deployment, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	d, err := scanDeployment(tx.QueryRow("deployment_select", id))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if d.DeployApproval == nil {
+		tx.Rollback()
+		return nil, ct.ValidationError{Message: "deployment does not require approval"}
+	}
+	var currentState *string
+	if err := tx.QueryRow("deployment_select_approval", id).Scan(&currentState, &d.ApprovalLog); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if d.FinishedAt != nil || currentState == nil || *currentState != string(ct.DeploymentApprovalStatePending) {
+		tx.Rollback()
+		return nil, ct.ValidationError{Message: "deployment is not waiting for approval"}
+	}
+	now := time.Now()
+	entry.CreatedAt = &now
+	if err := tx.Exec("deployment_update_approval", id, string(state), entry); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	d.ApprovalLog = append(d.ApprovalLog, entry)
+	return d, tx.Commit()
+}
+
 type ListDeploymentOptions struct {
 	PageToken     PageToken
 	AppIDs        []string
@@ -286,9 +461,9 @@ func scanExpandedDeployment(s postgres.Scanner) (*ct.ExpandedDeployment, error)
 	var oldArtifactIDs string
 	var newArtifactIDs string
 	var oldReleaseID *string
-	var status *string
+	var status, rollbackReason *string
 	err := s.Scan(
-		&d.ID, &d.AppID, &oldReleaseID, &newRelease.ID, &d.Strategy, &status, &d.Processes, &d.Tags, &d.DeployTimeout, &d.DeployBatchSize, &d.CreatedAt, &d.FinishedAt,
+		&d.ID, &d.AppID, &oldReleaseID, &newRelease.ID, &d.Strategy, &status, &d.Processes, &d.Tags, &d.DeployTimeout, &d.DeployBatchSize, &d.DeployCanary, &d.DeployWarmPeriod, &d.DeployRollback, &rollbackReason, &d.Paused, &d.Aborted, &d.DeployApproval, &d.ApprovalLog, &d.CreatedAt, &d.FinishedAt,
 		&oldArtifactIDs, &oldRelease.Env, &oldRelease.Processes, &oldRelease.Meta, &oldRelease.CreatedAt,
 		&newArtifactIDs, &newRelease.Env, &newRelease.Processes, &newRelease.Meta, &newRelease.CreatedAt,
 		&d.Type,
@@ -305,24 +480,29 @@ func scanExpandedDeployment(s postgres.Scanner) (*ct.ExpandedDeployment, error)
 		if oldArtifactIDs != "" {
 			oldRelease.ArtifactIDs = splitPGStringArray(oldArtifactIDs)
 		}
+		setReleaseSecrets(oldRelease)
 		d.OldRelease = oldRelease
 	}
 	if newArtifactIDs != "" {
 		newRelease.ArtifactIDs = splitPGStringArray(newArtifactIDs)
 	}
 	newRelease.AppID = d.AppID
+	setReleaseSecrets(newRelease)
 	d.NewRelease = newRelease
 	if status != nil {
 		d.Status = *status
 	}
+	if rollbackReason != nil {
+		d.RollbackReason = *rollbackReason
+	}
 	return d, err
 }
 
 func scanDeployment(s postgres.Scanner) (*ct.Deployment, error) {
 	d := &ct.Deployment{}
 	var oldReleaseID *string
-	var status *string
-	err := s.Scan(&d.ID, &d.AppID, &oldReleaseID, &d.NewReleaseID, &d.Strategy, &status, &d.Processes, &d.Tags, &d.DeployTimeout, &d.DeployBatchSize, &d.CreatedAt, &d.FinishedAt)
+	var status, rollbackReason *string
+	err := s.Scan(&d.ID, &d.AppID, &oldReleaseID, &d.NewReleaseID, &d.Strategy, &status, &d.Processes, &d.Tags, &d.DeployTimeout, &d.DeployBatchSize, &d.DeployCanary, &d.DeployWarmPeriod, &d.DeployRollback, &rollbackReason, &d.Paused, &d.Aborted, &d.DeployApproval, &d.ApprovalLog, &d.CreatedAt, &d.FinishedAt)
 	if err == pgx.ErrNoRows {
 		err = ErrNotFound
 	}
@@ -332,6 +512,9 @@ func scanDeployment(s postgres.Scanner) (*ct.Deployment, error) {
 	if status != nil {
 		d.Status = *status
 	}
+	if rollbackReason != nil {
+		d.RollbackReason = *rollbackReason
+	}
 	return d, err
 }
 
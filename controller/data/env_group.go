@@ -0,0 +1,245 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/controller/utils"
+	"github.com/flynn/flynn/pkg/postgres"
+	"github.com/flynn/flynn/pkg/random"
+	"github.com/jackc/pgx"
+)
+
+type EnvGroupRepo struct {
+	db *postgres.DB
+}
+
+func NewEnvGroupRepo(db *postgres.DB) *EnvGroupRepo {
+	return &EnvGroupRepo{db: db}
+}
+
+func validateEnvGroup(g *ct.EnvGroup) error {
+	if len(g.Name) > 100 || !utils.AppNamePattern.MatchString(g.Name) {
+		return ct.ValidationError{Field: "name", Message: "is invalid"}
+	}
+	if value, ok := g.Env[""]; ok {
+		return ct.ValidationError{
+			Field:   "env",
+			Message: fmt.Sprintf("you can't create an env var with an empty key (tried to set \"\"=%q)", value),
+		}
+	}
+	return nil
+}
+
+func (r *EnvGroupRepo) Add(g *ct.EnvGroup) error {
+	if err := validateEnvGroup(g); err != nil {
+		return err
+	}
+	if g.ID == "" {
+		g.ID = random.UUID()
+	}
+	if g.Env == nil {
+		g.Env = make(map[string]string)
+	}
+	err := r.db.QueryRow("env_group_insert", g.ID, g.Name, g.Env, g.AutoDeploy).Scan(&g.CreatedAt, &g.UpdatedAt)
+	if postgres.IsUniquenessError(err, "env_groups_name_idx") {
+		return ct.ValidationError{Field: "name", Message: "an env group with that name already exists"}
+	}
+	return err
+}
+
+func scanEnvGroup(s postgres.Scanner) (*ct.EnvGroup, error) {
+	g := &ct.EnvGroup{}
+	if err := s.Scan(&g.ID, &g.Name, &g.Env, &g.AutoDeploy, &g.Apps, &g.CreatedAt, &g.UpdatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			err = ErrNotFound
+		}
+		return nil, err
+	}
+	return g, nil
+}
+
+func scanEnvGroups(rows *pgx.Rows) ([]*ct.EnvGroup, error) {
+	defer rows.Close()
+	var groups []*ct.EnvGroup
+	for rows.Next() {
+		g, err := scanEnvGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
+
+// Get returns the env group with the given ID or name
+func (r *EnvGroupRepo) Get(ref string) (*ct.EnvGroup, error) {
+	if idPattern.MatchString(ref) {
+		return scanEnvGroup(r.db.QueryRow("env_group_select_by_name_or_id", ref, ref))
+	}
+	return scanEnvGroup(r.db.QueryRow("env_group_select_by_name", ref))
+}
+
+func (r *EnvGroupRepo) List() ([]*ct.EnvGroup, error) {
+	rows, err := r.db.Query("env_group_list")
+	if err != nil {
+		return nil, err
+	}
+	return scanEnvGroups(rows)
+}
+
+// AppList returns the env groups attached to the given app in order of
+// increasing precedence
+func (r *EnvGroupRepo) AppList(appID string) ([]*ct.EnvGroup, error) {
+	rows, err := r.db.Query("env_group_list_app", appID)
+	if err != nil {
+		return nil, err
+	}
+	return scanEnvGroups(rows)
+}
+
+// Update updates the env and auto deploy setting of an env group
+func (r *EnvGroupRepo) Update(g *ct.EnvGroup) error {
+	if err := validateEnvGroup(g); err != nil {
+		return err
+	}
+	if g.Env == nil {
+		g.Env = make(map[string]string)
+	}
+	err := r.db.QueryRow("env_group_update", g.ID, g.Env, g.AutoDeploy).Scan(&g.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		err = ErrNotFound
+	}
+	return err
+}
+
+// Remove deletes an env group, detaching it from all apps
+func (r *EnvGroupRepo) Remove(id string) error {
+	return r.db.Exec("env_group_delete", id)
+}
+
+// Attach attaches an env group to an app, giving it precedence over the
+// app's existing env groups
+func (r *EnvGroupRepo) Attach(appID, groupID string) error {
+	return r.db.Exec("env_group_attach", appID, groupID)
+}
+
+func (r *EnvGroupRepo) Detach(appID, groupID string) error {
+	return r.db.Exec("env_group_detach", appID, groupID)
+}
+
+// BaseRelease returns the release to apply a change to the app's env groups
+// to, which is the most recent release created by an earlier env group change
+// since the app's current release if it has not been deployed (so that
+// successive changes which are not deployed build on each other), otherwise
+// the current release
+func (r *EnvGroupRepo) BaseRelease(current *ct.Release) (*ct.Release, error) {
+	rows, err := r.db.Query("release_app_list", current.AppID)
+	if err != nil {
+		return nil, err
+	}
+	releases, err := releaseList(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, release := range releases {
+		// releases are listed most recent first
+		if release.ID == current.ID || release.CreatedAt == nil || current.CreatedAt == nil || !release.CreatedAt.After(*current.CreatedAt) {
+			break
+		}
+		if release.Meta[ct.ReleaseMetaEnvGroupsChange] == "true" && artifactIDsEqual(release.ArtifactIDs, current.ArtifactIDs) {
+			return release, nil
+		}
+	}
+	return current, nil
+}
+
+// MergeEnvGroups returns the env of the given groups, with groups later in
+// the list overriding those earlier in the list
+func MergeEnvGroups(groups []*ct.EnvGroup) map[string]string {
+	env := make(map[string]string)
+	for _, g := range groups {
+		for k, v := range g.Env {
+			env[k] = v
+		}
+	}
+	return env
+}
+
+// ApplyEnvGroups returns a copy of the release with its env changed from that
+// of the before groups to that of the after groups, or nil if the env and
+// precedence are unchanged.
+//
+// Vars set directly on the app take precedence over env group vars, so vars
+// whose values differ from those set by the before groups are left as they
+// are, and the precedence is recorded in the release meta along with
+// ReleaseMetaEnvGroupsChange.
+func ApplyEnvGroups(release *ct.Release, before, after []*ct.EnvGroup) *ct.Release {
+	oldEnv := MergeEnvGroups(before)
+	newEnv := MergeEnvGroups(after)
+
+	env := make(map[string]string, len(release.Env)+len(newEnv))
+	for k, v := range release.Env {
+		env[k] = v
+	}
+	for k, v := range newEnv {
+		cur, ok := env[k]
+		if old, set := oldEnv[k]; ok && (!set || cur != old) {
+			// set directly on the app
+			continue
+		}
+		env[k] = v
+	}
+	for k, old := range oldEnv {
+		if _, ok := newEnv[k]; !ok && env[k] == old {
+			delete(env, k)
+		}
+	}
+
+	meta := make(map[string]string, len(release.Meta)+2)
+	for k, v := range release.Meta {
+		meta[k] = v
+	}
+	delete(meta, ct.ReleaseMetaEnvGroups)
+	delete(meta, ct.ReleaseMetaEnvPrecedence)
+	if len(after) > 0 {
+		names := make([]string, len(after))
+		precedence := []string{"release env"}
+		for i, g := range after {
+			names[i] = g.Name
+			precedence = append(precedence, after[len(after)-1-i].Name)
+		}
+		meta[ct.ReleaseMetaEnvGroups] = strings.Join(names, ",")
+		meta[ct.ReleaseMetaEnvPrecedence] = strings.Join(precedence, " > ")
+	}
+
+	if stringMapEqual(env, release.Env) && stringMapEqual(meta, release.Meta) {
+		return nil
+	}
+	meta[ct.ReleaseMetaEnvGroupsChange] = "true"
+	processes := make(map[string]ct.ProcessType, len(release.Processes))
+	for typ, proc := range release.Processes {
+		processes[typ] = proc
+	}
+	return &ct.Release{
+		AppID:            release.AppID,
+		ArtifactIDs:      release.ArtifactIDs,
+		LegacyArtifactID: release.LegacyArtifactID,
+		Env:              env,
+		Meta:             meta,
+		Processes:        processes,
+	}
+}
+
+func stringMapEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || v != w {
+			return false
+		}
+	}
+	return true
+}
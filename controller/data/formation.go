@@ -39,10 +39,13 @@ func (r *FormationRepo) validateProcesses(req *ct.ScaleRequest) error {
 	}
 	release := data.(*ct.Release)
 	invalid := make([]string, 0, len(*req.NewProcesses))
-	for typ := range *req.NewProcesses {
+	for typ, count := range *req.NewProcesses {
 		if _, ok := release.Processes[typ]; !ok {
 			invalid = append(invalid, typ)
 		}
+		if typ == ct.ReleaseProcessType && count > 0 {
+			return ct.ValidationError{Message: fmt.Sprintf("the %q process type is run when deploying and cannot be scaled", typ)}
+		}
 	}
 	if len(invalid) > 0 {
 		return ct.ValidationError{Message: fmt.Sprintf("requested scale includes process types that do not exist in the release: %s", strings.Join(invalid, ", "))}
@@ -125,6 +128,9 @@ func (r *FormationRepo) AddScaleRequest(req *ct.ScaleRequest, deleteFormation bo
 			formation.Processes[typ] = count
 		}
 	}
+	if req.NewServiceSuffix != nil {
+		formation.ServiceSuffix = *req.NewServiceSuffix
+	}
 	if req.NewTags == nil {
 		req.NewTags = &formation.Tags
 	} else {
@@ -161,6 +167,7 @@ func (r *FormationRepo) AddScaleRequest(req *ct.ScaleRequest, deleteFormation bo
 			formation.ReleaseID,
 			formation.Processes,
 			formation.Tags,
+			serviceSuffix(formation.ServiceSuffix),
 		).Scan(&formation.CreatedAt, &formation.UpdatedAt)
 	}
 	if err != nil {
@@ -211,6 +218,15 @@ func (r *FormationRepo) AddScaleRequest(req *ct.ScaleRequest, deleteFormation bo
 	return req, tx.Commit()
 }
 
+// serviceSuffix returns the given formation service suffix as a value to
+// store in the database, storing an empty suffix as NULL
+func serviceSuffix(suffix string) *string {
+	if suffix == "" {
+		return nil
+	}
+	return &suffix
+}
+
 func scanFormations(rows *pgx.Rows) ([]*ct.Formation, error) {
 	var formations []*ct.Formation
 	for rows.Next() {
@@ -226,13 +242,17 @@ func scanFormations(rows *pgx.Rows) ([]*ct.Formation, error) {
 
 func scanFormation(s postgres.Scanner) (*ct.Formation, error) {
 	f := &ct.Formation{}
-	err := s.Scan(&f.AppID, &f.ReleaseID, &f.Processes, &f.Tags, &f.CreatedAt, &f.UpdatedAt)
+	var serviceSuffix *string
+	err := s.Scan(&f.AppID, &f.ReleaseID, &f.Processes, &f.Tags, &serviceSuffix, &f.CreatedAt, &f.UpdatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			err = ErrNotFound
 		}
 		return nil, err
 	}
+	if serviceSuffix != nil {
+		f.ServiceSuffix = *serviceSuffix
+	}
 	return f, nil
 }
 
@@ -244,7 +264,7 @@ func scanExpandedFormation(s postgres.Scanner) (*ct.ExpandedFormation, error) {
 	var artifactIDs string
 	var appReleaseID *string
 	var req ct.ScaleRequest
-	var reqID *string
+	var reqID, serviceSuffix *string
 	err := s.Scan(
 		&f.App.ID,
 		&f.App.Name,
@@ -268,6 +288,7 @@ func scanExpandedFormation(s postgres.Scanner) (*ct.ExpandedFormation, error) {
 		&req.CreatedAt,
 		&f.Processes,
 		&f.Tags,
+		&serviceSuffix,
 		&f.UpdatedAt,
 		&f.Deleted,
 	)
@@ -287,6 +308,9 @@ func scanExpandedFormation(s postgres.Scanner) (*ct.ExpandedFormation, error) {
 	if appReleaseID != nil {
 		f.App.ReleaseID = *appReleaseID
 	}
+	if serviceSuffix != nil {
+		f.ServiceSuffix = *serviceSuffix
+	}
 	if f.App.Meta == nil {
 		// ensure we don't return `{"meta": null}`
 		f.App.Meta = make(map[string]string)
@@ -298,6 +322,7 @@ func scanExpandedFormation(s postgres.Scanner) (*ct.ExpandedFormation, error) {
 		}
 	}
 	f.Release.AppID = f.App.ID
+	setReleaseSecrets(f.Release)
 	return f, nil
 }
 
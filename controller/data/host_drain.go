@@ -0,0 +1,61 @@
+package data
+
+import (
+	ct "github.com/flynn/flynn/controller/types"
+	host "github.com/flynn/flynn/host/types"
+	"github.com/flynn/flynn/pkg/postgres"
+)
+
+// HostDrainRepo stores the status of the scheduler's host drains so that
+// drains continue when the scheduler leader changes
+type HostDrainRepo struct {
+	db *postgres.DB
+}
+
+func NewHostDrainRepo(db *postgres.DB) *HostDrainRepo {
+	return &HostDrainRepo{db: db}
+}
+
+// Put stores the status of a host drain, replacing any previous drain of the
+// host, and emits a host_drain event
+func (r *HostDrainRepo) Put(status *host.DrainStatus) error {
+	if status.Events == nil {
+		status.Events = []*host.DrainEvent{}
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := tx.Exec("host_drain_upsert", status.HostID, string(status.State), status.Events); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := CreateEvent(tx.Exec, &ct.Event{
+		ObjectID:   status.HostID,
+		ObjectType: ct.EventTypeHostDrain,
+	}, status); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// List returns the most recent drain of each host
+func (r *HostDrainRepo) List() ([]*host.DrainStatus, error) {
+	rows, err := r.db.Query("host_drain_list")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*host.DrainStatus
+	for rows.Next() {
+		status := &host.DrainStatus{}
+		var state string
+		if err := rows.Scan(&status.HostID, &state, &status.Events); err != nil {
+			return nil, err
+		}
+		status.State = host.DrainState(state)
+		list = append(list, status)
+	}
+	return list, rows.Err()
+}
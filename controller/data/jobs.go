@@ -2,6 +2,7 @@ package data
 
 import (
 	"strings"
+	"time"
 
 	ct "github.com/flynn/flynn/controller/types"
 	"github.com/flynn/flynn/pkg/cluster"
@@ -52,6 +53,9 @@ func (r *JobRepo) Add(job *ct.Job) error {
 		job.RunAt,
 		job.Restarts,
 		job.Args,
+		job.BlockedReason,
+		job.PreemptedBy,
+		job.CrashLoopReason,
 	).Scan(&job.CreatedAt, &job.UpdatedAt)
 	if postgres.IsPostgresCode(err, postgres.CheckViolation) {
 		tx.Rollback()
@@ -69,8 +73,13 @@ func (r *JobRepo) Add(job *ct.Job) error {
 		}
 	}
 
-	// create a job event, ignoring possible duplications
-	uniqueID := strings.Join([]string{job.UUID, string(job.State)}, "|")
+	// create a job event, ignoring possible duplications (including a
+	// separate event for a stopped job being marked as crash looping)
+	uniqueParts := []string{job.UUID, string(job.State)}
+	if job.CrashLoopReason != nil {
+		uniqueParts = append(uniqueParts, "crash_loop")
+	}
+	uniqueID := strings.Join(uniqueParts, "|")
 	if err := tx.Exec("event_insert_unique", job.AppID, job.UUID, uniqueID, string(ct.EventTypeJob), job); err != nil {
 		tx.Rollback()
 		return err
@@ -99,6 +108,9 @@ func scanJob(s postgres.Scanner) (*ct.Job, error) {
 		&job.CreatedAt,
 		&job.UpdatedAt,
 		&job.Args,
+		&job.BlockedReason,
+		&job.PreemptedBy,
+		&job.CrashLoopReason,
 		&volumeIDs,
 	)
 	if err != nil {
@@ -131,6 +143,25 @@ func (r *JobRepo) List(appID string) ([]*ct.Job, error) {
 	return jobs, rows.Err()
 }
 
+// ListSince returns the formation jobs which have been updated since the
+// given time, oldest first
+func (r *JobRepo) ListSince(since time.Time) ([]*ct.Job, error) {
+	rows, err := r.db.Query("job_list_since", since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var jobs []*ct.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
 func (r *JobRepo) ListActive() ([]*ct.Job, error) {
 	rows, err := r.db.Query("job_list_active")
 	if err != nil {
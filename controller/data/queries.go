@@ -29,6 +29,8 @@ var preparedStatements = map[string]string{
 	"release_artifacts_insert":              releaseArtifactsInsertQuery,
 	"release_artifacts_delete":              releaseArtifactsDeleteQuery,
 	"release_delete":                        releaseDeleteQuery,
+	"release_list_secrets_to_rewrap":        releaseListSecretsToRewrapQuery,
+	"release_update_env":                    releaseUpdateEnvQuery,
 	"artifact_list":                         artifactListQuery,
 	"artifact_list_ids":                     artifactListIDsQuery,
 	"artifact_select":                       artifactSelectQuery,
@@ -44,6 +46,13 @@ var preparedStatements = map[string]string{
 	"deployment_insert":                     deploymentInsertQuery,
 	"deployment_update_finished_at":         deploymentUpdateFinishedAtQuery,
 	"deployment_update_finished_at_now":     deploymentUpdateFinishedAtNowQuery,
+	"deployment_update_rollback_reason":     deploymentUpdateRollbackReasonQuery,
+	"deployment_update_paused":              deploymentUpdatePausedQuery,
+	"deployment_update_aborted":             deploymentUpdateAbortedQuery,
+	"deployment_select_control":             deploymentSelectControlQuery,
+	"deployment_select_approval":            deploymentSelectApprovalQuery,
+	"deployment_update_approval":            deploymentUpdateApprovalQuery,
+	"deployment_append_approval_log":        deploymentAppendApprovalLogQuery,
 	"deployment_delete":                     deploymentDeleteQuery,
 	"event_select":                          eventSelectQuery,
 	"event_insert":                          eventInsertQuery,
@@ -64,6 +73,7 @@ var preparedStatements = map[string]string{
 	"scale_request_list":                    scaleRequestListQuery,
 	"job_list":                              jobListQuery,
 	"job_list_active":                       jobListActiveQuery,
+	"job_list_since":                        jobListSinceQuery,
 	"job_select":                            jobSelectQuery,
 	"job_insert":                            jobInsertQuery,
 	"job_volume_insert":                     jobVolumeInsertQuery,
@@ -96,6 +106,23 @@ var preparedStatements = map[string]string{
 	"volume_select":                         volumeSelectQuery,
 	"volume_insert":                         volumeInsertQuery,
 	"volume_decommission":                   volumeDecommissionQuery,
+	"schedule_list":                         scheduleListQuery,
+	"schedule_select":                       scheduleSelectQuery,
+	"schedule_select_due":                   scheduleSelectDueQuery,
+	"schedule_insert":                       scheduleInsertQuery,
+	"schedule_update_run":                   scheduleUpdateRunQuery,
+	"schedule_delete":                       scheduleDeleteQuery,
+	"schedule_run_list":                     scheduleRunListQuery,
+	"schedule_run_select_last_job":          scheduleRunSelectLastJobQuery,
+	"schedule_run_fail_pending":             scheduleRunFailPendingQuery,
+	"schedule_run_insert":                   scheduleRunInsertQuery,
+	"schedule_run_update":                   scheduleRunUpdateQuery,
+	"autoscale_policy_list":                 autoscalePolicyListQuery,
+	"autoscale_policy_select":               autoscalePolicySelectQuery,
+	"autoscale_policy_select_due":           autoscalePolicySelectDueQuery,
+	"autoscale_policy_upsert":               autoscalePolicyUpsertQuery,
+	"autoscale_policy_update_evaluated":     autoscalePolicyUpdateEvaluatedQuery,
+	"autoscale_policy_delete":               autoscalePolicyDeleteQuery,
 	"http_route_list":                       httpRouteListQuery,
 	"http_route_list_by_parent_ref":         httpRouteListByParentRefQuery,
 	"http_route_insert":                     httpRouteInsertQuery,
@@ -111,6 +138,35 @@ var preparedStatements = map[string]string{
 	"certificate_insert":                    certificateInsertQuery,
 	"route_certificate_delete_by_route_id":  routeCertificateDeleteByRouteIDQuery,
 	"route_certificate_insert":              routeCertificateInsertQuery,
+	"token_list":                            tokenListQuery,
+	"token_select":                          tokenSelectQuery,
+	"token_select_by_key_hash":              tokenSelectByKeyHashQuery,
+	"token_insert":                          tokenInsertQuery,
+	"token_delete":                          tokenDeleteQuery,
+	"audit_insert":                          auditInsertQuery,
+	"webhook_list":                          webhookListQuery,
+	"webhook_select":                        webhookSelectQuery,
+	"webhook_select_with_secret":            webhookSelectWithSecretQuery,
+	"webhook_insert":                        webhookInsertQuery,
+	"webhook_delete":                        webhookDeleteQuery,
+	"webhook_delivery_list":                 webhookDeliveryListQuery,
+	"webhook_delivery_select":               webhookDeliverySelectQuery,
+	"webhook_delivery_update":               webhookDeliveryUpdateQuery,
+	"env_group_list":                        envGroupListQuery,
+	"env_group_list_app":                    envGroupListAppQuery,
+	"env_group_select_by_name":              envGroupSelectByNameQuery,
+	"env_group_select_by_name_or_id":        envGroupSelectByNameOrIDQuery,
+	"env_group_insert":                      envGroupInsertQuery,
+	"env_group_update":                      envGroupUpdateQuery,
+	"env_group_delete":                      envGroupDeleteQuery,
+	"env_group_attach":                      envGroupAttachQuery,
+	"env_group_detach":                      envGroupDetachQuery,
+	"quota_select":                          quotaSelectQuery,
+	"quota_upsert":                          quotaUpsertQuery,
+	"quota_count_one_off_jobs":              quotaCountOneOffJobsQuery,
+	"quota_select_deployment_releases":      quotaSelectDeploymentReleasesQuery,
+	"host_drain_list":                       hostDrainListQuery,
+	"host_drain_upsert":                     hostDrainUpsertQuery,
 }
 
 func PrepareStatements(conn *pgx.Conn) error {
@@ -236,6 +292,15 @@ INSERT INTO release_artifacts (release_id, artifact_id, index) VALUES ($1, $2, $
 UPDATE release_artifacts SET deleted_at = now() WHERE release_id = $1 AND artifact_id = $2 AND deleted_at IS NULL`
 	releaseDeleteQuery = `
 UPDATE releases SET deleted_at = now() WHERE release_id = $1 AND deleted_at IS NULL`
+	releaseListSecretsToRewrapQuery = `
+SELECT release_id, env FROM releases
+WHERE CASE WHEN jsonb_typeof(env) = 'object' THEN EXISTS (
+  SELECT 1 FROM jsonb_each_text(env)
+  WHERE value LIKE 'flynn-secret:v1:%' AND split_part(value, ':', 3) != $1
+) ELSE false END
+LIMIT $2`
+	releaseUpdateEnvQuery = `
+UPDATE releases SET env = $2 WHERE release_id = $1`
 	artifactListQuery = `
 SELECT artifact_id, type, uri, meta, manifest, hashes, size, layer_url_template, created_at FROM artifacts
 WHERE deleted_at IS NULL ORDER BY created_at DESC`
@@ -260,22 +325,37 @@ SELECT COUNT(*) FROM (
   WHERE deleted_at IS NULL
 ) AS l WHERE l.layer_id = $1`
 	deploymentInsertQuery = `
-INSERT INTO deployments (deployment_id, app_id, old_release_id, new_release_id, type, strategy, processes, tags, deploy_timeout, deploy_batch_size)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING created_at`
+INSERT INTO deployments (deployment_id, app_id, old_release_id, new_release_id, type, strategy, processes, tags, deploy_timeout, deploy_batch_size, deploy_canary, deploy_warm_period, deploy_rollback, deploy_approval, approval_state)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING created_at`
 	deploymentUpdateFinishedAtQuery = `
 UPDATE deployments SET finished_at = $2 WHERE deployment_id = $1`
 	deploymentUpdateFinishedAtNowQuery = `
 UPDATE deployments SET finished_at = now() WHERE deployment_id = $1`
+	deploymentUpdateRollbackReasonQuery = `
+UPDATE deployments SET rollback_reason = $2 WHERE deployment_id = $1`
+	deploymentUpdatePausedQuery = `
+UPDATE deployments SET paused = $2 WHERE deployment_id = $1`
+	deploymentUpdateAbortedQuery = `
+UPDATE deployments SET aborted = true WHERE deployment_id = $1`
+	deploymentSelectControlQuery = `
+SELECT paused, aborted FROM deployments WHERE deployment_id = $1`
+	deploymentSelectApprovalQuery = `
+SELECT approval_state, approval_log FROM deployments WHERE deployment_id = $1`
+	deploymentUpdateApprovalQuery = `
+UPDATE deployments SET approval_state = $2, approval_log = COALESCE(approval_log, '[]'::jsonb) || $3::jsonb
+WHERE deployment_id = $1 AND approval_state = 'pending'`
+	deploymentAppendApprovalLogQuery = `
+UPDATE deployments SET approval_log = COALESCE(approval_log, '[]'::jsonb) || $2::jsonb WHERE deployment_id = $1`
 	deploymentDeleteQuery = `
 DELETE FROM deployments WHERE deployment_id = $1`
 	deploymentSelectQuery = `
 SELECT deployment_id, app_id, old_release_id, new_release_id, strategy, deployment_status(deployment_id),
-  processes, tags, deploy_timeout, deploy_batch_size, created_at, finished_at
+  processes, tags, deploy_timeout, deploy_batch_size, deploy_canary, deploy_warm_period, deploy_rollback, rollback_reason, paused, aborted, deploy_approval, approval_log, created_at, finished_at
 FROM deployments
 WHERE deployment_id = $1`
 	deploymentSelectExpandedQuery = `
 SELECT d.deployment_id, d.app_id, d.old_release_id, d.new_release_id, d.strategy, deployment_status(d.deployment_id),
-  d.processes, d.tags, d.deploy_timeout, d.deploy_batch_size, d.created_at, d.finished_at,
+  d.processes, d.tags, d.deploy_timeout, d.deploy_batch_size, d.deploy_canary, d.deploy_warm_period, d.deploy_rollback, d.rollback_reason, d.paused, d.aborted, d.deploy_approval, d.approval_log, d.created_at, d.finished_at,
   ARRAY(
     SELECT a.artifact_id
     FROM release_artifacts a
@@ -299,12 +379,12 @@ LIMIT 1
 `
 	deploymentListQuery = `
 SELECT deployment_id, app_id, old_release_id, new_release_id, strategy, deployment_status(deployment_id),
-  processes, tags, deploy_timeout, deploy_batch_size, created_at, finished_at
+  processes, tags, deploy_timeout, deploy_batch_size, deploy_canary, deploy_warm_period, deploy_rollback, rollback_reason, paused, aborted, deploy_approval, approval_log, created_at, finished_at
 FROM deployments
 WHERE app_id = $1 ORDER BY created_at DESC`
 	deploymentListPageQuery = `
 SELECT d.deployment_id, d.app_id, d.old_release_id, d.new_release_id, d.strategy, deployment_status(d.deployment_id),
-  d.processes, d.tags, d.deploy_timeout, d.deploy_batch_size, d.created_at, d.finished_at,
+  d.processes, d.tags, d.deploy_timeout, d.deploy_batch_size, d.deploy_canary, d.deploy_warm_period, d.deploy_rollback, d.rollback_reason, d.paused, d.aborted, d.deploy_approval, d.approval_log, d.created_at, d.finished_at,
   ARRAY(
     SELECT a.artifact_id
     FROM release_artifacts a
@@ -349,10 +429,10 @@ VALUES ($1, $2, $3, $4, $5)`
 INSERT INTO events (app_id, object_id, unique_id, object_type, data)
 VALUES ($1, $2, $3, $4, $5) ON CONFLICT (unique_id) DO NOTHING`
 	formationListByAppQuery = `
-SELECT app_id, release_id, processes, tags, created_at, updated_at
+SELECT app_id, release_id, processes, tags, service_suffix, created_at, updated_at
 FROM formations WHERE app_id = $1 AND deleted_at IS NULL ORDER BY created_at DESC`
 	formationListByReleaseQuery = `
-SELECT app_id, release_id, processes, tags, created_at, updated_at
+SELECT app_id, release_id, processes, tags, service_suffix, created_at, updated_at
 FROM formations WHERE release_id = $1 AND deleted_at IS NULL ORDER BY created_at DESC`
 	formationListActiveQuery = `
 SELECT
@@ -368,7 +448,7 @@ SELECT
   releases.meta, releases.env, releases.processes, releases.created_at,
   scale_requests.scale_request_id, scale_requests.old_processes, scale_requests.new_processes,
   scale_requests.old_tags, scale_requests.new_tags, scale_requests.created_at,
-  formations.processes, formations.tags, formations.updated_at, formations.deleted_at IS NOT NULL
+  formations.processes, formations.tags, formations.service_suffix, formations.updated_at, formations.deleted_at IS NOT NULL
 FROM formations
 JOIN apps USING (app_id)
 JOIN releases ON releases.release_id = formations.release_id
@@ -399,7 +479,7 @@ SELECT
   releases.meta, releases.env, releases.processes, releases.created_at,
   scale_requests.scale_request_id, scale_requests.old_processes, scale_requests.new_processes,
   scale_requests.old_tags, scale_requests.new_tags, scale_requests.created_at,
-  formations.processes, formations.tags, formations.updated_at, formations.deleted_at IS NOT NULL
+  formations.processes, formations.tags, formations.service_suffix, formations.updated_at, formations.deleted_at IS NOT NULL
 FROM formations
 JOIN apps USING (app_id)
 JOIN releases ON releases.release_id = formations.release_id
@@ -410,7 +490,7 @@ LEFT OUTER JOIN scale_requests
 WHERE formations.updated_at >= $1 AND formations.deleted_at IS NULL
 ORDER BY formations.updated_at DESC`
 	formationSelectQuery = `
-SELECT app_id, release_id, processes, tags, created_at, updated_at
+SELECT app_id, release_id, processes, tags, service_suffix, created_at, updated_at
 FROM formations WHERE app_id = $1 AND release_id = $2 AND deleted_at IS NULL`
 	formationSelectExpandedQuery = `
 SELECT
@@ -426,7 +506,7 @@ SELECT
   releases.meta, releases.env, releases.processes, releases.created_at,
   scale_requests.scale_request_id, scale_requests.old_processes, scale_requests.new_processes,
   scale_requests.old_tags, scale_requests.new_tags, scale_requests.created_at,
-  formations.processes, formations.tags, formations.updated_at, formations.deleted_at IS NOT NULL
+  formations.processes, formations.tags, formations.service_suffix, formations.updated_at, formations.deleted_at IS NOT NULL
 FROM formations
 JOIN apps USING (app_id)
 JOIN releases ON releases.release_id = formations.release_id
@@ -436,10 +516,10 @@ LEFT OUTER JOIN scale_requests
   AND scale_requests.state = 'pending'
 WHERE formations.app_id = $1 AND formations.release_id = $2`
 	formationInsertQuery = `
-INSERT INTO formations (app_id, release_id, processes, tags)
-VALUES ($1, $2, $3, $4)
+INSERT INTO formations (app_id, release_id, processes, tags, service_suffix)
+VALUES ($1, $2, $3, $4, $5)
 ON CONFLICT ON CONSTRAINT formations_pkey DO UPDATE
-SET processes = $3, tags = $4, updated_at = now(), deleted_at = NULL
+SET processes = $3, tags = $4, service_suffix = $5, updated_at = now(), deleted_at = NULL
 RETURNING created_at, updated_at`
 	formationDeleteQuery = `
 UPDATE formations SET deleted_at = now(), processes = NULL, updated_at = now()
@@ -482,6 +562,7 @@ LIMIT $6
 SELECT
   cluster_id, job_id, host_id, app_id, release_id, process_type, state, meta,
   exit_status, host_error, run_at, restarts, created_at, updated_at, args,
+  blocked_reason, preempted_by, crash_loop_reason,
   ARRAY(
     SELECT job_volumes.volume_id
     FROM job_volumes
@@ -493,6 +574,7 @@ FROM job_cache WHERE app_id = $1 ORDER BY created_at DESC`
 SELECT
   cluster_id, job_id, host_id, app_id, release_id, process_type, state, meta,
   exit_status, host_error, run_at, restarts, created_at, updated_at, args,
+  blocked_reason, preempted_by, crash_loop_reason,
   ARRAY(
     SELECT job_volumes.volume_id
     FROM job_volumes
@@ -500,10 +582,23 @@ SELECT
     ORDER BY job_volumes.index
   )
 FROM job_cache WHERE state = 'pending' OR state = 'starting' OR state = 'up' OR state = 'stopping' ORDER BY updated_at DESC`
+	jobListSinceQuery = `
+SELECT
+  cluster_id, job_id, host_id, app_id, release_id, process_type, state, meta,
+  exit_status, host_error, run_at, restarts, created_at, updated_at, args,
+  blocked_reason, preempted_by, crash_loop_reason,
+  ARRAY(
+    SELECT job_volumes.volume_id
+    FROM job_volumes
+    WHERE job_volumes.job_id = job_cache.job_id
+    ORDER BY job_volumes.index
+  )
+FROM job_cache WHERE updated_at >= $1 AND process_type IS NOT NULL ORDER BY created_at`
 	jobSelectQuery = `
 SELECT
   cluster_id, job_id, host_id, app_id, release_id, process_type, state, meta,
   exit_status, host_error, run_at, restarts, created_at, updated_at, args,
+  blocked_reason, preempted_by, crash_loop_reason,
   ARRAY(
     SELECT job_volumes.volume_id
     FROM job_volumes
@@ -512,9 +607,9 @@ SELECT
   )
 FROM job_cache WHERE job_id = $1`
 	jobInsertQuery = `
-INSERT INTO job_cache (cluster_id, job_id, host_id, app_id, release_id, process_type, state, meta, exit_status, host_error, run_at, restarts, args)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) ON CONFLICT (job_id) DO UPDATE
-SET cluster_id = $1, host_id = $3, state = $7, exit_status = $9, host_error = $10, run_at = $11, restarts = $12, args = $13, updated_at = now()
+INSERT INTO job_cache (cluster_id, job_id, host_id, app_id, release_id, process_type, state, meta, exit_status, host_error, run_at, restarts, args, blocked_reason, preempted_by, crash_loop_reason)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) ON CONFLICT (job_id) DO UPDATE
+SET cluster_id = $1, host_id = $3, state = $7, exit_status = $9, host_error = $10, run_at = $11, restarts = $12, args = $13, blocked_reason = $14, preempted_by = $15, crash_loop_reason = $16, updated_at = now()
 RETURNING created_at, updated_at`
 	jobVolumeInsertQuery = `
 INSERT INTO job_volumes (job_id, volume_id, index) VALUES ($1, $2, $3)
@@ -625,32 +720,86 @@ ON CONFLICT (volume_id) DO UPDATE SET job_id = $7, updated_at = now()
 RETURNING created_at, updated_at`
 	volumeDecommissionQuery = `
 UPDATE volumes SET updated_at = now(), decommissioned_at = now() WHERE app_id = $1 AND volume_id = $2 RETURNING updated_at, decommissioned_at`
+	scheduleListQuery = `
+SELECT schedule_id, app_id, release_id, cron, args, env, resources, concurrency, next_run_at, last_run_at, created_at, updated_at
+FROM schedules WHERE app_id = $1 AND deleted_at IS NULL ORDER BY created_at DESC`
+	scheduleSelectQuery = `
+SELECT schedule_id, app_id, release_id, cron, args, env, resources, concurrency, next_run_at, last_run_at, created_at, updated_at
+FROM schedules WHERE app_id = $1 AND schedule_id = $2 AND deleted_at IS NULL`
+	scheduleSelectDueQuery = `
+SELECT s.schedule_id, s.app_id, s.release_id, s.cron, s.args, s.env, s.resources, s.concurrency, s.next_run_at, s.last_run_at, s.created_at, s.updated_at
+FROM schedules AS s INNER JOIN apps AS a USING (app_id)
+WHERE s.next_run_at <= now() AND s.deleted_at IS NULL AND a.deleted_at IS NULL
+ORDER BY s.next_run_at LIMIT 1 FOR UPDATE OF s SKIP LOCKED`
+	scheduleInsertQuery = `
+INSERT INTO schedules (schedule_id, app_id, release_id, cron, args, env, resources, concurrency, next_run_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING created_at, updated_at`
+	scheduleUpdateRunQuery = `
+UPDATE schedules SET next_run_at = $2, last_run_at = $3, updated_at = now() WHERE schedule_id = $1`
+	scheduleDeleteQuery = `
+UPDATE schedules SET deleted_at = now() WHERE schedule_id = $1 AND deleted_at IS NULL`
+	scheduleRunListQuery = `
+SELECT schedule_run_id, schedule_id, app_id, release_id, job_id, state, error, scheduled_at, created_at
+FROM schedule_runs WHERE app_id = $1 AND schedule_id = $2 ORDER BY created_at DESC LIMIT $3`
+	scheduleRunSelectLastJobQuery = `
+SELECT schedule_run_id, schedule_id, app_id, release_id, job_id, state, error, scheduled_at, created_at
+FROM schedule_runs WHERE schedule_id = $1 AND job_id IS NOT NULL ORDER BY created_at DESC LIMIT 1`
+	scheduleRunFailPendingQuery = `
+UPDATE schedule_runs SET state = 'failed', error = $2 WHERE schedule_id = $1 AND state = 'pending'`
+	scheduleRunInsertQuery = `
+INSERT INTO schedule_runs (schedule_run_id, schedule_id, app_id, release_id, job_id, state, error, scheduled_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING created_at`
+	scheduleRunUpdateQuery = `
+UPDATE schedule_runs SET release_id = $2, job_id = $3, state = $4, error = $5 WHERE schedule_run_id = $1`
+	autoscalePolicyListQuery = `
+SELECT app_id, process_type, min, max, target_requests_per_second, target_cpu, target_memory, scale_up_cooldown, scale_down_cooldown, last_scaled_at, created_at, updated_at
+FROM autoscale_policies WHERE app_id = $1 AND deleted_at IS NULL ORDER BY process_type`
+	autoscalePolicySelectQuery = `
+SELECT app_id, process_type, min, max, target_requests_per_second, target_cpu, target_memory, scale_up_cooldown, scale_down_cooldown, last_scaled_at, created_at, updated_at
+FROM autoscale_policies WHERE app_id = $1 AND process_type = $2 AND deleted_at IS NULL`
+	autoscalePolicySelectDueQuery = `
+SELECT p.app_id, p.process_type, p.min, p.max, p.target_requests_per_second, p.target_cpu, p.target_memory, p.scale_up_cooldown, p.scale_down_cooldown, p.last_scaled_at, p.created_at, p.updated_at
+FROM autoscale_policies AS p INNER JOIN apps AS a USING (app_id)
+WHERE (p.evaluated_at IS NULL OR p.evaluated_at <= now() - make_interval(secs => $1))
+AND p.deleted_at IS NULL AND a.deleted_at IS NULL
+ORDER BY p.evaluated_at NULLS FIRST LIMIT 1 FOR UPDATE OF p SKIP LOCKED`
+	autoscalePolicyUpsertQuery = `
+INSERT INTO autoscale_policies (app_id, process_type, min, max, target_requests_per_second, target_cpu, target_memory, scale_up_cooldown, scale_down_cooldown)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (app_id, process_type) WHERE deleted_at IS NULL DO UPDATE
+SET min = $3, max = $4, target_requests_per_second = $5, target_cpu = $6, target_memory = $7, scale_up_cooldown = $8, scale_down_cooldown = $9, updated_at = now()
+RETURNING last_scaled_at, created_at, updated_at`
+	autoscalePolicyUpdateEvaluatedQuery = `
+UPDATE autoscale_policies SET evaluated_at = now(), last_scaled_at = CASE WHEN $3 THEN now() ELSE last_scaled_at END
+WHERE app_id = $1 AND process_type = $2 AND deleted_at IS NULL`
+	autoscalePolicyDeleteQuery = `
+UPDATE autoscale_policies SET deleted_at = now() WHERE app_id = $1 AND process_type = $2 AND deleted_at IS NULL`
 	httpRouteListQuery = `
-SELECT r.id, r.parent_ref, r.service, r.port, r.leader, r.drain_backends, r.domain, r.sticky, r.path, r.disable_keep_alives, r.created_at, r.updated_at, c.id, c.cert, c.key, c.created_at, c.updated_at FROM http_routes as r
+SELECT r.id, r.parent_ref, r.service, r.port, r.leader, r.drain_backends, r.domain, r.sticky, r.path, r.disable_keep_alives, r.canary_release_id, r.canary_weight, r.created_at, r.updated_at, c.id, c.cert, c.key, c.created_at, c.updated_at FROM http_routes as r
 LEFT OUTER JOIN route_certificates AS rc on r.id = rc.http_route_id
 LEFT OUTER JOIN certificates AS c ON c.id = rc.certificate_id
 WHERE r.deleted_at IS NULL
 ORDER BY r.domain, r.path`
 	httpRouteListByParentRefQuery = `
-SELECT r.id, r.parent_ref, r.service, r.port, r.leader, r.drain_backends, r.domain, r.sticky, r.path, r.disable_keep_alives, r.created_at, r.updated_at, c.id, c.cert, c.key, c.created_at, c.updated_at FROM http_routes as r
+SELECT r.id, r.parent_ref, r.service, r.port, r.leader, r.drain_backends, r.domain, r.sticky, r.path, r.disable_keep_alives, r.canary_release_id, r.canary_weight, r.created_at, r.updated_at, c.id, c.cert, c.key, c.created_at, c.updated_at FROM http_routes as r
 LEFT OUTER JOIN route_certificates AS rc on r.id = rc.http_route_id
 LEFT OUTER JOIN certificates AS c ON c.id = rc.certificate_id
 WHERE r.parent_ref = $1 AND r.deleted_at IS NULL
 ORDER BY r.domain, r.path`
 	httpRouteInsertQuery = `
-INSERT INTO http_routes (parent_ref, service, port, leader, drain_backends, domain, sticky, path, disable_keep_alives)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+INSERT INTO http_routes (parent_ref, service, port, leader, drain_backends, domain, sticky, path, disable_keep_alives, canary_release_id, canary_weight)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 RETURNING id, path, created_at, updated_at`
 	httpRouteSelectQuery = `
-SELECT r.id, r.parent_ref, r.service, r.port, r.leader, r.drain_backends, r.domain, r.sticky, r.path, r.disable_keep_alives, r.created_at, r.updated_at, c.id, c.cert, c.key, c.created_at, c.updated_at FROM http_routes as r
+SELECT r.id, r.parent_ref, r.service, r.port, r.leader, r.drain_backends, r.domain, r.sticky, r.path, r.disable_keep_alives, r.canary_release_id, r.canary_weight, r.created_at, r.updated_at, c.id, c.cert, c.key, c.created_at, c.updated_at FROM http_routes as r
 LEFT OUTER JOIN route_certificates AS rc on r.id = rc.http_route_id
 LEFT OUTER JOIN certificates AS c ON c.id = rc.certificate_id
 WHERE r.id = $1 AND r.deleted_at IS NULL`
 	httpRouteUpdateQuery = `
 UPDATE http_routes as r
-SET parent_ref = $1, service = $2, port = $3, leader = $4, sticky = $5, path = $6, disable_keep_alives = $7
+SET parent_ref = $1, service = $2, port = $3, leader = $4, sticky = $5, path = $6, disable_keep_alives = $7, canary_release_id = $10, canary_weight = $11
 WHERE id = $8 AND domain = $9 AND deleted_at IS NULL
-RETURNING r.id, r.parent_ref, r.service, r.port, r.leader, r.drain_backends, r.domain, r.sticky, r.path, r.disable_keep_alives, r.created_at, r.updated_at`
+RETURNING r.id, r.parent_ref, r.service, r.port, r.leader, r.drain_backends, r.domain, r.sticky, r.path, r.disable_keep_alives, r.canary_release_id, r.canary_weight, r.created_at, r.updated_at`
 	httpRouteDeleteQuery = `
 UPDATE http_routes SET deleted_at = now()
 WHERE id = $1`
@@ -685,4 +834,91 @@ WHERE http_route_id = $1`
 	routeCertificateInsertQuery = `
 INSERT INTO route_certificates (http_route_id, certificate_id)
 VALUES ($1, $2)`
+	tokenListQuery = `
+SELECT token_id, name, role, apps, resource_types, created_at FROM tokens WHERE deleted_at IS NULL ORDER BY created_at DESC`
+	tokenSelectQuery = `
+SELECT token_id, name, role, apps, resource_types, created_at FROM tokens WHERE token_id = $1 AND deleted_at IS NULL`
+	tokenSelectByKeyHashQuery = `
+SELECT token_id, name, role, apps, resource_types, created_at FROM tokens WHERE key_hash = $1 AND deleted_at IS NULL`
+	tokenInsertQuery = `
+INSERT INTO tokens (token_id, name, role, apps, resource_types, key_hash) VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at`
+	tokenDeleteQuery = `
+UPDATE tokens SET deleted_at = now() WHERE token_id = $1 AND deleted_at IS NULL`
+	auditInsertQuery = `
+INSERT INTO audit_log (key_id, method, path, app_id, body, status, client_ip, user_agent)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING audit_id, created_at`
+	webhookListQuery = `
+SELECT webhook_id, url, apps, event_types, created_at FROM webhooks WHERE deleted_at IS NULL ORDER BY created_at DESC`
+	webhookSelectQuery = `
+SELECT webhook_id, url, apps, event_types, created_at FROM webhooks WHERE webhook_id = $1 AND deleted_at IS NULL`
+	webhookSelectWithSecretQuery = `
+SELECT webhook_id, url, apps, event_types, created_at, secret FROM webhooks WHERE webhook_id = $1 AND deleted_at IS NULL`
+	webhookInsertQuery = `
+INSERT INTO webhooks (webhook_id, url, apps, event_types, secret) VALUES ($1, $2, $3, $4, $5) RETURNING created_at`
+	webhookDeleteQuery = `
+UPDATE webhooks SET deleted_at = now() WHERE webhook_id = $1 AND deleted_at IS NULL`
+	webhookDeliveryListQuery = `
+SELECT d.delivery_id, d.webhook_id, d.event_id, e.object_type, e.app_id, d.state, d.attempts, d.response_status, d.error, d.created_at, d.updated_at
+FROM webhook_deliveries d JOIN events e USING (event_id)
+WHERE d.webhook_id = $1 ORDER BY d.created_at DESC LIMIT $2`
+	webhookDeliverySelectQuery = `
+SELECT d.delivery_id, d.webhook_id, d.event_id, e.object_type, e.app_id, d.state, d.attempts, d.response_status, d.error, d.created_at, d.updated_at
+FROM webhook_deliveries d JOIN events e USING (event_id)
+WHERE d.delivery_id = $1`
+	webhookDeliveryUpdateQuery = `
+UPDATE webhook_deliveries SET state = $2, attempts = attempts + 1, response_status = $3, error = $4, updated_at = now()
+WHERE delivery_id = $1 RETURNING attempts, updated_at`
+	envGroupListQuery = `
+SELECT g.env_group_id, g.name, g.env, g.auto_deploy, (
+  SELECT jsonb_agg(a.app_id ORDER BY a.position) FROM app_env_groups a JOIN apps USING (app_id)
+  WHERE a.env_group_id = g.env_group_id AND apps.deleted_at IS NULL
+), g.created_at, g.updated_at
+FROM env_groups g ORDER BY g.name`
+	envGroupListAppQuery = `
+SELECT g.env_group_id, g.name, g.env, g.auto_deploy, (
+  SELECT jsonb_agg(a.app_id ORDER BY a.position) FROM app_env_groups a JOIN apps USING (app_id)
+  WHERE a.env_group_id = g.env_group_id AND apps.deleted_at IS NULL
+), g.created_at, g.updated_at
+FROM env_groups g JOIN app_env_groups ag USING (env_group_id)
+WHERE ag.app_id = $1 ORDER BY ag.position`
+	envGroupSelectByNameQuery = `
+SELECT g.env_group_id, g.name, g.env, g.auto_deploy, (
+  SELECT jsonb_agg(a.app_id ORDER BY a.position) FROM app_env_groups a JOIN apps USING (app_id)
+  WHERE a.env_group_id = g.env_group_id AND apps.deleted_at IS NULL
+), g.created_at, g.updated_at
+FROM env_groups g WHERE g.name = $1`
+	envGroupSelectByNameOrIDQuery = `
+SELECT g.env_group_id, g.name, g.env, g.auto_deploy, (
+  SELECT jsonb_agg(a.app_id ORDER BY a.position) FROM app_env_groups a JOIN apps USING (app_id)
+  WHERE a.env_group_id = g.env_group_id AND apps.deleted_at IS NULL
+), g.created_at, g.updated_at
+FROM env_groups g WHERE g.env_group_id = $1 OR g.name = $2 LIMIT 1`
+	envGroupInsertQuery = `
+INSERT INTO env_groups (env_group_id, name, env, auto_deploy) VALUES ($1, $2, $3, $4) RETURNING created_at, updated_at`
+	envGroupUpdateQuery = `
+UPDATE env_groups SET env = $2, auto_deploy = $3, updated_at = now() WHERE env_group_id = $1 RETURNING updated_at`
+	envGroupDeleteQuery = `
+DELETE FROM env_groups WHERE env_group_id = $1`
+	envGroupAttachQuery = `
+INSERT INTO app_env_groups (app_id, env_group_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	envGroupDetachQuery = `
+DELETE FROM app_env_groups WHERE app_id = $1 AND env_group_id = $2`
+	quotaSelectQuery = `
+SELECT jobs, memory, cpu, volumes, routes, updated_at FROM app_quotas WHERE app_id = $1`
+	quotaUpsertQuery = `
+INSERT INTO app_quotas (app_id, jobs, memory, cpu, volumes, routes) VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (app_id) DO UPDATE
+SET jobs = $2, memory = $3, cpu = $4, volumes = $5, routes = $6, updated_at = now()
+RETURNING updated_at`
+	quotaCountOneOffJobsQuery = `
+SELECT count(*) FROM job_cache
+WHERE app_id = $1 AND COALESCE(process_type, '') = ''
+AND state IN ('pending', 'blocked', 'starting', 'up')`
+	quotaSelectDeploymentReleasesQuery = `
+SELECT old_release_id, new_release_id FROM deployments WHERE app_id = $1 AND finished_at IS NULL AND old_release_id IS NOT NULL`
+	hostDrainListQuery = `
+SELECT host_id, state, events FROM host_drains ORDER BY updated_at DESC`
+	hostDrainUpsertQuery = `
+INSERT INTO host_drains (host_id, state, events) VALUES ($1, $2, $3)
+ON CONFLICT (host_id) DO UPDATE SET state = $2, events = $3, updated_at = now()`
 )
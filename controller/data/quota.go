@@ -0,0 +1,305 @@
+package data
+
+import (
+	"fmt"
+
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/host/resource"
+	"github.com/flynn/flynn/pkg/postgres"
+	"github.com/jackc/pgx"
+)
+
+type QuotaRepo struct {
+	db         *postgres.DB
+	formations *FormationRepo
+	releases   *ReleaseRepo
+	routes     *RouteRepo
+}
+
+func NewQuotaRepo(db *postgres.DB, formations *FormationRepo, releases *ReleaseRepo, routes *RouteRepo) *QuotaRepo {
+	return &QuotaRepo{db: db, formations: formations, releases: releases, routes: routes}
+}
+
+// Get returns the quota of the given app, which has no limits if a quota has
+// not been set
+func (r *QuotaRepo) Get(appID string) (*ct.AppQuota, error) {
+	q := &ct.AppQuota{AppID: appID}
+	l := &q.Limits
+	err := r.db.QueryRow("quota_select", appID).Scan(&l.Jobs, &l.Memory, &l.CPU, &l.Volumes, &l.Routes, &q.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		err = nil
+	}
+	return q, err
+}
+
+func (r *QuotaRepo) Put(q *ct.AppQuota) error {
+	l := q.Limits
+	for field, v := range map[string]int64{
+		"jobs":    l.Jobs,
+		"memory":  l.Memory,
+		"cpu":     l.CPU,
+		"volumes": l.Volumes,
+		"routes":  l.Routes,
+	} {
+		if v < 0 {
+			return ct.ValidationError{Field: "limits." + field, Message: "must not be negative"}
+		}
+	}
+	return r.db.QueryRow("quota_upsert", q.AppID, l.Jobs, l.Memory, l.CPU, l.Volumes, l.Routes).Scan(&q.UpdatedAt)
+}
+
+// jobQuotaResources returns the quota resources used by a single job with
+// the given resources
+func jobQuotaResources(r resource.Resources) *ct.QuotaResources {
+	return &ct.QuotaResources{
+		Jobs:   1,
+		Memory: resourceAmount(r, resource.TypeMemory),
+		CPU:    resourceAmount(r, resource.TypeCPU),
+	}
+}
+
+// resourceAmount returns the amount of the given resource requested by a job,
+// which is its limit if no request is set
+func resourceAmount(r resource.Resources, typ resource.Type) int64 {
+	spec := r[typ]
+	if spec.Request != nil {
+		return *spec.Request
+	}
+	if spec.Limit != nil {
+		return *spec.Limit
+	}
+	return 0
+}
+
+// Usage returns the resources used by the given app, with the app's
+// formation for the given release replaced with the given processes if
+// releaseID is set.
+//
+// The formations of the old and new releases of an in-progress deployment
+// are counted as the larger of the two for each process type so that
+// deployments do not exceed the quota while both releases are running.
+func (r *QuotaRepo) Usage(appID, releaseID string, processes map[string]int) (*ct.QuotaResources, error) {
+	deploy, err := r.deploymentReleases(appID)
+	if err != nil {
+		return nil, err
+	}
+	release, err := r.getRelease(releaseID)
+	if err != nil {
+		return nil, err
+	}
+	return r.usage(appID, release, processes, deploy)
+}
+
+// getRelease returns the release with the given ID, or nil if releaseID is
+// not set
+func (r *QuotaRepo) getRelease(releaseID string) (*ct.Release, error) {
+	if releaseID == "" {
+		return nil, nil
+	}
+	return r.releases.TxGet(r.db, releaseID)
+}
+
+// deployReleases are the old and new releases of a deployment
+type deployReleases struct {
+	oldReleaseID string
+	newReleaseID string
+}
+
+func (d *deployReleases) includes(releaseID string) bool {
+	return d != nil && (releaseID == d.oldReleaseID || releaseID == d.newReleaseID)
+}
+
+// deploymentReleases returns the releases of the app's in-progress
+// deployment, if any
+func (r *QuotaRepo) deploymentReleases(appID string) (*deployReleases, error) {
+	d := &deployReleases{}
+	if err := r.db.QueryRow("quota_select_deployment_releases", appID).Scan(&d.oldReleaseID, &d.newReleaseID); err == pgx.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// usage returns the resources used by the given app, with the app's
+// formation for the given release, which need not have been created yet,
+// replaced with the given processes if release is set
+func (r *QuotaRepo) usage(appID string, release *ct.Release, processes map[string]int, deploy *deployReleases) (*ct.QuotaResources, error) {
+	usage := &ct.QuotaResources{}
+
+	formations, err := r.formations.List(appID)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]map[string]int, len(formations)+1)
+	for _, f := range formations {
+		counts[f.ReleaseID] = f.Processes
+	}
+	if release != nil {
+		counts[release.ID] = processes
+	}
+	deployed := make(map[string]*ct.QuotaResources)
+	for id, procs := range counts {
+		rel := release
+		if rel == nil || id != rel.ID {
+			if rel, err = r.releases.TxGet(r.db, id); err != nil {
+				return nil, err
+			}
+		}
+		for typ, count := range procs {
+			proc, ok := rel.Processes[typ]
+			if !ok || count <= 0 {
+				continue
+			}
+			resource.SetDefaults(&proc.Resources)
+			job := jobQuotaResources(proc.Resources)
+			n := int64(count)
+			u := &ct.QuotaResources{
+				Jobs:    n,
+				Memory:  n * job.Memory,
+				CPU:     n * job.CPU,
+				Volumes: n * int64(len(proc.Volumes)),
+			}
+			if !deploy.includes(id) {
+				addQuotaResources(usage, u)
+				continue
+			}
+			if d, ok := deployed[typ]; ok {
+				maxQuotaResources(d, u)
+			} else {
+				deployed[typ] = u
+			}
+		}
+	}
+	for _, u := range deployed {
+		addQuotaResources(usage, u)
+	}
+
+	var oneOffJobs int64
+	if err := r.db.QueryRow("quota_count_one_off_jobs", appID).Scan(&oneOffJobs); err != nil {
+		return nil, err
+	}
+	usage.Jobs += oneOffJobs
+
+	routes, err := r.routes.List(ct.RouteParentRefPrefix + appID)
+	if err != nil {
+		return nil, err
+	}
+	usage.Routes = int64(len(routes))
+
+	return usage, nil
+}
+
+func addQuotaResources(to, r *ct.QuotaResources) {
+	to.Jobs += r.Jobs
+	to.Memory += r.Memory
+	to.CPU += r.CPU
+	to.Volumes += r.Volumes
+	to.Routes += r.Routes
+}
+
+func maxQuotaResources(to, r *ct.QuotaResources) {
+	for _, v := range []struct{ to, r *int64 }{
+		{&to.Jobs, &r.Jobs},
+		{&to.Memory, &r.Memory},
+		{&to.CPU, &r.CPU},
+		{&to.Volumes, &r.Volumes},
+		{&to.Routes, &r.Routes},
+	} {
+		if *v.r > *v.to {
+			*v.to = *v.r
+		}
+	}
+}
+
+// Check returns a ValidationError naming the exceeded quota if changing the
+// app's formation for the given release to the given processes (if releaseID
+// is set) and adding the extra resources would exceed the app's quota.
+// Changes which do not increase the usage of a resource are permitted even
+// if the quota is already exceeded (e.g. when scaling down after lowering a
+// quota).
+func (r *QuotaRepo) Check(appID, releaseID string, processes map[string]int, extra *ct.QuotaResources) error {
+	deploy, err := r.deploymentReleases(appID)
+	if err != nil {
+		return err
+	}
+	release, err := r.getRelease(releaseID)
+	if err != nil {
+		return err
+	}
+	return r.check(appID, release, processes, extra, deploy)
+}
+
+// CheckDeploy returns a ValidationError naming the exceeded quota if
+// deploying the given release with the given processes in place of the
+// app's current release would exceed the app's quota
+func (r *QuotaRepo) CheckDeploy(appID, oldReleaseID, releaseID string, processes map[string]int) error {
+	release, err := r.getRelease(releaseID)
+	if err != nil {
+		return err
+	}
+	return r.CheckRelease(appID, oldReleaseID, release, processes, nil)
+}
+
+// CheckRelease is like CheckDeploy but also adds the extra resources and
+// accepts a release which has not been created yet, so that the quota can be
+// checked before creating it
+func (r *QuotaRepo) CheckRelease(appID, oldReleaseID string, release *ct.Release, processes map[string]int, extra *ct.QuotaResources) error {
+	return r.check(appID, release, processes, extra, &deployReleases{oldReleaseID: oldReleaseID, newReleaseID: release.ID})
+}
+
+func (r *QuotaRepo) check(appID string, release *ct.Release, processes map[string]int, extra *ct.QuotaResources, deploy *deployReleases) error {
+	quota, err := r.Get(appID)
+	if err != nil {
+		return err
+	}
+	if quota.Limits == (ct.QuotaResources{}) {
+		return nil
+	}
+	current, err := r.Usage(appID, "", nil)
+	if err != nil {
+		return err
+	}
+	proposed := *current
+	if release != nil {
+		usage, err := r.usage(appID, release, processes, deploy)
+		if err != nil {
+			return err
+		}
+		proposed = *usage
+	}
+	if extra != nil {
+		addQuotaResources(&proposed, extra)
+	}
+
+	for _, c := range []struct {
+		name              string
+		limit, cur, value int64
+		format            func(int64) string
+	}{
+		{"jobs", quota.Limits.Jobs, current.Jobs, proposed.Jobs, nil},
+		{"memory", quota.Limits.Memory, current.Memory, proposed.Memory, func(v int64) string { return resource.FormatLimit(resource.TypeMemory, v) }},
+		{"cpu", quota.Limits.CPU, current.CPU, proposed.CPU, func(v int64) string { return fmt.Sprintf("%dm", v) }},
+		{"volumes", quota.Limits.Volumes, current.Volumes, proposed.Volumes, nil},
+		{"routes", quota.Limits.Routes, current.Routes, proposed.Routes, nil},
+	} {
+		if c.limit == 0 || c.value <= c.limit || c.value <= c.cur {
+			continue
+		}
+		format := c.format
+		if format == nil {
+			format = func(v int64) string { return fmt.Sprint(v) }
+		}
+		return ct.ValidationError{
+			Field:   "quota." + c.name,
+			Message: fmt.Sprintf("would exceed the app's %s quota of %s (requested %s)", c.name, format(c.limit), format(c.value)),
+		}
+	}
+	return nil
+}
+
+// CheckJob returns a ValidationError naming the exceeded quota if running a
+// one-off job with the given resources would exceed the app's quota
+func (r *QuotaRepo) CheckJob(appID string, resources resource.Resources) error {
+	return r.Check(appID, "", nil, jobQuotaResources(resources))
+}
@@ -3,7 +3,9 @@ package data
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 
+	"github.com/flynn/flynn/controller/secrets"
 	ct "github.com/flynn/flynn/controller/types"
 	"github.com/flynn/flynn/host/resource"
 	"github.com/flynn/flynn/pkg/postgres"
@@ -18,13 +20,15 @@ type ReleaseRepo struct {
 	artifacts  *ArtifactRepo
 	formations *FormationRepo
 	que        *que.Client
+	secrets    *secrets.Keyring
 }
 
-func NewReleaseRepo(db *postgres.DB, artifacts *ArtifactRepo, que *que.Client) *ReleaseRepo {
+func NewReleaseRepo(db *postgres.DB, artifacts *ArtifactRepo, que *que.Client, keyring *secrets.Keyring) *ReleaseRepo {
 	return &ReleaseRepo{
 		db:        db,
 		artifacts: artifacts,
 		que:       que,
+		secrets:   keyring,
 	}
 }
 
@@ -44,12 +48,227 @@ func scanRelease(s postgres.Scanner) (*ct.Release, error) {
 	if len(release.ArtifactIDs) > 0 {
 		release.LegacyArtifactID = release.ArtifactIDs[0]
 	}
+	setReleaseSecrets(release)
 	return release, err
 }
 
+// setReleaseSecrets sets release.Secrets to the names of the release's
+// encrypted env vars
+func setReleaseSecrets(release *ct.Release) {
+	release.Secrets = nil
+	for name, value := range release.Env {
+		if secrets.IsEncrypted(value) {
+			release.Secrets = append(release.Secrets, name)
+		}
+	}
+	sort.Strings(release.Secrets)
+}
+
+// encryptSecrets encrypts the values of the release's secret env vars which
+// are not already encrypted, and checks that already encrypted values (e.g.
+// those copied from a previous release) belong to the release's app
+func (r *ReleaseRepo) encryptSecrets(release *ct.Release) error {
+	secret := make(map[string]struct{}, len(release.Secrets))
+	for _, name := range release.Secrets {
+		secret[name] = struct{}{}
+	}
+	for name, value := range release.Env {
+		field := fmt.Sprintf("env.%s", name)
+		if secrets.IsEncrypted(value) {
+			if _, err := r.secrets.Decrypt(release.AppID, value); err != nil {
+				return ct.ValidationError{Field: field, Message: err.Error()}
+			}
+			continue
+		}
+		if _, ok := secret[name]; !ok {
+			continue
+		}
+		encrypted, err := r.secrets.Encrypt(release.AppID, value)
+		if err == secrets.ErrNoKeyring {
+			return ct.ValidationError{Field: field, Message: err.Error()}
+		} else if err != nil {
+			return err
+		}
+		release.Env[name] = encrypted
+	}
+	setReleaseSecrets(release)
+	return nil
+}
+
+// CanEncryptSecrets returns whether the repo has cluster keys to encrypt secret
+// env vars with
+func (r *ReleaseRepo) CanEncryptSecrets() bool {
+	return r.secrets != nil
+}
+
+// DecryptSecrets decrypts the release's secret env vars in place
+func (r *ReleaseRepo) DecryptSecrets(release *ct.Release) error {
+	return r.secrets.DecryptEnv(release.AppID, release.Env)
+}
+
+// RewrapSecrets re-encrypts the data keys of up to count releases' secret env
+// vars which are not encrypted with the active cluster key, returning the
+// number of releases updated
+func (r *ReleaseRepo) RewrapSecrets(count int) (int, error) {
+	if r.secrets == nil {
+		return 0, secrets.ErrNoKeyring
+	}
+	rows, err := r.db.Query("release_list_secrets_to_rewrap", r.secrets.ActiveKeyID(), count)
+	if err != nil {
+		return 0, err
+	}
+	type releaseEnv struct {
+		id  string
+		env map[string]string
+	}
+	var releases []*releaseEnv
+	for rows.Next() {
+		release := &releaseEnv{}
+		if err := rows.Scan(&release.id, &release.env); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		releases = append(releases, release)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	for _, release := range releases {
+		for name, value := range release.env {
+			if !r.secrets.NeedsRewrap(value) {
+				continue
+			}
+			rewrapped, err := r.secrets.Rewrap(value)
+			if err != nil {
+				return 0, fmt.Errorf("error rewrapping env var %s of release %s: %s", name, release.id, err)
+			}
+			release.env[name] = rewrapped
+		}
+		if err := r.db.Exec("release_update_env", release.id, release.env); err != nil {
+			return 0, err
+		}
+	}
+	return len(releases), nil
+}
+
+// validateRestartPolicy validates the given process type's restart policy,
+// setting the window and backoff cap to their defaults if not set
+func validateRestartPolicy(typ string, policy *ct.RestartPolicy) error {
+	field := func(name string) string {
+		return fmt.Sprintf("processes.%s.restart_policy.%s", typ, name)
+	}
+	switch policy.Type {
+	case "":
+		policy.Type = ct.RestartPolicyAlways
+	case ct.RestartPolicyAlways, ct.RestartPolicyOnFailure, ct.RestartPolicyNever:
+	default:
+		return ct.ValidationError{
+			Field:   field("type"),
+			Message: fmt.Sprintf("must be one of %q, %q or %q", ct.RestartPolicyAlways, ct.RestartPolicyOnFailure, ct.RestartPolicyNever),
+		}
+	}
+	for name, n := range map[string]int{
+		"max_restarts": policy.MaxRestarts,
+		"window":       policy.Window,
+		"backoff_base": policy.BackoffBase,
+		"backoff_cap":  policy.BackoffCap,
+	} {
+		if n < 0 {
+			return ct.ValidationError{Field: field(name), Message: "must not be negative"}
+		}
+	}
+	if policy.MaxRestarts > 0 && policy.Window == 0 {
+		policy.Window = ct.DefaultRestartWindow
+	}
+	if policy.BackoffBase > 0 && policy.BackoffCap == 0 {
+		policy.BackoffCap = ct.DefaultRestartBackoffCap
+	}
+	if policy.BackoffCap > 0 && policy.BackoffCap < policy.BackoffBase {
+		return ct.ValidationError{Field: field("backoff_cap"), Message: "must not be less than backoff_base"}
+	}
+	return nil
+}
+
+// validateDisruptionBudget validates that the given process type's disruption
+// budget sets exactly one of max_unavailable or min_available
+func validateDisruptionBudget(typ string, budget *ct.DisruptionBudget) error {
+	field := fmt.Sprintf("processes.%s.disruption_budget", typ)
+	switch {
+	case budget.MaxUnavailable < 0:
+		return ct.ValidationError{Field: field + ".max_unavailable", Message: "must not be negative"}
+	case budget.MinAvailable < 0:
+		return ct.ValidationError{Field: field + ".min_available", Message: "must not be negative"}
+	case budget.MaxUnavailable > 0 && budget.MinAvailable > 0:
+		return ct.ValidationError{Field: field, Message: "must not set both max_unavailable and min_available"}
+	case budget.MaxUnavailable == 0 && budget.MinAvailable == 0:
+		return ct.ValidationError{Field: field, Message: "must set either max_unavailable or min_available"}
+	}
+	return nil
+}
+
+// Validate returns a ValidationError if the release is invalid, without
+// storing it
+func (r *ReleaseRepo) Validate(release *ct.Release) error {
+	for typ, proc := range release.Processes {
+		for i, spread := range proc.Spread {
+			if spread.TagKey == "" {
+				return ct.ValidationError{
+					Field:   fmt.Sprintf("processes.%s.spread[%d].tag_key", typ, i),
+					Message: "must not be empty",
+				}
+			}
+			if spread.MaxSkew < 0 {
+				return ct.ValidationError{
+					Field:   fmt.Sprintf("processes.%s.spread[%d].max_skew", typ, i),
+					Message: "must not be negative",
+				}
+			}
+		}
+		switch proc.Partition {
+		case "", ct.PartitionTypeBackground, ct.PartitionTypeSystem, ct.PartitionTypeUser:
+		default:
+			return ct.ValidationError{
+				Field:   fmt.Sprintf("processes.%s.partition", typ),
+				Message: fmt.Sprintf("must be one of %q, %q or %q", ct.PartitionTypeBackground, ct.PartitionTypeUser, ct.PartitionTypeSystem),
+			}
+		}
+		for i, rule := range proc.Affinity {
+			if rule.AppID == "" && rule.Type == typ {
+				return ct.ValidationError{
+					Field:   fmt.Sprintf("processes.%s.affinity[%d]", typ, i),
+					Message: "cannot reference its own process type",
+				}
+			}
+		}
+		if policy := proc.RestartPolicy; policy != nil {
+			if err := validateRestartPolicy(typ, policy); err != nil {
+				return err
+			}
+		}
+		if budget := proc.DisruptionBudget; budget != nil {
+			if err := validateDisruptionBudget(typ, budget); err != nil {
+				return err
+			}
+		}
+	}
+
+	if value, ok := release.Env[""]; ok {
+		return ct.ValidationError{
+			Field:   "env",
+			Message: fmt.Sprintf("you can't create an env var with an empty key (tried to set \"\"=%q)", value),
+		}
+	}
+	return nil
+}
+
 func (r *ReleaseRepo) Add(data interface{}) error {
 	release := data.(*ct.Release)
 
+	if err := r.Validate(release); err != nil {
+		return err
+	}
+
 	for typ, proc := range release.Processes {
 		// handle deprecated Entrypoint and Cmd
 		if len(proc.DeprecatedEntrypoint) > 0 {
@@ -64,6 +283,11 @@ func (r *ReleaseRepo) Add(data interface{}) error {
 			proc.DeprecatedData = false
 		}
 		resource.SetDefaults(&proc.Resources)
+		for i, spread := range proc.Spread {
+			if spread.MaxSkew == 0 {
+				proc.Spread[i].MaxSkew = 1
+			}
+		}
 		release.Processes[typ] = proc
 	}
 
@@ -74,11 +298,8 @@ func (r *ReleaseRepo) Add(data interface{}) error {
 		release.ArtifactIDs = []string{release.LegacyArtifactID}
 	}
 
-	if value, ok := release.Env[""]; ok {
-		return ct.ValidationError{
-			Field:   "env",
-			Message: fmt.Sprintf("you can't create an env var with an empty key (tried to set \"\"=%q)", value),
-		}
+	if err := r.encryptSecrets(release); err != nil {
+		return err
 	}
 
 	tx, err := r.db.Begin()
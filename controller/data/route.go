@@ -69,6 +69,9 @@ func (r *RouteRepo) addHTTP(tx *postgres.DBTx, route *router.Route) error {
 	if route.Port > 0 {
 		return ErrRouteInvalid
 	}
+	if err := validateCanary(route); err != nil {
+		return err
+	}
 	if err := tx.QueryRow(
 		"http_route_insert",
 		route.ParentRef,
@@ -80,12 +83,39 @@ func (r *RouteRepo) addHTTP(tx *postgres.DBTx, route *router.Route) error {
 		route.Sticky,
 		route.Path,
 		route.DisableKeepAlives,
+		canaryReleaseID(route),
+		route.CanaryWeight,
 	).Scan(&route.ID, &route.Path, &route.CreatedAt, &route.UpdatedAt); err != nil {
 		return err
 	}
 	return r.addRouteCertWithTx(tx, route)
 }
 
+// validateCanary checks that an HTTP route's canary weight is a percentage
+// and is only set along with a canary release
+func validateCanary(route *router.Route) error {
+	if route.CanaryWeight < 0 || route.CanaryWeight > 100 {
+		return httphelper.JSONError{
+			Code:    httphelper.ValidationErrorCode,
+			Message: "canary_weight must be between 0 and 100",
+		}
+	}
+	if route.CanaryWeight > 0 && route.CanaryRelease == "" {
+		return httphelper.JSONError{
+			Code:    httphelper.ValidationErrorCode,
+			Message: "canary_release must be set if canary_weight is set",
+		}
+	}
+	return nil
+}
+
+func canaryReleaseID(route *router.Route) *string {
+	if route.CanaryRelease == "" {
+		return nil
+	}
+	return &route.CanaryRelease
+}
+
 func (r *RouteRepo) addTCP(tx *postgres.DBTx, route *router.Route) error {
 	// TODO: check non-default HTTP ports if set
 	if route.Port == 80 || route.Port == 443 {
@@ -188,6 +218,7 @@ func (r *RouteRepo) getHTTP(id string) (*router.Route, error) {
 func scanHTTPRoute(s postgres.Scanner) (*router.Route, error) {
 	var (
 		route         router.Route
+		canaryRelease *string
 		certID        *string
 		certCert      *string
 		certKey       *string
@@ -205,6 +236,8 @@ func scanHTTPRoute(s postgres.Scanner) (*router.Route, error) {
 		&route.Sticky,
 		&route.Path,
 		&route.DisableKeepAlives,
+		&canaryRelease,
+		&route.CanaryWeight,
 		&route.CreatedAt,
 		&route.UpdatedAt,
 		&certID,
@@ -216,6 +249,9 @@ func scanHTTPRoute(s postgres.Scanner) (*router.Route, error) {
 		return nil, err
 	}
 	route.Type = "http"
+	if canaryRelease != nil {
+		route.CanaryRelease = *canaryRelease
+	}
 	if certID != nil {
 		route.Certificate = &router.Certificate{
 			ID:        *certID,
@@ -341,6 +377,10 @@ func (r *RouteRepo) Update(route *router.Route) error {
 }
 
 func (r *RouteRepo) updateHTTP(tx *postgres.DBTx, route *router.Route) error {
+	if err := validateCanary(route); err != nil {
+		return err
+	}
+	var canaryRelease *string
 	if err := tx.QueryRow(
 		"http_route_update",
 		route.ParentRef,
@@ -352,6 +392,8 @@ func (r *RouteRepo) updateHTTP(tx *postgres.DBTx, route *router.Route) error {
 		route.DisableKeepAlives,
 		route.ID,
 		route.Domain,
+		canaryReleaseID(route),
+		route.CanaryWeight,
 	).Scan(
 		&route.ID,
 		&route.ParentRef,
@@ -363,11 +405,17 @@ func (r *RouteRepo) updateHTTP(tx *postgres.DBTx, route *router.Route) error {
 		&route.Sticky,
 		&route.Path,
 		&route.DisableKeepAlives,
+		&canaryRelease,
+		&route.CanaryWeight,
 		&route.CreatedAt,
 		&route.UpdatedAt,
 	); err != nil {
 		return err
 	}
+	route.CanaryRelease = ""
+	if canaryRelease != nil {
+		route.CanaryRelease = *canaryRelease
+	}
 	return r.addRouteCertWithTx(tx, route)
 }
 
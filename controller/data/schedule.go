@@ -0,0 +1,338 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/pkg/cron"
+	"github.com/flynn/flynn/pkg/postgres"
+	"github.com/flynn/flynn/pkg/random"
+	"github.com/jackc/pgx"
+)
+
+type ScheduleRepo struct {
+	db *postgres.DB
+}
+
+func NewScheduleRepo(db *postgres.DB) *ScheduleRepo {
+	return &ScheduleRepo{db}
+}
+
+func (r *ScheduleRepo) Add(s *ct.Schedule) error {
+	c, err := cron.Parse(s.Cron)
+	if err != nil {
+		return ct.ValidationError{Field: "cron", Message: err.Error()}
+	}
+	next := c.Next(time.Now().UTC())
+	if next.IsZero() {
+		return ct.ValidationError{Field: "cron", Message: "never matches"}
+	}
+	s.NextRunAt = &next
+
+	switch s.Concurrency {
+	case "":
+		s.Concurrency = ct.ScheduleConcurrencyAllow
+	case ct.ScheduleConcurrencyAllow, ct.ScheduleConcurrencyForbid, ct.ScheduleConcurrencyReplace:
+	default:
+		return ct.ValidationError{
+			Field:   "concurrency",
+			Message: fmt.Sprintf("must be one of %q, %q or %q", ct.ScheduleConcurrencyAllow, ct.ScheduleConcurrencyForbid, ct.ScheduleConcurrencyReplace),
+		}
+	}
+
+	if s.ID == "" {
+		s.ID = random.UUID()
+	}
+	var releaseID *string
+	if s.ReleaseID != "" {
+		releaseID = &s.ReleaseID
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	err = tx.QueryRow(
+		"schedule_insert",
+		s.ID,
+		s.AppID,
+		releaseID,
+		s.Cron,
+		s.Args,
+		s.Env,
+		s.Resources,
+		string(s.Concurrency),
+		s.NextRunAt,
+	).Scan(&s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		tx.Rollback()
+		if postgres.IsPostgresCode(err, postgres.ForeignKeyViolation) {
+			return ct.ValidationError{Field: "release", Message: "does not exist"}
+		}
+		return err
+	}
+	if err := CreateEvent(tx.Exec, &ct.Event{
+		AppID:      s.AppID,
+		ObjectID:   s.ID,
+		ObjectType: ct.EventTypeSchedule,
+	}, s); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func scanSchedule(s postgres.Scanner) (*ct.Schedule, error) {
+	schedule := &ct.Schedule{}
+	var releaseID *string
+	var concurrency string
+	err := s.Scan(
+		&schedule.ID,
+		&schedule.AppID,
+		&releaseID,
+		&schedule.Cron,
+		&schedule.Args,
+		&schedule.Env,
+		&schedule.Resources,
+		&concurrency,
+		&schedule.NextRunAt,
+		&schedule.LastRunAt,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = ErrNotFound
+		}
+		return nil, err
+	}
+	if releaseID != nil {
+		schedule.ReleaseID = *releaseID
+	}
+	schedule.Concurrency = ct.ScheduleConcurrencyPolicy(concurrency)
+	return schedule, nil
+}
+
+func (r *ScheduleRepo) Get(appID, id string) (*ct.Schedule, error) {
+	if !idPattern.MatchString(id) {
+		return nil, ErrNotFound
+	}
+	return scanSchedule(r.db.QueryRow("schedule_select", appID, id))
+}
+
+func (r *ScheduleRepo) List(appID string) ([]*ct.Schedule, error) {
+	rows, err := r.db.Query("schedule_list", appID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var schedules []*ct.Schedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, rows.Err()
+}
+
+func (r *ScheduleRepo) Remove(appID, id string) (*ct.Schedule, error) {
+	schedule, err := r.Get(appID, id)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Exec("schedule_delete", schedule.ID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := CreateEvent(tx.Exec, &ct.Event{
+		AppID:      schedule.AppID,
+		ObjectID:   schedule.ID,
+		ObjectType: ct.EventTypeScheduleDeletion,
+	}, schedule); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return schedule, tx.Commit()
+}
+
+func scanScheduleRun(s postgres.Scanner) (*ct.ScheduleRun, error) {
+	run := &ct.ScheduleRun{}
+	var releaseID, jobID, runErr *string
+	var state string
+	err := s.Scan(
+		&run.ID,
+		&run.ScheduleID,
+		&run.AppID,
+		&releaseID,
+		&jobID,
+		&state,
+		&runErr,
+		&run.ScheduledAt,
+		&run.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = ErrNotFound
+		}
+		return nil, err
+	}
+	if releaseID != nil {
+		run.ReleaseID = *releaseID
+	}
+	if jobID != nil {
+		run.JobID = *jobID
+	}
+	if runErr != nil {
+		run.Error = *runErr
+	}
+	run.State = ct.ScheduleRunState(state)
+	return run, nil
+}
+
+func (r *ScheduleRepo) ListRuns(appID, scheduleID string, count int) ([]*ct.ScheduleRun, error) {
+	rows, err := r.db.Query("schedule_run_list", appID, scheduleID, count)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var runs []*ct.ScheduleRun
+	for rows.Next() {
+		run, err := scanScheduleRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// ErrScheduleRunInterrupted is the error of runs which were interrupted
+// before their launch was recorded
+var ErrScheduleRunInterrupted = errors.New("controller: schedule run was interrupted before it was launched")
+
+// ScheduleLauncher launches the job for a schedule which is due to run,
+// given the schedule's most recent run which launched a job (which is nil if
+// no runs have launched a job), and returns a record of the run
+type ScheduleLauncher func(schedule *ct.Schedule, lastRun *ct.ScheduleRun) *ct.ScheduleRun
+
+// LaunchNextDue locks the schedule which has been due to run for the longest
+// time, records a pending run along with the schedule's next run time, and
+// then launches it using the given function and updates the run with the
+// result, returning ErrNotFound if no schedules are due.
+//
+// Locked schedules are skipped so that multiple launchers can run
+// concurrently without launching the same schedule twice, and the schedule
+// is advanced before the job is launched so that a failure to record the
+// launch does not lead to the run being launched again (the run is instead
+// left pending, and marked as failed when the schedule next runs).
+//
+// The last run which launched a job is passed to the launcher rather than
+// the last run so that runs which were skipped or failed do not hide a
+// previous job which is still active.
+func (r *ScheduleRepo) LaunchNextDue(launch ScheduleLauncher) (*ct.ScheduleRun, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := scanSchedule(tx.QueryRow("schedule_select_due"))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	lastRun, err := scanScheduleRun(tx.QueryRow("schedule_run_select_last_job", schedule.ID))
+	if err == ErrNotFound {
+		lastRun = nil
+	} else if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// earlier runs which are still pending were interrupted before
+	// their launch was recorded (e.g. by the controller restarting), as
+	// launches finish long before the schedule is next due (and a launch
+	// which is still in progress overwrites the state once it finishes)
+	if err := tx.Exec("schedule_run_fail_pending", schedule.ID, ErrScheduleRunInterrupted.Error()); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	run := &ct.ScheduleRun{
+		ID:          random.UUID(),
+		ScheduleID:  schedule.ID,
+		AppID:       schedule.AppID,
+		State:       ct.ScheduleRunStatePending,
+		ScheduledAt: schedule.NextRunAt,
+	}
+	if err := tx.QueryRow(
+		"schedule_run_insert",
+		run.ID,
+		run.ScheduleID,
+		run.AppID,
+		nil,
+		nil,
+		string(run.State),
+		nil,
+		run.ScheduledAt,
+	).Scan(&run.CreatedAt); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// schedule the next run from now rather than from when the run was
+	// due so that runs missed whilst no launchers were running are not
+	// all launched at once
+	var next *time.Time
+	if c, err := cron.Parse(schedule.Cron); err == nil {
+		if t := c.Next(time.Now().UTC()); !t.IsZero() {
+			next = &t
+		}
+	}
+	if err := tx.Exec("schedule_update_run", schedule.ID, next, run.CreatedAt); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	result := launch(schedule, lastRun)
+	run.ReleaseID = result.ReleaseID
+	run.JobID = result.JobID
+	run.State = result.State
+	run.Error = result.Error
+	var releaseID, jobID, runErr *string
+	if run.ReleaseID != "" {
+		releaseID = &run.ReleaseID
+	}
+	if run.JobID != "" {
+		jobID = &run.JobID
+	}
+	if run.Error != "" {
+		runErr = &run.Error
+	}
+	tx, err = r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Exec("schedule_run_update", run.ID, releaseID, jobID, string(run.State), runErr); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := CreateEvent(tx.Exec, &ct.Event{
+		AppID:      run.AppID,
+		ObjectID:   run.ID,
+		ObjectType: ct.EventTypeScheduleRun,
+	}, run); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return run, tx.Commit()
+}
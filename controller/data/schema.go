@@ -957,6 +957,213 @@ $$ LANGUAGE SQL;
 	migrations.Add(49, `
 ALTER TABLE http_routes ADD COLUMN disable_keep_alives boolean NOT NULL DEFAULT false;
 	`)
+	migrations.Add(50, `
+ALTER TABLE job_cache ADD COLUMN blocked_reason text;
+	`)
+	migrations.Add(51, `
+ALTER TABLE job_cache ADD COLUMN preempted_by uuid;
+	`)
+	migrations.Add(52,
+		`CREATE TABLE schedules (
+			schedule_id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+			app_id uuid NOT NULL REFERENCES apps (app_id),
+			release_id uuid REFERENCES releases (release_id),
+			cron text NOT NULL,
+			args jsonb,
+			env jsonb,
+			resources jsonb,
+			concurrency text NOT NULL,
+			next_run_at timestamptz,
+			last_run_at timestamptz,
+			created_at timestamptz NOT NULL DEFAULT now(),
+			updated_at timestamptz NOT NULL DEFAULT now(),
+			deleted_at timestamptz
+		)`,
+		`CREATE INDEX ON schedules (next_run_at) WHERE deleted_at IS NULL`,
+		`CREATE TABLE schedule_runs (
+			schedule_run_id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+			schedule_id uuid NOT NULL REFERENCES schedules (schedule_id),
+			app_id uuid NOT NULL REFERENCES apps (app_id),
+			release_id uuid,
+			job_id uuid,
+			state text NOT NULL,
+			error text,
+			scheduled_at timestamptz,
+			created_at timestamptz NOT NULL DEFAULT now()
+		)`,
+		`CREATE INDEX ON schedule_runs (schedule_id, created_at DESC)`,
+		`INSERT INTO event_types (name) VALUES ('schedule'), ('schedule_deletion'), ('schedule_run')`,
+	)
+	migrations.Add(53,
+		`CREATE TABLE autoscale_policies (
+			app_id uuid NOT NULL REFERENCES apps (app_id),
+			process_type text NOT NULL,
+			min integer NOT NULL,
+			max integer NOT NULL,
+			target_requests_per_second double precision NOT NULL DEFAULT 0,
+			target_cpu integer NOT NULL DEFAULT 0,
+			target_memory integer NOT NULL DEFAULT 0,
+			scale_up_cooldown integer NOT NULL,
+			scale_down_cooldown integer NOT NULL,
+			last_scaled_at timestamptz,
+			evaluated_at timestamptz,
+			created_at timestamptz NOT NULL DEFAULT now(),
+			updated_at timestamptz NOT NULL DEFAULT now(),
+			deleted_at timestamptz
+		)`,
+		`CREATE UNIQUE INDEX ON autoscale_policies (app_id, process_type) WHERE deleted_at IS NULL`,
+		`INSERT INTO event_types (name) VALUES ('autoscale_policy'), ('autoscale_policy_deletion'), ('autoscale')`,
+	)
+	migrations.Add(54, `
+ALTER TABLE job_cache ADD COLUMN crash_loop_reason text;
+	`)
+	migrations.Add(55,
+		`ALTER TABLE http_routes ADD COLUMN canary_release_id uuid`,
+		`ALTER TABLE http_routes ADD COLUMN canary_weight integer NOT NULL DEFAULT 0`,
+		`INSERT INTO deployment_strategies (name) VALUES ('canary')`,
+		`ALTER TABLE deployments ADD COLUMN deploy_canary jsonb`,
+	)
+	migrations.Add(56,
+		`ALTER TABLE formations ADD COLUMN service_suffix text`,
+		`INSERT INTO deployment_strategies (name) VALUES ('blue-green')`,
+		`ALTER TABLE deployments ADD COLUMN deploy_warm_period integer`,
+	)
+	migrations.Add(57,
+		`ALTER TABLE deployments ADD COLUMN deploy_rollback jsonb`,
+		`ALTER TABLE deployments ADD COLUMN rollback_reason text`,
+	)
+	migrations.Add(58,
+		`ALTER TABLE deployments ADD COLUMN paused boolean NOT NULL DEFAULT false`,
+		`ALTER TABLE deployments ADD COLUMN aborted boolean NOT NULL DEFAULT false`,
+	)
+	migrations.Add(59,
+		`ALTER TABLE deployments ADD COLUMN deploy_approval jsonb`,
+		`ALTER TABLE deployments ADD COLUMN approval_state text`,
+		`ALTER TABLE deployments ADD COLUMN approval_log jsonb`,
+	)
+	migrations.Add(60,
+		`CREATE TABLE tokens (
+			token_id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+			name text NOT NULL,
+			role text NOT NULL,
+			apps jsonb,
+			resource_types jsonb,
+			key_hash bytea NOT NULL UNIQUE,
+			created_at timestamptz NOT NULL DEFAULT now(),
+			deleted_at timestamptz
+		)`,
+		`CREATE UNIQUE INDEX ON tokens (name) WHERE deleted_at IS NULL`,
+	)
+	migrations.Add(61,
+		`CREATE TABLE audit_log (
+			audit_id bigserial PRIMARY KEY,
+			key_id text,
+			method text NOT NULL,
+			path text NOT NULL,
+			app_id uuid,
+			body text,
+			status integer NOT NULL,
+			client_ip text,
+			user_agent text,
+			created_at timestamptz NOT NULL DEFAULT now()
+		)`,
+		`CREATE INDEX ON audit_log (app_id, audit_id)`,
+		`CREATE INDEX ON audit_log (key_id, audit_id)`,
+		`CREATE INDEX ON audit_log (created_at)`,
+		`INSERT INTO event_types (name) VALUES ('audit')`,
+	)
+	migrations.Add(62,
+		`CREATE TABLE webhooks (
+			webhook_id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+			url text NOT NULL,
+			apps jsonb,
+			event_types jsonb,
+			secret text NOT NULL,
+			created_at timestamptz NOT NULL DEFAULT now(),
+			deleted_at timestamptz
+		)`,
+		`CREATE TABLE webhook_deliveries (
+			delivery_id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+			webhook_id uuid NOT NULL REFERENCES webhooks (webhook_id),
+			event_id bigint NOT NULL REFERENCES events (event_id),
+			state text NOT NULL DEFAULT 'pending',
+			attempts integer NOT NULL DEFAULT 0,
+			response_status integer,
+			error text,
+			created_at timestamptz NOT NULL DEFAULT now(),
+			updated_at timestamptz NOT NULL DEFAULT now()
+		)`,
+		`CREATE INDEX ON webhook_deliveries (webhook_id, created_at)`,
+		// create a delivery and enqueue a worker job to send it for each
+		// webhook which matches an event in the same transaction as the
+		// event is created
+		`CREATE FUNCTION enqueue_webhook_deliveries() RETURNS TRIGGER AS $$
+		DECLARE
+			webhook RECORD;
+			delivery uuid;
+		BEGIN
+			FOR webhook IN
+				SELECT webhook_id FROM webhooks
+				WHERE deleted_at IS NULL
+				AND (apps IS NULL OR apps ? NEW.app_id::text)
+				AND (event_types IS NULL OR event_types ? NEW.object_type::text)
+			LOOP
+				INSERT INTO webhook_deliveries (webhook_id, event_id)
+				VALUES (webhook.webhook_id, NEW.event_id)
+				RETURNING delivery_id INTO delivery;
+
+				INSERT INTO que_jobs (job_class, args)
+				VALUES ('webhook_delivery', json_build_object('id', delivery));
+			END LOOP;
+			RETURN NULL;
+		END;
+		$$ LANGUAGE plpgsql`,
+		`CREATE TRIGGER enqueue_webhook_deliveries
+			AFTER INSERT ON events
+			FOR EACH ROW EXECUTE PROCEDURE enqueue_webhook_deliveries()`,
+	)
+	migrations.Add(63,
+		`CREATE TABLE env_groups (
+			env_group_id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+			name text NOT NULL,
+			env jsonb NOT NULL DEFAULT '{}',
+			auto_deploy boolean NOT NULL DEFAULT false,
+			created_at timestamptz NOT NULL DEFAULT now(),
+			updated_at timestamptz NOT NULL DEFAULT now()
+		)`,
+		`CREATE UNIQUE INDEX env_groups_name_idx ON env_groups (name)`,
+		// position orders the groups attached to an app, with groups
+		// attached later taking precedence
+		`CREATE TABLE app_env_groups (
+			app_id uuid NOT NULL REFERENCES apps (app_id),
+			env_group_id uuid NOT NULL REFERENCES env_groups (env_group_id) ON DELETE CASCADE,
+			position bigserial NOT NULL,
+			created_at timestamptz NOT NULL DEFAULT now(),
+			PRIMARY KEY (app_id, env_group_id)
+		)`,
+		`CREATE INDEX ON app_env_groups (env_group_id)`,
+	)
+	migrations.Add(64,
+		`CREATE TABLE app_quotas (
+			app_id uuid PRIMARY KEY REFERENCES apps (app_id),
+			jobs bigint NOT NULL DEFAULT 0,
+			memory bigint NOT NULL DEFAULT 0,
+			cpu bigint NOT NULL DEFAULT 0,
+			volumes bigint NOT NULL DEFAULT 0,
+			routes bigint NOT NULL DEFAULT 0,
+			updated_at timestamptz NOT NULL DEFAULT now()
+		)`,
+	)
+	migrations.Add(65,
+		`CREATE TABLE host_drains (
+			host_id text PRIMARY KEY,
+			state text NOT NULL,
+			events jsonb NOT NULL DEFAULT '[]',
+			created_at timestamptz NOT NULL DEFAULT now(),
+			updated_at timestamptz NOT NULL DEFAULT now()
+		)`,
+		`INSERT INTO event_types (name) VALUES ('host_drain')`,
+	)
 }
 
 func MigrateDB(db *postgres.DB) error {
@@ -0,0 +1,131 @@
+package data
+
+import (
+	"crypto/sha256"
+
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/pkg/postgres"
+	"github.com/flynn/flynn/pkg/random"
+	"github.com/jackc/pgx"
+)
+
+type TokenRepo struct {
+	db      *postgres.DB
+	appRepo *AppRepo
+}
+
+func NewTokenRepo(db *postgres.DB, appRepo *AppRepo) *TokenRepo {
+	return &TokenRepo{db: db, appRepo: appRepo}
+}
+
+// hashTokenKey returns the hash of a token key, which is stored instead of
+// the key itself so that keys cannot be recovered from the database
+func hashTokenKey(key string) []byte {
+	sum := sha256.Sum256([]byte(key))
+	return sum[:]
+}
+
+func (r *TokenRepo) validate(t *ct.Token) error {
+	if t.Name == "" {
+		return ct.ValidationError{Field: "name", Message: "must not be empty"}
+	}
+	switch t.Role {
+	case ct.TokenRoleRead, ct.TokenRoleDeploy, ct.TokenRoleAdmin:
+	default:
+		return ct.ValidationError{Field: "role", Message: "must be one of read, deploy or admin"}
+	}
+outer:
+	for _, typ := range t.ResourceTypes {
+		for _, valid := range ct.TokenResourceTypes {
+			if typ == valid {
+				continue outer
+			}
+		}
+		return ct.ValidationError{Field: "resource_types", Message: "unknown resource type " + string(typ)}
+	}
+	// apps may be given by name, but are stored by ID so that the token
+	// is unaffected by apps being renamed or recreated
+	for i, ref := range t.Apps {
+		app, err := r.appRepo.TxGet(r.db, ref)
+		if err == ErrNotFound {
+			return ct.ValidationError{Field: "apps", Message: "app not found: " + ref}
+		} else if err != nil {
+			return err
+		}
+		t.Apps[i] = app.ID
+	}
+	return nil
+}
+
+// Add creates a token with a newly generated key, which is set on the token
+func (r *TokenRepo) Add(t *ct.Token) error {
+	if err := r.validate(t); err != nil {
+		return err
+	}
+	if t.ID == "" {
+		t.ID = random.UUID()
+	}
+	t.Key = random.Hex(32)
+	var apps, resourceTypes interface{}
+	if len(t.Apps) > 0 {
+		apps = t.Apps
+	}
+	if len(t.ResourceTypes) > 0 {
+		resourceTypes = t.ResourceTypes
+	}
+	err := r.db.QueryRow("token_insert", t.ID, t.Name, string(t.Role), apps, resourceTypes, hashTokenKey(t.Key)).Scan(&t.CreatedAt)
+	if postgres.IsUniquenessError(err, "tokens_name_idx") {
+		return ct.ValidationError{Field: "name", Message: "a token with that name already exists"}
+	}
+	return err
+}
+
+func scanTokens(rows *pgx.Rows) ([]*ct.Token, error) {
+	var tokens []*ct.Token
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+func scanToken(s postgres.Scanner) (*ct.Token, error) {
+	token := &ct.Token{}
+	var role string
+	err := s.Scan(&token.ID, &token.Name, &role, &token.Apps, &token.ResourceTypes, &token.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = ErrNotFound
+		}
+		return nil, err
+	}
+	token.Role = ct.TokenRole(role)
+	return token, nil
+}
+
+func (r *TokenRepo) Get(id string) (*ct.Token, error) {
+	return scanToken(r.db.QueryRow("token_select", id))
+}
+
+// GetByKey returns the token with the given key, and is used to authenticate
+// API requests
+func (r *TokenRepo) GetByKey(key string) (*ct.Token, error) {
+	return scanToken(r.db.QueryRow("token_select_by_key_hash", hashTokenKey(key)))
+}
+
+func (r *TokenRepo) List() ([]*ct.Token, error) {
+	rows, err := r.db.Query("token_list")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanTokens(rows)
+}
+
+// Remove revokes a token so that it can no longer be used to authenticate
+func (r *TokenRepo) Remove(id string) error {
+	return r.db.Exec("token_delete", id)
+}
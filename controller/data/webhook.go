@@ -0,0 +1,174 @@
+package data
+
+import (
+	"net/url"
+
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/pkg/postgres"
+	"github.com/flynn/flynn/pkg/random"
+	"github.com/jackc/pgx"
+)
+
+type WebhookRepo struct {
+	db      *postgres.DB
+	appRepo *AppRepo
+}
+
+func NewWebhookRepo(db *postgres.DB, appRepo *AppRepo) *WebhookRepo {
+	return &WebhookRepo{db: db, appRepo: appRepo}
+}
+
+func (r *WebhookRepo) validate(w *ct.Webhook) error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ct.ValidationError{Field: "url", Message: "must be an http or https URL"}
+	}
+	for _, typ := range w.EventTypes {
+		if typ == "" {
+			return ct.ValidationError{Field: "event_types", Message: "must not contain empty event types"}
+		}
+	}
+	// apps may be given by name, but are stored by ID so that events are
+	// matched by the ID they are created with
+	for i, ref := range w.Apps {
+		app, err := r.appRepo.TxGet(r.db, ref)
+		if err == ErrNotFound {
+			return ct.ValidationError{Field: "apps", Message: "app not found: " + ref}
+		} else if err != nil {
+			return err
+		}
+		w.Apps[i] = app.ID
+	}
+	return nil
+}
+
+// Add creates a webhook, generating a secret if one is not set
+func (r *WebhookRepo) Add(w *ct.Webhook) error {
+	if err := r.validate(w); err != nil {
+		return err
+	}
+	if w.ID == "" {
+		w.ID = random.UUID()
+	}
+	if w.Secret == "" {
+		w.Secret = random.Hex(32)
+	}
+	var apps, eventTypes interface{}
+	if len(w.Apps) > 0 {
+		apps = w.Apps
+	}
+	if len(w.EventTypes) > 0 {
+		eventTypes = w.EventTypes
+	}
+	return r.db.QueryRow("webhook_insert", w.ID, w.URL, apps, eventTypes, w.Secret).Scan(&w.CreatedAt)
+}
+
+func scanWebhook(s postgres.Scanner, withSecret bool) (*ct.Webhook, error) {
+	w := &ct.Webhook{}
+	fields := []interface{}{&w.ID, &w.URL, &w.Apps, &w.EventTypes, &w.CreatedAt}
+	if withSecret {
+		fields = append(fields, &w.Secret)
+	}
+	if err := s.Scan(fields...); err != nil {
+		if err == pgx.ErrNoRows {
+			err = ErrNotFound
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
+func (r *WebhookRepo) Get(id string) (*ct.Webhook, error) {
+	return scanWebhook(r.db.QueryRow("webhook_select", id), false)
+}
+
+// GetWithSecret returns the webhook including its secret, and is used to
+// sign the payloads sent to the webhook
+func (r *WebhookRepo) GetWithSecret(id string) (*ct.Webhook, error) {
+	return scanWebhook(r.db.QueryRow("webhook_select_with_secret", id), true)
+}
+
+func (r *WebhookRepo) List() ([]*ct.Webhook, error) {
+	rows, err := r.db.Query("webhook_list")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var webhooks []*ct.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows, false)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// Remove deletes a webhook so that no more events are sent to it, keeping
+// its delivery history
+func (r *WebhookRepo) Remove(id string) error {
+	return r.db.Exec("webhook_delete", id)
+}
+
+func scanWebhookDelivery(s postgres.Scanner) (*ct.WebhookDelivery, error) {
+	d := &ct.WebhookDelivery{}
+	var eventType, state string
+	var appID, deliveryErr *string
+	var responseStatus *int32
+	if err := s.Scan(&d.ID, &d.WebhookID, &d.EventID, &eventType, &appID, &state, &d.Attempts, &responseStatus, &deliveryErr, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			err = ErrNotFound
+		}
+		return nil, err
+	}
+	d.EventType = ct.EventType(eventType)
+	d.State = ct.WebhookDeliveryState(state)
+	if appID != nil {
+		d.AppID = *appID
+	}
+	if responseStatus != nil {
+		d.ResponseStatus = int(*responseStatus)
+	}
+	if deliveryErr != nil {
+		d.Error = *deliveryErr
+	}
+	return d, nil
+}
+
+func (r *WebhookRepo) GetDelivery(id string) (*ct.WebhookDelivery, error) {
+	return scanWebhookDelivery(r.db.QueryRow("webhook_delivery_select", id))
+}
+
+// ListDeliveries returns the deliveries of the given webhook, most recent
+// first, returning all deliveries if count is zero
+func (r *WebhookRepo) ListDeliveries(webhookID string, count int) ([]*ct.WebhookDelivery, error) {
+	var limit interface{}
+	if count > 0 {
+		limit = count
+	}
+	rows, err := r.db.Query("webhook_delivery_list", webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var deliveries []*ct.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// UpdateDelivery records an attempt to send the delivery, setting its state,
+// attempt count and update time
+func (r *WebhookRepo) UpdateDelivery(d *ct.WebhookDelivery) error {
+	var responseStatus interface{}
+	if d.ResponseStatus != 0 {
+		responseStatus = d.ResponseStatus
+	}
+	return r.db.QueryRow("webhook_delivery_update", d.ID, string(d.State), responseStatus, nullString(d.Error)).Scan(&d.Attempts, &d.UpdatedAt)
+}
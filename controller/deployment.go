@@ -3,6 +3,7 @@ package main
 import (
 	"net/http"
 
+	ct "github.com/flynn/flynn/controller/types"
 	"github.com/flynn/flynn/pkg/ctxhelper"
 	"github.com/flynn/flynn/pkg/httphelper"
 	"golang.org/x/net/context"
@@ -26,6 +27,10 @@ func (c *controllerAPI) CreateDeployment(ctx context.Context, w http.ResponseWri
 	}
 	appID := c.getApp(ctx).ID
 
+	if err := c.checkDeployQuota(appID, rid.ID); err != nil {
+		respondWithError(w, err)
+		return
+	}
 	d, err := c.deploymentRepo.Add(appID, rid.ID)
 	if err != nil {
 		respondWithError(w, err)
@@ -44,3 +49,55 @@ func (c *controllerAPI) ListDeployments(ctx context.Context, w http.ResponseWrit
 	}
 	httphelper.JSON(w, 200, list)
 }
+
+func (c *controllerAPI) PauseDeployment(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	c.controlDeployment(ctx, w, c.deploymentRepo.Pause)
+}
+
+func (c *controllerAPI) ResumeDeployment(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	c.controlDeployment(ctx, w, c.deploymentRepo.Resume)
+}
+
+func (c *controllerAPI) AbortDeployment(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	c.controlDeployment(ctx, w, c.deploymentRepo.Abort)
+}
+
+// controlDeployment updates the control state of a running deployment, which
+// the deployment worker polls to pause, resume or abort the deployment
+func (c *controllerAPI) controlDeployment(ctx context.Context, w http.ResponseWriter, update func(string) (*ct.Deployment, error)) {
+	params, _ := ctxhelper.ParamsFromContext(ctx)
+	deployment, err := update(params.ByName("deployment_id"))
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, deployment)
+}
+
+func (c *controllerAPI) ApproveDeployment(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	c.decideDeploymentApproval(ctx, w, req, c.deploymentRepo.Approve)
+}
+
+func (c *controllerAPI) RejectDeployment(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	c.decideDeploymentApproval(ctx, w, req, c.deploymentRepo.Reject)
+}
+
+// decideDeploymentApproval approves or rejects a deployment held by a deploy
+// gate, recording the ID of the key used to authenticate the request as the
+// actor in the deployment's audit trail
+func (c *controllerAPI) decideDeploymentApproval(ctx context.Context, w http.ResponseWriter, req *http.Request, decide func(id, actor, comment string) (*ct.Deployment, error)) {
+	var approval ct.DeploymentApproval
+	if req.ContentLength != 0 {
+		if err := httphelper.DecodeJSON(req, &approval); err != nil {
+			respondWithError(w, err)
+			return
+		}
+	}
+	params, _ := ctxhelper.ParamsFromContext(ctx)
+	deployment, err := decide(params.ByName("deployment_id"), req.Header.Get("Flynn-Auth-Key-ID"), approval.Comment)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, deployment)
+}
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"time"
 
+	controller "github.com/flynn/flynn/controller/client"
 	ct "github.com/flynn/flynn/controller/types"
 	hh "github.com/flynn/flynn/pkg/httphelper"
 	. "github.com/flynn/go-check"
@@ -125,6 +126,25 @@ func (s *S) TestGetDeployment(c *C) {
 	c.Assert(reflect.DeepEqual(deployment.Processes, map[string]int{"web": 1}), Equals, true)
 }
 
+func (s *S) TestCreateInitialDeploymentWithReleasePhase(c *C) {
+	app := s.createTestApp(c, &ct.App{Name: "initial-release-phase-deployment"})
+	release := s.createTestRelease(c, app.ID, &ct.Release{
+		Processes: map[string]ct.ProcessType{
+			"web":                 {},
+			ct.ReleaseProcessType: {Args: []string{"migrate"}},
+		},
+	})
+
+	// the initial deployment is left for the deployment worker to run the
+	// release phase rather than finishing immediately
+	d, err := s.c.CreateDeployment(app.ID, release.ID)
+	c.Assert(err, IsNil)
+	c.Assert(d.Status, Equals, "pending")
+	c.Assert(d.FinishedAt, IsNil)
+	_, err = s.c.GetAppRelease(app.ID)
+	c.Assert(err, Equals, controller.ErrNotFound)
+}
+
 func (s *S) TestDeploymentList(c *C) {
 	app := s.createTestApp(c, &ct.App{Name: "list-deployment"})
 	release := s.createTestRelease(c, app.ID, &ct.Release{
@@ -155,3 +175,171 @@ func (s *S) TestDeploymentList(c *C) {
 	c.Assert(deployments[1].ID, Equals, initial.ID)
 	c.Assert(deployments[0].ID, Equals, second.ID)
 }
+
+func (s *S) TestControlDeployment(c *C) {
+	app := s.createTestApp(c, &ct.App{Name: "control-deployment"})
+	release := s.createTestRelease(c, app.ID, &ct.Release{
+		Processes: map[string]ct.ProcessType{"web": {}},
+	})
+	c.Assert(s.c.PutFormation(&ct.Formation{
+		AppID:     app.ID,
+		ReleaseID: release.ID,
+		Processes: map[string]int{"web": 1},
+	}), IsNil)
+	defer s.c.DeleteFormation(app.ID, release.ID)
+
+	// deploy initial release, which finishes immediately so can't be paused
+	initial, err := s.c.CreateDeployment(app.ID, release.ID)
+	c.Assert(err, IsNil)
+	_, err = s.c.PauseDeployment(initial.ID)
+	c.Assert(hh.IsValidationError(err), Equals, true)
+
+	newRelease := s.createTestRelease(c, app.ID, &ct.Release{})
+	d, err := s.c.CreateDeployment(app.ID, newRelease.ID)
+	c.Assert(err, IsNil)
+
+	// resuming a deployment which isn't paused should fail
+	_, err = s.c.ResumeDeployment(d.ID)
+	c.Assert(hh.IsValidationError(err), Equals, true)
+
+	// pause the deployment
+	paused, err := s.c.PauseDeployment(d.ID)
+	c.Assert(err, IsNil)
+	c.Assert(paused.Paused, Equals, true)
+	_, err = s.c.PauseDeployment(d.ID)
+	c.Assert(hh.IsValidationError(err), Equals, true)
+	deployment, err := s.c.GetDeployment(d.ID)
+	c.Assert(err, IsNil)
+	c.Assert(deployment.Paused, Equals, true)
+
+	// resume the deployment
+	resumed, err := s.c.ResumeDeployment(d.ID)
+	c.Assert(err, IsNil)
+	c.Assert(resumed.Paused, Equals, false)
+
+	// abort the deployment, after which it can't be paused or resumed
+	aborted, err := s.c.AbortDeployment(d.ID)
+	c.Assert(err, IsNil)
+	c.Assert(aborted.Aborted, Equals, true)
+	_, err = s.c.PauseDeployment(d.ID)
+	c.Assert(hh.IsValidationError(err), Equals, true)
+	_, err = s.c.AbortDeployment(d.ID)
+	c.Assert(hh.IsValidationError(err), Equals, true)
+}
+
+func (s *S) TestPauseSireniaDeployment(c *C) {
+	app := s.createTestApp(c, &ct.App{Name: "pause-sirenia-deployment", Strategy: "sirenia"})
+	release := s.createTestRelease(c, app.ID, &ct.Release{
+		Processes: map[string]ct.ProcessType{"postgres": {}},
+	})
+	c.Assert(s.c.PutFormation(&ct.Formation{
+		AppID:     app.ID,
+		ReleaseID: release.ID,
+		Processes: map[string]int{"postgres": 3},
+	}), IsNil)
+	defer s.c.DeleteFormation(app.ID, release.ID)
+	_, err := s.c.CreateDeployment(app.ID, release.ID)
+	c.Assert(err, IsNil)
+
+	newRelease := s.createTestRelease(c, app.ID, &ct.Release{
+		Processes: map[string]ct.ProcessType{"postgres": {}},
+	})
+	d, err := s.c.CreateDeployment(app.ID, newRelease.ID)
+	c.Assert(err, IsNil)
+
+	// sirenia deployments can't be paused part way through
+	_, err = s.c.PauseDeployment(d.ID)
+	c.Assert(hh.IsValidationError(err), Equals, true)
+	deployment, err := s.c.GetDeployment(d.ID)
+	c.Assert(err, IsNil)
+	c.Assert(deployment.Paused, Equals, false)
+}
+
+func (s *S) TestDeploymentApproval(c *C) {
+	app := s.createTestApp(c, &ct.App{Name: "deployment-approval"})
+	release := s.createTestRelease(c, app.ID, &ct.Release{
+		Processes: map[string]ct.ProcessType{"web": {}},
+	})
+	c.Assert(s.c.PutFormation(&ct.Formation{
+		AppID:     app.ID,
+		ReleaseID: release.ID,
+		Processes: map[string]int{"web": 1},
+	}), IsNil)
+	defer s.c.DeleteFormation(app.ID, release.ID)
+
+	// deploy initial release, which doesn't need approval
+	initial, err := s.c.CreateDeployment(app.ID, release.ID)
+	c.Assert(err, IsNil)
+	_, err = s.c.ApproveDeployment(initial.ID, "")
+	c.Assert(hh.IsValidationError(err), Equals, true)
+
+	// enable the deploy gate
+	app.SetDeployApproval(&ct.ApprovalConfig{Timeout: 600})
+	c.Assert(s.c.UpdateApp(&ct.App{ID: app.ID, Meta: app.Meta}), IsNil)
+
+	newRelease := s.createTestRelease(c, app.ID, &ct.Release{})
+	d, err := s.c.CreateDeployment(app.ID, newRelease.ID)
+	c.Assert(err, IsNil)
+	c.Assert(d.Status, Equals, "pending_approval")
+	c.Assert(d.DeployApproval, NotNil)
+	c.Assert(d.DeployApproval.Timeout, Equals, 600)
+
+	// approve the deployment, after which it can't be approved or rejected
+	approved, err := s.c.ApproveDeployment(d.ID, "looks good")
+	c.Assert(err, IsNil)
+	c.Assert(approved.ApprovalLog, HasLen, 1)
+	c.Assert(approved.ApprovalLog[0].Action, Equals, ct.DeploymentApprovalActionApproved)
+	c.Assert(approved.ApprovalLog[0].Comment, Equals, "looks good")
+	_, err = s.c.ApproveDeployment(d.ID, "")
+	c.Assert(hh.IsValidationError(err), Equals, true)
+	_, err = s.c.RejectDeployment(d.ID, "")
+	c.Assert(hh.IsValidationError(err), Equals, true)
+
+	// the audit trail is stored on the deployment
+	deployment, err := s.c.GetDeployment(d.ID)
+	c.Assert(err, IsNil)
+	c.Assert(deployment.ApprovalLog, HasLen, 1)
+	c.Assert(deployment.ApprovalLog[0].Action, Equals, ct.DeploymentApprovalActionApproved)
+}
+
+func (s *S) TestDeploymentApprovalSecret(c *C) {
+	app := s.createTestApp(c, &ct.App{Name: "deployment-approval-secret"})
+	release := s.createTestRelease(c, app.ID, &ct.Release{
+		Processes: map[string]ct.ProcessType{"web": {}},
+	})
+	c.Assert(s.c.PutFormation(&ct.Formation{
+		AppID:     app.ID,
+		ReleaseID: release.ID,
+		Processes: map[string]int{"web": 1},
+	}), IsNil)
+	defer s.c.DeleteFormation(app.ID, release.ID)
+	_, err := s.c.CreateDeployment(app.ID, release.ID)
+	c.Assert(err, IsNil)
+
+	// setting a webhook generates the secret used to sign approval requests
+	app.SetDeployApproval(&ct.ApprovalConfig{WebhookURL: "http://example.com/approve", Timeout: 600})
+	c.Assert(s.c.UpdateApp(&ct.App{ID: app.ID, Meta: app.Meta}), IsNil)
+	app, err = s.c.GetApp(app.ID)
+	c.Assert(err, IsNil)
+	config := app.DeployApproval()
+	c.Assert(config, NotNil)
+	c.Assert(config.Secret, HasLen, 64)
+
+	// the secret is kept when the gate is updated
+	secret := config.Secret
+	config.Timeout = 300
+	app.SetDeployApproval(config)
+	c.Assert(s.c.UpdateApp(&ct.App{ID: app.ID, Meta: app.Meta}), IsNil)
+	app, err = s.c.GetApp(app.ID)
+	c.Assert(err, IsNil)
+	c.Assert(app.DeployApproval().Secret, Equals, secret)
+
+	// the secret is not stored with deployments
+	newRelease := s.createTestRelease(c, app.ID, &ct.Release{})
+	d, err := s.c.CreateDeployment(app.ID, newRelease.ID)
+	c.Assert(err, IsNil)
+	c.Assert(d.DeployApproval, NotNil)
+	c.Assert(d.DeployApproval.Secret, Equals, "")
+	_, err = s.c.RejectDeployment(d.ID, "")
+	c.Assert(err, IsNil)
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/flynn/flynn/controller/data"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/pkg/ctxhelper"
+	"github.com/flynn/flynn/pkg/httphelper"
+	"golang.org/x/net/context"
+)
+
+// Create a new env group, which is not attached to any apps
+func (c *controllerAPI) CreateEnvGroup(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	var group ct.EnvGroup
+	if err := httphelper.DecodeJSON(req, &group); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	group.Apps = nil
+
+	if err := c.envGroupRepo.Add(&group); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, &group)
+}
+
+func (c *controllerAPI) getEnvGroup(ctx context.Context) (*ct.EnvGroup, error) {
+	params, _ := ctxhelper.ParamsFromContext(ctx)
+	return c.envGroupRepo.Get(params.ByName("env_group_id"))
+}
+
+// Get an env group by ID or name
+func (c *controllerAPI) GetEnvGroup(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	group, err := c.getEnvGroup(ctx)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, group)
+}
+
+// List env groups
+func (c *controllerAPI) GetEnvGroups(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	list, err := c.envGroupRepo.List()
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, list)
+}
+
+// Update the env and auto deploy setting of an env group, creating releases
+// for the apps it is attached to
+func (c *controllerAPI) UpdateEnvGroup(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	var update ct.EnvGroup
+	if err := httphelper.DecodeJSON(req, &update); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	group, err := c.getEnvGroup(ctx)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	group.Env = update.Env
+	group.AutoDeploy = update.AutoDeploy
+
+	releases, err := c.changeEnvGroups(group.Apps, group.AutoDeploy, func() error {
+		return c.envGroupRepo.Update(group)
+	})
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, &ct.EnvGroupUpdate{EnvGroup: group, Releases: releases})
+}
+
+// Delete an env group, creating releases without its env for the apps it was
+// attached to
+func (c *controllerAPI) DeleteEnvGroup(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	group, err := c.getEnvGroup(ctx)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	releases, err := c.changeEnvGroups(group.Apps, group.AutoDeploy, func() error {
+		return c.envGroupRepo.Remove(group.ID)
+	})
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, &ct.EnvGroupUpdate{EnvGroup: group, Releases: releases})
+}
+
+// List the env groups attached to an app in order of increasing precedence
+func (c *controllerAPI) GetAppEnvGroups(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	list, err := c.envGroupRepo.AppList(c.getApp(ctx).ID)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, list)
+}
+
+// Attach an env group to an app, creating a release with the group's env
+func (c *controllerAPI) AttachEnvGroup(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	c.changeAppEnvGroup(ctx, w, c.envGroupRepo.Attach)
+}
+
+// Detach an env group from an app, creating a release without the group's
+// env
+func (c *controllerAPI) DetachEnvGroup(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	c.changeAppEnvGroup(ctx, w, c.envGroupRepo.Detach)
+}
+
+func (c *controllerAPI) changeAppEnvGroup(ctx context.Context, w http.ResponseWriter, change func(appID, groupID string) error) {
+	app := c.getApp(ctx)
+	group, err := c.getEnvGroup(ctx)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	releases, err := c.changeEnvGroups([]string{app.ID}, group.AutoDeploy, func() error {
+		return change(app.ID, group.ID)
+	})
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if group, err = c.envGroupRepo.Get(group.ID); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, &ct.EnvGroupUpdate{EnvGroup: group, Releases: releases})
+}
+
+// changeEnvGroups makes a change to env groups and then creates a release for
+// each of the given apps whose env is affected by the change, deploying the
+// releases if deploy is set
+func (c *controllerAPI) changeEnvGroups(appIDs []string, deploy bool, change func() error) ([]*ct.EnvGroupAppRelease, error) {
+	before := make(map[string][]*ct.EnvGroup, len(appIDs))
+	for _, appID := range appIDs {
+		groups, err := c.envGroupRepo.AppList(appID)
+		if err != nil {
+			return nil, err
+		}
+		before[appID] = groups
+	}
+
+	if err := change(); err != nil {
+		return nil, err
+	}
+
+	var releases []*ct.EnvGroupAppRelease
+	for _, appID := range appIDs {
+		after, err := c.envGroupRepo.AppList(appID)
+		if err != nil {
+			return nil, err
+		}
+		if r := c.updateEnvGroupApp(appID, before[appID], after, deploy); r != nil {
+			releases = append(releases, r)
+		}
+	}
+	return releases, nil
+}
+
+// updateEnvGroupApp creates a release for the app with its env changed from
+// that of the before groups to that of the after groups, returning nil if
+// the app has no release or its env is unchanged
+func (c *controllerAPI) updateEnvGroupApp(appID string, before, after []*ct.EnvGroup, deploy bool) *ct.EnvGroupAppRelease {
+	res := &ct.EnvGroupAppRelease{AppID: appID}
+	current, err := c.appRepo.GetRelease(appID)
+	if err == ErrNotFound {
+		return nil
+	} else if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	// the before groups are those of the last change, which may have
+	// created a release which hasn't been deployed yet
+	prev, err := c.envGroupRepo.BaseRelease(current)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	release := data.ApplyEnvGroups(prev, before, after)
+	if release == nil {
+		return nil
+	}
+	if err := c.releaseRepo.Add(release); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.ReleaseID = release.ID
+
+	if deploy {
+		if err := c.checkDeployQuota(appID, release.ID); err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		deployment, err := c.deploymentRepo.Add(appID, release.ID)
+		if err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		res.DeploymentID = deployment.ID
+	}
+	return res
+}
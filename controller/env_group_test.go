@@ -0,0 +1,148 @@
+package main
+
+import (
+	ct "github.com/flynn/flynn/controller/types"
+	hh "github.com/flynn/flynn/pkg/httphelper"
+	. "github.com/flynn/go-check"
+)
+
+func (s *S) TestEnvGroups(c *C) {
+	app := s.createTestApp(c, &ct.App{Name: "env-groups"})
+	release := s.createTestRelease(c, app.ID, &ct.Release{
+		Env: map[string]string{"APP_VAR": "1", "OVERRIDE": "app"},
+	})
+	c.Assert(s.c.SetAppRelease(app.ID, release.ID), IsNil)
+
+	// groups must have a valid name
+	err := s.c.CreateEnvGroup(&ct.EnvGroup{Name: "invalid name"})
+	c.Assert(hh.IsValidationError(err), Equals, true)
+
+	base := &ct.EnvGroup{
+		Name: "env-groups-base",
+		Env:  map[string]string{"SHARED": "base", "BASE_ONLY": "base", "OVERRIDE": "base"},
+	}
+	c.Assert(s.c.CreateEnvGroup(base), IsNil)
+	flags := &ct.EnvGroup{
+		Name: "env-groups-flags",
+		Env:  map[string]string{"SHARED": "flags", "FEATURE": "on"},
+	}
+	c.Assert(s.c.CreateEnvGroup(flags), IsNil)
+	err = s.c.CreateEnvGroup(&ct.EnvGroup{Name: base.Name})
+	c.Assert(hh.IsValidationError(err), Equals, true)
+
+	currentEnv := func() (map[string]string, map[string]string) {
+		release, err := s.c.GetAppRelease(app.ID)
+		c.Assert(err, IsNil)
+		return release.Env, release.Meta
+	}
+	setRelease := func(update *ct.EnvGroupUpdate) {
+		c.Assert(update.Releases, HasLen, 1)
+		c.Assert(update.Releases[0].AppID, Equals, app.ID)
+		c.Assert(update.Releases[0].Error, Equals, "")
+		c.Assert(s.c.SetAppRelease(app.ID, update.Releases[0].ReleaseID), IsNil)
+	}
+
+	// attaching groups creates releases with the group env, with app vars
+	// and later groups taking precedence
+	update, err := s.c.AttachEnvGroup(app.Name, base.Name)
+	c.Assert(err, IsNil)
+	c.Assert(update.EnvGroup.Apps, DeepEquals, []string{app.ID})
+	setRelease(update)
+	update, err = s.c.AttachEnvGroup(app.ID, flags.ID)
+	c.Assert(err, IsNil)
+	setRelease(update)
+	env, meta := currentEnv()
+	c.Assert(env, DeepEquals, map[string]string{
+		"APP_VAR":   "1",
+		"OVERRIDE":  "app",
+		"SHARED":    "flags",
+		"BASE_ONLY": "base",
+		"FEATURE":   "on",
+	})
+	c.Assert(meta[ct.ReleaseMetaEnvGroups], Equals, "env-groups-base,env-groups-flags")
+	c.Assert(meta[ct.ReleaseMetaEnvPrecedence], Equals, "release env > env-groups-flags > env-groups-base")
+
+	groups, err := s.c.AppEnvGroupList(app.ID)
+	c.Assert(err, IsNil)
+	c.Assert(groups, HasLen, 2)
+	c.Assert(groups[0].ID, Equals, base.ID)
+	c.Assert(groups[1].ID, Equals, flags.ID)
+
+	// updating a group creates releases for attached apps
+	flags.Env = map[string]string{"SHARED": "flags2"}
+	update, err = s.c.UpdateEnvGroup(flags)
+	c.Assert(err, IsNil)
+	setRelease(update)
+	env, _ = currentEnv()
+	c.Assert(env["SHARED"], Equals, "flags2")
+	_, ok := env["FEATURE"]
+	c.Assert(ok, Equals, false)
+
+	// updating a group var which the app overrides doesn't create a release
+	base.Env["OVERRIDE"] = "base2"
+	update, err = s.c.UpdateEnvGroup(base)
+	c.Assert(err, IsNil)
+	c.Assert(update.Releases, HasLen, 0)
+
+	// detaching a group removes its env, falling back to earlier groups
+	update, err = s.c.DetachEnvGroup(app.ID, flags.ID)
+	c.Assert(err, IsNil)
+	setRelease(update)
+	env, meta = currentEnv()
+	c.Assert(env["SHARED"], Equals, "base")
+	c.Assert(meta[ct.ReleaseMetaEnvGroups], Equals, "env-groups-base")
+
+	// deleting a group removes its env from attached apps
+	update, err = s.c.DeleteEnvGroup(base.Name)
+	c.Assert(err, IsNil)
+	setRelease(update)
+	env, meta = currentEnv()
+	c.Assert(env, DeepEquals, map[string]string{"APP_VAR": "1", "OVERRIDE": "app"})
+	_, ok = meta[ct.ReleaseMetaEnvGroups]
+	c.Assert(ok, Equals, false)
+	_, err = s.c.GetEnvGroup(base.ID)
+	c.Assert(err, NotNil)
+
+	_, err = s.c.DeleteEnvGroup(flags.ID)
+	c.Assert(err, IsNil)
+}
+
+func (s *S) TestEnvGroupUpdatesWithoutDeploying(c *C) {
+	app := s.createTestApp(c, &ct.App{Name: "env-groups-no-deploy"})
+	release := s.createTestRelease(c, app.ID, &ct.Release{
+		Env: map[string]string{"APP_VAR": "1"},
+	})
+	c.Assert(s.c.SetAppRelease(app.ID, release.ID), IsNil)
+
+	group := &ct.EnvGroup{
+		Name: "env-groups-no-deploy",
+		Env:  map[string]string{"A": "1"},
+	}
+	c.Assert(s.c.CreateEnvGroup(group), IsNil)
+	update, err := s.c.AttachEnvGroup(app.ID, group.ID)
+	c.Assert(err, IsNil)
+	c.Assert(update.Releases, HasLen, 1)
+	c.Assert(s.c.SetAppRelease(app.ID, update.Releases[0].ReleaseID), IsNil)
+
+	// successive updates which aren't deployed build on each other rather
+	// than on the app's current release
+	group.Env = map[string]string{"A": "2", "B": "1"}
+	update, err = s.c.UpdateEnvGroup(group)
+	c.Assert(err, IsNil)
+	c.Assert(update.Releases, HasLen, 1)
+	c.Assert(update.Releases[0].DeploymentID, Equals, "")
+	group.Env = map[string]string{"A": "3"}
+	update, err = s.c.UpdateEnvGroup(group)
+	c.Assert(err, IsNil)
+	c.Assert(update.Releases, HasLen, 1)
+	c.Assert(update.Releases[0].DeploymentID, Equals, "")
+
+	latest, err := s.c.GetRelease(update.Releases[0].ReleaseID)
+	c.Assert(err, IsNil)
+	c.Assert(latest.Env, DeepEquals, map[string]string{"APP_VAR": "1", "A": "3"})
+
+	// the current release is unchanged
+	current, err := s.c.GetAppRelease(app.ID)
+	c.Assert(err, IsNil)
+	c.Assert(current.Env, DeepEquals, map[string]string{"APP_VAR": "1", "A": "1"})
+}
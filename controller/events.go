@@ -7,6 +7,7 @@ import (
 
 	"github.com/flynn/flynn/controller/data"
 	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/controller/utils"
 	"github.com/flynn/flynn/pkg/ctxhelper"
 	"github.com/flynn/flynn/pkg/httphelper"
 	"github.com/flynn/flynn/pkg/sse"
@@ -35,9 +36,32 @@ func (c *controllerAPI) GetEvent(ctx context.Context, w http.ResponseWriter, req
 		respondWithError(w, err)
 		return
 	}
+	if event.ObjectType == ct.EventTypeAudit && !canReadAuditLog(req) {
+		respondWithError(w, ErrNotFound)
+		return
+	}
 	httphelper.JSON(w, 200, event)
 }
 
+// canReadAuditLog returns whether the request is authorized to read the audit
+// log, which is only readable by unrestricted admin tokens, and so whether
+// audit events are included in the events it lists or streams
+func canReadAuditLog(req *http.Request) bool {
+	auth, ok := req.Context().Value(ctxKeyAuthorization).(*utils.Authorization)
+	return ok && auth.Allows(&utils.Permission{Role: ct.TokenRoleAdmin})
+}
+
+// filterAuditEvents removes audit events from the list
+func filterAuditEvents(list []*ct.Event) []*ct.Event {
+	filtered := list[:0]
+	for _, e := range list {
+		if e.ObjectType != ct.EventTypeAudit {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
 func (c *controllerAPI) Events(ctx context.Context, w http.ResponseWriter, req *http.Request) {
 	l, _ := ctxhelper.LoggerFromContext(ctx)
 	log := l.New("fn", "Events")
@@ -116,6 +140,9 @@ func listEvents(ctx context.Context, w http.ResponseWriter, req *http.Request, a
 	if err != nil {
 		return err
 	}
+	if !canReadAuditLog(req) {
+		list = filterAuditEvents(list)
+	}
 	httphelper.JSON(w, 200, list)
 	return nil
 }
@@ -171,12 +198,16 @@ func streamEvents(ctx context.Context, w http.ResponseWriter, req *http.Request,
 	}
 	defer sub.Close()
 
+	readAudit := canReadAuditLog(req)
 	var currID int64
 	if past == "true" || lastID > 0 {
 		list, err := repo.ListEvents(appIDs, objectTypes, objectIDs, nil, &lastID, count)
 		if err != nil {
 			return err
 		}
+		if !readAudit {
+			list = filterAuditEvents(list)
+		}
 		// events are in ID DESC order, so iterate in reverse
 		for i := len(list) - 1; i >= 0; i-- {
 			e := list[i]
@@ -193,7 +224,7 @@ func streamEvents(ctx context.Context, w http.ResponseWriter, req *http.Request,
 			if !ok {
 				return sub.Err
 			}
-			if event.ID <= currID {
+			if event.ID <= currID || (event.ObjectType == ct.EventTypeAudit && !readAudit) {
 				continue
 			}
 			ch <- event
@@ -2,13 +2,16 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/flynn/flynn/controller/schema"
 	ct "github.com/flynn/flynn/controller/types"
+	discoverd "github.com/flynn/flynn/discoverd/client"
 	"github.com/flynn/flynn/pkg/ctxhelper"
+	"github.com/flynn/flynn/pkg/httpclient"
 	"github.com/flynn/flynn/pkg/httphelper"
 	"github.com/flynn/flynn/pkg/sse"
 	"golang.org/x/net/context"
@@ -42,6 +45,10 @@ func (c *controllerAPI) PutFormation(ctx context.Context, w http.ResponseWriter,
 	}
 
 	req := newScaleRequest(formation, release)
+	if err := c.quotaRepo.Check(app.ID, release.ID, *req.NewProcesses, nil); err != nil {
+		respondWithError(w, err)
+		return
+	}
 	req, err = c.formationRepo.AddScaleRequest(req, false)
 	if err != nil {
 		respondWithError(w, err)
@@ -71,6 +78,13 @@ func (c *controllerAPI) PutScaleRequest(ctx context.Context, w http.ResponseWrit
 		return
 	}
 
+	if req.State == ct.ScaleRequestStatePending && req.NewProcesses != nil {
+		if err := c.quotaRepo.Check(app.ID, release.ID, *req.NewProcesses, nil); err != nil {
+			respondWithError(w, err)
+			return
+		}
+	}
+
 	if req.State == ct.ScaleRequestStatePending {
 		_, err = c.formationRepo.AddScaleRequest(&req, false)
 	} else {
@@ -83,6 +97,98 @@ func (c *controllerAPI) PutScaleRequest(ctx context.Context, w http.ResponseWrit
 	httphelper.JSON(w, 200, &req)
 }
 
+// ScaleDryRun returns the decisions the scheduler would make if the
+// formation was scaled according to the given scale request, without actually
+// scaling it
+func (c *controllerAPI) ScaleDryRun(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	app := c.getApp(ctx)
+	release, err := c.getRelease(ctx)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	var req ct.ScaleRequest
+	if err := httphelper.DecodeJSON(r, &req); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if req.NewProcesses == nil && req.NewTags == nil {
+		respondWithError(w, ct.ValidationError{Message: "scale request must have either processes or tags set"})
+		return
+	}
+	if req.NewProcesses != nil {
+		invalid := make([]string, 0, len(*req.NewProcesses))
+		for typ, count := range *req.NewProcesses {
+			if _, ok := release.Processes[typ]; !ok {
+				invalid = append(invalid, typ)
+			}
+			if typ == ct.ReleaseProcessType && count > 0 {
+				respondWithError(w, ct.ValidationError{Message: fmt.Sprintf("the %q process type is run when deploying and cannot be scaled", typ)})
+				return
+			}
+		}
+		if len(invalid) > 0 {
+			respondWithError(w, ct.ValidationError{Message: fmt.Sprintf("requested scale includes process types that do not exist in the release: %s", strings.Join(invalid, ", "))})
+			return
+		}
+	}
+
+	// apply the scale request to the current formation in the same way as
+	// FormationRepo.AddScaleRequest
+	formation, err := c.formationRepo.GetExpanded(app.ID, release.ID, false)
+	if err == ErrNotFound {
+		formation = &ct.ExpandedFormation{App: app, Release: release}
+	} else if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	processes := make(map[string]int, len(formation.Processes))
+	for typ, count := range formation.Processes {
+		processes[typ] = count
+	}
+	if req.NewProcesses != nil {
+		for typ, count := range *req.NewProcesses {
+			processes[typ] = count
+		}
+	}
+	tags := make(map[string]map[string]string, len(formation.Tags))
+	for typ, t := range formation.Tags {
+		tags[typ] = t
+	}
+	if req.NewTags != nil {
+		for typ, t := range *req.NewTags {
+			tags[typ] = t
+		}
+	}
+	formation.Processes = processes
+	formation.Tags = tags
+	formation.UpdatedAt = time.Now()
+
+	result, err := c.schedulerDryRun(&ct.SchedulerDryRunRequest{
+		Formations: []*ct.ExpandedFormation{formation},
+	})
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, result)
+}
+
+// schedulerDryRun sends the given dry run request to the scheduler leader
+func (c *controllerAPI) schedulerDryRun(req *ct.SchedulerDryRunRequest) (*ct.SchedulerDryRunResult, error) {
+	leader, err := discoverd.NewService("controller-scheduler").Leader()
+	if err != nil {
+		return nil, err
+	}
+	client := &httpclient.Client{
+		URL:  "http://" + leader.Addr,
+		HTTP: http.DefaultClient,
+	}
+	var result ct.SchedulerDryRunResult
+	return &result, client.Post("/debug/dry-run", req, &result)
+}
+
 func (c *controllerAPI) GetFormation(ctx context.Context, w http.ResponseWriter, req *http.Request) {
 	params, _ := ctxhelper.ParamsFromContext(ctx)
 
@@ -248,12 +354,18 @@ func newScaleRequest(f *ct.Formation, release *ct.Release) *ct.ScaleRequest {
 			f.Processes[typ] = 0
 		}
 	}
-	return &ct.ScaleRequest{
+	req := &ct.ScaleRequest{
 		AppID:        f.AppID,
 		ReleaseID:    f.ReleaseID,
 		NewProcesses: &f.Processes,
 		NewTags:      &f.Tags,
 	}
+	// only change the service suffix if set so that clients which are
+	// not aware of it do not reset it
+	if f.ServiceSuffix != "" {
+		req.NewServiceSuffix = &f.ServiceSuffix
+	}
+	return req
 }
 
 func scaleRequestAsFormation(sr *ct.ScaleRequest) *ct.Formation {
@@ -265,12 +377,17 @@ func scaleRequestAsFormation(sr *ct.ScaleRequest) *ct.Formation {
 	if sr.NewTags != nil {
 		tags = *sr.NewTags
 	}
+	var serviceSuffix string
+	if sr.NewServiceSuffix != nil {
+		serviceSuffix = *sr.NewServiceSuffix
+	}
 	return &ct.Formation{
-		AppID:     sr.AppID,
-		ReleaseID: sr.ReleaseID,
-		Processes: processes,
-		Tags:      tags,
-		CreatedAt: sr.CreatedAt,
-		UpdatedAt: sr.UpdatedAt,
+		AppID:         sr.AppID,
+		ReleaseID:     sr.ReleaseID,
+		Processes:     processes,
+		Tags:          tags,
+		ServiceSuffix: serviceSuffix,
+		CreatedAt:     sr.CreatedAt,
+		UpdatedAt:     sr.UpdatedAt,
 	}
 }
@@ -4,16 +4,22 @@ import (
 	"encoding/json"
 	fmt "fmt"
 	"net"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/flynn/flynn/controller/api"
 	"github.com/flynn/flynn/controller/data"
+	"github.com/flynn/flynn/controller/schema"
 	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/controller/utils"
 	"github.com/flynn/flynn/pkg/ctxhelper"
 	"github.com/flynn/flynn/pkg/postgres"
 	"github.com/flynn/flynn/pkg/random"
+	router "github.com/flynn/flynn/router/types"
 	"github.com/golang/protobuf/ptypes/empty"
 	middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	log "github.com/inconshreveable/log15"
@@ -34,28 +40,64 @@ type grpcAPI struct {
 
 const ctxKeyFlynnAuthKeyID = "flynn-auth-key-id"
 
-func (g *grpcAPI) authorize(ctx context.Context) (context.Context, error) {
+func (g *grpcAPI) authorize(ctx context.Context) (context.Context, *utils.Authorization, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return ctx, grpc.Errorf(codes.Unauthenticated, "metadata missing")
+		return ctx, nil, grpc.Errorf(codes.Unauthenticated, "metadata missing")
 	}
 
-	passwords, ok := md["auth-key"]
-	if !ok || len(passwords) == 0 {
-		return ctx, grpc.Errorf(codes.Unauthenticated, "no Auth-Key provided")
+	var key string
+	if passwords := md["auth-key"]; len(passwords) > 0 {
+		key = passwords[0]
+	} else if headers := md["authorization"]; len(headers) > 0 {
+		key = bearerToken(headers[0])
+	}
+	if key == "" {
+		return ctx, nil, grpc.Errorf(codes.Unauthenticated, "no Auth-Key provided")
 	}
 
-	auth, err := g.authorizer.Authorize(passwords[0])
+	auth, err := g.authorizer.Authorize(key)
 	if err != nil {
-		return ctx, grpc.Errorf(codes.Unauthenticated, err.Error())
+		return ctx, nil, grpc.Errorf(codes.Unauthenticated, err.Error())
 	}
 
+	ctx = context.WithValue(ctx, ctxKeyAuthorization, auth)
 	if auth.ID != "" {
 		ctx = context.WithValue(ctx, ctxKeyFlynnAuthKeyID, auth.ID)
 		ctx = ctxhelper.NewContextLogger(ctx, g.logger(ctx).New("authKeyID", auth.ID))
 	}
 
-	return ctx, nil
+	return ctx, auth, nil
+}
+
+// checkPermission returns a PermissionDenied error if the authorization does
+// not permit the given request
+func checkPermission(auth *utils.Authorization, req interface{}) error {
+	if perm := grpcPermission(req); perm != nil && !auth.Allows(perm) {
+		return grpc.Errorf(codes.PermissionDenied, "token %q is not permitted to make this request", auth.Token.Name)
+	}
+	return nil
+}
+
+// permissionServerStream checks the permission required for the request
+// received by a streaming RPC, which is not available to the stream
+// interceptor
+type permissionServerStream struct {
+	*middleware.WrappedServerStream
+
+	auth    *utils.Authorization
+	checked bool
+}
+
+func (s *permissionServerStream) RecvMsg(m interface{}) error {
+	if err := s.WrappedServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if !s.checked {
+		s.checked = true
+		return checkPermission(s.auth, m)
+	}
+	return nil
 }
 
 func (g *grpcAPI) logger(ctx context.Context) log.Logger {
@@ -164,13 +206,16 @@ func (g *grpcAPI) streamInterceptor(srv interface{}, stream grpc.ServerStream, i
 		logRequestEnd(ctx, err)
 	}()
 
-	ctx, err = g.authorize(stream.Context())
+	ctx, auth, err := g.authorize(stream.Context())
 	if err != nil {
 		return err
 	}
 
 	wrappedStream := middleware.WrapServerStream(stream)
 	wrappedStream.WrappedContext = ctx
+	if auth.Token != nil {
+		return handler(srv, &permissionServerStream{WrappedServerStream: wrappedStream, auth: auth})
+	}
 	return handler(srv, wrappedStream)
 }
 
@@ -180,10 +225,15 @@ func (g *grpcAPI) unaryInterceptor(ctx context.Context, req interface{}, info *g
 		logRequestEnd(ctx, err)
 	}()
 
-	ctx, err = g.authorize(ctx)
+	ctx, auth, err := g.authorize(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if auth.Token != nil {
+		if err := checkPermission(auth, req); err != nil {
+			return nil, err
+		}
+	}
 
 	return handler(ctx, req)
 }
@@ -392,6 +442,9 @@ func (g *grpcAPI) createScale(req *api.CreateScaleRequest) (*api.ScaleRequest, e
 	}
 	if processes != nil {
 		scaleReq.NewProcesses = &processes
+		if err := g.quotaRepo.Check(appID, releaseID, processes, nil); err != nil {
+			return nil, api.NewError(err, err.Error())
+		}
 	}
 	if tags != nil {
 		scaleReq.NewTags = &tags
@@ -879,25 +932,37 @@ func parseDeploymentProcesses(from map[string]int32) map[string]int {
 func (g *grpcAPI) CreateDeployment(req *api.CreateDeploymentRequest, ds api.Controller_CreateDeploymentServer) error {
 	appID := api.ParseIDFromName(req.Parent, "apps")
 	releaseID := api.ParseIDFromName(req.Parent, "releases")
-	d, err := g.deploymentRepo.Add(appID, releaseID)
-	if err != nil {
+	if err := g.checkDeployQuota(appID, releaseID); err != nil {
 		return api.NewError(err, err.Error())
 	}
 
-	// Wait for deployment to complete and perform scale
-
-	sub, err := g.subscribeEvents([]string{appID}, []ct.EventType{ct.EventTypeDeployment}, []string{d.ID})
+	// subscribe before creating the deployment so that its first event,
+	// which is emitted as it is created, is not missed
+	sub, err := g.subscribeEvents([]string{appID}, []ct.EventType{ct.EventTypeDeployment}, nil)
 	if err != nil {
 		return api.NewError(err, err.Error())
 	}
 	defer sub.Close()
 
+	d, err := g.deploymentRepo.Add(appID, releaseID)
+	if err != nil {
+		return api.NewError(err, err.Error())
+	}
+
+	// Wait for deployment to complete and perform scale
+outer:
 	for {
-		event, ok := <-sub.Events
-		if !ok {
-			break
+		var event *ct.Event
+		select {
+		case e, ok := <-sub.Events:
+			if !ok {
+				break outer
+			}
+			event = e
+		case <-ds.Context().Done():
+			return nil
 		}
-		if event.ObjectType != "deployment" {
+		if event.ObjectType != "deployment" || event.ObjectID != d.ID {
 			continue
 		}
 		var de *ct.DeploymentEvent
@@ -944,3 +1009,861 @@ func maybeError(err error) error {
 	}
 	return api.NewError(err, err.Error())
 }
+
+// nameFilter matches resource names against the name filters of a
+// streaming RPC, with a resource matching if it or one of its parents is
+// in the filters (or if there are no filters)
+type nameFilter map[string]struct{}
+
+func newNameFilter(names []string) nameFilter {
+	f := make(nameFilter, len(names))
+	for _, name := range names {
+		f[strings.Trim(name, "/")] = struct{}{}
+	}
+	return f
+}
+
+func (f nameFilter) Match(names ...string) bool {
+	if len(f) == 0 {
+		return true
+	}
+	for _, name := range names {
+		for ; name != "." && name != "/" && name != ""; name = path.Dir(path.Dir(name)) {
+			if _, ok := f[name]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseAppIDsFromNames returns the unique app IDs of the given resource
+// names (or their parents)
+func parseAppIDsFromNames(names []string) []string {
+	ids := make([]string, 0, len(names))
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		id := api.ParseIDFromName(name, "apps")
+		if _, ok := seen[id]; ok || id == "" {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// objectEvents filters the events received by a subscription according to
+// the stream_creates and stream_updates fields of a streaming RPC, treating
+// events for objects which have not been seen before as creations and all
+// other events as updates (the object types streamed by these RPCs do not
+// record the event op)
+type objectEvents struct {
+	creates bool
+	updates bool
+	seen    map[string]struct{}
+}
+
+func newObjectEvents(creates, updates bool) *objectEvents {
+	return &objectEvents{creates: creates, updates: updates, seen: make(map[string]struct{})}
+}
+
+// Seen marks the object with the given ID as seen, so that subsequent
+// events for it are treated as updates
+func (e *objectEvents) Seen(id string) {
+	e.seen[id] = struct{}{}
+}
+
+func (e *objectEvents) Accept(event *ct.Event) bool {
+	if _, ok := e.seen[event.ObjectID]; ok {
+		return e.updates
+	}
+	if e.creates {
+		e.Seen(event.ObjectID)
+	}
+	return e.creates
+}
+
+// receiveEvents calls fn with each event received by the subscription until
+// either the subscription or the stream is closed
+func receiveEvents(ctx context.Context, sub *data.EventSubscriber, fn func(*ct.Event)) error {
+	for {
+		select {
+		case event, ok := <-sub.Events:
+			if !ok {
+				return maybeError(sub.Err)
+			}
+			fn(event)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (g *grpcAPI) listJobs(req *api.StreamJobsRequest) ([]*ct.Job, error) {
+	appIDs := parseAppIDsFromNames(req.NameFilters)
+	if len(appIDs) == 0 {
+		return g.jobRepo.ListActive()
+	}
+	var jobs []*ct.Job
+	for _, appID := range appIDs {
+		list, err := g.jobRepo.List(appID)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, list...)
+	}
+	return jobs, nil
+}
+
+func (g *grpcAPI) StreamJobs(req *api.StreamJobsRequest, stream api.Controller_StreamJobsServer) error {
+	unary := !(req.StreamUpdates || req.StreamCreates)
+	names := newNameFilter(req.NameFilters)
+	matchJob := func(job *api.Job) bool {
+		// jobs may be referenced by either UUID or job ID
+		if !names.Match(job.Name, path.Join(path.Dir(job.Name), job.JobId)) {
+			return false
+		}
+		if len(req.StateFilters) == 0 {
+			return true
+		}
+		for _, state := range req.StateFilters {
+			if state == job.State {
+				return true
+			}
+		}
+		return false
+	}
+
+	var sub *data.EventSubscriber
+	if !unary {
+		var err error
+		sub, err = g.subscribeEvents(parseAppIDsFromNames(req.NameFilters), []ct.EventType{ct.EventTypeJob}, nil)
+		if err != nil {
+			return err
+		}
+		defer sub.Close()
+	}
+
+	ctJobs, err := g.listJobs(req)
+	if err != nil {
+		return api.NewError(err, err.Error())
+	}
+	events := newObjectEvents(req.StreamCreates, req.StreamUpdates)
+	jobs := make([]*api.Job, 0, len(ctJobs))
+	for _, ctJob := range ctJobs {
+		if job := api.NewJob(ctJob); matchJob(job) {
+			jobs = append(jobs, job)
+			events.Seen(ctJob.UUID)
+		}
+	}
+	stream.Send(&api.StreamJobsResponse{
+		Jobs:         jobs,
+		PageComplete: true,
+	})
+
+	if unary {
+		return nil
+	}
+
+	return receiveEvents(stream.Context(), sub, func(event *ct.Event) {
+		if !events.Accept(event) {
+			return
+		}
+		var ctJob *ct.Job
+		if err := json.Unmarshal(event.Data, &ctJob); err != nil {
+			logger.Error("error unmarshalling event", "rpcMethod", "StreamJobs", "event_id", event.ID, "error", err)
+			return
+		}
+		if job := api.NewJob(ctJob); matchJob(job) {
+			stream.Send(&api.StreamJobsResponse{Jobs: []*api.Job{job}})
+		}
+	})
+}
+
+func (g *grpcAPI) CreateJob(ctx context.Context, req *api.CreateJobRequest) (*api.Job, error) {
+	app, err := g.appRepo.Get(api.ParseIDFromName(req.Parent, "apps"))
+	if err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	job, err := g.runJob(app.(*ct.App), req.ControllerType())
+	if err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	return api.NewJob(job), nil
+}
+
+func (g *grpcAPI) DeleteJob(ctx context.Context, req *api.DeleteJobRequest) (*empty.Empty, error) {
+	job, err := g.jobRepo.Get(api.ParseIDFromName(req.Name, "jobs"))
+	if err == nil && job.AppID != api.ParseIDFromName(req.Name, "apps") {
+		err = ErrNotFound
+	}
+	if err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	if err := g.killJob(job); err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	return &empty.Empty{}, nil
+}
+
+func (g *grpcAPI) listRoutes(names []string) ([]*router.Route, error) {
+	appIDs := parseAppIDsFromNames(names)
+	if len(appIDs) == 0 {
+		return g.routeRepo.List("")
+	}
+	var routes []*router.Route
+	for _, appID := range appIDs {
+		list, err := g.routeRepo.List(routeParentRef(appID))
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, list...)
+	}
+	return routes, nil
+}
+
+// getRouteByName returns the route with the given name, which does not
+// include the route type needed to look up the route directly
+func (g *grpcAPI) getRouteByName(name string) (*router.Route, error) {
+	routes, err := g.listRoutes([]string{name})
+	if err != nil {
+		return nil, err
+	}
+	id := api.ParseIDFromName(name, "routes")
+	for _, route := range routes {
+		if route.ID == id {
+			return route, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (g *grpcAPI) StreamRoutes(req *api.StreamRoutesRequest, stream api.Controller_StreamRoutesServer) error {
+	unary := !(req.StreamUpdates || req.StreamCreates)
+	names := newNameFilter(req.NameFilters)
+
+	var sub *data.EventSubscriber
+	if !unary {
+		var err error
+		sub, err = g.subscribeEvents(parseAppIDsFromNames(req.NameFilters), []ct.EventType{ct.EventTypeRoute, ct.EventTypeRouteDeletion}, nil)
+		if err != nil {
+			return err
+		}
+		defer sub.Close()
+	}
+
+	ctRoutes, err := g.listRoutes(req.NameFilters)
+	if err != nil {
+		return api.NewError(err, err.Error())
+	}
+	sort.Sort(sortedRoutes(ctRoutes))
+	events := newObjectEvents(req.StreamCreates, req.StreamUpdates)
+	routes := make([]*api.Route, 0, len(ctRoutes))
+	for _, ctRoute := range ctRoutes {
+		if route := api.NewRoute(ctRoute); names.Match(route.Name) {
+			routes = append(routes, route)
+			events.Seen(ctRoute.ID)
+		}
+	}
+	stream.Send(&api.StreamRoutesResponse{
+		Routes:       routes,
+		PageComplete: true,
+	})
+
+	if unary {
+		return nil
+	}
+
+	return receiveEvents(stream.Context(), sub, func(event *ct.Event) {
+		if !events.Accept(event) {
+			return
+		}
+		var ctRoute *router.Route
+		if err := json.Unmarshal(event.Data, &ctRoute); err != nil {
+			logger.Error("error unmarshalling event", "rpcMethod", "StreamRoutes", "event_id", event.ID, "error", err)
+			return
+		}
+		route := api.NewRoute(ctRoute)
+		if !names.Match(route.Name) {
+			return
+		}
+		if event.ObjectType == ct.EventTypeRouteDeletion {
+			route.DeleteTime = api.NewTimestamp(event.CreatedAt)
+		}
+		stream.Send(&api.StreamRoutesResponse{Routes: []*api.Route{route}})
+	})
+}
+
+func (g *grpcAPI) CreateRoute(ctx context.Context, req *api.CreateRouteRequest) (*api.Route, error) {
+	if req.Route == nil {
+		return nil, status.Error(codes.InvalidArgument, "route must be set")
+	}
+	appID := api.ParseIDFromName(req.Parent, "apps")
+	route := req.Route.ControllerType()
+	route.ID = ""
+	route.ParentRef = routeParentRef(appID)
+
+	if err := schema.Validate(route); err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	if err := g.quotaRepo.Check(appID, "", nil, &ct.QuotaResources{Routes: 1}); err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	if err := g.routeRepo.Add(route); err != nil {
+		err = routeAddError(route, err)
+		return nil, api.NewError(err, err.Error())
+	}
+	return api.NewRoute(route), nil
+}
+
+func (g *grpcAPI) UpdateRoute(ctx context.Context, req *api.UpdateRouteRequest) (*api.Route, error) {
+	if req.Route == nil {
+		return nil, status.Error(codes.InvalidArgument, "route must be set")
+	}
+	existing, err := g.getRouteByName(req.Route.Name)
+	if err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	route := req.Route.ControllerType()
+	route.Type = existing.Type
+	route.ID = existing.ID
+	route.ParentRef = existing.ParentRef
+	if err := g.routeRepo.Update(route); err != nil {
+		if err == data.ErrRouteNotFound {
+			err = ErrNotFound
+		}
+		return nil, api.NewError(err, err.Error())
+	}
+	return api.NewRoute(route), nil
+}
+
+func (g *grpcAPI) DeleteRoute(ctx context.Context, req *api.DeleteRouteRequest) (*empty.Empty, error) {
+	route, err := g.getRouteByName(req.Name)
+	if err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	if err := g.deleteRoute(route); err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	return &empty.Empty{}, nil
+}
+
+func (g *grpcAPI) StreamProviders(req *api.StreamProvidersRequest, stream api.Controller_StreamProvidersServer) error {
+	unary := !(req.StreamUpdates || req.StreamCreates)
+
+	// providers may be referenced by either ID or name
+	refs := make(map[string]struct{}, len(req.NameFilters))
+	for _, name := range req.NameFilters {
+		refs[api.ParseIDFromName(name, "providers")] = struct{}{}
+	}
+	matchProvider := func(p *ct.Provider) bool {
+		if len(refs) == 0 {
+			return true
+		}
+		_, matchID := refs[p.ID]
+		_, matchName := refs[p.Name]
+		return matchID || matchName
+	}
+
+	var sub *data.EventSubscriber
+	if !unary {
+		var err error
+		sub, err = g.subscribeEvents(nil, []ct.EventType{ct.EventTypeProvider}, nil)
+		if err != nil {
+			return err
+		}
+		defer sub.Close()
+	}
+
+	list, err := g.providerRepo.List()
+	if err != nil {
+		return api.NewError(err, err.Error())
+	}
+	events := newObjectEvents(req.StreamCreates, req.StreamUpdates)
+	var providers []*api.Provider
+	for _, p := range list.([]*ct.Provider) {
+		if matchProvider(p) {
+			providers = append(providers, api.NewProvider(p))
+			events.Seen(p.ID)
+		}
+	}
+	stream.Send(&api.StreamProvidersResponse{
+		Providers:    providers,
+		PageComplete: true,
+	})
+
+	if unary {
+		return nil
+	}
+
+	return receiveEvents(stream.Context(), sub, func(event *ct.Event) {
+		if !events.Accept(event) {
+			return
+		}
+		var p *ct.Provider
+		if err := json.Unmarshal(event.Data, &p); err != nil {
+			logger.Error("error unmarshalling event", "rpcMethod", "StreamProviders", "event_id", event.ID, "error", err)
+			return
+		}
+		if matchProvider(p) {
+			stream.Send(&api.StreamProvidersResponse{Providers: []*api.Provider{api.NewProvider(p)}})
+		}
+	})
+}
+
+func (g *grpcAPI) CreateProvider(ctx context.Context, req *api.CreateProviderRequest) (*api.Provider, error) {
+	if req.Provider == nil {
+		return nil, status.Error(codes.InvalidArgument, "provider must be set")
+	}
+	p := req.Provider.ControllerType()
+	if err := schema.Validate(p); err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	if err := g.providerRepo.Add(p); err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	return api.NewProvider(p), nil
+}
+
+func (g *grpcAPI) getProviderByName(name string) (*ct.Provider, error) {
+	p, err := g.providerRepo.Get(api.ParseIDFromName(name, "providers"))
+	if err != nil {
+		return nil, err
+	}
+	return p.(*ct.Provider), nil
+}
+
+func (g *grpcAPI) StreamResources(req *api.StreamResourcesRequest, stream api.Controller_StreamResourcesServer) error {
+	unary := !(req.StreamUpdates || req.StreamCreates)
+
+	// resolve provider names to IDs, and list the resources of any
+	// filtered apps rather than all resources
+	filters := make([]string, 0, len(req.NameFilters))
+	appIDs := make([]string, 0, len(req.NameFilters))
+	for _, name := range req.NameFilters {
+		if appID := api.ParseIDFromName(name, "apps"); appID != "" {
+			appIDs = append(appIDs, appID)
+			continue
+		}
+		if ref := api.ParseIDFromName(name, "providers"); ref != "" {
+			p, err := g.getProviderByName(name)
+			if err == ErrNotFound {
+				continue
+			} else if err != nil {
+				return api.NewError(err, err.Error())
+			}
+			name = path.Join("providers", p.ID, strings.TrimPrefix(strings.Trim(name, "/"), path.Join("providers", ref)))
+		}
+		filters = append(filters, name)
+	}
+	names := newNameFilter(filters)
+	matchResource := func(r *api.Resource) bool {
+		if len(req.NameFilters) == 0 {
+			return true
+		}
+		if len(filters) > 0 && names.Match(r.Name) {
+			return true
+		}
+		return len(appIDs) > 0 && newNameFilter(r.Apps).Match(req.NameFilters...)
+	}
+
+	var sub *data.EventSubscriber
+	if !unary {
+		var err error
+		sub, err = g.subscribeEvents(nil, []ct.EventType{ct.EventTypeResource, ct.EventTypeResourceDeletion, ct.EventTypeResourceAppDeletion}, nil)
+		if err != nil {
+			return err
+		}
+		defer sub.Close()
+	}
+
+	var ctResources []*ct.Resource
+	if len(filters) == 0 && len(appIDs) > 0 {
+		for _, appID := range appIDs {
+			list, err := g.resourceRepo.AppList(appID)
+			if err != nil {
+				return api.NewError(err, err.Error())
+			}
+			ctResources = append(ctResources, list...)
+		}
+	} else {
+		var err error
+		ctResources, err = g.resourceRepo.List()
+		if err != nil {
+			return api.NewError(err, err.Error())
+		}
+	}
+	events := newObjectEvents(req.StreamCreates, req.StreamUpdates)
+	resources := make([]*api.Resource, 0, len(ctResources))
+	for _, ctResource := range ctResources {
+		if _, ok := events.seen[ctResource.ID]; ok {
+			continue
+		}
+		if r := api.NewResource(ctResource); matchResource(r) {
+			resources = append(resources, r)
+			events.Seen(ctResource.ID)
+		}
+	}
+	stream.Send(&api.StreamResourcesResponse{
+		Resources:    resources,
+		PageComplete: true,
+	})
+
+	if unary {
+		return nil
+	}
+
+	return receiveEvents(stream.Context(), sub, func(event *ct.Event) {
+		if !events.Accept(event) {
+			return
+		}
+		var ctResource *ct.Resource
+		if err := json.Unmarshal(event.Data, &ctResource); err != nil {
+			logger.Error("error unmarshalling event", "rpcMethod", "StreamResources", "event_id", event.ID, "error", err)
+			return
+		}
+		r := api.NewResource(ctResource)
+		if !matchResource(r) {
+			return
+		}
+		if event.ObjectType == ct.EventTypeResourceDeletion {
+			r.DeleteTime = api.NewTimestamp(event.CreatedAt)
+		}
+		stream.Send(&api.StreamResourcesResponse{Resources: []*api.Resource{r}})
+	})
+}
+
+func (g *grpcAPI) CreateResource(ctx context.Context, req *api.CreateResourceRequest) (*api.Resource, error) {
+	p, err := g.getProviderByName(req.Parent)
+	if err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	var config *json.RawMessage
+	if len(req.Config) > 0 {
+		c := json.RawMessage(req.Config)
+		config = &c
+	}
+	var apps []string
+	for _, name := range req.Apps {
+		apps = append(apps, api.ParseIDFromName(name, "apps"))
+	}
+	res, err := g.provisionResource(p, config, apps)
+	if err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	return api.NewResource(res), nil
+}
+
+func (g *grpcAPI) DeleteResource(ctx context.Context, req *api.DeleteResourceRequest) (*api.Resource, error) {
+	p, err := g.getProviderByName(req.Name)
+	if err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	res, err := g.deleteResource(p, api.ParseIDFromName(req.Name, "resources"))
+	if err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	return api.NewResource(res), nil
+}
+
+func (g *grpcAPI) StreamVolumes(req *api.StreamVolumesRequest, stream api.Controller_StreamVolumesServer) error {
+	unary := !(req.StreamUpdates || req.StreamCreates)
+	names := newNameFilter(req.NameFilters)
+	appIDs := parseAppIDsFromNames(req.NameFilters)
+
+	var sub *data.EventSubscriber
+	if !unary {
+		var err error
+		sub, err = g.subscribeEvents(appIDs, []ct.EventType{ct.EventTypeVolume}, nil)
+		if err != nil {
+			return err
+		}
+		defer sub.Close()
+	}
+
+	var ctVolumes []*ct.Volume
+	if len(appIDs) > 0 {
+		for _, appID := range appIDs {
+			list, err := g.volumeRepo.AppList(appID)
+			if err != nil {
+				return api.NewError(err, err.Error())
+			}
+			ctVolumes = append(ctVolumes, list...)
+		}
+	} else {
+		var err error
+		ctVolumes, err = g.volumeRepo.List()
+		if err != nil {
+			return api.NewError(err, err.Error())
+		}
+	}
+	events := newObjectEvents(req.StreamCreates, req.StreamUpdates)
+	volumes := make([]*api.Volume, 0, len(ctVolumes))
+	for _, ctVolume := range ctVolumes {
+		if vol := api.NewVolume(ctVolume); names.Match(vol.Name) {
+			volumes = append(volumes, vol)
+			events.Seen(ctVolume.ID)
+		}
+	}
+	stream.Send(&api.StreamVolumesResponse{
+		Volumes:      volumes,
+		PageComplete: true,
+	})
+
+	if unary {
+		return nil
+	}
+
+	return receiveEvents(stream.Context(), sub, func(event *ct.Event) {
+		if !events.Accept(event) {
+			return
+		}
+		var ctVolume *ct.Volume
+		if err := json.Unmarshal(event.Data, &ctVolume); err != nil {
+			logger.Error("error unmarshalling event", "rpcMethod", "StreamVolumes", "event_id", event.ID, "error", err)
+			return
+		}
+		if vol := api.NewVolume(ctVolume); names.Match(vol.Name) {
+			stream.Send(&api.StreamVolumesResponse{Volumes: []*api.Volume{vol}})
+		}
+	})
+}
+
+func (g *grpcAPI) DecommissionVolume(ctx context.Context, req *api.DecommissionVolumeRequest) (*api.Volume, error) {
+	appID := api.ParseIDFromName(req.Name, "apps")
+	vol, err := g.volumeRepo.Get(appID, api.ParseIDFromName(req.Name, "volumes"))
+	if err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	if err := g.volumeRepo.Decommission(appID, vol); err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	return api.NewVolume(vol), nil
+}
+
+func (g *grpcAPI) StreamSinks(req *api.StreamSinksRequest, stream api.Controller_StreamSinksServer) error {
+	unary := !(req.StreamUpdates || req.StreamCreates)
+	names := newNameFilter(req.NameFilters)
+
+	var sub *data.EventSubscriber
+	if !unary {
+		var err error
+		sub, err = g.subscribeEvents(nil, []ct.EventType{ct.EventTypeSink, ct.EventTypeSinkDeletion}, nil)
+		if err != nil {
+			return err
+		}
+		defer sub.Close()
+	}
+
+	ctSinks, err := g.sinkRepo.List()
+	if err != nil {
+		return api.NewError(err, err.Error())
+	}
+	events := newObjectEvents(req.StreamCreates, req.StreamUpdates)
+	sinks := make([]*api.Sink, 0, len(ctSinks))
+	for _, ctSink := range ctSinks {
+		if sink := api.NewSink(ctSink); names.Match(sink.Name) {
+			sinks = append(sinks, sink)
+			events.Seen(ctSink.ID)
+		}
+	}
+	stream.Send(&api.StreamSinksResponse{
+		Sinks:        sinks,
+		PageComplete: true,
+	})
+
+	if unary {
+		return nil
+	}
+
+	return receiveEvents(stream.Context(), sub, func(event *ct.Event) {
+		if !events.Accept(event) {
+			return
+		}
+		var ctSink *ct.Sink
+		if err := json.Unmarshal(event.Data, &ctSink); err != nil {
+			logger.Error("error unmarshalling event", "rpcMethod", "StreamSinks", "event_id", event.ID, "error", err)
+			return
+		}
+		sink := api.NewSink(ctSink)
+		if !names.Match(sink.Name) {
+			return
+		}
+		if event.ObjectType == ct.EventTypeSinkDeletion {
+			sink.DeleteTime = api.NewTimestamp(event.CreatedAt)
+		}
+		stream.Send(&api.StreamSinksResponse{Sinks: []*api.Sink{sink}})
+	})
+}
+
+func (g *grpcAPI) CreateSink(ctx context.Context, req *api.CreateSinkRequest) (*api.Sink, error) {
+	if req.Sink == nil {
+		return nil, status.Error(codes.InvalidArgument, "sink must be set")
+	}
+	sink := req.Sink.ControllerType()
+	if err := schema.Validate(sink); err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	if err := g.sinkRepo.Add(sink); err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	return api.NewSink(sink), nil
+}
+
+func (g *grpcAPI) DeleteSink(ctx context.Context, req *api.DeleteSinkRequest) (*api.Sink, error) {
+	id := api.ParseIDFromName(req.Name, "sinks")
+	sink, err := g.sinkRepo.Get(id)
+	if err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	if err := g.sinkRepo.Remove(id); err != nil {
+		return nil, api.NewError(err, err.Error())
+	}
+	return api.NewSink(sink), nil
+}
+
+func parseEventID(name string) (*int64, error) {
+	if name == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseInt(api.ParseIDFromName(name, "events"), 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid event name: %q", name)
+	}
+	return &id, nil
+}
+
+// getEvents returns the events with the given IDs which match the given
+// filters, in ID DESC order to match EventRepo.ListEvents
+func (g *grpcAPI) getEvents(ids map[int64]struct{}, appIDs, objectIDs []string, beforeID, sinceID *int64) ([]*ct.Event, error) {
+	match := func(v string, filters []string) bool {
+		if len(filters) == 0 {
+			return true
+		}
+		for _, f := range filters {
+			if v == f {
+				return true
+			}
+		}
+		return false
+	}
+	events := make([]*ct.Event, 0, len(ids))
+	for id := range ids {
+		if beforeID != nil && id >= *beforeID || sinceID != nil && id <= *sinceID {
+			continue
+		}
+		event, err := g.eventRepo.GetEvent(id)
+		if err == ErrNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if match(event.AppID, appIDs) && match(event.ObjectID, objectIDs) {
+			events = append(events, event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID > events[j].ID })
+	return events, nil
+}
+
+func (g *grpcAPI) StreamEvents(req *api.StreamEventsRequest, stream api.Controller_StreamEventsServer) error {
+	appIDs := parseAppIDsFromNames(req.NameFilters)
+	eventIDs := make(map[int64]struct{})
+	for _, name := range req.NameFilters {
+		if api.ParseIDFromName(name, "events") == "" {
+			continue
+		}
+		id, err := parseEventID(name)
+		if err != nil {
+			return err
+		}
+		eventIDs[*id] = struct{}{}
+	}
+	beforeID, err := parseEventID(req.Before)
+	if err != nil {
+		return err
+	}
+	sinceID, err := parseEventID(req.Since)
+	if err != nil {
+		return err
+	}
+
+	var sub *data.EventSubscriber
+	if req.StreamCreates {
+		sub, err = g.subscribeEvents(appIDs, nil, req.ObjectIdFilters)
+		if err != nil {
+			return err
+		}
+		defer sub.Close()
+	}
+
+	objectTypes := make(map[string]struct{}, len(req.ObjectTypeFilters))
+	for _, typ := range req.ObjectTypeFilters {
+		objectTypes[typ] = struct{}{}
+	}
+	// audit events are only readable by unrestricted admin tokens, as in
+	// canReadAuditLog
+	auth, ok := stream.Context().Value(ctxKeyAuthorization).(*utils.Authorization)
+	readAuditLog := ok && auth.Allows(&utils.Permission{Role: ct.TokenRoleAdmin})
+	matchEvent := func(event *ct.Event) bool {
+		if event.ObjectType == ct.EventTypeAudit && !readAuditLog {
+			return false
+		}
+		if len(eventIDs) > 0 {
+			if _, ok := eventIDs[event.ID]; !ok {
+				return false
+			}
+		}
+		if len(objectTypes) > 0 {
+			if _, ok := objectTypes[string(event.ObjectType)]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	var currID int64
+	if sinceID != nil {
+		currID = *sinceID
+	}
+	events := make([]*api.Event, 0, req.PageSize)
+	if !req.SkipPast {
+		var list []*ct.Event
+		if len(eventIDs) > 0 {
+			// look up filtered events directly rather than listing all
+			// events, with the remaining filters applied to the result
+			list, err = g.getEvents(eventIDs, appIDs, req.ObjectIdFilters, beforeID, sinceID)
+		} else {
+			list, err = g.eventRepo.ListEvents(appIDs, req.ObjectTypeFilters, req.ObjectIdFilters, beforeID, sinceID, int(req.PageSize))
+		}
+		if err != nil {
+			return api.NewError(err, err.Error())
+		}
+		// events are in ID DESC order, so iterate in reverse
+		for i := len(list) - 1; i >= 0; i-- {
+			if event := list[i]; matchEvent(event) {
+				events = append(events, api.NewEvent(event))
+			}
+		}
+		if len(list) > 0 && list[0].ID > currID {
+			currID = list[0].ID
+		}
+	}
+	stream.Send(&api.StreamEventsResponse{
+		Events:       events,
+		PageComplete: true,
+	})
+
+	if sub == nil {
+		return nil
+	}
+
+	return receiveEvents(stream.Context(), sub, func(event *ct.Event) {
+		// avoid overlap between list and stream
+		if event.ID <= currID || !matchEvent(event) {
+			return
+		}
+		stream.Send(&api.StreamEventsResponse{Events: []*api.Event{api.NewEvent(event)}})
+	})
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net"
@@ -10,6 +11,8 @@ import (
 	"time"
 
 	"github.com/flynn/flynn/controller/api"
+	controller "github.com/flynn/flynn/controller/client"
+	grpccontroller "github.com/flynn/flynn/controller/client/grpc"
 	"github.com/flynn/flynn/controller/data"
 	tu "github.com/flynn/flynn/controller/testutils"
 	ct "github.com/flynn/flynn/controller/types"
@@ -32,6 +35,9 @@ type GRPCSuite struct {
 	api                 *controllerAPI
 	grpc                api.ControllerClient
 	grpcNoAuth          api.ControllerClient
+	grpcClient          func(...grpc.DialOption) api.ControllerClient
+	grpcConn            func(...grpc.DialOption) *grpc.ClientConn
+	authKey             string
 	httpSrv             *httptest.Server
 	tearDownFns         []func()
 	scaleRequestNameMap map[string]string
@@ -74,7 +80,7 @@ func (s *GRPCSuite) SetUpSuite(c *C) {
 	s.onTeardown(func() { grpcListener.Close() })
 	go grpcServer.Serve(grpcListener)
 
-	grpcClient := func(opts ...grpc.DialOption) api.ControllerClient {
+	grpcConn := func(opts ...grpc.DialOption) *grpc.ClientConn {
 		opts = append(opts, grpc.WithInsecure())
 		opts = append(opts, grpc.WithDialer(func(string, time.Duration) (net.Conn, error) {
 			return grpcListener.Dial()
@@ -84,9 +90,16 @@ func (s *GRPCSuite) SetUpSuite(c *C) {
 			c.Fatalf("did not connect to server: %v", err)
 		}
 		s.onTeardown(func() { conn.Close() })
-		return api.NewControllerClient(conn)
+		return conn
+	}
+	grpcClient := func(opts ...grpc.DialOption) api.ControllerClient {
+		return api.NewControllerClient(grpcConn(opts...))
 	}
 
+	s.grpcConn = grpcConn
+	s.grpcClient = grpcClient
+	s.authKey = authKeys[0]
+
 	// Set up an authenticated connection to the server
 	s.grpc = grpcClient(api.WithAuthKey(authKeys[0]))
 
@@ -245,6 +258,89 @@ func (s *GRPCSuite) TestUnauthenticated(c *C) {
 	c.Assert(errStatus.Code(), Equals, codes.Unauthenticated)
 }
 
+func (s *GRPCSuite) TestTokenPermissionDenied(c *C) {
+	testApp1 := s.createTestApp(c, &api.App{DisplayName: "token-app1"})
+	testApp2 := s.createTestApp(c, &api.App{DisplayName: "token-app2"})
+
+	token := &ct.Token{Name: "grpc-token", Role: ct.TokenRoleRead, Apps: []string{api.ParseIDFromName(testApp1.Name, "apps")}}
+	c.Assert(s.api.tokenRepo.Add(token), IsNil)
+	client := s.grpcClient(api.WithAuthKey(token.Key))
+
+	streamApps := func(names ...string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		stream, err := client.StreamApps(ctx, &api.StreamAppsRequest{NameFilters: names})
+		c.Assert(err, IsNil)
+		_, err = stream.Recv()
+		return err
+	}
+
+	// the token can stream its own app
+	c.Assert(streamApps(testApp1.Name), IsNil)
+
+	// but not other apps, or all apps
+	for _, names := range [][]string{{testApp2.Name}, {testApp1.Name, testApp2.Name}, nil} {
+		err := streamApps(names...)
+		c.Assert(err, Not(IsNil))
+		c.Assert(status.Convert(err).Code(), Equals, codes.PermissionDenied)
+	}
+
+	// the read only token can't update the app
+	_, err := client.UpdateApp(context.Background(), &api.UpdateAppRequest{App: testApp1})
+	c.Assert(status.Convert(err).Code(), Equals, codes.PermissionDenied)
+}
+
+func (s *GRPCSuite) TestStreamEventsAuditPermission(c *C) {
+	testApp := s.createTestApp(c, &api.App{DisplayName: "audit-events-app"})
+	appID := api.ParseIDFromName(testApp.Name, "apps")
+	addAuditRecord := func() {
+		c.Assert(s.api.auditRepo.Add(&ct.AuditRecord{Method: "POST", Path: "/apps/" + appID, AppID: appID, Status: 200}), IsNil)
+	}
+	addAuditRecord()
+
+	token := &ct.Token{Name: "grpc-audit-token", Role: ct.TokenRoleRead}
+	c.Assert(s.api.tokenRepo.Add(token), IsNil)
+	tokenClient := s.grpcClient(api.WithAuthKey(token.Key))
+
+	streamEvents := func(client api.ControllerClient) api.Controller_StreamEventsClient {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		s.onTeardown(cancel)
+		stream, err := client.StreamEvents(ctx, &api.StreamEventsRequest{NameFilters: []string{testApp.Name}, StreamCreates: true})
+		c.Assert(err, IsNil)
+		return stream
+	}
+	objectTypes := func(res *api.StreamEventsResponse) []string {
+		types := make([]string, len(res.Events))
+		for i, e := range res.Events {
+			types[i] = e.ObjectType
+		}
+		return types
+	}
+
+	// the admin key lists audit events, but the read token does not
+	adminStream := streamEvents(s.grpc)
+	res, err := adminStream.Recv()
+	c.Assert(err, IsNil)
+	c.Assert(objectTypes(res), DeepEquals, []string{string(ct.EventTypeApp), string(ct.EventTypeAudit)})
+	tokenStream := streamEvents(tokenClient)
+	res, err = tokenStream.Recv()
+	c.Assert(err, IsNil)
+	c.Assert(objectTypes(res), DeepEquals, []string{string(ct.EventTypeApp)})
+
+	// nor does the read token receive streamed audit events
+	addAuditRecord()
+	s.createTestRelease(c, testApp.Name, &api.Release{})
+	res, err = adminStream.Recv()
+	c.Assert(err, IsNil)
+	c.Assert(objectTypes(res), DeepEquals, []string{string(ct.EventTypeAudit)})
+	res, err = adminStream.Recv()
+	c.Assert(err, IsNil)
+	c.Assert(objectTypes(res), DeepEquals, []string{string(ct.EventTypeRelease)})
+	res, err = tokenStream.Recv()
+	c.Assert(err, IsNil)
+	c.Assert(objectTypes(res), DeepEquals, []string{string(ct.EventTypeRelease)})
+}
+
 func unaryReceiveApps(s *GRPCSuite, c *C, req *api.StreamAppsRequest) (res *api.StreamAppsResponse, receivedEOF bool) {
 	ctx, ctxCancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
 	defer func() {
@@ -1585,3 +1681,178 @@ func (s *GRPCSuite) TestStreamDeploymentsUnaryPagination(c *C) {
 	c.Assert(res.NextPageToken, Equals, "")
 	c.Assert(res.PageComplete, Equals, true)
 }
+
+func (s *GRPCSuite) receiveRoutes(c *C, stream api.Controller_StreamRoutesClient) *api.StreamRoutesResponse {
+	res, err := stream.Recv()
+	c.Assert(err, IsNil)
+	return res
+}
+
+func (s *GRPCSuite) TestRoutes(c *C) {
+	testApp := s.createTestApp(c, &api.App{DisplayName: "grpc-routes"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stream, err := s.grpc.StreamRoutes(ctx, &api.StreamRoutesRequest{
+		NameFilters:   []string{testApp.Name},
+		StreamCreates: true,
+		StreamUpdates: true,
+	})
+	c.Assert(err, IsNil)
+	res := s.receiveRoutes(c, stream)
+	c.Assert(res.Routes, HasLen, 0)
+	c.Assert(res.PageComplete, Equals, true)
+
+	// creating a route streams it
+	route, err := s.grpc.CreateRoute(ctx, &api.CreateRouteRequest{
+		Parent: testApp.Name,
+		Route: &api.Route{
+			Type:    "http",
+			Service: "grpc-routes-web",
+			Domain:  "grpc-routes.example.com",
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(route.Name, Matches, testApp.Name+"/routes/.+")
+	res = s.receiveRoutes(c, stream)
+	c.Assert(res.Routes, HasLen, 1)
+	c.Assert(res.Routes[0].Name, Equals, route.Name)
+	c.Assert(res.Routes[0].Domain, Equals, "grpc-routes.example.com")
+
+	// updating the route streams the update
+	route.Sticky = true
+	route, err = s.grpc.UpdateRoute(ctx, &api.UpdateRouteRequest{Route: route})
+	c.Assert(err, IsNil)
+	c.Assert(route.Sticky, Equals, true)
+	res = s.receiveRoutes(c, stream)
+	c.Assert(res.Routes, HasLen, 1)
+	c.Assert(res.Routes[0].Sticky, Equals, true)
+
+	// the route can be read by name
+	list, err := s.grpc.StreamRoutes(ctx, &api.StreamRoutesRequest{NameFilters: []string{route.Name}})
+	c.Assert(err, IsNil)
+	res = s.receiveRoutes(c, list)
+	c.Assert(res.Routes, HasLen, 1)
+	c.Assert(res.Routes[0].Name, Equals, route.Name)
+
+	// deleting the route streams it with a delete time
+	_, err = s.grpc.DeleteRoute(ctx, &api.DeleteRouteRequest{Name: route.Name})
+	c.Assert(err, IsNil)
+	res = s.receiveRoutes(c, stream)
+	c.Assert(res.Routes, HasLen, 1)
+	c.Assert(res.Routes[0].Name, Equals, route.Name)
+	c.Assert(res.Routes[0].DeleteTime, NotNil)
+
+	_, err = s.grpc.DeleteRoute(ctx, &api.DeleteRouteRequest{Name: route.Name})
+	c.Assert(status.Convert(err).Code(), Equals, codes.NotFound)
+}
+
+func (s *GRPCSuite) TestClient(c *C) {
+	fallback, err := controller.NewClient(s.httpSrv.URL, s.authKey)
+	c.Assert(err, IsNil)
+	client := grpccontroller.NewClient(s.grpcConn(), s.authKey, fallback)
+
+	sinks := make(chan *ct.Sink)
+	stream, err := client.StreamSinks(nil, sinks)
+	c.Assert(err, IsNil)
+	defer stream.Close()
+	receiveSink := func() *ct.Sink {
+		select {
+		case sink, ok := <-sinks:
+			if !ok {
+				c.Fatalf("sink stream closed unexpectedly: %s", stream.Err())
+			}
+			return sink
+		case <-time.After(10 * time.Second):
+			c.Fatal("timed out waiting for sink")
+		}
+		return nil
+	}
+	// skip existing sinks
+	for sink := receiveSink(); sink.ID != ""; sink = receiveSink() {
+	}
+
+	config := json.RawMessage(`{"url":"syslog://grpc.example.com:514"}`)
+	sink := &ct.Sink{Kind: ct.SinkKindSyslog, Config: &config}
+	c.Assert(client.CreateSink(sink), IsNil)
+	c.Assert(sink.ID, Not(Equals), "")
+	c.Assert(receiveSink().ID, Equals, sink.ID)
+
+	gotSink, err := client.GetSink(sink.ID)
+	c.Assert(err, IsNil)
+	c.Assert(gotSink.ID, Equals, sink.ID)
+	c.Assert(string(*gotSink.Config), Equals, string(config))
+
+	events, err := client.ListEvents(ct.ListEventsOptions{
+		ObjectTypes: []ct.EventType{ct.EventTypeSink},
+		ObjectID:    sink.ID,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(events, HasLen, 1)
+	event, err := client.GetEvent(events[0].ID)
+	c.Assert(err, IsNil)
+	c.Assert(event.ObjectID, Equals, sink.ID)
+
+	_, err = client.DeleteSink(sink.ID)
+	c.Assert(err, IsNil)
+	deleted := receiveSink()
+	c.Assert(deleted.ID, Equals, sink.ID)
+	c.Assert(deleted.Config, IsNil)
+
+	_, err = client.GetSink(sink.ID)
+	c.Assert(err, Equals, controller.ErrNotFound)
+	_, err = client.GetEvent(-1)
+	c.Assert(err, Equals, controller.ErrNotFound)
+
+	// methods which aren't part of the gRPC API use the fallback client
+	_, err = client.ArtifactList()
+	c.Assert(err, IsNil)
+}
+
+func (s *GRPCSuite) TestClientWithoutFallback(c *C) {
+	client := grpccontroller.NewClient(s.grpcConn(), s.authKey, nil)
+	client.SetKey(s.authKey)
+
+	st, err := client.Status()
+	c.Assert(err, IsNil)
+	c.Assert(string(st.Status), Equals, "healthy")
+
+	// apps can be looked up by ID or name
+	app := s.createTestApp(c, &api.App{DisplayName: "grpc-client-no-fallback"}).ControllerType()
+	gotApp, err := client.GetApp(app.ID)
+	c.Assert(err, IsNil)
+	c.Assert(gotApp.Name, Equals, app.Name)
+	gotApp, err = client.GetApp(app.Name)
+	c.Assert(err, IsNil)
+	c.Assert(gotApp.ID, Equals, app.ID)
+	c.Assert(client.UpdateAppMeta(&ct.App{ID: app.ID, Meta: map[string]string{"foo": "bar"}}), IsNil)
+	gotApp, err = client.GetApp(app.ID)
+	c.Assert(err, IsNil)
+	c.Assert(gotApp.Meta, DeepEquals, map[string]string{"foo": "bar"})
+
+	_, err = client.GetAppRelease(app.ID)
+	c.Assert(err, Equals, controller.ErrNotFound)
+	release := &ct.Release{Env: map[string]string{"FOO": "BAR"}}
+	c.Assert(client.CreateRelease(app.ID, release), IsNil)
+	c.Assert(release.ID, Not(Equals), "")
+	releases, err := client.AppReleaseList(app.ID)
+	c.Assert(err, IsNil)
+	c.Assert(releases, HasLen, 1)
+	c.Assert(releases[0].ID, Equals, release.ID)
+
+	// the initial deployment finishes immediately
+	d, err := client.CreateDeployment(app.ID, release.ID)
+	c.Assert(err, IsNil)
+	c.Assert(d.NewReleaseID, Equals, release.ID)
+	deployments, err := client.DeploymentList(app.ID)
+	c.Assert(err, IsNil)
+	c.Assert(deployments, HasLen, 1)
+	c.Assert(deployments[0].ID, Equals, d.ID)
+	appRelease, err := client.GetAppRelease(app.ID)
+	c.Assert(err, IsNil)
+	c.Assert(appRelease.ID, Equals, release.ID)
+	c.Assert(appRelease.Env, DeepEquals, release.Env)
+
+	// methods which aren't part of the gRPC API return an error
+	c.Assert(client.CreateApp(&ct.App{}), Equals, grpccontroller.ErrNoFallback)
+}
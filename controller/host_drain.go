@@ -0,0 +1,45 @@
+package main
+
+import (
+	"net/http"
+
+	host "github.com/flynn/flynn/host/types"
+	"github.com/flynn/flynn/pkg/ctxhelper"
+	"github.com/flynn/flynn/pkg/httphelper"
+	"golang.org/x/net/context"
+)
+
+// PutHostDrain stores the status of a host drain, which the scheduler calls
+// as a drain progresses
+func (c *controllerAPI) PutHostDrain(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	params, _ := ctxhelper.ParamsFromContext(ctx)
+
+	var status host.DrainStatus
+	if err := httphelper.DecodeJSON(req, &status); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	status.HostID = params.ByName("host_id")
+	switch status.State {
+	case host.DrainStateDraining, host.DrainStateComplete, host.DrainStateCancelled:
+	default:
+		httphelper.ValidationError(w, "state", "must be one of draining, complete or cancelled")
+		return
+	}
+
+	if err := c.hostDrainRepo.Put(&status); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, &status)
+}
+
+// GetHostDrains returns the most recent drain of each host
+func (c *controllerAPI) GetHostDrains(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	list, err := c.hostDrainRepo.List()
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, list)
+}
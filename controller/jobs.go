@@ -40,6 +40,23 @@ func (c *controllerAPI) ListActiveJobs(ctx context.Context, w http.ResponseWrite
 	httphelper.JSON(w, 200, list)
 }
 
+// ListJobsSince lists the formation jobs which have been updated since the
+// time in the "since" query parameter, which the scheduler uses to rebuild
+// the restart state of process types when it becomes leader
+func (c *controllerAPI) ListJobsSince(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	since, err := time.Parse(time.RFC3339Nano, req.FormValue("since"))
+	if err != nil {
+		httphelper.ValidationError(w, "since", "must be a RFC3339 timestamp")
+		return
+	}
+	list, err := c.jobRepo.ListSince(since)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, list)
+}
+
 func (c *controllerAPI) GetJob(ctx context.Context, w http.ResponseWriter, req *http.Request) {
 	params, _ := ctxhelper.ParamsFromContext(ctx)
 	job, err := c.jobRepo.Get(params.ByName("jobs_id"))
@@ -75,24 +92,31 @@ func (c *controllerAPI) KillJob(ctx context.Context, w http.ResponseWriter, req
 	if err != nil {
 		respondWithError(w, err)
 		return
-	} else if job.HostID == "" {
-		httphelper.ValidationError(w, "", "cannot kill a job which has not been placed on a host")
+	}
+	if err := c.killJob(job); err != nil {
+		respondWithError(w, err)
 		return
 	}
+}
+
+// killJob stops the given job on the host it is running on
+func (c *controllerAPI) killJob(job *ct.Job) error {
+	if job.HostID == "" {
+		return ct.ValidationError{Message: "cannot kill a job which has not been placed on a host"}
+	}
 
 	client, err := c.clusterClient.Host(job.HostID)
 	if err != nil {
-		respondWithError(w, err)
-		return
+		return err
 	}
 
 	if err = client.StopJob(job.ID); err != nil {
 		if _, ok := err.(ct.NotFoundError); ok {
 			err = ErrNotFound
 		}
-		respondWithError(w, err)
-		return
+		return err
 	}
+	return nil
 }
 
 var runJobAttempts = attempt.Strategy{
@@ -107,16 +131,104 @@ func (c *controllerAPI) RunJob(ctx context.Context, w http.ResponseWriter, req *
 		return
 	}
 
-	if err := schema.Validate(newJob); err != nil {
+	app := c.getApp(ctx)
+	attach := strings.Contains(req.Header.Get("Upgrade"), "flynn-attach/0")
+	if !attach {
+		job, err := c.runJob(app, &newJob)
+		if err != nil {
+			respondWithError(w, err)
+			return
+		}
+		httphelper.JSON(w, 200, job)
+		return
+	}
+
+	job, client, err := c.newHostJob(app, &newJob, attach)
+	if err != nil {
 		respondWithError(w, err)
 		return
 	}
 
-	data, err := c.releaseRepo.Get(newJob.ReleaseID)
+	attachReq := &host.AttachReq{
+		JobID:  job.ID,
+		Flags:  host.AttachFlagStdout | host.AttachFlagStderr | host.AttachFlagStdin | host.AttachFlagStream,
+		Height: uint16(newJob.Lines),
+		Width:  uint16(newJob.Columns),
+	}
+	attachClient, err := client.Attach(attachReq, true)
 	if err != nil {
+		respondWithError(w, fmt.Errorf("attach failed: %s", err.Error()))
+		return
+	}
+	defer attachClient.Close()
+
+	if err := addHostJob(client, job); err != nil {
 		respondWithError(w, err)
 		return
 	}
+
+	// TODO(titanous): This Wait could block indefinitely if something goes
+	// wrong, a context should be threaded in that cancels if the client
+	// goes away.
+	if err := attachClient.Wait(); err != nil {
+		respondWithError(w, fmt.Errorf("attach wait failed: %s", err.Error()))
+		return
+	}
+	w.Header().Set("Connection", "upgrade")
+	w.Header().Set("Upgrade", "flynn-attach/0")
+	w.WriteHeader(http.StatusSwitchingProtocols)
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+
+	done := make(chan struct{}, 2)
+	cp := func(to io.Writer, from io.Reader) {
+		io.Copy(to, from)
+		done <- struct{}{}
+	}
+	go cp(conn, attachClient.Conn())
+	go cp(attachClient.Conn(), conn)
+
+	// Wait for one of the connections to be closed or interrupted. EOF is
+	// framed inside the attach protocol, so a read/write error indicates
+	// that we're done and should clean up.
+	<-done
+}
+
+// runJob runs a detached one-off job for the given app
+func (c *controllerAPI) runJob(app *ct.App, newJob *ct.NewJob) (*ct.Job, error) {
+	job, client, err := c.newHostJob(app, newJob, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := addHostJob(client, job); err != nil {
+		return nil, err
+	}
+	uuid, _ := cluster.ExtractUUID(job.ID)
+	return &ct.Job{
+		ID:        job.ID,
+		UUID:      uuid,
+		HostID:    client.ID(),
+		AppID:     app.ID,
+		ReleaseID: newJob.ReleaseID,
+		Args:      newJob.Args,
+	}, nil
+}
+
+// newHostJob validates the given one-off job and returns the host job to
+// run along with the randomly chosen host to run it on, provisioning a data
+// volume on the host if requested
+func (c *controllerAPI) newHostJob(app *ct.App, newJob *ct.NewJob, attach bool) (*host.Job, utils.HostClient, error) {
+	if err := schema.Validate(newJob); err != nil {
+		return nil, nil, err
+	}
+
+	data, err := c.releaseRepo.Get(newJob.ReleaseID)
+	if err != nil {
+		return nil, nil, err
+	}
 	release := data.(*ct.Release)
 	var artifactIDs []string
 	if len(newJob.ArtifactIDs) > 0 {
@@ -124,15 +236,13 @@ func (c *controllerAPI) RunJob(ctx context.Context, w http.ResponseWriter, req *
 	} else if len(release.ArtifactIDs) > 0 {
 		artifactIDs = release.ArtifactIDs
 	} else {
-		httphelper.ValidationError(w, "release.ArtifactIDs", "cannot be empty")
-		return
+		return nil, nil, ct.ValidationError{Field: "release.ArtifactIDs", Message: "cannot be empty"}
 	}
 
 	artifacts := make([]*ct.Artifact, len(artifactIDs))
 	artifactList, err := c.artifactRepo.ListIDs(artifactIDs...)
 	if err != nil {
-		respondWithError(w, err)
-		return
+		return nil, nil, err
 	}
 	for i, id := range artifactIDs {
 		artifacts[i] = artifactList[id]
@@ -143,23 +253,16 @@ func (c *controllerAPI) RunJob(ctx context.Context, w http.ResponseWriter, req *
 		entrypoint = *e
 	}
 
-	attach := strings.Contains(req.Header.Get("Upgrade"), "flynn-attach/0")
-
 	hosts, err := c.clusterClient.Hosts()
 	if err != nil {
-		respondWithError(w, err)
-		return
+		return nil, nil, err
 	}
 	if len(hosts) == 0 {
-		respondWithError(w, errors.New("no hosts found"))
-		return
+		return nil, nil, errors.New("no hosts found")
 	}
 	client := hosts[random.Math.Intn(len(hosts))]
 
-	uuid := random.UUID()
-	hostID := client.ID()
-	id := cluster.GenerateJobID(hostID, uuid)
-	app := c.getApp(ctx)
+	id := cluster.GenerateJobID(client.ID(), random.UUID())
 	env := make(map[string]string, len(entrypoint.Env)+len(release.Env)+len(newJob.Env)+4)
 	env["FLYNN_APP_ID"] = app.ID
 	env["FLYNN_RELEASE_ID"] = release.ID
@@ -176,6 +279,9 @@ func (c *controllerAPI) RunJob(ctx context.Context, w http.ResponseWriter, req *
 	for k, v := range newJob.Env {
 		env[k] = v
 	}
+	if err := c.config.secrets.DecryptEnv(app.ID, env); err != nil {
+		return nil, nil, ct.ValidationError{Field: "env", Message: err.Error()}
+	}
 	metadata := make(map[string]string, len(newJob.Meta)+3)
 	for k, v := range newJob.Meta {
 		metadata[k] = v
@@ -201,6 +307,9 @@ func (c *controllerAPI) RunJob(ctx context.Context, w http.ResponseWriter, req *
 		Profiles:  newJob.Profiles,
 	}
 	resource.SetDefaults(&job.Resources)
+	if err := c.quotaRepo.CheckJob(app.ID, job.Resources); err != nil {
+		return nil, nil, err
+	}
 	if len(newJob.Args) > 0 {
 		job.Config.Args = newJob.Args
 	}
@@ -213,73 +322,20 @@ func (c *controllerAPI) RunJob(ctx context.Context, w http.ResponseWriter, req *
 	if newJob.Data {
 		vol := &ct.VolumeReq{Path: "/data", DeleteOnStop: true}
 		if _, err := utils.ProvisionVolume(vol, client, job); err != nil {
-			respondWithError(w, err)
-			return
+			return nil, nil, err
 		}
 	}
+	return job, client, nil
+}
 
-	var attachClient cluster.AttachClient
-	if attach {
-		attachReq := &host.AttachReq{
-			JobID:  job.ID,
-			Flags:  host.AttachFlagStdout | host.AttachFlagStderr | host.AttachFlagStdin | host.AttachFlagStream,
-			Height: uint16(newJob.Lines),
-			Width:  uint16(newJob.Columns),
-		}
-		attachClient, err = client.Attach(attachReq, true)
-		if err != nil {
-			respondWithError(w, fmt.Errorf("attach failed: %s", err.Error()))
-			return
-		}
-		defer attachClient.Close()
-	}
-
-	err = runJobAttempts.RunWithValidator(func() error {
+// addHostJob adds the job to the host, retrying if the host is temporarily
+// unavailable
+func addHostJob(client utils.HostClient, job *host.Job) error {
+	err := runJobAttempts.RunWithValidator(func() error {
 		return client.AddJob(job)
 	}, httphelper.IsRetryableError)
 	if err != nil {
-		respondWithError(w, fmt.Errorf("schedule failed: %s", err.Error()))
-		return
-	}
-
-	if attach {
-		// TODO(titanous): This Wait could block indefinitely if something goes
-		// wrong, a context should be threaded in that cancels if the client
-		// goes away.
-		if err := attachClient.Wait(); err != nil {
-			respondWithError(w, fmt.Errorf("attach wait failed: %s", err.Error()))
-			return
-		}
-		w.Header().Set("Connection", "upgrade")
-		w.Header().Set("Upgrade", "flynn-attach/0")
-		w.WriteHeader(http.StatusSwitchingProtocols)
-		conn, _, err := w.(http.Hijacker).Hijack()
-		if err != nil {
-			panic(err)
-		}
-		defer conn.Close()
-
-		done := make(chan struct{}, 2)
-		cp := func(to io.Writer, from io.Reader) {
-			io.Copy(to, from)
-			done <- struct{}{}
-		}
-		go cp(conn, attachClient.Conn())
-		go cp(attachClient.Conn(), conn)
-
-		// Wait for one of the connections to be closed or interrupted. EOF is
-		// framed inside the attach protocol, so a read/write error indicates
-		// that we're done and should clean up.
-		<-done
-
-		return
-	} else {
-		httphelper.JSON(w, 200, &ct.Job{
-			ID:        job.ID,
-			UUID:      uuid,
-			HostID:    hostID,
-			ReleaseID: newJob.ReleaseID,
-			Args:      newJob.Args,
-		})
+		return fmt.Errorf("schedule failed: %s", err.Error())
 	}
+	return nil
 }
@@ -0,0 +1,708 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/flynn/flynn/controller/data"
+	"github.com/flynn/flynn/controller/schema"
+	"github.com/flynn/flynn/controller/secrets"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/pkg/httphelper"
+	router "github.com/flynn/flynn/router/types"
+	"golang.org/x/net/context"
+)
+
+// secretPlaceholder replaces the values of secret env vars in manifest diffs
+const secretPlaceholder = "<secret>"
+
+// ManifestDryRun returns the changes which applying the given manifest would
+// make to the app, without making them
+func (c *controllerAPI) ManifestDryRun(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	app := c.getApp(ctx)
+	var manifest ct.AppManifest
+	if err := httphelper.DecodeJSON(req, &manifest); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	plan, err := c.planManifest(app, &manifest)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, plan.diff)
+}
+
+// ApplyManifest applies the given manifest to the app as a single release,
+// formation and route change
+func (c *controllerAPI) ApplyManifest(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	app := c.getApp(ctx)
+	var manifest ct.AppManifest
+	if err := httphelper.DecodeJSON(req, &manifest); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	plan, err := c.planManifest(app, &manifest)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if err := c.applyManifest(app, plan); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, plan.diff)
+}
+
+// manifestPlan is the set of changes needed to apply a manifest
+type manifestPlan struct {
+	diff *ct.ManifestDiff
+
+	// prev is the app's current release, or nil if it doesn't have one
+	prev *ct.Release
+
+	// release is the new release, or nil if the release is unchanged
+	release *ct.Release
+
+	// formation is the new formation, or nil if the formation is
+	// unchanged, and current and tags are the current formation's
+	// processes and tags
+	formation map[string]int
+	current   map[string]int
+	tags      map[string]map[string]string
+
+	// env is the env set by the manifest, which takes precedence over
+	// the env of provisioned resources
+	env map[string]string
+
+	providers []*ct.Provider
+	appUpdate appUpdate
+
+	addRoutes    []*router.Route
+	updateRoutes []*router.Route
+	deleteRoutes []*router.Route
+}
+
+func (p *manifestPlan) change(typ string, op ct.ManifestChangeOp, name, before, after string) {
+	p.diff.Changes = append(p.diff.Changes, &ct.ManifestChange{
+		Type: typ,
+		Op:   op,
+		Name: name,
+		Old:  before,
+		New:  after,
+	})
+}
+
+// changeOp returns the operation which changes a value from before to after,
+// with empty values meaning the value is not set
+func changeOp(before, after string) ct.ManifestChangeOp {
+	switch {
+	case before == "":
+		return ct.ManifestChangeAdd
+	case after == "":
+		return ct.ManifestChangeRemove
+	default:
+		return ct.ManifestChangeUpdate
+	}
+}
+
+// planManifest determines the changes needed to apply the manifest to the
+// app, validating the manifest in the process
+func (c *controllerAPI) planManifest(app *ct.App, m *ct.AppManifest) (*manifestPlan, error) {
+	p := &manifestPlan{diff: &ct.ManifestDiff{Changes: []*ct.ManifestChange{}}}
+
+	current, err := c.appRepo.GetRelease(app.ID)
+	if err == nil {
+		p.prev = current
+	} else if err == ErrNotFound {
+		current = &ct.Release{}
+	} else {
+		return nil, err
+	}
+
+	if m.Deploy != nil {
+		if err := p.planDeploy(app, m.Deploy); err != nil {
+			return nil, err
+		}
+	}
+
+	resources, err := c.resourceRepo.AppList(app.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range m.Resources {
+		if err := c.planResource(p, ref, resources); err != nil {
+			return nil, err
+		}
+	}
+
+	// compare the decrypted env, keeping secret vars secret
+	env := make(map[string]string, len(current.Env))
+	for k, v := range current.Env {
+		env[k] = v
+	}
+	if err := c.releaseRepo.DecryptSecrets(&ct.Release{AppID: app.ID, Env: env}); err != nil {
+		return nil, err
+	}
+	secret := make(map[string]bool, len(current.Secrets))
+	for _, name := range current.Secrets {
+		secret[name] = true
+	}
+	newEnv := env
+	if m.Env != nil {
+		groups, err := c.envGroupRepo.AppList(app.ID)
+		if err != nil {
+			return nil, err
+		}
+		newEnv = make(map[string]string, len(m.Env))
+		for _, r := range resources {
+			for k, v := range r.Env {
+				newEnv[k] = v
+			}
+		}
+		for k, v := range data.MergeEnvGroups(groups) {
+			newEnv[k] = v
+		}
+		for k, v := range m.Env {
+			newEnv[k] = v
+		}
+		p.env = m.Env
+	}
+	envChanged := p.planEnv(env, newEnv, secret)
+
+	processes := current.Processes
+	var processesChanged bool
+	if m.Processes != nil {
+		processes = make(map[string]ct.ProcessType, len(m.Processes))
+		for typ, raw := range m.Processes {
+			proc, err := mergeProcessType(current.Processes[typ], raw)
+			if err != nil {
+				return nil, ct.ValidationError{Field: "processes." + typ, Message: err.Error()}
+			}
+			processes[typ] = proc
+		}
+		if processesChanged, err = p.planProcesses(current.Processes, processes); err != nil {
+			return nil, err
+		}
+	}
+
+	release := current
+	if envChanged || processesChanged || len(p.providers) > 0 {
+		meta := make(map[string]string, len(current.Meta))
+		for k, v := range current.Meta {
+			meta[k] = v
+		}
+		var secrets []string
+		for name := range secret {
+			if _, ok := newEnv[name]; ok {
+				secrets = append(secrets, name)
+			}
+		}
+		sort.Strings(secrets)
+		release = &ct.Release{
+			AppID:            app.ID,
+			ArtifactIDs:      current.ArtifactIDs,
+			LegacyArtifactID: current.LegacyArtifactID,
+			Env:              newEnv,
+			Meta:             meta,
+			Processes:        processes,
+			Secrets:          secrets,
+		}
+		p.release = release
+	}
+
+	var formation map[string]int
+	if p.prev != nil {
+		f, err := c.formationRepo.Get(app.ID, p.prev.ID)
+		if err == nil {
+			formation = f.Processes
+			p.current = f.Processes
+			p.tags = f.Tags
+		} else if err != ErrNotFound {
+			return nil, err
+		}
+	}
+	if m.Formation != nil {
+		if err := p.planFormation(release, formation, m.Formation); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.Routes != nil {
+		routes, err := c.routeRepo.List(routeParentRef(app.ID))
+		if err != nil {
+			return nil, err
+		}
+		if err := p.planRoutes(app, routes, m.Routes); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+func (p *manifestPlan) planDeploy(app *ct.App, deploy *ct.ManifestDeploy) error {
+	update := make(appUpdate)
+	if deploy.Strategy != "" && deploy.Strategy != app.Strategy {
+		update["strategy"] = deploy.Strategy
+		p.change("deploy", changeOp(app.Strategy, deploy.Strategy), "strategy", app.Strategy, deploy.Strategy)
+	}
+	if deploy.Timeout != 0 && deploy.Timeout != app.DeployTimeout {
+		update["deploy_timeout"] = deploy.Timeout
+		p.change("deploy", ct.ManifestChangeUpdate, "timeout", strconv.Itoa(int(app.DeployTimeout)), strconv.Itoa(int(deploy.Timeout)))
+	}
+	if len(update) == 0 {
+		return nil
+	}
+	if err := schema.Validate(update); err != nil {
+		return err
+	}
+	p.appUpdate = update
+	return nil
+}
+
+func (c *controllerAPI) planResource(p *manifestPlan, ref string, resources []*ct.Resource) error {
+	v, err := c.providerRepo.Get(ref)
+	if err == ErrNotFound {
+		return ct.ValidationError{Field: "resources", Message: fmt.Sprintf("provider %q not found", ref)}
+	} else if err != nil {
+		return err
+	}
+	provider := v.(*ct.Provider)
+	for _, r := range resources {
+		if r.ProviderID == provider.ID {
+			return nil
+		}
+	}
+	for _, existing := range p.providers {
+		if existing.ID == provider.ID {
+			return nil
+		}
+	}
+	p.providers = append(p.providers, provider)
+	p.change("resource", ct.ManifestChangeAdd, provider.Name, "", "")
+	return nil
+}
+
+// planEnv records the changes from env to newEnv, returning whether there
+// are any
+func (p *manifestPlan) planEnv(env, newEnv map[string]string, secret map[string]bool) bool {
+	names := make([]string, 0, len(env)+len(newEnv))
+	for k := range env {
+		names = append(names, k)
+	}
+	for k := range newEnv {
+		if _, ok := env[k]; !ok {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+
+	var changed bool
+	for _, k := range names {
+		before, hadBefore := env[k]
+		after, hasAfter := newEnv[k]
+		if hadBefore == hasAfter && before == after {
+			continue
+		}
+		changed = true
+		if secret[k] {
+			if hadBefore {
+				before = secretPlaceholder
+			}
+			if hasAfter {
+				after = secretPlaceholder
+			}
+		}
+		switch {
+		case !hadBefore:
+			p.change("env", ct.ManifestChangeAdd, k, "", after)
+		case !hasAfter:
+			p.change("env", ct.ManifestChangeRemove, k, before, "")
+		default:
+			p.change("env", ct.ManifestChangeUpdate, k, before, after)
+		}
+	}
+	return changed
+}
+
+// mergeProcessType returns a copy of proc with the fields set in raw
+// replacing those of proc
+func mergeProcessType(proc ct.ProcessType, raw json.RawMessage) (ct.ProcessType, error) {
+	// round trip proc through JSON so that merging doesn't modify its
+	// maps and slices
+	var merged ct.ProcessType
+	b, err := json.Marshal(proc)
+	if err != nil {
+		return merged, err
+	}
+	if err := json.Unmarshal(b, &merged); err != nil {
+		return merged, err
+	}
+	err = json.Unmarshal(raw, &merged)
+	return merged, err
+}
+
+// planProcesses records the changes from processes to newProcesses,
+// returning whether there are any
+func (p *manifestPlan) planProcesses(processes, newProcesses map[string]ct.ProcessType) (bool, error) {
+	types := make([]string, 0, len(processes)+len(newProcesses))
+	for typ := range processes {
+		types = append(types, typ)
+	}
+	for typ := range newProcesses {
+		if _, ok := processes[typ]; !ok {
+			types = append(types, typ)
+		}
+	}
+	sort.Strings(types)
+
+	var changed bool
+	for _, typ := range types {
+		var before, after string
+		if proc, ok := processes[typ]; ok {
+			b, err := json.Marshal(proc)
+			if err != nil {
+				return false, err
+			}
+			before = string(b)
+		}
+		if proc, ok := newProcesses[typ]; ok {
+			b, err := json.Marshal(proc)
+			if err != nil {
+				return false, err
+			}
+			after = string(b)
+		}
+		if before != after {
+			changed = true
+			p.change("process", changeOp(before, after), typ, before, after)
+		}
+	}
+	return changed, nil
+}
+
+// planFormation validates the given formation of the release and records
+// the changes from the current formation, with process types which are not
+// in the formation being scaled to zero
+func (p *manifestPlan) planFormation(release *ct.Release, current, formation map[string]int) error {
+	processes := make(map[string]int, len(release.Processes))
+	for typ := range release.Processes {
+		processes[typ] = 0
+	}
+	var running bool
+	for typ, count := range formation {
+		field := "formation." + typ
+		if _, ok := release.Processes[typ]; !ok {
+			return ct.ValidationError{Field: field, Message: "is not a process type of the release"}
+		}
+		if count < 0 {
+			return ct.ValidationError{Field: field, Message: "must not be negative"}
+		}
+		if typ == ct.ReleaseProcessType && count > 0 {
+			return ct.ValidationError{Field: field, Message: "is run when deploying and cannot be scaled"}
+		}
+		processes[typ] = count
+		running = running || count > 0
+	}
+	if running && len(release.ArtifactIDs) == 0 {
+		return ct.ValidationError{Field: "formation", Message: "cannot be scaled as the release is not deployable"}
+	}
+
+	types := make([]string, 0, len(processes)+len(current))
+	for typ := range processes {
+		types = append(types, typ)
+	}
+	for typ := range current {
+		if _, ok := processes[typ]; !ok {
+			types = append(types, typ)
+		}
+	}
+	sort.Strings(types)
+	var changed bool
+	for _, typ := range types {
+		if before, after := current[typ], processes[typ]; before != after {
+			changed = true
+			p.change("formation", ct.ManifestChangeUpdate, typ, strconv.Itoa(before), strconv.Itoa(after))
+		}
+	}
+	if changed || p.release != nil {
+		p.formation = processes
+	}
+	return nil
+}
+
+func manifestRoute(r *router.Route) *ct.ManifestRoute {
+	return &ct.ManifestRoute{
+		Type:          r.Type,
+		Domain:        r.Domain,
+		Path:          r.Path,
+		Port:          r.Port,
+		Service:       r.Service,
+		Leader:        r.Leader,
+		Sticky:        r.Sticky,
+		DrainBackends: r.DrainBackends,
+	}
+}
+
+// planRoutes validates the given routes and records the changes from the
+// app's current routes
+func (p *manifestPlan) planRoutes(app *ct.App, current []*router.Route, routes []*ct.ManifestRoute) error {
+	existing := make(map[string]*router.Route, len(current))
+	for _, r := range current {
+		existing[manifestRoute(r).Key()] = r
+	}
+
+	seen := make(map[string]struct{}, len(routes))
+	var adds []*ct.ManifestRoute
+	for i, r := range routes {
+		field := fmt.Sprintf("routes[%d]", i)
+		route := *r
+		switch route.Type {
+		case "", "http":
+			route.Type = "http"
+			if route.Domain == "" {
+				return ct.ValidationError{Field: field + ".domain", Message: "must be set for HTTP routes"}
+			}
+			if route.Port != 0 {
+				return ct.ValidationError{Field: field + ".port", Message: "must not be set for HTTP routes"}
+			}
+			// paths are stored with a trailing slash
+			if !strings.HasSuffix(route.Path, "/") {
+				route.Path += "/"
+			}
+		case "tcp":
+			if route.Port <= 0 {
+				return ct.ValidationError{Field: field + ".port", Message: "must be set for TCP routes"}
+			}
+			if route.Domain != "" || route.Path != "" {
+				return ct.ValidationError{Field: field, Message: "domain and path must not be set for TCP routes"}
+			}
+		default:
+			return ct.ValidationError{Field: field + ".type", Message: `must be either "http" or "tcp"`}
+		}
+		if route.Service == "" {
+			route.Service = app.Name + "-web"
+		}
+
+		key := route.Key()
+		if _, ok := seen[key]; ok {
+			return ct.ValidationError{Field: field, Message: fmt.Sprintf("duplicate route %s", key)}
+		}
+		seen[key] = struct{}{}
+
+		r, ok := existing[key]
+		if !ok {
+			adds = append(adds, &route)
+			continue
+		}
+		before := manifestRoute(r).String()
+		if before == route.String() {
+			continue
+		}
+		updated := *r
+		updated.Service = route.Service
+		updated.Leader = route.Leader
+		updated.Sticky = route.Sticky
+		updated.DrainBackends = route.DrainBackends
+		p.updateRoutes = append(p.updateRoutes, &updated)
+		p.change("route", ct.ManifestChangeUpdate, key, before, route.String())
+	}
+
+	// delete routes with paths before their domain's default route, which
+	// cannot be deleted while they exist
+	deletes := make([]string, 0, len(existing))
+	for key := range existing {
+		if _, ok := seen[key]; !ok {
+			deletes = append(deletes, key)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(deletes)))
+	for _, key := range deletes {
+		r := existing[key]
+		p.deleteRoutes = append(p.deleteRoutes, r)
+		p.change("route", ct.ManifestChangeRemove, key, manifestRoute(r).String(), "")
+	}
+
+	// add default routes before routes with paths, which require them
+	sort.Slice(adds, func(i, j int) bool { return adds[i].Key() < adds[j].Key() })
+	for _, route := range adds {
+		p.addRoutes = append(p.addRoutes, &router.Route{
+			Type:          route.Type,
+			ParentRef:     routeParentRef(app.ID),
+			Service:       route.Service,
+			Port:          route.Port,
+			Leader:        route.Leader,
+			Domain:        route.Domain,
+			Path:          route.Path,
+			Sticky:        route.Sticky,
+			DrainBackends: route.DrainBackends,
+		})
+		p.change("route", ct.ManifestChangeAdd, route.Key(), "", route.String())
+	}
+	return nil
+}
+
+// applyManifest makes the changes in the plan, validating them all and
+// checking the app's quota before making any of them.
+//
+// Applying a manifest is not atomic: resources are provisioned first (as
+// that is the step most likely to fail), followed by updating the app,
+// creating the release and deployment and then changing routes, with an
+// error in any step leaving the changes made by the previous steps in place.
+func (c *controllerAPI) applyManifest(app *ct.App, p *manifestPlan) error {
+	for _, route := range p.addRoutes {
+		if err := schema.Validate(route); err != nil {
+			return err
+		}
+	}
+	if p.release != nil {
+		if err := schema.Validate(p.release); err != nil {
+			return err
+		}
+		if err := c.releaseRepo.Validate(p.release); err != nil {
+			return err
+		}
+	}
+	// resource env is stored as secret env vars, so check it can be
+	// encrypted before provisioning any resources
+	if len(p.providers) > 0 && !c.releaseRepo.CanEncryptSecrets() {
+		return ct.ValidationError{Field: "resources", Message: secrets.ErrNoKeyring.Error()}
+	}
+	if err := c.checkManifestQuota(app, p); err != nil {
+		return err
+	}
+
+	secret := make(map[string]struct{})
+	for _, provider := range p.providers {
+		res, err := c.provisionResource(provider, nil, []string{app.ID})
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(res.Env))
+		for k := range res.Env {
+			if _, ok := p.env[k]; !ok {
+				names = append(names, k)
+			}
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			secret[k] = struct{}{}
+			before, ok := p.release.Env[k]
+			if ok && before == res.Env[k] {
+				continue
+			}
+			p.release.Env[k] = res.Env[k]
+			// resource env contains credentials, so keep the values
+			// out of the diff
+			op := ct.ManifestChangeUpdate
+			if !ok {
+				op = ct.ManifestChangeAdd
+				before = ""
+			} else {
+				before = secretPlaceholder
+			}
+			p.change("env", op, k, before, secretPlaceholder)
+		}
+	}
+	if len(secret) > 0 {
+		for _, name := range p.release.Secrets {
+			secret[name] = struct{}{}
+		}
+		p.release.Secrets = make([]string, 0, len(secret))
+		for name := range secret {
+			p.release.Secrets = append(p.release.Secrets, name)
+		}
+		sort.Strings(p.release.Secrets)
+	}
+
+	if p.appUpdate != nil {
+		if _, err := c.appRepo.Update(app.ID, p.appUpdate); err != nil {
+			return err
+		}
+	}
+
+	if p.release != nil {
+		if err := c.releaseRepo.Add(p.release); err != nil {
+			return err
+		}
+		p.diff.ReleaseID = p.release.ID
+
+		deployment, err := c.deploymentRepo.AddWithProcesses(app.ID, p.release.ID, p.formation)
+		if err != nil {
+			return err
+		}
+		p.diff.DeploymentID = deployment.ID
+
+		// deployments of apps without running processes finish
+		// immediately, so scale the new release directly
+		if deployment.FinishedAt != nil && p.formation != nil && len(p.release.ArtifactIDs) > 0 {
+			if err := c.scaleManifestFormation(app, p.release, p.formation, p.tags); err != nil {
+				return err
+			}
+		}
+	} else if p.formation != nil {
+		if err := c.scaleManifestFormation(app, p.prev, p.formation, p.tags); err != nil {
+			return err
+		}
+	}
+
+	for _, route := range p.deleteRoutes {
+		if err := c.routeRepo.Delete(route); err != nil {
+			return err
+		}
+	}
+	for _, route := range p.updateRoutes {
+		if err := c.routeRepo.Update(route); err != nil {
+			return err
+		}
+	}
+	for _, route := range p.addRoutes {
+		if err := c.routeRepo.Add(route); err != nil {
+			return routeAddError(route, err)
+		}
+	}
+	return nil
+}
+
+// checkManifestQuota returns a ValidationError naming the exceeded quota if
+// applying the plan would exceed the app's quota, checking the new release
+// before it is created
+func (c *controllerAPI) checkManifestQuota(app *ct.App, p *manifestPlan) error {
+	var extra *ct.QuotaResources
+	if n := len(p.addRoutes) - len(p.deleteRoutes); n > 0 {
+		extra = &ct.QuotaResources{Routes: int64(n)}
+	}
+	switch {
+	case p.release != nil:
+		processes := p.formation
+		if processes == nil {
+			processes = p.current
+		}
+		var prevID string
+		if p.prev != nil {
+			prevID = p.prev.ID
+		}
+		return c.quotaRepo.CheckRelease(app.ID, prevID, p.release, processes, extra)
+	case p.formation != nil:
+		return c.quotaRepo.Check(app.ID, p.prev.ID, p.formation, extra)
+	case extra != nil:
+		return c.quotaRepo.Check(app.ID, "", nil, extra)
+	}
+	return nil
+}
+
+func (c *controllerAPI) scaleManifestFormation(app *ct.App, release *ct.Release, processes map[string]int, tags map[string]map[string]string) error {
+	req := newScaleRequest(&ct.Formation{
+		AppID:     app.ID,
+		ReleaseID: release.ID,
+		Processes: processes,
+		Tags:      tags,
+	}, release)
+	_, err := c.formationRepo.AddScaleRequest(req, false)
+	return err
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/flynn/flynn/controller/data"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/controller/utils"
+	"github.com/flynn/flynn/pkg/oidc"
+)
+
+// newAuthorizer returns an authorizer which accepts the configured auth keys,
+// API tokens and, if configured, OIDC issued JWTs
+func newAuthorizer(c handlerConfig, tokenRepo *data.TokenRepo) *utils.Authorizer {
+	if c.oidc != nil {
+		// check JWTs first as non-JWT keys are rejected without a
+		// database lookup
+		return utils.NewAuthorizer(c.keys, c.keyIDs, c.oidc, tokenRepo)
+	}
+	return utils.NewAuthorizer(c.keys, c.keyIDs, tokenRepo)
+}
+
+// oidcTokenStore authenticates JWTs issued by an OpenID Connect provider,
+// returning a token with the role mapped from the configured role claim so
+// that OIDC users are subject to the same permission checks as API tokens
+type oidcTokenStore struct {
+	verifier  *oidc.Verifier
+	roleClaim string
+	roles     map[string]ct.TokenRole
+}
+
+// oidcTokenStoreFromEnv returns an oidcTokenStore configured from the
+// following environment variables, or nil if OIDC_ISSUER is not set:
+//
+// OIDC_ISSUER     the issuer URL
+// OIDC_AUDIENCE   comma separated list of accepted audiences (i.e. client IDs)
+// OIDC_JWKS_URL   the issuer's JWKS URL (discovered from the issuer if not set)
+// OIDC_ROLE_CLAIM the claim to map to a role (defaults to "groups")
+// OIDC_ROLES      comma separated list of value:role pairs (e.g. "ops:admin,devs:deploy")
+func oidcTokenStoreFromEnv() (*oidcTokenStore, error) {
+	issuer := os.Getenv("OIDC_ISSUER")
+	if issuer == "" {
+		return nil, nil
+	}
+	audience := os.Getenv("OIDC_AUDIENCE")
+	if audience == "" {
+		return nil, fmt.Errorf("OIDC_AUDIENCE must be set when OIDC_ISSUER is set")
+	}
+	jwksURL := os.Getenv("OIDC_JWKS_URL")
+	if jwksURL == "" {
+		config, err := oidc.Discover(issuer)
+		if err != nil {
+			return nil, err
+		}
+		jwksURL = config.JWKSURI
+	}
+	roles, err := parseOIDCRoles(os.Getenv("OIDC_ROLES"))
+	if err != nil {
+		return nil, err
+	}
+	roleClaim := os.Getenv("OIDC_ROLE_CLAIM")
+	if roleClaim == "" {
+		roleClaim = "groups"
+	}
+	return &oidcTokenStore{
+		verifier:  oidc.NewVerifier(issuer, strings.Split(audience, ","), jwksURL),
+		roleClaim: roleClaim,
+		roles:     roles,
+	}, nil
+}
+
+func parseOIDCRoles(s string) (map[string]ct.TokenRole, error) {
+	roles := make(map[string]ct.TokenRole)
+	if s == "" {
+		return roles, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		i := strings.LastIndex(pair, ":")
+		if i == -1 {
+			return nil, fmt.Errorf("invalid OIDC role mapping %q, expected <value>:<role>", pair)
+		}
+		role := ct.TokenRole(pair[i+1:])
+		switch role {
+		case ct.TokenRoleRead, ct.TokenRoleDeploy, ct.TokenRoleAdmin:
+		default:
+			return nil, fmt.Errorf("invalid OIDC role %q", role)
+		}
+		roles[pair[:i]] = role
+	}
+	return roles, nil
+}
+
+// GetByKey implements the utils.TokenStore interface, returning ErrNotFound
+// if the key is not a valid JWT or the user has no mapped role
+func (s *oidcTokenStore) GetByKey(key string) (*ct.Token, error) {
+	if !oidc.LooksLikeToken(key) {
+		return nil, ErrNotFound
+	}
+	claims, err := s.verifier.Verify(key)
+	if err != nil {
+		return nil, err
+	}
+	role := s.role(claims.Strings(s.roleClaim))
+	if role == "" {
+		return nil, ErrNotFound
+	}
+	return &ct.Token{Name: claims.Subject(), Role: role}, nil
+}
+
+// role returns the most privileged role mapped from the given claim values
+func (s *oidcTokenStore) role(values []string) ct.TokenRole {
+	var mapped []ct.TokenRole
+	for _, v := range values {
+		if role, ok := s.roles[v]; ok {
+			mapped = append(mapped, role)
+		}
+	}
+	for _, role := range []ct.TokenRole{ct.TokenRoleAdmin, ct.TokenRoleDeploy, ct.TokenRoleRead} {
+		for _, r := range mapped {
+			if r == role {
+				return role
+			}
+		}
+	}
+	return ""
+}
+
+// bearerToken returns the token from a "Bearer" Authorization header
+func bearerToken(header string) string {
+	const prefix = "Bearer "
+	if len(header) > len(prefix) && strings.EqualFold(header[:len(prefix)], prefix) {
+		return header[len(prefix):]
+	}
+	return ""
+}
+
+// requestKey returns the key used to authenticate the request, which is
+// either the basic auth password or a bearer token
+func requestKey(r *http.Request) string {
+	if _, password, ok := r.BasicAuth(); ok {
+		return password
+	}
+	return bearerToken(r.Header.Get("Authorization"))
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	controller "github.com/flynn/flynn/controller/client"
+	ct "github.com/flynn/flynn/controller/types"
+	hh "github.com/flynn/flynn/pkg/httphelper"
+	"github.com/flynn/flynn/pkg/oidc"
+	"github.com/flynn/flynn/pkg/oidc/oidctest"
+	. "github.com/flynn/go-check"
+)
+
+func (s *S) TestOIDCAuth(c *C) {
+	issuer, err := oidctest.NewIssuer()
+	c.Assert(err, IsNil)
+	defer issuer.Close()
+
+	hc := s.hc
+	hc.oidc = &oidcTokenStore{
+		verifier:  oidc.NewVerifier(issuer.URL, []string{"flynn"}, issuer.URL+"/keys"),
+		roleClaim: "groups",
+		roles:     map[string]ct.TokenRole{"ops": ct.TokenRoleAdmin, "devs": ct.TokenRoleDeploy},
+	}
+	handler, _, _ := appHandler(hc)
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	app := s.createTestApp(c, &ct.App{Name: "oidc-auth"})
+	jwtClient := func(aud string, groups ...string) controller.Client {
+		token := issuer.Sign(aud, map[string]interface{}{"sub": "alice", "groups": groups}, time.Hour)
+		client, err := controller.NewClient(srv.URL, token)
+		c.Assert(err, IsNil)
+		return client
+	}
+
+	// the most privileged mapped role is used
+	client := jwtClient("flynn", "devs", "ops")
+	c.Assert(client.UpdateApp(&ct.App{ID: app.ID, Meta: map[string]string{"foo": "bar"}}), IsNil)
+
+	// a deploy user can create releases but not update apps
+	client = jwtClient("flynn", "devs", "other")
+	c.Assert(client.CreateRelease(app.ID, &ct.Release{}), IsNil)
+	err = client.UpdateApp(&ct.App{ID: app.ID, Meta: map[string]string{"foo": "baz"}})
+	c.Assert(hh.IsForbiddenError(err), Equals, true)
+
+	// users without a mapped role and tokens for other audiences are
+	// not authenticated
+	_, err = jwtClient("flynn", "other").GetApp(app.ID)
+	c.Assert(err, NotNil)
+	_, err = jwtClient("other", "ops").GetApp(app.ID)
+	c.Assert(err, NotNil)
+
+	// tokens are accepted as bearer tokens
+	req, err := http.NewRequest("GET", srv.URL+"/apps/"+app.ID, nil)
+	c.Assert(err, IsNil)
+	req.Header.Set("Authorization", "Bearer "+issuer.Sign("flynn", map[string]interface{}{"groups": "devs"}, time.Hour))
+	res, err := http.DefaultClient.Do(req)
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+
+	// the cluster auth key is still accepted
+	client, err = controller.NewClient(srv.URL, authKey)
+	c.Assert(err, IsNil)
+	_, err = client.GetApp(app.ID)
+	c.Assert(err, IsNil)
+}
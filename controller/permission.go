@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/flynn/flynn/controller/api"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/controller/utils"
+	"github.com/golang/protobuf/ptypes/empty"
+)
+
+// requestPermission returns the permission required to perform the given
+// HTTP request, only looking up the apps the request accesses if
+// resolveApps is set (i.e. when authenticated with an app restricted token)
+func (c *controllerAPI) requestPermission(req *http.Request, resolveApps bool) *utils.Permission {
+	read := req.Method == "GET" || req.Method == "HEAD"
+	role := func(write ct.TokenRole) ct.TokenRole {
+		if read {
+			return ct.TokenRoleRead
+		}
+		return write
+	}
+
+	p := &utils.Permission{Role: ct.TokenRoleAdmin}
+	var appRef string
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	switch parts[0] {
+	case "apps":
+		p.ResourceType = ct.TokenResourceApps
+		p.Role = role(ct.TokenRoleAdmin)
+		if len(parts) < 2 {
+			break
+		}
+		appRef = parts[1]
+		if len(parts) < 3 {
+			break
+		}
+		switch parts[2] {
+		case "releases":
+			p.ResourceType = ct.TokenResourceReleases
+		case "release":
+			p.ResourceType = ct.TokenResourceReleases
+			p.Role = role(ct.TokenRoleDeploy)
+			// reading decrypted secrets requires the deploy role
+			if req.URL.Query().Get("secrets") == "true" {
+				p.Role = ct.TokenRoleDeploy
+			}
+		case "formations", "scale":
+			p.ResourceType = ct.TokenResourceFormations
+			p.Role = role(ct.TokenRoleDeploy)
+		case "deploy", "deployments":
+			p.ResourceType = ct.TokenResourceDeployments
+			p.Role = role(ct.TokenRoleDeploy)
+		case "jobs", "log":
+			p.ResourceType = ct.TokenResourceJobs
+		case "routes":
+			p.ResourceType = ct.TokenResourceRoutes
+		case "resources":
+			p.ResourceType = ct.TokenResourceResources
+		case "volumes":
+			p.ResourceType = ct.TokenResourceVolumes
+		case "schedules":
+			p.ResourceType = ct.TokenResourceSchedules
+		case "autoscale":
+			p.ResourceType = ct.TokenResourceAutoscale
+		case "env-groups":
+			// attaching an env group creates and deploys a release
+			p.ResourceType = ct.TokenResourceEnvGroups
+			p.Role = role(ct.TokenRoleDeploy)
+		case "quota":
+			// quotas limit token holders, so are only changeable by
+			// unrestricted admin tokens
+			if !read {
+				p.ResourceType = ""
+				p.Role = ct.TokenRoleAdmin
+			}
+		case "manifest":
+			// dry runs decrypt the app's env to compare it, so
+			// require the deploy role rather than just read
+			if len(parts) > 3 && parts[3] == "dry-run" {
+				p.Role = ct.TokenRoleDeploy
+			}
+		}
+	case "artifacts":
+		p.ResourceType = ct.TokenResourceArtifacts
+		p.Role = role(ct.TokenRoleDeploy)
+		p.AppIndependent = true
+	case "releases":
+		p.ResourceType = ct.TokenResourceReleases
+		p.Role = role(ct.TokenRoleDeploy)
+		if resolveApps {
+			appRef = c.releaseAppID(req, parts)
+		}
+	case "deployments":
+		p.ResourceType = ct.TokenResourceDeployments
+		p.Role = role(ct.TokenRoleDeploy)
+		if resolveApps && len(parts) > 1 {
+			if deployment, err := c.deploymentRepo.Get(parts[1]); err == nil {
+				appRef = deployment.AppID
+			}
+		}
+		// deploy gates hold deployments made by deploy tokens, so only
+		// admin tokens can approve or reject them
+		if len(parts) > 2 && (parts[2] == "approve" || parts[2] == "reject") {
+			p.Role = ct.TokenRoleAdmin
+		}
+	case "formations":
+		p.ResourceType = ct.TokenResourceFormations
+		p.Role = role(ct.TokenRoleAdmin)
+	case "active-jobs", "jobs":
+		p.ResourceType = ct.TokenResourceJobs
+		p.Role = role(ct.TokenRoleAdmin)
+	case "providers", "resources":
+		p.ResourceType = ct.TokenResourceResources
+		p.Role = role(ct.TokenRoleAdmin)
+	case "routes":
+		p.ResourceType = ct.TokenResourceRoutes
+		p.Role = role(ct.TokenRoleAdmin)
+	case "events":
+		p.ResourceType = ct.TokenResourceEvents
+		p.Role = role(ct.TokenRoleAdmin)
+		if len(parts) == 1 {
+			appRef = req.URL.Query().Get("app_id")
+		}
+	case "volumes":
+		p.ResourceType = ct.TokenResourceVolumes
+		p.Role = role(ct.TokenRoleAdmin)
+	case "sinks":
+		p.ResourceType = ct.TokenResourceSinks
+		p.Role = role(ct.TokenRoleAdmin)
+	case "webhooks":
+		p.ResourceType = ct.TokenResourceWebhooks
+		p.Role = role(ct.TokenRoleAdmin)
+	case "env-groups":
+		p.ResourceType = ct.TokenResourceEnvGroups
+		p.Role = role(ct.TokenRoleAdmin)
+	case "audit":
+		// the audit log is only readable by unrestricted admin tokens
+		p.Role = ct.TokenRoleAdmin
+	}
+
+	if resolveApps && appRef != "" {
+		// apps may be referenced by name, but tokens are restricted by ID
+		if data, err := c.appRepo.Get(appRef); err == nil {
+			p.AppIDs = []string{data.(*ct.App).ID}
+		} else {
+			// an app which doesn't exist is not one of the token's apps
+			p.AppIDs = []string{appRef}
+		}
+	}
+	return p
+}
+
+// maxReleaseBodySize is the maximum size of a release request body which is
+// read to determine the app the release is for
+const maxReleaseBodySize = 1000000
+
+// releaseAppID returns the ID of the app a release request accesses, which
+// is in the request body when creating a release
+func (c *controllerAPI) releaseAppID(req *http.Request, parts []string) string {
+	if len(parts) > 1 {
+		data, err := c.releaseRepo.Get(parts[1])
+		if err != nil {
+			return ""
+		}
+		return data.(*ct.Release).AppID
+	}
+	if req.Method != "POST" || req.Body == nil {
+		return ""
+	}
+	// only read as much of the body as a release is expected to need,
+	// leaving the rest for the handler to read (and reject if too big)
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxReleaseBodySize))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+	if err != nil {
+		return ""
+	}
+	var release ct.Release
+	if err := json.Unmarshal(body, &release); err != nil {
+		return ""
+	}
+	return release.AppID
+}
+
+// grpcPermission returns the permission required to perform the gRPC
+// request, or nil if the request is permitted for any API token
+func grpcPermission(req interface{}) *utils.Permission {
+	switch r := req.(type) {
+	case *empty.Empty:
+		return nil
+	case *api.StreamAppsRequest:
+		return &utils.Permission{Role: ct.TokenRoleRead, ResourceType: ct.TokenResourceApps, AppIDs: grpcAppIDs(r.NameFilters...)}
+	case *api.StreamReleasesRequest:
+		return &utils.Permission{Role: ct.TokenRoleRead, ResourceType: ct.TokenResourceReleases, AppIDs: grpcAppIDs(r.NameFilters...)}
+	case *api.StreamScalesRequest:
+		return &utils.Permission{Role: ct.TokenRoleRead, ResourceType: ct.TokenResourceFormations, AppIDs: grpcAppIDs(r.NameFilters...)}
+	case *api.StreamDeploymentsRequest:
+		return &utils.Permission{Role: ct.TokenRoleRead, ResourceType: ct.TokenResourceDeployments, AppIDs: grpcAppIDs(r.NameFilters...)}
+	case *api.UpdateAppRequest:
+		var name string
+		if r.App != nil {
+			name = r.App.Name
+		}
+		return &utils.Permission{Role: ct.TokenRoleAdmin, ResourceType: ct.TokenResourceApps, AppIDs: grpcAppIDs(name)}
+	case *api.CreateScaleRequest:
+		return &utils.Permission{Role: ct.TokenRoleDeploy, ResourceType: ct.TokenResourceFormations, AppIDs: grpcAppIDs(r.Parent)}
+	case *api.CreateReleaseRequest:
+		return &utils.Permission{Role: ct.TokenRoleDeploy, ResourceType: ct.TokenResourceReleases, AppIDs: grpcAppIDs(r.Parent)}
+	case *api.CreateDeploymentRequest:
+		return &utils.Permission{Role: ct.TokenRoleDeploy, ResourceType: ct.TokenResourceDeployments, AppIDs: grpcAppIDs(r.Parent)}
+	case *api.StreamJobsRequest:
+		return &utils.Permission{Role: ct.TokenRoleRead, ResourceType: ct.TokenResourceJobs, AppIDs: grpcAppIDs(r.NameFilters...)}
+	case *api.CreateJobRequest:
+		return &utils.Permission{Role: ct.TokenRoleAdmin, ResourceType: ct.TokenResourceJobs, AppIDs: grpcAppIDs(r.Parent)}
+	case *api.DeleteJobRequest:
+		return &utils.Permission{Role: ct.TokenRoleAdmin, ResourceType: ct.TokenResourceJobs, AppIDs: grpcAppIDs(r.Name)}
+	case *api.StreamRoutesRequest:
+		return &utils.Permission{Role: ct.TokenRoleRead, ResourceType: ct.TokenResourceRoutes, AppIDs: grpcAppIDs(r.NameFilters...)}
+	case *api.CreateRouteRequest:
+		return &utils.Permission{Role: ct.TokenRoleAdmin, ResourceType: ct.TokenResourceRoutes, AppIDs: grpcAppIDs(r.Parent)}
+	case *api.UpdateRouteRequest:
+		var name string
+		if r.Route != nil {
+			name = r.Route.Name
+		}
+		return &utils.Permission{Role: ct.TokenRoleAdmin, ResourceType: ct.TokenResourceRoutes, AppIDs: grpcAppIDs(name)}
+	case *api.DeleteRouteRequest:
+		return &utils.Permission{Role: ct.TokenRoleAdmin, ResourceType: ct.TokenResourceRoutes, AppIDs: grpcAppIDs(r.Name)}
+	case *api.StreamProvidersRequest:
+		return &utils.Permission{Role: ct.TokenRoleRead, ResourceType: ct.TokenResourceResources}
+	case *api.StreamResourcesRequest:
+		return &utils.Permission{Role: ct.TokenRoleRead, ResourceType: ct.TokenResourceResources, AppIDs: grpcAppIDs(r.NameFilters...)}
+	case *api.CreateProviderRequest, *api.CreateResourceRequest, *api.DeleteResourceRequest:
+		return &utils.Permission{Role: ct.TokenRoleAdmin, ResourceType: ct.TokenResourceResources}
+	case *api.StreamVolumesRequest:
+		return &utils.Permission{Role: ct.TokenRoleRead, ResourceType: ct.TokenResourceVolumes, AppIDs: grpcAppIDs(r.NameFilters...)}
+	case *api.DecommissionVolumeRequest:
+		return &utils.Permission{Role: ct.TokenRoleAdmin, ResourceType: ct.TokenResourceVolumes, AppIDs: grpcAppIDs(r.Name)}
+	case *api.StreamSinksRequest:
+		return &utils.Permission{Role: ct.TokenRoleRead, ResourceType: ct.TokenResourceSinks}
+	case *api.CreateSinkRequest, *api.DeleteSinkRequest:
+		return &utils.Permission{Role: ct.TokenRoleAdmin, ResourceType: ct.TokenResourceSinks}
+	case *api.StreamEventsRequest:
+		return &utils.Permission{Role: ct.TokenRoleRead, ResourceType: ct.TokenResourceEvents, AppIDs: grpcAppIDs(r.NameFilters...)}
+	default:
+		return &utils.Permission{Role: ct.TokenRoleAdmin}
+	}
+}
+
+// grpcAppIDs returns the app IDs from the given resource names, with names
+// which are not in an app being returned as empty IDs so that they are not
+// permitted for app restricted tokens
+func grpcAppIDs(names ...string) []string {
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		ids = append(ids, api.ParseIDFromName(name, "apps"))
+	}
+	return ids
+}
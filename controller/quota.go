@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/pkg/httphelper"
+	"golang.org/x/net/context"
+)
+
+// Get an app's quota along with its current usage
+func (c *controllerAPI) GetAppQuota(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	app := c.getApp(ctx)
+	quota, err := c.quotaRepo.Get(app.ID)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	if quota.Usage, err = c.quotaRepo.Usage(app.ID, "", nil); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, quota)
+}
+
+// Set an app's quota, with zero limits meaning no limit
+func (c *controllerAPI) PutAppQuota(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	var quota ct.AppQuota
+	if err := httphelper.DecodeJSON(req, &quota); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	quota.AppID = c.getApp(ctx).ID
+	quota.Usage = nil
+
+	if err := c.quotaRepo.Put(&quota); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, &quota)
+}
+
+// checkDeployQuota returns a ValidationError naming the exceeded quota if
+// deploying the release in place of the app's current release would exceed
+// the app's quota
+func (c *controllerAPI) checkDeployQuota(appID, releaseID string) error {
+	prev, err := c.appRepo.GetRelease(appID)
+	if err == ErrNotFound {
+		// initial deployments don't scale any processes
+		return nil
+	} else if err != nil {
+		return err
+	}
+	formation, err := c.formationRepo.Get(appID, prev.ID)
+	if err == ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return c.quotaRepo.CheckDeploy(appID, prev.ID, releaseID, formation.Processes)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/host/resource"
+	hh "github.com/flynn/flynn/pkg/httphelper"
+	"github.com/flynn/flynn/pkg/typeconv"
+	router "github.com/flynn/flynn/router/types"
+	. "github.com/flynn/go-check"
+)
+
+func (s *S) TestAppQuota(c *C) {
+	app := s.createTestApp(c, &ct.App{Name: "quota"})
+	release := s.createTestRelease(c, app.ID, &ct.Release{
+		Processes: map[string]ct.ProcessType{
+			"web": {
+				Resources: resource.Resources{
+					resource.TypeMemory: resource.Spec{Limit: typeconv.Int64Ptr(512 * 1024 * 1024)},
+				},
+			},
+		},
+	})
+
+	// apps have no limits by default
+	quota, err := s.c.GetAppQuota(app.ID)
+	c.Assert(err, IsNil)
+	c.Assert(quota.Limits, DeepEquals, ct.QuotaResources{})
+	c.Assert(quota.Usage, NotNil)
+
+	// limits must not be negative
+	err = s.c.UpdateAppQuota(&ct.AppQuota{AppID: app.ID, Limits: ct.QuotaResources{Jobs: -1}})
+	c.Assert(hh.IsValidationError(err), Equals, true)
+
+	c.Assert(s.c.UpdateAppQuota(&ct.AppQuota{
+		AppID:  app.ID,
+		Limits: ct.QuotaResources{Jobs: 4, Memory: 1024 * 1024 * 1024, Routes: 1},
+	}), IsNil)
+
+	// scaling within the quota succeeds
+	formation := &ct.Formation{AppID: app.ID, ReleaseID: release.ID, Processes: map[string]int{"web": 2}}
+	c.Assert(s.c.PutFormation(formation), IsNil)
+	quota, err = s.c.GetAppQuota(app.ID)
+	c.Assert(err, IsNil)
+	c.Assert(quota.Usage.Jobs, Equals, int64(2))
+	c.Assert(quota.Usage.Memory, Equals, int64(1024*1024*1024))
+
+	// scaling beyond the memory quota fails
+	formation.Processes["web"] = 3
+	err = s.c.PutFormation(formation)
+	c.Assert(hh.IsValidationError(err), Equals, true)
+	c.Assert(err.(hh.JSONError).Message, Matches, "quota.memory .*")
+
+	// lowering the quota below the usage still permits scaling down
+	c.Assert(s.c.UpdateAppQuota(&ct.AppQuota{
+		AppID:  app.ID,
+		Limits: ct.QuotaResources{Jobs: 1, Routes: 1},
+	}), IsNil)
+	formation.Processes["web"] = 1
+	c.Assert(s.c.PutFormation(formation), IsNil)
+
+	// routes beyond the quota are rejected
+	c.Assert(s.c.CreateRoute(app.ID, (&router.HTTPRoute{Domain: "quota1.example.com", Service: "quota-web"}).ToRoute()), IsNil)
+	err = s.c.CreateRoute(app.ID, (&router.HTTPRoute{Domain: "quota2.example.com", Service: "quota-web"}).ToRoute())
+	c.Assert(hh.IsValidationError(err), Equals, true)
+	c.Assert(err.(hh.JSONError).Message, Matches, "quota.routes .*")
+}
+
+func (s *S) TestAppQuotaDeploy(c *C) {
+	app := s.createTestApp(c, &ct.App{Name: "quota-deploy"})
+	newRelease := func(memory int64) *ct.Release {
+		return s.createTestRelease(c, app.ID, &ct.Release{
+			Processes: map[string]ct.ProcessType{
+				"web": {
+					Resources: resource.Resources{
+						resource.TypeMemory: resource.Spec{Limit: typeconv.Int64Ptr(memory)},
+					},
+				},
+			},
+		})
+	}
+	release := newRelease(512 * 1024 * 1024)
+	formation := &ct.Formation{AppID: app.ID, ReleaseID: release.ID, Processes: map[string]int{"web": 2}}
+	c.Assert(s.c.PutFormation(formation), IsNil)
+	defer s.c.DeleteFormation(app.ID, release.ID)
+	_, err := s.c.CreateDeployment(app.ID, release.ID)
+	c.Assert(err, IsNil)
+	c.Assert(s.c.UpdateAppQuota(&ct.AppQuota{
+		AppID:  app.ID,
+		Limits: ct.QuotaResources{Memory: 1024 * 1024 * 1024},
+	}), IsNil)
+
+	// deploying a release which needs more memory than the quota fails
+	_, err = s.c.CreateDeployment(app.ID, newRelease(1024*1024*1024).ID)
+	c.Assert(hh.IsValidationError(err), Equals, true)
+	c.Assert(err.(hh.JSONError).Message, Matches, "quota.memory .*")
+
+	// deploying a release of the same size succeeds as the old and new
+	// releases are not counted together
+	d, err := s.c.CreateDeployment(app.ID, newRelease(512*1024*1024).ID)
+	c.Assert(err, IsNil)
+	defer s.c.AbortDeployment(d.ID)
+	quota, err := s.c.GetAppQuota(app.ID)
+	c.Assert(err, IsNil)
+	c.Assert(quota.Usage.Memory, Equals, int64(1024*1024*1024))
+
+	// the old release can't be scaled beyond the quota while the
+	// deployment is in progress
+	formation.Processes["web"] = 3
+	err = s.c.PutFormation(formation)
+	c.Assert(hh.IsValidationError(err), Equals, true)
+	c.Assert(err.(hh.JSONError).Message, Matches, "quota.memory .*")
+}
@@ -59,6 +59,13 @@ func (c *controllerAPI) GetAppRelease(ctx context.Context, w http.ResponseWriter
 		respondWithError(w, err)
 		return
 	}
+	// secret env vars are only decrypted if explicitly requested
+	if req.FormValue("secrets") == "true" {
+		if err := c.releaseRepo.DecryptSecrets(release); err != nil {
+			respondWithError(w, err)
+			return
+		}
+	}
 	httphelper.JSON(w, 200, release)
 }
 
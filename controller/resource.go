@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"github.com/flynn/flynn/controller/schema"
@@ -24,36 +25,44 @@ func (c *controllerAPI) ProvisionResource(ctx context.Context, w http.ResponseWr
 		return
 	}
 
+	res, err := c.provisionResource(p, rr.Config, rr.Apps)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, res)
+}
+
+// provisionResource provisions a resource from the given provider for the
+// given apps
+func (c *controllerAPI) provisionResource(p *ct.Provider, rawConfig *json.RawMessage, apps []string) (*ct.Resource, error) {
 	var config []byte
-	if rr.Config != nil {
-		config = *rr.Config
+	if rawConfig != nil {
+		config = *rawConfig
 	} else {
 		config = []byte(`{}`)
 	}
 	data, err := resource.Provision(p.URL, config)
 	if err != nil {
-		respondWithError(w, err)
-		return
+		return nil, err
 	}
 
 	res := &ct.Resource{
 		ProviderID: p.ID,
 		ExternalID: data.ID,
 		Env:        data.Env,
-		Apps:       rr.Apps,
+		Apps:       apps,
 	}
 
 	if err := schema.Validate(res); err != nil {
-		respondWithError(w, err)
-		return
+		return nil, err
 	}
 
 	if err := c.resourceRepo.Add(res); err != nil {
 		// TODO: attempt to "rollback" provisioning
-		respondWithError(w, err)
-		return
+		return nil, err
 	}
-	httphelper.JSON(w, 200, res)
+	return res, nil
 }
 
 func (c *controllerAPI) GetProviderResources(ctx context.Context, w http.ResponseWriter, req *http.Request) {
@@ -140,30 +149,37 @@ func (c *controllerAPI) DeleteResource(ctx context.Context, w http.ResponseWrite
 		return
 	}
 
+	res, err := c.deleteResource(p, id)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, res)
+}
+
+// deleteResource deprovisions the given resource from the provider and
+// removes it
+func (c *controllerAPI) deleteResource(p *ct.Provider, id string) (*ct.Resource, error) {
 	logger.Info("getting resource", "id", id)
 	res, err := c.resourceRepo.Get(id)
 	if err != nil {
 		logger.Error("getting resource error", "err", err)
-		respondWithError(w, err)
-		return
+		return nil, err
 	}
 
 	logger.Info("deprovisioning", "url", p.URL, "external.id", res.ExternalID)
 	if err := resource.Deprovision(p.URL, res.ExternalID); err != nil {
 		logger.Error("error deprovisioning", "err", err)
-		respondWithError(w, err)
-		return
+		return nil, err
 	}
 
 	logger.Info("removing resource")
 	if err := c.resourceRepo.Remove(res); err != nil {
 		logger.Error("error removing resource", "err", err)
-		respondWithError(w, err)
-		return
+		return nil, err
 	}
 	logger.Info("completed resource removal")
-
-	httphelper.JSON(w, 200, res)
+	return res, nil
 }
 
 func (c *controllerAPI) AddResourceApp(ctx context.Context, w http.ResponseWriter, req *http.Request) {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/flynn/flynn/controller/data"
 	"github.com/flynn/flynn/controller/schema"
+	ct "github.com/flynn/flynn/controller/types"
 	"github.com/flynn/flynn/pkg/ctxhelper"
 	"github.com/flynn/flynn/pkg/httphelper"
 	router "github.com/flynn/flynn/router/types"
@@ -19,48 +20,58 @@ func (c *controllerAPI) CreateRoute(ctx context.Context, w http.ResponseWriter,
 		respondWithError(w, err)
 		return
 	}
-	route.ParentRef = routeParentRef(c.getApp(ctx).ID)
+	app := c.getApp(ctx)
+	route.ParentRef = routeParentRef(app.ID)
 
 	if err := schema.Validate(&route); err != nil {
 		respondWithError(w, err)
 		return
 	}
 
-	err := c.routeRepo.Add(&route)
-	if err != nil {
-		rjson, jerr := json.Marshal(&route)
-		if jerr != nil {
-			httphelper.Error(w, jerr)
-			return
-		}
-		jsonError := httphelper.JSONError{Detail: rjson}
-		switch err {
-		case data.ErrRouteConflict:
-			jsonError.Code = httphelper.ConflictErrorCode
-			jsonError.Message = "Duplicate route"
-		case data.ErrRouteReserved:
-			jsonError.Code = httphelper.ConflictErrorCode
-			jsonError.Message = "Port reserved for HTTP/HTTPS traffic"
-		case data.ErrRouteUnreservedHTTP:
-			jsonError.Code = httphelper.ValidationErrorCode
-			jsonError.Message = "Port not reserved for HTTP traffic"
-		case data.ErrRouteUnreservedHTTPS:
-			jsonError.Code = httphelper.ValidationErrorCode
-			jsonError.Message = "Port not reserved for HTTPS traffic"
-		case data.ErrRouteInvalid:
-			jsonError.Code = httphelper.ValidationErrorCode
-			jsonError.Message = "Invalid route"
-		default:
-			httphelper.Error(w, err)
-			return
-		}
-		httphelper.Error(w, jsonError)
+	if err := c.quotaRepo.Check(app.ID, "", nil, &ct.QuotaResources{Routes: 1}); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	if err := c.routeRepo.Add(&route); err != nil {
+		httphelper.Error(w, routeAddError(&route, err))
 		return
 	}
 
 	httphelper.JSON(w, 200, &route)
 }
 
+// routeAddError converts an error from adding the given route into a
+// JSONError with a message describing the error, including the route as
+// the error detail
+func routeAddError(route *router.Route, err error) error {
+	rjson, jerr := json.Marshal(route)
+	if jerr != nil {
+		return jerr
+	}
+	jsonError := httphelper.JSONError{Detail: rjson}
+	switch err {
+	case data.ErrRouteConflict:
+		jsonError.Code = httphelper.ConflictErrorCode
+		jsonError.Message = "Duplicate route"
+	case data.ErrRouteReserved:
+		jsonError.Code = httphelper.ConflictErrorCode
+		jsonError.Message = "Port reserved for HTTP/HTTPS traffic"
+	case data.ErrRouteUnreservedHTTP:
+		jsonError.Code = httphelper.ValidationErrorCode
+		jsonError.Message = "Port not reserved for HTTP traffic"
+	case data.ErrRouteUnreservedHTTPS:
+		jsonError.Code = httphelper.ValidationErrorCode
+		jsonError.Message = "Port not reserved for HTTPS traffic"
+	case data.ErrRouteInvalid:
+		jsonError.Code = httphelper.ValidationErrorCode
+		jsonError.Message = "Invalid route"
+	default:
+		return err
+	}
+	return jsonError
+}
+
 func (c *controllerAPI) GetRoute(ctx context.Context, w http.ResponseWriter, req *http.Request) {
 	route, err := c.getRoute(ctx)
 	if err != nil {
@@ -125,19 +136,27 @@ func (c *controllerAPI) DeleteRoute(ctx context.Context, w http.ResponseWriter,
 		return
 	}
 
+	if err := c.deleteRoute(route); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	w.WriteHeader(200)
+}
+
+// deleteRoute deletes the given route, returning a validation error if
+// other routes depend on it
+func (c *controllerAPI) deleteRoute(route *router.Route) error {
 	if err := c.routeRepo.Delete(route); err != nil {
 		if err == data.ErrRouteInvalid {
-			httphelper.Error(w, httphelper.JSONError{
+			return httphelper.JSONError{
 				Code:    httphelper.ValidationErrorCode,
 				Message: "Route has dependent routes",
-			})
-			return
+			}
 		}
 		if err == data.ErrRouteNotFound {
 			err = ErrNotFound
 		}
-		respondWithError(w, err)
-		return
+		return err
 	}
-	w.WriteHeader(200)
+	return nil
 }
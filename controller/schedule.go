@@ -0,0 +1,103 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/flynn/flynn/controller/schema"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/pkg/ctxhelper"
+	"github.com/flynn/flynn/pkg/httphelper"
+	"golang.org/x/net/context"
+)
+
+// defaultScheduleRunCount is the number of runs returned when listing a
+// schedule's runs if no count is given
+const defaultScheduleRunCount = 20
+
+func (c *controllerAPI) CreateSchedule(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	var schedule ct.Schedule
+	if err := httphelper.DecodeJSON(req, &schedule); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	if err := schema.Validate(&schedule); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	app := c.getApp(ctx)
+	schedule.AppID = app.ID
+
+	if schedule.ReleaseID != "" {
+		release, err := c.releaseRepo.Get(schedule.ReleaseID)
+		if err == ErrNotFound || err == nil && release.(*ct.Release).AppID != app.ID {
+			respondWithError(w, ct.ValidationError{Field: "release", Message: "does not exist"})
+			return
+		} else if err != nil {
+			respondWithError(w, err)
+			return
+		}
+	}
+
+	if err := c.scheduleRepo.Add(&schedule); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, &schedule)
+}
+
+func (c *controllerAPI) ListSchedules(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	list, err := c.scheduleRepo.List(c.getApp(ctx).ID)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, list)
+}
+
+func (c *controllerAPI) GetSchedule(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	params, _ := ctxhelper.ParamsFromContext(ctx)
+	schedule, err := c.scheduleRepo.Get(c.getApp(ctx).ID, params.ByName("schedule_id"))
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, schedule)
+}
+
+func (c *controllerAPI) DeleteSchedule(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	params, _ := ctxhelper.ParamsFromContext(ctx)
+	schedule, err := c.scheduleRepo.Remove(c.getApp(ctx).ID, params.ByName("schedule_id"))
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, schedule)
+}
+
+func (c *controllerAPI) ListScheduleRuns(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	params, _ := ctxhelper.ParamsFromContext(ctx)
+	app := c.getApp(ctx)
+	schedule, err := c.scheduleRepo.Get(app.ID, params.ByName("schedule_id"))
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	count := defaultScheduleRunCount
+	if s := req.FormValue("count"); s != "" {
+		if count, err = strconv.Atoi(s); err != nil || count < 1 {
+			httphelper.ValidationError(w, "count", "must be a positive integer")
+			return
+		}
+	}
+
+	runs, err := c.scheduleRepo.ListRuns(app.ID, schedule.ID, count)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, runs)
+}
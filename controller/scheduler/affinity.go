@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	ct "github.com/flynn/flynn/controller/types"
+)
+
+// AffinityRules returns the job's affinity and anti-affinity rules from the
+// formation's release
+func (j *Job) AffinityRules() (affinity, antiAffinity []ct.AffinityRule) {
+	if j.Formation == nil || j.Formation.ExpandedFormation == nil || j.Formation.Release == nil {
+		return nil, nil
+	}
+	proc := j.Formation.Release.Processes[j.Type]
+	return proc.Affinity, proc.AntiAffinity
+}
+
+// GetHostJobs returns the jobs which have been placed on each host and are
+// not either blocked, stopping or stopped
+func (j Jobs) GetHostJobs() map[string][]*Job {
+	jobs := make(map[string][]*Job)
+	for _, job := range j {
+		if job.HostID == "" {
+			continue
+		}
+		switch job.State {
+		case JobStatePending, JobStateStarting, JobStateRunning:
+			jobs[job.HostID] = append(jobs[job.HostID], job)
+		}
+	}
+	return jobs
+}
+
+// affinityMatches checks whether any of the given jobs other than the job
+// itself are referenced by the job's affinity rule
+func affinityMatches(job *Job, rule ct.AffinityRule, jobs []*Job) bool {
+	appID := rule.AppID
+	if appID == "" {
+		appID = job.AppID
+	}
+	for _, j := range jobs {
+		if j != job && j.AppID == appID && (rule.Type == "" || j.Type == rule.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// affinityScore checks whether placing the given job on a host running the
+// given jobs would satisfy the job's required affinity and anti-affinity
+// rules, and returns the number of preferred rules which would not be
+// satisfied (so that hosts satisfying the most preferred rules can be
+// preferred)
+func affinityScore(job *Job, hostJobs []*Job) (bool, int) {
+	affinity, antiAffinity := job.AffinityRules()
+	score := 0
+	for _, rule := range affinity {
+		if !affinityMatches(job, rule, hostJobs) {
+			if rule.Required {
+				return false, 0
+			}
+			score++
+		}
+	}
+	for _, rule := range antiAffinity {
+		if affinityMatches(job, rule, hostJobs) {
+			if rule.Required {
+				return false, 0
+			}
+			score++
+		}
+	}
+	return true, score
+}
+
+// affinityViolated checks whether a job which has already been placed on a
+// host violates its required affinity or anti-affinity rules, only
+// considering jobs started before the job for anti-affinity rules so that
+// only the most recent of two conflicting jobs is considered in violation
+func affinityViolated(job *Job, hostJobs []*Job) bool {
+	affinity, antiAffinity := job.AffinityRules()
+	for _, rule := range affinity {
+		if rule.Required && !affinityMatches(job, rule, hostJobs) {
+			return true
+		}
+	}
+	var older []*Job
+	for _, j := range hostJobs {
+		if j.StartedAt.Before(job.StartedAt) {
+			older = append(older, j)
+		}
+	}
+	for _, rule := range antiAffinity {
+		if rule.Required && affinityMatches(job, rule, older) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatAffinityRules formats the job's required affinity and anti-affinity
+// rules as a comma separated list (e.g. "affinity=app/web, anti_affinity=db")
+func formatAffinityRules(job *Job) string {
+	affinity, antiAffinity := job.AffinityRules()
+	format := func(rule ct.AffinityRule) string {
+		if rule.AppID == "" && rule.Type == "" {
+			return job.AppID
+		} else if rule.AppID == "" {
+			return rule.Type
+		} else if rule.Type == "" {
+			return rule.AppID
+		}
+		return rule.AppID + "/" + rule.Type
+	}
+	var rules []string
+	for _, rule := range affinity {
+		if rule.Required {
+			rules = append(rules, fmt.Sprintf("affinity=%s", format(rule)))
+		}
+	}
+	for _, rule := range antiAffinity {
+		if rule.Required {
+			rules = append(rules, fmt.Sprintf("anti_affinity=%s", format(rule)))
+		}
+	}
+	return strings.Join(rules, ", ")
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"math"
+
+	ct "github.com/flynn/flynn/controller/types"
+)
+
+// ErrDisruptionBudget is returned when stopping a job would violate the
+// disruption budget of its process type
+var ErrDisruptionBudget = errors.New("stopping job would violate its disruption budget")
+
+// DisruptionBudget returns the disruption budget of the job's process type,
+// which is nil if the process type does not have one
+func (j *Job) DisruptionBudget() *ct.DisruptionBudget {
+	if j.Formation == nil || j.Formation.ExpandedFormation == nil || j.Formation.Release == nil {
+		return nil
+	}
+	return j.Formation.Release.Processes[j.Type].DisruptionBudget
+}
+
+// disruptionsAllowed returns the number of running jobs of the given job's
+// app and process type which can be voluntarily stopped without violating the
+// process type's disruption budget.
+//
+// Jobs are counted across all of the app's formations since the jobs of both
+// the old and new release are serving the app during a deployment.
+func (s *Scheduler) disruptionsAllowed(job *Job) int {
+	budget := job.DisruptionBudget()
+	if budget == nil {
+		return math.MaxInt32
+	}
+	var expected, available, stopping int
+	for _, f := range s.formations {
+		if f.App.ID == job.AppID {
+			expected += f.Processes[job.Type]
+		}
+	}
+	for _, j := range s.jobs {
+		if j.AppID != job.AppID || j.Type != job.Type {
+			continue
+		}
+		switch j.State {
+		case JobStateRunning:
+			available++
+		case JobStateStopping:
+			stopping++
+		}
+	}
+	return budget.AllowedDisruptions(expected, available, stopping)
+}
+
+// canDisrupt returns whether the given job can be voluntarily stopped without
+// violating its disruption budget (jobs which are not running can always be
+// stopped since they are not yet available)
+func (s *Scheduler) canDisrupt(job *Job) bool {
+	return job.State != JobStateRunning || s.disruptionsAllowed(job) > 0
+}
+
+// triggerDisruptedRectify triggers a rectify of all the formations of the
+// given job's app if its process type has a disruption budget, since their
+// jobs may have been waiting for the job to either start or stop before they
+// could be stopped
+func (s *Scheduler) triggerDisruptedRectify(job *Job) {
+	if job.DisruptionBudget() == nil {
+		return
+	}
+	for key, f := range s.formations {
+		if f.App.ID == job.AppID {
+			s.triggerRectify(key)
+		}
+	}
+}
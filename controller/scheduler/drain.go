@@ -0,0 +1,352 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	host "github.com/flynn/flynn/host/types"
+	"github.com/flynn/flynn/pkg/httphelper"
+)
+
+var (
+	ErrUnknownHost      = errors.New("unknown host")
+	ErrHostNotCordoned  = errors.New("host must be cordoned before it can be drained")
+	ErrUnknownHostDrain = errors.New("host has not been drained")
+)
+
+// drain tracks the progress of moving jobs off a cordoned host
+type drain struct {
+	status *host.DrainStatus
+
+	// current is the job currently being moved off the host
+	current *Job
+
+	// reported tracks the jobs which have been reported as either blocked
+	// or skipped so that they are only reported once
+	reported map[string]struct{}
+}
+
+func (d *drain) event(typ host.DrainEventType, job *Job, message string) {
+	e := &host.DrainEvent{
+		Type:      typ,
+		Message:   message,
+		CreatedAt: time.Now(),
+	}
+	if job != nil {
+		e.JobID = job.JobID
+		e.AppID = job.AppID
+		e.JobType = job.Type
+	}
+	d.status.Events = append(d.status.Events, e)
+}
+
+func (d *drain) reportOnce(typ host.DrainEventType, job *Job, message string) {
+	key := string(typ) + ":" + job.JobID
+	if _, ok := d.reported[key]; ok {
+		return
+	}
+	d.reported[key] = struct{}{}
+	d.event(typ, job, message)
+}
+
+func (d *drain) finish(state host.DrainState, typ host.DrainEventType, message string) {
+	d.status.State = state
+	d.event(typ, nil, message)
+}
+
+func NewDrainRequest(hostID string, start bool) *DrainRequest {
+	return &DrainRequest{HostID: hostID, Start: start, Done: make(chan struct{})}
+}
+
+// DrainRequest is a request to either start draining a host or get the status
+// of its most recent drain
+type DrainRequest struct {
+	HostID string
+	Start  bool
+	Status *host.DrainStatus
+	Err    error
+	Done   chan struct{}
+}
+
+func (s *Scheduler) HandleDrainRequest(req *DrainRequest) {
+	log := s.logger.New("fn", "HandleDrainRequest", "host.id", req.HostID, "start", req.Start)
+	log.Info("handling drain request")
+
+	defer close(req.Done)
+
+	if !s.IsLeader() {
+		req.Err = ErrNotLeader
+		return
+	}
+
+	d, ok := s.drains[req.HostID]
+	if req.Start && (!ok || d.status.State != host.DrainStateDraining) {
+		h, ok := s.hosts[req.HostID]
+		if !ok {
+			req.Err = ErrUnknownHost
+			return
+		}
+		if !h.Cordoned {
+			req.Err = ErrHostNotCordoned
+			return
+		}
+		log.Info("starting host drain")
+		d = &drain{
+			status: &host.DrainStatus{
+				HostID: h.ID,
+				State:  host.DrainStateDraining,
+			},
+			reported: make(map[string]struct{}),
+		}
+		d.event(host.DrainEventStarted, nil, "")
+		s.drains[h.ID] = d
+		s.drainHost(d)
+		s.persistDrain(d)
+	} else if !ok {
+		req.Err = ErrUnknownHostDrain
+		return
+	}
+
+	// return a copy of the status so it can be safely encoded outside of
+	// the scheduler loop
+	status := *d.status
+	status.Events = make([]*host.DrainEvent, len(d.status.Events))
+	copy(status.Events, d.status.Events)
+	req.Status = &status
+}
+
+func (s *Scheduler) Drain(hostID string, start bool) (*host.DrainStatus, error) {
+	req := NewDrainRequest(hostID, start)
+	s.drainRequests <- req
+	<-req.Done
+	return req.Status, req.Err
+}
+
+// serveDrain either starts draining the host in the request path (for POST
+// requests) or returns the status of its most recent drain (for GET requests)
+func (s *Scheduler) serveDrain(w http.ResponseWriter, r *http.Request) {
+	var start bool
+	switch r.Method {
+	case "GET":
+	case "POST":
+		start = true
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	hostID := strings.TrimPrefix(r.URL.Path, "/drain/")
+	status, err := s.Drain(hostID, start)
+	switch err {
+	case nil:
+		httphelper.JSON(w, 200, status)
+	case ErrUnknownHost, ErrUnknownHostDrain:
+		httphelper.ObjectNotFoundError(w, err.Error())
+	case ErrHostNotCordoned:
+		httphelper.ValidationError(w, "host", err.Error())
+	default:
+		httphelper.Error(w, err)
+	}
+}
+
+func (s *Scheduler) triggerDrainHosts() {
+	if len(s.drains) == 0 {
+		return
+	}
+	select {
+	case s.drainHosts <- struct{}{}:
+	default:
+	}
+}
+
+// DrainHosts progresses all in-progress host drains
+func (s *Scheduler) DrainHosts() {
+	if !s.IsLeader() {
+		return
+	}
+	for _, d := range s.drains {
+		if d.status.State != host.DrainStateDraining {
+			continue
+		}
+		n := len(d.status.Events)
+		s.drainHost(d)
+		if len(d.status.Events) != n {
+			s.persistDrain(d)
+		}
+	}
+}
+
+// persistDrain triggers the ControllerPersistLoop goroutine to persist a copy
+// of the drain's status to the controller so that the drain can be continued
+// by the next leader (see SyncDrains)
+func (s *Scheduler) persistDrain(d *drain) {
+	if s.isLeader == nil || *s.isLeader {
+		status := *d.status
+		status.Events = make([]*host.DrainEvent, len(d.status.Events))
+		copy(status.Events, d.status.Events)
+		s.controllerPersist <- &status
+	}
+}
+
+// SyncDrains restores host drains persisted by a previous leader, continuing
+// any which were in progress once jobs have been synced
+func (s *Scheduler) SyncDrains() {
+	log := s.logger.New("fn", "SyncDrains")
+
+	log.Info("getting host drains")
+	list, err := s.HostDrainList()
+	if err != nil {
+		log.Error("error getting host drains", "err", err)
+		return
+	}
+
+	jobs := make(map[string]*Job, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs[job.JobID] = job
+	}
+	for _, status := range list {
+		d := &drain{
+			status:   status,
+			reported: make(map[string]struct{}),
+		}
+		// replay the drain's events to determine which job was
+		// being moved and which jobs have already been reported
+		for _, e := range status.Events {
+			switch e.Type {
+			case host.DrainEventJobMoving:
+				d.current = jobs[e.JobID]
+			case host.DrainEventJobStopped:
+				d.current = nil
+			case host.DrainEventJobBlocked, host.DrainEventJobWaiting, host.DrainEventJobSkipped:
+				d.reported[string(e.Type)+":"+e.JobID] = struct{}{}
+			}
+		}
+		if d.current != nil && status.State == host.DrainStateDraining {
+			log.Info("continuing host drain", "host.id", status.HostID, "job.id", d.current.JobID)
+			d.current.Evacuating = true
+		} else {
+			d.current = nil
+		}
+		s.drains[status.HostID] = d
+	}
+	s.triggerDrainHosts()
+}
+
+// drainHost moves jobs off a cordoned host one at a time by marking a job as
+// evacuating, which causes the formation to start a replacement on another
+// host, and then stopping the job once its formation has the expected number
+// of running jobs (which gives routers a chance to drain the job, see
+// stopJob), only moving on to the next job once the job has stopped
+func (s *Scheduler) drainHost(d *drain) {
+	log := s.logger.New("fn", "drainHost", "host.id", d.status.HostID)
+
+	h, ok := s.hosts[d.status.HostID]
+	if !ok {
+		log.Info("cancelling drain of host which left the cluster")
+		s.cancelEvacuation(d)
+		d.finish(host.DrainStateCancelled, host.DrainEventCancelled, "host left the cluster")
+		return
+	}
+	if !h.Cordoned {
+		log.Info("cancelling drain of uncordoned host")
+		s.cancelEvacuation(d)
+		d.finish(host.DrainStateCancelled, host.DrainEventCancelled, "host was uncordoned")
+		return
+	}
+
+	if job := d.current; job != nil {
+		switch job.State {
+		case JobStateStopped:
+			log.Info("evacuated job has stopped", "job.id", job.JobID)
+			d.event(host.DrainEventJobStopped, job, "")
+			d.current = nil
+		case JobStateStopping:
+			return
+		default:
+			if !s.replacementRunning(job) {
+				for _, j := range s.jobs.WithFormationAndType(job.Formation, job.Type) {
+					if j.State == JobStateBlocked {
+						d.reportOnce(host.DrainEventJobBlocked, job, fmt.Sprintf("replacement job is blocked: %s", j.BlockedReason))
+						break
+					}
+				}
+				return
+			}
+			if !s.canDisrupt(job) {
+				d.reportOnce(host.DrainEventJobWaiting, job, "waiting for the disruption budget to allow stopping the job")
+				return
+			}
+			log.Info("replacement job is running, stopping evacuated job", "job.id", job.JobID)
+			d.event(host.DrainEventJobStopping, job, "replacement job is running")
+			if err := s.stopJob(job); err != nil {
+				log.Error("error stopping evacuated job", "job.id", job.JobID, "err", err)
+			}
+			return
+		}
+	}
+
+	// find the next job to move, starting with the oldest
+	var jobs sortJobs
+	for _, job := range s.jobs {
+		if job.HostID == h.ID && job.IsRunning() {
+			jobs = append(jobs, job)
+		}
+	}
+	jobs.SortReverse()
+	for _, job := range jobs {
+		if reason := evacuationSkipReason(job); reason != "" {
+			d.reportOnce(host.DrainEventJobSkipped, job, reason)
+			continue
+		}
+		log.Info("moving job off host", "job.id", job.JobID, "job.type", job.Type)
+		job.Evacuating = true
+		d.current = job
+		d.event(host.DrainEventJobMoving, job, "starting replacement job on another host")
+		s.triggerRectify(job.Formation.key())
+		return
+	}
+
+	log.Info("host drain complete")
+	d.finish(host.DrainStateComplete, host.DrainEventComplete, "")
+}
+
+// evacuationSkipReason returns the reason a job on a host being drained should
+// not be moved, or an empty string if it should
+func evacuationSkipReason(job *Job) string {
+	switch {
+	case job.Formation == nil:
+		return "job is not part of a formation"
+	case job.Formation.Release.Processes[job.Type].Omni:
+		return "omni jobs run on every host"
+	case len(job.Volumes) > 0:
+		return "job has volumes on the host"
+	default:
+		return ""
+	}
+}
+
+// replacementRunning checks whether the evacuating job's formation has the
+// expected number of running jobs without it
+func (s *Scheduler) replacementRunning(job *Job) bool {
+	running := 0
+	for _, j := range s.jobs.WithFormationAndType(job.Formation, job.Type) {
+		if j.State == JobStateRunning && !j.Evacuating {
+			running++
+		}
+	}
+	return running >= job.Formation.Processes[job.Type]
+}
+
+// cancelEvacuation stops evacuating the drain's current job, causing any
+// surplus replacement job to be stopped by the next rectify
+func (s *Scheduler) cancelEvacuation(d *drain) {
+	if job := d.current; job != nil {
+		job.Evacuating = false
+		d.current = nil
+		if job.Formation != nil {
+			s.triggerRectify(job.Formation.key())
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/controller/utils"
+	"github.com/flynn/flynn/pkg/httphelper"
+)
+
+func NewDryRunRequest(change *ct.SchedulerDryRunRequest) *DryRunRequest {
+	return &DryRunRequest{Change: change, Done: make(chan struct{})}
+}
+
+type DryRunRequest struct {
+	Change *ct.SchedulerDryRunRequest
+	Result *ct.SchedulerDryRunResult
+	Err    error
+	Done   chan struct{}
+}
+
+// HandleDryRunRequest computes the decisions the scheduler would make if the
+// requested change was made to the cluster, simulating them against a copy of
+// the current state so that no jobs are actually started or stopped
+func (s *Scheduler) HandleDryRunRequest(req *DryRunRequest) {
+	log := s.logger.New("fn", "HandleDryRunRequest")
+	log.Info("handling dry run request")
+
+	defer close(req.Done)
+	sim, err := s.newDryRun(req.Change)
+	if err != nil {
+		log.Error("error handling dry run request", "err", err)
+		req.Err = err
+		return
+	}
+	sim.run()
+	req.Result = sim.result
+}
+
+func (s *Scheduler) DryRun(change *ct.SchedulerDryRunRequest) (*ct.SchedulerDryRunResult, error) {
+	req := NewDryRunRequest(change)
+	s.dryRunRequests <- req
+	<-req.Done
+	return req.Result, req.Err
+}
+
+func (s *Scheduler) serveDryRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var change ct.SchedulerDryRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+		httphelper.Error(w, err)
+		return
+	}
+	result, err := s.DryRun(&change)
+	if e, ok := err.(ct.ValidationError); ok {
+		httphelper.ValidationError(w, e.Field, e.Message)
+		return
+	} else if err != nil {
+		httphelper.Error(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, result)
+}
+
+// dryRun simulates the scheduler rectifying formations against copies of
+// the scheduler's hosts, jobs and formations
+type dryRun struct {
+	*Scheduler
+
+	result *ct.SchedulerDryRunResult
+
+	// pending is the list of formations which need rectifying
+	pending []utils.FormationKey
+}
+
+func (s *Scheduler) newDryRun(change *ct.SchedulerDryRunRequest) (*dryRun, error) {
+	sim := &dryRun{
+		Scheduler: &Scheduler{
+			isLeader:        s.isLeader,
+			logger:          s.logger,
+			hosts:           make(map[string]*Host, len(s.hosts)),
+			jobs:            make(Jobs, len(s.jobs)),
+			formations:      make(Formations, len(s.formations)),
+			generateJobUUID: s.generateJobUUID,
+		},
+		result: &ct.SchedulerDryRunResult{
+			Placements: []*ct.SchedulerDryRunJob{},
+			Blocked:    []*ct.SchedulerDryRunJob{},
+			Stopped:    []*ct.SchedulerDryRunJob{},
+		},
+	}
+
+	// hosts are not modified by the simulation, so only the map is copied
+	for id, host := range s.hosts {
+		sim.hosts[id] = host
+	}
+	for key, formation := range s.formations {
+		sim.formations[key] = formation
+	}
+	for id, job := range s.jobs {
+		j := *job
+		sim.jobs[id] = &j
+	}
+
+	// replace or add the requested formations, pointing existing jobs at
+	// the new formations
+	for _, ef := range change.Formations {
+		if ef.App == nil || ef.Release == nil {
+			return nil, ct.ValidationError{Field: "formations", Message: "must include app and release"}
+		}
+		formation := NewFormation(ef)
+		formation.RectifyOmni(sim.activeHostCount())
+		key := formation.key()
+		if existing, ok := sim.formations[key]; ok {
+			// do not completely scale down critical apps for which
+			// this is the only active formation (see handleFormation)
+			diff := Processes(ef.Processes).Diff(existing.OriginalProcesses)
+			if diff.IsScaleDownOf(existing.OriginalProcesses) && existing.App.Critical() && s.activeFormationCount(existing.App.ID) < 2 {
+				continue
+			}
+		}
+		sim.formations[key] = formation
+		for _, job := range sim.jobs {
+			if job.Formation != nil && job.Formation.key() == key {
+				job.Formation = formation
+			}
+		}
+		sim.schedule(key)
+	}
+
+	// remove the requested hosts, stopping any jobs running on them
+	for _, id := range change.RemoveHosts {
+		if _, ok := sim.hosts[id]; !ok {
+			return nil, ct.ValidationError{Field: "remove_hosts", Message: fmt.Sprintf("unknown host %q", id)}
+		}
+		delete(sim.hosts, id)
+		for _, job := range sim.jobs {
+			if job.HostID == id && job.State != JobStateStopped && job.State != JobStateBlocked {
+				sim.stop(job, "host removed")
+				if job.Formation != nil {
+					sim.schedule(job.Formation.key())
+				}
+			}
+		}
+	}
+
+	// update omni counts for the new host count, copying formations
+	// before modifying them
+	if len(change.RemoveHosts) > 0 {
+		for key, formation := range sim.formations {
+			ef := *formation.ExpandedFormation
+			f := &Formation{
+				ExpandedFormation: &ef,
+				OriginalProcesses: formation.OriginalProcesses,
+				CrashLooping:      formation.CrashLooping,
+				Exited:            formation.Exited,
+			}
+			f.Processes = make(map[string]int, len(formation.Processes))
+			for typ, n := range formation.Processes {
+				f.Processes[typ] = n
+			}
+			if !f.RectifyOmni(sim.activeHostCount()) {
+				continue
+			}
+			sim.formations[key] = f
+			for _, job := range sim.jobs {
+				if job.Formation == formation {
+					job.Formation = f
+				}
+			}
+			sim.schedule(key)
+		}
+	}
+
+	return sim, nil
+}
+
+// schedule adds the given formation to the list of formations to rectify
+func (d *dryRun) schedule(key utils.FormationKey) {
+	for _, k := range d.pending {
+		if k == key {
+			return
+		}
+	}
+	d.pending = append(d.pending, key)
+}
+
+// run rectifies pending formations until there are none left (formations
+// with jobs preempted by others are rectified again so that the replacement
+// jobs are included)
+func (d *dryRun) run() {
+	for len(d.pending) > 0 {
+		key := d.pending[0]
+		d.pending = d.pending[1:]
+		if formation, ok := d.formations[key]; ok {
+			d.rectify(formation)
+		}
+	}
+}
+
+// rectify simulates RectifyFormation for the given formation
+func (d *dryRun) rectify(formation *Formation) {
+	key := formation.key()
+	hostJobs := d.jobs.GetHostJobs()
+	for _, job := range d.sortedJobs() {
+		if !job.IsInFormation(key) || !job.IsRunning() {
+			continue
+		}
+		if host, ok := d.hosts[job.HostID]; ok && !job.TagsMatchHost(host) {
+			d.stop(job, "job tags do not match host")
+		} else if affinityViolated(job, hostJobs[job.HostID]) {
+			d.stop(job, fmt.Sprintf("job violates affinity rules (required %s)", formatAffinityRules(job)))
+		}
+	}
+
+	diff := formation.Diff(d.jobs.GetProcesses(key))
+	types := make([]string, 0, len(diff))
+	for typ := range diff {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	for _, typ := range types {
+		n := diff[typ]
+		for i := 0; i < n; i++ {
+			d.start(formation, typ)
+		}
+		for i := 0; i < -n; i++ {
+			job, err := d.findJobToStop(formation, typ)
+			if err != nil {
+				break
+			}
+			d.stop(job, "formation scaled down")
+		}
+	}
+}
+
+// start simulates starting a new job of the given type, recording either
+// the host it would be placed on or the reason it would be blocked
+func (d *dryRun) start(formation *Formation, typ string) {
+	job := &Job{
+		ID:        d.generateJobUUID(),
+		Type:      typ,
+		AppID:     formation.App.ID,
+		ReleaseID: formation.Release.ID,
+		Formation: formation,
+		StartedAt: time.Now(),
+		State:     JobStatePending,
+	}
+	d.jobs.Add(job)
+
+	if len(d.hosts) == 0 {
+		d.block(job, ErrNoHosts)
+		return
+	}
+	host, preempt, err := d.selectHost(job)
+	if err != nil {
+		d.block(job, err)
+		return
+	}
+	for _, j := range preempt {
+		j.PreemptedBy = job.ID
+		d.stop(j, fmt.Sprintf("preempted by higher priority %s job of app %s", job.Type, job.AppID))
+		if j.Formation != nil {
+			d.schedule(j.Formation.key())
+		}
+	}
+	job.HostID = host.ID
+	d.result.Placements = append(d.result.Placements, dryRunJob(job, ""))
+}
+
+func (d *dryRun) block(job *Job, err error) {
+	job.State = JobStateBlocked
+	job.BlockedReason = blockedReason(job, err)
+	d.result.Blocked = append(d.result.Blocked, dryRunJob(job, job.BlockedReason))
+}
+
+func (d *dryRun) stop(job *Job, reason string) {
+	job.State = JobStateStopped
+	d.result.Stopped = append(d.result.Stopped, dryRunJob(job, reason))
+}
+
+// sortedJobs returns the simulated jobs sorted by ID so that the result of a
+// dry run does not depend on map iteration order
+func (d *dryRun) sortedJobs() []*Job {
+	jobs := make([]*Job, 0, len(d.jobs))
+	for _, job := range d.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+	return jobs
+}
+
+func dryRunJob(job *Job, reason string) *ct.SchedulerDryRunJob {
+	j := &ct.SchedulerDryRunJob{
+		AppID:     job.AppID,
+		ReleaseID: job.ReleaseID,
+		Type:      job.Type,
+		HostID:    job.HostID,
+		Reason:    reason,
+	}
+	// only existing jobs have a cluster job ID
+	if job.JobID != "" {
+		j.ID = job.JobID
+	}
+	return j
+}
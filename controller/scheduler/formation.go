@@ -1,6 +1,8 @@
 package main
 
 import (
+	"time"
+
 	ct "github.com/flynn/flynn/controller/types"
 	"github.com/flynn/flynn/controller/utils"
 )
@@ -72,6 +74,20 @@ type Formation struct {
 	// without any changes for omni jobs so we can recalculate omni counts
 	// when host counts change
 	OriginalProcesses Processes `json:"original_processes"`
+
+	// CrashLooping maps process types which have exhausted the restart
+	// budget of their restart policy to the reason their jobs are no
+	// longer being restarted
+	CrashLooping map[string]string `json:"crash_looping,omitempty"`
+
+	// Exited is the number of jobs of each process type which have
+	// stopped and were not restarted due to their restart policy
+	Exited Processes `json:"exited,omitempty"`
+
+	// restarts are the times jobs of each process type have been
+	// restarted, which are counted against the restart budget of the
+	// process type's restart policy
+	restarts map[string][]time.Time
 }
 
 func NewFormation(ef *ct.ExpandedFormation) *Formation {
@@ -122,5 +138,20 @@ func (f *Formation) key() utils.FormationKey {
 // Diff returns the diff between the given running processes and what is
 // expected to be running for the formation
 func (f *Formation) Diff(running Processes) Processes {
-	return Processes(f.Processes).Diff(running)
+	diff := Processes(f.Processes).Diff(running)
+
+	// don't start jobs to replace those which are not being restarted
+	// due to their restart policy
+	for typ, n := range diff {
+		if n <= 0 {
+			continue
+		}
+		if _, ok := f.CrashLooping[typ]; ok {
+			n = 0
+		} else if n -= f.Exited[typ]; n < 0 {
+			n = 0
+		}
+		diff[typ] = n
+	}
+	return diff
 }
@@ -2,12 +2,14 @@ package main
 
 import (
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
 	"github.com/flynn/flynn/controller/testutils"
 	ct "github.com/flynn/flynn/controller/types"
 	"github.com/flynn/flynn/controller/utils"
+	"github.com/flynn/flynn/host/resource"
 	"github.com/flynn/flynn/host/types"
 	"github.com/flynn/flynn/host/volume"
 	"github.com/flynn/flynn/pkg/stream"
@@ -21,6 +23,14 @@ type Host struct {
 	Checks   int               `json:"checks"`
 	Shutdown bool              `json:"shutdown"`
 
+	// Cordoned is whether the host has been cordoned, in which case new
+	// jobs are not placed on it
+	Cordoned bool `json:"cordoned"`
+
+	// Capacity is the amount of each resource type the host can allocate
+	// to jobs, with missing types being treated as unlimited
+	Capacity map[resource.Type]int64 `json:"capacity,omitempty"`
+
 	client   utils.HostClient
 	stop     chan struct{}
 	stopOnce sync.Once
@@ -33,6 +43,8 @@ func NewHost(h utils.HostClient, l log15.Logger) *Host {
 	return &Host{
 		ID:       h.ID(),
 		Tags:     h.Tags(),
+		Capacity: h.Capacity(),
+		Cordoned: h.Cordoned(),
 		Healthy:  true,
 		client:   h,
 		stop:     make(chan struct{}),
@@ -54,6 +66,48 @@ func (h *Host) TagsEqual(tags map[string]string) bool {
 	return true
 }
 
+func (h *Host) CapacityEqual(capacity map[resource.Type]int64) bool {
+	if len(h.Capacity) != len(capacity) {
+		return false
+	}
+	for typ, n := range h.Capacity {
+		if m, ok := capacity[typ]; !ok || m != n {
+			return false
+		}
+	}
+	return true
+}
+
+// HasRoomFor checks whether the host has enough unallocated capacity for the
+// given resource requests, taking into account the resources already
+// committed to jobs on the host
+func (h *Host) HasRoomFor(committed, requests map[resource.Type]int64) bool {
+	for typ, capacity := range h.Capacity {
+		if committed[typ]+requests[typ] > capacity {
+			return false
+		}
+	}
+	return true
+}
+
+// FitScore returns the proportion of the host's capacity which would remain
+// unallocated if the given resource requests were placed on the host, with
+// lower scores indicating a better fit (hosts which do not report any
+// capacity are considered the worst fit)
+func (h *Host) FitScore(committed, requests map[resource.Type]int64) float64 {
+	if len(h.Capacity) == 0 {
+		return math.MaxFloat64
+	}
+	var score float64
+	for typ, capacity := range h.Capacity {
+		if capacity <= 0 {
+			continue
+		}
+		score += float64(capacity-committed[typ]-requests[typ]) / float64(capacity)
+	}
+	return score / float64(len(h.Capacity))
+}
+
 func isAppVolume(info *volume.Info) bool {
 	_, ok := info.Meta["flynn-controller.app"]
 	return ok
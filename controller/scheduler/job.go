@@ -6,6 +6,7 @@ import (
 
 	ct "github.com/flynn/flynn/controller/types"
 	"github.com/flynn/flynn/controller/utils"
+	"github.com/flynn/flynn/host/resource"
 	"github.com/flynn/flynn/pkg/typeconv"
 )
 
@@ -37,8 +38,9 @@ const (
 	JobStatePending JobState = "pending"
 
 	// JobStateBlocked is a job's state when it cannot be scheduled due to
-	// either having tags which don't match any hosts or having volumes on
-	// hosts which are currently down
+	// either having tags which don't match any hosts, having volumes on
+	// hosts which are currently down or having resource requests which
+	// don't fit on any hosts
 	JobStateBlocked JobState = "blocked"
 )
 
@@ -97,6 +99,23 @@ type Job struct {
 	// referenced from within the main scheduler loop
 	State JobState `json:"state"`
 
+	// BlockedReason is the reason the job could not be placed in the
+	// cluster when it is in the JobStateBlocked state
+	BlockedReason string `json:"blocked_reason,omitempty"`
+
+	// PreemptedBy is the ID of the higher priority job this job was
+	// stopped to make room for
+	PreemptedBy string `json:"preempted_by,omitempty"`
+
+	// Evacuating is whether the job is being moved off a cordoned host
+	// which is being drained, in which case it is not counted as part of
+	// its formation so that a replacement is started on another host
+	Evacuating bool `json:"evacuating,omitempty"`
+
+	// CrashLoopReason is set when the job has stopped and was not
+	// restarted because its process type exhausted its restart budget
+	CrashLoopReason string `json:"crash_loop_reason,omitempty"`
+
 	// metadata is the cluster job's metadata, assigned whenever a host
 	// event is received for the job, and is used when persisting the job
 	// to the controller
@@ -108,6 +127,16 @@ type Job struct {
 	// hostError is the error from the host if the job fails to start
 	hostError *string
 
+	// resources is the cluster job's resources, assigned whenever a host
+	// event is received for the job, and is used to determine the amount
+	// of resources committed to the job's host
+	resources resource.Resources
+
+	// partition is the cluster job's partition, assigned whenever a host
+	// event is received for the job, and is used to determine the job's
+	// priority
+	partition string
+
 	serviceFirstSeen *time.Time
 }
 
@@ -130,6 +159,23 @@ func (j *Job) TagsMatchHost(host *Host) bool {
 	return true
 }
 
+// ResourceRequests returns the amount of each resource type requested by the
+// job, either from the cluster job if it has been started or from the
+// formation's release otherwise
+func (j *Job) ResourceRequests() map[resource.Type]int64 {
+	resources := j.resources
+	if resources == nil && j.Formation != nil {
+		resources = j.Formation.Release.Processes[j.Type].Resources
+	}
+	requests := make(map[resource.Type]int64, len(resources))
+	for typ, spec := range resources {
+		if spec.Request != nil {
+			requests[typ] = *spec.Request
+		}
+	}
+	return requests
+}
+
 func (j *Job) VolumeRequests() []ct.VolumeReq {
 	proc := j.Formation.Release.Processes[j.Type]
 	if len(proc.Volumes) > 0 {
@@ -144,7 +190,7 @@ func (j *Job) Service() string {
 	if j.Formation == nil {
 		return ""
 	}
-	return j.Formation.Release.Processes[j.Type].Service
+	return j.Formation.ServiceName(j.Formation.Release.Processes[j.Type].Service)
 }
 
 func (j *Job) IsRunning() bool {
@@ -173,6 +219,16 @@ func (j *Job) ControllerJob() *ct.Job {
 		Args:      j.Args,
 	}
 
+	if j.State == JobStateBlocked && j.BlockedReason != "" {
+		job.BlockedReason = &j.BlockedReason
+	}
+	if j.PreemptedBy != "" {
+		job.PreemptedBy = &j.PreemptedBy
+	}
+	if j.CrashLoopReason != "" {
+		job.CrashLoopReason = &j.CrashLoopReason
+	}
+
 	switch j.State {
 	case JobStatePending:
 		job.State = ct.JobStatePending
@@ -238,10 +294,31 @@ func (j Jobs) GetHostJobCounts(key utils.FormationKey, typ string) map[string]in
 	return counts
 }
 
+// GetHostResourceRequests returns the total amount of each resource type
+// requested by jobs which have been placed on each host and have not yet
+// stopped
+func (j Jobs) GetHostResourceRequests() map[string]map[resource.Type]int64 {
+	committed := make(map[string]map[resource.Type]int64)
+	for _, job := range j {
+		if job.HostID == "" || job.State == JobStateStopped || job.State == JobStateBlocked {
+			continue
+		}
+		requests, ok := committed[job.HostID]
+		if !ok {
+			requests = make(map[resource.Type]int64)
+			committed[job.HostID] = requests
+		}
+		for typ, n := range job.ResourceRequests() {
+			requests[typ] += n
+		}
+	}
+	return committed
+}
+
 func (js Jobs) GetProcesses(key utils.FormationKey) Processes {
 	procs := make(Processes)
 	for _, j := range js {
-		if j.IsInFormation(key) {
+		if j.IsInFormation(key) && !j.Evacuating {
 			procs[j.Type]++
 		}
 	}
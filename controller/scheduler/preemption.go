@@ -0,0 +1,122 @@
+package main
+
+import (
+	"sort"
+
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/host/resource"
+)
+
+// Partition returns the partition the job runs in, either from the cluster
+// job if it has been started or from the formation otherwise
+func (j *Job) Partition() ct.PartitionType {
+	if j.partition != "" {
+		return ct.PartitionType(j.partition)
+	}
+	if j.Formation != nil && j.Formation.ExpandedFormation != nil {
+		if j.Formation.App != nil && j.Formation.App.Meta["flynn-system-app"] == "true" {
+			return ct.PartitionTypeSystem
+		}
+		if j.Formation.Release != nil {
+			if partition := j.Formation.Release.Processes[j.Type].Partition; partition != "" {
+				return partition
+			}
+		}
+	}
+	return ct.PartitionTypeUser
+}
+
+// Priority returns the job's scheduling priority based on its partition, with
+// jobs only being preempted to make room for jobs with a higher priority
+func (j *Job) Priority() int {
+	switch j.Partition() {
+	case ct.PartitionTypeBackground:
+		return 0
+	case ct.PartitionTypeSystem:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// findJobsToPreempt finds the host from the given hosts which requires the
+// least amount of lower priority jobs to be stopped to make room for the
+// given job's resource requests, preferring to stop the lowest priority and
+// then most recently started jobs on each host.
+//
+// Running jobs are only preempted if doing so would not violate the
+// disruption budget of their process type, taking into account the other
+// jobs of the same process type being preempted on the host.
+func (s *Scheduler) findJobsToPreempt(job *Job, hosts []*Host) (*Host, []*Job) {
+	priority := job.Priority()
+	requests := job.ResourceRequests()
+	hostJobs := s.jobs.GetHostJobs()
+	type processKey struct{ appID, typ string }
+	allowed := make(map[processKey]int)
+	var found *Host
+	var preempt []*Job
+	for _, host := range hosts {
+		// determine the resources committed to the host, ignoring
+		// stopping jobs which may have already been preempted
+		committed := make(map[resource.Type]int64)
+		var candidates sortJobs
+		for _, j := range hostJobs[host.ID] {
+			for typ, n := range j.ResourceRequests() {
+				committed[typ] += n
+			}
+			if j.Priority() < priority {
+				candidates = append(candidates, j)
+			}
+		}
+		candidates.Sort()
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].Priority() < candidates[j].Priority()
+		})
+
+		var jobs []*Job
+		disrupted := make(map[processKey]int)
+		for _, j := range candidates {
+			if host.HasRoomFor(committed, requests) {
+				break
+			}
+			if j.State == JobStateRunning {
+				key := processKey{j.AppID, j.Type}
+				n, ok := allowed[key]
+				if !ok {
+					n = s.disruptionsAllowed(j)
+					allowed[key] = n
+				}
+				if disrupted[key] >= n {
+					continue
+				}
+				disrupted[key]++
+			}
+			for typ, n := range j.ResourceRequests() {
+				committed[typ] -= n
+			}
+			jobs = append(jobs, j)
+		}
+		if !host.HasRoomFor(committed, requests) {
+			continue
+		}
+		if found == nil || len(jobs) < len(preempt) {
+			found = host
+			preempt = jobs
+		}
+	}
+	return found, preempt
+}
+
+// preemptJobs stops the given jobs to make room for the given higher
+// priority job, recording the preemption on the stopped jobs so that it
+// appears in their job events
+func (s *Scheduler) preemptJobs(job *Job, jobs []*Job) {
+	log := s.logger.New("fn", "preemptJobs", "job.id", job.ID, "job.type", job.Type, "job.partition", job.Partition())
+	for _, j := range jobs {
+		log.Info("preempting lower priority job", "preempted.id", j.ID, "preempted.type", j.Type, "preempted.partition", j.Partition(), "host.id", j.HostID)
+		j.PreemptedBy = job.ID
+		if err := s.stopJob(j); err != nil {
+			log.Error("error preempting job", "preempted.id", j.ID, "err", err)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	ct "github.com/flynn/flynn/controller/types"
+)
+
+// RestartPolicy returns the restart policy of the job's process type, which
+// is nil if the process type does not have one
+func (j *Job) RestartPolicy() *ct.RestartPolicy {
+	if j.Formation == nil || j.Formation.ExpandedFormation == nil || j.Formation.Release == nil {
+		return nil
+	}
+	return j.Formation.Release.Processes[j.Type].RestartPolicy
+}
+
+// Failed returns whether the stopped job either failed to run or exited with
+// a non-zero status (jobs with an unknown exit status, for example those
+// which were running on a host which has gone away, are considered failed)
+func (j *Job) Failed() bool {
+	return j.hostError != nil || j.exitStatus == nil || *j.exitStatus != 0
+}
+
+// recordRestart records a restart of a job of the given type, returning the
+// number of restarts of the type in the given window, including this one
+func (f *Formation) recordRestart(typ string, window time.Duration) int {
+	if f.restarts == nil {
+		f.restarts = make(map[string][]time.Time)
+	}
+	now := time.Now()
+	restarts := []time.Time{now}
+	for _, t := range f.restarts[typ] {
+		if now.Sub(t) < window {
+			restarts = append(restarts, t)
+		}
+	}
+	f.restarts[typ] = restarts
+	return len(restarts)
+}
+
+// markExited records a stopped job of the given type which is not being
+// restarted due to its restart policy
+func (f *Formation) markExited(typ string) {
+	if f.Exited == nil {
+		f.Exited = make(Processes)
+	}
+	f.Exited[typ]++
+}
+
+// markCrashLooping stops jobs of the given type being restarted
+func (f *Formation) markCrashLooping(typ, reason string) {
+	if f.CrashLooping == nil {
+		f.CrashLooping = make(map[string]string)
+	}
+	f.CrashLooping[typ] = reason
+}
+
+// ResetRestarts resets the restart state of all process types, so that jobs
+// which have not been restarted due to their restart policy are started again
+func (f *Formation) ResetRestarts() {
+	f.CrashLooping = nil
+	f.Exited = nil
+	f.restarts = nil
+}
+
+// maybeRestartJob restarts the given stopped job unless its restart policy
+// says not to, marking its process type as crash looping if the policy's
+// restart budget has been exhausted
+func (s *Scheduler) maybeRestartJob(job *Job) {
+	log := s.logger.New("fn", "maybeRestartJob", "job.id", job.ID, "app.id", job.AppID, "release.id", job.ReleaseID, "job.type", job.Type)
+
+	// always restart jobs which were stopped to make room for higher
+	// priority jobs since they did not stop of their own accord
+	policy := job.RestartPolicy()
+	if policy == nil || job.PreemptedBy != "" {
+		s.restartJob(job)
+		return
+	}
+
+	switch {
+	case policy.Type == ct.RestartPolicyNever:
+		log.Info("not restarting job due to restart policy", "policy", policy.Type)
+		job.Formation.markExited(job.Type)
+		return
+	case policy.Type == ct.RestartPolicyOnFailure && !job.Failed():
+		log.Info("not restarting successfully exited job due to restart policy", "policy", policy.Type)
+		job.Formation.markExited(job.Type)
+		return
+	}
+
+	if policy.MaxRestarts > 0 {
+		window := time.Duration(policy.Window) * time.Second
+		if n := job.Formation.recordRestart(job.Type, window); n > policy.MaxRestarts {
+			reason := fmt.Sprintf("restarted %d times in %s", policy.MaxRestarts, window)
+			log.Warn("process type is crash looping, not restarting job", "reason", reason)
+			job.Formation.markCrashLooping(job.Type, reason)
+			job.CrashLoopReason = reason
+			s.persistJob(job)
+			return
+		}
+	}
+
+	s.restartJob(job)
+}
+
+// getBackoffDuration returns how long to wait before restarting a job which
+// has already been restarted the given number of times, doubling the policy's
+// backoff base for each restart up to its backoff cap if it has a custom
+// backoff
+func (s *Scheduler) getBackoffDuration(policy *ct.RestartPolicy, restarts uint) time.Duration {
+	if policy != nil && policy.BackoffBase > 0 {
+		backoff := time.Duration(policy.BackoffBase) * time.Second
+		max := time.Duration(policy.BackoffCap) * time.Second
+		for i := uint(0); i < restarts && backoff < max; i++ {
+			backoff *= 2
+		}
+		if backoff > max {
+			backoff = max
+		}
+		return backoff
+	}
+	switch {
+	case restarts < 5:
+		return 0
+	case restarts < 15:
+		return 10 * time.Second
+	default:
+		return 30 * time.Second
+	}
+}
+
+// restartHistoryPeriod is how far back SyncRestarts looks for restarted jobs
+// of process types whose restart policies have no restart window, which
+// covers the period after which restartJob resets a job's restart count
+const restartHistoryPeriod = 5 * time.Minute
+
+// SyncRestarts rebuilds the restart state of process types from the job
+// history in the controller when becoming leader so that a crash looping
+// process type is not restarted at full speed after a leader change.
+//
+// Restarts scheduled by the previous leader are rescheduled with their
+// original backoff, restarts within each restart policy's window are counted
+// against its restart budget, and process types whose most recent job was
+// not restarted because they were crash looping are marked as such.
+//
+// It is expected to run before SyncJobs, which otherwise marks the previous
+// leader's pending restarts as down.
+func (s *Scheduler) SyncRestarts() {
+	log := s.logger.New("fn", "SyncRestarts")
+
+	period := restartHistoryPeriod
+	for _, f := range s.formations {
+		for _, proc := range f.Release.Processes {
+			if policy := proc.RestartPolicy; policy != nil {
+				if window := time.Duration(policy.Window) * time.Second; window > period {
+					period = window
+				}
+			}
+		}
+	}
+
+	log.Info("getting job history", "period", period)
+	jobs, err := s.JobListSince(time.Now().Add(-period))
+	if err != nil {
+		log.Error("error getting job history", "err", err)
+		return
+	}
+
+	latest := make(map[string]*ct.Job)
+	for _, job := range jobs {
+		formation := s.formations.Get(job.AppID, job.ReleaseID)
+		if formation == nil || job.CreatedAt == nil {
+			continue
+		}
+		key := job.AppID + ":" + job.ReleaseID + ":" + job.Type
+		latest[key] = job
+
+		// count the restart against the restart budget of the
+		// process type's restart policy
+		if policy := formation.Release.Processes[job.Type].RestartPolicy; policy != nil && policy.MaxRestarts > 0 && job.Restarts != nil {
+			window := time.Duration(policy.Window) * time.Second
+			if time.Since(*job.CreatedAt) < window {
+				if formation.restarts == nil {
+					formation.restarts = make(map[string][]time.Time)
+				}
+				formation.restarts[job.Type] = append(formation.restarts[job.Type], *job.CreatedAt)
+			}
+		}
+
+		// reschedule restarts which the previous leader was waiting
+		// to start
+		if job.State != ct.JobStatePending || job.Restarts == nil {
+			continue
+		}
+		if _, ok := s.jobs[job.UUID]; ok {
+			continue
+		}
+		restart := &Job{
+			ID:        job.UUID,
+			Type:      job.Type,
+			AppID:     job.AppID,
+			ReleaseID: job.ReleaseID,
+			Formation: formation,
+			RunAt:     job.RunAt,
+			StartedAt: *job.CreatedAt,
+			State:     JobStatePending,
+			Restarts:  uint(*job.Restarts),
+			Args:      job.Args,
+		}
+		var delay time.Duration
+		if restart.RunAt != nil {
+			delay = time.Until(*restart.RunAt)
+		}
+		log.Info("rescheduling job restart", "job.id", restart.ID, "app.id", job.AppID, "release.id", job.ReleaseID, "job.type", job.Type, "attempts", restart.Restarts, "delay", delay)
+		s.jobs.Add(restart)
+		restart.restartTimer = time.AfterFunc(delay, func() { s.StartJob(restart) })
+	}
+
+	for _, job := range latest {
+		if job.State == ct.JobStateDown && job.CrashLoopReason != nil {
+			formation := s.formations.Get(job.AppID, job.ReleaseID)
+			log.Info("process type is crash looping", "app.id", job.AppID, "release.id", job.ReleaseID, "job.type", job.Type, "reason", *job.CrashLoopReason)
+			formation.markCrashLooping(job.Type, *job.CrashLoopReason)
+		}
+	}
+}
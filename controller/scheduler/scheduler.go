@@ -8,14 +8,18 @@ import (
 	"net/http"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	controller "github.com/flynn/flynn/controller/client"
+	"github.com/flynn/flynn/controller/secrets"
 	ct "github.com/flynn/flynn/controller/types"
 	"github.com/flynn/flynn/controller/utils"
 	discoverd "github.com/flynn/flynn/discoverd/client"
+	"github.com/flynn/flynn/host/resource"
 	host "github.com/flynn/flynn/host/types"
 	"github.com/flynn/flynn/host/volume"
 	"github.com/flynn/flynn/pkg/attempt"
@@ -38,11 +42,14 @@ const (
 )
 
 var (
-	ErrNotLeader        = errors.New("scheduler is not the leader")
-	ErrNoHosts          = errors.New("no hosts found")
-	ErrJobNotPending    = errors.New("job is no longer pending")
-	ErrNoHostsMatchTags = errors.New("no hosts found matching job tags")
-	ErrHostIsDown       = errors.New("host is down")
+	ErrNotLeader            = errors.New("scheduler is not the leader")
+	ErrNoHosts              = errors.New("no hosts found")
+	ErrJobNotPending        = errors.New("job is no longer pending")
+	ErrNoHostsMatchTags     = errors.New("no hosts found matching job tags")
+	ErrHostIsDown           = errors.New("host is down")
+	ErrNoHostCapacity       = errors.New("no hosts found with capacity for job resource requests")
+	ErrNoHostsMatchSpread   = errors.New("no hosts found satisfying job spread constraints")
+	ErrNoHostsMatchAffinity = errors.New("no hosts found satisfying job affinity rules")
 )
 
 type Scheduler struct {
@@ -88,6 +95,9 @@ type Scheduler struct {
 	controllerPersist     chan interface{}
 	placementRequests     chan *PlacementRequest
 	internalStateRequests chan *InternalStateRequest
+	dryRunRequests        chan *DryRunRequest
+	drainRequests         chan *DrainRequest
+	drainHosts            chan struct{}
 
 	rectifyBatch map[utils.FormationKey]struct{}
 
@@ -107,6 +117,13 @@ type Scheduler struct {
 	generateJobUUID func() string
 
 	routerBackends map[string]*RouterBackend
+
+	// drains are the in-progress and most recent drains of each host
+	drains map[string]*drain
+
+	// secrets are the cluster keys used to decrypt secret release env
+	// vars when building job configs
+	secrets *secrets.Keyring
 }
 
 func NewScheduler(cluster utils.ClusterClient, cc utils.ControllerClient, disc Discoverd, l log15.Logger) *Scheduler {
@@ -145,11 +162,15 @@ func NewScheduler(cluster utils.ClusterClient, cc utils.ControllerClient, disc D
 		controllerPersist:     make(chan interface{}, eventBufferSize),
 		placementRequests:     make(chan *PlacementRequest, eventBufferSize),
 		internalStateRequests: make(chan *InternalStateRequest, eventBufferSize),
+		dryRunRequests:        make(chan *DryRunRequest, eventBufferSize),
+		drainRequests:         make(chan *DrainRequest, eventBufferSize),
+		drainHosts:            make(chan struct{}, 1),
 		formationlessJobs:     make(map[utils.FormationKey]map[string]*Job),
 		pause:                 make(chan struct{}),
 		resume:                make(chan struct{}),
 		generateJobUUID:       random.UUID,
 		routerBackends:        make(map[string]*RouterBackend),
+		drains:                make(map[string]*drain),
 	}
 }
 
@@ -178,7 +199,14 @@ func main() {
 		shutdown.Fatal(err)
 	}
 
+	keyring, err := secrets.ParseKeyring(os.Getenv("SECRETS_KEYS"))
+	if err != nil {
+		log.Error("error parsing secrets keys", "err", err)
+		shutdown.Fatal(err)
+	}
+
 	s := NewScheduler(clusterClient, controllerClient, newDiscoverdWrapper(logger), logger)
+	s.secrets = keyring
 	log.Info("started scheduler")
 
 	go s.startHTTPServer(os.Getenv("PORT"))
@@ -551,6 +579,12 @@ func (s *Scheduler) Run() {
 			s.HandlePlacementRequest(req)
 		case req := <-s.internalStateRequests:
 			s.HandleInternalStateRequest(req)
+		case req := <-s.dryRunRequests:
+			s.HandleDryRunRequest(req)
+		case req := <-s.drainRequests:
+			s.HandleDrainRequest(req)
+		case <-s.drainHosts:
+			s.DrainHosts()
 		case e := <-s.hostEvents:
 			s.HandleHostEvent(e)
 		case e := <-s.serviceEvents:
@@ -903,6 +937,10 @@ func (s *Scheduler) RectifyFormation(key utils.FormationKey) {
 	// them on hosts with matching tags
 	s.stopJobsWithMismatchedTags(formation)
 
+	// stop jobs which violate their required affinity rules in case we
+	// need to reschedule them on hosts which satisfy them
+	s.stopJobsWithViolatedAffinity(formation)
+
 	// if there is a pending scale request, mark it as complete if the
 	// formation has the correct number of running jobs
 	if req := formation.PendingScaleRequest; req != nil && req.State == ct.ScaleRequestStatePending {
@@ -992,18 +1030,61 @@ func (s *Scheduler) stopJobsWithMismatchedTags(formation *Formation) {
 		if job.TagsMatchHost(host) {
 			continue
 		}
+		if !s.canDisrupt(job) {
+			log.Info("delaying stopping job with mismatched tags to honour its disruption budget", "job.id", job.ID)
+			continue
+		}
 		log.Info("job has mismatched tags, stopping", "job.id", job.ID, "job.tags", job.Tags(), "host.id", host.ID, "host.tags", host.Tags)
 		s.stopJob(job)
 	}
 }
 
+// stopJobsWithViolatedAffinity stops any running jobs which violate their
+// required affinity or anti-affinity rules (possible after the jobs they
+// reference are stopped or moved, or the formation's release is updated)
+func (s *Scheduler) stopJobsWithViolatedAffinity(formation *Formation) {
+	log := s.logger.New("fn", "stopJobsWithViolatedAffinity")
+	var hostJobs map[string][]*Job
+	for _, job := range s.jobs {
+		if !job.IsInFormation(formation.key()) || !job.IsRunning() {
+			continue
+		}
+		if affinity, antiAffinity := job.AffinityRules(); len(affinity) == 0 && len(antiAffinity) == 0 {
+			continue
+		}
+		if hostJobs == nil {
+			hostJobs = s.jobs.GetHostJobs()
+		}
+		if !affinityViolated(job, hostJobs[job.HostID]) {
+			continue
+		}
+		if !s.canDisrupt(job) {
+			log.Info("delaying stopping job which violates affinity rules to honour its disruption budget", "job.id", job.ID)
+			continue
+		}
+		log.Info("job violates affinity rules, stopping", "job.id", job.ID, "host.id", job.HostID, "rules", formatAffinityRules(job))
+		s.stopJob(job)
+	}
+}
+
 // maybeStartBlockedJobs starts any jobs which are blocked due to not
-// matching tags of any hosts on the given host, which is expected to be
-// either a new host or a host whose tags have just changed
+// matching tags of any hosts, not satisfying affinity rules or spread
+// constraints, or not fitting on any hosts on the given host, which is
+// expected to be either a new host, a host whose tags or capacity have just
+// changed, or a host which has just had a job start or stop
 func (s *Scheduler) maybeStartBlockedJobs(host *Host) {
+	if host.Cordoned {
+		return
+	}
+	committed := s.jobs.GetHostResourceRequests()[host.ID]
+	hostJobs := s.jobs.GetHostJobs()[host.ID]
 	for _, job := range s.jobs {
-		if job.State == JobStateBlocked && job.TagsMatchHost(host) {
+		if job.State != JobStateBlocked || !job.TagsMatchHost(host) {
+			continue
+		}
+		if ok, _ := affinityScore(job, hostJobs); ok && s.spreadAllows(job, host) && host.HasRoomFor(committed, job.ResourceRequests()) {
 			job.State = JobStatePending
+			job.BlockedReason = ""
 			go s.StartJob(job)
 		}
 	}
@@ -1145,14 +1226,13 @@ func (s *Scheduler) HandlePlacementRequest(req *PlacementRequest) {
 			if vol.HostID != "" {
 				host, ok := s.hosts[vol.HostID]
 				if !ok {
-					req.Job.State = JobStateBlocked
-					s.persistJob(req.Job)
-					req.Error(ErrHostIsDown)
+					s.blockJob(req, ErrHostIsDown)
 					return
 				} else if !req.Job.TagsMatchHost(host) {
-					req.Job.State = JobStateBlocked
-					s.persistJob(req.Job)
-					req.Error(ErrNoHostsMatchTags)
+					s.blockJob(req, ErrNoHostsMatchTags)
+					return
+				} else if !host.HasRoomFor(s.jobs.GetHostResourceRequests()[host.ID], req.Job.ResourceRequests()) {
+					s.blockJob(req, ErrNoHostCapacity)
 					return
 				}
 				req.Host = host
@@ -1168,39 +1248,17 @@ func (s *Scheduler) HandlePlacementRequest(req *PlacementRequest) {
 		}
 	}
 
-	// if we didn't pick a host for the job's volumes, pick a host with
-	// the least amount of jobs running of the given type
+	// if we didn't pick a host for the job's volumes, select one
 	if req.Host == nil {
-		formation := req.Job.Formation
-		counts := s.jobs.GetHostJobCounts(formation.key(), req.Job.Type)
-		var minCount int = math.MaxInt32
-		for _, h := range s.ShuffledHosts() {
-			if h.Shutdown {
-				continue
-			}
-			if !req.Job.TagsMatchHost(h) {
-				continue
-			}
-			count, ok := counts[h.ID]
-			if !ok || count == 0 {
-				req.Host = h
-				break
-			}
-			if count < minCount {
-				minCount = count
-				req.Host = h
-			}
-		}
-
-		// if we still didn't pick a host, the job's tags don't match
-		// any hosts so mark it as blocked and return an error to
-		// cause the StartJob goroutine to stop trying to place the job
-		if req.Host == nil {
-			req.Job.State = JobStateBlocked
-			s.persistJob(req.Job)
-			req.Error(ErrNoHostsMatchTags)
+		host, preempt, err := s.selectHost(req.Job)
+		if err != nil {
+			s.blockJob(req, err)
 			return
 		}
+		if len(preempt) > 0 {
+			s.preemptJobs(req.Job, preempt)
+		}
+		req.Host = host
 
 		if len(req.Job.Tags()) == 0 {
 			log.Info(fmt.Sprintf("placed job on host with least %s jobs", req.Job.Type), "host.id", req.Host.ID)
@@ -1210,6 +1268,10 @@ func (s *Scheduler) HandlePlacementRequest(req *PlacementRequest) {
 	}
 
 	req.Config = jobConfig(req.Job, req.Host.ID)
+	if err := s.secrets.DecryptEnv(req.Job.Formation.App.ID, req.Config.Config.Env); err != nil {
+		s.blockJob(req, err)
+		return
+	}
 	req.Job.JobID = req.Config.ID
 	req.Job.HostID = req.Host.ID
 	for _, vol := range req.Job.Volumes {
@@ -1220,6 +1282,133 @@ func (s *Scheduler) HandlePlacementRequest(req *PlacementRequest) {
 	req.Error(nil)
 }
 
+// selectHost picks a host with room for the given job's resource requests
+// which satisfies the type's required affinity rules and hard spread
+// constraints, preferring hosts which satisfy the most preferred affinity
+// rules, then hosts in the least populated spread tag values, then the host
+// with the least amount of jobs running of the given type, then the host which
+// would have the least unallocated capacity left over (i.e. the best fit).
+//
+// If no hosts have room for the job, it returns a host along with the lower
+// priority jobs which need to be preempted to make room, and otherwise returns
+// an error explaining why the job cannot be placed.
+//
+// selectHost does not modify any state so that it can also be used to
+// simulate placements (see DryRun).
+func (s *Scheduler) selectHost(job *Job) (*Host, []*Job, error) {
+	formation := job.Formation
+	counts := s.jobs.GetHostJobCounts(formation.key(), job.Type)
+	committed := s.jobs.GetHostResourceRequests()
+	requests := job.ResourceRequests()
+	domains := s.spreadDomains(formation, job.Type)
+	hostJobs := s.jobs.GetHostJobs()
+	var minAffinity int = math.MaxInt32
+	var minSpread int = math.MaxInt32
+	var minCount int = math.MaxInt32
+	var minScore float64 = math.MaxFloat64
+	var tagsMatch, affinityMatch, spreadMatch bool
+	var selected *Host
+	var full []*Host
+	for _, h := range s.ShuffledHosts() {
+		if h.Shutdown || h.Cordoned {
+			continue
+		}
+		if !job.TagsMatchHost(h) {
+			continue
+		}
+		tagsMatch = true
+		ok, affinity := affinityScore(job, hostJobs[h.ID])
+		if !ok {
+			continue
+		}
+		affinityMatch = true
+		if !spreadAllowed(domains, h) {
+			continue
+		}
+		spreadMatch = true
+		if !h.HasRoomFor(committed[h.ID], requests) {
+			full = append(full, h)
+			continue
+		}
+		spread := spreadScore(domains, h)
+		count := counts[h.ID]
+		score := h.FitScore(committed[h.ID], requests)
+		if affinity < minAffinity ||
+			affinity == minAffinity && spread < minSpread ||
+			affinity == minAffinity && spread == minSpread && count < minCount ||
+			affinity == minAffinity && spread == minSpread && count == minCount && score < minScore {
+			minAffinity = affinity
+			minSpread = spread
+			minCount = count
+			minScore = score
+			selected = h
+		}
+	}
+	if selected != nil {
+		return selected, nil, nil
+	}
+
+	// if no hosts have room for the job's resource requests, try to make
+	// room by preempting lower priority jobs
+	if len(full) > 0 {
+		if host, jobs := s.findJobsToPreempt(job, full); host != nil {
+			return host, jobs, nil
+		}
+	}
+
+	// we didn't pick a host, so either the job's tags don't match any
+	// hosts, no hosts satisfy the job's required affinity rules or hard
+	// spread constraints, or no hosts have room for the job's resource
+	// requests
+	switch {
+	case !tagsMatch:
+		return nil, nil, ErrNoHostsMatchTags
+	case !affinityMatch:
+		return nil, nil, ErrNoHostsMatchAffinity
+	case !spreadMatch:
+		return nil, nil, ErrNoHostsMatchSpread
+	default:
+		return nil, nil, ErrNoHostCapacity
+	}
+}
+
+// blockJob marks the job in the given placement request as blocked, recording
+// the reason it could not be placed, and returns the error to the StartJob
+// goroutine so that it stops trying to place the job
+func (s *Scheduler) blockJob(req *PlacementRequest, err error) {
+	req.Job.State = JobStateBlocked
+	req.Job.BlockedReason = blockedReason(req.Job, err)
+	s.logger.Warn("marking job as blocked", "fn", "blockJob", "job.id", req.Job.ID, "job.type", req.Job.Type, "reason", req.Job.BlockedReason)
+	s.persistJob(req.Job)
+	s.triggerDrainHosts()
+	req.Error(err)
+}
+
+// blockedReason returns the reason the given job could not be placed due to
+// the given error, including the job's resource requests or required affinity
+// rules when relevant
+func blockedReason(job *Job, err error) string {
+	switch err {
+	case ErrNoHostCapacity:
+		return fmt.Sprintf("%s (requested %s)", err, formatResourceRequests(job.ResourceRequests()))
+	case ErrNoHostsMatchAffinity:
+		return fmt.Sprintf("%s (required %s)", err, formatAffinityRules(job))
+	default:
+		return err.Error()
+	}
+}
+
+// formatResourceRequests formats resource requests as a sorted, comma
+// separated list of TYPE=VAL pairs (e.g. "cpu=1000, memory=1GB")
+func formatResourceRequests(requests map[resource.Type]int64) string {
+	pairs := make([]string, 0, len(requests))
+	for typ, n := range requests {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", typ, resource.FormatLimit(typ, n)))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
 type InternalState struct {
 	JobID      string                `json:"job_id"`
 	Hosts      map[string]*Host      `json:"hosts"`
@@ -1259,6 +1448,10 @@ func (s *Scheduler) HandleInternalStateRequest(req *InternalStateRequest) {
 		for key, val := range host.Tags {
 			h.Tags[key] = val
 		}
+		h.Capacity = make(map[resource.Type]int64, len(host.Capacity))
+		for typ, n := range host.Capacity {
+			h.Capacity[typ] = n
+		}
 		req.State.Hosts[id] = &h
 	}
 
@@ -1337,12 +1530,14 @@ var controllerPersistAttempts = attempt.Strategy{
 	Total: time.Minute,
 }
 
-// ControllerPersistLoop starts a loop which receives jobs, volumes and scale
-// requests from the s.controllerPersist channel and persists them to the
-// controller using the controllerPersistAttempts retry strategy.
+// ControllerPersistLoop starts a loop which receives jobs, volumes, host drain
+// statuses and scale requests from the s.controllerPersist channel and
+// persists them to the controller using the controllerPersistAttempts retry
+// strategy.
 //
-// A goroutine is started per job, volume and scale request to persist, but
-// care is taken to persist jobs with the same UUID sequentially and in order
+// A goroutine is started per job, volume, drain status and scale request to
+// persist, but care is taken to persist jobs with the same UUID (and drain
+// statuses of the same host) sequentially and in order
 // (to avoid for example a job transitioning from "down" to "up" in the
 // controller) and scale requests after associated jobs (so that scale events
 // are emitted after job events).
@@ -1370,6 +1565,16 @@ func (s *Scheduler) ControllerPersistLoop() {
 	// of the next volume in the queue for that ID
 	volDone := make(chan string)
 
+	// drainQueue is a map of host ID to a slice of drain statuses to
+	// persist for that host, and the loop below persists the statuses in
+	// the slice in FIFO order
+	drainQueue := make(map[string][]*host.DrainStatus)
+
+	// drainDone is a channel which receives a host ID once a drain status
+	// has been persisted for that host, thus potentially triggering the
+	// persistence of the next status in the queue for that host
+	drainDone := make(chan string)
+
 	// scaleRequests is a queue of scale requests waiting to be persisted
 	// after the associated job events
 	scaleRequests := make(map[string]*ct.ScaleRequest)
@@ -1398,6 +1603,19 @@ func (s *Scheduler) ControllerPersistLoop() {
 		volDone <- vol.ID
 	}
 
+	// persistDrain makes multiple attempts to persist the given drain
+	// status, sending to the drainDone channel once the attempts have
+	// finished
+	persistDrain := func(status *host.DrainStatus) {
+		err := controllerPersistAttempts.RunWithValidator(func() error {
+			return s.PutHostDrain(status)
+		}, httphelper.IsRetryableError)
+		if err != nil {
+			log.Error("error persisting host drain", "host.id", status.HostID, "drain.state", status.State, "err", err)
+		}
+		drainDone <- status.HostID
+	}
+
 	maybePersistScaleRequest := func(req *ct.ScaleRequest) {
 		// if there are any associated jobs being persisted, add to the
 		// scale request queue to be persisted later (to avoid scale
@@ -1451,6 +1669,14 @@ func (s *Scheduler) ControllerPersistLoop() {
 				if len(volQueue[v.ID]) == 1 {
 					go persistVolume(v)
 				}
+			case *host.DrainStatus:
+				// push the status to the back of the queue
+				drainQueue[v.HostID] = append(drainQueue[v.HostID], v)
+
+				// if there is only one status in the queue, persist it
+				if len(drainQueue[v.HostID]) == 1 {
+					go persistDrain(v)
+				}
 			case *ct.ScaleRequest:
 				maybePersistScaleRequest(v)
 			}
@@ -1479,6 +1705,16 @@ func (s *Scheduler) ControllerPersistLoop() {
 			} else {
 				delete(volQueue, id)
 			}
+		case id := <-drainDone:
+			// remove the persisted status from the queue
+			drainQueue[id] = drainQueue[id][1:]
+
+			// if the queue has more statuses, persist the first one
+			if len(drainQueue[id]) > 0 {
+				go persistDrain(drainQueue[id][0])
+			} else {
+				delete(drainQueue, id)
+			}
 		}
 	}
 }
@@ -1491,7 +1727,9 @@ func (s *Scheduler) HandleLeaderChange(isLeader bool) {
 		// ensure we are in sync and then rectify
 		s.SyncHosts()
 		s.SyncFormations()
+		s.SyncRestarts()
 		s.SyncJobs()
+		s.SyncDrains()
 		s.rectifyAll()
 		s.triggerSendTelemetry()
 	} else {
@@ -1526,7 +1764,12 @@ func (s *Scheduler) handleFormationDiff(f *Formation, diff Processes) {
 		} else if n < 0 {
 			log.Info(fmt.Sprintf("stopping %d %s jobs", -n, typ))
 			for i := 0; i < -n; i++ {
-				s.stopJobOfType(f, typ)
+				// stop stopping jobs once the disruption
+				// budget is reached, the formation will be
+				// rectified again as jobs start and stop
+				if err := s.stopJobOfType(f, typ); err == ErrDisruptionBudget {
+					break
+				}
 			}
 		}
 	}
@@ -1575,6 +1818,15 @@ outer:
 		} else if err == ErrHostIsDown {
 			log.Warn("unable to place job as the host is down")
 			return
+		} else if err == ErrNoHostCapacity {
+			log.Warn("unable to place job as no hosts have capacity for its resource requests")
+			return
+		} else if err == ErrNoHostsMatchSpread {
+			log.Warn("unable to place job as no hosts satisfy its spread constraints")
+			return
+		} else if err == ErrNoHostsMatchAffinity {
+			log.Warn("unable to place job as no hosts satisfy its affinity rules")
+			return
 		} else if err != nil {
 			log.Error("error placing job in the cluster", "err", err)
 			continue
@@ -1732,6 +1984,27 @@ func (s *Scheduler) HandleHostEvent(e *discoverd.Event) {
 			s.rectifyAll()
 			s.maybeStartBlockedJobs(host)
 		}
+
+		// if the host's capacity has changed, try to start blocked
+		// jobs in case they now fit on the host
+		capacity := cluster.HostCapacityFromMeta(e.Instance.Meta)
+		if !host.CapacityEqual(capacity) {
+			log.Info("host capacity changed", "host.id", id, "from", host.Capacity, "to", capacity)
+			host.Capacity = capacity
+			s.maybeStartBlockedJobs(host)
+		}
+
+		// if the host has been uncordoned, try to start blocked jobs
+		// in case they can now run on the host, and progress any drain
+		// of the host so it gets cancelled
+		if cordoned := cluster.HostCordonedFromMeta(e.Instance.Meta); cordoned != host.Cordoned {
+			log.Info("host cordon changed", "host.id", id, "cordoned", cordoned)
+			host.Cordoned = cordoned
+			if !cordoned {
+				s.maybeStartBlockedJobs(host)
+			}
+			s.triggerDrainHosts()
+		}
 	case discoverd.EventKindDown:
 		id := e.Instance.Meta["id"]
 		log = log.New("host.id", id)
@@ -2014,6 +2287,7 @@ func (s *Scheduler) handleControllerVolume(volume *ct.Volume) {
 		}
 		if job, ok := s.jobs[*vol.JobID]; ok && job.State == JobStateBlocked {
 			job.State = JobStatePending
+			job.BlockedReason = ""
 			go s.StartJob(job)
 		}
 	}
@@ -2121,6 +2395,8 @@ func (s *Scheduler) handleActiveJob(activeJob *host.ActiveJob) *Job {
 
 	job.StartedAt = activeJob.StartedAt
 	job.metadata = hostJob.Metadata
+	job.resources = hostJob.Resources
+	job.partition = hostJob.Partition
 	job.exitStatus = activeJob.ExitStatus
 	job.hostError = activeJob.Error
 
@@ -2169,6 +2445,22 @@ func (s *Scheduler) handleJobStatus(job *Job, status host.JobStatus) {
 		s.persistJob(job)
 	}
 
+	// if the job has just started, jobs with affinity for it may now be
+	// able to run on its host, and if it has just stopped, its resources
+	// are no longer committed to its host and jobs with anti-affinity for
+	// it may now be able to run on its host, so try to start any blocked
+	// jobs on the host
+	if job.State != previousState && (job.State == JobStateRunning || job.State == JobStateStopped) && s.IsLeader() {
+		if host, ok := s.hosts[job.HostID]; ok {
+			s.maybeStartBlockedJobs(host)
+		}
+	}
+
+	// progress any host drains which may be waiting for the job
+	if job.State != previousState {
+		s.triggerDrainHosts()
+	}
+
 	// ensure jobs started as part of a formation change have a known formation
 	if job.metadata["flynn-controller.formation"] == "true" && job.Formation == nil {
 		formation := s.formations.Get(job.AppID, job.ReleaseID)
@@ -2212,13 +2504,19 @@ func (s *Scheduler) handleJobStatus(job *Job, status host.JobStatus) {
 	// expect it to be running, and if we do, restart it
 	if previousState != JobStateStopped && job.State == JobStateStopped {
 		if diff := s.formationDiff(job.Formation); diff[job.Type] > 0 {
-			s.restartJob(job)
+			s.maybeRestartJob(job)
 		}
 	}
 
 	// trigger a rectify for the job's formation in case we have too many
 	// jobs of the given type and we need to stop some
 	s.triggerRectify(job.Formation.key())
+
+	// jobs of the app's other formations may have been waiting for this
+	// job to start or stop before they could be stopped
+	if job.State != previousState {
+		s.triggerDisruptedRectify(job)
+	}
 }
 
 func (s *Scheduler) persistJob(job *Job) {
@@ -2253,19 +2551,20 @@ func (s *Scheduler) handleFormation(ef *ct.ExpandedFormation) (formation *Format
 			if proc.Service == "" {
 				continue
 			}
-			service, ok := s.services[proc.Service]
+			name := ef.ServiceName(proc.Service)
+			service, ok := s.services[name]
 			if !ok {
 				if processes.IsEmpty() {
 					continue
 				}
-				service = NewService(proc.Service, s.serviceEvents, s.logger)
-				s.services[proc.Service] = service
+				service = NewService(name, s.serviceEvents, s.logger)
+				s.services[name] = service
 			}
 			if processes.IsEmpty() {
 				delete(service.Formations, formation.key())
 				if len(service.Formations) == 0 {
 					service.Close()
-					delete(s.services, proc.Service)
+					delete(s.services, name)
 				}
 			} else {
 				service.Formations[formation.key()] = struct{}{}
@@ -2302,6 +2601,12 @@ func (s *Scheduler) handleFormation(ef *ct.ExpandedFormation) (formation *Format
 			return
 		}
 		formation.UpdatedAt = ef.UpdatedAt
+
+		// retry process types whose jobs are not being restarted due
+		// to their restart policy when the formation is scaled again
+		if req := ef.PendingScaleRequest; req != nil && (formation.PendingScaleRequest == nil || formation.PendingScaleRequest.ID != req.ID) {
+			formation.ResetRestarts()
+		}
 		formation.PendingScaleRequest = ef.PendingScaleRequest
 
 		diff := Processes(ef.Processes).Diff(formation.OriginalProcesses)
@@ -2319,6 +2624,7 @@ func (s *Scheduler) handleFormation(ef *ct.ExpandedFormation) (formation *Format
 		log.Info("updating processes and tags of existing formation", "processes", ef.Processes, "tags", ef.Tags)
 		formation.Tags = ef.Tags
 		formation.SetProcesses(ef.Processes)
+		formation.ResetRestarts()
 	}
 	s.triggerRectify(formation.key())
 	return
@@ -2381,7 +2687,7 @@ func (s *Scheduler) stopJobOfType(f *Formation, typ string) (err error) {
 	log.Info(fmt.Sprintf("stopping %s job", typ))
 
 	defer func() {
-		if err != nil {
+		if err != nil && err != ErrDisruptionBudget {
 			log.Error(fmt.Sprintf("error stopping %s job", typ), "err", err)
 		}
 	}()
@@ -2390,6 +2696,10 @@ func (s *Scheduler) stopJobOfType(f *Formation, typ string) (err error) {
 	if err != nil {
 		return err
 	}
+	if !s.canDisrupt(job) {
+		log.Info("delaying stopping job to honour its disruption budget", "job.id", job.ID)
+		return ErrDisruptionBudget
+	}
 	return s.stopJob(job)
 }
 
@@ -2473,10 +2783,13 @@ func (s *Scheduler) stopJob(job *Job) error {
 }
 
 // findJobToStop finds a job from the given formation and type which should be
-// stopped, choosing pending jobs if present, and the most recently started job
-// otherwise
+// stopped, choosing pending jobs if present, then jobs in the most populated
+// tag values of the type's spread constraints, and the most recently started
+// job otherwise
 func (s *Scheduler) findJobToStop(f *Formation, typ string) (*Job, error) {
 	var found *Job
+	domains := s.spreadDomains(f, typ)
+	maxSpread := -1
 	for _, job := range s.jobs.WithFormationAndType(f, typ) {
 		switch job.State {
 		case JobStatePending:
@@ -2492,12 +2805,24 @@ func (s *Scheduler) findJobToStop(f *Formation, typ string) (*Job, error) {
 				return job, nil
 			}
 
+			// if the job is being moved off a cordoned host,
+			// return it rather than stopping the job replacing it
+			if job.Evacuating {
+				return job, nil
+			}
+
 			// return the most recent job (which is the first in
 			// the slice we are iterating over) if none of the
-			// above cases match, preferring starting jobs to
+			// above cases match, preferring jobs in the most
+			// populated spread tag values, then starting jobs to
 			// running ones
-			if found == nil || found.State == JobStateRunning && job.State == JobStateStarting {
+			var spread int
+			if host, ok := s.hosts[job.HostID]; ok {
+				spread = spreadScore(domains, host)
+			}
+			if found == nil || spread > maxSpread || spread == maxSpread && found.State == JobStateRunning && job.State == JobStateStarting {
 				found = job
+				maxSpread = spread
 			}
 		}
 	}
@@ -2555,11 +2880,12 @@ func (s *Scheduler) RunningJobs() map[string]*Job {
 
 func (s *Scheduler) restartJob(job *Job) {
 	restarts := job.Restarts
-	// reset the restart count if it has been running for more than 5 minutes
-	if !job.StartedAt.IsZero() && job.StartedAt.Before(time.Now().Add(-5*time.Minute)) {
+	// reset the restart count if it has been running for more than 5
+	// minutes, or was stopped to make room for a higher priority job
+	if !job.StartedAt.IsZero() && job.StartedAt.Before(time.Now().Add(-5*time.Minute)) || job.PreemptedBy != "" {
 		restarts = 0
 	}
-	backoff := s.getBackoffDuration(restarts)
+	backoff := s.getBackoffDuration(job.RestartPolicy(), restarts)
 
 	// create a new job so its state is tracked separately from the job
 	// it is replacing
@@ -2584,23 +2910,14 @@ func (s *Scheduler) restartJob(job *Job) {
 	newJob.restartTimer = time.AfterFunc(backoff, func() { s.StartJob(newJob) })
 }
 
-func (s *Scheduler) getBackoffDuration(restarts uint) time.Duration {
-	switch {
-	case restarts < 5:
-		return 0
-	case restarts < 15:
-		return 10 * time.Second
-	default:
-		return 30 * time.Second
-	}
-}
-
 func (s *Scheduler) startHTTPServer(port string) {
 	log := s.logger.New("fn", "startHTTPServer")
 
 	http.HandleFunc("/debug/state", func(w http.ResponseWriter, _ *http.Request) {
 		json.NewEncoder(w).Encode(s.InternalState())
 	})
+	http.HandleFunc("/debug/dry-run", s.serveDryRun)
+	http.HandleFunc("/drain/", s.serveDrain)
 
 	status.AddHandler(status.HealthyHandler)
 	addr := ":" + port
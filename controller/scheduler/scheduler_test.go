@@ -3,13 +3,16 @@ package main
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/docker/go-units"
 	. "github.com/flynn/flynn/controller/testutils"
 	ct "github.com/flynn/flynn/controller/types"
 	"github.com/flynn/flynn/controller/utils"
+	"github.com/flynn/flynn/host/resource"
 	"github.com/flynn/flynn/host/types"
 	"github.com/flynn/flynn/pkg/cluster"
 	"github.com/flynn/flynn/pkg/random"
@@ -108,6 +111,18 @@ func newTestScheduler(c *C, cluster utils.ClusterClient, isLeader bool, processe
 	return &TestScheduler{s, c, events, discoverd}
 }
 
+// newRequestTestScheduler returns a leader scheduler with the given hosts
+// which is not run, for testing the handling of individual requests against
+// a known cluster state
+func newRequestTestScheduler(hosts map[string]*Host) *Scheduler {
+	s := createTestScheduler(newTestCluster(nil), newFakeDiscoverd(true), nil, log15.New())
+	s.isLeader = typeconv.BoolPtr(true)
+	if hosts != nil {
+		s.hosts = hosts
+	}
+	return s
+}
+
 func runTestScheduler(c *C, cluster utils.ClusterClient, isLeader bool) *TestScheduler {
 	s := newTestScheduler(c, cluster, isLeader, nil)
 	go s.Run()
@@ -765,6 +780,857 @@ func (TestSuite) TestJobPlacementTags(c *C) {
 	}
 }
 
+func (TestSuite) TestJobPlacementResources(c *C) {
+	// create a scheduler with hosts of varying capacity
+	s := newRequestTestScheduler(map[string]*Host{
+		"host1": {ID: "host1", Capacity: map[resource.Type]int64{resource.TypeMemory: 4 * units.GiB}},
+		"host2": {ID: "host2", Capacity: map[resource.Type]int64{resource.TypeMemory: 8 * units.GiB}},
+		"host3": {ID: "host3", Capacity: map[resource.Type]int64{resource.TypeMemory: 16 * units.GiB}, Tags: map[string]string{"disk": "ssd"}},
+	})
+
+	memory := func(size int64) resource.Resources {
+		return resource.Resources{resource.TypeMemory: {Request: typeconv.Int64Ptr(size)}}
+	}
+	formation := NewFormation(&ct.ExpandedFormation{
+		App: &ct.App{ID: "app"},
+		Release: &ct.Release{ID: "release", Processes: map[string]ct.ProcessType{
+			"small": {Resources: memory(1 * units.GiB)},
+			"large": {Resources: memory(8 * units.GiB)},
+			"huge":  {Resources: memory(32 * units.GiB)},
+			"db":    {Resources: memory(10 * units.GiB)},
+		}},
+		Artifacts: []*ct.Artifact{{}},
+		Tags: map[string]map[string]string{
+			"db": {"disk": "ssd"},
+		},
+	})
+
+	place := func(typ string) (*PlacementRequest, error) {
+		job := s.jobs.Add(&Job{ID: random.UUID(), Formation: formation, Type: typ, State: JobStatePending})
+		req := &PlacementRequest{Job: job, Err: make(chan error, 1)}
+		s.HandlePlacementRequest(req)
+		return req, <-req.Err
+	}
+
+	// the first small job goes on the host with the best fit, then they
+	// are spread across the remaining hosts
+	req, err := place("small")
+	c.Assert(err, IsNil)
+	c.Assert(req.Host.ID, Equals, "host1")
+	hosts := make(map[string]int)
+	for i := 0; i < 2; i++ {
+		req, err := place("small")
+		c.Assert(err, IsNil)
+		hosts[req.Host.ID]++
+	}
+	c.Assert(hosts, DeepEquals, map[string]int{"host2": 1, "host3": 1})
+
+	// a large job only fits on host3 as host2 has 1GB committed
+	req, err = place("large")
+	c.Assert(err, IsNil)
+	c.Assert(req.Host.ID, Equals, "host3")
+
+	// a huge job doesn't fit anywhere, so is blocked with a reason
+	req, err = place("huge")
+	c.Assert(err, Equals, ErrNoHostCapacity)
+	c.Assert(req.Job.State, Equals, JobStateBlocked)
+	c.Assert(req.Job.BlockedReason, Equals, "no hosts found with capacity for job resource requests (requested memory=32GB)")
+
+	// a db job only matches host3 which has 9GB committed, so is blocked
+	req, err = place("db")
+	c.Assert(err, Equals, ErrNoHostCapacity)
+	db := req.Job
+
+	// stopping the large job frees up room on host3 for the db job
+	for _, job := range s.jobs {
+		if job.Type == "large" {
+			job.State = JobStateStopped
+		}
+	}
+	s.maybeStartBlockedJobs(s.hosts["host3"])
+	c.Assert(db.State, Equals, JobStatePending)
+	c.Assert(db.BlockedReason, Equals, "")
+	req = &PlacementRequest{Job: db, Err: make(chan error, 1)}
+	s.HandlePlacementRequest(req)
+	c.Assert(<-req.Err, IsNil)
+	c.Assert(req.Host.ID, Equals, "host3")
+}
+
+func (TestSuite) TestJobPlacementSpread(c *C) {
+	// create a scheduler with hosts in two zones plus a host with no zone,
+	// with the zone b host only having room for two jobs
+	s := newRequestTestScheduler(map[string]*Host{
+		"host1": {ID: "host1", Tags: map[string]string{"zone": "a"}},
+		"host2": {ID: "host2", Tags: map[string]string{"zone": "a"}},
+		"host3": {ID: "host3", Tags: map[string]string{"zone": "b"}, Capacity: map[resource.Type]int64{resource.TypeMemory: 2 * units.GiB}},
+		"host4": {ID: "host4"},
+	})
+
+	resources := resource.Resources{resource.TypeMemory: {Request: typeconv.Int64Ptr(1 * units.GiB)}}
+	formation := NewFormation(&ct.ExpandedFormation{
+		App: &ct.App{ID: "app"},
+		Release: &ct.Release{ID: "release", Processes: map[string]ct.ProcessType{
+			"web": {Resources: resources, Spread: []ct.SpreadConstraint{{TagKey: "zone", MaxSkew: 1, Hard: true}}},
+			"db":  {Resources: resources, Spread: []ct.SpreadConstraint{{TagKey: "rack", Hard: true}}},
+		}},
+		Artifacts: []*ct.Artifact{{}},
+	})
+
+	place := func(typ string) (*PlacementRequest, error) {
+		job := s.jobs.Add(&Job{ID: random.UUID(), Formation: formation, Type: typ, State: JobStatePending, StartedAt: time.Now()})
+		req := &PlacementRequest{Job: job, Err: make(chan error, 1)}
+		s.HandlePlacementRequest(req)
+		if req.Host != nil {
+			job.State = JobStateRunning
+		}
+		return req, <-req.Err
+	}
+	zoneCounts := func() map[string]int {
+		counts := make(map[string]int)
+		for _, job := range s.jobs {
+			if job.Type == "web" && job.IsRunning() {
+				counts[s.hosts[job.HostID].Tags["zone"]]++
+			}
+		}
+		return counts
+	}
+
+	// the first four web jobs alternate between zones
+	for i := 0; i < 4; i++ {
+		_, err := place("web")
+		c.Assert(err, IsNil)
+	}
+	c.Assert(zoneCounts(), DeepEquals, map[string]int{"a": 2, "b": 2})
+
+	// the fifth web job goes in zone a as zone b is full, but a sixth
+	// would exceed the max skew so is blocked
+	_, err := place("web")
+	c.Assert(err, IsNil)
+	c.Assert(zoneCounts(), DeepEquals, map[string]int{"a": 3, "b": 2})
+	req, err := place("web")
+	c.Assert(err, Equals, ErrNoHostCapacity)
+	delete(s.jobs, req.Job.ID)
+
+	// scaling down stops a job in the most populated zone
+	job, err := s.findJobToStop(formation, "web")
+	c.Assert(err, IsNil)
+	c.Assert(s.hosts[job.HostID].Tags["zone"], Equals, "a")
+
+	// db jobs can't be placed as no hosts have a rack tag
+	req, err = place("db")
+	c.Assert(err, Equals, ErrNoHostsMatchSpread)
+	c.Assert(req.Job.State, Equals, JobStateBlocked)
+	c.Assert(req.Job.BlockedReason, Equals, ErrNoHostsMatchSpread.Error())
+}
+
+func (TestSuite) TestJobPlacementAffinity(c *C) {
+	s := newRequestTestScheduler(map[string]*Host{
+		"host1": {ID: "host1"},
+		"host2": {ID: "host2"},
+		"host3": {ID: "host3"},
+	})
+
+	// cache jobs must run alongside a web job with at most one per host,
+	// and heavy jobs prefer not to run alongside any app jobs
+	app := NewFormation(&ct.ExpandedFormation{
+		App: &ct.App{ID: "app"},
+		Release: &ct.Release{ID: "release", Processes: map[string]ct.ProcessType{
+			"web": {},
+			"cache": {
+				Affinity:     []ct.AffinityRule{{Type: "web", Required: true}},
+				AntiAffinity: []ct.AffinityRule{{Type: "cache", Required: true}},
+			},
+		}},
+		Artifacts: []*ct.Artifact{{}},
+	})
+	other := NewFormation(&ct.ExpandedFormation{
+		App: &ct.App{ID: "other"},
+		Release: &ct.Release{ID: "other-release", Processes: map[string]ct.ProcessType{
+			"heavy": {AntiAffinity: []ct.AffinityRule{{AppID: "app"}}},
+		}},
+		Artifacts: []*ct.Artifact{{}},
+	})
+
+	place := func(formation *Formation, typ string) (*PlacementRequest, error) {
+		job := s.jobs.Add(&Job{
+			ID:        random.UUID(),
+			AppID:     formation.App.ID,
+			Formation: formation,
+			Type:      typ,
+			State:     JobStatePending,
+			StartedAt: time.Now(),
+		})
+		req := &PlacementRequest{Job: job, Err: make(chan error, 1)}
+		s.HandlePlacementRequest(req)
+		if req.Host != nil {
+			job.State = JobStateRunning
+		}
+		return req, <-req.Err
+	}
+
+	// a cache job is blocked until there are web jobs
+	req, err := place(app, "cache")
+	c.Assert(err, Equals, ErrNoHostsMatchAffinity)
+	c.Assert(req.Job.State, Equals, JobStateBlocked)
+	c.Assert(req.Job.BlockedReason, Equals, "no hosts found satisfying job affinity rules (required affinity=web, anti_affinity=cache)")
+	delete(s.jobs, req.Job.ID)
+
+	// cache jobs are placed alongside web jobs, one per host
+	webHosts := make(map[string]*Job)
+	for i := 0; i < 2; i++ {
+		req, err := place(app, "web")
+		c.Assert(err, IsNil)
+		webHosts[req.Host.ID] = req.Job
+	}
+	c.Assert(webHosts, HasLen, 2)
+	cacheHosts := make(map[string]*Job)
+	for i := 0; i < 2; i++ {
+		req, err := place(app, "cache")
+		c.Assert(err, IsNil)
+		c.Assert(webHosts[req.Host.ID], NotNil)
+		cacheHosts[req.Host.ID] = req.Job
+	}
+	c.Assert(cacheHosts, HasLen, 2)
+	_, err = place(app, "cache")
+	c.Assert(err, Equals, ErrNoHostsMatchAffinity)
+
+	// a heavy job is placed on the host without any app jobs
+	req, err = place(other, "heavy")
+	c.Assert(err, IsNil)
+	c.Assert(webHosts[req.Host.ID], IsNil)
+
+	// stopping a web job leaves the cache job on its host in violation
+	for hostID, web := range webHosts {
+		cache := cacheHosts[hostID]
+		c.Assert(affinityViolated(cache, s.jobs.GetHostJobs()[hostID]), Equals, false)
+		web.State = JobStateStopped
+		c.Assert(affinityViolated(cache, s.jobs.GetHostJobs()[hostID]), Equals, true)
+		break
+	}
+}
+
+func (TestSuite) TestJobPlacementPreemption(c *C) {
+	capacity := map[resource.Type]int64{resource.TypeMemory: 4 * units.GiB}
+	s := newRequestTestScheduler(map[string]*Host{
+		"host1": {ID: "host1", Capacity: capacity, client: NewFakeHostClient("host1", false)},
+		"host2": {ID: "host2", Capacity: capacity, client: NewFakeHostClient("host2", false)},
+	})
+	memory := func(size int64) resource.Resources {
+		return resource.Resources{resource.TypeMemory: {Request: typeconv.Int64Ptr(size)}}
+	}
+
+	// fill host1 with background jobs and host2 with a user job
+	addJob := func(hostID, partition string, size int64, startedAt time.Time) *Job {
+		return s.jobs.Add(&Job{
+			ID:        random.UUID(),
+			HostID:    hostID,
+			JobID:     cluster.GenerateJobID(hostID, ""),
+			State:     JobStateRunning,
+			StartedAt: startedAt,
+			partition: partition,
+			resources: memory(size),
+		})
+	}
+	bg1 := addJob("host1", "background", 2*units.GiB, time.Now().Add(-time.Minute))
+	bg2 := addJob("host1", "background", 2*units.GiB, time.Now())
+	addJob("host2", "user", 3*units.GiB, time.Now())
+
+	formation := NewFormation(&ct.ExpandedFormation{
+		App: &ct.App{ID: "app"},
+		Release: &ct.Release{ID: "release", Processes: map[string]ct.ProcessType{
+			"web":   {Resources: memory(2 * units.GiB)},
+			"batch": {Resources: memory(2 * units.GiB), Partition: ct.PartitionTypeBackground},
+		}},
+		Artifacts: []*ct.Artifact{{}},
+	})
+	place := func(typ string) (*PlacementRequest, error) {
+		job := s.jobs.Add(&Job{ID: random.UUID(), Formation: formation, Type: typ, State: JobStatePending})
+		req := &PlacementRequest{Job: job, Err: make(chan error, 1)}
+		s.HandlePlacementRequest(req)
+		return req, <-req.Err
+	}
+
+	// a user job preempts the most recent background job
+	req, err := place("web")
+	c.Assert(err, IsNil)
+	c.Assert(req.Host.ID, Equals, "host1")
+	c.Assert(bg1.State, Equals, JobStateRunning)
+	c.Assert(bg2.State, Equals, JobStateStopping)
+	c.Assert(bg2.PreemptedBy, Equals, req.Job.ID)
+	c.Assert(*bg2.ControllerJob().PreemptedBy, Equals, req.Job.ID)
+
+	// a background job can't preempt anything so is blocked
+	req, err = place("batch")
+	c.Assert(err, Equals, ErrNoHostCapacity)
+	c.Assert(req.Job.State, Equals, JobStateBlocked)
+}
+
+func (TestSuite) TestJobPlacementPreemptionDisruptionBudget(c *C) {
+	capacity := map[resource.Type]int64{resource.TypeMemory: 4 * units.GiB}
+	s := newRequestTestScheduler(map[string]*Host{
+		"host1": {ID: "host1", Capacity: capacity, client: NewFakeHostClient("host1", false)},
+	})
+	memory := func(size int64) resource.Resources {
+		return resource.Resources{resource.TypeMemory: {Request: typeconv.Int64Ptr(size)}}
+	}
+
+	// fill host1 with background jobs whose process type has a disruption
+	// budget
+	budget := &ct.DisruptionBudget{MinAvailable: 2}
+	background := s.formations.Add(NewFormation(&ct.ExpandedFormation{
+		App: &ct.App{ID: "background"},
+		Release: &ct.Release{ID: "background-release", Processes: map[string]ct.ProcessType{
+			"batch": {Resources: memory(2 * units.GiB), Partition: ct.PartitionTypeBackground, DisruptionBudget: budget},
+		}},
+		Artifacts: []*ct.Artifact{{}},
+		Processes: map[string]int{"batch": 2},
+	}))
+	var bg []*Job
+	for i := 0; i < 2; i++ {
+		bg = append(bg, s.jobs.Add(&Job{
+			ID:        random.UUID(),
+			Type:      "batch",
+			AppID:     "background",
+			ReleaseID: "background-release",
+			Formation: background,
+			HostID:    "host1",
+			JobID:     cluster.GenerateJobID("host1", ""),
+			State:     JobStateRunning,
+			StartedAt: time.Now().Add(time.Duration(i) * time.Second),
+		}))
+	}
+	countState := func(state JobState) int {
+		n := 0
+		for _, job := range bg {
+			if job.State == state {
+				n++
+			}
+		}
+		return n
+	}
+
+	formation := NewFormation(&ct.ExpandedFormation{
+		App: &ct.App{ID: "app"},
+		Release: &ct.Release{ID: "release", Processes: map[string]ct.ProcessType{
+			"small": {Resources: memory(2 * units.GiB)},
+			"large": {Resources: memory(4 * units.GiB)},
+		}},
+		Artifacts: []*ct.Artifact{{}},
+	})
+	place := func(typ string) (*PlacementRequest, error) {
+		job := s.jobs.Add(&Job{ID: random.UUID(), Formation: formation, Type: typ, State: JobStatePending})
+		req := &PlacementRequest{Job: job, Err: make(chan error, 1)}
+		s.HandlePlacementRequest(req)
+		return req, <-req.Err
+	}
+
+	// min_available stops any background jobs being preempted
+	req, err := place("small")
+	c.Assert(err, Equals, ErrNoHostCapacity)
+	c.Assert(req.Job.State, Equals, JobStateBlocked)
+	c.Assert(countState(JobStateRunning), Equals, 2)
+
+	// max_unavailable stops more than one background job being preempted
+	// at a time, even when preempting both would make room
+	*budget = ct.DisruptionBudget{MaxUnavailable: 1}
+	req, err = place("large")
+	c.Assert(err, Equals, ErrNoHostCapacity)
+	c.Assert(req.Job.State, Equals, JobStateBlocked)
+	c.Assert(countState(JobStateRunning), Equals, 2)
+
+	// a job which only requires one background job to be preempted is
+	// placed
+	req, err = place("small")
+	c.Assert(err, IsNil)
+	c.Assert(req.Host.ID, Equals, "host1")
+	c.Assert(countState(JobStateStopping), Equals, 1)
+	c.Assert(bg[1].PreemptedBy, Equals, req.Job.ID)
+}
+
+func (TestSuite) TestDryRun(c *C) {
+	capacity := map[resource.Type]int64{resource.TypeMemory: 4 * units.GiB}
+	s := newRequestTestScheduler(map[string]*Host{
+		"host1": {ID: "host1", Tags: map[string]string{"disk": "ssd"}, Capacity: capacity, client: NewFakeHostClient("host1", false)},
+		"host2": {ID: "host2", Capacity: capacity, client: NewFakeHostClient("host2", false)},
+	})
+	app := &ct.App{ID: "app"}
+	release := &ct.Release{ID: "release", Processes: map[string]ct.ProcessType{
+		"web": {Resources: resource.Resources{resource.TypeMemory: {Request: typeconv.Int64Ptr(units.GiB)}}},
+	}}
+	formation := s.formations.Add(NewFormation(&ct.ExpandedFormation{
+		App:       app,
+		Release:   release,
+		Processes: map[string]int{"web": 2},
+	}))
+	for _, hostID := range []string{"host1", "host2"} {
+		s.jobs.Add(&Job{
+			ID:        random.UUID(),
+			Type:      "web",
+			AppID:     app.ID,
+			ReleaseID: release.ID,
+			Formation: formation,
+			HostID:    hostID,
+			JobID:     cluster.GenerateJobID(hostID, ""),
+			State:     JobStateRunning,
+			StartedAt: time.Now(),
+		})
+	}
+
+	dryRun := func(change *ct.SchedulerDryRunRequest) *ct.SchedulerDryRunResult {
+		req := NewDryRunRequest(change)
+		s.HandleDryRunRequest(req)
+		c.Assert(req.Err, IsNil)
+		return req.Result
+	}
+	scale := func(processes map[string]int, tags map[string]map[string]string) *ct.SchedulerDryRunRequest {
+		return &ct.SchedulerDryRunRequest{Formations: []*ct.ExpandedFormation{{
+			App:       app,
+			Release:   release,
+			Processes: processes,
+			Tags:      tags,
+		}}}
+	}
+	hostIDs := func(jobs []*ct.SchedulerDryRunJob) []string {
+		ids := make([]string, len(jobs))
+		for i, job := range jobs {
+			ids[i] = job.HostID
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	// scaling up places jobs across both hosts until they are full
+	res := dryRun(scale(map[string]int{"web": 10}, nil))
+	c.Assert(hostIDs(res.Placements), DeepEquals, []string{"host1", "host1", "host1", "host2", "host2", "host2"})
+	c.Assert(res.Blocked, HasLen, 2)
+	c.Assert(res.Blocked[0].Reason, Equals, "no hosts found with capacity for job resource requests (requested memory=1GB)")
+	c.Assert(res.Stopped, HasLen, 0)
+
+	// the scheduler's state is left unchanged
+	c.Assert(s.jobs, HasLen, 2)
+	c.Assert(s.formations.Get(app.ID, release.ID).Processes, DeepEquals, map[string]int{"web": 2})
+	for _, job := range s.jobs {
+		c.Assert(job.State, Equals, JobStateRunning)
+		c.Assert(job.Formation, Equals, formation)
+	}
+
+	// scaling down stops a job
+	res = dryRun(scale(map[string]int{"web": 1}, nil))
+	c.Assert(res.Placements, HasLen, 0)
+	c.Assert(res.Stopped, HasLen, 1)
+	c.Assert(res.Stopped[0].Reason, Equals, "formation scaled down")
+
+	// changing tags moves the job on host2 to host1
+	res = dryRun(scale(map[string]int{"web": 2}, map[string]map[string]string{"web": {"disk": "ssd"}}))
+	c.Assert(hostIDs(res.Stopped), DeepEquals, []string{"host2"})
+	c.Assert(res.Stopped[0].Reason, Equals, "job tags do not match host")
+	c.Assert(hostIDs(res.Placements), DeepEquals, []string{"host1"})
+
+	// tags which don't match any hosts block jobs
+	res = dryRun(scale(map[string]int{"web": 3}, map[string]map[string]string{"web": {"disk": "hdd"}}))
+	c.Assert(res.Stopped, HasLen, 2)
+	c.Assert(res.Placements, HasLen, 0)
+	c.Assert(res.Blocked, HasLen, 3)
+	c.Assert(res.Blocked[0].Reason, Equals, ErrNoHostsMatchTags.Error())
+
+	// removing a host replaces its jobs on the remaining hosts
+	res = dryRun(&ct.SchedulerDryRunRequest{RemoveHosts: []string{"host2"}})
+	c.Assert(hostIDs(res.Stopped), DeepEquals, []string{"host2"})
+	c.Assert(res.Stopped[0].Reason, Equals, "host removed")
+	c.Assert(hostIDs(res.Placements), DeepEquals, []string{"host1"})
+	c.Assert(s.hosts, HasLen, 2)
+
+	// removing an unknown host is an error
+	req := NewDryRunRequest(&ct.SchedulerDryRunRequest{RemoveHosts: []string{"host3"}})
+	s.HandleDryRunRequest(req)
+	c.Assert(req.Err, NotNil)
+}
+
+func (TestSuite) TestDrainHost(c *C) {
+	s := newRequestTestScheduler(map[string]*Host{
+		"host1": {ID: "host1", Cordoned: true, client: NewFakeHostClient("host1", false)},
+		"host2": {ID: "host2", client: NewFakeHostClient("host2", false)},
+	})
+	formation := NewFormation(&ct.ExpandedFormation{
+		App:       &ct.App{ID: "app"},
+		Release:   &ct.Release{ID: "release", Processes: map[string]ct.ProcessType{"web": {}}},
+		Artifacts: []*ct.Artifact{{}},
+		Processes: map[string]int{"web": 1},
+	})
+	job := s.jobs.Add(&Job{
+		ID:        random.UUID(),
+		Type:      "web",
+		AppID:     "app",
+		ReleaseID: "release",
+		Formation: formation,
+		HostID:    "host1",
+		JobID:     cluster.GenerateJobID("host1", ""),
+		State:     JobStateRunning,
+	})
+	drainHost := func(hostID string) (*host.DrainStatus, error) {
+		req := NewDrainRequest(hostID, true)
+		s.HandleDrainRequest(req)
+		return req.Status, req.Err
+	}
+	eventTypes := func() []host.DrainEventType {
+		req := NewDrainRequest("host1", false)
+		s.HandleDrainRequest(req)
+		c.Assert(req.Err, IsNil)
+		types := make([]host.DrainEventType, len(req.Status.Events))
+		for i, e := range req.Status.Events {
+			types[i] = e.Type
+		}
+		return types
+	}
+
+	// only cordoned hosts can be drained
+	_, err := drainHost("host2")
+	c.Assert(err, Equals, ErrHostNotCordoned)
+
+	// draining the host marks the job as evacuating so that a replacement
+	// is started
+	status, err := drainHost("host1")
+	c.Assert(err, IsNil)
+	c.Assert(status.State, Equals, host.DrainStateDraining)
+	c.Assert(job.Evacuating, Equals, true)
+	c.Assert(s.formationDiff(formation), DeepEquals, Processes{"web": 1})
+	c.Assert(eventTypes(), DeepEquals, []host.DrainEventType{host.DrainEventStarted, host.DrainEventJobMoving})
+
+	// the replacement is not placed on the cordoned host
+	replacement := s.jobs.Add(&Job{ID: random.UUID(), Type: "web", Formation: formation, State: JobStatePending})
+	req := &PlacementRequest{Job: replacement, Err: make(chan error, 1)}
+	s.HandlePlacementRequest(req)
+	c.Assert(<-req.Err, IsNil)
+	c.Assert(req.Host.ID, Equals, "host2")
+
+	// the job keeps running until the replacement is running
+	replacement.State = JobStateStarting
+	s.DrainHosts()
+	c.Assert(job.State, Equals, JobStateRunning)
+	replacement.State = JobStateRunning
+	s.DrainHosts()
+	c.Assert(job.State, Equals, JobStateStopping)
+
+	// the drain completes once the job has stopped
+	job.State = JobStateStopped
+	s.DrainHosts()
+	c.Assert(eventTypes(), DeepEquals, []host.DrainEventType{
+		host.DrainEventStarted,
+		host.DrainEventJobMoving,
+		host.DrainEventJobStopping,
+		host.DrainEventJobStopped,
+		host.DrainEventComplete,
+	})
+	c.Assert(s.drains["host1"].status.State, Equals, host.DrainStateComplete)
+	c.Assert(s.formationDiff(formation).IsEmpty(), Equals, true)
+
+	// uncordoning a host cancels its drain
+	s.jobs.Add(&Job{
+		ID:        random.UUID(),
+		Type:      "web",
+		Formation: formation,
+		HostID:    "host1",
+		JobID:     cluster.GenerateJobID("host1", ""),
+		State:     JobStateRunning,
+	})
+	formation.Processes["web"] = 2
+	_, err = drainHost("host1")
+	c.Assert(err, IsNil)
+	s.hosts["host1"].Cordoned = false
+	s.DrainHosts()
+	c.Assert(s.drains["host1"].status.State, Equals, host.DrainStateCancelled)
+	c.Assert(s.formationDiff(formation).IsEmpty(), Equals, true)
+}
+
+func (TestSuite) TestSyncDrains(c *C) {
+	s := newRequestTestScheduler(map[string]*Host{
+		"host1": {ID: "host1", Cordoned: true, client: NewFakeHostClient("host1", false)},
+		"host2": {ID: "host2", client: NewFakeHostClient("host2", false)},
+	})
+	formation := NewFormation(&ct.ExpandedFormation{
+		App:       &ct.App{ID: "app"},
+		Release:   &ct.Release{ID: "release", Processes: map[string]ct.ProcessType{"web": {}}},
+		Artifacts: []*ct.Artifact{{}},
+		Processes: map[string]int{"web": 1},
+	})
+	job := s.jobs.Add(&Job{
+		ID:        random.UUID(),
+		Type:      "web",
+		AppID:     "app",
+		ReleaseID: "release",
+		Formation: formation,
+		HostID:    "host1",
+		JobID:     cluster.GenerateJobID("host1", ""),
+		State:     JobStateRunning,
+	})
+
+	// starting a drain persists its status to the controller
+	req := NewDrainRequest("host1", true)
+	s.HandleDrainRequest(req)
+	c.Assert(req.Err, IsNil)
+	c.Assert(len(s.controllerPersist), Equals, 1)
+	status, ok := (<-s.controllerPersist).(*host.DrainStatus)
+	c.Assert(ok, Equals, true)
+	c.Assert(status.State, Equals, host.DrainStateDraining)
+	c.Assert(s.PutHostDrain(status), IsNil)
+
+	// a new leader continues moving the job off the host
+	s.drains = make(map[string]*drain)
+	job.Evacuating = false
+	s.SyncDrains()
+	d, ok := s.drains["host1"]
+	c.Assert(ok, Equals, true)
+	c.Assert(d.status.State, Equals, host.DrainStateDraining)
+	c.Assert(d.current, Equals, job)
+	c.Assert(job.Evacuating, Equals, true)
+	c.Assert(s.formationDiff(formation), DeepEquals, Processes{"web": 1})
+
+	// progressing the drain persists the updated status
+	s.jobs.Add(&Job{ID: random.UUID(), Type: "web", Formation: formation, HostID: "host2", State: JobStateRunning})
+	s.DrainHosts()
+	c.Assert(job.State, Equals, JobStateStopping)
+	status = nil
+	for len(s.controllerPersist) > 0 {
+		if v, ok := (<-s.controllerPersist).(*host.DrainStatus); ok {
+			status = v
+		}
+	}
+	c.Assert(status, NotNil)
+	c.Assert(status.Events[len(status.Events)-1].Type, Equals, host.DrainEventJobStopping)
+}
+
+func (TestSuite) TestRestartPolicy(c *C) {
+	s := newRequestTestScheduler(nil)
+	release := &ct.Release{ID: "release", Processes: map[string]ct.ProcessType{"web": {}}}
+	formation := NewFormation(&ct.ExpandedFormation{
+		App:       &ct.App{ID: "app"},
+		Release:   release,
+		Artifacts: []*ct.Artifact{{}},
+		Processes: map[string]int{"web": 1},
+	})
+	var running *Job
+	reset := func(policy *ct.RestartPolicy) {
+		release.Processes["web"] = ct.ProcessType{RestartPolicy: policy}
+		formation.ResetRestarts()
+		s.jobs = make(Jobs)
+		running = s.jobs.Add(&Job{ID: random.UUID(), Type: "web", Formation: formation, State: JobStateRunning})
+	}
+
+	// stop stops the running job with the given exit status, returning its
+	// replacement if it was restarted
+	stop := func(exitStatus int) *Job {
+		running.exitStatus = &exitStatus
+		s.handleJobStatus(running, host.StatusDone)
+		for _, job := range s.jobs {
+			if job.State == JobStatePending {
+				job.restartTimer.Stop()
+				job.State = JobStateRunning
+				running = job
+				return job
+			}
+		}
+		return nil
+	}
+
+	// jobs with a never restart policy are not restarted
+	reset(&ct.RestartPolicy{Type: ct.RestartPolicyNever})
+	c.Assert(stop(1), IsNil)
+	c.Assert(s.formationDiff(formation).IsEmpty(), Equals, true)
+
+	// jobs with an on-failure restart policy are only restarted if they fail
+	reset(&ct.RestartPolicy{Type: ct.RestartPolicyOnFailure, BackoffBase: 60, BackoffCap: 60})
+	c.Assert(stop(1), NotNil)
+	c.Assert(stop(0), IsNil)
+	c.Assert(s.formationDiff(formation).IsEmpty(), Equals, true)
+
+	// process types which exceed their restart budget are marked as crash
+	// looping until they are reset
+	reset(&ct.RestartPolicy{Type: ct.RestartPolicyAlways, MaxRestarts: 2, Window: 60, BackoffBase: 60, BackoffCap: 60})
+	c.Assert(stop(1), NotNil)
+	c.Assert(stop(1), NotNil)
+	job := running
+	c.Assert(stop(1), IsNil)
+	c.Assert(job.CrashLoopReason, Equals, "restarted 2 times in 1m0s")
+	c.Assert(job.ControllerJob().CrashLoopReason, NotNil)
+	c.Assert(formation.CrashLooping, DeepEquals, map[string]string{"web": "restarted 2 times in 1m0s"})
+	c.Assert(s.formationDiff(formation).IsEmpty(), Equals, true)
+	formation.ResetRestarts()
+	c.Assert(s.formationDiff(formation), DeepEquals, Processes{"web": 1})
+
+	// preempted jobs are always restarted
+	reset(&ct.RestartPolicy{Type: ct.RestartPolicyNever, BackoffBase: 60, BackoffCap: 60})
+	running.PreemptedBy = random.UUID()
+	c.Assert(stop(0), NotNil)
+}
+
+func (TestSuite) TestRestartBackoff(c *C) {
+	s := newRequestTestScheduler(nil)
+	for _, t := range []struct {
+		policy   *ct.RestartPolicy
+		restarts uint
+		backoff  time.Duration
+	}{
+		{nil, 0, 0},
+		{nil, 5, 10 * time.Second},
+		{nil, 15, 30 * time.Second},
+		{&ct.RestartPolicy{MaxRestarts: 3}, 15, 30 * time.Second},
+		{&ct.RestartPolicy{BackoffBase: 1, BackoffCap: 10}, 0, time.Second},
+		{&ct.RestartPolicy{BackoffBase: 1, BackoffCap: 10}, 2, 4 * time.Second},
+		{&ct.RestartPolicy{BackoffBase: 1, BackoffCap: 10}, 4, 10 * time.Second},
+		{&ct.RestartPolicy{BackoffBase: 1, BackoffCap: 10}, 100, 10 * time.Second},
+	} {
+		c.Assert(s.getBackoffDuration(t.policy, t.restarts), Equals, t.backoff)
+	}
+}
+
+func (TestSuite) TestSyncRestarts(c *C) {
+	s := newRequestTestScheduler(nil)
+	policy := &ct.RestartPolicy{Type: ct.RestartPolicyAlways, MaxRestarts: 2, Window: 60, BackoffBase: 60, BackoffCap: 60}
+	release := &ct.Release{ID: "release", Processes: map[string]ct.ProcessType{"web": {RestartPolicy: policy}, "worker": {RestartPolicy: policy}}}
+	formation := s.formations.Add(NewFormation(&ct.ExpandedFormation{
+		App:       &ct.App{ID: "app"},
+		Release:   release,
+		Artifacts: []*ct.Artifact{{}},
+		Processes: map[string]int{"web": 1, "worker": 1},
+	}))
+
+	// record the history of a previous leader restarting web jobs and
+	// giving up on a crash looping worker job
+	now := time.Now()
+	putJob := func(typ string, state ct.JobState, restarts int32, created time.Time) *ct.Job {
+		id := random.UUID()
+		job := &ct.Job{
+			ID:        cluster.GenerateJobID("host1", id),
+			UUID:      id,
+			AppID:     "app",
+			ReleaseID: "release",
+			Type:      typ,
+			State:     state,
+			CreatedAt: typeconv.TimePtr(created),
+		}
+		if restarts > 0 {
+			job.Restarts = typeconv.Int32Ptr(restarts)
+		}
+		c.Assert(s.PutJob(job), IsNil)
+		return job
+	}
+	putJob("web", ct.JobStateDown, 0, now.Add(-3*time.Minute))
+	putJob("web", ct.JobStateDown, 1, now.Add(-2*time.Minute))
+	putJob("web", ct.JobStateDown, 2, now.Add(-30*time.Second))
+	pending := putJob("web", ct.JobStatePending, 3, now.Add(-time.Second))
+	pending.RunAt = typeconv.TimePtr(now.Add(time.Minute))
+	crashed := putJob("worker", ct.JobStateDown, 2, now.Add(-10*time.Second))
+	crashed.CrashLoopReason = typeconv.StringPtr("restarted 2 times in 1m0s")
+
+	s.SyncRestarts()
+
+	// the pending restart is rescheduled with its original backoff
+	job, ok := s.jobs[pending.UUID]
+	c.Assert(ok, Equals, true)
+	c.Assert(job.State, Equals, JobStatePending)
+	c.Assert(job.Restarts, Equals, uint(3))
+	c.Assert(job.restartTimer, NotNil)
+	job.restartTimer.Stop()
+
+	// only restarts within the policy's window are counted
+	c.Assert(formation.restarts["web"], HasLen, 2)
+
+	// the crash looping process type is not restarted, and the pending
+	// restart counts towards the formation
+	c.Assert(formation.CrashLooping, DeepEquals, map[string]string{"worker": "restarted 2 times in 1m0s"})
+	c.Assert(s.formationDiff(formation).IsEmpty(), Equals, true)
+}
+
+func (TestSuite) TestDisruptionBudget(c *C) {
+	s := newRequestTestScheduler(map[string]*Host{
+		"host1": {ID: "host1", Cordoned: true, client: NewFakeHostClient("host1", false)},
+		"host2": {ID: "host2", client: NewFakeHostClient("host2", false)},
+	})
+	budget := &ct.DisruptionBudget{MaxUnavailable: 1}
+	formation := s.formations.Add(NewFormation(&ct.ExpandedFormation{
+		App:       &ct.App{ID: "app"},
+		Release:   &ct.Release{ID: "release", Processes: map[string]ct.ProcessType{"web": {DisruptionBudget: budget}}},
+		Artifacts: []*ct.Artifact{{}},
+		Processes: map[string]int{"web": 3},
+	}))
+	addJob := func(hostID string) *Job {
+		return s.jobs.Add(&Job{
+			ID:        random.UUID(),
+			Type:      "web",
+			AppID:     "app",
+			ReleaseID: "release",
+			Formation: formation,
+			HostID:    hostID,
+			JobID:     cluster.GenerateJobID(hostID, ""),
+			State:     JobStateRunning,
+		})
+	}
+	for i := 0; i < 3; i++ {
+		addJob("host2")
+	}
+	countState := func(state JobState) int {
+		n := 0
+		for _, job := range s.jobs {
+			if job.State == state {
+				n++
+			}
+		}
+		return n
+	}
+	scale := func(n int) {
+		formation.Processes["web"] = n
+		s.handleFormationDiff(formation, s.formationDiff(formation))
+	}
+	stopStopping := func() {
+		for _, job := range s.jobs {
+			if job.State == JobStateStopping {
+				job.State = JobStateStopped
+			}
+		}
+	}
+
+	// scaling down only stops max_unavailable jobs at a time
+	scale(1)
+	c.Assert(countState(JobStateStopping), Equals, 1)
+	scale(1)
+	c.Assert(countState(JobStateStopping), Equals, 1)
+	stopStopping()
+	scale(1)
+	c.Assert(countState(JobStateStopping), Equals, 1)
+	stopStopping()
+	c.Assert(countState(JobStateRunning), Equals, 1)
+
+	// min_available stops jobs being stopped if there would be fewer than
+	// expected, but not if the process type is scaled down
+	*budget = ct.DisruptionBudget{MinAvailable: 2}
+	formation.Processes["web"] = 2
+	c.Assert(s.canDisrupt(addJob("host2")), Equals, false)
+	scale(1)
+	c.Assert(countState(JobStateStopping), Equals, 1)
+
+	// draining a host waits for the budget before stopping the evacuated
+	// job
+	*budget = ct.DisruptionBudget{MaxUnavailable: 1}
+	s.jobs = make(Jobs)
+	formation.Processes["web"] = 2
+	job := addJob("host1")
+	addJob("host2")
+	stopping := addJob("host2")
+	stopping.State = JobStateStopping
+	req := NewDrainRequest("host1", true)
+	s.HandleDrainRequest(req)
+	c.Assert(req.Err, IsNil)
+	c.Assert(job.Evacuating, Equals, true)
+	addJob("host2")
+	s.DrainHosts()
+	c.Assert(job.State, Equals, JobStateRunning)
+	events := s.drains["host1"].status.Events
+	c.Assert(events[len(events)-1].Type, Equals, host.DrainEventJobWaiting)
+	stopping.State = JobStateStopped
+	s.DrainHosts()
+	c.Assert(job.State, Equals, JobStateStopping)
+}
+
 func (TestSuite) TestScaleCriticalApp(c *C) {
 	s := runTestScheduler(c, nil, true)
 	defer s.Stop()
@@ -0,0 +1,122 @@
+package main
+
+import (
+	ct "github.com/flynn/flynn/controller/types"
+)
+
+// spreadDomain tracks the number of jobs of a given formation and type which
+// are running in each value of a spread constraint's host tag
+type spreadDomain struct {
+	ct.SpreadConstraint
+
+	counts map[string]int
+}
+
+// min returns the number of jobs in the least populated tag value
+func (d *spreadDomain) min() int {
+	min := -1
+	for _, n := range d.counts {
+		if min == -1 || n < min {
+			min = n
+		}
+	}
+	if min == -1 {
+		return 0
+	}
+	return min
+}
+
+// max returns the number of jobs in the most populated tag value
+func (d *spreadDomain) max() int {
+	max := 0
+	for _, n := range d.counts {
+		if n > max {
+			max = n
+		}
+	}
+	return max
+}
+
+// allows checks whether placing another job on the given host would keep
+// the skew within the constraint's MaxSkew
+func (d *spreadDomain) allows(host *Host) bool {
+	v, ok := host.Tags[d.TagKey]
+	if !ok {
+		return false
+	}
+	maxSkew := d.MaxSkew
+	if maxSkew <= 0 {
+		maxSkew = 1
+	}
+	return d.counts[v]+1-d.min() <= maxSkew
+}
+
+// score returns the number of jobs in the host's tag value, treating hosts
+// which lack the tag as being more populated than any tag value
+func (d *spreadDomain) score(host *Host) int {
+	if v, ok := host.Tags[d.TagKey]; ok {
+		return d.counts[v]
+	}
+	return d.max() + 1
+}
+
+// spreadDomains returns a spreadDomain for each of the spread constraints of
+// the given formation and type, counting jobs which have been placed on hosts
+// the type can run on (i.e. hosts which are not shutting down or cordoned and
+// which match the formation's tags for the type)
+func (s *Scheduler) spreadDomains(f *Formation, typ string) []*spreadDomain {
+	if f == nil || f.ExpandedFormation == nil || f.Release == nil {
+		return nil
+	}
+	constraints := f.Release.Processes[typ].Spread
+	if len(constraints) == 0 {
+		return nil
+	}
+	counts := s.jobs.GetHostJobCounts(f.key(), typ)
+	job := &Job{Formation: f, Type: typ}
+	domains := make([]*spreadDomain, len(constraints))
+	for i, constraint := range constraints {
+		domain := &spreadDomain{
+			SpreadConstraint: constraint,
+			counts:           make(map[string]int),
+		}
+		for _, host := range s.hosts {
+			if host.Shutdown || host.Cordoned || !job.TagsMatchHost(host) {
+				continue
+			}
+			if v, ok := host.Tags[constraint.TagKey]; ok {
+				domain.counts[v] += counts[host.ID]
+			}
+		}
+		domains[i] = domain
+	}
+	return domains
+}
+
+// spreadAllowed checks whether placing another job on the given host would
+// satisfy all hard spread constraints
+func spreadAllowed(domains []*spreadDomain, host *Host) bool {
+	for _, domain := range domains {
+		if domain.Hard && !domain.allows(host) {
+			return false
+		}
+	}
+	return true
+}
+
+// spreadScore returns the sum of the domain scores for the given host, which
+// is used to prefer placing jobs on hosts in less populated tag values, and
+// stopping jobs on hosts in more populated tag values
+func spreadScore(domains []*spreadDomain, host *Host) int {
+	score := 0
+	for _, domain := range domains {
+		score += domain.score(host)
+	}
+	return score
+}
+
+// spreadAllows checks whether placing the given job on the given host would
+// satisfy the job's hard spread constraints
+func (s *Scheduler) spreadAllows(job *Job, host *Host) bool {
+	return spreadAllowed(s.spreadDomains(job.Formation, job.Type), host)
+}
@@ -60,6 +60,9 @@ func schemaForType(thing interface{}) *jsonschema.Schema {
 	if name == "scalerequest" {
 		name = "scale_request"
 	}
+	if name == "autoscalepolicy" {
+		name = "autoscale_policy"
+	}
 	if name == "appupdate" {
 		name = "app"
 	}
@@ -0,0 +1,239 @@
+// Package secrets implements envelope encryption of secret release env
+// values.
+//
+// Each value is encrypted with a random data key using AES-256-GCM, and the
+// data key is encrypted with a cluster key from a Keyring. Rotating the
+// cluster key therefore only requires re-encrypting the data keys rather than
+// the values themselves (see Keyring.Rewrap).
+//
+// Encrypted values are bound to the ID of the app they belong to so that they
+// cannot be decrypted in another app's jobs by copying them into another
+// app's release.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Prefix is the prefix of encrypted values, which is followed by the ID of
+// the cluster key, the encrypted data key and the encrypted value, separated
+// by colons
+const Prefix = "flynn-secret:v1:"
+
+var ErrNoKeyring = errors.New("secrets: no cluster keys are configured (set SECRETS_KEYS on the controller)")
+
+// IsEncrypted returns whether the value is an encrypted secret
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// Keyring is a set of cluster keys used to encrypt data keys, with the active
+// key used to encrypt new data keys and the others only used to decrypt data
+// keys which have not yet been rewrapped with the active key
+type Keyring struct {
+	active string
+	keys   map[string][]byte
+}
+
+// ParseKeyring parses a comma separated list of cluster keys of the form
+// "<id>:<hex encoded 32 byte key>", the first of which is the active key,
+// returning a nil Keyring if s is empty
+func ParseKeyring(s string) (*Keyring, error) {
+	if s == "" {
+		return nil, nil
+	}
+	k := &Keyring{keys: make(map[string][]byte)}
+	for _, entry := range strings.Split(s, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("secrets: invalid key %q, expected <id>:<hex key>", entry)
+		}
+		id := parts[0]
+		key, err := hex.DecodeString(parts[1])
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("secrets: key %q must be 32 hex encoded bytes", id)
+		}
+		if _, ok := k.keys[id]; ok {
+			return nil, fmt.Errorf("secrets: duplicate key %q", id)
+		}
+		if k.active == "" {
+			k.active = id
+		}
+		k.keys[id] = key
+	}
+	return k, nil
+}
+
+// ActiveKeyID returns the ID of the key used to encrypt new data keys
+func (k *Keyring) ActiveKeyID() string {
+	if k == nil {
+		return ""
+	}
+	return k.active
+}
+
+type envelope struct {
+	keyID   string
+	dataKey []byte
+	value   []byte
+}
+
+func parseEnvelope(s string) (*envelope, error) {
+	parts := strings.Split(strings.TrimPrefix(s, Prefix), ":")
+	if !IsEncrypted(s) || len(parts) != 3 {
+		return nil, errors.New("secrets: invalid encrypted value")
+	}
+	e := &envelope{keyID: parts[0]}
+	var err error
+	if e.dataKey, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
+		return nil, errors.New("secrets: invalid encrypted data key")
+	}
+	if e.value, err = base64.RawURLEncoding.DecodeString(parts[2]); err != nil {
+		return nil, errors.New("secrets: invalid encrypted value")
+	}
+	return e, nil
+}
+
+func (e *envelope) String() string {
+	return Prefix + e.keyID + ":" + base64.RawURLEncoding.EncodeToString(e.dataKey) + ":" + base64.RawURLEncoding.EncodeToString(e.value)
+}
+
+func seal(key, plaintext, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(plaintext)+gcm.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, data), nil
+}
+
+func open(key, ciphertext, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("secrets: ciphertext too short")
+	}
+	return gcm.Open(nil, ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():], data)
+}
+
+// Encrypt encrypts a value of the given app's env with a new data key
+func (k *Keyring) Encrypt(appID, value string) (string, error) {
+	if k == nil {
+		return "", ErrNoKeyring
+	}
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", err
+	}
+	e := &envelope{keyID: k.active}
+	var err error
+	if e.value, err = seal(dataKey, []byte(value), []byte(appID)); err != nil {
+		return "", err
+	}
+	if e.dataKey, err = seal(k.keys[k.active], dataKey, nil); err != nil {
+		return "", err
+	}
+	return e.String(), nil
+}
+
+func (k *Keyring) openDataKey(e *envelope) ([]byte, error) {
+	if k == nil {
+		return nil, ErrNoKeyring
+	}
+	key, ok := k.keys[e.keyID]
+	if !ok {
+		return nil, fmt.Errorf("secrets: unknown cluster key %q", e.keyID)
+	}
+	dataKey, err := open(key, e.dataKey, nil)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: error decrypting data key with cluster key %q", e.keyID)
+	}
+	return dataKey, nil
+}
+
+// Decrypt decrypts an encrypted value of the given app's env
+func (k *Keyring) Decrypt(appID, value string) (string, error) {
+	e, err := parseEnvelope(value)
+	if err != nil {
+		return "", err
+	}
+	dataKey, err := k.openDataKey(e)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dataKey, e.value, []byte(appID))
+	if err != nil {
+		return "", errors.New("secrets: error decrypting value, it may belong to another app")
+	}
+	return string(plaintext), nil
+}
+
+// DecryptEnv decrypts the encrypted values of the given app's env in place
+func (k *Keyring) DecryptEnv(appID string, env map[string]string) error {
+	for name, value := range env {
+		if !IsEncrypted(value) {
+			continue
+		}
+		plaintext, err := k.Decrypt(appID, value)
+		if err != nil {
+			return fmt.Errorf("error decrypting env var %s: %s", name, err)
+		}
+		env[name] = plaintext
+	}
+	return nil
+}
+
+// NeedsRewrap returns whether the value's data key is not encrypted with the
+// active cluster key
+func (k *Keyring) NeedsRewrap(value string) bool {
+	if k == nil {
+		return false
+	}
+	e, err := parseEnvelope(value)
+	return err == nil && e.keyID != k.active
+}
+
+// Rewrap re-encrypts the value's data key with the active cluster key,
+// leaving the encrypted value itself unchanged
+func (k *Keyring) Rewrap(value string) (string, error) {
+	if k == nil {
+		return "", ErrNoKeyring
+	}
+	e, err := parseEnvelope(value)
+	if err != nil {
+		return "", err
+	}
+	if e.keyID == k.active {
+		return value, nil
+	}
+	dataKey, err := k.openDataKey(e)
+	if err != nil {
+		return "", err
+	}
+	e.keyID = k.active
+	if e.dataKey, err = seal(k.keys[k.active], dataKey, nil); err != nil {
+		return "", err
+	}
+	return e.String(), nil
+}
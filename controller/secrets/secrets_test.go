@@ -0,0 +1,108 @@
+package secrets
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	key1 = "k1:000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	key2 = "k2:1f1e1d1c1b1a191817161514131211100f0e0d0c0b0a09080706050403020100"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	keyring, err := ParseKeyring(key1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := keyring.Encrypt("app1", "s3cr3t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(encrypted) || strings.Contains(encrypted, "s3cr3t") {
+		t.Fatalf("expected value to be encrypted, got %q", encrypted)
+	}
+
+	env := map[string]string{"PASSWORD": encrypted, "PORT": "8080"}
+	if err := keyring.DecryptEnv("app1", env); err != nil {
+		t.Fatal(err)
+	}
+	if env["PASSWORD"] != "s3cr3t" || env["PORT"] != "8080" {
+		t.Fatalf("unexpected decrypted env: %v", env)
+	}
+
+	// values cannot be decrypted for other apps
+	if _, err := keyring.Decrypt("app2", encrypted); err == nil {
+		t.Fatal("expected error decrypting value for another app")
+	}
+
+	// values cannot be decrypted without a keyring
+	var none *Keyring
+	if _, err := none.Decrypt("app1", encrypted); err != ErrNoKeyring {
+		t.Fatalf("expected ErrNoKeyring, got %v", err)
+	}
+}
+
+func TestRewrap(t *testing.T) {
+	old, err := ParseKeyring(key1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := old.Encrypt("app1", "s3cr3t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// rotate to a new active key, keeping the old key for decryption
+	keyring, err := ParseKeyring(key2 + "," + key1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyring.ActiveKeyID() != "k2" {
+		t.Fatalf("expected active key k2, got %q", keyring.ActiveKeyID())
+	}
+	if !keyring.NeedsRewrap(encrypted) {
+		t.Fatal("expected value to need rewrapping")
+	}
+	rewrapped, err := keyring.Rewrap(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyring.NeedsRewrap(rewrapped) {
+		t.Fatal("expected rewrapped value to not need rewrapping")
+	}
+
+	// the rewrapped value can be decrypted once the old key is removed
+	rotated, err := ParseKeyring(key2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := rotated.Decrypt("app1", rewrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "s3cr3t" {
+		t.Fatalf("expected s3cr3t, got %q", value)
+	}
+	if _, err := rotated.Decrypt("app1", encrypted); err == nil {
+		t.Fatal("expected error decrypting value encrypted with a removed key")
+	}
+}
+
+func TestParseKeyring(t *testing.T) {
+	for _, s := range []string{
+		"k1",
+		":000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+		"k1:0001",
+		"k1:zz",
+		key1 + "," + key1,
+	} {
+		if _, err := ParseKeyring(s); err == nil {
+			t.Fatalf("expected error parsing %q", s)
+		}
+	}
+	keyring, err := ParseKeyring("")
+	if err != nil || keyring != nil {
+		t.Fatalf("expected nil keyring, got %v, %v", keyring, err)
+	}
+}
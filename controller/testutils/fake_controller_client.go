@@ -2,12 +2,14 @@ package testutils
 
 import (
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/flynn/flynn/controller/client"
 	ct "github.com/flynn/flynn/controller/types"
 	"github.com/flynn/flynn/controller/utils"
+	host "github.com/flynn/flynn/host/types"
 	"github.com/flynn/flynn/pkg/stream"
 )
 
@@ -18,6 +20,7 @@ type FakeControllerClient struct {
 	formationStreams map[chan<- *ct.ExpandedFormation]struct{}
 	jobs             map[string]*ct.Job
 	apps             map[string]*ct.App
+	drains           map[string]*host.DrainStatus
 	mtx              sync.Mutex
 }
 
@@ -29,6 +32,7 @@ func NewFakeControllerClient() *FakeControllerClient {
 		formationStreams: make(map[chan<- *ct.ExpandedFormation]struct{}),
 		apps:             make(map[string]*ct.App),
 		jobs:             make(map[string]*ct.Job),
+		drains:           make(map[string]*host.DrainStatus),
 	}
 }
 
@@ -263,6 +267,22 @@ func (c *FakeControllerClient) JobListActive() ([]*ct.Job, error) {
 	return list, nil
 }
 
+func (c *FakeControllerClient) JobListSince(since time.Time) ([]*ct.Job, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	list := make([]*ct.Job, 0, len(c.jobs))
+	for _, job := range c.jobs {
+		if job.UpdatedAt == nil || !job.UpdatedAt.Before(since) {
+			list = append(list, job)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt != nil && list[j].CreatedAt != nil && list[i].CreatedAt.Before(*list[j].CreatedAt)
+	})
+	return list, nil
+}
+
 func (c *FakeControllerClient) ListSinks() ([]*ct.Sink, error) {
 	return nil, nil
 }
@@ -284,6 +304,25 @@ func (c *FakeControllerClient) StreamVolumes(since *time.Time, ch chan *ct.Volum
 	return nil, nil
 }
 
+func (c *FakeControllerClient) PutHostDrain(status *host.DrainStatus) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.drains[status.HostID] = status
+	return nil
+}
+
+func (c *FakeControllerClient) HostDrainList() ([]*host.DrainStatus, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	list := make([]*host.DrainStatus, 0, len(c.drains))
+	for _, status := range c.drains {
+		list = append(list, status)
+	}
+	return list, nil
+}
+
 func NewRelease(id string, artifact *ct.Artifact, processes map[string]int) *ct.Release {
 	return NewReleaseOmni(id, artifact, processes, false)
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/host/resource"
 	"github.com/flynn/flynn/host/types"
 	"github.com/flynn/flynn/host/volume"
 	"github.com/flynn/flynn/pkg/cluster"
@@ -35,6 +36,8 @@ type FakeHostClient struct {
 	stopped          map[string]bool
 	attach           map[string]attachFunc
 	Jobs             map[string]host.ActiveJob
+	capacity         map[resource.Type]int64
+	cordoned         bool
 	volumes          map[string]*volume.Info
 	eventChannelsMtx sync.Mutex
 	eventChannels    map[chan<- *host.Event]struct{}
@@ -47,6 +50,18 @@ func (c *FakeHostClient) ID() string { return c.hostID }
 
 func (c *FakeHostClient) Tags() map[string]string { return nil }
 
+func (c *FakeHostClient) Capacity() map[resource.Type]int64 { return c.capacity }
+
+func (c *FakeHostClient) SetCapacity(capacity map[resource.Type]int64) {
+	c.capacity = capacity
+}
+
+func (c *FakeHostClient) Cordoned() bool { return c.cordoned }
+
+func (c *FakeHostClient) SetCordoned(cordoned bool) {
+	c.cordoned = cordoned
+}
+
 func (c *FakeHostClient) Attach(req *host.AttachReq, wait bool) (cluster.AttachClient, error) {
 	f, ok := c.attach[req.JobID]
 	if !ok {
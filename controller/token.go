@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/pkg/ctxhelper"
+	"github.com/flynn/flynn/pkg/httphelper"
+	"golang.org/x/net/context"
+)
+
+// Create a new API token, responding with its key
+func (c *controllerAPI) CreateToken(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	var token ct.Token
+	if err := httphelper.DecodeJSON(req, &token); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	if err := c.tokenRepo.Add(&token); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, &token)
+}
+
+// List API tokens
+func (c *controllerAPI) GetTokens(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	list, err := c.tokenRepo.List()
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, list)
+}
+
+// Revoke an API token
+func (c *controllerAPI) DeleteToken(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	params, _ := ctxhelper.ParamsFromContext(ctx)
+
+	token, err := c.tokenRepo.Get(params.ByName("token_id"))
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	if err := c.tokenRepo.Remove(token.ID); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, token)
+}
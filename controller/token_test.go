@@ -0,0 +1,129 @@
+package main
+
+import (
+	controller "github.com/flynn/flynn/controller/client"
+	ct "github.com/flynn/flynn/controller/types"
+	hh "github.com/flynn/flynn/pkg/httphelper"
+	. "github.com/flynn/go-check"
+)
+
+func (s *S) tokenClient(c *C, token *ct.Token) controller.Client {
+	c.Assert(s.c.CreateToken(token), IsNil)
+	c.Assert(token.Key, Not(Equals), "")
+	client, err := controller.NewClient(s.srv.URL, token.Key)
+	c.Assert(err, IsNil)
+	return client
+}
+
+func (s *S) TestTokenRoles(c *C) {
+	app := s.createTestApp(c, &ct.App{Name: "token-roles"})
+
+	// a read only token can get but not update the app
+	read := s.tokenClient(c, &ct.Token{Name: "read-only", Role: ct.TokenRoleRead})
+	gotApp, err := read.GetApp(app.Name)
+	c.Assert(err, IsNil)
+	c.Assert(gotApp.ID, Equals, app.ID)
+	err = read.UpdateApp(&ct.App{ID: app.ID, Meta: map[string]string{"foo": "bar"}})
+	c.Assert(hh.IsForbiddenError(err), Equals, true)
+
+	// manifest dry runs decrypt the app's env so need a deploy token
+	_, err = read.ManifestDryRun(app.ID, &ct.AppManifest{})
+	c.Assert(hh.IsForbiddenError(err), Equals, true)
+
+	// a deploy token can create releases but not tokens
+	deploy := s.tokenClient(c, &ct.Token{Name: "deploy", Role: ct.TokenRoleDeploy})
+	c.Assert(deploy.CreateRelease(app.ID, &ct.Release{}), IsNil)
+	_, err = deploy.ManifestDryRun(app.ID, &ct.AppManifest{})
+	c.Assert(err, IsNil)
+	err = deploy.CreateToken(&ct.Token{Name: "escalate", Role: ct.TokenRoleAdmin})
+	c.Assert(hh.IsForbiddenError(err), Equals, true)
+
+	// unknown roles are rejected
+	err = s.c.CreateToken(&ct.Token{Name: "unknown", Role: "root"})
+	c.Assert(hh.IsValidationError(err), Equals, true)
+}
+
+func (s *S) TestTokenScopes(c *C) {
+	app := s.createTestApp(c, &ct.App{Name: "token-scope"})
+	other := s.createTestApp(c, &ct.App{Name: "token-scope-other"})
+
+	// an app restricted token can only access its apps, which may be
+	// given by name
+	client := s.tokenClient(c, &ct.Token{Name: "app-scoped", Role: ct.TokenRoleDeploy, Apps: []string{app.Name}})
+	_, err := client.GetApp(app.Name)
+	c.Assert(err, IsNil)
+	_, err = client.GetApp(other.ID)
+	c.Assert(hh.IsForbiddenError(err), Equals, true)
+	c.Assert(client.CreateRelease(app.ID, &ct.Release{}), IsNil)
+	err = client.CreateRelease(other.ID, &ct.Release{})
+	c.Assert(hh.IsForbiddenError(err), Equals, true)
+	_, err = client.AppList()
+	c.Assert(hh.IsForbiddenError(err), Equals, true)
+
+	// a resource type restricted token can only access those resources
+	client = s.tokenClient(c, &ct.Token{Name: "releases-only", Role: ct.TokenRoleRead, ResourceTypes: []ct.TokenResourceType{ct.TokenResourceReleases}})
+	_, err = client.AppReleaseList(app.ID)
+	c.Assert(err, IsNil)
+	_, err = client.GetApp(app.ID)
+	c.Assert(hh.IsForbiddenError(err), Equals, true)
+}
+
+func (s *S) TestTokenDeploymentApproval(c *C) {
+	app := s.createTestApp(c, &ct.App{Name: "token-deployment-approval"})
+	release := s.createTestRelease(c, app.ID, &ct.Release{
+		Processes: map[string]ct.ProcessType{"web": {}},
+	})
+	c.Assert(s.c.PutFormation(&ct.Formation{
+		AppID:     app.ID,
+		ReleaseID: release.ID,
+		Processes: map[string]int{"web": 1},
+	}), IsNil)
+	defer s.c.DeleteFormation(app.ID, release.ID)
+	_, err := s.c.CreateDeployment(app.ID, release.ID)
+	c.Assert(err, IsNil)
+	app.SetDeployApproval(&ct.ApprovalConfig{Timeout: 600})
+	c.Assert(s.c.UpdateApp(&ct.App{ID: app.ID, Meta: app.Meta}), IsNil)
+
+	// a deploy token can create a gated deployment but not approve or
+	// reject it
+	deploy := s.tokenClient(c, &ct.Token{Name: "approval-deploy", Role: ct.TokenRoleDeploy})
+	newRelease := s.createTestRelease(c, app.ID, &ct.Release{})
+	d, err := deploy.CreateDeployment(app.ID, newRelease.ID)
+	c.Assert(err, IsNil)
+	c.Assert(d.Status, Equals, "pending_approval")
+	_, err = deploy.ApproveDeployment(d.ID, "")
+	c.Assert(hh.IsForbiddenError(err), Equals, true)
+	_, err = deploy.RejectDeployment(d.ID, "")
+	c.Assert(hh.IsForbiddenError(err), Equals, true)
+
+	// an admin token restricted to the app can approve it
+	admin := s.tokenClient(c, &ct.Token{Name: "approval-admin", Role: ct.TokenRoleAdmin, Apps: []string{app.ID}})
+	approved, err := admin.ApproveDeployment(d.ID, "")
+	c.Assert(err, IsNil)
+	c.Assert(approved.ApprovalLog, HasLen, 1)
+}
+
+func (s *S) TestTokenRevoke(c *C) {
+	token := &ct.Token{Name: "revoke", Role: ct.TokenRoleRead}
+	client := s.tokenClient(c, token)
+	_, err := client.AppList()
+	c.Assert(err, IsNil)
+
+	// the key isn't returned when listing tokens
+	tokens, err := s.c.ListTokens()
+	c.Assert(err, IsNil)
+	var found bool
+	for _, t := range tokens {
+		if t.ID == token.ID {
+			found = true
+			c.Assert(t.Key, Equals, "")
+		}
+	}
+	c.Assert(found, Equals, true)
+
+	revoked, err := s.c.DeleteToken(token.ID)
+	c.Assert(err, IsNil)
+	c.Assert(revoked.ID, Equals, token.ID)
+	_, err = client.AppList()
+	c.Assert(err, NotNil)
+}
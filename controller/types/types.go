@@ -1,11 +1,15 @@
 package types
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -36,6 +40,7 @@ type ExpandedFormation struct {
 	UpdatedAt           time.Time                    `json:"updated_at,omitempty"`
 	Deleted             bool                         `json:"deleted,omitempty"`
 	PendingScaleRequest *ScaleRequest                `json:"pending_scale_request,omitempty"`
+	ServiceSuffix       string                       `json:"service_suffix,omitempty"`
 
 	// DeprecatedImageArtifact is for creating backwards compatible cluster
 	// backups (the restore process used to require the ImageArtifact field
@@ -101,6 +106,190 @@ func (a *App) SetDeployBatchSize(size int) {
 	a.Meta["flynn-deploy-batch-size"] = strconv.Itoa(size)
 }
 
+// DeployWarmPeriod returns the number of seconds to keep the old formation
+// running after routes have been switched to the new release when deploying
+// using the blue-green deployment strategy
+func (a *App) DeployWarmPeriod() int {
+	if i, err := strconv.Atoi(a.Meta["flynn-deploy-warm-period"]); err == nil {
+		return i
+	}
+	return DefaultDeployWarmPeriod
+}
+
+// SetDeployWarmPeriod sets the number of seconds to keep the old formation
+// running after routes have been switched to the new release when deploying
+// using the blue-green deployment strategy
+func (a *App) SetDeployWarmPeriod(seconds int) {
+	if a.Meta == nil {
+		a.Meta = make(map[string]string)
+	}
+	a.Meta["flynn-deploy-warm-period"] = strconv.Itoa(seconds)
+}
+
+const DefaultDeployWarmPeriod = 300
+
+// DeployApproval returns the configuration of the app's deploy gate, or nil
+// if deployments of the app do not need to be approved
+func (a *App) DeployApproval() *ApprovalConfig {
+	if a.Meta["flynn-deploy-approval"] != "true" {
+		return nil
+	}
+	config := &ApprovalConfig{
+		WebhookURL: a.Meta["flynn-deploy-approval-webhook"],
+		Secret:     a.Meta["flynn-deploy-approval-secret"],
+		Timeout:    DefaultDeployApprovalTimeout,
+	}
+	if i, err := strconv.Atoi(a.Meta["flynn-deploy-approval-timeout"]); err == nil {
+		config.Timeout = i
+	}
+	return config
+}
+
+// SetDeployApproval sets the configuration of the app's deploy gate, with a
+// nil config meaning deployments do not need to be approved
+func (a *App) SetDeployApproval(config *ApprovalConfig) {
+	if a.Meta == nil {
+		a.Meta = make(map[string]string)
+	}
+	if config == nil {
+		delete(a.Meta, "flynn-deploy-approval")
+		delete(a.Meta, "flynn-deploy-approval-webhook")
+		delete(a.Meta, "flynn-deploy-approval-secret")
+		delete(a.Meta, "flynn-deploy-approval-timeout")
+		return
+	}
+	a.Meta["flynn-deploy-approval"] = "true"
+	a.Meta["flynn-deploy-approval-webhook"] = config.WebhookURL
+	if config.Secret != "" {
+		a.Meta["flynn-deploy-approval-secret"] = config.Secret
+	}
+	a.Meta["flynn-deploy-approval-timeout"] = strconv.Itoa(config.Timeout)
+}
+
+// ApprovalConfig configures a deploy gate, which holds deployments in the
+// pending_approval status until they are approved
+type ApprovalConfig struct {
+	// WebhookURL, if set, is sent a DeploymentApprovalRequest when a
+	// deployment starts waiting for approval
+	WebhookURL string `json:"webhook_url,omitempty"`
+
+	// Secret is used to sign the approval requests sent to WebhookURL in
+	// the same way as webhook deliveries (see WebhookSignature), and is
+	// generated by the controller if a webhook is set without one
+	Secret string `json:"secret,omitempty"`
+
+	// Timeout is the number of seconds to wait for approval before
+	// failing the deployment
+	Timeout int `json:"timeout"`
+}
+
+const DefaultDeployApprovalTimeout = 3600
+
+// DeployRollback returns the configuration for automatically rolling back
+// deployments, with defaults for any unset values (deployments are only
+// monitored for rollback if the bake period is positive)
+func (a *App) DeployRollback() *RollbackConfig {
+	config := &RollbackConfig{
+		MaxErrorRate: DefaultRollbackMaxErrorRate,
+	}
+	for key, v := range map[string]*int{
+		"flynn-deploy-bake-period":         &config.BakePeriod,
+		"flynn-deploy-max-crashes":         &config.MaxCrashes,
+		"flynn-deploy-max-health-failures": &config.MaxHealthFailures,
+	} {
+		if i, err := strconv.Atoi(a.Meta[key]); err == nil {
+			*v = i
+		}
+	}
+	if f, err := strconv.ParseFloat(a.Meta["flynn-deploy-max-error-rate"], 64); err == nil {
+		config.MaxErrorRate = f
+	}
+	return config
+}
+
+// SetDeployRollback sets the configuration for automatically rolling back
+// deployments
+func (a *App) SetDeployRollback(config *RollbackConfig) {
+	if a.Meta == nil {
+		a.Meta = make(map[string]string)
+	}
+	a.Meta["flynn-deploy-bake-period"] = strconv.Itoa(config.BakePeriod)
+	a.Meta["flynn-deploy-max-error-rate"] = strconv.FormatFloat(config.MaxErrorRate, 'f', -1, 64)
+	a.Meta["flynn-deploy-max-crashes"] = strconv.Itoa(config.MaxCrashes)
+	a.Meta["flynn-deploy-max-health-failures"] = strconv.Itoa(config.MaxHealthFailures)
+}
+
+// RollbackConfig configures automatically rolling back deployments which
+// appear unhealthy once the new release is running, with the new release
+// being monitored for BakePeriod seconds and the deployment rolled back if
+// any of the thresholds are exceeded
+type RollbackConfig struct {
+	BakePeriod int `json:"bake_period"`
+
+	// MaxErrorRate is the maximum percentage of HTTP requests to the new
+	// release's jobs which may get a 5xx response
+	MaxErrorRate float64 `json:"max_error_rate"`
+
+	// MaxCrashes is the maximum number of the new release's jobs which
+	// may exit unsuccessfully
+	MaxCrashes int `json:"max_crashes"`
+
+	// MaxHealthFailures is the maximum number of times the new release's
+	// jobs may be removed from service discovery (e.g. because of failing
+	// health checks)
+	MaxHealthFailures int `json:"max_health_failures"`
+}
+
+const DefaultRollbackMaxErrorRate = 5
+
+// DeployCanary returns the configuration to use when deploying using the
+// canary deployment strategy, with defaults for any unset values
+func (a *App) DeployCanary() *CanaryConfig {
+	config := &CanaryConfig{
+		Count:  DefaultCanaryCount,
+		Weight: DefaultCanaryWeight,
+		Window: DefaultCanaryWindow,
+	}
+	for key, v := range map[string]*int{
+		"flynn-deploy-canary-count":  &config.Count,
+		"flynn-deploy-canary-weight": &config.Weight,
+		"flynn-deploy-canary-window": &config.Window,
+	} {
+		if i, err := strconv.Atoi(a.Meta[key]); err == nil {
+			*v = i
+		}
+	}
+	return config
+}
+
+// SetDeployCanary sets the configuration to use when deploying using the
+// canary deployment strategy
+func (a *App) SetDeployCanary(config *CanaryConfig) {
+	if a.Meta == nil {
+		a.Meta = make(map[string]string)
+	}
+	a.Meta["flynn-deploy-canary-count"] = strconv.Itoa(config.Count)
+	a.Meta["flynn-deploy-canary-weight"] = strconv.Itoa(config.Weight)
+	a.Meta["flynn-deploy-canary-window"] = strconv.Itoa(config.Window)
+}
+
+// CanaryConfig configures deployments using the canary strategy, which
+// start Count jobs of each process type from the new release, route Weight
+// percent of HTTP requests to them for Window seconds and then either
+// promote the new release if the canary jobs stayed up or abort the
+// deployment if any of them failed
+type CanaryConfig struct {
+	Count  int `json:"count"`
+	Weight int `json:"weight"`
+	Window int `json:"window"`
+}
+
+const (
+	DefaultCanaryCount  = 1
+	DefaultCanaryWeight = 10
+	DefaultCanaryWindow = 300
+)
+
 type ReleaseType string
 
 var (
@@ -118,6 +307,11 @@ type Release struct {
 	Processes   map[string]ProcessType `json:"processes,omitempty"`
 	CreatedAt   *time.Time             `json:"created_at,omitempty"`
 
+	// Secrets are the names of Env vars whose values are secret, which
+	// are encrypted by the controller before being persisted and are
+	// returned encrypted unless explicitly requested
+	Secrets []string `json:"secrets,omitempty"`
+
 	// LegacyArtifactID is to support old clients which expect releases
 	// to have a single ArtifactID
 	LegacyArtifactID string `json:"artifact,omitempty"`
@@ -131,6 +325,12 @@ func (r *Release) IsDockerReceiveDeploy() bool {
 	return r.Meta["docker-receive"] == "true"
 }
 
+// ReleaseProcessType is the process type which, if a release defines it, is
+// run as a one-off job when the release is deployed and before any of its
+// other jobs are started (e.g. to run database migrations), with the
+// deployment failing if the job exits unsuccessfully
+const ReleaseProcessType = "release"
+
 type ProcessType struct {
 	Args              []string           `json:"args,omitempty"`
 	Env               map[string]string  `json:"env,omitempty"`
@@ -147,6 +347,12 @@ type ProcessType struct {
 	LinuxCapabilities []string           `json:"linux_capabilities,omitempty"`
 	AllowedDevices    []*host.Device     `json:"allowed_devices,omitempty"`
 	WriteableCgroups  bool               `json:"writeable_cgroups,omitempty"`
+	Spread            []SpreadConstraint `json:"spread,omitempty"`
+	Affinity          []AffinityRule     `json:"affinity,omitempty"`
+	Partition         PartitionType      `json:"partition,omitempty"`
+	AntiAffinity      []AffinityRule     `json:"anti_affinity,omitempty"`
+	RestartPolicy     *RestartPolicy     `json:"restart_policy,omitempty"`
+	DisruptionBudget  *DisruptionBudget  `json:"disruption_budget,omitempty"`
 
 	// Entrypoint and Cmd are DEPRECATED: use Args instead
 	DeprecatedCmd        []string `json:"cmd,omitempty"`
@@ -156,6 +362,116 @@ type ProcessType struct {
 	DeprecatedData bool `json:"data,omitempty"`
 }
 
+// SpreadConstraint spreads the jobs of a process type across the distinct
+// values of a host tag (e.g. to spread jobs across zones or racks)
+type SpreadConstraint struct {
+	// TagKey is the host tag whose values jobs should be spread across
+	TagKey string `json:"tag_key"`
+
+	// MaxSkew is the maximum permitted difference between the number of
+	// jobs running in any two tag values (defaults to 1)
+	MaxSkew int `json:"max_skew,omitempty"`
+
+	// Hard determines whether jobs which would exceed MaxSkew are blocked
+	// rather than just being placed on the least preferred hosts
+	Hard bool `json:"hard,omitempty"`
+}
+
+// AffinityRule references the jobs of an app's process type which jobs of a
+// process type should either be placed on the same hosts as (affinity) or
+// kept apart from (anti-affinity)
+type AffinityRule struct {
+	// AppID is the app whose jobs are referenced (defaults to the app
+	// the rule belongs to)
+	AppID string `json:"app,omitempty"`
+
+	// Type is the process type whose jobs are referenced (defaults to all
+	// process types of the app)
+	Type string `json:"type,omitempty"`
+
+	// Required determines whether jobs are blocked if the rule cannot be
+	// satisfied rather than the rule just being preferred
+	Required bool `json:"required,omitempty"`
+}
+
+// RestartPolicy determines whether and how quickly the scheduler restarts
+// the jobs of a process type when they stop
+type RestartPolicy struct {
+	// Type determines which stopped jobs are restarted (defaults to
+	// always)
+	Type RestartPolicyType `json:"type,omitempty"`
+
+	// MaxRestarts is the maximum number of times jobs of the process type
+	// are restarted within Window seconds before the process type is
+	// marked as crash looping and its jobs are no longer restarted (zero
+	// means there is no limit)
+	MaxRestarts int `json:"max_restarts,omitempty"`
+	Window      int `json:"window,omitempty"`
+
+	// BackoffBase is the number of seconds to wait before restarting a
+	// job, which is doubled for each consecutive restart up to
+	// BackoffCap seconds (the scheduler's default backoff is used if
+	// BackoffBase is zero)
+	BackoffBase int `json:"backoff_base,omitempty"`
+	BackoffCap  int `json:"backoff_cap,omitempty"`
+}
+
+type RestartPolicyType string
+
+const (
+	// RestartPolicyAlways restarts jobs whenever they stop
+	RestartPolicyAlways RestartPolicyType = "always"
+
+	// RestartPolicyOnFailure restarts jobs which either exit with a
+	// non-zero status or fail to run
+	RestartPolicyOnFailure RestartPolicyType = "on-failure"
+
+	// RestartPolicyNever does not restart jobs
+	RestartPolicyNever RestartPolicyType = "never"
+)
+
+const (
+	// DefaultRestartWindow is the default number of seconds in which a
+	// restart policy's MaxRestarts are counted
+	DefaultRestartWindow = 300
+
+	// DefaultRestartBackoffCap is the default maximum number of seconds
+	// to wait before restarting a job when using a custom backoff
+	DefaultRestartBackoffCap = 300
+)
+
+// DisruptionBudget limits how many jobs of a process type can be voluntarily
+// stopped at the same time (e.g. when scaling down, deploying or draining
+// hosts), with jobs counted across all of an app's releases
+type DisruptionBudget struct {
+	// MaxUnavailable is the maximum number of expected jobs which can be
+	// unavailable (either stopping or not yet running) at any time
+	MaxUnavailable int `json:"max_unavailable,omitempty"`
+
+	// MinAvailable is the minimum number of jobs which must be running at
+	// any time (it is capped at the expected number of jobs so that the
+	// process type can still be scaled down)
+	MinAvailable int `json:"min_available,omitempty"`
+}
+
+// AllowedDisruptions returns the number of available jobs which can be
+// stopped without violating the budget, given the expected number of jobs,
+// the number which are available and the number which are already stopping
+func (b *DisruptionBudget) AllowedDisruptions(expected, available, stopping int) int {
+	if b.MinAvailable > 0 {
+		min := b.MinAvailable
+		if min > expected {
+			min = expected
+		}
+		return available - min
+	}
+	unavailable := stopping
+	if missing := expected - available; missing > 0 {
+		unavailable += missing
+	}
+	return b.MaxUnavailable - unavailable
+}
+
 type Port struct {
 	Port    int           `json:"port"`
 	Proto   string        `json:"proto"`
@@ -256,6 +572,31 @@ type Formation struct {
 	Tags      map[string]map[string]string `json:"tags,omitempty"`
 	CreatedAt *time.Time                   `json:"created_at,omitempty"`
 	UpdatedAt *time.Time                   `json:"updated_at,omitempty"`
+
+	// ServiceSuffix is appended to the names of the release's services
+	// when registering the formation's jobs in service discovery, so that
+	// formations of different releases can run under separate services
+	// (used by the blue-green deployment strategy)
+	ServiceSuffix string `json:"service_suffix,omitempty"`
+}
+
+// ServiceName returns the name of the service which the formation's jobs
+// register the given service of the release as
+func (f *Formation) ServiceName(service string) string {
+	return formationServiceName(service, f.ServiceSuffix)
+}
+
+// ServiceName returns the name of the service which the formation's jobs
+// register the given service of the release as
+func (f *ExpandedFormation) ServiceName(service string) string {
+	return formationServiceName(service, f.ServiceSuffix)
+}
+
+func formationServiceName(service, suffix string) string {
+	if service == "" || suffix == "" {
+		return service
+	}
+	return service + "-" + suffix
 }
 
 type Job struct {
@@ -284,6 +625,19 @@ type Job struct {
 	Restarts   *int32            `json:"restarts,omitempty"`
 	CreatedAt  *time.Time        `json:"created_at,omitempty"`
 	UpdatedAt  *time.Time        `json:"updated_at,omitempty"`
+
+	// BlockedReason is the reason the scheduler could not place the job
+	// in the cluster when it is in the blocked state
+	BlockedReason *string `json:"blocked_reason,omitempty"`
+
+	// PreemptedBy is the UUID of the higher priority job the scheduler
+	// stopped the job to make room for
+	PreemptedBy *string `json:"preempted_by,omitempty"`
+
+	// CrashLoopReason is set on a stopped job which the scheduler did not
+	// restart because its process type exhausted the restart budget of
+	// its restart policy
+	CrashLoopReason *string `json:"crash_loop_reason,omitempty"`
 }
 
 type JobState string
@@ -390,34 +744,156 @@ type NewJob struct {
 const DefaultDeployTimeout = 120 // seconds
 
 type Deployment struct {
-	ID              string                       `json:"id,omitempty"`
-	AppID           string                       `json:"app,omitempty"`
-	OldReleaseID    string                       `json:"old_release,omitempty"`
-	NewReleaseID    string                       `json:"new_release,omitempty"`
-	Strategy        string                       `json:"strategy,omitempty"`
-	Status          string                       `json:"status,omitempty"`
-	Processes       map[string]int               `json:"processes,omitempty"`
-	Tags            map[string]map[string]string `json:"tags,omitempty"`
-	DeployTimeout   int32                        `json:"deploy_timeout,omitempty"`
-	DeployBatchSize *int                         `json:"deploy_batch_size,omitempty"`
-	CreatedAt       *time.Time                   `json:"created_at,omitempty"`
-	FinishedAt      *time.Time                   `json:"finished_at,omitempty"`
+	ID               string                       `json:"id,omitempty"`
+	AppID            string                       `json:"app,omitempty"`
+	OldReleaseID     string                       `json:"old_release,omitempty"`
+	NewReleaseID     string                       `json:"new_release,omitempty"`
+	Strategy         string                       `json:"strategy,omitempty"`
+	Status           string                       `json:"status,omitempty"`
+	Processes        map[string]int               `json:"processes,omitempty"`
+	Tags             map[string]map[string]string `json:"tags,omitempty"`
+	DeployTimeout    int32                        `json:"deploy_timeout,omitempty"`
+	DeployBatchSize  *int                         `json:"deploy_batch_size,omitempty"`
+	DeployCanary     *CanaryConfig                `json:"deploy_canary,omitempty"`
+	DeployWarmPeriod *int                         `json:"deploy_warm_period,omitempty"`
+	DeployRollback   *RollbackConfig              `json:"deploy_rollback,omitempty"`
+	RollbackReason   string                       `json:"rollback_reason,omitempty"`
+	Paused           bool                         `json:"paused,omitempty"`
+	Aborted          bool                         `json:"aborted,omitempty"`
+	DeployApproval   *ApprovalConfig              `json:"deploy_approval,omitempty"`
+	ApprovalLog      []*DeploymentApprovalEntry   `json:"approval_log,omitempty"`
+	CreatedAt        *time.Time                   `json:"created_at,omitempty"`
+	FinishedAt       *time.Time                   `json:"finished_at,omitempty"`
 }
 
 type ExpandedDeployment struct {
-	ID              string                       `json:"id,omitempty"`
-	AppID           string                       `json:"app,omitempty"`
-	OldRelease      *Release                     `json:"old_release,omitempty"`
-	NewRelease      *Release                     `json:"new_release,omitempty"`
-	Type            ReleaseType                  `json:"type,omitempty"`
-	Strategy        string                       `json:"strategy,omitempty"`
-	Status          string                       `json:"status,omitempty"`
-	Processes       map[string]int               `json:"processes,omitempty"`
-	Tags            map[string]map[string]string `json:"tags,omitempty"`
-	DeployTimeout   int32                        `json:"deploy_timeout,omitempty"`
-	DeployBatchSize *int                         `json:"deploy_batch_size,omitempty"`
-	CreatedAt       *time.Time                   `json:"created_at,omitempty"`
-	FinishedAt      *time.Time                   `json:"finished_at,omitempty"`
+	ID               string                       `json:"id,omitempty"`
+	AppID            string                       `json:"app,omitempty"`
+	OldRelease       *Release                     `json:"old_release,omitempty"`
+	NewRelease       *Release                     `json:"new_release,omitempty"`
+	Type             ReleaseType                  `json:"type,omitempty"`
+	Strategy         string                       `json:"strategy,omitempty"`
+	Status           string                       `json:"status,omitempty"`
+	Processes        map[string]int               `json:"processes,omitempty"`
+	Tags             map[string]map[string]string `json:"tags,omitempty"`
+	DeployTimeout    int32                        `json:"deploy_timeout,omitempty"`
+	DeployBatchSize  *int                         `json:"deploy_batch_size,omitempty"`
+	DeployCanary     *CanaryConfig                `json:"deploy_canary,omitempty"`
+	DeployWarmPeriod *int                         `json:"deploy_warm_period,omitempty"`
+	DeployRollback   *RollbackConfig              `json:"deploy_rollback,omitempty"`
+	RollbackReason   string                       `json:"rollback_reason,omitempty"`
+	Paused           bool                         `json:"paused,omitempty"`
+	Aborted          bool                         `json:"aborted,omitempty"`
+	DeployApproval   *ApprovalConfig              `json:"deploy_approval,omitempty"`
+	ApprovalLog      []*DeploymentApprovalEntry   `json:"approval_log,omitempty"`
+	CreatedAt        *time.Time                   `json:"created_at,omitempty"`
+	FinishedAt       *time.Time                   `json:"finished_at,omitempty"`
+}
+
+// DeploymentApprovalEntry is an entry in the audit trail of a deployment
+// which is held by a deploy gate
+type DeploymentApprovalEntry struct {
+	Action DeploymentApprovalAction `json:"action"`
+
+	// Actor identifies who approved or rejected the deployment (the ID of
+	// the key used to authenticate with the controller, if known)
+	Actor string `json:"actor,omitempty"`
+
+	// Comment is a comment given when approving or rejecting the
+	// deployment, or the result of sending the webhook
+	Comment   string     `json:"comment,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+type DeploymentApprovalAction string
+
+const (
+	DeploymentApprovalActionRequested     DeploymentApprovalAction = "requested"
+	DeploymentApprovalActionWebhookSent   DeploymentApprovalAction = "webhook_sent"
+	DeploymentApprovalActionWebhookFailed DeploymentApprovalAction = "webhook_failed"
+	DeploymentApprovalActionApproved      DeploymentApprovalAction = "approved"
+	DeploymentApprovalActionRejected      DeploymentApprovalAction = "rejected"
+	DeploymentApprovalActionTimedOut      DeploymentApprovalAction = "timed_out"
+)
+
+// DeploymentApprovalState is the state of a deployment held by a deploy gate
+type DeploymentApprovalState string
+
+const (
+	DeploymentApprovalStatePending  DeploymentApprovalState = "pending"
+	DeploymentApprovalStateApproved DeploymentApprovalState = "approved"
+	DeploymentApprovalStateRejected DeploymentApprovalState = "rejected"
+)
+
+// DeploymentApproval is the request body when approving or rejecting a
+// deployment
+type DeploymentApproval struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+// DeploymentApprovalRequest is POSTed to the webhook of an app's deploy gate
+// when a deployment starts waiting for approval
+type DeploymentApprovalRequest struct {
+	Deployment  *Deployment  `json:"deployment"`
+	AppName     string       `json:"app_name"`
+	Diff        *ReleaseDiff `json:"diff"`
+	ApprovePath string       `json:"approve_path"`
+	RejectPath  string       `json:"reject_path"`
+	ExpiresAt   *time.Time   `json:"expires_at"`
+}
+
+// ReleaseDiff summarises the differences between two releases (values of
+// environment variables are omitted as they may be secret)
+type ReleaseDiff struct {
+	OldArtifactIDs   []string `json:"old_artifacts,omitempty"`
+	NewArtifactIDs   []string `json:"new_artifacts,omitempty"`
+	EnvAdded         []string `json:"env_added,omitempty"`
+	EnvRemoved       []string `json:"env_removed,omitempty"`
+	EnvChanged       []string `json:"env_changed,omitempty"`
+	ProcessesAdded   []string `json:"processes_added,omitempty"`
+	ProcessesRemoved []string `json:"processes_removed,omitempty"`
+	ProcessesChanged []string `json:"processes_changed,omitempty"`
+}
+
+// DiffReleases returns the differences between the old and new releases,
+// with old being nil for the first release of an app
+func DiffReleases(old, new *Release) *ReleaseDiff {
+	if old == nil {
+		old = &Release{}
+	}
+	diff := &ReleaseDiff{}
+	if !reflect.DeepEqual(old.ArtifactIDs, new.ArtifactIDs) {
+		diff.OldArtifactIDs = old.ArtifactIDs
+		diff.NewArtifactIDs = new.ArtifactIDs
+	}
+	for k, v := range new.Env {
+		if oldV, ok := old.Env[k]; !ok {
+			diff.EnvAdded = append(diff.EnvAdded, k)
+		} else if oldV != v {
+			diff.EnvChanged = append(diff.EnvChanged, k)
+		}
+	}
+	for k := range old.Env {
+		if _, ok := new.Env[k]; !ok {
+			diff.EnvRemoved = append(diff.EnvRemoved, k)
+		}
+	}
+	for typ, proc := range new.Processes {
+		if oldProc, ok := old.Processes[typ]; !ok {
+			diff.ProcessesAdded = append(diff.ProcessesAdded, typ)
+		} else if !reflect.DeepEqual(oldProc, proc) {
+			diff.ProcessesChanged = append(diff.ProcessesChanged, typ)
+		}
+	}
+	for typ := range old.Processes {
+		if _, ok := new.Processes[typ]; !ok {
+			diff.ProcessesRemoved = append(diff.ProcessesRemoved, typ)
+		}
+	}
+	for _, keys := range [][]string{diff.EnvAdded, diff.EnvRemoved, diff.EnvChanged, diff.ProcessesAdded, diff.ProcessesRemoved, diff.ProcessesChanged} {
+		sort.Strings(keys)
+	}
+	return diff
 }
 
 type DeployID struct {
@@ -432,8 +908,79 @@ type DeploymentEvent struct {
 	JobType      string   `json:"job_type,omitempty"`
 	JobState     JobState `json:"job_state,omitempty"`
 	Error        string   `json:"error,omitempty"`
+
+	// Step is the step of the deployment the event reports, if any (for
+	// example the steps of a canary deployment)
+	Step DeploymentStep `json:"step,omitempty"`
+
+	// Output is a line of output from the release phase job
+	Output string `json:"output,omitempty"`
 }
 
+type DeploymentStep string
+
+const (
+	// DeploymentStepCanaryStarted is reported once the canary jobs of
+	// a canary deployment are running
+	DeploymentStepCanaryStarted DeploymentStep = "canary_started"
+
+	// DeploymentStepCanaryRouted is reported once the app's HTTP routes
+	// are sending a share of requests to the canary jobs, at which point
+	// the observation window starts
+	DeploymentStepCanaryRouted DeploymentStep = "canary_routed"
+
+	// DeploymentStepCanaryPromoted is reported when the canary jobs have
+	// stayed up for the observation window and the new release is being
+	// scaled up to replace the old one
+	DeploymentStepCanaryPromoted DeploymentStep = "canary_promoted"
+
+	// DeploymentStepCanaryAborted is reported when a canary job fails
+	// during the observation window, causing the deployment to be rolled
+	// back
+	DeploymentStepCanaryAborted DeploymentStep = "canary_aborted"
+
+	// DeploymentStepBakeStarted is reported when the new release starts
+	// being monitored for automatic rollback
+	DeploymentStepBakeStarted DeploymentStep = "bake_started"
+
+	// DeploymentStepBakeFailed is reported when the new release exceeded
+	// one of the automatic rollback thresholds, causing the deployment to
+	// be rolled back
+	DeploymentStepBakeFailed DeploymentStep = "bake_failed"
+
+	// DeploymentStepReleasePhaseStarted is reported once the release
+	// phase job of the new release has started
+	DeploymentStepReleasePhaseStarted DeploymentStep = "release_phase_started"
+
+	// DeploymentStepReleasePhaseOutput is reported for each line of
+	// output from the release phase job
+	DeploymentStepReleasePhaseOutput DeploymentStep = "release_phase_output"
+
+	// DeploymentStepReleasePhaseComplete is reported once the release
+	// phase job has exited successfully, after which the new release is
+	// deployed
+	DeploymentStepReleasePhaseComplete DeploymentStep = "release_phase_complete"
+
+	// DeploymentStepReleasePhaseFailed is reported when the release phase
+	// job exits unsuccessfully, causing the deployment to fail
+	DeploymentStepReleasePhaseFailed DeploymentStep = "release_phase_failed"
+
+	// DeploymentStepBlueGreenUp is reported once all the jobs of the new
+	// release are running and passing health checks under the new
+	// release's services
+	DeploymentStepBlueGreenUp DeploymentStep = "blue_green_up"
+
+	// DeploymentStepRoutesSwitched is reported once the app's routes have
+	// been switched to the new release's services, at which point the old
+	// release is kept running for the warm period
+	DeploymentStepRoutesSwitched DeploymentStep = "routes_switched"
+
+	// DeploymentStepRoutesRestored is reported when a blue-green
+	// deployment fails after switching routes and they have been switched
+	// back to the old release's services
+	DeploymentStepRoutesRestored DeploymentStep = "routes_restored"
+)
+
 func (e *DeploymentEvent) Err() error {
 	if e.Error == "" {
 		return nil
@@ -514,6 +1061,14 @@ const (
 	EventTypeSink                    EventType = "sink"
 	EventTypeSinkDeletion            EventType = "sink_deletion"
 	EventTypeVolume                  EventType = "volume"
+	EventTypeSchedule                EventType = "schedule"
+	EventTypeScheduleDeletion        EventType = "schedule_deletion"
+	EventTypeScheduleRun             EventType = "schedule_run"
+	EventTypeAutoscalePolicy         EventType = "autoscale_policy"
+	EventTypeAutoscalePolicyDeletion EventType = "autoscale_policy_deletion"
+	EventTypeAutoscale               EventType = "autoscale"
+	EventTypeAudit                   EventType = "audit"
+	EventTypeHostDrain               EventType = "host_drain"
 
 	// EventTypeDeprecatedScale is a deprecated event which is emitted for
 	// old clients waiting for formations to be scaled (new clients should
@@ -550,6 +1105,10 @@ type ScaleRequest struct {
 	NewTags      *map[string]map[string]string `json:"new_tags,omitempty"`
 	CreatedAt    *time.Time                    `json:"created_at"`
 	UpdatedAt    *time.Time                    `json:"updated_at"`
+
+	// NewServiceSuffix, if set, changes the formation's service suffix
+	// (it is applied to the formation rather than stored with the request)
+	NewServiceSuffix *string `json:"new_service_suffix,omitempty"`
 }
 
 type ScaleRequestState string
@@ -569,6 +1128,7 @@ type DeprecatedScale struct {
 type ScaleOptions struct {
 	Processes            map[string]int
 	Tags                 map[string]map[string]string
+	ServiceSuffix        *string
 	Timeout              *time.Duration
 	Stop                 chan struct{}
 	NoWait               bool
@@ -762,6 +1322,72 @@ type Sink struct {
 	UpdatedAt   *time.Time       `json:"updated_at,omitempty"`
 }
 
+// ScheduleConcurrencyPolicy determines what happens when a schedule is due to
+// run but the job from its previous run is still active
+type ScheduleConcurrencyPolicy string
+
+const (
+	// ScheduleConcurrencyAllow runs a new job alongside the active one
+	ScheduleConcurrencyAllow ScheduleConcurrencyPolicy = "allow"
+
+	// ScheduleConcurrencyForbid skips the run, leaving the active job
+	// running
+	ScheduleConcurrencyForbid ScheduleConcurrencyPolicy = "forbid"
+
+	// ScheduleConcurrencyReplace stops the active job before running a
+	// new one
+	ScheduleConcurrencyReplace ScheduleConcurrencyPolicy = "replace"
+)
+
+// Schedule periodically runs a one-off job in an app according to a cron
+// expression
+type Schedule struct {
+	ID    string `json:"id,omitempty"`
+	AppID string `json:"app,omitempty"`
+
+	// Cron is a standard five field cron expression (e.g. "*/15 * * * *"
+	// or "@daily") evaluated in UTC
+	Cron string `json:"cron,omitempty"`
+
+	// ReleaseID is the release to run the job from, with an empty value
+	// meaning the app's current release at the time of each run
+	ReleaseID string `json:"release,omitempty"`
+
+	Args        []string                  `json:"args,omitempty"`
+	Env         map[string]string         `json:"env,omitempty"`
+	Resources   resource.Resources        `json:"resources,omitempty"`
+	Concurrency ScheduleConcurrencyPolicy `json:"concurrency,omitempty"`
+	NextRunAt   *time.Time                `json:"next_run_at,omitempty"`
+	LastRunAt   *time.Time                `json:"last_run_at,omitempty"`
+	CreatedAt   *time.Time                `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time                `json:"updated_at,omitempty"`
+}
+
+type ScheduleRunState string
+
+const (
+	ScheduleRunStatePending ScheduleRunState = "pending"
+	ScheduleRunStateStarted ScheduleRunState = "started"
+	ScheduleRunStateSkipped ScheduleRunState = "skipped"
+	ScheduleRunStateFailed  ScheduleRunState = "failed"
+)
+
+// ScheduleRun records an attempt to run a schedule's job
+type ScheduleRun struct {
+	ID         string           `json:"id,omitempty"`
+	ScheduleID string           `json:"schedule,omitempty"`
+	AppID      string           `json:"app,omitempty"`
+	ReleaseID  string           `json:"release,omitempty"`
+	JobID      string           `json:"job,omitempty"`
+	State      ScheduleRunState `json:"state,omitempty"`
+	Error      string           `json:"error,omitempty"`
+
+	// ScheduledAt is the time the run was due according to the
+	// schedule's cron expression
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
 type SyslogFormat string
 
 const (
@@ -799,3 +1425,478 @@ type LabelFilterExpression struct {
 	Key    string                  `json:"key"`
 	Values []string                `json:"values"`
 }
+
+const (
+	DefaultAutoscaleScaleUpCooldown   = 60  // seconds
+	DefaultAutoscaleScaleDownCooldown = 300 // seconds
+)
+
+// AutoscalePolicy automatically scales an app's process type between a
+// minimum and maximum number of jobs so that the observed load per job
+// stays close to the given targets, using the highest number of jobs
+// required by any of the targets
+type AutoscalePolicy struct {
+	AppID       string `json:"app,omitempty"`
+	ProcessType string `json:"process_type,omitempty"`
+
+	Min int `json:"min"`
+	Max int `json:"max"`
+
+	// TargetRequestsPerSecond is the desired number of requests per
+	// second handled by each job, as measured by the router
+	TargetRequestsPerSecond float64 `json:"target_requests_per_second,omitempty"`
+
+	// TargetCPU is the desired CPU utilisation of each job as a
+	// percentage of its CPU limit, as measured by the host
+	TargetCPU int `json:"target_cpu,omitempty"`
+
+	// TargetMemory is the desired memory usage of each job as a
+	// percentage of its memory limit, as measured by the host
+	TargetMemory int `json:"target_memory,omitempty"`
+
+	// ScaleUpCooldown and ScaleDownCooldown are the minimum number of
+	// seconds between scaling the process type and scaling it up or down
+	// again
+	ScaleUpCooldown   int `json:"scale_up_cooldown,omitempty"`
+	ScaleDownCooldown int `json:"scale_down_cooldown,omitempty"`
+
+	LastScaledAt *time.Time `json:"last_scaled_at,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}
+
+type AutoscaleMetric string
+
+const (
+	AutoscaleMetricRequestsPerSecond AutoscaleMetric = "requests_per_second"
+	AutoscaleMetricCPU               AutoscaleMetric = "cpu"
+	AutoscaleMetricMemory            AutoscaleMetric = "memory"
+)
+
+// AutoscaleMeasurement is the observed value of a metric averaged across a
+// process type's jobs, along with the number of jobs the metric requires to
+// meet its target
+type AutoscaleMeasurement struct {
+	Metric  AutoscaleMetric `json:"metric"`
+	Value   float64         `json:"value"`
+	Target  float64         `json:"target"`
+	Desired int             `json:"desired"`
+}
+
+// AutoscaleEvent is emitted when an autoscale policy scales a process type
+// (or fails to), explaining the decision
+type AutoscaleEvent struct {
+	AppID        string                  `json:"app"`
+	ReleaseID    string                  `json:"release"`
+	ProcessType  string                  `json:"process_type"`
+	From         int                     `json:"from"`
+	To           int                     `json:"to"`
+	Measurements []*AutoscaleMeasurement `json:"measurements,omitempty"`
+	Reason       string                  `json:"reason"`
+	Error        string                  `json:"error,omitempty"`
+}
+
+// SchedulerDryRunRequest describes a hypothetical change to the cluster for
+// the scheduler to compute its decisions for without acting on them
+type SchedulerDryRunRequest struct {
+	// Formations are formations to add, or to replace the scheduler's
+	// formations for the same app and release with
+	Formations []*ExpandedFormation `json:"formations,omitempty"`
+
+	// RemoveHosts are the IDs of hosts to remove from the cluster
+	RemoveHosts []string `json:"remove_hosts,omitempty"`
+}
+
+// SchedulerDryRunResult is the result of a SchedulerDryRunRequest, listing
+// the jobs which the scheduler would place, block or stop
+type SchedulerDryRunResult struct {
+	Placements []*SchedulerDryRunJob `json:"placements"`
+	Blocked    []*SchedulerDryRunJob `json:"blocked"`
+	Stopped    []*SchedulerDryRunJob `json:"stopped"`
+}
+
+// SchedulerDryRunJob is a job in a SchedulerDryRunResult, with ID only being
+// set for existing jobs and Reason explaining why the job would be blocked or
+// stopped
+type SchedulerDryRunJob struct {
+	ID        string `json:"id,omitempty"`
+	AppID     string `json:"app"`
+	ReleaseID string `json:"release"`
+	Type      string `json:"type"`
+	HostID    string `json:"host_id,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// TokenRole determines which operations an API token may perform, with each
+// role also granting the operations of the roles below it
+type TokenRole string
+
+const (
+	// TokenRoleRead may only read resources
+	TokenRoleRead TokenRole = "read"
+
+	// TokenRoleDeploy may also create artifacts, releases and deployments,
+	// scale formations and pause, resume or abort deployments (but not
+	// approve or reject them)
+	TokenRoleDeploy TokenRole = "deploy"
+
+	// TokenRoleAdmin may perform any operation
+	TokenRoleAdmin TokenRole = "admin"
+)
+
+// TokenResourceType is a type of resource which an API token can be
+// restricted to
+type TokenResourceType string
+
+const (
+	TokenResourceApps        TokenResourceType = "apps"
+	TokenResourceArtifacts   TokenResourceType = "artifacts"
+	TokenResourceReleases    TokenResourceType = "releases"
+	TokenResourceFormations  TokenResourceType = "formations"
+	TokenResourceDeployments TokenResourceType = "deployments"
+	TokenResourceJobs        TokenResourceType = "jobs"
+	TokenResourceRoutes      TokenResourceType = "routes"
+	TokenResourceResources   TokenResourceType = "resources"
+	TokenResourceVolumes     TokenResourceType = "volumes"
+	TokenResourceSchedules   TokenResourceType = "schedules"
+	TokenResourceAutoscale   TokenResourceType = "autoscale"
+	TokenResourceEvents      TokenResourceType = "events"
+	TokenResourceSinks       TokenResourceType = "sinks"
+	TokenResourceWebhooks    TokenResourceType = "webhooks"
+	TokenResourceEnvGroups   TokenResourceType = "env_groups"
+)
+
+var TokenResourceTypes = []TokenResourceType{
+	TokenResourceApps,
+	TokenResourceArtifacts,
+	TokenResourceReleases,
+	TokenResourceFormations,
+	TokenResourceDeployments,
+	TokenResourceJobs,
+	TokenResourceRoutes,
+	TokenResourceResources,
+	TokenResourceVolumes,
+	TokenResourceSchedules,
+	TokenResourceAutoscale,
+	TokenResourceEvents,
+	TokenResourceSinks,
+	TokenResourceWebhooks,
+	TokenResourceEnvGroups,
+}
+
+// Token is a named API token which authenticates with the controller in the
+// same way as a cluster auth key, but is limited to the operations permitted
+// by its role, apps and resource types
+type Token struct {
+	ID   string    `json:"id,omitempty"`
+	Name string    `json:"name,omitempty"`
+	Role TokenRole `json:"role,omitempty"`
+
+	// Apps, if set, are the IDs of the only apps the token may access
+	Apps []string `json:"apps,omitempty"`
+
+	// ResourceTypes, if set, are the only types of resource the token may
+	// access
+	ResourceTypes []TokenResourceType `json:"resource_types,omitempty"`
+
+	// Key is the secret used to authenticate with the token, which is
+	// only returned when the token is created
+	Key string `json:"key,omitempty"`
+
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// Unrestricted returns whether the token may access all apps and resource
+// types
+func (t *Token) Unrestricted() bool {
+	return len(t.Apps) == 0 && len(t.ResourceTypes) == 0
+}
+
+// AuditRecord is a record of a request which modified the cluster through
+// the controller API
+type AuditRecord struct {
+	ID int64 `json:"id,omitempty"`
+
+	// KeyID is the ID of the auth key or the name of the API token which
+	// authenticated the request
+	KeyID string `json:"key_id,omitempty"`
+
+	Method string `json:"method,omitempty"`
+	Path   string `json:"path,omitempty"`
+	AppID  string `json:"app,omitempty"`
+
+	// Body is the request body, with secrets (e.g. release env vars
+	// which look like credentials) redacted
+	Body string `json:"body,omitempty"`
+
+	Status    int        `json:"status,omitempty"`
+	ClientIP  string     `json:"client_ip,omitempty"`
+	UserAgent string     `json:"user_agent,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+type ListAuditOptions struct {
+	AppID  string
+	KeyID  string
+	Since  *time.Time
+	Before *time.Time
+	Count  int
+}
+
+// Webhook is a URL which is POSTed controller events matching its apps and
+// event types
+type Webhook struct {
+	ID  string `json:"id,omitempty"`
+	URL string `json:"url,omitempty"`
+
+	// Apps, if set, are the IDs of the apps whose events are sent
+	Apps []string `json:"apps,omitempty"`
+
+	// EventTypes, if set, are the types of event which are sent
+	EventTypes []EventType `json:"event_types,omitempty"`
+
+	// Secret is used to sign the payloads sent to the webhook, and is only
+	// returned when the webhook is created (a random secret is generated
+	// if it is not set)
+	Secret string `json:"secret,omitempty"`
+
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// WebhookSignatureHeader is the header of webhook requests containing the
+// signature of the request body
+const WebhookSignatureHeader = "Flynn-Signature"
+
+// WebhookSignature returns the signature of a webhook request body, which is
+// the hex encoded HMAC-SHA256 of the body keyed with the webhook secret,
+// prefixed with "sha256="
+func WebhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookMaxAttempts is the maximum number of times a webhook delivery is
+// attempted before it is marked as failed
+const WebhookMaxAttempts = 10
+
+type WebhookDeliveryState string
+
+const (
+	WebhookDeliveryStatePending   WebhookDeliveryState = "pending"
+	WebhookDeliveryStateDelivered WebhookDeliveryState = "delivered"
+	WebhookDeliveryStateFailed    WebhookDeliveryState = "failed"
+)
+
+// WebhookDelivery is a record of sending an event to a webhook
+type WebhookDelivery struct {
+	ID        string               `json:"id,omitempty"`
+	WebhookID string               `json:"webhook,omitempty"`
+	EventID   int64                `json:"event,omitempty"`
+	EventType EventType            `json:"event_type,omitempty"`
+	AppID     string               `json:"app,omitempty"`
+	State     WebhookDeliveryState `json:"state,omitempty"`
+	Attempts  int                  `json:"attempts,omitempty"`
+
+	// ResponseStatus is the HTTP status of the last attempt's response
+	ResponseStatus int `json:"response_status,omitempty"`
+
+	// Error is the reason the last attempt failed
+	Error string `json:"error,omitempty"`
+
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// EnvGroup is a set of env vars shared by the apps it is attached to, which
+// are set in a new release of each attached app when the group changes
+type EnvGroup struct {
+	ID   string            `json:"id,omitempty"`
+	Name string            `json:"name,omitempty"`
+	Env  map[string]string `json:"env,omitempty"`
+
+	// AutoDeploy is whether to deploy the releases created for attached
+	// apps when the group changes (if not set, the releases are created
+	// but must be deployed separately)
+	AutoDeploy bool `json:"auto_deploy,omitempty"`
+
+	// Apps are the IDs of the apps the group is attached to
+	Apps []string `json:"apps,omitempty"`
+
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+const (
+	// ReleaseMetaEnvGroups is the release meta key containing the names of
+	// the env groups whose env is set in the release, in order of
+	// increasing precedence
+	ReleaseMetaEnvGroups = "flynn-env-groups"
+
+	// ReleaseMetaEnvPrecedence is the release meta key describing the
+	// precedence of the release's env, which is the release env (i.e. vars
+	// set directly on the app) followed by the env groups from last
+	// attached to first attached
+	ReleaseMetaEnvPrecedence = "flynn-env-precedence"
+
+	// ReleaseMetaEnvGroupsChange is the release meta key set on releases
+	// created because of a change to the app's env groups
+	ReleaseMetaEnvGroupsChange = "flynn-env-groups-change"
+)
+
+// EnvGroupAppRelease is a release created for an app because of a change to
+// the app's env groups
+type EnvGroupAppRelease struct {
+	AppID        string `json:"app,omitempty"`
+	ReleaseID    string `json:"release,omitempty"`
+	DeploymentID string `json:"deployment,omitempty"`
+
+	// Error is the reason a release could not be created or deployed
+	Error string `json:"error,omitempty"`
+}
+
+// EnvGroupUpdate is the result of changing an env group or the env groups
+// attached to an app, including the releases created for the affected apps
+type EnvGroupUpdate struct {
+	EnvGroup *EnvGroup             `json:"env_group,omitempty"`
+	Releases []*EnvGroupAppRelease `json:"releases,omitempty"`
+}
+
+// QuotaResources are the amounts of resources which are limited by app
+// quotas, with zero values in limits meaning no limit
+type QuotaResources struct {
+	// Jobs is the number of formation and one-off jobs
+	Jobs int64 `json:"jobs,omitempty"`
+
+	// Memory is the total memory of jobs in bytes
+	Memory int64 `json:"memory,omitempty"`
+
+	// CPU is the total CPU of jobs in milliCPU
+	CPU int64 `json:"cpu,omitempty"`
+
+	// Volumes is the number of volumes used by formation jobs
+	Volumes int64 `json:"volumes,omitempty"`
+
+	// Routes is the number of HTTP and TCP routes
+	Routes int64 `json:"routes,omitempty"`
+}
+
+// AppQuota limits the resources used by an app, which are checked when
+// scaling the app, running jobs and adding routes
+type AppQuota struct {
+	AppID  string         `json:"app,omitempty"`
+	Limits QuotaResources `json:"limits"`
+
+	// Usage is the app's current resource usage, which is only set when
+	// getting the quota
+	Usage *QuotaResources `json:"usage,omitempty"`
+
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// AppManifest is a declarative description of an app's configuration which
+// is applied as a single release, formation and route change.
+//
+// Fields which are nil are left unchanged when the manifest is applied,
+// whereas non-nil fields replace the app's existing configuration (so for
+// example an empty list of routes deletes all of the app's routes).
+type AppManifest struct {
+	// Env is the release env, which is merged with the env of the app's
+	// resources and env groups (with the manifest taking precedence)
+	Env map[string]string `json:"env"`
+
+	// Processes are merged with the existing process types of the same
+	// name, with process types which are not listed being removed
+	Processes map[string]json.RawMessage `json:"processes"`
+
+	// Formation is the number of each process type to run, with process
+	// types which are not listed being scaled to zero
+	Formation map[string]int `json:"formation"`
+
+	Routes []*ManifestRoute `json:"routes"`
+
+	// Resources are the names or IDs of providers to provision resources
+	// from, with existing resources from providers which are not listed
+	// being left as they are
+	Resources []string `json:"resources"`
+
+	Deploy *ManifestDeploy `json:"deploy"`
+}
+
+// ManifestRoute is a route in an AppManifest, with HTTP routes being
+// identified by their domain and path and TCP routes by their port
+type ManifestRoute struct {
+	// Type is either "http" (the default) or "tcp"
+	Type string `json:"type,omitempty"`
+
+	Domain string `json:"domain,omitempty"`
+	Path   string `json:"path,omitempty"`
+	Port   int32  `json:"port,omitempty"`
+
+	// Service defaults to the app's web service (<app>-web)
+	Service       string `json:"service,omitempty"`
+	Leader        bool   `json:"leader,omitempty"`
+	Sticky        bool   `json:"sticky,omitempty"`
+	DrainBackends bool   `json:"drain_backends,omitempty"`
+}
+
+// Key returns the key which identifies the route amongst the app's routes
+func (r *ManifestRoute) Key() string {
+	if r.Type == "tcp" {
+		return fmt.Sprintf("tcp:%d", r.Port)
+	}
+	return "http:" + r.Domain + r.Path
+}
+
+// String returns the route's service and options, which is how routes with
+// the same key are compared
+func (r *ManifestRoute) String() string {
+	s := r.Service
+	for _, opt := range []struct {
+		name string
+		set  bool
+	}{
+		{"leader", r.Leader},
+		{"sticky", r.Sticky},
+		{"drain_backends", r.DrainBackends},
+	} {
+		if opt.set {
+			s += " " + opt.name
+		}
+	}
+	return s
+}
+
+// ManifestDeploy is the deploy configuration of an AppManifest
+type ManifestDeploy struct {
+	Strategy string `json:"strategy,omitempty"`
+	Timeout  int32  `json:"timeout,omitempty"`
+}
+
+type ManifestChangeOp string
+
+const (
+	ManifestChangeAdd    ManifestChangeOp = "add"
+	ManifestChangeUpdate ManifestChangeOp = "update"
+	ManifestChangeRemove ManifestChangeOp = "remove"
+)
+
+// ManifestChange is a single change made by applying an AppManifest, with
+// Type being one of "env", "process", "formation", "route", "resource" or
+// "deploy", and the values of secret env vars being masked
+type ManifestChange struct {
+	Type string           `json:"type"`
+	Op   ManifestChangeOp `json:"op"`
+	Name string           `json:"name"`
+	Old  string           `json:"old,omitempty"`
+	New  string           `json:"new,omitempty"`
+}
+
+// ManifestDiff is the difference between an AppManifest and the app's
+// current configuration, along with the release and deployment created if
+// the manifest was applied
+type ManifestDiff struct {
+	Changes      []*ManifestChange `json:"changes"`
+	ReleaseID    string            `json:"release,omitempty"`
+	DeploymentID string            `json:"deployment,omitempty"`
+}
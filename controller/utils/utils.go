@@ -11,6 +11,7 @@ import (
 
 	ct "github.com/flynn/flynn/controller/types"
 	discoverd "github.com/flynn/flynn/discoverd/client"
+	"github.com/flynn/flynn/host/resource"
 	host "github.com/flynn/flynn/host/types"
 	"github.com/flynn/flynn/host/volume"
 	"github.com/flynn/flynn/pkg/attempt"
@@ -88,12 +89,22 @@ func JobConfig(f *ct.ExpandedFormation, name, hostID string, uuid string) *host.
 	SetupMountspecs(job, f.Artifacts)
 	if f.App.Meta["flynn-system-app"] == "true" {
 		job.Partition = "system"
+	} else if t.Partition != "" {
+		job.Partition = string(t.Partition)
 	}
 	job.Config.Ports = make([]host.Port, len(t.Ports))
 	for i, p := range t.Ports {
 		job.Config.Ports[i].Proto = p.Proto
 		job.Config.Ports[i].Port = p.Port
 		job.Config.Ports[i].Service = p.Service
+		if p.Service != nil && f.ServiceSuffix != "" {
+			// register the job under the formation's own service,
+			// creating it as it will not have been created up front
+			service := *p.Service
+			service.Name = f.ServiceName(service.Name)
+			service.Create = true
+			job.Config.Ports[i].Service = &service
+		}
 	}
 	return job
 }
@@ -261,6 +272,8 @@ type HostClient interface {
 	VolumeCreator
 	ID() string
 	Tags() map[string]string
+	Capacity() map[resource.Type]int64
+	Cordoned() bool
 	AddJob(*host.Job) error
 	GetJob(id string) (*host.ActiveJob, error)
 	Attach(*host.AttachReq, bool) (cluster.AttachClient, error)
@@ -298,11 +311,14 @@ type ControllerClient interface {
 	FormationListActive() ([]*ct.ExpandedFormation, error)
 	PutJob(*ct.Job) error
 	JobListActive() ([]*ct.Job, error)
+	JobListSince(since time.Time) ([]*ct.Job, error)
 	StreamSinks(since *time.Time, ch chan *ct.Sink) (stream.Stream, error)
 	ListSinks() ([]*ct.Sink, error)
 	VolumeList() ([]*ct.Volume, error)
 	PutVolume(*ct.Volume) error
 	StreamVolumes(since *time.Time, ch chan *ct.Volume) (stream.Stream, error)
+	PutHostDrain(*host.DrainStatus) error
+	HostDrainList() ([]*host.DrainStatus, error)
 }
 
 func ClusterClientWrapper(c *cluster.Client) clusterClientWrapper {
@@ -351,19 +367,31 @@ func FormationTagsEqual(a, b map[string]map[string]string) bool {
 
 var ErrAuthKeyInvalid = errors.New("invalid Auth-Key")
 
+// TokenStore looks up API tokens by their key (e.g. the controller's token
+// repository, or a verifier of OIDC issued JWTs which maps claims to tokens)
+type TokenStore interface {
+	GetByKey(key string) (*ct.Token, error)
+}
+
 type Authorizer struct {
 	authKeys []string
 	authIDs  []string
+	tokens   []TokenStore
 }
 
 type Authorization struct {
 	ID string
+
+	// Token is the API token which was used to authenticate, and is nil
+	// for cluster auth keys, which may perform any operation
+	Token *ct.Token
 }
 
-func NewAuthorizer(authKeys, authIDs []string) *Authorizer {
+func NewAuthorizer(authKeys, authIDs []string, tokens ...TokenStore) *Authorizer {
 	return &Authorizer{
 		authKeys: authKeys,
 		authIDs:  authIDs,
+		tokens:   tokens,
 	}
 }
 
@@ -377,5 +405,96 @@ func (a *Authorizer) Authorize(key string) (*Authorization, error) {
 			return authorization, nil
 		}
 	}
+	if key == "" {
+		return nil, ErrAuthKeyInvalid
+	}
+	for _, tokens := range a.tokens {
+		if tokens == nil {
+			continue
+		}
+		if token, err := tokens.GetByKey(key); err == nil {
+			return &Authorization{ID: token.Name, Token: token}, nil
+		}
+	}
 	return nil, ErrAuthKeyInvalid
 }
+
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Permission is the permission required to perform an API operation
+type Permission struct {
+	// Role is the minimum role required
+	Role ct.TokenRole
+
+	// ResourceType is the type of resource being accessed, with operations
+	// on no particular resource type (e.g. managing tokens) only being
+	// permitted for unrestricted tokens
+	ResourceType ct.TokenResourceType
+
+	// AppIDs are the IDs of the apps being accessed, with app restricted
+	// tokens only being permitted if AppIDs is not empty (or AppIndependent
+	// is set) and all are in the token's apps
+	AppIDs []string
+
+	// AppIndependent is set for resources which do not belong to an app
+	// (e.g. artifacts)
+	AppIndependent bool
+}
+
+// Allows returns whether the authorization permits an operation requiring
+// the given permission
+func (a *Authorization) Allows(p *Permission) bool {
+	t := a.Token
+	if t == nil {
+		return true
+	}
+	if tokenRoleLevel(t.Role) < tokenRoleLevel(p.Role) {
+		return false
+	}
+	if p.ResourceType == "" {
+		return t.Unrestricted()
+	}
+	if len(t.ResourceTypes) > 0 {
+		found := false
+		for _, typ := range t.ResourceTypes {
+			if typ == p.ResourceType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(t.Apps) > 0 {
+		if len(p.AppIDs) == 0 {
+			return p.AppIndependent
+		}
+		for _, id := range p.AppIDs {
+			found := false
+			for _, appID := range t.Apps {
+				if appID == id {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func tokenRoleLevel(role ct.TokenRole) int {
+	switch role {
+	case ct.TokenRoleRead:
+		return 1
+	case ct.TokenRoleDeploy:
+		return 2
+	case ct.TokenRoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
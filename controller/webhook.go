@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/pkg/ctxhelper"
+	"github.com/flynn/flynn/pkg/httphelper"
+	"golang.org/x/net/context"
+)
+
+// Create a new webhook, responding with its secret
+func (c *controllerAPI) CreateWebhook(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	var webhook ct.Webhook
+	if err := httphelper.DecodeJSON(req, &webhook); err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	if err := c.webhookRepo.Add(&webhook); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, &webhook)
+}
+
+// Get a webhook
+func (c *controllerAPI) GetWebhook(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	params, _ := ctxhelper.ParamsFromContext(ctx)
+
+	webhook, err := c.webhookRepo.Get(params.ByName("webhook_id"))
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, webhook)
+}
+
+// List webhooks
+func (c *controllerAPI) GetWebhooks(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	list, err := c.webhookRepo.List()
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, list)
+}
+
+// Remove a webhook
+func (c *controllerAPI) DeleteWebhook(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	params, _ := ctxhelper.ParamsFromContext(ctx)
+
+	webhook, err := c.webhookRepo.Get(params.ByName("webhook_id"))
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	if err := c.webhookRepo.Remove(webhook.ID); err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, webhook)
+}
+
+// List the deliveries of a webhook, most recent first
+func (c *controllerAPI) GetWebhookDeliveries(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	params, _ := ctxhelper.ParamsFromContext(ctx)
+
+	webhook, err := c.webhookRepo.Get(params.ByName("webhook_id"))
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+
+	var count int
+	if v := req.FormValue("count"); v != "" {
+		count, err = strconv.Atoi(v)
+		if err != nil || count < 0 {
+			respondWithError(w, ct.ValidationError{Field: "count", Message: "is invalid"})
+			return
+		}
+	}
+
+	list, err := c.webhookRepo.ListDeliveries(webhook.ID, count)
+	if err != nil {
+		respondWithError(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, list)
+}
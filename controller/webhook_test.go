@@ -0,0 +1,70 @@
+package main
+
+import (
+	ct "github.com/flynn/flynn/controller/types"
+	hh "github.com/flynn/flynn/pkg/httphelper"
+	. "github.com/flynn/go-check"
+)
+
+func (s *S) TestWebhooks(c *C) {
+	app := s.createTestApp(c, &ct.App{Name: "webhooks"})
+
+	// webhooks must have an http URL
+	err := s.c.CreateWebhook(&ct.Webhook{URL: "ftp://example.com"})
+	c.Assert(hh.IsValidationError(err), Equals, true)
+
+	// apps may be given by name and a secret is generated
+	webhook := &ct.Webhook{
+		URL:        "https://example.com/flynn",
+		Apps:       []string{app.Name},
+		EventTypes: []ct.EventType{ct.EventTypeAppRelease},
+	}
+	c.Assert(s.c.CreateWebhook(webhook), IsNil)
+	c.Assert(webhook.ID, Not(Equals), "")
+	c.Assert(webhook.Secret, Not(Equals), "")
+	c.Assert(webhook.Apps, DeepEquals, []string{app.ID})
+
+	// the secret is not returned once the webhook is created
+	gotWebhook, err := s.c.GetWebhook(webhook.ID)
+	c.Assert(err, IsNil)
+	c.Assert(gotWebhook.URL, Equals, webhook.URL)
+	c.Assert(gotWebhook.Secret, Equals, "")
+	list, err := s.c.ListWebhooks()
+	c.Assert(err, IsNil)
+	c.Assert(len(list) > 0, Equals, true)
+	c.Assert(list[0].ID, Equals, webhook.ID)
+
+	// webhooks for other event types are not sent the event
+	other := &ct.Webhook{
+		URL:        "https://example.com/other",
+		EventTypes: []ct.EventType{ct.EventTypeDeployment},
+	}
+	c.Assert(s.c.CreateWebhook(other), IsNil)
+
+	// a delivery is created for matching events
+	release := s.createTestRelease(c, app.ID, &ct.Release{})
+	c.Assert(s.c.SetAppRelease(app.ID, release.ID), IsNil)
+	deliveries, err := s.c.ListWebhookDeliveries(webhook.ID, 0)
+	c.Assert(err, IsNil)
+	c.Assert(deliveries, HasLen, 1)
+	c.Assert(deliveries[0].EventType, Equals, ct.EventTypeAppRelease)
+	c.Assert(deliveries[0].AppID, Equals, app.ID)
+	c.Assert(deliveries[0].State, Equals, ct.WebhookDeliveryStatePending)
+
+	deliveries, err = s.c.ListWebhookDeliveries(other.ID, 0)
+	c.Assert(err, IsNil)
+	c.Assert(deliveries, HasLen, 0)
+
+	for _, w := range []*ct.Webhook{webhook, other} {
+		_, err = s.c.DeleteWebhook(w.ID)
+		c.Assert(err, IsNil)
+		_, err = s.c.GetWebhook(w.ID)
+		c.Assert(err, NotNil)
+	}
+}
+
+func (s *S) TestWebhookSignature(c *C) {
+	// the signature is the hex encoded HMAC-SHA256 of the body
+	sig := ct.WebhookSignature("key", []byte("The quick brown fox jumps over the lazy dog"))
+	c.Assert(sig, Equals, "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8")
+}
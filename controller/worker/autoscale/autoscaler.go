@@ -0,0 +1,418 @@
+// Package autoscale implements a loop which evaluates autoscale policies,
+// scaling process types based on request rates measured by the router and
+// CPU and memory usage measured by hosts.
+package autoscale
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/flynn/flynn/controller/client"
+	"github.com/flynn/flynn/controller/data"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/host/resource"
+	"github.com/flynn/flynn/pkg/cluster"
+	"github.com/flynn/flynn/pkg/postgres"
+	routerc "github.com/flynn/flynn/router/client"
+	"github.com/inconshreveable/log15"
+)
+
+// tolerance is how far the ratio of a measured value to its target can be
+// from 1.0 before scaling, to avoid scaling back and forth on small changes
+const tolerance = 0.1
+
+type ClusterClient interface {
+	Host(id string) (*cluster.Host, error)
+}
+
+type RouterAddrsFunc func() ([]string, error)
+
+// Autoscaler periodically evaluates autoscale policies, issuing scale
+// requests for process types whose load is outside their targets
+type Autoscaler struct {
+	repo        *data.AutoscaleRepo
+	client      controller.Client
+	cluster     ClusterClient
+	routerAddrs RouterAddrsFunc
+	logger      log15.Logger
+	interval    time.Duration
+	stop        chan struct{}
+
+	// samples are the most recently sampled cumulative request counts and
+	// CPU usages, used to determine rates between evaluations
+	samplesMtx sync.Mutex
+	samples    map[string]*sample
+}
+
+type sample struct {
+	value uint64
+	time  time.Time
+}
+
+func NewAutoscaler(db *postgres.DB, client controller.Client, cluster ClusterClient, routerAddrs RouterAddrsFunc, logger log15.Logger, interval time.Duration) *Autoscaler {
+	return &Autoscaler{
+		repo:        data.NewAutoscaleRepo(db),
+		client:      client,
+		cluster:     cluster,
+		routerAddrs: routerAddrs,
+		logger:      logger.New("component", "autoscaler"),
+		interval:    interval,
+		stop:        make(chan struct{}),
+		samples:     make(map[string]*sample),
+	}
+}
+
+func (a *Autoscaler) Start() {
+	go a.run()
+}
+
+func (a *Autoscaler) Stop() {
+	close(a.stop)
+}
+
+func (a *Autoscaler) run() {
+	log := a.logger.New("fn", "run")
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		// evaluate all due policies before waiting for the next tick
+		for {
+			event, err := a.repo.EvaluateNextDue(a.interval, a.evaluate)
+			if err == data.ErrNotFound {
+				break
+			} else if err != nil {
+				log.Error("error evaluating autoscale policy", "err", err)
+				break
+			}
+			if event != nil {
+				log.Info("autoscaled process type", "app.id", event.AppID, "type", event.ProcessType, "from", event.From, "to", event.To, "reason", event.Reason, "err", event.Error)
+			}
+		}
+		a.pruneSamples(time.Now().Add(-10 * a.interval))
+		select {
+		case <-a.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *Autoscaler) evaluate(policy *ct.AutoscalePolicy) *ct.AutoscaleEvent {
+	log := a.logger.New("fn", "evaluate", "app.id", policy.AppID, "type", policy.ProcessType)
+
+	release, err := a.client.GetAppRelease(policy.AppID)
+	if err != nil {
+		if err != controller.ErrNotFound {
+			log.Error("error getting app release", "err", err)
+		}
+		return nil
+	}
+	proc, ok := release.Processes[policy.ProcessType]
+	if !ok {
+		return nil
+	}
+
+	// leave the formation alone whilst it is being deployed
+	deployments, err := a.client.DeploymentList(policy.AppID)
+	if err != nil {
+		log.Error("error listing deployments", "err", err)
+		return nil
+	}
+	if len(deployments) > 0 && (deployments[0].Status == "pending" || deployments[0].Status == "running") {
+		return nil
+	}
+
+	processes := make(map[string]int)
+	formation, err := a.client.GetFormation(policy.AppID, release.ID)
+	if err == nil {
+		for typ, n := range formation.Processes {
+			processes[typ] = n
+		}
+	} else if err != controller.ErrNotFound {
+		log.Error("error getting formation", "err", err)
+		return nil
+	}
+	current := processes[policy.ProcessType]
+
+	jobs, err := a.runningJobs(policy, release.ID)
+	if err != nil {
+		log.Error("error listing jobs", "err", err)
+		return nil
+	}
+	measurements := a.measure(policy, proc, jobs)
+
+	desired, reason := desiredJobs(policy, current, len(jobs), measurements)
+	if desired == current {
+		return nil
+	}
+	// scale immediately if the formation is outside the policy's bounds,
+	// otherwise wait for the cooldown since the last time it was scaled
+	if current >= policy.Min && current <= policy.Max && cooldown(policy, desired > current) > 0 {
+		return nil
+	}
+
+	event := &ct.AutoscaleEvent{
+		AppID:        policy.AppID,
+		ReleaseID:    release.ID,
+		ProcessType:  policy.ProcessType,
+		From:         current,
+		To:           desired,
+		Measurements: measurements,
+		Reason:       reason,
+	}
+	log.Info("scaling process type", "from", current, "to", desired, "reason", reason)
+	processes[policy.ProcessType] = desired
+	if err := a.client.ScaleAppRelease(policy.AppID, release.ID, ct.ScaleOptions{
+		Processes: processes,
+		NoWait:    true,
+	}); err != nil {
+		log.Error("error scaling process type", "err", err)
+		event.Error = err.Error()
+	}
+	return event
+}
+
+func (a *Autoscaler) runningJobs(policy *ct.AutoscalePolicy, releaseID string) ([]*ct.Job, error) {
+	list, err := a.client.JobList(policy.AppID)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []*ct.Job
+	for _, job := range list {
+		if job.ReleaseID == releaseID && job.Type == policy.ProcessType && job.State == ct.JobStateUp {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+// measure measures each of the policy's target metrics across the given
+// running jobs, omitting metrics which could not be measured (e.g. because
+// rates require two samples)
+func (a *Autoscaler) measure(policy *ct.AutoscalePolicy, proc ct.ProcessType, jobs []*ct.Job) []*ct.AutoscaleMeasurement {
+	if len(jobs) == 0 {
+		return nil
+	}
+	resources := proc.Resources
+	resource.SetDefaults(&resources)
+
+	var measurements []*ct.AutoscaleMeasurement
+	if policy.TargetRequestsPerSecond > 0 {
+		if rate, measured := a.requestRate(jobs); measured > 0 {
+			measurements = append(measurements, newMeasurement(
+				ct.AutoscaleMetricRequestsPerSecond,
+				rate/float64(measured),
+				policy.TargetRequestsPerSecond,
+				len(jobs),
+			))
+		}
+	}
+	if policy.TargetCPU > 0 || policy.TargetMemory > 0 {
+		cpu, memory, ok := a.hostUsage(jobs, resources)
+		if ok && policy.TargetCPU > 0 && !math.IsNaN(cpu) {
+			measurements = append(measurements, newMeasurement(ct.AutoscaleMetricCPU, cpu, float64(policy.TargetCPU), len(jobs)))
+		}
+		if ok && policy.TargetMemory > 0 {
+			measurements = append(measurements, newMeasurement(ct.AutoscaleMetricMemory, memory, float64(policy.TargetMemory), len(jobs)))
+		}
+	}
+	return measurements
+}
+
+// requestRate returns the total number of requests per second proxied to the
+// given jobs by all router instances since they were last sampled, along with
+// the number of jobs the total was measured across (jobs which have only just
+// started have no previous sample, so are excluded from both)
+func (a *Autoscaler) requestRate(jobs []*ct.Job) (float64, int) {
+	log := a.logger.New("fn", "requestRate")
+	addrs, err := a.routerAddrs()
+	if err != nil {
+		log.Error("error getting router addresses", "err", err)
+		return 0, 0
+	}
+	ids := make(map[string]struct{}, len(jobs))
+	for _, job := range jobs {
+		ids[job.ID] = struct{}{}
+	}
+	var total float64
+	measured := make(map[string]struct{}, len(jobs))
+	for _, addr := range addrs {
+		stats, err := routerc.NewWithAddr(addr).BackendStats()
+		if err != nil {
+			log.Error("error getting router backend stats", "router.addr", addr, "err", err)
+			continue
+		}
+		for _, s := range stats {
+			if _, ok := ids[s.JobID]; !ok {
+				continue
+			}
+			key := fmt.Sprintf("requests:%s:%s:%s", addr, s.Service, s.Addr)
+			if rate, ok := a.sampleRate(key, s.Requests); ok {
+				total += rate
+				measured[s.JobID] = struct{}{}
+			}
+		}
+	}
+	return total, len(measured)
+}
+
+// hostUsage returns the average CPU utilisation and memory usage of the
+// given jobs as percentages of their limits, with CPU utilisation being NaN
+// if the jobs have not been sampled before
+func (a *Autoscaler) hostUsage(jobs []*ct.Job, resources resource.Resources) (cpu, memory float64, ok bool) {
+	log := a.logger.New("fn", "hostUsage")
+	cpuLimit := float64(*resources[resource.TypeCPU].Limit) / 1000
+	memoryLimit := float64(*resources[resource.TypeMemory].Limit)
+
+	var cpuTotal, memoryTotal float64
+	var cpuCount, memoryCount int
+	for _, job := range jobs {
+		host, err := a.cluster.Host(job.HostID)
+		if err != nil {
+			log.Error("error getting host client", "host.id", job.HostID, "err", err)
+			continue
+		}
+		stats, err := host.GetJobStats(job.ID)
+		if err != nil {
+			log.Error("error getting job stats", "job.id", job.ID, "err", err)
+			continue
+		}
+		if rate, ok := a.sampleRate("cpu:"+job.ID, stats.CPUUsage); ok && cpuLimit > 0 {
+			// rate is CPU nanoseconds per second
+			cpuTotal += rate / float64(time.Second) / cpuLimit * 100
+			cpuCount++
+		}
+		if memoryLimit > 0 {
+			memoryTotal += float64(stats.MemoryUsage) / memoryLimit * 100
+			memoryCount++
+		}
+	}
+	if memoryCount == 0 {
+		return 0, 0, false
+	}
+	cpu = math.NaN()
+	if cpuCount > 0 {
+		cpu = cpuTotal / float64(cpuCount)
+	}
+	return cpu, memoryTotal / float64(memoryCount), true
+}
+
+// sampleRate records a sample of the cumulative counter with the given key
+// and returns its rate of change per second since the previous sample
+func (a *Autoscaler) sampleRate(key string, value uint64) (float64, bool) {
+	a.samplesMtx.Lock()
+	defer a.samplesMtx.Unlock()
+	now := time.Now()
+	prev, ok := a.samples[key]
+	a.samples[key] = &sample{value: value, time: now}
+	if !ok || value < prev.value {
+		return 0, false
+	}
+	elapsed := now.Sub(prev.time).Seconds()
+	if elapsed <= 0 {
+		return 0, false
+	}
+	return float64(value-prev.value) / elapsed, true
+}
+
+// pruneSamples removes samples taken before the given time, which belong to
+// jobs which are no longer running
+func (a *Autoscaler) pruneSamples(before time.Time) {
+	a.samplesMtx.Lock()
+	defer a.samplesMtx.Unlock()
+	for key, s := range a.samples {
+		if s.time.Before(before) {
+			delete(a.samples, key)
+		}
+	}
+}
+
+// newMeasurement returns a measurement of the given metric's average value
+// across the given number of running jobs, determining the number of jobs
+// required to bring the average value to the target
+func newMeasurement(metric ct.AutoscaleMetric, value, target float64, running int) *ct.AutoscaleMeasurement {
+	m := &ct.AutoscaleMeasurement{
+		Metric:  metric,
+		Value:   value,
+		Target:  target,
+		Desired: running,
+	}
+	if ratio := value / target; math.Abs(ratio-1) > tolerance {
+		m.Desired = int(math.Ceil(float64(running) * ratio))
+	}
+	return m
+}
+
+// desiredJobs determines the number of jobs the process type should be
+// scaled to from the measurement requiring the most jobs, bounded by the
+// policy's min and max, and returns the reason for the decision.
+//
+// Measurements are not used to scale down whilst some of the current jobs are
+// not running, as their load cannot be measured.
+func desiredJobs(policy *ct.AutoscalePolicy, current, running int, measurements []*ct.AutoscaleMeasurement) (int, string) {
+	desired, reason := current, ""
+	var highest *ct.AutoscaleMeasurement
+	for _, m := range measurements {
+		if highest == nil || m.Desired > highest.Desired {
+			highest = m
+		}
+	}
+	if highest != nil && (highest.Desired > current || running >= current) {
+		desired = highest.Desired
+		reason = describeMeasurement(highest)
+	}
+
+	if desired > policy.Max {
+		if reason == "" {
+			reason = fmt.Sprintf("%d jobs is above the maximum of %d", current, policy.Max)
+		} else {
+			reason += fmt.Sprintf(", limited to the maximum of %d", policy.Max)
+		}
+		desired = policy.Max
+	} else if desired < policy.Min {
+		if reason == "" {
+			reason = fmt.Sprintf("%d jobs is below the minimum of %d", current, policy.Min)
+		} else {
+			reason += fmt.Sprintf(", limited to the minimum of %d", policy.Min)
+		}
+		desired = policy.Min
+	}
+	return desired, reason
+}
+
+func describeMeasurement(m *ct.AutoscaleMeasurement) string {
+	comparison := "within 10% of"
+	if ratio := m.Value / m.Target; ratio-1 > tolerance {
+		comparison = "above"
+	} else if 1-ratio > tolerance {
+		comparison = "below"
+	}
+	var s string
+	switch m.Metric {
+	case ct.AutoscaleMetricRequestsPerSecond:
+		s = fmt.Sprintf("average of %.1f requests per second per job is %s the target of %g", m.Value, comparison, m.Target)
+	case ct.AutoscaleMetricCPU:
+		s = fmt.Sprintf("average CPU utilisation of %.1f%% is %s the target of %g%%", m.Value, comparison, m.Target)
+	case ct.AutoscaleMetricMemory:
+		s = fmt.Sprintf("average memory usage of %.1f%% is %s the target of %g%%", m.Value, comparison, m.Target)
+	}
+	return fmt.Sprintf("%s (requires %d jobs)", s, m.Desired)
+}
+
+// cooldown returns how long the policy must wait before scaling the process
+// type up or down again
+func cooldown(policy *ct.AutoscalePolicy, up bool) time.Duration {
+	if policy.LastScaledAt == nil {
+		return 0
+	}
+	seconds := policy.ScaleDownCooldown
+	if up {
+		seconds = policy.ScaleUpCooldown
+	}
+	if remaining := policy.LastScaledAt.Add(time.Duration(seconds) * time.Second).Sub(time.Now()); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
@@ -0,0 +1,111 @@
+package autoscale
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ct "github.com/flynn/flynn/controller/types"
+	router "github.com/flynn/flynn/router/types"
+	. "github.com/flynn/go-check"
+	"github.com/inconshreveable/log15"
+)
+
+// Hook gocheck up to the "go test" runner
+func Test(t *testing.T) { TestingT(t) }
+
+type S struct{}
+
+var _ = Suite(&S{})
+
+func (S) TestNewMeasurement(c *C) {
+	for _, t := range []struct {
+		value   float64
+		target  float64
+		running int
+		desired int
+	}{
+		{value: 50, target: 50, running: 3, desired: 3},
+		{value: 54, target: 50, running: 3, desired: 3},
+		{value: 46, target: 50, running: 3, desired: 3},
+		{value: 100, target: 50, running: 3, desired: 6},
+		{value: 60, target: 50, running: 3, desired: 4},
+		{value: 10, target: 50, running: 4, desired: 1},
+		{value: 0, target: 50, running: 4, desired: 0},
+	} {
+		m := newMeasurement(ct.AutoscaleMetricCPU, t.value, t.target, t.running)
+		c.Assert(m.Desired, Equals, t.desired, Commentf("value=%v target=%v running=%d", t.value, t.target, t.running))
+	}
+}
+
+func (S) TestDesiredJobs(c *C) {
+	policy := &ct.AutoscalePolicy{Min: 2, Max: 5}
+	cpu := func(desired int) *ct.AutoscaleMeasurement {
+		return &ct.AutoscaleMeasurement{Metric: ct.AutoscaleMetricCPU, Value: 80, Target: 50, Desired: desired}
+	}
+	rps := func(desired int) *ct.AutoscaleMeasurement {
+		return &ct.AutoscaleMeasurement{Metric: ct.AutoscaleMetricRequestsPerSecond, Value: 5, Target: 10, Desired: desired}
+	}
+	for _, t := range []struct {
+		desc         string
+		current      int
+		running      int
+		measurements []*ct.AutoscaleMeasurement
+		desired      int
+	}{
+		{desc: "no measurements within bounds", current: 3, running: 3, desired: 3},
+		{desc: "no measurements below min", current: 0, running: 0, desired: 2},
+		{desc: "no measurements above max", current: 7, running: 7, desired: 5},
+		{desc: "scale up", current: 3, running: 3, measurements: []*ct.AutoscaleMeasurement{cpu(4)}, desired: 4},
+		{desc: "scale up to max", current: 3, running: 3, measurements: []*ct.AutoscaleMeasurement{cpu(10)}, desired: 5},
+		{desc: "scale down to min", current: 3, running: 3, measurements: []*ct.AutoscaleMeasurement{rps(1)}, desired: 2},
+		{desc: "highest measurement wins", current: 3, running: 3, measurements: []*ct.AutoscaleMeasurement{rps(2), cpu(4)}, desired: 4},
+		{desc: "no scale down whilst jobs starting", current: 4, running: 3, measurements: []*ct.AutoscaleMeasurement{rps(3)}, desired: 4},
+		{desc: "scale up whilst jobs starting", current: 4, running: 3, measurements: []*ct.AutoscaleMeasurement{cpu(5)}, desired: 5},
+	} {
+		desired, reason := desiredJobs(policy, t.current, t.running, t.measurements)
+		c.Assert(desired, Equals, t.desired, Commentf(t.desc))
+		if desired != t.current {
+			c.Assert(reason, Not(Equals), "", Commentf(t.desc))
+		}
+	}
+}
+
+func (S) TestRequestRateAfterScaleUp(c *C) {
+	stats := []*router.BackendStats{
+		{Backend: router.Backend{Service: "web", Addr: "10.0.0.1:80", JobID: "job1"}, Requests: 100},
+		{Backend: router.Backend{Service: "web", Addr: "10.0.0.2:80", JobID: "job2"}, Requests: 100},
+		{Backend: router.Backend{Service: "web", Addr: "10.0.0.3:80", JobID: "job3"}, Requests: 500},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stats)
+	}))
+	defer srv.Close()
+	addr := strings.TrimPrefix(srv.URL, "http://")
+
+	a := &Autoscaler{
+		routerAddrs: func() ([]string, error) { return []string{addr}, nil },
+		logger:      log15.New(),
+		samples:     make(map[string]*sample),
+	}
+
+	// job3 has only just started so has no previous sample, and should be
+	// excluded from the average rather than counted as receiving nothing
+	before := time.Now().Add(-10 * time.Second)
+	for _, s := range stats[:2] {
+		a.samples[fmt.Sprintf("requests:%s:%s:%s", addr, s.Service, s.Addr)] = &sample{value: 0, time: before}
+	}
+	jobs := []*ct.Job{{ID: "job1"}, {ID: "job2"}, {ID: "job3"}}
+	policy := &ct.AutoscalePolicy{TargetRequestsPerSecond: 10}
+	measurements := a.measure(policy, ct.ProcessType{}, jobs)
+	c.Assert(measurements, HasLen, 1)
+	m := measurements[0]
+	c.Assert(m.Metric, Equals, ct.AutoscaleMetricRequestsPerSecond)
+	c.Assert(m.Value > 9 && m.Value <= 10, Equals, true, Commentf("value=%v", m.Value))
+	c.Assert(m.Desired, Equals, 3)
+}
@@ -1,11 +1,33 @@
 package deployment
 
+import "github.com/inconshreveable/log15"
+
 func (d *DeployJob) deployAllAtOnce() error {
 	log := d.logger.New("fn", "deployAllAtOnce")
 	log.Info("starting all-at-once deployment")
 
-	d.newFormation.Processes = make(map[string]int, len(d.oldFormation.Processes))
-	for typ, count := range d.oldFormation.Processes {
+	// the new release is scaled up and the old one down in a single
+	// step, so pausing is only possible before it starts (e.g. while the
+	// release phase runs)
+	if err := d.checkPause(); err != nil {
+		return err
+	}
+	if err := d.scaleAllAtOnce(log); err != nil {
+		return err
+	}
+
+	// treat the deployment as finished now (rather than waiting for the
+	// jobs to actually stop) as we can trust that the scheduler will
+	// actually kill the jobs, so no need to delay the deployment.
+	log.Info("finished all-at-once deployment")
+	return nil
+}
+
+// scaleAllAtOnce scales the new formation up in full and then scales the old
+// formation to zero without waiting for its jobs to stop
+func (d *DeployJob) scaleAllAtOnce(log log15.Logger) error {
+	d.newFormation.Processes = make(map[string]int, len(d.Processes))
+	for typ, count := range d.Processes {
 		// only scale new processes which still exist
 		if _, ok := d.newRelease.Processes[typ]; ok {
 			d.newFormation.Processes[typ] = count
@@ -28,10 +50,5 @@ func (d *DeployJob) deployAllAtOnce() error {
 		log.Error("error scaling old formation to zero", "release.id", d.OldReleaseID, "err", err)
 		return ErrSkipRollback{err.Error()}
 	}
-
-	// treat the deployment as finished now (rather than waiting for the
-	// jobs to actually stop) as we can trust that the scheduler will
-	// actually kill the jobs, so no need to delay the deployment.
-	log.Info("finished all-at-once deployment")
 	return nil
 }
@@ -0,0 +1,213 @@
+package deployment
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	ct "github.com/flynn/flynn/controller/types"
+	worker "github.com/flynn/flynn/controller/worker/types"
+	"github.com/inconshreveable/log15"
+)
+
+// approvalWebhookTimeout is the timeout for sending a deployment approval
+// request to the webhook of an app's deploy gate
+const approvalWebhookTimeout = 10 * time.Second
+
+// waitForApproval waits for a deployment which is held by a deploy gate to be
+// approved, sending the approval request to the gate's webhook the first time
+// the deployment is handled and failing the deployment if it is rejected or
+// not approved before the gate's timeout
+func (c *context) waitForApproval(l log15.Logger, ctl *control, deployment *ct.Deployment, events chan<- ct.DeploymentEvent) error {
+	if deployment.DeployApproval == nil {
+		return nil
+	}
+	log := l.New("fn", "waitForApproval")
+
+	state, entries, err := c.getApproval(deployment.ID)
+	if err != nil {
+		log.Error("error getting deployment approval state", "err", err)
+		return err
+	}
+	var requestedAt time.Time
+	for _, entry := range entries {
+		if entry.Action == ct.DeploymentApprovalActionRequested && entry.CreatedAt != nil {
+			requestedAt = *entry.CreatedAt
+		}
+	}
+	if state == ct.DeploymentApprovalStatePending && requestedAt.IsZero() {
+		requestedAt = time.Now()
+		if err := c.appendApprovalLog(deployment.ID, &ct.DeploymentApprovalEntry{
+			Action:    ct.DeploymentApprovalActionRequested,
+			CreatedAt: &requestedAt,
+		}); err != nil {
+			log.Error("error recording deployment approval request", "err", err)
+			return err
+		}
+		if url := deployment.DeployApproval.WebhookURL; url != "" {
+			log.Info("sending deployment approval request", "url", url)
+			entry := &ct.DeploymentApprovalEntry{Action: ct.DeploymentApprovalActionWebhookSent}
+			if err := c.sendApprovalWebhook(deployment, requestedAt); err != nil {
+				// the deployment can still be approved using the API
+				log.Error("error sending deployment approval request", "url", url, "err", err)
+				entry.Action = ct.DeploymentApprovalActionWebhookFailed
+				entry.Comment = err.Error()
+			}
+			now := time.Now()
+			entry.CreatedAt = &now
+			if err := c.appendApprovalLog(deployment.ID, entry); err != nil {
+				log.Error("error recording deployment approval webhook", "err", err)
+			}
+		}
+	}
+
+	timeout := time.Duration(deployment.DeployApproval.Timeout) * time.Second
+	log.Info("waiting for deployment approval", "timeout", timeout)
+	deadline := time.After(requestedAt.Add(timeout).Sub(time.Now()))
+	ticker := time.NewTicker(controlPollInterval)
+	defer ticker.Stop()
+	for {
+		switch state {
+		case ct.DeploymentApprovalStateApproved:
+			log.Info("deployment approved")
+			events <- ct.DeploymentEvent{
+				ReleaseID: deployment.NewReleaseID,
+				Status:    "running",
+			}
+			return nil
+		case ct.DeploymentApprovalStateRejected:
+			msg := rejectedMessage(entries, timeout)
+			log.Info(msg)
+			// nothing has been deployed, so there is nothing to roll back
+			return ErrSkipRollback{msg}
+		}
+
+		select {
+		case <-ticker.C:
+			state, entries, err = c.getApproval(deployment.ID)
+			if err != nil {
+				log.Error("error getting deployment approval state", "err", err)
+			}
+		case <-deadline:
+			now := time.Now()
+			if err := c.execWithRetries("deployment_update_approval", deployment.ID, string(ct.DeploymentApprovalStateRejected), &ct.DeploymentApprovalEntry{
+				Action:    ct.DeploymentApprovalActionTimedOut,
+				CreatedAt: &now,
+			}); err != nil {
+				log.Error("error recording deployment approval timeout", "err", err)
+			}
+			// the deployment may have been approved or rejected just
+			// before timing out, in which case the state is handled
+			// at the start of the loop
+			state, entries, err = c.getApproval(deployment.ID)
+			if err != nil || state == ct.DeploymentApprovalStatePending {
+				return ErrSkipRollback{timedOutMessage(timeout)}
+			}
+			deadline = nil
+		case <-ctl.stop:
+			if ctl.IsAborted() {
+				return ErrSkipRollback{ErrAborted.Error()}
+			}
+			return worker.ErrStopped
+		}
+	}
+}
+
+// rejectedMessage returns the reason a deployment was rejected given its
+// approval audit trail
+func rejectedMessage(entries []*ct.DeploymentApprovalEntry, timeout time.Duration) string {
+	msg := "deployment rejected"
+	if len(entries) == 0 {
+		return msg
+	}
+	last := entries[len(entries)-1]
+	switch last.Action {
+	case ct.DeploymentApprovalActionTimedOut:
+		return timedOutMessage(timeout)
+	case ct.DeploymentApprovalActionRejected:
+		if last.Actor != "" {
+			msg += " by " + last.Actor
+		}
+		if last.Comment != "" {
+			msg += ": " + last.Comment
+		}
+	}
+	return msg
+}
+
+func timedOutMessage(timeout time.Duration) string {
+	return fmt.Sprintf("deployment was not approved within %s", timeout)
+}
+
+func (c *context) getApproval(id string) (state ct.DeploymentApprovalState, entries []*ct.DeploymentApprovalEntry, err error) {
+	var s *string
+	err = execAttempts.Run(func() error {
+		return c.db.QueryRow("deployment_select_approval", id).Scan(&s, &entries)
+	})
+	if s != nil {
+		state = ct.DeploymentApprovalState(*s)
+	}
+	return
+}
+
+func (c *context) appendApprovalLog(id string, entry *ct.DeploymentApprovalEntry) error {
+	return c.execWithRetries("deployment_append_approval_log", id, entry)
+}
+
+// sendApprovalWebhook POSTs a DeploymentApprovalRequest, which includes the
+// differences between the old and new releases, to the webhook of the
+// deployment's deploy gate, signed with the gate's secret
+func (c *context) sendApprovalWebhook(deployment *ct.Deployment, requestedAt time.Time) error {
+	app, err := c.client.GetApp(deployment.AppID)
+	if err != nil {
+		return err
+	}
+	newRelease, err := c.client.GetRelease(deployment.NewReleaseID)
+	if err != nil {
+		return err
+	}
+	var oldRelease *ct.Release
+	if deployment.OldReleaseID != "" {
+		oldRelease, err = c.client.GetRelease(deployment.OldReleaseID)
+		if err != nil {
+			return err
+		}
+	}
+	expiresAt := requestedAt.Add(time.Duration(deployment.DeployApproval.Timeout) * time.Second)
+	data, err := json.Marshal(&ct.DeploymentApprovalRequest{
+		Deployment:  deployment,
+		AppName:     app.Name,
+		Diff:        ct.DiffReleases(oldRelease, newRelease),
+		ApprovePath: fmt.Sprintf("/deployments/%s/approve", deployment.ID),
+		RejectPath:  fmt.Sprintf("/deployments/%s/reject", deployment.ID),
+		ExpiresAt:   &expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", deployment.DeployApproval.WebhookURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// sign the request with the app's deploy gate secret so that the
+	// receiver can verify it was sent by the controller
+	config := app.DeployApproval()
+	if config == nil || config.Secret == "" {
+		return errors.New("deploy gate has no secret to sign the approval request with")
+	}
+	req.Header.Set(ct.WebhookSignatureHeader, ct.WebhookSignature(config.Secret, data))
+	client := &http.Client{Timeout: approvalWebhookTimeout}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return nil
+}
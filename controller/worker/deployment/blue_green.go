@@ -0,0 +1,160 @@
+package deployment
+
+import (
+	"time"
+
+	ct "github.com/flynn/flynn/controller/types"
+	worker "github.com/flynn/flynn/controller/worker/types"
+	router "github.com/flynn/flynn/router/types"
+)
+
+// deployBlueGreen scales the new release up in full under separate services
+// to the old release, switches the app's routes to the new services once all
+// the new jobs are up (which means they are passing health checks) and then
+// keeps the old release running for the warm period before scaling it down,
+// so that rolling back during the warm period just involves switching the
+// routes back
+func (d *DeployJob) deployBlueGreen() (err error) {
+	log := d.logger.New("fn", "deployBlueGreen")
+	log.Info("starting blue-green deployment")
+
+	// keep the suffix of a previous attempt at the deployment so that any
+	// new jobs which are already running are reused
+	if d.newFormation.ServiceSuffix == "" || d.newFormation.ServiceSuffix == d.oldFormation.ServiceSuffix {
+		d.newFormation.ServiceSuffix = blueGreenServiceSuffix(d.oldFormation.ServiceSuffix)
+	}
+
+	d.newFormation.Processes = make(map[string]int, len(d.Processes))
+	for typ, count := range d.Processes {
+		// only scale new processes which still exist
+		if _, ok := d.newRelease.Processes[typ]; ok {
+			d.newFormation.Processes[typ] = count
+		}
+	}
+	log.Info("scaling up new formation", "release.id", d.NewReleaseID, "service_suffix", d.newFormation.ServiceSuffix, "processes", d.newFormation.Processes)
+	if err := d.scaleNewRelease(); err != nil {
+		log.Error("error scaling up new formation", "release.id", d.NewReleaseID, "err", err)
+		return err
+	}
+	d.deployEvents <- ct.DeploymentEvent{
+		ReleaseID: d.NewReleaseID,
+		Step:      ct.DeploymentStepBlueGreenUp,
+	}
+
+	if err := d.checkPause(); err != nil {
+		return err
+	}
+
+	// switch routes from the old release's services to the new release's
+	// services (routers apply each route change atomically, so requests
+	// for a route are either sent to the old release or the new one)
+	services := make(map[string]string)
+	oldServices := make(map[string]string)
+	for typ, proc := range d.newRelease.Processes {
+		oldProc, ok := d.oldRelease.Processes[typ]
+		if !ok || proc.Service == "" || oldProc.Service == "" {
+			continue
+		}
+		services[d.oldFormation.ServiceName(oldProc.Service)] = d.newFormation.ServiceName(proc.Service)
+		oldServices[d.newFormation.ServiceName(proc.Service)] = d.oldFormation.ServiceName(oldProc.Service)
+	}
+	routes, err := d.client.AppRouteList(d.AppID)
+	if err != nil {
+		log.Error("error listing app routes", "err", err)
+		return err
+	}
+	// routes already switched before the deployment was paused are
+	// switched back along with the others if it fails
+	switched := make(map[*router.Route]string)
+	for _, route := range routes {
+		if service, ok := oldServices[route.Service]; ok {
+			switched[route] = service
+		}
+	}
+	defer func() {
+		if err == nil || err == worker.ErrStopped || err == ErrPaused || IsSkipRollback(err) || len(switched) == 0 {
+			return
+		}
+		log.Info("switching routes back to the old release")
+		for route, service := range switched {
+			route.Service = service
+			if err := d.client.UpdateRoute(d.AppID, route.FormattedID(), route); err != nil {
+				log.Error("error switching route back to the old release", "route.id", route.FormattedID(), "err", err)
+			}
+		}
+		d.deployEvents <- ct.DeploymentEvent{
+			ReleaseID: d.OldReleaseID,
+			Step:      ct.DeploymentStepRoutesRestored,
+		}
+	}()
+	for _, route := range routes {
+		service, ok := services[route.Service]
+		if !ok {
+			continue
+		}
+		log.Info("switching route to the new release", "route.id", route.FormattedID(), "service", service)
+		oldService := route.Service
+		route.Service = service
+		if err := d.client.UpdateRoute(d.AppID, route.FormattedID(), route); err != nil {
+			log.Error("error switching route to the new release", "route.id", route.FormattedID(), "err", err)
+			route.Service = oldService
+			return err
+		}
+		switched[route] = oldService
+	}
+	d.deployEvents <- ct.DeploymentEvent{
+		ReleaseID: d.NewReleaseID,
+		Step:      ct.DeploymentStepRoutesSwitched,
+	}
+
+	warmPeriod := ct.DefaultDeployWarmPeriod
+	if d.DeployWarmPeriod != nil {
+		warmPeriod = *d.DeployWarmPeriod
+	}
+	log.Info("keeping old formation warm", "release.id", d.OldReleaseID, "warm_period", warmPeriod)
+	if d.DeployRollback != nil {
+		// monitor the new release while the old one is warm, rolling
+		// back by switching the routes back if it fails
+		bakePeriod := d.DeployRollback.BakePeriod
+		if bakePeriod < warmPeriod {
+			bakePeriod = warmPeriod
+		}
+		if err := d.bake(time.Duration(bakePeriod) * time.Second); err != nil {
+			return err
+		}
+	} else {
+		select {
+		case <-time.After(time.Duration(warmPeriod) * time.Second):
+		case <-d.stop:
+			return worker.ErrStopped
+		}
+	}
+
+	// pausing here keeps the old formation warm until the deployment is
+	// resumed, so the routes can still be switched back
+	if err := d.checkPause(); err != nil {
+		return err
+	}
+
+	log.Info("scaling old formation to zero", "release.id", d.OldReleaseID)
+	for typ := range d.oldRelease.Processes {
+		d.oldFormation.Processes[typ] = 0
+	}
+	if err := d.scaleOldRelease(false); err != nil {
+		// the routes now point at the new jobs, so don't roll back
+		log.Error("error scaling old formation to zero", "release.id", d.OldReleaseID, "err", err)
+		return ErrSkipRollback{err.Error()}
+	}
+
+	log.Info("finished blue-green deployment")
+	return nil
+}
+
+// blueGreenServiceSuffix returns the service suffix to use for the new
+// release given the suffix of the old release
+func blueGreenServiceSuffix(old string) string {
+	if old == "blue" {
+		return "green"
+	}
+	return "blue"
+}
@@ -0,0 +1,171 @@
+package deployment
+
+import (
+	"fmt"
+	"time"
+
+	ct "github.com/flynn/flynn/controller/types"
+	worker "github.com/flynn/flynn/controller/worker/types"
+	router "github.com/flynn/flynn/router/types"
+)
+
+// deployCanary starts a small number of jobs from the new release, routes a
+// share of the app's HTTP requests to them and observes them for a window of
+// time, promoting the new release if none of them failed and otherwise
+// aborting the deployment (which rolls back to the old release)
+func (d *DeployJob) deployCanary() (err error) {
+	log := d.logger.New("fn", "deployCanary")
+	log.Info("starting canary deployment")
+
+	config := d.DeployCanary
+	if config == nil {
+		config = &ct.CanaryConfig{
+			Count:  ct.DefaultCanaryCount,
+			Weight: ct.DefaultCanaryWeight,
+			Window: ct.DefaultCanaryWindow,
+		}
+	}
+
+	// start the canary jobs, watching job events first so that canary
+	// jobs which fail once up are not missed
+	jobEvents := make(chan *ct.Job)
+	stream, err := d.client.StreamJobEvents(d.AppID, jobEvents)
+	if err != nil {
+		log.Error("error streaming job events", "err", err)
+		return err
+	}
+	defer stream.Close()
+
+	if err := d.checkPause(); err != nil {
+		return err
+	}
+	for typ, count := range d.Processes {
+		if _, ok := d.newRelease.Processes[typ]; !ok {
+			continue
+		}
+		if count > config.Count {
+			count = config.Count
+		}
+		d.newFormation.Processes[typ] = count
+	}
+	log.Info("scaling up canary jobs", "release.id", d.NewReleaseID, "processes", d.newFormation.Processes)
+	if err := d.scaleNewRelease(); err != nil {
+		log.Error("error scaling up canary jobs", "release.id", d.NewReleaseID, "err", err)
+		return err
+	}
+	d.deployEvents <- ct.DeploymentEvent{
+		ReleaseID: d.NewReleaseID,
+		Step:      ct.DeploymentStepCanaryStarted,
+	}
+
+	// route a share of requests to the canary jobs, making sure the
+	// routes are reset however the deployment finishes
+	routes, err := d.canaryRoutes()
+	if err != nil {
+		log.Error("error listing app routes", "err", err)
+		return err
+	}
+	defer func() {
+		if resetErr := d.setCanaryRoutes(routes, "", 0); resetErr != nil {
+			log.Error("error resetting canary routes", "err", resetErr)
+			if err == nil {
+				err = resetErr
+			}
+		}
+	}()
+	log.Info("routing requests to canary jobs", "routes", len(routes), "weight", config.Weight)
+	if err := d.setCanaryRoutes(routes, d.NewReleaseID, config.Weight); err != nil {
+		log.Error("error routing requests to canary jobs", "err", err)
+		return err
+	}
+	d.deployEvents <- ct.DeploymentEvent{
+		ReleaseID: d.NewReleaseID,
+		Step:      ct.DeploymentStepCanaryRouted,
+	}
+
+	log.Info("observing canary jobs", "window", config.Window)
+	if err := d.observeCanary(jobEvents, time.Duration(config.Window)*time.Second); err != nil {
+		if err == worker.ErrStopped {
+			return err
+		}
+		log.Error("aborting canary deployment", "err", err)
+		d.deployEvents <- ct.DeploymentEvent{
+			ReleaseID: d.NewReleaseID,
+			Step:      ct.DeploymentStepCanaryAborted,
+			Error:     err.Error(),
+		}
+		return err
+	}
+
+	// pausing before promotion resets the routes, and resuming routes
+	// requests to the canary jobs and observes them again
+	if err := d.checkPause(); err != nil {
+		return err
+	}
+
+	log.Info("promoting canary release", "release.id", d.NewReleaseID)
+	d.deployEvents <- ct.DeploymentEvent{
+		ReleaseID: d.NewReleaseID,
+		Step:      ct.DeploymentStepCanaryPromoted,
+	}
+	return d.scaleAllAtOnce(log)
+}
+
+// observeCanary waits for the given duration, returning an error if any job
+// of the new release fails in the meantime
+func (d *DeployJob) observeCanary(events chan *ct.Job, window time.Duration) error {
+	timeout := time.After(window)
+	for {
+		select {
+		case job, ok := <-events:
+			if !ok {
+				return fmt.Errorf("job event stream closed unexpectedly")
+			}
+			if job.ReleaseID != d.NewReleaseID {
+				continue
+			}
+			d.logJobEvent(job)
+			// canary jobs are not stopped until the window ends, so
+			// any which go down have failed
+			if job.State == ct.JobStateDown {
+				msg := "job went down"
+				if job.HostError != nil {
+					msg = *job.HostError
+				}
+				return fmt.Errorf("canary %s job failed: %s", job.Type, msg)
+			}
+		case <-d.stop:
+			return worker.ErrStopped
+		case <-timeout:
+			return nil
+		}
+	}
+}
+
+// canaryRoutes returns the app's HTTP routes
+func (d *DeployJob) canaryRoutes() ([]*router.Route, error) {
+	routes, err := d.client.AppRouteList(d.AppID)
+	if err != nil {
+		return nil, err
+	}
+	httpRoutes := make([]*router.Route, 0, len(routes))
+	for _, route := range routes {
+		if route.Type == "http" {
+			httpRoutes = append(httpRoutes, route)
+		}
+	}
+	return httpRoutes, nil
+}
+
+// setCanaryRoutes updates the given routes to send the given percentage of
+// requests to jobs of the given release
+func (d *DeployJob) setCanaryRoutes(routes []*router.Route, releaseID string, weight int) error {
+	for _, route := range routes {
+		route.CanaryRelease = releaseID
+		route.CanaryWeight = weight
+		if err := d.client.UpdateRoute(d.AppID, route.FormattedID(), route); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -45,12 +45,16 @@ func (c *context) HandleDeployment(job *que.Job) (e error) {
 		"app_id", deployment.AppID,
 		"strategy", deployment.Strategy,
 	)
-	// for recovery purposes, fetch old formation
-	log.Info("getting old formation")
-	f, err := c.client.GetFormation(deployment.AppID, deployment.OldReleaseID)
-	if err != nil {
-		log.Error("error getting old formation", "release_id", deployment.OldReleaseID, "err", err)
-		return err
+	// for recovery purposes, fetch old formation (which is empty if the
+	// app has no release, e.g. for a first deploy with a release phase)
+	f := &ct.Formation{AppID: deployment.AppID}
+	if deployment.OldReleaseID != "" {
+		log.Info("getting old formation")
+		f, err = c.client.GetFormation(deployment.AppID, deployment.OldReleaseID)
+		if err != nil {
+			log.Error("error getting old formation", "release_id", deployment.OldReleaseID, "err", err)
+			return err
+		}
 	}
 
 	events := make(chan ct.DeploymentEvent)
@@ -91,6 +95,11 @@ func (c *context) HandleDeployment(job *que.Job) (e error) {
 				// to nil to avoid retrying the deploy
 				e = nil
 			} else {
+				if r, ok := e.(ErrRollback); ok {
+					if err := c.setRollbackReason(deployment.ID, r.Reason); err != nil {
+						log.Error("error recording the rollback reason", "err", err)
+					}
+				}
 				log.Warn("rolling back deployment due to error", "err", e)
 				e = c.rollback(log, deployment, f, job.Stop)
 			}
@@ -102,18 +111,43 @@ func (c *context) HandleDeployment(job *que.Job) (e error) {
 		}
 	}()
 
+	ctl, err := c.watchControl(deployment.ID, job.Stop)
+	if err != nil {
+		log.Error("error getting deployment control state", "err", err)
+		return err
+	}
+	defer ctl.Close()
+
+	if err := c.waitForApproval(log, ctl, deployment, events); err != nil {
+		return err
+	}
+
 	j := &DeployJob{
 		Deployment:   deployment,
 		client:       c.client,
 		deployEvents: events,
 		logger:       c.logger,
-		stop:         job.Stop,
+		stop:         ctl.stop,
 	}
 
-	log.Info("performing deployment")
-	if err := j.Perform(); err != nil {
-		log.Error("error performing deployment", "err", err)
-		return err
+	for {
+		if err := c.waitForResume(log, ctl, deployment, events); err != nil {
+			return err
+		}
+		j.pause = ctl.Paused()
+
+		log.Info("performing deployment")
+		err := j.Perform()
+		if err == ErrPaused {
+			continue
+		} else if err == worker.ErrStopped && ctl.IsAborted() {
+			err = ErrAborted
+		}
+		if err != nil {
+			log.Error("error performing deployment", "err", err)
+			return err
+		}
+		break
 	}
 	log.Info("setting the app release")
 	if err := c.client.SetAppRelease(deployment.AppID, deployment.NewReleaseID); err != nil {
@@ -131,27 +165,62 @@ func (c *context) HandleDeployment(job *que.Job) (e error) {
 	return nil
 }
 
+// waitForResume waits for the deployment to be resumed if it is paused,
+// returning ErrAborted if it is aborted in the meantime
+func (c *context) waitForResume(l log15.Logger, ctl *control, deployment *ct.Deployment, events chan<- ct.DeploymentEvent) error {
+	if ctl.IsAborted() {
+		return ErrAborted
+	}
+	if !ctl.IsPaused() {
+		return nil
+	}
+	log := l.New("fn", "waitForResume")
+	log.Info("deployment paused, waiting for it to be resumed")
+	events <- ct.DeploymentEvent{
+		ReleaseID: deployment.NewReleaseID,
+		Status:    "paused",
+	}
+	select {
+	case <-ctl.Resumed():
+		log.Info("deployment resumed")
+		events <- ct.DeploymentEvent{
+			ReleaseID: deployment.NewReleaseID,
+			Status:    "running",
+		}
+		return nil
+	case <-ctl.stop:
+		if ctl.IsAborted() {
+			return ErrAborted
+		}
+		return worker.ErrStopped
+	}
+}
+
 func (c *context) rollback(l log15.Logger, deployment *ct.Deployment, original *ct.Formation, stop chan struct{}) error {
 	log := l.New("fn", "rollback")
 
-	log.Info("restoring the original formation", "release.id", original.ReleaseID)
-	timeout := 10 * time.Second
-	opts := ct.ScaleOptions{
-		Processes: original.Processes,
-		Timeout:   &timeout,
-		Stop:      stop,
-		JobEventCallback: func(job *ct.Job) error {
-			log.Info("got job event", "job.id", job.ID, "job.type", job.Type, "job.state", job.State)
-			return nil
-		},
-	}
-	if err := c.client.ScaleAppRelease(original.AppID, original.ReleaseID, opts); err != nil {
-		log.Error("error restoring the original formation", "err", err)
-		return err
+	if original.ReleaseID != "" {
+		log.Info("restoring the original formation", "release.id", original.ReleaseID)
+		timeout := 10 * time.Second
+		opts := ct.ScaleOptions{
+			Processes: original.Processes,
+			Timeout:   &timeout,
+			Stop:      stop,
+			JobEventCallback: func(job *ct.Job) error {
+				log.Info("got job event", "job.id", job.ID, "job.type", job.Type, "job.state", job.State)
+				return nil
+			},
+		}
+		if err := c.client.ScaleAppRelease(original.AppID, original.ReleaseID, opts); err != nil {
+			log.Error("error restoring the original formation", "err", err)
+			return err
+		}
 	}
 
 	log.Info("deleting the new formation")
-	if err := c.client.DeleteFormation(deployment.AppID, deployment.NewReleaseID); err != nil {
+	// the new formation does not exist if the deployment failed before
+	// scaling up the new release (e.g. in the release phase)
+	if err := c.client.DeleteFormation(deployment.AppID, deployment.NewReleaseID); err != nil && err != controller.ErrNotFound {
 		log.Error("error deleting the new formation:", "err", err)
 		return err
 	}
@@ -164,6 +233,10 @@ func (c *context) setDeploymentDone(id string) error {
 	return c.execWithRetries("deployment_update_finished_at_now", id)
 }
 
+func (c *context) setRollbackReason(id, reason string) error {
+	return c.execWithRetries("deployment_update_rollback_reason", id, reason)
+}
+
 func (c *context) createDeploymentEvent(e ct.DeploymentEvent) error {
 	if e.Status == "" {
 		e.Status = "running"
@@ -0,0 +1,156 @@
+package deployment
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/flynn/flynn/pkg/postgres"
+	"github.com/inconshreveable/log15"
+)
+
+// ErrPaused is returned by deployment strategies which stopped between steps
+// because the deployment was paused
+var ErrPaused = errors.New("deployment paused")
+
+// ErrAborted is returned when a deployment is aborted, which rolls it back
+var ErrAborted = errors.New("deployment aborted")
+
+// controlPollInterval is how often the control state of a running deployment
+// is read from the database
+const controlPollInterval = time.Second
+
+// control tracks the paused and aborted state of a deployment, which is set
+// using the controller API and persisted in the deployments table so that a
+// deployment which is picked up by another worker (e.g. after a restart)
+// remains paused
+type control struct {
+	db  *postgres.DB
+	id  string
+	log log15.Logger
+
+	mtx     sync.Mutex
+	paused  bool
+	aborted bool
+
+	// pause is closed when the deployment is paused and resume is closed
+	// when it is resumed, with each being replaced once the state changes
+	// back
+	pause  chan struct{}
+	resume chan struct{}
+
+	// stop is closed when the deployment is aborted or the worker stops,
+	// and is used as the stop channel of the DeployJob
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	done chan struct{}
+}
+
+// watchControl reads the current control state of the given deployment and
+// then polls for changes until Close is called or workerStop is closed
+func (c *context) watchControl(id string, workerStop chan struct{}) (*control, error) {
+	ctl := &control{
+		db:     c.db,
+		id:     id,
+		log:    c.logger.New("fn", "watchControl", "deployment_id", id),
+		pause:  make(chan struct{}),
+		resume: make(chan struct{}),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if err := ctl.update(); err != nil {
+		return nil, err
+	}
+	go func() {
+		ticker := time.NewTicker(controlPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := ctl.update(); err != nil {
+					ctl.log.Error("error getting deployment control state", "err", err)
+				}
+			case <-workerStop:
+				ctl.closeStop()
+				return
+			case <-ctl.done:
+				return
+			}
+		}
+	}()
+	return ctl, nil
+}
+
+func (c *control) update() error {
+	var paused, aborted bool
+	if err := c.db.QueryRow("deployment_select_control", c.id).Scan(&paused, &aborted); err != nil {
+		return err
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if paused != c.paused {
+		c.log.Info("deployment control state changed", "paused", paused)
+		if paused {
+			close(c.pause)
+			c.resume = make(chan struct{})
+		} else {
+			close(c.resume)
+			c.pause = make(chan struct{})
+		}
+		c.paused = paused
+	}
+	if aborted && !c.aborted {
+		c.log.Info("deployment aborted")
+		c.aborted = true
+		c.closeStop()
+	}
+	return nil
+}
+
+func (c *control) closeStop() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+// Paused returns a channel which is closed when the deployment is paused
+func (c *control) Paused() <-chan struct{} {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.pause
+}
+
+// Resumed returns a channel which is closed when the deployment is resumed
+func (c *control) Resumed() <-chan struct{} {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.resume
+}
+
+func (c *control) IsPaused() bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.paused
+}
+
+func (c *control) IsAborted() bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.aborted
+}
+
+func (c *control) Close() {
+	close(c.done)
+}
+
+// checkPause returns ErrPaused if the deployment has been paused, and is
+// called by strategies between steps so that pausing a deployment lets the
+// current step (e.g. a batch of jobs) finish first
+func (d *DeployJob) checkPause() error {
+	select {
+	case <-d.pause:
+		return ErrPaused
+	default:
+		return nil
+	}
+}
@@ -8,13 +8,6 @@ func (d *DeployJob) deployDiscoverdMeta() (err error) {
 	log := d.logger.New("fn", "deployDiscoverdMeta")
 	log.Info("starting discoverd-meta deployment")
 
-	defer func() {
-		if err != nil {
-			// TODO: support rolling back
-			err = ErrSkipRollback{err.Error()}
-		}
-	}()
-
 	for typ, count := range d.Processes {
 		proc := d.newRelease.Processes[typ]
 
@@ -35,6 +28,9 @@ func (d *DeployJob) deployDiscoverdMeta() (err error) {
 		defer discDeploy.Close()
 
 		for i := 0; i < count; i++ {
+			if err := d.checkPause(); err != nil {
+				return err
+			}
 			if err := d.scaleNewFormationUpByOne(typ, log); err != nil {
 				return err
 			}
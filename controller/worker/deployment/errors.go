@@ -15,6 +15,16 @@ func IsSkipRollback(err error) bool {
 	return ok
 }
 
+// ErrRollback indicates the new release exceeded one of the deployment's
+// rollback thresholds during the bake period
+type ErrRollback struct {
+	Reason string
+}
+
+func (e ErrRollback) Error() string {
+	return e.Reason
+}
+
 type UnknownStrategyError struct {
 	Strategy string
 }
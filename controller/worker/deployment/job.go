@@ -21,6 +21,11 @@ type DeployJob struct {
 	newFormation *ct.Formation
 	timeout      time.Duration
 	stop         chan struct{}
+	pause        <-chan struct{}
+
+	// releasePhaseDone is set once the release phase of the new release
+	// has run so that it is not run again if the deployment is resumed
+	releasePhaseDone bool
 }
 
 func (d *DeployJob) Perform() error {
@@ -37,6 +42,10 @@ func (d *DeployJob) Perform() error {
 		deployFunc = d.deployInBatches
 	case "all-at-once":
 		deployFunc = d.deployAllAtOnce
+	case "canary":
+		deployFunc = d.deployCanary
+	case "blue-green":
+		deployFunc = d.deployBlueGreen
 	case "sirenia":
 		deployFunc = d.deploySirenia
 	case "discoverd-meta":
@@ -47,17 +56,23 @@ func (d *DeployJob) Perform() error {
 		return err
 	}
 
-	log.Info("getting old release", "release.id", d.OldReleaseID)
 	var err error
-	d.oldRelease, err = d.client.GetRelease(d.OldReleaseID)
-	if err != nil {
-		log.Error("error getting old release", "release.id", d.OldReleaseID, "err", err)
-		return err
-	}
-	d.oldFormation, err = d.client.GetFormation(d.AppID, d.OldReleaseID)
-	if err != nil {
-		log.Error("error getting old formation", "release.id", d.OldReleaseID, "err", err)
-		return err
+	if d.OldReleaseID == "" {
+		// the app has no release yet, so there is nothing to scale down
+		d.oldRelease = &ct.Release{}
+		d.oldFormation = &ct.Formation{AppID: d.AppID, Processes: make(map[string]int)}
+	} else {
+		log.Info("getting old release", "release.id", d.OldReleaseID)
+		d.oldRelease, err = d.client.GetRelease(d.OldReleaseID)
+		if err != nil {
+			log.Error("error getting old release", "release.id", d.OldReleaseID, "err", err)
+			return err
+		}
+		d.oldFormation, err = d.client.GetFormation(d.AppID, d.OldReleaseID)
+		if err != nil {
+			log.Error("error getting old formation", "release.id", d.OldReleaseID, "err", err)
+			return err
+		}
 	}
 
 	log.Info("getting new release", "release.id", d.NewReleaseID)
@@ -79,21 +94,53 @@ func (d *DeployJob) Perform() error {
 	if d.newFormation.Processes == nil {
 		d.newFormation.Processes = make(map[string]int)
 	}
+	if d.Strategy != "blue-green" {
+		// run new jobs under the same services as the old jobs, which
+		// the app's routes point at (the services have a suffix if the
+		// app was previously deployed using the blue-green strategy)
+		d.newFormation.ServiceSuffix = d.oldFormation.ServiceSuffix
+	}
 
-	if processesEqual(d.newFormation.Processes, d.Processes) {
+	d.timeout = time.Duration(d.DeployTimeout) * time.Second
+
+	// deployments with no processes to scale (e.g. an app's first
+	// deploy) are only created to run the new release's release phase
+	procCount := 0
+	for _, count := range d.Processes {
+		procCount += count
+	}
+	if procCount == 0 {
+		log.Info("no processes to scale, only running the release phase")
+		return d.runReleasePhase()
+	}
+
+	// blue-green deployments have more to do once the new formation is
+	// scaled up (i.e. switching routes and scaling the old formation
+	// down), so are always performed
+	if processesEqual(d.newFormation.Processes, d.Processes) && d.Strategy != "blue-green" {
 		log.Info("deployment already completed, nothing to do")
 		return nil
 	}
 
-	d.timeout = time.Duration(d.DeployTimeout) * time.Second
-
 	log.Info(
 		"determined deployment state",
 		"original", d.Processes,
 		"old_release", d.oldFormation.Processes,
 		"new_release", d.newFormation.Processes,
 	)
-	return deployFunc()
+	if err := d.runReleasePhase(); err != nil {
+		return err
+	}
+	if err := deployFunc(); err != nil {
+		return err
+	}
+
+	// blue-green deployments monitor the new release while the old one is
+	// kept warm, which is when rolling back is cheapest
+	if d.DeployRollback != nil && d.Strategy != "blue-green" {
+		return d.bake(time.Duration(d.DeployRollback.BakePeriod) * time.Second)
+	}
+	return nil
 }
 
 func (d *DeployJob) scaleOldRelease(wait bool) error {
@@ -118,10 +165,11 @@ const newJobFailureThreshold = 5
 func (d *DeployJob) scaleNewRelease() error {
 	failures := 0
 	opts := ct.ScaleOptions{
-		Processes: d.newFormation.Processes,
-		Tags:      d.newFormation.Tags,
-		Timeout:   &d.timeout,
-		Stop:      d.stop,
+		Processes:     d.newFormation.Processes,
+		Tags:          d.newFormation.Tags,
+		ServiceSuffix: &d.newFormation.ServiceSuffix,
+		Timeout:       &d.timeout,
+		Stop:          d.stop,
 		JobEventCallback: func(job *ct.Job) error {
 			d.logJobEvent(job)
 			// return an error if we get more than newJobFailureThreshold
@@ -165,6 +213,9 @@ func (d *DeployJob) scaleOneByOne(typ string, log log15.Logger) error {
 
 func (d *DeployJob) scaleUpDownInBatches(typ string, batchCount int, log log15.Logger) error {
 	for i := 0; i < d.Processes[typ]; i += batchCount {
+		if err := d.checkPause(); err != nil {
+			return err
+		}
 		if err := d.scaleNewFormationUp(typ, batchCount, log); err != nil {
 			return err
 		}
@@ -178,6 +229,21 @@ func (d *DeployJob) scaleUpDownInBatches(typ string, batchCount int, log log15.L
 
 func (d *DeployJob) scaleOneDownOneUp(typ string, log log15.Logger) error {
 	for i := 0; i < d.Processes[typ]; i++ {
+		if err := d.checkPause(); err != nil {
+			return err
+		}
+		// start the new job first if stopping an old job first would
+		// violate the process type's disruption budget
+		if !d.canDisrupt(typ) {
+			log.Info("scaling new formation up first to honour disruption budget", "job.type", typ)
+			if err := d.scaleNewFormationUpByOne(typ, log); err != nil {
+				return err
+			}
+			if err := d.scaleOldFormationDownByOne(typ, log); err != nil {
+				return err
+			}
+			continue
+		}
 		if err := d.scaleOldFormationDownByOne(typ, log); err != nil {
 			return err
 		}
@@ -188,6 +254,22 @@ func (d *DeployJob) scaleOneDownOneUp(typ string, log log15.Logger) error {
 	return nil
 }
 
+// canDisrupt returns whether a job of the given type can be stopped before its
+// replacement has started without violating the process type's disruption
+// budget (the scheduler also delays stopping old jobs until the budget
+// allows, which paces the other strategies)
+func (d *DeployJob) canDisrupt(typ string) bool {
+	budget := d.newRelease.Processes[typ].DisruptionBudget
+	if budget == nil {
+		budget = d.oldRelease.Processes[typ].DisruptionBudget
+	}
+	if budget == nil {
+		return true
+	}
+	running := d.oldFormation.Processes[typ] + d.newFormation.Processes[typ]
+	return budget.AllowedDisruptions(d.Processes[typ], running, 0) > 0
+}
+
 func (d *DeployJob) scaleNewFormationUpByOne(typ string, log log15.Logger) error {
 	return d.scaleNewFormationUp(typ, 1, log)
 }
@@ -0,0 +1,141 @@
+package deployment
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	ct "github.com/flynn/flynn/controller/types"
+	worker "github.com/flynn/flynn/controller/worker/types"
+	"github.com/flynn/flynn/pkg/cluster"
+)
+
+// ReleasePhaseError is returned when the release phase job of the new release
+// exits unsuccessfully
+type ReleasePhaseError struct {
+	ExitStatus int
+}
+
+func (e ReleasePhaseError) Error() string {
+	return fmt.Sprintf("release phase exited with status %d", e.ExitStatus)
+}
+
+// runReleasePhase runs the release process type of the new release (if it has
+// one) as a one-off job before any of the new release's jobs are started,
+// streaming the job's output into deployment events and failing the
+// deployment if it exits unsuccessfully
+func (d *DeployJob) runReleasePhase() error {
+	proc, ok := d.newRelease.Processes[ct.ReleaseProcessType]
+	if !ok || d.releasePhaseDone {
+		return nil
+	}
+	// a deployment picked up by another worker after the release phase
+	// has run will have started new jobs, so don't run it again
+	for _, count := range d.newFormation.Processes {
+		if count > 0 {
+			d.releasePhaseDone = true
+			return nil
+		}
+	}
+
+	log := d.logger.New("fn", "runReleasePhase", "release.id", d.NewReleaseID)
+	log.Info("running release phase")
+
+	req := &ct.NewJob{
+		ReleaseID:  d.NewReleaseID,
+		ReleaseEnv: true,
+		Args:       proc.Args,
+		Env:        proc.Env,
+		Resources:  proc.Resources,
+		Profiles:   proc.Profiles,
+		MountsFrom: ct.ReleaseProcessType,
+	}
+	rwc, err := d.client.RunJobAttached(d.AppID, req)
+	if err != nil {
+		log.Error("error starting release phase job", "err", err)
+		return err
+	}
+	defer rwc.Close()
+	attachClient := cluster.NewAttachClient(rwc)
+	attachClient.CloseWrite()
+
+	d.deployEvents <- ct.DeploymentEvent{
+		ReleaseID: d.NewReleaseID,
+		JobType:   ct.ReleaseProcessType,
+		Step:      ct.DeploymentStepReleasePhaseStarted,
+	}
+
+	// stop waiting for the job if the deployment is stopped
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-d.stop:
+			rwc.Close()
+		case <-done:
+		}
+	}()
+
+	var wg sync.WaitGroup
+	stdout := d.releasePhaseOutput(&wg)
+	stderr := d.releasePhaseOutput(&wg)
+	exitStatus, err := attachClient.Receive(stdout, stderr)
+	stdout.Close()
+	stderr.Close()
+	wg.Wait()
+	select {
+	case <-d.stop:
+		return worker.ErrStopped
+	default:
+	}
+	if err != nil {
+		log.Error("error waiting for release phase job", "err", err)
+		return err
+	}
+
+	if exitStatus != 0 {
+		err := ReleasePhaseError{exitStatus}
+		log.Error("release phase failed", "err", err)
+		d.deployEvents <- ct.DeploymentEvent{
+			ReleaseID: d.NewReleaseID,
+			JobType:   ct.ReleaseProcessType,
+			Step:      ct.DeploymentStepReleasePhaseFailed,
+			Error:     err.Error(),
+		}
+		return err
+	}
+
+	log.Info("release phase complete")
+	d.releasePhaseDone = true
+	d.deployEvents <- ct.DeploymentEvent{
+		ReleaseID: d.NewReleaseID,
+		JobType:   ct.ReleaseProcessType,
+		Step:      ct.DeploymentStepReleasePhaseComplete,
+	}
+	return nil
+}
+
+// releasePhaseOutput returns a writer which sends each line written to it as
+// a deployment event
+func (d *DeployJob) releasePhaseOutput(wg *sync.WaitGroup) io.WriteCloser {
+	r, w := io.Pipe()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s := bufio.NewScanner(r)
+		for s.Scan() {
+			d.deployEvents <- ct.DeploymentEvent{
+				ReleaseID: d.NewReleaseID,
+				JobType:   ct.ReleaseProcessType,
+				Step:      ct.DeploymentStepReleasePhaseOutput,
+				Output:    s.Text(),
+			}
+		}
+		// drain the pipe if the line was too long to scan so that
+		// the writer does not block
+		io.Copy(ioutil.Discard, r)
+	}()
+	return w
+}
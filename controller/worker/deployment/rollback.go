@@ -0,0 +1,191 @@
+package deployment
+
+import (
+	"fmt"
+	"time"
+
+	ct "github.com/flynn/flynn/controller/types"
+	worker "github.com/flynn/flynn/controller/worker/types"
+	discoverd "github.com/flynn/flynn/discoverd/client"
+	routerc "github.com/flynn/flynn/router/client"
+)
+
+// bakeSampleInterval is how often router statistics are sampled when
+// monitoring a new release for automatic rollback
+const bakeSampleInterval = 10 * time.Second
+
+// bakeMinRequests is the minimum number of requests to the new release which
+// must have been proxied before the error rate is checked, so that a handful
+// of failing requests does not cause a rollback
+const bakeMinRequests = 20
+
+// bake monitors the jobs of the new release for the given duration, returning
+// an ErrRollback if any of the deployment's rollback thresholds are exceeded
+func (d *DeployJob) bake(duration time.Duration) error {
+	log := d.logger.New("fn", "bake", "release.id", d.NewReleaseID)
+	config := d.DeployRollback
+	log.Info("monitoring new release for rollback", "duration", duration, "max_error_rate", config.MaxErrorRate, "max_crashes", config.MaxCrashes, "max_health_failures", config.MaxHealthFailures)
+
+	jobEvents := make(chan *ct.Job)
+	jobStream, err := d.client.StreamJobEvents(d.AppID, jobEvents)
+	if err != nil {
+		log.Error("error streaming job events", "err", err)
+		return err
+	}
+	defer jobStream.Close()
+
+	// watch the services of the new release, merging the events into a
+	// single channel
+	serviceEvents := make(chan *discoverd.Event)
+	done := make(chan struct{})
+	defer close(done)
+	for _, proc := range d.newRelease.Processes {
+		if proc.Service == "" {
+			continue
+		}
+		events := make(chan *discoverd.Event)
+		stream, err := discoverd.NewService(d.newFormation.ServiceName(proc.Service)).Watch(events)
+		if err != nil {
+			log.Error("error watching service", "service", proc.Service, "err", err)
+			return err
+		}
+		defer stream.Close()
+		go func() {
+			for e := range events {
+				select {
+				case serviceEvents <- e:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	d.deployEvents <- ct.DeploymentEvent{
+		ReleaseID: d.NewReleaseID,
+		Step:      ct.DeploymentStepBakeStarted,
+	}
+	rollback := func(format string, v ...interface{}) error {
+		err := ErrRollback{fmt.Sprintf(format, v...)}
+		log.Warn("new release exceeded rollback threshold", "reason", err.Reason)
+		d.deployEvents <- ct.DeploymentEvent{
+			ReleaseID: d.NewReleaseID,
+			Step:      ct.DeploymentStepBakeFailed,
+			Error:     err.Reason,
+		}
+		return err
+	}
+
+	baseline := d.routerStats()
+	checkErrorRate := func() error {
+		requests, errors := d.routerStats().since(baseline)
+		if requests < bakeMinRequests {
+			return nil
+		}
+		if rate := float64(errors) / float64(requests) * 100; rate > config.MaxErrorRate {
+			return rollback("%.1f%% of requests to the new release got a 5xx response (%d of %d), exceeding the maximum of %g%%", rate, errors, requests, config.MaxErrorRate)
+		}
+		return nil
+	}
+
+	var crashes, healthFailures int
+	ticker := time.NewTicker(bakeSampleInterval)
+	defer ticker.Stop()
+	timeout := time.After(duration)
+	for {
+		select {
+		case job, ok := <-jobEvents:
+			if !ok {
+				return fmt.Errorf("job event stream closed unexpectedly: %s", jobStream.Err())
+			}
+			if job.ReleaseID != d.NewReleaseID || !jobCrashed(job) {
+				continue
+			}
+			crashes++
+			log.Warn("new release job crashed", "job.id", job.ID, "job.type", job.Type, "crashes", crashes)
+			if crashes > config.MaxCrashes {
+				return rollback("%d jobs of the new release crashed, exceeding the maximum of %d", crashes, config.MaxCrashes)
+			}
+		case e := <-serviceEvents:
+			if e.Kind != discoverd.EventKindDown || e.Instance == nil || e.Instance.Meta["FLYNN_RELEASE_ID"] != d.NewReleaseID {
+				continue
+			}
+			healthFailures++
+			log.Warn("new release job went down in service discovery", "service", e.Service, "job.id", e.Instance.Meta["FLYNN_JOB_ID"], "failures", healthFailures)
+			if healthFailures > config.MaxHealthFailures {
+				return rollback("jobs of the new release went down in service discovery %d times, exceeding the maximum of %d", healthFailures, config.MaxHealthFailures)
+			}
+		case <-ticker.C:
+			if err := checkErrorRate(); err != nil {
+				return err
+			}
+		case <-d.stop:
+			return worker.ErrStopped
+		case <-timeout:
+			if err := checkErrorRate(); err != nil {
+				return err
+			}
+			log.Info("new release is healthy")
+			return nil
+		}
+	}
+}
+
+// jobCrashed returns whether the job event is for a job which exited
+// unsuccessfully
+func jobCrashed(job *ct.Job) bool {
+	if job.State != ct.JobStateDown {
+		return false
+	}
+	return job.HostError != nil || job.ExitStatus != nil && *job.ExitStatus != 0
+}
+
+// backendRequests is the number of requests and 5xx responses proxied to a
+// backend by a router
+type backendRequests struct {
+	requests uint64
+	errors   uint64
+}
+
+// releaseStats maps router and backend addresses to request counts
+type releaseStats map[string]backendRequests
+
+// since returns the total number of requests and errors since the given
+// stats were sampled
+func (s releaseStats) since(baseline releaseStats) (requests, errors uint64) {
+	for key, r := range s {
+		b := baseline[key]
+		// the router may have restarted and reset its counters
+		if r.requests < b.requests || r.errors < b.errors {
+			b = backendRequests{}
+		}
+		requests += r.requests - b.requests
+		errors += r.errors - b.errors
+	}
+	return
+}
+
+// routerStats returns request counts for the new release's backends from all
+// router instances, ignoring routers which cannot be reached
+func (d *DeployJob) routerStats() releaseStats {
+	stats := make(releaseStats)
+	addrs, err := discoverd.NewService("router-api").Addrs()
+	if err != nil {
+		d.logger.Error("error getting router addresses", "err", err)
+		return stats
+	}
+	for _, addr := range addrs {
+		backends, err := routerc.NewWithAddr(addr).BackendStats()
+		if err != nil {
+			d.logger.Error("error getting router backend stats", "router.addr", addr, "err", err)
+			continue
+		}
+		for _, b := range backends {
+			if b.ReleaseID != d.NewReleaseID {
+				continue
+			}
+			stats[addr+":"+b.Service+":"+b.Addr] = backendRequests{b.Requests, b.Errors}
+		}
+	}
+	return stats
+}
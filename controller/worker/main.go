@@ -7,12 +7,18 @@ import (
 
 	"github.com/flynn/flynn/controller/client"
 	"github.com/flynn/flynn/controller/data"
+	"github.com/flynn/flynn/controller/secrets"
 	"github.com/flynn/flynn/controller/worker/app_deletion"
 	"github.com/flynn/flynn/controller/worker/app_garbage_collection"
+	"github.com/flynn/flynn/controller/worker/autoscale"
 	"github.com/flynn/flynn/controller/worker/deployment"
 	"github.com/flynn/flynn/controller/worker/domain_migration"
 	"github.com/flynn/flynn/controller/worker/release_cleanup"
+	"github.com/flynn/flynn/controller/worker/schedule"
+	"github.com/flynn/flynn/controller/worker/secret_rotation"
+	"github.com/flynn/flynn/controller/worker/webhook_delivery"
 	"github.com/flynn/flynn/discoverd/client"
+	"github.com/flynn/flynn/pkg/cluster"
 	"github.com/flynn/flynn/pkg/postgres"
 	"github.com/flynn/flynn/pkg/shutdown"
 	"github.com/flynn/flynn/pkg/status"
@@ -22,6 +28,13 @@ import (
 
 const workerCount = 10
 
+// autoscaleInterval is how often each autoscale policy is evaluated
+const autoscaleInterval = 30 * time.Second
+
+// secretRotationInterval is how often secrets are checked for data keys
+// which need to be rewrapped with the active cluster key
+const secretRotationInterval = 10 * time.Minute
+
 var logger = log15.New("app", "worker")
 
 func main() {
@@ -64,6 +77,7 @@ func main() {
 			"domain_migration":       domain_migration.JobHandler(db, client, logger),
 			"release_cleanup":        release_cleanup.JobHandler(db, client, logger),
 			"app_garbage_collection": app_garbage_collection.JobHandler(db, client, logger),
+			"webhook_delivery":       webhook_delivery.JobHandler(db, client, logger),
 		},
 		workerCount,
 	)
@@ -73,5 +87,27 @@ func main() {
 	workers.Start()
 	shutdown.BeforeExit(func() { workers.Shutdown() })
 
+	launcher := schedule.NewLauncher(db, client, logger, workers.Interval)
+	log.Info("starting schedule launcher", "interval", workers.Interval)
+	launcher.Start()
+	shutdown.BeforeExit(func() { launcher.Stop() })
+
+	autoscaler := autoscale.NewAutoscaler(db, client, cluster.NewClient(), discoverd.NewService("router-api").Addrs, logger, autoscaleInterval)
+	log.Info("starting autoscaler", "interval", autoscaleInterval)
+	autoscaler.Start()
+	shutdown.BeforeExit(func() { autoscaler.Stop() })
+
+	keyring, err := secrets.ParseKeyring(os.Getenv("SECRETS_KEYS"))
+	if err != nil {
+		log.Error("error parsing secrets keys", "err", err)
+		shutdown.Fatal(err)
+	}
+	if keyring != nil {
+		rotator := secret_rotation.NewRotator(db, keyring, logger, secretRotationInterval)
+		log.Info("starting secret rotator", "interval", secretRotationInterval, "key_id", keyring.ActiveKeyID())
+		rotator.Start()
+		shutdown.BeforeExit(func() { rotator.Stop() })
+	}
+
 	select {} // block and keep running
 }
@@ -0,0 +1,126 @@
+package schedule
+
+import (
+	"time"
+
+	"github.com/flynn/flynn/controller/client"
+	"github.com/flynn/flynn/controller/data"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/pkg/postgres"
+	"github.com/inconshreveable/log15"
+)
+
+// Launcher periodically launches the jobs of schedules which are due to run
+type Launcher struct {
+	repo     *data.ScheduleRepo
+	client   controller.Client
+	logger   log15.Logger
+	interval time.Duration
+	stop     chan struct{}
+}
+
+func NewLauncher(db *postgres.DB, client controller.Client, logger log15.Logger, interval time.Duration) *Launcher {
+	return &Launcher{
+		repo:     data.NewScheduleRepo(db),
+		client:   client,
+		logger:   logger.New("component", "schedule_launcher"),
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+func (l *Launcher) Start() {
+	go l.run()
+}
+
+func (l *Launcher) Stop() {
+	close(l.stop)
+}
+
+func (l *Launcher) run() {
+	log := l.logger.New("fn", "run")
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+	for {
+		// launch all due schedules before waiting for the next tick
+		for {
+			run, err := l.repo.LaunchNextDue(l.launch)
+			if err == data.ErrNotFound {
+				break
+			} else if err != nil {
+				log.Error("error launching schedule", "err", err)
+				break
+			}
+			log.Info("launched schedule", "app.id", run.AppID, "schedule.id", run.ScheduleID, "run.id", run.ID, "run.state", run.State, "job.id", run.JobID)
+		}
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (l *Launcher) launch(schedule *ct.Schedule, lastRun *ct.ScheduleRun) *ct.ScheduleRun {
+	log := l.logger.New("fn", "launch", "app.id", schedule.AppID, "schedule.id", schedule.ID)
+	run := &ct.ScheduleRun{}
+	failed := func(err error) *ct.ScheduleRun {
+		log.Error("error launching schedule", "err", err)
+		run.State = ct.ScheduleRunStateFailed
+		run.Error = err.Error()
+		return run
+	}
+
+	run.ReleaseID = schedule.ReleaseID
+	if run.ReleaseID == "" {
+		release, err := l.client.GetAppRelease(schedule.AppID)
+		if err != nil {
+			return failed(err)
+		}
+		run.ReleaseID = release.ID
+	}
+
+	if lastRun != nil && lastRun.JobID != "" && schedule.Concurrency != ct.ScheduleConcurrencyAllow {
+		job, err := l.client.GetJob(schedule.AppID, lastRun.JobID)
+		if err != nil && err != controller.ErrNotFound {
+			return failed(err)
+		}
+		if job != nil && jobActive(job) {
+			switch schedule.Concurrency {
+			case ct.ScheduleConcurrencyForbid:
+				log.Info("skipping run as previous job is still active", "job.id", job.ID)
+				run.State = ct.ScheduleRunStateSkipped
+				return run
+			case ct.ScheduleConcurrencyReplace:
+				log.Info("stopping previous job which is still active", "job.id", job.ID)
+				if err := l.client.DeleteJob(schedule.AppID, job.ID); err != nil {
+					return failed(err)
+				}
+			}
+		}
+	}
+
+	job, err := l.client.RunJobDetached(schedule.AppID, &ct.NewJob{
+		ReleaseID:  run.ReleaseID,
+		ReleaseEnv: true,
+		Args:       schedule.Args,
+		Env:        schedule.Env,
+		Resources:  schedule.Resources,
+		Meta:       map[string]string{"flynn-controller.schedule": schedule.ID},
+	})
+	if err != nil {
+		return failed(err)
+	}
+	run.JobID = job.UUID
+	run.State = ct.ScheduleRunStateStarted
+	return run
+}
+
+func jobActive(job *ct.Job) bool {
+	switch job.State {
+	case ct.JobStatePending, ct.JobStateBlocked, ct.JobStateStarting, ct.JobStateUp:
+		return true
+	default:
+		return false
+	}
+}
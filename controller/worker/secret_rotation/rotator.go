@@ -0,0 +1,69 @@
+package secret_rotation
+
+import (
+	"time"
+
+	"github.com/flynn/flynn/controller/data"
+	"github.com/flynn/flynn/controller/secrets"
+	"github.com/flynn/flynn/pkg/postgres"
+	"github.com/inconshreveable/log15"
+)
+
+// batchSize is the number of releases rewrapped in each batch
+const batchSize = 100
+
+// Rotator periodically re-encrypts the data keys of secret release env vars
+// which are not encrypted with the active cluster key, so that old cluster
+// keys can be removed once a key has been rotated
+type Rotator struct {
+	repo     *data.ReleaseRepo
+	logger   log15.Logger
+	interval time.Duration
+	stop     chan struct{}
+}
+
+func NewRotator(db *postgres.DB, keyring *secrets.Keyring, logger log15.Logger, interval time.Duration) *Rotator {
+	return &Rotator{
+		repo:     data.NewReleaseRepo(db, nil, nil, keyring),
+		logger:   logger.New("component", "secret_rotator"),
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+func (r *Rotator) Start() {
+	go r.run()
+}
+
+func (r *Rotator) Stop() {
+	close(r.stop)
+}
+
+func (r *Rotator) run() {
+	log := r.logger.New("fn", "run")
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		// rewrap all releases before waiting for the next tick
+		var total int
+		for {
+			n, err := r.repo.RewrapSecrets(batchSize)
+			if err != nil {
+				log.Error("error rewrapping secrets", "err", err)
+				break
+			}
+			total += n
+			if n < batchSize {
+				break
+			}
+		}
+		if total > 0 {
+			log.Info("rewrapped secrets with the active cluster key", "releases", total)
+		}
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package webhook_delivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/flynn/flynn/controller/client"
+	"github.com/flynn/flynn/controller/data"
+	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/pkg/postgres"
+	"github.com/flynn/que-go"
+	"github.com/inconshreveable/log15"
+)
+
+// deliveryTimeout is the timeout for sending an event to a webhook
+const deliveryTimeout = 10 * time.Second
+
+type context struct {
+	webhooks *data.WebhookRepo
+	events   *data.EventRepo
+	logger   log15.Logger
+}
+
+func JobHandler(db *postgres.DB, client controller.Client, logger log15.Logger) func(*que.Job) error {
+	return (&context{
+		// webhooks are not validated by the worker, so no app repo is
+		// needed to look up apps
+		webhooks: data.NewWebhookRepo(db, nil),
+		events:   data.NewEventRepo(db),
+		logger:   logger,
+	}).HandleWebhookDelivery
+}
+
+// HandleWebhookDelivery sends an event to a webhook, returning an error to
+// have que retry the delivery with backoff until it has been attempted
+// ct.WebhookMaxAttempts times
+func (c *context) HandleWebhookDelivery(job *que.Job) error {
+	log := c.logger.New("fn", "HandleWebhookDelivery")
+	log.Info("handling webhook delivery", "job_id", job.ID, "error_count", job.ErrorCount)
+
+	var args struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(job.Args, &args); err != nil {
+		log.Error("error unmarshaling job", "err", err)
+		return err
+	}
+	log = log.New("delivery_id", args.ID)
+
+	delivery, err := c.webhooks.GetDelivery(args.ID)
+	if err != nil {
+		log.Error("error getting webhook delivery", "err", err)
+		return err
+	}
+	log = log.New("webhook_id", delivery.WebhookID, "event_id", delivery.EventID)
+
+	webhook, err := c.webhooks.GetWithSecret(delivery.WebhookID)
+	if err == data.ErrNotFound {
+		log.Info("webhook has been removed, not sending event")
+		delivery.State = ct.WebhookDeliveryStateFailed
+		delivery.Error = "webhook removed"
+		return c.webhooks.UpdateDelivery(delivery)
+	} else if err != nil {
+		log.Error("error getting webhook", "err", err)
+		return err
+	}
+
+	event, err := c.events.GetEvent(delivery.EventID)
+	if err != nil {
+		log.Error("error getting event", "err", err)
+		return err
+	}
+
+	delivery.State = ct.WebhookDeliveryStateDelivered
+	delivery.ResponseStatus, err = send(webhook, delivery, event)
+	if err != nil {
+		delivery.Error = err.Error()
+		delivery.State = ct.WebhookDeliveryStatePending
+		if int(job.ErrorCount)+1 >= ct.WebhookMaxAttempts {
+			delivery.State = ct.WebhookDeliveryStateFailed
+		}
+	}
+	log.Info("sent event to webhook", "state", delivery.State, "status", delivery.ResponseStatus, "err", err)
+	if err := c.webhooks.UpdateDelivery(delivery); err != nil {
+		log.Error("error updating webhook delivery", "err", err)
+		return err
+	}
+	if delivery.State == ct.WebhookDeliveryStatePending {
+		return err
+	}
+	return nil
+}
+
+// send POSTs the event to the webhook, signing the payload with the webhook
+// secret, and returns the response status
+func send(webhook *ct.Webhook, delivery *ct.WebhookDelivery, event *ct.Event) (int, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Flynn-Event-Type", string(event.ObjectType))
+	req.Header.Set("Flynn-Delivery-ID", delivery.ID)
+	req.Header.Set(ct.WebhookSignatureHeader, ct.WebhookSignature(webhook.Secret, payload))
+	client := &http.Client{Timeout: deliveryTimeout}
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
@@ -49,6 +49,13 @@ func APIHandler(conf *Config) http.Handler {
 			panic(err)
 		}
 	}
+	if conf.OIDCIssuer != "" {
+		provider, err := newOIDCProvider(conf)
+		if err != nil {
+			panic(err)
+		}
+		api.oidc = provider
+	}
 
 	router := httprouter.New()
 	router2 := httprouter.New()
@@ -63,6 +70,10 @@ func APIHandler(conf *Config) http.Handler {
 
 	router.POST(prefixPath("/user/sessions"), api.WrapHandler(api.Login))
 	router.DELETE(prefixPath("/user/session"), api.WrapHandler(api.Logout))
+	if api.oidc != nil {
+		router.GET(prefixPath("/user/oidc/login"), api.WrapHandler(api.OIDCLogin))
+		router.GET(prefixPath("/user/oidc/callback"), api.WrapHandler(api.OIDCCallback))
+	}
 
 	router.GET(prefixPath("/config"), api.WrapHandler(api.GetConfig))
 
@@ -76,6 +87,7 @@ func APIHandler(conf *Config) http.Handler {
 
 type API struct {
 	conf               *Config
+	oidc               *oidcProvider
 	dashboardJS        bytes.Buffer
 	dashboardJSModTime time.Time
 }
@@ -102,7 +114,12 @@ func (api *API) SessionFromContext(ctx context.Context) *sessions.Session {
 }
 
 func (api *API) IsAuthenticated(ctx context.Context) bool {
-	return api.SessionFromContext(ctx).Values["auth"] == true
+	if api.SessionFromContext(ctx).Values["auth"] != true {
+		return false
+	}
+	// users logged in with OIDC are only authenticated until their ID
+	// token expires
+	return api.oidc == nil || api.oidcToken(ctx) != ""
 }
 
 func (api *API) SetAuthenticated(ctx context.Context, w http.ResponseWriter, req *http.Request) {
@@ -114,6 +131,8 @@ func (api *API) SetAuthenticated(ctx context.Context, w http.ResponseWriter, req
 func (api *API) UnsetAuthenticated(ctx context.Context, w http.ResponseWriter, req *http.Request) {
 	s := api.SessionFromContext(ctx)
 	delete(s.Values, "auth")
+	delete(s.Values, "oidc_token")
+	delete(s.Values, "oidc_expires")
 	s.Save(req, w)
 }
 
@@ -202,6 +221,13 @@ func (api *API) ServeAsset(ctx context.Context, w http.ResponseWriter, req *http
 }
 
 func (api *API) Login(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	if api.oidc != nil {
+		httphelper.Error(w, httphelper.JSONError{
+			Code:    httphelper.UnauthorizedErrorCode,
+			Message: "Login token is disabled, log in with OIDC",
+		})
+		return
+	}
 	var info LoginInfo
 	if strings.Contains(req.Header.Get("Content-Type"), "form-urlencoded") {
 		if err := req.ParseForm(); err != nil {
@@ -264,6 +290,13 @@ var baseConfig = UserConfig{
 
 func (api *API) GetConfig(ctx context.Context, w http.ResponseWriter, req *http.Request) {
 	config := baseConfig
+	config.Endpoints = make(map[string]string, len(baseConfig.Endpoints))
+	for k, v := range baseConfig.Endpoints {
+		config.Endpoints[k] = v
+	}
+	if api.oidc != nil {
+		config.Endpoints["oidc_login"] = path.Join(api.conf.PathPrefix, "/user/oidc/login")
+	}
 
 	config.Endpoints["cluster_controller"] = fmt.Sprintf("https://%s", api.conf.ControllerDomain)
 	config.Endpoints["cluster_status"] = fmt.Sprintf("https://%s", api.conf.StatusDomain)
@@ -282,6 +315,9 @@ func (api *API) GetConfig(ctx context.Context, w http.ResponseWriter, req *http.
 		}
 
 		config.User.ControllerKey = api.conf.ControllerKey
+		if api.oidc != nil {
+			config.User.ControllerKey = api.oidcToken(ctx)
+		}
 		config.User.StatusKey = api.conf.StatusKey
 	}
 
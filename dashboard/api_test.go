@@ -9,6 +9,8 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/flynn/flynn/pkg/oidc"
+	"github.com/flynn/flynn/pkg/oidc/oidctest"
 	. "github.com/flynn/go-check"
 	"github.com/gorilla/sessions"
 )
@@ -79,3 +81,80 @@ func (s *S) TestUserSessionForm(c *C) {
 	c.Assert(res.Header.Get("Location"), Equals, s.cookiePath)
 	s.testAuthenticated(c, client)
 }
+
+func (s *S) TestOIDCLogin(c *C) {
+	issuer, err := oidctest.NewIssuer()
+	c.Assert(err, IsNil)
+	defer issuer.Close()
+
+	// the server URL is needed for the redirect URL, so set the handler
+	// once the server has started
+	srv := httptest.NewUnstartedServer(nil)
+	srv.Start()
+	defer srv.Close()
+	srv.Config.Handler = APIHandler(&Config{
+		SessionStore:     sessions.NewCookieStore([]byte("session-secret")),
+		ControllerKey:    testControllerKey,
+		CookiePath:       s.cookiePath,
+		URL:              srv.URL,
+		OIDCIssuer:       issuer.URL,
+		OIDCClientID:     "dashboard",
+		OIDCClientSecret: "secret",
+		OIDCScopes:       []string{"openid"},
+	})
+
+	jar, err := cookiejar.New(&cookiejar.Options{})
+	c.Assert(err, IsNil)
+	client := &http.Client{Jar: jar}
+
+	// the login token is rejected
+	data, err := json.Marshal(&LoginInfo{Token: testLoginToken})
+	c.Assert(err, IsNil)
+	res, err := client.Post(srv.URL+"/user/sessions", "application/json", bytes.NewReader(data))
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(res.StatusCode, Equals, 401)
+
+	// the config includes the OIDC login endpoint
+	var conf *UserConfig
+	res, err = client.Get(srv.URL + "/config")
+	c.Assert(err, IsNil)
+	c.Assert(json.NewDecoder(res.Body).Decode(&conf), IsNil)
+	res.Body.Close()
+	c.Assert(conf.User, IsNil)
+	c.Assert(conf.Endpoints["oidc_login"], Equals, "/user/oidc/login")
+
+	// logging in redirects via the issuer back to the requested page
+	issuer.SetUser(map[string]interface{}{"sub": "alice"})
+	var redirects []*url.URL
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		redirects = append(redirects, req.URL)
+		if req.URL.Path == "/apps" {
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}
+	res, err = client.Get(srv.URL + "/user/oidc/login?redirect=/apps")
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(res.StatusCode, Equals, 302)
+	c.Assert(redirects, HasLen, 3)
+	c.Assert(redirects[2].Path, Equals, "/apps")
+
+	// the ID token is used as the controller key
+	res, err = client.Get(srv.URL + "/config")
+	c.Assert(err, IsNil)
+	c.Assert(json.NewDecoder(res.Body).Decode(&conf), IsNil)
+	res.Body.Close()
+	c.Assert(conf.User, Not(IsNil))
+	verifier := oidc.NewVerifier(issuer.URL, []string{"dashboard"}, issuer.URL+"/keys")
+	claims, err := verifier.Verify(conf.User.ControllerKey)
+	c.Assert(err, IsNil)
+	c.Assert(claims.Subject(), Equals, "alice")
+
+	// a callback with an invalid state is rejected
+	res, err = client.Get(srv.URL + "/user/oidc/callback?code=foo&state=bar")
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(res.StatusCode, Equals, 401)
+}
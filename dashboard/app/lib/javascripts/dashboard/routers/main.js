@@ -44,6 +44,13 @@ var MainRouter = Router.createClass({
 			return;
 		}
 
+		if (Config.endpoints.oidc_login) {
+			// the OIDC provider redirects back to the dashboard once
+			// the user has authenticated
+			window.location.href = Config.endpoints.oidc_login + "?redirect=" + encodeURIComponent((Config.PATH_PREFIX || '') + "/" + decodeURIComponent(redirectPath).replace(/^\//, ""));
+			return;
+		}
+
 		if (params[0].token) {
 			LoginModel.setValue("token", params[0].token);
 			LoginModel.performLogin().then(function () {
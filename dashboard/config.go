@@ -33,6 +33,10 @@ type Config struct {
 	InstallCert             bool
 	Cache                   bool
 	DefaultDeployTimeout    int
+	OIDCIssuer              string
+	OIDCClientID            string
+	OIDCClientSecret        string
+	OIDCScopes              []string
 }
 
 func LoadConfigFromEnv() *Config {
@@ -83,9 +87,24 @@ func LoadConfigFromEnv() *Config {
 
 	conf.SecureCookies = os.Getenv("SECURE_COOKIES") != ""
 
-	conf.LoginToken = os.Getenv("LOGIN_TOKEN")
-	if conf.LoginToken == "" {
-		log.Fatal("LOGIN_TOKEN is required")
+	// users log in with an OpenID Connect provider if configured, otherwise
+	// with the login token
+	conf.OIDCIssuer = os.Getenv("OIDC_ISSUER")
+	if conf.OIDCIssuer != "" {
+		conf.OIDCClientID = os.Getenv("OIDC_CLIENT_ID")
+		if conf.OIDCClientID == "" {
+			log.Fatal("OIDC_CLIENT_ID is required when OIDC_ISSUER is set")
+		}
+		conf.OIDCClientSecret = os.Getenv("OIDC_CLIENT_SECRET")
+		conf.OIDCScopes = []string{"openid", "profile", "email"}
+		if scopes := os.Getenv("OIDC_SCOPES"); scopes != "" {
+			conf.OIDCScopes = strings.Split(scopes, ",")
+		}
+	} else {
+		conf.LoginToken = os.Getenv("LOGIN_TOKEN")
+		if conf.LoginToken == "" {
+			log.Fatal("LOGIN_TOKEN is required")
+		}
 	}
 
 	conf.GithubToken = os.Getenv("GITHUB_TOKEN")
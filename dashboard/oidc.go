@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flynn/flynn/pkg/httphelper"
+	"github.com/flynn/flynn/pkg/oidc"
+	"github.com/flynn/flynn/pkg/random"
+	"golang.org/x/net/context"
+	"golang.org/x/oauth2"
+)
+
+// oidcProvider authenticates dashboard users with an OpenID Connect provider
+// using the authorization code flow, with the resulting ID token being used
+// as the user's controller key
+type oidcProvider struct {
+	oauth    *oauth2.Config
+	verifier *oidc.Verifier
+}
+
+func newOIDCProvider(conf *Config) (*oidcProvider, error) {
+	provider, err := oidc.Discover(conf.OIDCIssuer)
+	if err != nil {
+		return nil, err
+	}
+	return &oidcProvider{
+		oauth: &oauth2.Config{
+			ClientID:     conf.OIDCClientID,
+			ClientSecret: conf.OIDCClientSecret,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  provider.AuthorizationEndpoint,
+				TokenURL: provider.TokenEndpoint,
+			},
+			RedirectURL: strings.TrimSuffix(conf.URL, "/") + conf.PathPrefix + "/user/oidc/callback",
+			Scopes:      conf.OIDCScopes,
+		},
+		verifier: oidc.NewVerifier(provider.Issuer, []string{conf.OIDCClientID}, provider.JWKSURI),
+	}, nil
+}
+
+// OIDCLogin redirects the user to the provider to authenticate, storing the
+// state and the page to return to in the session
+func (api *API) OIDCLogin(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	state := random.Hex(16)
+	s := api.SessionFromContext(ctx)
+	s.Values["oidc_state"] = state
+	s.Values["oidc_redirect"] = req.URL.Query().Get("redirect")
+	s.Save(req, w)
+	http.Redirect(w, req, api.oidc.oauth.AuthCodeURL(state), 302)
+}
+
+// OIDCCallback exchanges the authorization code for an ID token, which is
+// stored in the session and used as the user's controller key until it
+// expires
+func (api *API) OIDCCallback(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	s := api.SessionFromContext(ctx)
+	q := req.URL.Query()
+	state, _ := s.Values["oidc_state"].(string)
+	delete(s.Values, "oidc_state")
+	if state == "" || q.Get("state") != state {
+		httphelper.Error(w, httphelper.JSONError{
+			Code:    httphelper.UnauthorizedErrorCode,
+			Message: "Invalid OIDC state",
+		})
+		return
+	}
+	if e := q.Get("error"); e != "" {
+		httphelper.Error(w, httphelper.JSONError{
+			Code:    httphelper.UnauthorizedErrorCode,
+			Message: fmt.Sprintf("OIDC login failed: %s", e),
+		})
+		return
+	}
+	token, err := api.oidc.oauth.Exchange(ctx, q.Get("code"))
+	if err != nil {
+		httphelper.Error(w, httphelper.JSONError{
+			Code:    httphelper.UnauthorizedErrorCode,
+			Message: fmt.Sprintf("OIDC login failed: %s", err),
+		})
+		return
+	}
+	idToken, _ := token.Extra("id_token").(string)
+	claims, err := api.oidc.verifier.Verify(idToken)
+	if err != nil {
+		httphelper.Error(w, httphelper.JSONError{
+			Code:    httphelper.UnauthorizedErrorCode,
+			Message: fmt.Sprintf("OIDC login failed: %s", err),
+		})
+		return
+	}
+	exp, _ := claims.Time("exp")
+	s.Values["oidc_token"] = idToken
+	s.Values["oidc_expires"] = exp.Unix()
+
+	// only redirect within the dashboard
+	redirect, _ := s.Values["oidc_redirect"].(string)
+	delete(s.Values, "oidc_redirect")
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") {
+		redirect = api.conf.CookiePath
+	}
+	api.SetAuthenticated(ctx, w, req)
+	http.Redirect(w, req, redirect, 302)
+}
+
+// oidcToken returns the ID token stored in the session, or an empty string
+// if it has expired
+func (api *API) oidcToken(ctx context.Context) string {
+	s := api.SessionFromContext(ctx)
+	token, _ := s.Values["oidc_token"].(string)
+	expires, _ := s.Values["oidc_expires"].(int64)
+	if token == "" || time.Now().After(time.Unix(expires, 0)) {
+		return ""
+	}
+	return token
+}
@@ -49,6 +49,12 @@ type JobStateSaver interface {
 	MarshalJobState(jobID string) ([]byte, error)
 }
 
+// JobStatsGetter is implemented by backends which can report the resource
+// usage of running jobs
+type JobStatsGetter interface {
+	JobStats(jobID string) (*host.JobStats, error)
+}
+
 type StateSaver interface {
 	MarshalGlobalState() ([]byte, error)
 }
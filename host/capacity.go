@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/flynn/flynn/host/resource"
+)
+
+// detectCapacity determines the total amount of memory, CPU and temporary
+// disk space on the host, skipping any resource types which cannot be
+// determined (the scheduler treats missing types as unlimited)
+func detectCapacity() map[resource.Type]int64 {
+	capacity := make(map[resource.Type]int64, 3)
+	if mem, err := totalMemory(); err == nil {
+		capacity[resource.TypeMemory] = mem
+	}
+	capacity[resource.TypeCPU] = int64(runtime.NumCPU()) * 1000
+	if disk, err := availableDisk(os.TempDir()); err == nil {
+		capacity[resource.TypeTempDisk] = disk
+	}
+	return capacity
+}
+
+// totalMemory returns the total amount of memory in bytes as reported by
+// /proc/meminfo
+func totalMemory() (int64, error) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemTotal:" {
+			continue
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return kb * 1024, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("MemTotal missing from /proc/meminfo")
+}
+
+// availableDisk returns the number of bytes available in the filesystem
+// containing the given path
+func availableDisk(path string) (int64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flynn/flynn/discoverd/client"
+	"github.com/flynn/flynn/host/types"
+	"github.com/flynn/flynn/pkg/cluster"
+	"github.com/flynn/flynn/pkg/httpclient"
+	"github.com/flynn/flynn/pkg/httphelper"
+	"github.com/flynn/go-docopt"
+)
+
+func init() {
+	Register("cordon", runCordon, `
+usage: flynn-host cordon <hostid>
+
+Cordon a host so that the scheduler does not place new jobs on it.
+
+Jobs already running on the host are left running, use "flynn-host drain" to
+move them to other hosts.`)
+
+	Register("uncordon", runUncordon, `
+usage: flynn-host uncordon <hostid>
+
+Uncordon a host so that the scheduler can place new jobs on it again, cancelling
+any in-progress drain of the host.`)
+
+	Register("drain", runDrain, `
+usage: flynn-host drain [--no-wait] <hostid>
+
+Cordon a host and move its jobs to other hosts.
+
+Jobs are moved one at a time by starting a replacement job on another host and
+stopping the original job once the replacement is running, so formations keep
+their expected number of running jobs, and are only stopped once the
+disruption budget of their process type allows. Omni jobs and jobs with
+volumes on the host are left running.
+
+Options:
+	-n, --no-wait  don't wait for the drain to complete
+
+Example:
+
+  $ flynn-host drain host1
+  15:04:05.000 started
+  15:04:05.002 job_moving    host1-f18a2ef4-b2b7-4c0a-9e8b-3bc2e6a4d7a0 (web)  starting replacement job on another host
+  15:04:08.317 job_stopping  host1-f18a2ef4-b2b7-4c0a-9e8b-3bc2e6a4d7a0 (web)  replacement job is running
+  15:04:09.421 job_stopped   host1-f18a2ef4-b2b7-4c0a-9e8b-3bc2e6a4d7a0 (web)
+  15:04:09.422 complete
+`)
+}
+
+func runCordon(args *docopt.Args, client *cluster.Client) error {
+	h, err := client.Host(args.String["<hostid>"])
+	if err != nil {
+		return err
+	}
+	if err := h.Cordon(); err != nil {
+		return err
+	}
+	fmt.Println(h.ID(), "cordoned")
+	return nil
+}
+
+func runUncordon(args *docopt.Args, client *cluster.Client) error {
+	h, err := client.Host(args.String["<hostid>"])
+	if err != nil {
+		return err
+	}
+	if err := h.Uncordon(); err != nil {
+		return err
+	}
+	fmt.Println(h.ID(), "uncordoned")
+	return nil
+}
+
+func runDrain(args *docopt.Args, client *cluster.Client) error {
+	h, err := client.Host(args.String["<hostid>"])
+	if err != nil {
+		return err
+	}
+	if err := h.Cordon(); err != nil {
+		return err
+	}
+
+	scheduler, err := schedulerClient()
+	if err != nil {
+		return err
+	}
+
+	// the scheduler learns that the host is cordoned asynchronously via
+	// service discovery, so retry starting the drain until it has
+	var status host.DrainStatus
+	path := "/drain/" + h.ID()
+	for start := time.Now(); ; time.Sleep(100 * time.Millisecond) {
+		err = scheduler.Post(path, nil, &status)
+		if err == nil || !httphelper.IsValidationError(err) || time.Since(start) > 10*time.Second {
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if args.Bool["--no-wait"] {
+		fmt.Println(h.ID(), "draining")
+		return nil
+	}
+
+	printed := 0
+	for {
+		for _, e := range status.Events[printed:] {
+			job := ""
+			if e.JobID != "" {
+				job = fmt.Sprintf("%s (%s)", e.JobID, e.JobType)
+			}
+			line := fmt.Sprintf("%s %-13s %s  %s", e.CreatedAt.Format("15:04:05.000"), e.Type, job, e.Message)
+			fmt.Println(strings.TrimRight(line, " "))
+		}
+		printed = len(status.Events)
+
+		switch status.State {
+		case host.DrainStateComplete:
+			return nil
+		case host.DrainStateCancelled:
+			return fmt.Errorf("drain of %s was cancelled", h.ID())
+		}
+
+		time.Sleep(time.Second)
+		if err := scheduler.Get(path, &status); err != nil {
+			return err
+		}
+	}
+}
+
+// schedulerClient returns a client for the scheduler leader's HTTP API
+func schedulerClient() (*httpclient.Client, error) {
+	leader, err := discoverd.NewService("controller-scheduler").Leader()
+	if err != nil {
+		return nil, err
+	}
+	return &httpclient.Client{
+		URL:  "http://" + leader.Addr,
+		HTTP: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
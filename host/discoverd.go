@@ -4,16 +4,18 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"sync"
 	"sync/atomic"
 
 	"github.com/flynn/flynn/discoverd/client"
 	"github.com/flynn/flynn/host/logmux"
+	"github.com/flynn/flynn/host/resource"
 	"github.com/flynn/flynn/host/types"
 	"github.com/flynn/flynn/pkg/shutdown"
 )
 
-func NewDiscoverdManager(backend Backend, sinkManager *logmux.SinkManager, hostID, publishAddr string, tags map[string]string) *DiscoverdManager {
+func NewDiscoverdManager(backend Backend, sinkManager *logmux.SinkManager, hostID, publishAddr string, tags map[string]string, capacity map[resource.Type]int64) *DiscoverdManager {
 	d := &DiscoverdManager{
 		backend:     backend,
 		sinkManager: sinkManager,
@@ -25,6 +27,9 @@ func NewDiscoverdManager(backend Backend, sinkManager *logmux.SinkManager, hostI
 	for k, v := range tags {
 		d.inst.Meta[host.TagPrefix+k] = v
 	}
+	for typ, v := range capacity {
+		d.inst.Meta[host.CapacityPrefix+string(typ)] = strconv.FormatInt(v, 10)
+	}
 	d.local.Store(false)
 	return d
 }
@@ -137,3 +142,17 @@ func (d *DiscoverdManager) UpdateTags(tags map[string]string) error {
 	}
 	return d.hb.SetMeta(d.inst.Meta)
 }
+
+func (d *DiscoverdManager) SetCordoned(cordoned bool) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if cordoned {
+		d.inst.Meta[host.CordonedMeta] = "true"
+	} else {
+		delete(d.inst.Meta, host.CordonedMeta)
+	}
+	if d.hb == nil {
+		return nil
+	}
+	return d.hb.SetMeta(d.inst.Meta)
+}
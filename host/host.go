@@ -20,6 +20,7 @@ import (
 	"github.com/flynn/flynn/host/cli"
 	"github.com/flynn/flynn/host/config"
 	"github.com/flynn/flynn/host/logmux"
+	"github.com/flynn/flynn/host/resource"
 	host "github.com/flynn/flynn/host/types"
 	"github.com/flynn/flynn/host/volume"
 	volumeapi "github.com/flynn/flynn/host/volume/api"
@@ -47,6 +48,7 @@ options:
   --sink-state=PATH          path to the sink state file [default: /var/lib/flynn/sink-state.bolt]
   --id=ID                    host id
   --tags=TAGS                host tags (comma separated list of KEY=VAL pairs, used for job constraints in the scheduler)
+  --capacity=CAPACITY        allocatable resources used to limit job placement in the scheduler (comma separated list of TYPE=VAL pairs, "auto" detects memory, cpu and temp_disk)
   --force                    kill all containers booted by flynn-host before starting
   --volpath=PATH             directory to create volumes in [default: /var/lib/flynn/volumes]
   --vol-provider=VOL         volume provider [default: zfs]
@@ -107,6 +109,9 @@ Commands:
   version                    Show current version
   fix                        Fix a broken cluster
   tags                       Manage flynn-host daemon tags
+  cordon                     Stop the scheduler placing new jobs on a host
+  uncordon                   Allow the scheduler to place new jobs on a host
+  drain                      Cordon a host and move its jobs to other hosts
   discover                   Return low-level information about a service
   promote                    Promotes a Flynn node to a member of the consensus cluster
   demote                     Demotes a Flynn node, removing it from the consensus cluster
@@ -177,6 +182,10 @@ func runDaemon(args *docopt.Args) {
 	sinkFile := args.String["--sink-state"]
 	hostID := args.String["--id"]
 	tags := parseTagArgs(args.String["--tags"])
+	capacity, err := parseCapacityArgs(args.String["--capacity"])
+	if err != nil {
+		shutdown.Fatalf("error parsing capacity: %s", err)
+	}
 	force := args.Bool["--force"]
 	volPath := args.String["--volpath"]
 	volProvider := args.String["--vol-provider"]
@@ -331,15 +340,16 @@ func runDaemon(args *docopt.Args) {
 	backend.SetDefaultEnv("LISTEN_IP", listenIP)
 
 	var buffers host.LogBuffers
-	discoverdManager := NewDiscoverdManager(backend, sman, hostID, publishAddr, tags)
+	discoverdManager := NewDiscoverdManager(backend, sman, hostID, publishAddr, tags, capacity)
 	publishURL := "http://" + publishAddr
 	host := &Host{
 		id:  hostID,
 		url: publishURL,
 		status: &host.HostStatus{
-			ID:   hostID,
-			URL:  publishURL,
-			Tags: tags,
+			ID:       hostID,
+			URL:      publishURL,
+			Tags:     tags,
+			Capacity: capacity,
 		},
 		state:   state,
 		backend: backend,
@@ -360,8 +370,9 @@ func runDaemon(args *docopt.Args) {
 			log.Error("error restoring host status from parent", "err", err)
 			shutdown.Fatal(err)
 		}
-		// keep the same tags as the parent
+		// keep the same tags and cordon as the parent
 		discoverdManager.UpdateTags(host.status.Tags)
+		discoverdManager.SetCordoned(host.status.Cordoned)
 	}
 	pid := os.Getpid()
 	log.Info("setting host status PID", "pid", pid)
@@ -417,6 +428,15 @@ func runDaemon(args *docopt.Args) {
 		shutdown.Fatal(err)
 	}
 
+	// keep the host cordoned if it was cordoned before restarting
+	if state.Cordoned() && !host.status.Cordoned {
+		log.Info("restoring host cordon")
+		if err := host.SetCordoned(true); err != nil {
+			log.Error("error restoring host cordon", "err", err)
+			shutdown.Fatal(err)
+		}
+	}
+
 	// stopJobs stops all jobs, leaving discoverd until the end so other
 	// jobs can unregister themselves on shutdown.
 	stopJobs := func() (err error) {
@@ -571,6 +591,44 @@ func parseTagArgs(args string) map[string]string {
 	return tags
 }
 
+// parseCapacityArgs parses a comma separated list of TYPE=VAL pairs into a
+// map of allocatable resources, with "auto" setting any memory, CPU and temp
+// disk capacities which are not explicitly set to those detected on the host
+func parseCapacityArgs(args string) (map[resource.Type]int64, error) {
+	capacity := make(map[resource.Type]int64)
+	var auto bool
+	for _, s := range strings.Split(args, ",") {
+		if s == "" {
+			continue
+		}
+		if s == "auto" {
+			auto = true
+			continue
+		}
+		typVal := strings.SplitN(s, "=", 2)
+		if len(typVal) != 2 {
+			return nil, fmt.Errorf("invalid capacity: %q", s)
+		}
+		typ, ok := resource.ToType(typVal[0])
+		if !ok {
+			return nil, fmt.Errorf("invalid capacity type: %q", typVal[0])
+		}
+		val, err := resource.ParseLimit(typ, typVal[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid capacity value: %q", typVal[1])
+		}
+		capacity[typ] = val
+	}
+	if auto {
+		for typ, val := range detectCapacity() {
+			if _, ok := capacity[typ]; !ok {
+				capacity[typ] = val
+			}
+		}
+	}
+	return capacity, nil
+}
+
 func setupLogger(logDir, logFile string) (log15.Logger, error) {
 	if err := os.MkdirAll(logDir, 0755); err != nil {
 		return nil, err
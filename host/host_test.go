@@ -1,6 +1,10 @@
 package main
 
-import . "github.com/flynn/go-check"
+import (
+	"github.com/docker/go-units"
+	"github.com/flynn/flynn/host/resource"
+	. "github.com/flynn/go-check"
+)
 
 func (S) TestParseTagArgs(c *C) {
 	type test struct {
@@ -57,3 +61,27 @@ func (S) TestParseTagArgs(c *C) {
 		c.Assert(actual, DeepEquals, t.expected, Commentf("parsing %q", t.args))
 	}
 }
+
+func (S) TestParseCapacityArgs(c *C) {
+	capacity, err := parseCapacityArgs("")
+	c.Assert(err, IsNil)
+	c.Assert(capacity, HasLen, 0)
+
+	capacity, err = parseCapacityArgs("memory=8GB,cpu=4000")
+	c.Assert(err, IsNil)
+	c.Assert(capacity, DeepEquals, map[resource.Type]int64{
+		resource.TypeMemory: 8 * units.GiB,
+		resource.TypeCPU:    4000,
+	})
+
+	detected := detectCapacity()
+	capacity, err = parseCapacityArgs("auto,memory=8GB")
+	c.Assert(err, IsNil)
+	c.Assert(capacity[resource.TypeMemory], Equals, int64(8*units.GiB))
+	c.Assert(capacity[resource.TypeCPU], Equals, detected[resource.TypeCPU])
+
+	for _, args := range []string{"memory", "foo=1", "memory=lots"} {
+		_, err := parseCapacityArgs(args)
+		c.Assert(err, NotNil, Commentf("parsing %q", args))
+	}
+}
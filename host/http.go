@@ -105,6 +105,21 @@ func (h *Host) SignalJob(id string, sig int) error {
 	return h.backend.Signal(id, sig)
 }
 
+func (h *Host) JobStats(id string) (*host.JobStats, error) {
+	job := h.state.GetJob(id)
+	if job == nil {
+		return nil, ErrNotFound
+	}
+	if job.Status != host.StatusRunning {
+		return nil, errors.New("host: job is not running")
+	}
+	backend, ok := h.backend.(JobStatsGetter)
+	if !ok {
+		return nil, errors.New("host: backend does not support job stats")
+	}
+	return backend.JobStats(id)
+}
+
 func (h *Host) DiscoverdDeregisterJob(id string) error {
 	log := h.log.New("fn", "DiscoverdDeregisterJob", "job.id", id)
 
@@ -223,6 +238,19 @@ func (h *jobAPI) GetJob(w http.ResponseWriter, r *http.Request, ps httprouter.Pa
 	httphelper.JSON(w, 200, job)
 }
 
+func (h *jobAPI) GetJobStats(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id := ps.ByName("id")
+	stats, err := h.host.JobStats(id)
+	if err == ErrNotFound {
+		httphelper.ObjectNotFoundError(w, err.Error())
+		return
+	} else if err != nil {
+		httphelper.Error(w, err)
+		return
+	}
+	httphelper.JSON(w, 200, stats)
+}
+
 func (h *jobAPI) StopJob(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	id := ps.ByName("id")
 	if err := h.host.StopJob(id); err != nil {
@@ -476,6 +504,42 @@ func (h *Host) UpdateTags(tags map[string]string) error {
 	return nil
 }
 
+func (h *jobAPI) Cordon(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if err := h.host.SetCordoned(true); err != nil {
+		httphelper.Error(w, err)
+		return
+	}
+	w.WriteHeader(200)
+}
+
+func (h *jobAPI) Uncordon(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if err := h.host.SetCordoned(false); err != nil {
+		httphelper.Error(w, err)
+		return
+	}
+	w.WriteHeader(200)
+}
+
+// SetCordoned sets whether the host is cordoned, publishing it in the host's
+// discoverd metadata so that the scheduler stops placing new jobs on it, and
+// persisting it so the host stays cordoned across restarts
+func (h *Host) SetCordoned(cordoned bool) error {
+	if err := h.state.Acquire(); err != nil {
+		return err
+	}
+	defer h.state.Release()
+	h.statusMtx.Lock()
+	defer h.statusMtx.Unlock()
+	if err := h.state.PersistCordoned(cordoned); err != nil {
+		return err
+	}
+	if err := h.discMan.SetCordoned(cordoned); err != nil {
+		return err
+	}
+	h.status.Cordoned = cordoned
+	return nil
+}
+
 func checkPort(port host.Port) bool {
 	l, err := net.Listen(port.Proto, fmt.Sprintf(":%d", port.Port))
 	if err != nil {
@@ -583,6 +647,7 @@ func (h *jobAPI) RegisterRoutes(r *httprouter.Router) error {
 	r.GET("/host/jobs/:id", h.GetJob)
 	r.PUT("/host/jobs/:id", h.AddJob)
 	r.DELETE("/host/jobs/:id", h.StopJob)
+	r.GET("/host/jobs/:id/stats", h.GetJobStats)
 	r.PUT("/host/jobs/:id/discoverd-deregister", h.DiscoverdDeregisterJob)
 	r.PUT("/host/jobs/:id/signal/:signal", h.SignalJob)
 	r.POST("/host/pull/images", h.PullImages)
@@ -593,6 +658,8 @@ func (h *jobAPI) RegisterRoutes(r *httprouter.Router) error {
 	r.POST("/host/resource-check", h.ResourceCheck)
 	r.POST("/host/update", h.Update)
 	r.POST("/host/tags", h.UpdateTags)
+	r.PUT("/host/cordon", h.Cordon)
+	r.DELETE("/host/cordon", h.Uncordon)
 	return nil
 }
 
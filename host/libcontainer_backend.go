@@ -1233,6 +1233,26 @@ func (l *LibcontainerBackend) getContainer(id string) (*Container, error) {
 	return c, nil
 }
 
+func (l *LibcontainerBackend) JobStats(id string) (*host.JobStats, error) {
+	container, err := l.getContainer(id)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := container.container.Stats()
+	if err != nil {
+		return nil, err
+	}
+	res := &host.JobStats{JobID: id, Timestamp: time.Now().UTC()}
+	if cg := stats.CgroupStats; cg != nil {
+		res.CPUUsage = cg.CpuStats.CpuUsage.TotalUsage
+		res.MemoryUsage = cg.MemoryStats.Usage.Usage
+		if cache := cg.MemoryStats.Cache; cache < res.MemoryUsage {
+			res.MemoryUsage -= cache
+		}
+	}
+	return res, nil
+}
+
 func (l *LibcontainerBackend) ResizeTTY(id string, height, width uint16) error {
 	container, err := l.getContainer(id)
 	if err != nil {
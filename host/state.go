@@ -187,6 +187,7 @@ func (s *State) OpenDB() error {
 		tx.CreateBucketIfNotExists([]byte("backend-jobs"))
 		tx.CreateBucketIfNotExists([]byte("backend-global"))
 		tx.CreateBucketIfNotExists([]byte("persistent-jobs"))
+		tx.CreateBucketIfNotExists([]byte("host"))
 		return nil
 	}); err != nil {
 		return fmt.Errorf("could not initialize host persistence db: %s", err)
@@ -251,6 +252,27 @@ func (s *State) PersistBackendGlobalState(data []byte) error {
 	})
 }
 
+// PersistCordoned persists whether the host is cordoned so that it stays
+// cordoned when the daemon restarts
+func (s *State) PersistCordoned(cordoned bool) error {
+	return s.stateDB.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte("host"))
+		if cordoned {
+			return bucket.Put([]byte("cordoned"), []byte("true"))
+		}
+		return bucket.Delete([]byte("cordoned"))
+	})
+}
+
+// Cordoned returns whether the host was cordoned when the daemon last ran
+func (s *State) Cordoned() (cordoned bool) {
+	s.stateDB.View(func(tx *bolt.Tx) error {
+		cordoned = tx.Bucket([]byte("host")).Get([]byte("cordoned")) != nil
+		return nil
+	})
+	return
+}
+
 func (s *State) pruneDownJobs(tx *bolt.Tx, downJobs *list.List) error {
 	for downJobs.Len() > maxDownJobsPerApp {
 		el := downJobs.Back()
@@ -11,6 +11,14 @@ import (
 // TagPrefix is the prefix added to tags in discoverd instance metadata
 const TagPrefix = "tag:"
 
+// CapacityPrefix is the prefix added to allocatable resource capacities in
+// discoverd instance metadata
+const CapacityPrefix = "capacity:"
+
+// CordonedMeta is the discoverd instance metadata key which is set when a host
+// is cordoned, indicating that the scheduler should not place new jobs on it
+const CordonedMeta = "cordoned"
+
 const DiffPath = "/.container-diff"
 
 type Job struct {
@@ -271,6 +279,22 @@ type ActiveJob struct {
 	Error      *string   `json:"error,omitempty"`
 }
 
+// JobStats is a snapshot of a running job's resource usage as reported by its
+// cgroups
+type JobStats struct {
+	JobID string `json:"job_id,omitempty"`
+
+	// CPUUsage is the total CPU time consumed by the job in nanoseconds,
+	// which can be sampled periodically to determine CPU utilisation
+	CPUUsage uint64 `json:"cpu_usage"`
+
+	// MemoryUsage is the job's current memory usage in bytes, excluding
+	// the page cache
+	MemoryUsage uint64 `json:"memory_usage"`
+
+	Timestamp time.Time `json:"timestamp"`
+}
+
 func (j *ActiveJob) Dup() *ActiveJob {
 	job := *j
 	job.Job = j.Job.Dup()
@@ -358,6 +382,67 @@ type HostStatus struct {
 	Network   *NetworkConfig    `json:"network,omitempty"`
 	Version   string            `json:"version"`
 	Flags     []string          `json:"flags"`
+
+	// Capacity is the amount of each resource type the host can allocate
+	// to jobs, and is used by the scheduler to avoid placing jobs on hosts
+	// which do not have room for their resource requests
+	Capacity map[resource.Type]int64 `json:"capacity,omitempty"`
+
+	// Cordoned is whether the host has been cordoned, in which case the
+	// scheduler does not place new jobs on it
+	Cordoned bool `json:"cordoned,omitempty"`
+}
+
+// DrainState is the state of a host drain
+type DrainState string
+
+const (
+	// DrainStateDraining is the state of a drain which is moving jobs
+	// off the host
+	DrainStateDraining DrainState = "draining"
+
+	// DrainStateComplete is the state of a drain once all jobs which can
+	// be moved have been moved off the host
+	DrainStateComplete DrainState = "complete"
+
+	// DrainStateCancelled is the state of a drain which was stopped
+	// before completing, either because the host was uncordoned or left
+	// the cluster
+	DrainStateCancelled DrainState = "cancelled"
+)
+
+// DrainEventType is the type of a host drain progress event
+type DrainEventType string
+
+const (
+	DrainEventStarted     DrainEventType = "started"
+	DrainEventJobMoving   DrainEventType = "job_moving"
+	DrainEventJobBlocked  DrainEventType = "job_blocked"
+	DrainEventJobWaiting  DrainEventType = "job_waiting"
+	DrainEventJobStopping DrainEventType = "job_stopping"
+	DrainEventJobStopped  DrainEventType = "job_stopped"
+	DrainEventJobSkipped  DrainEventType = "job_skipped"
+	DrainEventComplete    DrainEventType = "complete"
+	DrainEventCancelled   DrainEventType = "cancelled"
+)
+
+// DrainEvent records the progress of a host drain
+type DrainEvent struct {
+	Type      DrainEventType `json:"type"`
+	JobID     string         `json:"job_id,omitempty"`
+	AppID     string         `json:"app_id,omitempty"`
+	JobType   string         `json:"job_type,omitempty"`
+	Message   string         `json:"message,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// DrainStatus is the status of a scheduler moving jobs off a cordoned host
+// one at a time, starting a replacement for each job on another host and
+// only stopping the job once the replacement is running
+type DrainStatus struct {
+	HostID string        `json:"host_id"`
+	State  DrainState    `json:"state"`
+	Events []*DrainEvent `json:"events"`
 }
 
 type JobEventType string
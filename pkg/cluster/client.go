@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/flynn/flynn/discoverd/client"
+	"github.com/flynn/flynn/host/resource"
 	"github.com/flynn/flynn/host/types"
 	"github.com/flynn/flynn/pkg/httphelper"
 	"github.com/flynn/flynn/pkg/stream"
@@ -78,6 +80,8 @@ func (c *Client) Hosts() ([]*Host, error) {
 			c.h,
 			HostTagsFromMeta(inst.Meta),
 		)
+		hosts[i].capacity = HostCapacityFromMeta(inst.Meta)
+		hosts[i].cordoned = HostCordonedFromMeta(inst.Meta)
 	}
 	return hosts, nil
 }
@@ -92,6 +96,30 @@ func HostTagsFromMeta(meta map[string]string) map[string]string {
 	return tags
 }
 
+// HostCordonedFromMeta returns whether a host is cordoned according to its
+// discoverd instance metadata
+func HostCordonedFromMeta(meta map[string]string) bool {
+	_, ok := meta[host.CordonedMeta]
+	return ok
+}
+
+// HostCapacityFromMeta returns the allocatable resource capacity published in
+// a host's discoverd instance metadata
+func HostCapacityFromMeta(meta map[string]string) map[resource.Type]int64 {
+	capacity := make(map[resource.Type]int64)
+	for k, v := range meta {
+		if !strings.HasPrefix(k, host.CapacityPrefix) {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			continue
+		}
+		capacity[resource.Type(strings.TrimPrefix(k, host.CapacityPrefix))] = n
+	}
+	return capacity
+}
+
 func (c *Client) StreamHostEvents(ch chan *discoverd.Event) (stream.Stream, error) {
 	return c.s.Watch(ch)
 }
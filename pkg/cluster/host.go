@@ -12,6 +12,7 @@ import (
 	"time"
 
 	ct "github.com/flynn/flynn/controller/types"
+	"github.com/flynn/flynn/host/resource"
 	"github.com/flynn/flynn/host/types"
 	"github.com/flynn/flynn/host/volume"
 	"github.com/flynn/flynn/pkg/httpclient"
@@ -20,9 +21,11 @@ import (
 
 // Host is a client for a host daemon.
 type Host struct {
-	id   string
-	tags map[string]string
-	c    *httpclient.Client
+	id       string
+	tags     map[string]string
+	capacity map[resource.Type]int64
+	cordoned bool
+	c        *httpclient.Client
 }
 
 // NewHost creates a new Host that uses client to communicate with it.
@@ -55,6 +58,16 @@ func (c *Host) Tags() map[string]string {
 	return c.tags
 }
 
+// Capacity returns the host's allocatable resource capacity
+func (c *Host) Capacity() map[resource.Type]int64 {
+	return c.capacity
+}
+
+// Cordoned returns whether the host is cordoned
+func (c *Host) Cordoned() bool {
+	return c.cordoned
+}
+
 // Addr returns the IP/port that the host API is listening on.
 func (c *Host) Addr() string {
 	u, err := url.Parse(c.c.URL)
@@ -115,6 +128,13 @@ func (c *Host) GetJob(id string) (*host.ActiveJob, error) {
 	return &res, err
 }
 
+// GetJobStats returns the resource usage of a running job.
+func (c *Host) GetJobStats(id string) (*host.JobStats, error) {
+	var res host.JobStats
+	err := c.c.Get(fmt.Sprintf("/host/jobs/%s/stats", id), &res)
+	return &res, err
+}
+
 // StopJob stops a running job.
 func (c *Host) StopJob(id string) error {
 	return c.c.Delete(fmt.Sprintf("/host/jobs/%s", id))
@@ -250,6 +270,18 @@ func (c *Host) UpdateTags(tags map[string]string) error {
 	return c.c.Post("/host/tags", tags, nil)
 }
 
+// Cordon marks the host as cordoned so that the scheduler does not place new
+// jobs on it
+func (c *Host) Cordon() error {
+	return c.c.Put("/host/cordon", nil, nil)
+}
+
+// Uncordon removes the host's cordon so that the scheduler can place jobs on
+// it again
+func (c *Host) Uncordon() error {
+	return c.c.Delete("/host/cordon")
+}
+
 func (c *Host) GetSinks() ([]*ct.Sink, error) {
 	var sinks []*ct.Sink
 	return sinks, c.c.Get("/sinks", &sinks)
@@ -0,0 +1,61 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	for _, test := range []struct {
+		expr string
+		from string
+		next string
+	}{
+		{"* * * * *", "2017-01-01T00:00:30Z", "2017-01-01T00:01:00Z"},
+		{"*/15 * * * *", "2017-01-01T00:01:00Z", "2017-01-01T00:15:00Z"},
+		{"5/15 * * * *", "2017-01-01T00:21:00Z", "2017-01-01T00:35:00Z"},
+		{"0 * * * *", "2017-01-01T00:00:00Z", "2017-01-01T01:00:00Z"},
+		{"30 2 * * *", "2017-01-01T03:00:00Z", "2017-01-02T02:30:00Z"},
+		{"0 0 1 * *", "2017-01-15T00:00:00Z", "2017-02-01T00:00:00Z"},
+		{"0 0 * * mon-fri", "2017-01-06T12:00:00Z", "2017-01-09T00:00:00Z"},
+		{"0 0 * * 7", "2017-01-02T00:00:00Z", "2017-01-08T00:00:00Z"},
+		{"0 0 1 jan,jul *", "2017-02-01T00:00:00Z", "2017-07-01T00:00:00Z"},
+		{"0 0 13 * fri", "2017-01-01T00:00:00Z", "2017-01-06T00:00:00Z"},
+		{"0 0 29 2 *", "2017-01-01T00:00:00Z", "2020-02-29T00:00:00Z"},
+		{"0 0 30 2 *", "2017-01-01T00:00:00Z", "0001-01-01T00:00:00Z"},
+		{"@hourly", "2017-01-01T00:59:00Z", "2017-01-01T01:00:00Z"},
+		{"@weekly", "2017-01-01T00:00:00Z", "2017-01-08T00:00:00Z"},
+		{"@yearly", "2017-01-01T00:00:00Z", "2018-01-01T00:00:00Z"},
+	} {
+		s, err := Parse(test.expr)
+		if err != nil {
+			t.Fatalf("error parsing %q: %s", test.expr, err)
+		}
+		from, _ := time.Parse(time.RFC3339, test.from)
+		next := s.Next(from).Format(time.RFC3339)
+		if next != test.next {
+			t.Errorf("%q: expected next time after %s to be %s, got %s", test.expr, test.from, test.next, next)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+		"@never",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("expected error parsing %q", expr)
+		}
+	}
+}
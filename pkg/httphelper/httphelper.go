@@ -34,6 +34,7 @@ const (
 	ValidationErrorCode         ErrorCode = "validation_error"
 	PreconditionFailedErrorCode ErrorCode = "precondition_failed"
 	UnauthorizedErrorCode       ErrorCode = "unauthorized"
+	ForbiddenErrorCode          ErrorCode = "forbidden"
 	UnknownErrorCode            ErrorCode = "unknown_error"
 	RatelimitedErrorCode        ErrorCode = "ratelimited"
 	ServiceUnavailableErrorCode ErrorCode = "service_unavailable"
@@ -52,6 +53,7 @@ var errorResponseCodes = map[ErrorCode]int{
 	ValidationErrorCode:         400,
 	RequestBodyTooBigErrorCode:  400,
 	UnauthorizedErrorCode:       401,
+	ForbiddenErrorCode:          403,
 	UnknownErrorCode:            500,
 	RatelimitedErrorCode:        429,
 	ServiceUnavailableErrorCode: 503,
@@ -85,6 +87,10 @@ func IsValidationError(err error) bool {
 	return isJSONErrorWithCode(err, ValidationErrorCode)
 }
 
+func IsForbiddenError(err error) bool {
+	return isJSONErrorWithCode(err, ForbiddenErrorCode)
+}
+
 // IsRetryableError indicates whether a HTTP request can be safely retried.
 func IsRetryableError(err error) bool {
 	e, ok := err.(JSONError)
@@ -0,0 +1,413 @@
+// Package oidc implements verification of JSON Web Tokens issued by an
+// OpenID Connect provider, along with discovery of the provider's endpoints.
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrMalformedToken   = errors.New("oidc: malformed token")
+	ErrInvalidSignature = errors.New("oidc: invalid token signature")
+	ErrUnknownKey       = errors.New("oidc: unknown signing key")
+	ErrExpired          = errors.New("oidc: token has expired")
+	ErrNotYetValid      = errors.New("oidc: token is not yet valid")
+	ErrInvalidIssuer    = errors.New("oidc: invalid token issuer")
+	ErrInvalidAudience  = errors.New("oidc: invalid token audience")
+)
+
+// clockSkew is the allowed difference between the clocks of the issuer and
+// the verifier when checking a token's expiry and not before times
+const clockSkew = time.Minute
+
+// keyRefreshInterval is the minimum time between fetching the issuer's keys
+// when a token is signed with an unknown key
+const keyRefreshInterval = time.Minute
+
+// httpClient is used to make requests to the issuer, with a timeout so that
+// an unresponsive issuer does not hold up token verification indefinitely
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// ProviderConfig is the configuration of an OpenID Connect provider, as
+// returned by its discovery endpoint
+type ProviderConfig struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Discover fetches the configuration of the OpenID Connect provider with the
+// given issuer URL
+func Discover(issuer string) (*ProviderConfig, error) {
+	url := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	res, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc: unexpected status %d from %s", res.StatusCode, url)
+	}
+	config := &ProviderConfig{}
+	if err := json.NewDecoder(res.Body).Decode(config); err != nil {
+		return nil, err
+	}
+	if config.Issuer != issuer {
+		return nil, fmt.Errorf("oidc: discovered issuer %q does not match %q", config.Issuer, issuer)
+	}
+	return config, nil
+}
+
+// Claims are the claims of a verified token
+type Claims map[string]interface{}
+
+// Subject returns the sub claim
+func (c Claims) Subject() string {
+	s, _ := c["sub"].(string)
+	return s
+}
+
+// Strings returns the values of a claim which is either a string or an
+// array of strings
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		s := make([]string, 0, len(v))
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				s = append(s, str)
+			}
+		}
+		return s
+	default:
+		return nil
+	}
+}
+
+// Time returns the value of a claim which is a number of seconds since the
+// Unix epoch
+func (c Claims) Time(name string) (time.Time, bool) {
+	n, ok := c[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(n), 0), true
+}
+
+// Verifier verifies tokens issued by an OpenID Connect provider
+type Verifier struct {
+	issuer    string
+	audiences []string
+	jwksURL   string
+
+	mtx         sync.Mutex
+	keys        map[string]crypto.PublicKey
+	refreshedAt time.Time
+	refresh     *keyRefresh
+}
+
+// keyRefresh is an in progress fetch of the issuer's keys, which concurrent
+// verifications of tokens signed with an unknown key wait for rather than
+// each fetching the keys
+type keyRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+// NewVerifier returns a Verifier which accepts tokens signed by a key from the
+// JSON Web Key Set at jwksURL with the given issuer and one of the given
+// audiences
+func NewVerifier(issuer string, audiences []string, jwksURL string) *Verifier {
+	return &Verifier{
+		issuer:    issuer,
+		audiences: audiences,
+		jwksURL:   jwksURL,
+	}
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// LooksLikeToken returns whether s has the structure of a JSON Web Token, and
+// is used to avoid verifying strings which are not tokens (e.g. API keys)
+func LooksLikeToken(s string) bool {
+	return strings.Count(s, ".") == 2 && strings.HasPrefix(s, "eyJ")
+}
+
+// Verify verifies the signature and claims of the given token, returning its
+// claims
+func (v *Verifier) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, ErrMalformedToken
+	}
+	hash, err := algHash(h.Alg)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	key, err := v.key(h.Kid)
+	if err != nil {
+		return nil, err
+	}
+	hasher := hash.New()
+	hasher.Write([]byte(parts[0] + "." + parts[1]))
+	if err := verifySignature(h.Alg, key, hash, hasher.Sum(nil), sig); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrMalformedToken
+	}
+	if iss, _ := claims["iss"].(string); iss != v.issuer {
+		return nil, ErrInvalidIssuer
+	}
+	if !v.validAudience(claims.Strings("aud")) {
+		return nil, ErrInvalidAudience
+	}
+	now := time.Now()
+	exp, ok := claims.Time("exp")
+	if !ok || now.After(exp.Add(clockSkew)) {
+		return nil, ErrExpired
+	}
+	if nbf, ok := claims.Time("nbf"); ok && now.Add(clockSkew).Before(nbf) {
+		return nil, ErrNotYetValid
+	}
+	return claims, nil
+}
+
+func (v *Verifier) validAudience(aud []string) bool {
+	for _, a := range aud {
+		for _, valid := range v.audiences {
+			if a == valid {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// key returns the public key with the given ID, fetching the issuer's keys if
+// the key is unknown (e.g. because the issuer has rotated its keys)
+func (v *Verifier) key(kid string) (crypto.PublicKey, error) {
+	v.mtx.Lock()
+	if key, ok := v.keys[kid]; ok {
+		v.mtx.Unlock()
+		return key, nil
+	}
+	refresh := v.refresh
+	if refresh == nil {
+		if time.Since(v.refreshedAt) < keyRefreshInterval {
+			v.mtx.Unlock()
+			return nil, ErrUnknownKey
+		}
+		refresh = &keyRefresh{done: make(chan struct{})}
+		v.refresh = refresh
+		v.mtx.Unlock()
+
+		// fetch the keys without holding the lock so that tokens
+		// signed with known keys can be verified in the meantime
+		keys, err := fetchKeys(v.jwksURL)
+
+		v.mtx.Lock()
+		if err == nil {
+			v.keys = keys
+			v.refreshedAt = time.Now()
+		}
+		refresh.err = err
+		v.refresh = nil
+		close(refresh.done)
+		v.mtx.Unlock()
+	} else {
+		v.mtx.Unlock()
+		<-refresh.done
+	}
+	if refresh.err != nil {
+		return nil, refresh.err
+	}
+
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	// tokens need not specify a key ID if the issuer only has one key
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, nil
+		}
+	}
+	return nil, ErrUnknownKey
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func algHash(alg string) (crypto.Hash, error) {
+	switch alg {
+	case "RS256", "ES256":
+		return crypto.SHA256, nil
+	case "RS384", "ES384":
+		return crypto.SHA384, nil
+	case "RS512", "ES512":
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("oidc: unsupported signing algorithm %q", alg)
+	}
+}
+
+func verifySignature(alg string, key crypto.PublicKey, hash crypto.Hash, hashed, sig []byte) error {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return ErrInvalidSignature
+		}
+		if rsa.VerifyPKCS1v15(k, hash, hashed, sig) != nil {
+			return ErrInvalidSignature
+		}
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return ErrInvalidSignature
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(k, hashed, r, s) {
+			return ErrInvalidSignature
+		}
+	default:
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// JSONWebKey is a public key in a JSON Web Key Set
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// EC keys
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JSONWebKeySet is a set of public keys, as served by an issuer's JWKS URI
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+func fetchKeys(url string) (map[string]crypto.PublicKey, error) {
+	res, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc: unexpected status %d from %s", res.StatusCode, url)
+	}
+	var set JSONWebKeySet
+	if err := json.NewDecoder(res.Body).Decode(&set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.PublicKey()
+		if err != nil {
+			// skip key types we don't support
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	return keys, nil
+}
+
+// PublicKey returns the RSA or ECDSA public key
+func (k *JSONWebKey) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("oidc: unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	default:
+		return nil, fmt.Errorf("oidc: unsupported key type %q", k.Kty)
+	}
+}
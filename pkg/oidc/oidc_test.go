@@ -0,0 +1,107 @@
+package oidc_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flynn/flynn/pkg/oidc"
+	"github.com/flynn/flynn/pkg/oidc/oidctest"
+)
+
+func TestVerify(t *testing.T) {
+	issuer, err := oidctest.NewIssuer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer issuer.Close()
+
+	config, err := oidc.Discover(issuer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := oidc.NewVerifier(issuer.URL, []string{"flynn"}, config.JWKSURI)
+
+	token := issuer.Sign("flynn", map[string]interface{}{
+		"sub":    "alice",
+		"groups": []string{"admins", "developers"},
+	}, time.Hour)
+	if !oidc.LooksLikeToken(token) {
+		t.Fatal("expected token to look like a token")
+	}
+	claims, err := verifier.Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub := claims.Subject(); sub != "alice" {
+		t.Fatalf("expected sub to be alice, got %q", sub)
+	}
+	if groups := claims.Strings("groups"); len(groups) != 2 || groups[0] != "admins" {
+		t.Fatalf("unexpected groups claim: %v", groups)
+	}
+
+	// tampering with the claims should invalidate the signature
+	parts := strings.Split(token, ".")
+	other := strings.Split(issuer.Sign("flynn", map[string]interface{}{"sub": "mallory"}, time.Hour), ".")
+	if _, err := verifier.Verify(parts[0] + "." + other[1] + "." + parts[2]); err != oidc.ErrInvalidSignature {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	for _, test := range []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"wrong audience", issuer.Sign("other", nil, time.Hour), oidc.ErrInvalidAudience},
+		{"expired", issuer.Sign("flynn", nil, -time.Hour), oidc.ErrExpired},
+		{"malformed", "not-a-token", oidc.ErrMalformedToken},
+	} {
+		if _, err := verifier.Verify(test.token); err != test.err {
+			t.Fatalf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+
+	// a verifier for a different issuer should reject the token
+	verifier = oidc.NewVerifier("https://other.example.com", []string{"flynn"}, config.JWKSURI)
+	if _, err := verifier.Verify(token); err != oidc.ErrInvalidIssuer {
+		t.Fatalf("expected ErrInvalidIssuer, got %v", err)
+	}
+}
+
+func TestVerifyConcurrentKeyRefresh(t *testing.T) {
+	issuer, err := oidctest.NewIssuer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer issuer.Close()
+
+	config, err := oidc.Discover(issuer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := oidc.NewVerifier(issuer.URL, []string{"flynn"}, config.JWKSURI)
+	token := issuer.Sign("flynn", map[string]interface{}{"sub": "alice"}, time.Hour)
+
+	// concurrent verifications before the keys are known should share a
+	// single fetch of the keys
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := verifier.Verify(token); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	if n := issuer.KeyRequests(); n != 1 {
+		t.Fatalf("expected keys to be fetched once, got %d", n)
+	}
+}
@@ -0,0 +1,168 @@
+// Package oidctest provides a local OpenID Connect issuer for testing.
+package oidctest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/flynn/flynn/pkg/oidc"
+	"github.com/flynn/flynn/pkg/random"
+)
+
+const keyID = "test-key"
+
+// Issuer is an OpenID Connect issuer which signs tokens with a generated RSA
+// key and implements the authorization code flow, automatically authorizing
+// the user set with SetUser
+type Issuer struct {
+	*httptest.Server
+
+	key *rsa.PrivateKey
+
+	mtx         sync.Mutex
+	claims      map[string]interface{}
+	codes       map[string]string
+	keyRequests int
+}
+
+// NewIssuer starts a new Issuer, which should be closed when no longer needed
+func NewIssuer() (*Issuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	i := &Issuer{
+		key:    key,
+		claims: map[string]interface{}{"sub": "test-user"},
+		codes:  make(map[string]string),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", i.serveConfig)
+	mux.HandleFunc("/keys", i.serveKeys)
+	mux.HandleFunc("/authorize", i.serveAuthorize)
+	mux.HandleFunc("/token", i.serveToken)
+	i.Server = httptest.NewServer(mux)
+	return i, nil
+}
+
+// SetUser sets the claims (in addition to the standard claims) of the ID
+// tokens issued by the authorization code flow
+func (i *Issuer) SetUser(claims map[string]interface{}) {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	i.claims = claims
+}
+
+// Sign returns an ID token for the given audience with the given claims in
+// addition to the standard claims, which expires after ttl
+func (i *Issuer) Sign(audience string, claims map[string]interface{}, ttl time.Duration) string {
+	now := time.Now()
+	payload := map[string]interface{}{
+		"iss": i.URL,
+		"aud": audience,
+		"iat": now.Unix(),
+		"exp": now.Add(ttl).Unix(),
+	}
+	for k, v := range claims {
+		payload[k] = v
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": keyID, "typ": "JWT"})
+	data, _ := json.Marshal(payload)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(data)
+	hashed := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, i.key, crypto.SHA256, hashed[:])
+	if err != nil {
+		panic(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func (i *Issuer) serveConfig(w http.ResponseWriter, req *http.Request) {
+	json.NewEncoder(w).Encode(&oidc.ProviderConfig{
+		Issuer:                i.URL,
+		AuthorizationEndpoint: i.URL + "/authorize",
+		TokenEndpoint:         i.URL + "/token",
+		JWKSURI:               i.URL + "/keys",
+	})
+}
+
+// KeyRequests returns the number of times the issuer's keys have been fetched
+func (i *Issuer) KeyRequests() int {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	return i.keyRequests
+}
+
+func (i *Issuer) serveKeys(w http.ResponseWriter, req *http.Request) {
+	i.mtx.Lock()
+	i.keyRequests++
+	i.mtx.Unlock()
+	json.NewEncoder(w).Encode(&oidc.JSONWebKeySet{Keys: []oidc.JSONWebKey{{
+		Kty: "RSA",
+		Kid: keyID,
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(i.key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(i.key.E)).Bytes()),
+	}}})
+}
+
+// serveAuthorize immediately redirects back to the client with a code which
+// can be exchanged for an ID token for the current user
+func (i *Issuer) serveAuthorize(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || q.Get("response_type") != "code" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	code := random.Hex(16)
+	i.mtx.Lock()
+	i.codes[code] = q.Get("client_id")
+	i.mtx.Unlock()
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, req, redirect.String(), http.StatusFound)
+}
+
+func (i *Issuer) serveToken(w http.ResponseWriter, req *http.Request) {
+	if err := req.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	clientID := req.PostForm.Get("client_id")
+	if id, _, ok := req.BasicAuth(); ok {
+		clientID = id
+	}
+	code := req.PostForm.Get("code")
+	i.mtx.Lock()
+	codeClientID, ok := i.codes[code]
+	delete(i.codes, code)
+	claims := i.claims
+	i.mtx.Unlock()
+	if !ok || codeClientID != clientID {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": random.Hex(16),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     i.Sign(clientID, claims, time.Hour),
+	})
+}
@@ -25,6 +25,7 @@ func apiHandler(rtr *Router) http.Handler {
 	r.HandlerFunc("GET", status.Path, status.HealthyHandler.ServeHTTP)
 
 	r.GET("/events", httphelper.WrapHandler(api.StreamEvents))
+	r.GET("/backends/stats", httphelper.WrapHandler(api.GetBackendStats))
 
 	r.HandlerFunc("GET", "/debug/*path", pprof.Handler.ServeHTTP)
 
@@ -72,3 +73,9 @@ func (api *API) StreamEvents(ctx context.Context, w http.ResponseWriter, req *ht
 	go sendEvents(tcpEvents)
 	sse.ServeStream(w, sseEvents, log)
 }
+
+func (api *API) GetBackendStats(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	stats := api.router.HTTP.BackendStats()
+	stats = append(stats, api.router.TCP.BackendStats()...)
+	httphelper.JSON(w, 200, stats)
+}
@@ -52,6 +52,9 @@ func NewWithAddr(addr string) Client {
 type Client interface {
 	// StreamEvents streams router events with the given options
 	StreamEvents(opts *router.StreamEventsOptions, output chan *router.StreamEvent) (stream.Stream, error)
+
+	// BackendStats returns request statistics for the router's backends
+	BackendStats() ([]*router.BackendStats, error)
 }
 
 func (c *client) StreamEvents(opts *router.StreamEventsOptions, output chan *router.StreamEvent) (stream.Stream, error) {
@@ -66,3 +69,8 @@ func (c *client) StreamEvents(opts *router.StreamEventsOptions, output chan *rou
 	}
 	return c.ResumingStream("GET", "/events?types="+strings.Join(types, ","), output)
 }
+
+func (c *client) BackendStats() ([]*router.BackendStats, error) {
+	var stats []*router.BackendStats
+	return stats, c.Get("/backends/stats", &stats)
+}
@@ -63,6 +63,16 @@ type DiscoverdClient interface {
 	AddService(string, *discoverd.ServiceConfig) error
 }
 
+func (s *HTTPListener) BackendStats() []*router.BackendStats {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	var stats []*router.BackendStats
+	for _, service := range s.services {
+		stats = append(stats, service.BackendStats()...)
+	}
+	return stats
+}
+
 func (s *HTTPListener) Close() error {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
@@ -224,15 +234,18 @@ func (h *httpSyncHandler) Set(data *router.Route) error {
 		return nil
 	}
 
-	service := h.l.services[r.Service]
-	if service != nil && service.name != r.Service {
-		service.refs--
-		if service.refs <= 0 {
-			service.Close()
-			delete(h.l.services, service.name)
+	// release the service of any route being replaced, which may
+	// reference a different service (e.g. when a blue-green deployment
+	// switches the route to the new release's service)
+	if old, ok := h.l.routes[data.ID]; ok {
+		old.service.refs--
+		if old.service.refs <= 0 && old.service.name != r.Service {
+			old.service.Close()
+			delete(h.l.services, old.service.name)
 		}
-		service = nil
 	}
+
+	service := h.l.services[r.Service]
 	if service == nil {
 		sc, err := cache.New(h.l.discoverd.Service(r.Service))
 		if err != nil {
@@ -249,6 +262,9 @@ func (h *httpSyncHandler) Set(data *router.Route) error {
 	} else {
 		bf = backendFunc(r.Service, service.sc.Instances)
 	}
+	if r.CanaryRelease != "" {
+		bf = canaryBackendFunc(bf, r.CanaryRelease, r.CanaryWeight)
+	}
 	r.rp = proxy.NewReverseProxy(proxy.ReverseProxyConfig{
 		BackendListFunc:   bf,
 		StickyKey:         h.l.cookieKey,
@@ -489,13 +505,23 @@ type service struct {
 	stream stream.Stream
 	reqs   map[string]int64
 	cond   *sync.Cond
+
+	// requests is the total number of requests proxied to each backend
+	// address, used to determine request rates when autoscaling, and
+	// errors is the number of those requests which got a 5xx response,
+	// used to decide whether to roll back deployments
+	requests    map[string]uint64
+	errors      map[string]uint64
+	requestsMtx sync.Mutex
 }
 
 func newService(name string, sc *cache.ServiceCache, wm *WatchManager, trackBackends bool) *service {
 	s := &service{
-		name: name,
-		sc:   sc,
-		wm:   wm,
+		name:     name,
+		sc:       sc,
+		wm:       wm,
+		requests: make(map[string]uint64),
+		errors:   make(map[string]uint64),
 	}
 	if trackBackends {
 		events := make(chan *discoverd.Event)
@@ -508,6 +534,10 @@ func newService(name string, sc *cache.ServiceCache, wm *WatchManager, trackBack
 }
 
 func (s *service) TrackRequestStart(backend string) {
+	s.requestsMtx.Lock()
+	s.requests[backend]++
+	s.requestsMtx.Unlock()
+
 	if s.reqs == nil {
 		return
 	}
@@ -528,6 +558,56 @@ func (s *service) TrackRequestDone(backend string) {
 	s.cond.L.Unlock()
 }
 
+func (s *service) TrackResponse(backend string, status int) {
+	if status < 500 {
+		return
+	}
+	s.requestsMtx.Lock()
+	s.errors[backend]++
+	s.requestsMtx.Unlock()
+}
+
+// BackendStats returns the request statistics of the service's current
+// backends
+func (s *service) BackendStats() []*router.BackendStats {
+	instances := s.sc.Instances()
+	stats := make([]*router.BackendStats, 0, len(instances))
+
+	s.requestsMtx.Lock()
+	current := make(map[string]struct{}, len(instances))
+	for _, inst := range instances {
+		current[inst.Addr] = struct{}{}
+		stats = append(stats, &router.BackendStats{
+			Backend: router.Backend{
+				Service:   s.name,
+				Addr:      inst.Addr,
+				App:       inst.Meta["FLYNN_APP_NAME"],
+				JobID:     inst.Meta["FLYNN_JOB_ID"],
+				ReleaseID: inst.Meta["FLYNN_RELEASE_ID"],
+			},
+			Requests: s.requests[inst.Addr],
+			Errors:   s.errors[inst.Addr],
+		})
+	}
+	// forget backends which have gone away
+	for addr := range s.requests {
+		if _, ok := current[addr]; !ok {
+			delete(s.requests, addr)
+			delete(s.errors, addr)
+		}
+	}
+	s.requestsMtx.Unlock()
+
+	if s.reqs != nil {
+		s.cond.L.Lock()
+		for _, stat := range stats {
+			stat.InFlight = s.reqs[stat.Addr]
+		}
+		s.cond.L.Unlock()
+	}
+	return stats
+}
+
 func (s *service) Close() {
 	if s.stream != nil {
 		s.stream.Close()
@@ -612,8 +692,35 @@ func backendFunc(service string, f func() []*discoverd.Instance) proxy.BackendLi
 				Addr:    inst.Addr,
 				App:     inst.Meta["FLYNN_APP_NAME"],
 				JobID:   inst.Meta["FLYNN_JOB_ID"],
+
+				ReleaseID: inst.Meta["FLYNN_RELEASE_ID"],
 			}
 		}
 		return backends
 	}
 }
+
+// canaryBackendFunc wraps the given BackendListFunc so that it returns the
+// backends of the given canary release for the given percentage of calls (it
+// is called once per request), and the other backends for the rest, returning
+// all backends if either set is empty
+func canaryBackendFunc(f proxy.BackendListFunc, releaseID string, weight int) proxy.BackendListFunc {
+	return func() []*router.Backend {
+		backends := f()
+		var canary, stable []*router.Backend
+		for _, b := range backends {
+			if b.ReleaseID == releaseID {
+				canary = append(canary, b)
+			} else {
+				stable = append(stable, b)
+			}
+		}
+		if len(canary) == 0 || len(stable) == 0 {
+			return backends
+		}
+		if random.Math.Intn(100) < weight {
+			return canary
+		}
+		return stable
+	}
+}
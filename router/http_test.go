@@ -184,6 +184,56 @@ func (s *S) TestAddHTTPRoute(c *C) {
 	res.Body.Close()
 }
 
+func (s *S) TestHTTPBackendStats(c *C) {
+	srv := httptest.NewServer(httpTestHandler("1"))
+	defer srv.Close()
+
+	l := s.newHTTPListener(c)
+	defer l.Close()
+
+	s.addHTTPRoute(c, l)
+	discoverdRegisterHTTP(c, l, srv.Listener.Addr().String())
+
+	for i := 0; i < 3; i++ {
+		assertGet(c, "http://"+l.Addrs[0], "example.com", "1")
+	}
+
+	stats := l.BackendStats()
+	c.Assert(stats, HasLen, 1)
+	c.Assert(stats[0].Service, Equals, "test")
+	c.Assert(stats[0].Addr, Equals, srv.Listener.Addr().String())
+	c.Assert(stats[0].Requests, Equals, uint64(3))
+	c.Assert(stats[0].Errors, Equals, uint64(0))
+}
+
+func (s *S) TestHTTPBackendErrorStats(c *C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	l := s.newHTTPListener(c)
+	defer l.Close()
+
+	s.addHTTPRoute(c, l)
+	discoverdRegisterHTTP(c, l, srv.Listener.Addr().String())
+
+	client := newHTTPClient("example.com")
+	for _, path := range []string{"/", "/fail", "/fail"} {
+		req := newReq("http://"+l.Addrs[0]+path, "example.com")
+		res, err := client.Do(req)
+		c.Assert(err, IsNil)
+		res.Body.Close()
+	}
+
+	stats := l.BackendStats()
+	c.Assert(stats, HasLen, 1)
+	c.Assert(stats[0].Requests, Equals, uint64(3))
+	c.Assert(stats[0].Errors, Equals, uint64(2))
+}
+
 func (s *S) TestAddHTTPRouteWithCert(c *C) {
 	srv1 := httptest.NewServer(httpTestHandler("1"))
 	defer srv1.Close()
@@ -275,6 +325,67 @@ func (s *S) TestUpdateCert(c *C) {
 	assertGet(c, "https://"+l.TLSAddrs[0], domain, "1")
 }
 
+// TestHTTPRouteServiceSwitch tests switching routes between services (as
+// blue-green deployments do), checking the old service is only closed once
+// no routes reference it
+func (s *S) TestHTTPRouteServiceSwitch(c *C) {
+	blue := httptest.NewServer(httpTestHandler("blue"))
+	defer blue.Close()
+	green := httptest.NewServer(httpTestHandler("green"))
+	defer green.Close()
+
+	l := s.newHTTPListener(c)
+	defer l.Close()
+
+	getService := func(name string) *service {
+		l.mtx.RLock()
+		defer l.mtx.RUnlock()
+		return l.services[name]
+	}
+	updateService := func(r *router.Route, name string) *router.Route {
+		updated := *r
+		updated.Service = name
+		wait := waitForEvent(c, l, "set", "")
+		s.store.update(&updated)
+		wait()
+		return &updated
+	}
+
+	// add two routes to the blue service
+	r1 := s.addRoute(c, l, router.HTTPRoute{Domain: "one.example.com", Service: "blue"}.ToRoute())
+	r2 := s.addRoute(c, l, router.HTTPRoute{Domain: "two.example.com", Service: "blue"}.ToRoute())
+	blueService := getService("blue")
+	c.Assert(blueService, NotNil)
+	c.Assert(blueService.refs, Equals, 2)
+	discoverdRegisterHTTPService(c, l, "blue", blue.Listener.Addr().String())
+	assertGet(c, "http://"+l.Addrs[0], "one.example.com", "blue")
+	assertGet(c, "http://"+l.Addrs[0], "two.example.com", "blue")
+
+	// switching one route keeps the blue service open for the other
+	r1 = updateService(r1, "green")
+	c.Assert(getService("blue"), Equals, blueService)
+	c.Assert(blueService.refs, Equals, 1)
+	greenService := getService("green")
+	c.Assert(greenService, NotNil)
+	c.Assert(greenService.refs, Equals, 1)
+	discoverdRegisterHTTPService(c, l, "green", green.Listener.Addr().String())
+	assertGet(c, "http://"+l.Addrs[0], "one.example.com", "green")
+	assertGet(c, "http://"+l.Addrs[0], "two.example.com", "blue")
+
+	// updating a route without changing its service keeps the service
+	r1.Sticky = true
+	r1 = updateService(r1, "green")
+	c.Assert(getService("green"), Equals, greenService)
+	c.Assert(greenService.refs, Equals, 1)
+
+	// switching the other route closes the unreferenced blue service
+	updateService(r2, "green")
+	c.Assert(getService("blue"), IsNil)
+	c.Assert(greenService.refs, Equals, 2)
+	assertGet(c, "http://"+l.Addrs[0], "one.example.com", "green")
+	assertGet(c, "http://"+l.Addrs[0], "two.example.com", "green")
+}
+
 func newReq(url, host string) *http.Request {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -1802,3 +1913,48 @@ func (s *S) TestHTTPLoadBalance(c *C) {
 		c.Assert(string(body), Not(Equals), backendID)
 	}
 }
+
+func (s *S) TestCanaryBackendFunc(c *C) {
+	stable := []*router.Backend{
+		{Addr: "10.0.0.1:80", ReleaseID: "old"},
+		{Addr: "10.0.0.2:80", ReleaseID: "old"},
+	}
+	canary := []*router.Backend{
+		{Addr: "10.0.0.3:80", ReleaseID: "new"},
+	}
+	backends := func(b ...[]*router.Backend) proxy.BackendListFunc {
+		var all []*router.Backend
+		for _, list := range b {
+			all = append(all, list...)
+		}
+		return func() []*router.Backend { return all }
+	}
+	countCanary := func(f proxy.BackendListFunc, n int) int {
+		count := 0
+		for i := 0; i < n; i++ {
+			list := f()
+			c.Assert(list, Not(HasLen), 0)
+			if list[0].ReleaseID == "new" {
+				c.Assert(list, DeepEquals, canary)
+				count++
+			} else {
+				c.Assert(list, DeepEquals, stable)
+			}
+		}
+		return count
+	}
+
+	// requests are split between the canary and stable backends by weight
+	c.Assert(countCanary(canaryBackendFunc(backends(stable, canary), "new", 0), 1000), Equals, 0)
+	c.Assert(countCanary(canaryBackendFunc(backends(stable, canary), "new", 100), 1000), Equals, 1000)
+	n := countCanary(canaryBackendFunc(backends(stable, canary), "new", 30), 1000)
+	c.Assert(n > 200 && n < 400, Equals, true, Commentf("got %d canary requests, expected around 300", n))
+
+	// all backends are returned if either side has no backends
+	f := canaryBackendFunc(backends(stable), "new", 100)
+	c.Assert(f(), DeepEquals, stable)
+	f = canaryBackendFunc(backends(canary), "new", 0)
+	c.Assert(f(), DeepEquals, canary)
+	f = canaryBackendFunc(backends(), "new", 50)
+	c.Assert(f(), HasLen, 0)
+}
@@ -77,6 +77,12 @@ type RequestTracker interface {
 	TrackRequestDone(backend string)
 }
 
+// ResponseTracker can be implemented by a RequestTracker to also track the
+// status codes of HTTP responses from backends
+type ResponseTracker interface {
+	TrackResponse(backend string, status int)
+}
+
 // NewReverseProxy initializes a new ReverseProxy with a callback to get
 // backends, a stickyKey for encrypting sticky session cookies, and a flag
 // sticky to enable sticky sessions.
@@ -119,6 +125,9 @@ func (p *ReverseProxy) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	defer res.Body.Close()
 	defer p.RequestTracker.TrackRequestDone(trace.Backend.Addr)
 	defer transport.trackRequestEnd(trace.Backend)
+	if rt, ok := p.RequestTracker.(ResponseTracker); ok {
+		rt.TrackResponse(trace.Backend.Addr, res.StatusCode)
+	}
 
 	prepareResponseHeaders(res)
 	p.writeResponse(rw, res)
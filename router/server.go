@@ -18,6 +18,7 @@ import (
 	discoverd "github.com/flynn/flynn/discoverd/client"
 	"github.com/flynn/flynn/pkg/keepalive"
 	"github.com/flynn/flynn/pkg/shutdown"
+	router "github.com/flynn/flynn/router/types"
 	"github.com/inconshreveable/log15"
 )
 
@@ -34,6 +35,10 @@ type Listener interface {
 	Start() error
 	Close() error
 	Watcher
+
+	// BackendStats returns request statistics for the listener's
+	// backends
+	BackendStats() []*router.BackendStats
 }
 
 type Router struct {
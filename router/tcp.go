@@ -119,6 +119,16 @@ func (l *TCPListener) doSync(ctx context.Context, errc chan<- error) <-chan stru
 	return startc
 }
 
+func (l *TCPListener) BackendStats() []*router.BackendStats {
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+	var stats []*router.BackendStats
+	for _, service := range l.services {
+		stats = append(stats, service.BackendStats()...)
+	}
+	return stats
+}
+
 func (l *TCPListener) Close() error {
 	l.mtx.Lock()
 	defer l.mtx.Unlock()
@@ -165,14 +175,6 @@ func (h *tcpSyncHandler) Set(data *router.Route) error {
 	}
 
 	service := h.l.services[r.Service]
-	if service != nil && service.name != r.Service {
-		service.refs--
-		if service.refs <= 0 {
-			service.Close()
-			delete(h.l.services, service.name)
-		}
-		service = nil
-	}
 	if service == nil {
 		sc, err := cache.New(h.l.discoverd.Service(r.Service))
 		if err != nil {
@@ -194,18 +196,45 @@ func (h *tcpSyncHandler) Set(data *router.Route) error {
 		RequestTracker:  service,
 		Logger:          logger,
 	})
-	if listener, ok := h.l.listeners[r.Port]; ok {
-		r.l = listener
-		delete(h.l.listeners, r.Port)
+
+	// keep serving any route being replaced until the new route has
+	// started so that its port keeps serving if the new route fails to
+	// start, unless they share a port, in which case the old route hands
+	// its listener over to the new one and is restarted on failure
+	old, replacing := h.l.routes[data.ID]
+	samePort := replacing && old.Port == r.Port
+	if samePort {
+		old.Close()
 	}
-	started := make(chan error)
-	go r.Serve(started)
-	if err := <-started; err != nil {
-		if r.l != nil {
-			h.l.listeners[r.Port] = r.l
+	if err := r.start(); err != nil {
+		if samePort {
+			if err := old.start(); err != nil {
+				logger.Error("error restoring tcp route", "route.id", data.ID, "err", err)
+				delete(h.l.routes, data.ID)
+				delete(h.l.ports, old.Port)
+			}
+		}
+		if service.refs <= 0 {
+			service.Close()
+			delete(h.l.services, service.name)
 		}
 		return err
 	}
+
+	// release the replaced route's service, which may be different to
+	// the new one (e.g. when a blue-green deployment switches the route
+	// to the new release's service)
+	if replacing {
+		if !samePort {
+			old.Close()
+			delete(h.l.ports, old.Port)
+		}
+		old.service.refs--
+		if old.service.refs <= 0 && old.service != service {
+			old.service.Close()
+			delete(h.l.services, old.service.name)
+		}
+	}
 	service.refs++
 	h.l.routes[data.ID] = r
 	h.l.ports[r.Port] = r
@@ -247,6 +276,22 @@ type tcpRoute struct {
 	rp      *proxy.ReverseProxy
 }
 
+// start starts serving the route, using the listener left by a previous
+// route on the same port if there is one
+func (r *tcpRoute) start() error {
+	r.l = r.parent.listeners[r.Port]
+	delete(r.parent.listeners, r.Port)
+	started := make(chan error)
+	go r.Serve(started)
+	if err := <-started; err != nil {
+		if r.l != nil {
+			r.parent.listeners[r.Port] = r.l
+		}
+		return err
+	}
+	return nil
+}
+
 func (r *tcpRoute) Serve(started chan<- error) {
 	var err error
 	// TODO: close the listener while there are no backends available
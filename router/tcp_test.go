@@ -105,6 +105,33 @@ func (s *S) TestAddTCPRoute(c *C) {
 	c.Assert(err, Not(IsNil))
 }
 
+func (s *S) TestUpdateTCPRouteFailure(c *C) {
+	portInt := allocatePort()
+	addr := "127.0.0.1:" + strconv.Itoa(portInt)
+
+	srv := NewTCPTestServer("1")
+	defer srv.Close()
+
+	l := s.newTCPListener(c)
+	defer l.Close()
+
+	r := s.addTCPRoute(c, l, portInt)
+	discoverdRegisterTCP(c, l, srv.Addr)
+	assertTCPConn(c, addr, "1")
+
+	// moving the route to a port which is in use fails, but leaves the
+	// route serving on its original port
+	inUse, err := net.Listen("tcp4", "127.0.0.1:0")
+	c.Assert(err, IsNil)
+	defer inUse.Close()
+	updated := *r
+	updated.Port = inUse.Addr().(*net.TCPAddr).Port
+	h := &tcpSyncHandler{l: l}
+	c.Assert(h.Set(updated.ToRoute()), NotNil)
+	assertTCPConn(c, addr, "1")
+	c.Assert(l.routes[r.ID].Port, Equals, portInt)
+}
+
 func (s *S) addTCPRoute(c *C, l *TCPListener, port int) *router.TCPRoute {
 	wait := waitForEvent(c, l, "set", "")
 	r := router.TCPRoute{
@@ -70,6 +70,13 @@ type Route struct {
 	// DisableKeepAlives when set will disable keep-alives between the
 	// router and backends for this route
 	DisableKeepAlives bool `json:"disable_keep_alives,omitempty"`
+
+	// CanaryRelease and CanaryWeight, when set, cause CanaryWeight
+	// percent of requests to be sent to the backends of the
+	// CanaryRelease release and the rest to other backends (used by
+	// canary deployments). They are only used for HTTP routes.
+	CanaryRelease string `json:"canary_release,omitempty"`
+	CanaryWeight  int    `json:"canary_weight,omitempty"`
 }
 
 func (r Route) FormattedID() string {
@@ -94,6 +101,8 @@ func (r Route) HTTPRoute() *HTTPRoute {
 		Sticky:            r.Sticky,
 		Path:              r.Path,
 		DisableKeepAlives: r.DisableKeepAlives,
+		CanaryRelease:     r.CanaryRelease,
+		CanaryWeight:      r.CanaryWeight,
 	}
 }
 
@@ -128,6 +137,8 @@ type HTTPRoute struct {
 	Sticky            bool
 	Path              string
 	DisableKeepAlives bool
+	CanaryRelease     string
+	CanaryWeight      int
 }
 
 func (r HTTPRoute) FormattedID() string {
@@ -159,6 +170,8 @@ func (r HTTPRoute) ToRoute() *Route {
 		Sticky:            r.Sticky,
 		Path:              r.Path,
 		DisableKeepAlives: r.DisableKeepAlives,
+		CanaryRelease:     r.CanaryRelease,
+		CanaryWeight:      r.CanaryWeight,
 	}
 }
 
@@ -219,6 +232,29 @@ type Backend struct {
 	Addr    string `json:"addr"`
 	App     string `json:"app"`
 	JobID   string `json:"job_id"`
+
+	// ReleaseID is the ID of the release the backend's job is running,
+	// used to route requests to canary releases
+	ReleaseID string `json:"release_id,omitempty"`
+}
+
+// BackendStats is a snapshot of the requests proxied to a backend by a router
+// instance
+type BackendStats struct {
+	Backend
+
+	// Requests is the total number of requests (or connections for TCP
+	// routes) proxied to the backend since the router started tracking
+	// it, which can be sampled periodically to determine request rates
+	Requests uint64 `json:"requests"`
+
+	// Errors is the number of HTTP requests proxied to the backend which
+	// got a 5xx response, used to detect failing deployments
+	Errors uint64 `json:"errors"`
+
+	// InFlight is the number of requests currently being proxied to the
+	// backend (only tracked for routes which drain backends)
+	InFlight int64 `json:"in_flight,omitempty"`
 }
 
 type StreamEvent struct {
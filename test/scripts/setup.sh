@@ -67,7 +67,7 @@ main() {
   set_app_release "${app_id}" "${release_id}" >/dev/null
 
   info "adding PostgreSQL database"
-  flynn -a "${app}" resource add postgres
+  flynn -a "${app}" resource add --unencrypted postgres
 
   local memory="${FLYNN_CI_MEMORY:-${DEFAULT_MEMORY}}"
   info "setting ${memory} memory limit"
@@ -140,14 +140,14 @@ func (s *BackupSuite) testClusterBackupWithFn(t *c.C, name string, fn func(*c.C,
 	if _, ok := release.Env["FLYNN_MYSQL"]; ok {
 		t.Assert(flynn("mysql", "console", "--", "-e", "SELECT * FROM foos"), SuccessfulOutputContains, "foobar")
 	} else {
-		t.Assert(flynn("resource", "add", "mysql"), Succeeds)
+		t.Assert(flynn("resource", "add", "--unencrypted", "mysql"), Succeeds)
 	}
 
 	debug(t, "checking mongodb resource")
 	if _, ok := release.Env["FLYNN_MONGO"]; ok {
 		t.Assert(flynn("mongodb", "mongo", "--", "--eval", "db.foos.find()"), SuccessfulOutputContains, "foobar")
 	} else {
-		t.Assert(flynn("resource", "add", "mongodb"), Succeeds)
+		t.Assert(flynn("resource", "add", "--unencrypted", "mongodb"), Succeeds)
 	}
 
 	debug(t, "checking dashboard STATUS_KEY matches status AUTH_KEY")
@@ -595,7 +595,7 @@ func (s *CLISuite) TestResource(t *c.C) {
 	app := s.newCliTestApp(t)
 	defer app.cleanup()
 	matchExp := fmt.Sprintf("Created resource %s and release %s.", UUIDRegex, UUIDRegex)
-	t.Assert(app.flynn("resource", "add", "postgres").Output, Matches, matchExp)
+	t.Assert(app.flynn("resource", "add", "--unencrypted", "postgres").Output, Matches, matchExp)
 
 	res, err := s.controllerClient(t).AppResourceList(app.name)
 	t.Assert(err, c.IsNil)
@@ -610,7 +610,7 @@ func (s *CLISuite) TestResource(t *c.C) {
 func (s *CLISuite) TestResourceList(t *c.C) {
 	app := s.newCliTestApp(t)
 	defer app.cleanup()
-	t.Assert(app.flynn("resource", "add", "postgres"), Succeeds)
+	t.Assert(app.flynn("resource", "add", "--unencrypted", "postgres"), Succeeds)
 	t.Assert(app.flynn("resource").Output, Matches, `postgres`)
 }
 
@@ -618,7 +618,7 @@ func (s *CLISuite) TestResourceRemove(t *c.C) {
 	app := s.newCliTestApp(t)
 	defer app.cleanup()
 
-	add := app.flynn("resource", "add", "postgres")
+	add := app.flynn("resource", "add", "--unencrypted", "postgres")
 	t.Assert(add, Succeeds)
 	t.Assert(app.flynn("resource").Output, Matches, "postgres")
 	t.Assert(app.flynn("env").Output, Matches, "FLYNN_POSTGRES")
@@ -979,10 +979,10 @@ func (s *CLISuite) TestExportImport(t *c.C) {
 
 	// release the app and provision some dbs
 	t.Assert(r.git("push", "flynn", "master"), Succeeds)
-	t.Assert(r.flynn("resource", "add", "postgres"), Succeeds)
+	t.Assert(r.flynn("resource", "add", "--unencrypted", "postgres"), Succeeds)
 	t.Assert(r.flynn("pg", "psql", "--", "-c",
 		"CREATE table foos (data text); INSERT INTO foos (data) VALUES ('foobar')"), Succeeds)
-	t.Assert(r.flynn("resource", "add", "mysql"), Succeeds)
+	t.Assert(r.flynn("resource", "add", "--unencrypted", "mysql"), Succeeds)
 	t.Assert(r.flynn("mysql", "console", "--", "-e",
 		"CREATE TABLE foos (data TEXT); INSERT INTO foos (data) VALUES ('foobar')"), Succeeds)
 
@@ -372,7 +372,7 @@ func (s *ControllerSuite) TestAppDeleteCleanup(t *c.C) {
 	}
 
 	// provision resources
-	t.Assert(r.flynn("resource", "add", "postgres"), Succeeds)
+	t.Assert(r.flynn("resource", "add", "--unencrypted", "postgres"), Succeeds)
 	resources, err := client.AppResourceList(app)
 	t.Assert(err, c.IsNil)
 	numResources := 1
@@ -423,7 +423,7 @@ func (s *ControllerSuite) TestResourceProvisionRecreatedApp(t *c.C) {
 	t.Assert(r.flynn("create", app), Succeeds)
 
 	// provision resource
-	t.Assert(r.flynn("resource", "add", "postgres"), Succeeds)
+	t.Assert(r.flynn("resource", "add", "--unencrypted", "postgres"), Succeeds)
 	resources, err := client.AppResourceList(app)
 	t.Assert(err, c.IsNil)
 	t.Assert(resources, c.HasLen, 1)
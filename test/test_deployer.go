@@ -7,7 +7,9 @@ import (
 	discoverd "github.com/flynn/flynn/discoverd/client"
 	host "github.com/flynn/flynn/host/types"
 	"github.com/flynn/flynn/pkg/attempt"
+	"github.com/flynn/flynn/pkg/random"
 	"github.com/flynn/flynn/pkg/stream"
+	router "github.com/flynn/flynn/router/types"
 	c "github.com/flynn/go-check"
 )
 
@@ -129,8 +131,9 @@ func (s *DeployerSuite) waitForDeploymentStatus(t *c.C, events chan *ct.Deployme
 	for {
 		select {
 		case event := <-events:
-			// ignore pending status
-			if event.Status == "pending" {
+			// ignore pending status and the events of individual
+			// deployment steps
+			if event.Status == "pending" || event.Step != "" {
 				continue
 			}
 			if event.Status != status {
@@ -144,6 +147,28 @@ func (s *DeployerSuite) waitForDeploymentStatus(t *c.C, events chan *ct.Deployme
 	return nil
 }
 
+func (t *testDeploy) waitForDeploymentStep(step ct.DeploymentStep) *ct.DeploymentEvent {
+	return t.s.waitForDeploymentStep(t.t, t.deployEvents, step)
+}
+
+func (s *DeployerSuite) waitForDeploymentStep(t *c.C, events chan *ct.DeploymentEvent, step ct.DeploymentStep) *ct.DeploymentEvent {
+	debugf(t, "waiting for deploy %s event", step)
+	for {
+		select {
+		case event := <-events:
+			if event.Status == "failed" {
+				t.Fatalf("expected deploy %s event, got failed event: %s", step, event.Error)
+			}
+			if event.Step == step {
+				return event
+			}
+		case <-time.After(60 * time.Second):
+			t.Fatalf("timed out waiting for deploy %s event", step)
+		}
+	}
+	return nil
+}
+
 func (t *testDeploy) waitForJobEvents(typ string, expected []*ct.Job) {
 	t.s.waitForJobEvents(t.t, typ, t.jobEvents, expected)
 }
@@ -321,6 +346,329 @@ func (s *DeployerSuite) assertRolledBack(t *c.C, deployment *ct.Deployment, proc
 	t.Assert(err, c.NotNil)
 }
 
+// createCanaryRelease creates a running release of two echoer jobs using the
+// canary strategy with the given configuration, along with an HTTP route to
+// their service
+func (s *DeployerSuite) createCanaryRelease(t *c.C, config *ct.CanaryConfig) (*ct.App, *ct.Release, *router.Route) {
+	app, release := s.createRelease(t, "echoer", "canary", 2)
+	app.SetDeployCanary(config)
+	client := s.controllerClient(t)
+	t.Assert(client.UpdateApp(app), c.IsNil)
+	route := (&router.HTTPRoute{
+		Domain:  random.String(32) + ".com",
+		Service: "echo-service",
+	}).ToRoute()
+	t.Assert(client.CreateRoute(app.ID, route), c.IsNil)
+	return app, release, route
+}
+
+// assertCanaryRoute checks the given route of the given app is sending the
+// given percentage of requests to the given release
+func (s *DeployerSuite) assertCanaryRoute(t *c.C, appID string, route *router.Route, releaseID string, weight int) {
+	r, err := s.controllerClient(t).GetRoute(appID, route.FormattedID())
+	t.Assert(err, c.IsNil)
+	t.Assert(r.CanaryRelease, c.Equals, releaseID)
+	t.Assert(r.CanaryWeight, c.Equals, weight)
+}
+
+func (s *DeployerSuite) TestCanaryStrategy(t *c.C) {
+	app, release, route := s.createCanaryRelease(t, &ct.CanaryConfig{Count: 1, Weight: 50, Window: 5})
+	d := s.createDeploymentWithApp(t, app, release, "echo-service", 2)
+	defer d.cleanup()
+	releaseID := d.deployment.NewReleaseID
+	oldReleaseID := d.deployment.OldReleaseID
+
+	// a single canary job is started and sent half of the requests
+	d.waitForJobEvents("echoer", []*ct.Job{
+		{ReleaseID: releaseID, State: ct.JobStateUp},
+	})
+	d.waitForDeploymentStep(ct.DeploymentStepCanaryStarted)
+	d.waitForDeploymentStep(ct.DeploymentStepCanaryRouted)
+	s.assertCanaryRoute(t, app.ID, route, releaseID, 50)
+
+	// the canary job stays up for the window, so the release is promoted
+	d.waitForDeploymentStep(ct.DeploymentStepCanaryPromoted)
+	d.waitForJobEvents("echoer", []*ct.Job{
+		{ReleaseID: releaseID, State: ct.JobStateUp},
+		{ReleaseID: oldReleaseID, State: ct.JobStateDown},
+		{ReleaseID: oldReleaseID, State: ct.JobStateDown},
+	})
+	d.waitForDeploymentStatus("complete")
+	s.assertCanaryRoute(t, app.ID, route, "", 0)
+}
+
+func (s *DeployerSuite) TestCanaryStrategyFailedJob(t *c.C) {
+	app, release, route := s.createCanaryRelease(t, &ct.CanaryConfig{Count: 1, Weight: 50, Window: 60})
+
+	// deploy a release whose jobs exit shortly after starting
+	client := s.controllerClient(t)
+	release.ID = ""
+	echoer := release.Processes["echoer"]
+	echoer.Args = []string{"sh", "-c", "/bin/echoer & sleep 10; exit 1"}
+	release.Processes["echoer"] = echoer
+	t.Assert(client.CreateRelease(app.ID, release), c.IsNil)
+	deployment, err := client.CreateDeployment(app.ID, release.ID)
+	t.Assert(err, c.IsNil)
+	events := make(chan *ct.DeploymentEvent)
+	stream, err := client.StreamDeployment(deployment, events)
+	t.Assert(err, c.IsNil)
+	defer stream.Close()
+
+	// check the canary is aborted once its job fails, resetting the route
+	s.waitForDeploymentStep(t, events, ct.DeploymentStepCanaryRouted)
+	s.assertCanaryRoute(t, app.ID, route, deployment.NewReleaseID, 50)
+	aborted := s.waitForDeploymentStep(t, events, ct.DeploymentStepCanaryAborted)
+	t.Assert(aborted.Error, c.Matches, "canary echoer job failed: .*")
+	failed := s.waitForDeploymentStatus(t, events, "failed")
+	t.Assert(failed.Error, c.Equals, aborted.Error)
+	s.assertCanaryRoute(t, app.ID, route, "", 0)
+	s.assertRolledBack(t, deployment, map[string]int{"echoer": 2})
+}
+
+func (s *DeployerSuite) TestCanaryStrategyAbort(t *c.C) {
+	app, release, route := s.createCanaryRelease(t, &ct.CanaryConfig{Count: 1, Weight: 50, Window: 300})
+	d := s.createDeploymentWithApp(t, app, release, "echo-service", 2)
+	defer d.cleanup()
+
+	// aborting the deployment whilst the canary is being observed resets
+	// the route and rolls back without promoting the release
+	d.waitForDeploymentStep(ct.DeploymentStepCanaryRouted)
+	s.assertCanaryRoute(t, app.ID, route, d.deployment.NewReleaseID, 50)
+	_, err := s.controllerClient(t).AbortDeployment(d.deployment.ID)
+	t.Assert(err, c.IsNil)
+	event := s.waitForDeploymentStatus(t, d.deployEvents, "failed")
+	t.Assert(event.Error, c.Equals, "deployment aborted")
+	s.assertCanaryRoute(t, app.ID, route, "", 0)
+	s.assertRolledBack(t, d.deployment, map[string]int{"echoer": 2})
+}
+
+func (s *DeployerSuite) TestCanaryStrategyPause(t *c.C) {
+	app, release, route := s.createCanaryRelease(t, &ct.CanaryConfig{Count: 1, Weight: 50, Window: 10})
+	d := s.createDeploymentWithApp(t, app, release, "echo-service", 2)
+	defer d.cleanup()
+	releaseID := d.deployment.NewReleaseID
+	client := s.controllerClient(t)
+
+	// pausing the deployment whilst the canary is being observed resets
+	// the route once the window ends rather than promoting the release
+	d.waitForDeploymentStep(ct.DeploymentStepCanaryRouted)
+	_, err := client.PauseDeployment(d.deployment.ID)
+	t.Assert(err, c.IsNil)
+	d.waitForDeploymentStatus("paused")
+	s.assertCanaryRoute(t, app.ID, route, "", 0)
+
+	// resuming the deployment routes requests to the canary job again
+	// and observes it for another window before promoting the release
+	_, err = client.ResumeDeployment(d.deployment.ID)
+	t.Assert(err, c.IsNil)
+	d.waitForDeploymentStatus("running")
+	d.waitForDeploymentStep(ct.DeploymentStepCanaryRouted)
+	s.assertCanaryRoute(t, app.ID, route, releaseID, 50)
+	d.waitForDeploymentStep(ct.DeploymentStepCanaryPromoted)
+	d.waitForDeploymentStatus("complete")
+	s.assertCanaryRoute(t, app.ID, route, "", 0)
+}
+
+// createBlueGreenRelease creates a running release of two echoer jobs using
+// the blue-green strategy with a short warm period, along with an HTTP route
+// to their service
+func (s *DeployerSuite) createBlueGreenRelease(t *c.C) (*ct.App, *ct.Release, *router.Route) {
+	app, release := s.createRelease(t, "echoer", "blue-green", 2)
+	app.SetDeployWarmPeriod(1)
+	client := s.controllerClient(t)
+	t.Assert(client.UpdateApp(app), c.IsNil)
+	route := (&router.HTTPRoute{
+		Domain:  random.String(32) + ".com",
+		Service: "echo-service",
+	}).ToRoute()
+	t.Assert(client.CreateRoute(app.ID, route), c.IsNil)
+	return app, release, route
+}
+
+// assertRouteService checks the given route of the given app points at the
+// given service
+func (s *DeployerSuite) assertRouteService(t *c.C, appID string, route *router.Route, service string) {
+	r, err := s.controllerClient(t).GetRoute(appID, route.FormattedID())
+	t.Assert(err, c.IsNil)
+	t.Assert(r.Service, c.Equals, service)
+}
+
+func (s *DeployerSuite) TestBlueGreenStrategy(t *c.C) {
+	app, release, route := s.createBlueGreenRelease(t)
+	d := s.createDeploymentWithApp(t, app, release, "echo-service", 2)
+	defer d.cleanup()
+	releaseID := d.deployment.NewReleaseID
+	oldReleaseID := d.deployment.OldReleaseID
+	client := s.controllerClient(t)
+
+	// the new jobs are started under suffixed services before the route
+	// is switched to them, and the old jobs are stopped once warm
+	d.waitForJobEvents("echoer", []*ct.Job{
+		{ReleaseID: releaseID, State: ct.JobStateUp},
+		{ReleaseID: releaseID, State: ct.JobStateUp},
+	})
+	d.waitForDeploymentStep(ct.DeploymentStepBlueGreenUp)
+	d.waitForDeploymentStep(ct.DeploymentStepRoutesSwitched)
+	s.assertRouteService(t, app.ID, route, "echo-service-blue")
+	d.waitForJobEvents("echoer", []*ct.Job{
+		{ReleaseID: oldReleaseID, State: ct.JobStateDown},
+		{ReleaseID: oldReleaseID, State: ct.JobStateDown},
+	})
+	d.waitForDeploymentStatus("complete")
+	formation, err := client.GetFormation(app.ID, releaseID)
+	t.Assert(err, c.IsNil)
+	t.Assert(formation.ServiceSuffix, c.Equals, "blue")
+
+	// a following deployment using a different strategy runs the new
+	// jobs under the same suffixed services, so the route is untouched
+	app.Strategy = "all-at-once"
+	t.Assert(client.UpdateApp(app), c.IsNil)
+	release.ID = ""
+	t.Assert(client.CreateRelease(app.ID, release), c.IsNil)
+	var deployment *ct.Deployment
+	attempts := attempt.Strategy{Total: 10 * time.Second, Delay: 100 * time.Millisecond}
+	err = attempts.Run(func() (err error) {
+		deployment, err = client.CreateDeployment(app.ID, release.ID)
+		return
+	})
+	t.Assert(err, c.IsNil)
+	events := make(chan *ct.DeploymentEvent)
+	stream, err := client.StreamDeployment(deployment, events)
+	t.Assert(err, c.IsNil)
+	defer stream.Close()
+	s.waitForDeploymentStatus(t, events, "complete")
+	formation, err = client.GetFormation(app.ID, deployment.NewReleaseID)
+	t.Assert(err, c.IsNil)
+	t.Assert(formation.ServiceSuffix, c.Equals, "blue")
+	s.assertRouteService(t, app.ID, route, "echo-service-blue")
+}
+
+func (s *DeployerSuite) TestBlueGreenStrategyRollback(t *c.C) {
+	app, release, route := s.createBlueGreenRelease(t)
+	app.SetDeployRollback(&ct.RollbackConfig{BakePeriod: 60, MaxErrorRate: 100})
+	client := s.controllerClient(t)
+	t.Assert(client.UpdateApp(app), c.IsNil)
+
+	// deploy a release whose jobs exit shortly after starting
+	release.ID = ""
+	echoer := release.Processes["echoer"]
+	echoer.Args = []string{"sh", "-c", "/bin/echoer & sleep 10; exit 1"}
+	release.Processes["echoer"] = echoer
+	t.Assert(client.CreateRelease(app.ID, release), c.IsNil)
+	deployment, err := client.CreateDeployment(app.ID, release.ID)
+	t.Assert(err, c.IsNil)
+	events := make(chan *ct.DeploymentEvent)
+	stream, err := client.StreamDeployment(deployment, events)
+	t.Assert(err, c.IsNil)
+	defer stream.Close()
+
+	// check the route is switched to the new jobs and then switched back
+	// once they crash
+	s.waitForDeploymentStep(t, events, ct.DeploymentStepRoutesSwitched)
+	s.assertRouteService(t, app.ID, route, "echo-service-blue")
+	s.waitForDeploymentStep(t, events, ct.DeploymentStepBakeFailed)
+	s.waitForDeploymentStep(t, events, ct.DeploymentStepRoutesRestored)
+	event := s.waitForDeploymentStatus(t, events, "failed")
+	t.Assert(event.Error, c.Equals, "1 jobs of the new release crashed, exceeding the maximum of 0")
+	s.assertRouteService(t, app.ID, route, "echo-service")
+	s.assertRolledBack(t, deployment, map[string]int{"echoer": 2})
+}
+
+// deployReleasePhase creates a release of the given app which runs the given
+// command as its release phase, deploys it and waits for the release phase to
+// start, returning the deployment and a stream of its events
+func (s *DeployerSuite) deployReleasePhase(t *c.C, app *ct.App, release *ct.Release, cmd string) (*ct.Deployment, chan *ct.DeploymentEvent, stream.Stream) {
+	client := s.controllerClient(t)
+	release.ID = ""
+	release.Processes[ct.ReleaseProcessType] = ct.ProcessType{Args: []string{"sh", "-c", cmd}}
+	t.Assert(client.CreateRelease(app.ID, release), c.IsNil)
+	deployment, err := client.CreateDeployment(app.ID, release.ID)
+	t.Assert(err, c.IsNil)
+	t.Assert(deployment.FinishedAt, c.IsNil)
+	events := make(chan *ct.DeploymentEvent)
+	stream, err := client.StreamDeployment(deployment, events)
+	t.Assert(err, c.IsNil)
+	event := s.waitForDeploymentStep(t, events, ct.DeploymentStepReleasePhaseStarted)
+	t.Assert(event.JobType, c.Equals, ct.ReleaseProcessType)
+	return deployment, events, stream
+}
+
+// waitForReleasePhase waits for the release phase to either complete or fail,
+// returning the lines it output and the final event
+func (s *DeployerSuite) waitForReleasePhase(t *c.C, events chan *ct.DeploymentEvent) ([]string, *ct.DeploymentEvent) {
+	var output []string
+	for {
+		select {
+		case event := <-events:
+			switch event.Step {
+			case ct.DeploymentStepReleasePhaseOutput:
+				output = append(output, event.Output)
+			case ct.DeploymentStepReleasePhaseComplete, ct.DeploymentStepReleasePhaseFailed:
+				return output, event
+			}
+			if event.Status == "failed" {
+				t.Fatalf("expected release phase event, got failed event: %s", event.Error)
+			}
+		case <-time.After(60 * time.Second):
+			t.Fatal("timed out waiting for release phase")
+		}
+	}
+}
+
+func (s *DeployerSuite) TestReleasePhase(t *c.C) {
+	app, release := s.createRelease(t, "printer", "all-at-once", 1)
+	deployment, events, stream := s.deployReleasePhase(t, app, release, "echo migrating; echo done")
+	defer stream.Close()
+
+	// the output is streamed into deployment events before the new
+	// release's jobs are started
+	output, event := s.waitForReleasePhase(t, events)
+	t.Assert(event.Step, c.Equals, ct.DeploymentStepReleasePhaseComplete)
+	t.Assert(output, c.DeepEquals, []string{"migrating", "done"})
+	s.waitForDeploymentStatus(t, events, "complete")
+	formation, err := s.controllerClient(t).GetFormation(app.ID, deployment.NewReleaseID)
+	t.Assert(err, c.IsNil)
+	t.Assert(formation.Processes, c.DeepEquals, map[string]int{"printer": 1})
+}
+
+func (s *DeployerSuite) TestReleasePhaseFailure(t *c.C) {
+	app, release := s.createRelease(t, "printer", "all-at-once", 1)
+	deployment, events, stream := s.deployReleasePhase(t, app, release, "echo migration failed; exit 3")
+	defer stream.Close()
+
+	// a non-zero exit fails the deployment without starting any of the
+	// new release's jobs
+	output, event := s.waitForReleasePhase(t, events)
+	t.Assert(output, c.DeepEquals, []string{"migration failed"})
+	t.Assert(event.Step, c.Equals, ct.DeploymentStepReleasePhaseFailed)
+	t.Assert(event.Error, c.Equals, "release phase exited with status 3")
+	event = s.waitForDeploymentStatus(t, events, "failed")
+	t.Assert(event.Error, c.Equals, "release phase exited with status 3")
+	s.assertRolledBack(t, deployment, map[string]int{"printer": 1})
+}
+
+func (s *DeployerSuite) TestReleasePhaseFirstDeploy(t *c.C) {
+	// deploy the first release of an app, which has no old release or
+	// processes to scale but still runs the release phase
+	app := &ct.App{}
+	t.Assert(s.controllerClient(t).CreateApp(app), c.IsNil)
+	release := &ct.Release{
+		ArtifactIDs: []string{s.createArtifact(t, "test-apps").ID},
+		Processes:   make(map[string]ct.ProcessType),
+	}
+	deployment, events, stream := s.deployReleasePhase(t, app, release, "echo first deploy")
+	defer stream.Close()
+	t.Assert(deployment.OldReleaseID, c.Equals, "")
+
+	output, event := s.waitForReleasePhase(t, events)
+	t.Assert(event.Step, c.Equals, ct.DeploymentStepReleasePhaseComplete)
+	t.Assert(output, c.DeepEquals, []string{"first deploy"})
+	s.waitForDeploymentStatus(t, events, "complete")
+	current, err := s.controllerClient(t).GetAppRelease(app.ID)
+	t.Assert(err, c.IsNil)
+	t.Assert(current.ID, c.Equals, deployment.NewReleaseID)
+}
+
 func (s *DeployerSuite) TestRollbackFailedJob(t *c.C) {
 	// create a running release
 	app, release := s.createRelease(t, "printer", "all-at-once", 2)
@@ -124,7 +124,7 @@ func (s *GitDeploySuite) runBuildpackTestWithResponsePattern(t *c.C, name string
 	t.Assert(r.flynn("create", name), Outputs, fmt.Sprintf("Created %s\n", name))
 
 	for _, resource := range resources {
-		t.Assert(r.flynn("resource", "add", resource), Succeeds)
+		t.Assert(r.flynn("resource", "add", "--unencrypted", resource), Succeeds)
 	}
 
 	watcher, err := s.controllerClient(t).WatchJobEvents(name, "")
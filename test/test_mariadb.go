@@ -81,7 +81,7 @@ func (s *MariaDBSuite) TestDumpRestore(t *c.C) {
 	r := s.newGitRepo(t, "empty")
 	t.Assert(r.flynn("create"), Succeeds)
 
-	res := r.flynn("resource", "add", "mysql")
+	res := r.flynn("resource", "add", "--unencrypted", "mysql")
 	t.Assert(res, Succeeds)
 	id := strings.Split(res.Output, " ")[2]
 
@@ -30,7 +30,7 @@ func (s *MongoDBSuite) TestDumpRestore(t *c.C) {
 	r := s.newGitRepo(t, "empty")
 	t.Assert(r.flynn("create"), Succeeds)
 
-	res := r.flynn("resource", "add", "mongodb")
+	res := r.flynn("resource", "add", "--unencrypted", "mongodb")
 	t.Assert(res, Succeeds)
 	id := strings.Split(res.Output, " ")[2]
 
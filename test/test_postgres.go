@@ -27,7 +27,7 @@ func (s *PostgresSuite) TestDumpRestore(t *c.C) {
 	r := s.newGitRepo(t, "empty")
 	t.Assert(r.flynn("create"), Succeeds)
 
-	res := r.flynn("resource", "add", "postgres")
+	res := r.flynn("resource", "add", "--unencrypted", "postgres")
 	t.Assert(res, Succeeds)
 	id := strings.Split(res.Output, " ")[2]
 
@@ -18,7 +18,7 @@ func (s *RedisSuite) TestRedisEnv(t *c.C) {
 	a := s.newCliTestApp(t)
 
 	// create a redis resource
-	t.Assert(a.flynn("resource", "add", "redis"), Succeeds)
+	t.Assert(a.flynn("resource", "add", "--unencrypted", "redis"), Succeeds)
 
 	// get the new release
 	client := s.controllerClient(t)
@@ -66,7 +66,7 @@ func (s *RedisSuite) TestRedisEnv(t *c.C) {
 func (s *RedisSuite) TestDumpRestore(t *c.C) {
 	a := s.newCliTestApp(t)
 
-	res := a.flynn("resource", "add", "redis")
+	res := a.flynn("resource", "add", "--unencrypted", "redis")
 	t.Assert(res, Succeeds)
 	id := strings.Split(res.Output, " ")[2]
 
@@ -902,7 +902,7 @@ func (s *SchedulerSuite) TestPersistentVolumes(t *c.C) {
 
 	// create an app with a Redis resource
 	app, _ := s.createAppWithClient(t, x.controller)
-	t.Assert(x.flynn("/", "-a", app.Name, "resource", "add", "redis"), Succeeds)
+	t.Assert(x.flynn("/", "-a", app.Name, "resource", "add", "--unencrypted", "redis"), Succeeds)
 
 	// check the Redis volume exists
 	release, err := x.controller.GetAppRelease(app.ID)